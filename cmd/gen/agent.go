@@ -9,12 +9,12 @@ import (
 
 	"github.com/spf13/cobra"
 
-	"github.com/yanmxa/gencode/internal/core"
-	"github.com/yanmxa/gencode/internal/core/system"
 	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/plugin"
 	"github.com/yanmxa/gencode/internal/setting"
+	"github.com/yanmxa/gencode/internal/skill"
 	"github.com/yanmxa/gencode/internal/subagent"
-	"github.com/yanmxa/gencode/internal/tool"
+	"github.com/yanmxa/gencode/internal/tool/perm"
 )
 
 var agentRunOpts struct {
@@ -22,13 +22,16 @@ var agentRunOpts struct {
 	prompt    string
 	model     string
 	maxTurns  int
+	permMode  string
 }
 
 func init() {
 	agentRunCmd.Flags().StringVar(&agentRunOpts.agentType, "type", "", "Agent type to run")
 	agentRunCmd.Flags().StringVar(&agentRunOpts.prompt, "prompt", "", "Task prompt")
 	agentRunCmd.Flags().StringVar(&agentRunOpts.model, "model", "", "Model override")
-	agentRunCmd.Flags().IntVar(&agentRunOpts.maxTurns, "max-turns", 100, "Maximum conversation turns")
+	agentRunCmd.Flags().IntVar(&agentRunOpts.maxTurns, "max-turns", 0, "Maximum conversation turns (default: the agent's own limit)")
+	agentRunCmd.Flags().StringVar(&agentRunOpts.permMode, "permission-mode", setting.HeadlessDeny,
+		"Tool permissions without prompts: deny, accept-edits, or accept-all (dangerous)")
 
 	agentCmd.AddCommand(agentRunCmd)
 	rootCmd.AddCommand(agentCmd)
@@ -40,18 +43,42 @@ var agentCmd = &cobra.Command{
 }
 
 var agentRunCmd = &cobra.Command{
-	Use:   "run",
+	Use:   "run [agent] [prompt]",
 	Short: "Run a headless agent",
 	Long: `Run an agent in headless mode without TUI.
 
+The agent can be built-in, user/project-defined, or provided by an installed
+plugin (use the namespaced name, e.g. "my-plugin:changelog"). It runs to
+completion with its own prompt, tools, and turn limit, and the final response
+is printed to stdout.
+
+Nobody can answer permission prompts in a headless run, so --permission-mode
+decides up front what the agent may do:
+  deny          safe read-only tools and settings allow rules only (default)
+  accept-edits  also edit files inside the current directory and run
+                common development commands
+  accept-all    run any tool, including arbitrary shell commands. This is
+                dangerous: only use it in a sandbox or throwaway checkout.
+Anything that would otherwise prompt is denied. Deny rules and the
+bypass-immune safety checks always apply.
+
 Example:
+  gen agent run Explore "find main.go"
+  gen agent run my-plugin:changelog "summarize changes since v1.2.0"
   gen agent run --type Explore --prompt "find main.go"`,
+	Args: cobra.MaximumNArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 {
+			agentRunOpts.agentType = args[0]
+		}
+		if len(args) > 1 {
+			agentRunOpts.prompt = args[1]
+		}
 		if agentRunOpts.agentType == "" {
-			return fmt.Errorf("--type is required")
+			return fmt.Errorf("agent name is required (positional or --type)")
 		}
 		if agentRunOpts.prompt == "" {
-			return fmt.Errorf("--prompt is required")
+			return fmt.Errorf("prompt is required (positional or --prompt)")
 		}
 		return runHeadlessAgent()
 	},
@@ -61,6 +88,11 @@ Example:
 func runHeadlessAgent() error {
 	cwd, _ := os.Getwd()
 
+	perms, err := setting.NewHeadlessPermissions(agentRunOpts.permMode, cwd)
+	if err != nil {
+		return err
+	}
+
 	// Set up signal handling for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -68,7 +100,7 @@ func runHeadlessAgent() error {
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
 		<-sigCh
-		fmt.Println("\nShutting down agent...")
+		fmt.Fprintln(os.Stderr, "\nShutting down agent...")
 		cancel()
 	}()
 
@@ -79,75 +111,49 @@ func runHeadlessAgent() error {
 	}
 
 	currentModel := store.GetCurrentModel()
-	var llmProvider llm.Provider
-
-	if currentModel != nil {
-		p, err := llm.GetProvider(ctx, currentModel.Provider, currentModel.AuthMethod)
-		if err != nil {
-			return fmt.Errorf("failed to connect provider: %w", err)
-		}
-		llmProvider = p
-	}
-	if llmProvider == nil {
+	if currentModel == nil {
 		return fmt.Errorf("no provider available")
 	}
-
-	modelID := ""
-	if currentModel != nil {
-		modelID = currentModel.ModelID
-	}
-	if agentRunOpts.model != "" {
-		modelID = agentRunOpts.model
+	llmProvider, err := llm.GetProvider(ctx, currentModel.Provider, currentModel.AuthMethod)
+	if err != nil {
+		return fmt.Errorf("failed to connect provider: %w", err)
 	}
 
-	// Initialize agent registry
-	if err := subagent.Initialize(subagent.Options{CWD: cwd}); err != nil {
+	// Load plugins first so plugin-provided agents are registered.
+	setting.Initialize(setting.Options{CWD: cwd})
+	if err := plugin.Initialize(ctx, plugin.Options{CWD: cwd}); err != nil {
+		return fmt.Errorf("failed to load plugins: %w", err)
+	}
+	skill.Initialize(skill.Options{CWD: cwd})
+	if err := subagent.Initialize(subagent.Options{CWD: cwd, PluginAgentPaths: pluginAgentPaths}); err != nil {
 		return fmt.Errorf("failed to initialize agent registry: %w", err)
 	}
-
-	// Get agent configuration
-	agentCfg, ok := subagent.Default().Get(agentRunOpts.agentType)
-	if !ok {
+	if _, ok := subagent.Default().Get(agentRunOpts.agentType); !ok {
 		return fmt.Errorf("unknown agent type: %s", agentRunOpts.agentType)
 	}
 
-	// Build tool set based on agent config
-	toolSet := &tool.Set{}
-	if agentCfg.Tools != nil {
-		toolSet.Allow = []string(agentCfg.Tools)
-	}
-
-	sys := system.Build(system.Config{
-		Cwd:   cwd,
-		IsGit: setting.IsGitRepo(cwd),
-	})
-
-	client := llm.NewClient(llmProvider, modelID, 16384)
+	executor := subagent.NewExecutor(llmProvider, cwd, currentModel.ModelID, nil)
+	executor.SetContext("", "", setting.IsGitRepo(cwd))
+	executor.SetPermissionGate(headlessPermissionGate(perms))
 
-	schemas := toolSet.Tools()
-	tools := tool.AdaptToolRegistry(schemas, func() string { return cwd })
-
-	maxTurns := agentRunOpts.maxTurns
-	if maxTurns <= 0 {
-		maxTurns = 100
+	fmt.Fprintf(os.Stderr, "Agent: %s\n", agentRunOpts.agentType)
+	fmt.Fprintf(os.Stderr, "Prompt: %s\n", agentRunOpts.prompt)
+	fmt.Fprintf(os.Stderr, "Permissions: %s\n", agentRunOpts.permMode)
+	if agentRunOpts.permMode == setting.HeadlessAcceptAll {
+		fmt.Fprintln(os.Stderr, "WARNING: accept-all lets the agent run any command without confirmation")
 	}
-
-	ag := core.NewAgent(core.Config{
-		LLM:       client,
-		System:    sys,
-		Tools:     tools,
-		CWD:       cwd,
-		MaxTurns:  maxTurns,
-		OutboxBuf: -1,
+	fmt.Fprintln(os.Stderr, "---")
+
+	result, err := executor.Run(ctx, subagent.AgentRequest{
+		Agent:       agentRunOpts.agentType,
+		Prompt:      agentRunOpts.prompt,
+		Description: "headless run",
+		Model:       agentRunOpts.model,
+		MaxTurns:    agentRunOpts.maxTurns,
+		OnProgress: func(msg string) {
+			fmt.Fprintln(os.Stderr, "  "+msg)
+		},
 	})
-
-	ag.Append(ctx, core.UserMessage(agentRunOpts.prompt, nil))
-
-	fmt.Printf("Agent: %s\n", agentRunOpts.agentType)
-	fmt.Printf("Prompt: %s\n", agentRunOpts.prompt)
-	fmt.Println("---")
-
-	result, err := ag.ThinkAct(ctx)
 	if err != nil {
 		return fmt.Errorf("agent failed: %w", err)
 	}
@@ -156,6 +162,33 @@ func runHeadlessAgent() error {
 		fmt.Println(result.Content)
 	}
 
-	fmt.Printf("\n---\nDone: %d turns, %d tool uses\n", result.Turns, result.ToolUses)
+	fmt.Fprintf(os.Stderr, "\n---\nDone: %d turns, %d tool uses\n", result.TurnCount, result.ToolUses)
+	if !result.Success {
+		return fmt.Errorf("agent did not complete: %s", result.Error)
+	}
 	return nil
 }
+
+// headlessPermissionGate checks tool calls against settings rules and the
+// preconfigured session permissions; anything not allowed outright is denied.
+func headlessPermissionGate(perms *setting.SessionPermissions) perm.PermissionFunc {
+	return func(_ context.Context, name string, input map[string]any) (bool, string) {
+		d := setting.Default().HasPermissionToUseTool(name, input, perms)
+		if d.Behavior == setting.Allow {
+			return true, ""
+		}
+		return false, fmt.Sprintf("tool %s denied by --permission-mode %s: %s", name, agentRunOpts.permMode, d.Reason)
+	}
+}
+
+func pluginAgentPaths() []subagent.PluginAgentPath {
+	pPaths := plugin.GetPluginAgentPaths()
+	paths := make([]subagent.PluginAgentPath, len(pPaths))
+	for i, p := range pPaths {
+		paths[i] = subagent.PluginAgentPath{
+			Path:      p.Path,
+			Namespace: p.Namespace,
+		}
+	}
+	return paths
+}
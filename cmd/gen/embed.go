@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yanmxa/gencode/internal/core/system"
+	"github.com/yanmxa/gencode/internal/llm"
+)
+
+// embedChunkChars caps the size of each memory chunk sent for embedding.
+const embedChunkChars = 2000
+
+var embedOpts struct {
+	search string
+	top    int
+}
+
+func init() {
+	embedCmd.Flags().StringVar(&embedOpts.search, "search", "", "Rank memory and rules sections by similarity to this query")
+	embedCmd.Flags().IntVar(&embedOpts.top, "top", 5, "Number of results to show with --search")
+
+	rootCmd.AddCommand(embedCmd)
+}
+
+var embedCmd = &cobra.Command{
+	Use:   "embed [text...]",
+	Short: "Generate embeddings or search memory semantically",
+	Long: `Generate embedding vectors with the connected provider.
+
+Each argument is embedded separately and printed as one JSON array per line.
+With no arguments, stdin is embedded as a single text.
+
+With --search, GEN.md, CLAUDE.md, and rules files for the current directory
+are split into sections and ranked by similarity to the query.
+
+Embeddings come from the current provider when it supports them, otherwise
+from another connected provider that does (OpenAI or Google).
+
+Example:
+  gen embed "hello world" "goodbye"
+  gen embed --search "how do I run the tests"`,
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		provider, err := embeddingProvider(ctx)
+		if err != nil {
+			return err
+		}
+		if embedOpts.search != "" {
+			cwd, _ := os.Getwd()
+			return runEmbedSearch(ctx, provider, cwd, embedOpts.search, embedOpts.top)
+		}
+
+		texts := args
+		if len(texts) == 0 {
+			if in := readStdin(); in != "" {
+				texts = []string{in}
+			}
+		}
+		if len(texts) == 0 {
+			return fmt.Errorf("text is required (arguments or stdin)")
+		}
+		vectors, err := llm.GetEmbeddings(ctx, provider, texts)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		for _, v := range vectors {
+			if err := enc.Encode(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// embeddingProvider connects the current provider and falls back to any
+// connected provider that can generate embeddings.
+func embeddingProvider(ctx context.Context) (llm.Provider, error) {
+	store, _ := llm.NewStore()
+	if store == nil {
+		return nil, fmt.Errorf("no provider store available")
+	}
+	var current llm.Provider
+	if cm := store.GetCurrentModel(); cm != nil {
+		current, _ = llm.GetProvider(ctx, cm.Provider, cm.AuthMethod)
+	}
+	return llm.FindEmbeddingProvider(ctx, current, store)
+}
+
+// runEmbedSearch prints the memory sections most similar to query.
+func runEmbedSearch(ctx context.Context, provider llm.Provider, cwd, query string, top int) error {
+	chunks := system.ChunkMemoryFiles(system.LoadMemoryFiles(cwd), embedChunkChars)
+	if len(chunks) == 0 {
+		return fmt.Errorf("no memory or rules files found for %s", cwd)
+	}
+
+	texts := make([]string, 0, len(chunks)+1)
+	texts = append(texts, query)
+	for _, c := range chunks {
+		texts = append(texts, c.Content)
+	}
+	vectors, err := llm.GetEmbeddings(ctx, provider, texts)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range llm.RankBySimilarity(vectors[0], vectors[1:], top) {
+		c := chunks[m.Index]
+		label := c.Path
+		if c.Heading != "" {
+			label += " › " + c.Heading
+		}
+		fmt.Printf("%.3f  %s\n", m.Score, label)
+		fmt.Printf("       %s\n", firstLine(c.Content))
+	}
+	return nil
+}
+
+// firstLine returns the first non-heading line of text, for previews.
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return line
+		}
+	}
+	return ""
+}
@@ -5,22 +5,29 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 
 	"github.com/yanmxa/gencode/internal/app"
+	"github.com/yanmxa/gencode/internal/image"
 	"github.com/yanmxa/gencode/internal/log"
 	"github.com/yanmxa/gencode/internal/session"
 	"github.com/yanmxa/gencode/internal/setting"
+	"github.com/yanmxa/gencode/internal/tool"
+	"github.com/yanmxa/gencode/internal/tool/fs"
 
 	// Import providers for registration
 	_ "github.com/yanmxa/gencode/internal/llm/alibaba"
 	_ "github.com/yanmxa/gencode/internal/llm/anthropic"
+	_ "github.com/yanmxa/gencode/internal/llm/deepseek"
 	_ "github.com/yanmxa/gencode/internal/llm/google"
 	_ "github.com/yanmxa/gencode/internal/llm/minmax"
 	_ "github.com/yanmxa/gencode/internal/llm/moonshot"
+	_ "github.com/yanmxa/gencode/internal/llm/ollama"
 	_ "github.com/yanmxa/gencode/internal/llm/openai"
 )
 
@@ -33,22 +40,65 @@ var cliOpts struct {
 	resume bool   // --resume
 
 	pluginDir string
+	workspace string // --workspace
+	cache     bool   // --cache
+	provider  string // --provider
+	model     string // --model
+
+	maxTokens   int     // --max-tokens
+	temperature float64 // --temperature, applied only when set
+	permMode    string  // --permission-mode, print mode only
 }
 
 func init() {
-	// Load .env file if it exists (silent fail if not found)
-	_ = godotenv.Load()
+	// Load the nearest .env file if there is one (silent fail if not found).
+	// Variables already set in the environment are not overridden.
+	if cwd, err := os.Getwd(); err == nil {
+		home, _ := os.UserHomeDir()
+		if path := findDotEnv(cwd, home); path != "" {
+			_ = godotenv.Load(path)
+		}
+	}
 	// Initialize logging (enabled via GEN_DEBUG=1)
 	_ = log.Init()
 
 	// Set app version for session entries.
 	session.SetAppVersion(version)
 
+	// Apply the toolTimeouts, bashMaxOutput, and imageMaxSize settings in
+	// every mode that runs tools or reads images.
+	tool.SetTimeoutProvider(func(name string) (time.Duration, bool) {
+		if svc := setting.DefaultIfInit(); svc != nil {
+			return svc.ToolTimeout(name)
+		}
+		return 0, false
+	})
+	fs.SetMaxOutputProvider(func() int {
+		if svc := setting.DefaultIfInit(); svc != nil {
+			return svc.BashMaxOutput()
+		}
+		return 0
+	})
+	image.SetMaxSizeProvider(func() int {
+		if svc := setting.DefaultIfInit(); svc != nil {
+			return svc.ImageMaxSize()
+		}
+		return 0
+	})
+
 	// Register flags
 	rootCmd.Flags().StringVarP(&cliOpts.print, "print", "p", "", "Non-interactive print mode with prompt")
 	rootCmd.Flags().BoolVarP(&cliOpts.cont, "continue", "c", false, "Resume the most recent session")
 	rootCmd.Flags().BoolVarP(&cliOpts.resume, "resume", "r", false, "Select and resume a previous session")
 	rootCmd.PersistentFlags().StringVar(&cliOpts.pluginDir, "plugin-dir", "", "Load plugins from a specific directory")
+	rootCmd.Flags().StringVar(&cliOpts.workspace, "workspace", "", "Start with a named workspace from settings")
+	rootCmd.Flags().BoolVar(&cliOpts.cache, "cache", false, "Replay cached responses to identical LLM requests")
+	rootCmd.Flags().StringVar(&cliOpts.provider, "provider", "", "Use this connected provider for this run (default: the current one)")
+	rootCmd.Flags().StringVar(&cliOpts.model, "model", "", "Use this model for this run (default: the current one)")
+	rootCmd.Flags().IntVar(&cliOpts.maxTokens, "max-tokens", 0, "Maximum output tokens per response (default: the model limit)")
+	rootCmd.Flags().Float64Var(&cliOpts.temperature, "temperature", 0, "Sampling temperature, 0-2 (default: the provider default)")
+	rootCmd.Flags().StringVar(&cliOpts.permMode, "permission-mode", setting.HeadlessDeny,
+		"Print-mode tool permissions without prompts: deny, accept-edits, or accept-all (dangerous)")
 
 	// Register subcommands
 	rootCmd.AddCommand(versionCmd)
@@ -74,7 +124,19 @@ Extensible tools, customizable prompts, multi-provider support.
 
 Non-interactive mode:
   gen -p "your prompt"     Print response and exit
-  echo "msg" | gen -p ""   Pipe stdin in print mode`,
+  echo "msg" | gen -p ""   Pipe stdin in print mode
+
+Print mode runs tools, but nobody can answer permission prompts, so
+--permission-mode decides up front what it may do:
+  deny          safe read-only tools and settings allow rules only (default)
+  accept-edits  also edit files inside the current directory and run
+                common development commands
+  accept-all    run any tool, including arbitrary shell commands. This is
+                dangerous: only use it in a sandbox or throwaway checkout.
+
+Model selection (this run only):
+  gen --provider openai --model gpt-4o -p "hi"
+  gen --temperature 0 --max-tokens 1024 -p "hi"`,
 	Args: cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		printPrompt := cliOpts.print
@@ -82,22 +144,41 @@ Non-interactive mode:
 			printPrompt = readStdin()
 		}
 
-		// When -r is used with an argument, treat it as a session ID
-		var resumeID string
+		// When -r is used with an argument, treat it as a session file or ID
+		var resumeID, resumeFile string
 		if cliOpts.resume && len(args) > 0 {
-			resumeID = args[0]
+			if isSessionFile(args[0]) {
+				resumeFile = args[0]
+			} else {
+				resumeID = args[0]
+			}
 			args = args[1:]
 		}
 
 		prompt := strings.Join(args, " ")
 
 		opts := setting.RunOptions{
-			Print:     printPrompt,
-			Prompt:    prompt,
-			PluginDir: cliOpts.pluginDir,
-			Continue:  cliOpts.cont,
-			Resume:    cliOpts.resume,
-			ResumeID:  resumeID,
+			Print:      printPrompt,
+			Prompt:     prompt,
+			PluginDir:  cliOpts.pluginDir,
+			Continue:   cliOpts.cont,
+			Resume:     cliOpts.resume,
+			ResumeID:   resumeID,
+			ResumeFile: resumeFile,
+			Workspace:  cliOpts.workspace,
+			Cache:      cliOpts.cache,
+			Provider:   cliOpts.provider,
+			Model:      cliOpts.model,
+			MaxTokens:  cliOpts.maxTokens,
+
+			PermissionMode: cliOpts.permMode,
+		}
+		if cmd.Flags().Changed("temperature") {
+			opts.Temperature = &cliOpts.temperature
+		}
+		if err := opts.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
 		if err := app.Run(opts); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -106,6 +187,40 @@ Non-interactive mode:
 	},
 }
 
+// isSessionFile reports whether a --resume argument names a transcript file
+// rather than a session ID: it has a path separator or a .jsonl/.json
+// extension.
+func isSessionFile(arg string) bool {
+	if strings.ContainsRune(arg, filepath.Separator) || strings.ContainsRune(arg, '/') {
+		return true
+	}
+	ext := filepath.Ext(arg)
+	return ext == ".jsonl" || ext == ".json"
+}
+
+// findDotEnv returns the .env file in dir or the closest parent that has
+// one. The search stops after the git root or the home directory, so a
+// project never picks up another project's keys.
+func findDotEnv(dir, home string) string {
+	for {
+		path := filepath.Join(dir, ".env")
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		if dir == home {
+			return ""
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
 // readStdin returns piped stdin data, or empty string if stdin is a terminal.
 func readStdin() string {
 	stat, _ := os.Stdin.Stat()
@@ -149,6 +264,8 @@ Usage:
 Print Mode (non-interactive):
   gen -p "your prompt"       Print response and exit
   echo "data" | gen -p "analyze"  Pipe stdin with prompt
+  gen -p "fix the test" --permission-mode accept-edits
+                             Let print mode edit files in this directory
 
 Interactive Mode:
   gen                        Start chat
@@ -158,13 +275,22 @@ Session:
   gen -c, --continue         Resume the most recent session
   gen -r, --resume           Select and resume a previous session
   gen -r <session-id>        Resume a specific session by ID
+  gen -r <file.jsonl>        Resume a session from a transcript file
   gen --plugin-dir <path>    Load plugins from a specific directory
+  gen --workspace <name>     Start with a named workspace from settings
+  gen --cache                Replay cached responses to identical requests
 
 Commands:
   version      Print the version number
   agent run    Run a headless agent
+  embed        Generate embeddings or search memory semantically
+  serve        Serve a local HTTP API for editors and scripts
   help         Show this help message
 
+Environment:
+  GEN_DEBUG=1          Write debug logs to ~/.gen/debug.log
+  GEN_NO_TELEMETRY=1   No update checks, analytics, or remote MCP at startup
+
 Keybindings:
   Enter        Send message
   Alt+Enter    Insert newline
@@ -43,7 +43,7 @@ func init() {
 	mcpCmd.AddCommand(mcpRemoveCmd)
 
 	// Add flags
-	mcpAddCmd.Flags().StringVarP(&mcpTransport, "transport", "t", "stdio", "Transport type (stdio, http, sse)")
+	mcpAddCmd.Flags().StringVarP(&mcpTransport, "transport", "t", "stdio", "Transport type (stdio, http, sse, ws)")
 	mcpAddCmd.Flags().StringVarP(&mcpScope, "scope", "s", "local", "Config scope (user, project, local)")
 	mcpAddCmd.Flags().StringArrayVarP(&mcpEnvVars, "env", "e", nil, "Environment variables (KEY=value)")
 	mcpAddCmd.Flags().StringArrayVarP(&mcpHeaders, "header", "H", nil, "HTTP headers (Key: Value)")
@@ -96,7 +96,7 @@ Examples:
 				config.Args = cmdArgs[1:]
 			}
 
-		case mcp.TransportHTTP, mcp.TransportSSE:
+		case mcp.TransportHTTP, mcp.TransportSSE, mcp.TransportWebSocket:
 			if len(args) < 2 {
 				return fmt.Errorf("%s transport requires a URL: gen mcp add --transport %s <name> <url>", mcpTransport, mcpTransport)
 			}
@@ -219,7 +219,7 @@ var mcpListCmd = &cobra.Command{
 				if len(config.Args) > 0 {
 					location += " " + strings.Join(config.Args, " ")
 				}
-			case mcp.TransportHTTP, mcp.TransportSSE:
+			case mcp.TransportHTTP, mcp.TransportSSE, mcp.TransportWebSocket:
 				location = config.URL
 			}
 
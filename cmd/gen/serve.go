@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/server"
+	"github.com/yanmxa/gencode/internal/setting"
+)
+
+// serveTokenEnvVar fixes the bearer token instead of generating one per run.
+const serveTokenEnvVar = "GEN_SERVE_TOKEN"
+
+var serveOpts struct {
+	addr     string
+	model    string
+	permMode string
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveOpts.addr, "addr", "127.0.0.1:7878", "Address to listen on")
+	serveCmd.Flags().StringVar(&serveOpts.model, "model", "", "Default model for new sessions (default: the current model)")
+	serveCmd.Flags().StringVar(&serveOpts.permMode, "permission-mode", setting.HeadlessDeny,
+		"Tool permissions without prompts: deny, accept-edits, or accept-all (dangerous)")
+
+	rootCmd.AddCommand(serveCmd)
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a local HTTP API for editors and scripts",
+	Long: `Run the agent behind a local HTTP API instead of the TUI.
+
+Endpoints:
+  POST   /v1/messages       Send {"message", "session_id", "provider", "model"}
+                            and stream the answer as Server-Sent Events
+  GET    /v1/sessions       List session IDs
+  DELETE /v1/sessions/{id}  Forget a session
+
+Omit session_id to start a new session; its ID arrives in the first
+"session" event. "provider" and "model" switch the session's model for this
+and later messages. Sessions live in memory until the server exits.
+
+Events: session, text, thinking, tool_use, tool_result, done, error.
+
+Nobody can answer permission prompts, so --permission-mode decides up front
+what tools may do, as with "gen agent run":
+  deny          safe read-only tools and settings allow rules only (default)
+  accept-edits  also edit files inside the current directory and run
+                common development commands
+  accept-all    run any tool, including arbitrary shell commands
+
+Every request needs "Authorization: Bearer <token>". The token is printed
+at startup; set GEN_SERVE_TOKEN to choose it instead. On a loopback address
+only requests for localhost or a loopback IP are answered.
+
+Example:
+  gen serve --addr 127.0.0.1:7878
+  curl -N -H "Authorization: Bearer $TOKEN" -H 'Content-Type: application/json' \
+    -d '{"message":"summarize README.md"}' http://127.0.0.1:7878/v1/messages`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runServe()
+	},
+}
+
+func runServe() error {
+	cwd, _ := os.Getwd()
+	setting.Initialize(setting.Options{CWD: cwd})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	store, err := llm.NewStore()
+	if err != nil {
+		return fmt.Errorf("failed to load store: %w", err)
+	}
+	current := store.GetCurrentModel()
+	if current == nil {
+		return fmt.Errorf("no provider connected. Run 'gen' and use /provider to connect")
+	}
+	provider, err := llm.GetProvider(ctx, current.Provider, current.AuthMethod)
+	if err != nil {
+		return fmt.Errorf("failed to connect provider: %w", err)
+	}
+	modelID := current.ModelID
+	if serveOpts.model != "" {
+		modelID = serveOpts.model
+	}
+
+	ln, err := net.Listen("tcp", serveOpts.addr)
+	if err != nil {
+		return err
+	}
+	loopback := ln.Addr().(*net.TCPAddr).IP.IsLoopback()
+	token := os.Getenv(serveTokenEnvVar)
+	if token == "" {
+		token = server.NewToken()
+	}
+
+	srv, err := server.New(server.Options{
+		CWD:            cwd,
+		Provider:       provider,
+		ModelID:        modelID,
+		Connect:        connectStoredProvider(store),
+		PermissionMode: serveOpts.permMode,
+		Token:          token,
+		LoopbackOnly:   loopback,
+	})
+	if err != nil {
+		ln.Close()
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Serving on http://%s (%s, permissions: %s)\n", ln.Addr(), modelID, serveOpts.permMode)
+	fmt.Fprintf(os.Stderr, "Token: %s\n", token)
+	if !loopback {
+		fmt.Fprintln(os.Stderr, "WARNING: the API is reachable from other machines; only the token protects it")
+	}
+	if serveOpts.permMode == setting.HeadlessAcceptAll {
+		fmt.Fprintln(os.Stderr, "WARNING: accept-all lets any client run any command without confirmation")
+	}
+
+	httpSrv := &http.Server{Handler: srv, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpSrv.Shutdown(shutdownCtx)
+	}()
+	if err := httpSrv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// connectStoredProvider connects providers saved in the store by name, for
+// requests that select a provider other than the current one.
+func connectStoredProvider(store *llm.Store) func(context.Context, string) (llm.Provider, string, error) {
+	return func(ctx context.Context, name string) (llm.Provider, string, error) {
+		conn, ok := store.GetConnections()[name]
+		if !ok {
+			return nil, "", fmt.Errorf("not connected. Run 'gen' and use /provider to connect")
+		}
+		p, err := llm.GetProvider(ctx, llm.Name(name), conn.AuthMethod)
+		if err != nil {
+			return nil, "", err
+		}
+		return p, setting.DefaultModel(name, string(conn.AuthMethod)), nil
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/yanmxa/gencode/internal/core/system"
 	"github.com/yanmxa/gencode/internal/llm"
 	"github.com/yanmxa/gencode/internal/tool"
+	"github.com/yanmxa/gencode/internal/tool/scratchpad"
 )
 
 // BuildParams contains all values needed to construct a core.Agent.
@@ -18,11 +19,15 @@ type BuildParams struct {
 	ModelID        string
 	MaxTokens      int
 	ThinkingEffort string
+	Failover       *llm.Failover // nil disables failover to other models
+
+	CompactThreshold int // context usage percent that triggers compaction, 0 = default
 
 	CWD     string
 	CWDFunc func() string // dynamic CWD for tool execution; falls back to CWD if nil
 	IsGit   bool
 
+	CustomPrompt        string // project .gen/prompt.md; replaces the default identity
 	UserInstructions    string
 	ProjectInstructions string
 	SkillsPrompt        string
@@ -30,26 +35,38 @@ type BuildParams struct {
 	DeferredToolsPrompt string
 	Extra               []system.ExtraLayer
 
-	DisabledTools map[string]bool
-	MCPTools      []core.Tool
+	DisabledTools   map[string]bool
+	MCPTools        []core.Tool
+	MaxToolParallel int // read-only tool calls run at once, 0 = core.DefaultMaxToolParallel
 
 	PermissionDecider PermDecisionFunc
+	PreToolHook       tool.PreToolHook   // runs before the permission check; nil skips it
+	RunningCalls      *tool.RunningCalls // lets the caller cancel a running tool call; nil disables it
 	InteractionFunc   tool.InteractionFunc
+
+	// ScratchpadID names the scratchpad the agent's tools and compaction
+	// use; "" is the interactive session's.
+	ScratchpadID string
 }
 
-func buildAgent(p BuildParams) (core.Agent, *PermissionBridge, error) {
+// Build constructs a core.Agent from p without starting it, along with the
+// permission bridge its tools consult. Service.Start uses it for the TUI
+// session; other front ends can drive the agent directly.
+func Build(p BuildParams) (core.Agent, *PermissionBridge, error) {
 	if p.Provider == nil {
 		return nil, nil, fmt.Errorf("no LLM provider configured")
 	}
 
 	client := llm.NewClient(p.Provider, p.ModelID, p.MaxTokens)
 	client.SetThinkingEffort(p.ThinkingEffort)
+	client.SetFailover(p.Failover)
 
 	sys := system.Build(system.Config{
 		ProviderName:        client.Name(),
 		ModelID:             client.ModelID(),
 		Cwd:                 p.CWD,
 		IsGit:               p.IsGit,
+		CustomPrompt:        p.CustomPrompt,
 		UserInstructions:    p.UserInstructions,
 		ProjectInstructions: p.ProjectInstructions,
 		Skills:              p.SkillsPrompt,
@@ -67,7 +84,7 @@ func buildAgent(p BuildParams) (core.Agent, *PermissionBridge, error) {
 	schemas := (&tool.Set{
 		Disabled: p.DisabledTools,
 	}).Tools()
-	var adaptOpts []tool.AdaptOption
+	adaptOpts := []tool.AdaptOption{tool.WithScratchpad(p.ScratchpadID)}
 	if p.InteractionFunc != nil {
 		adaptOpts = append(adaptOpts, tool.WithInteraction(p.InteractionFunc))
 	}
@@ -77,6 +94,12 @@ func buildAgent(p BuildParams) (core.Agent, *PermissionBridge, error) {
 	}
 
 	pb := NewPermissionBridge(p.PermissionDecider)
+	// Disabled tools wrap outside the permission check so a call to one is
+	// answered with an explanation rather than an approval prompt. PreToolUse
+	// hooks run before the check, so a blocked call never prompts. Only the
+	// execution itself is cancellable, not the wait for approval.
+	cancellable := tool.WithCancel(tools, p.RunningCalls)
+	toolset := tool.WithDisabled(tool.WithPreHook(tool.WithPermission(cancellable, pb.PermissionFunc()), p.PreToolHook), p.DisabledTools)
 
 	compactClient := client
 	compactFunc := func(ctx context.Context, msgs []core.Message) (string, error) {
@@ -89,16 +112,19 @@ func buildAgent(p BuildParams) (core.Agent, *PermissionBridge, error) {
 		if summary == "" {
 			return "", fmt.Errorf("compaction produced empty summary")
 		}
-		return summary, nil
+		return scratchpad.AppendToSummary(p.ScratchpadID, summary), nil
 	}
 
 	ag := core.NewAgent(core.Config{
-		ID:          "main",
-		LLM:         client,
-		System:      sys,
-		Tools:       tool.WithPermission(tools, pb.PermissionFunc()),
-		CompactFunc: compactFunc,
-		CWD:         p.CWD,
+		ID:               "main",
+		LLM:              client,
+		System:           sys,
+		Tools:            toolset,
+		CompactFunc:      compactFunc,
+		CompactThreshold: p.CompactThreshold,
+		CWD:              p.CWD,
+		MaxToolParallel:  p.MaxToolParallel,
+		ParallelTool:     tool.ParallelSafe,
 	})
 
 	return ag, pb, nil
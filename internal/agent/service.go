@@ -21,6 +21,23 @@ type Service interface {
 	// Send pushes a user message to the agent's inbox. No-op if not active.
 	Send(content string, images []core.Image)
 
+	// SetInstructions replaces the user and project instructions in the
+	// running agent's system prompt. No-op if not active.
+	SetInstructions(user, project string)
+
+	// SetMessages replaces the running agent's conversation history.
+	// No-op if not active.
+	SetMessages(messages []core.Message)
+
+	// SetMCPTools replaces the running agent's MCP tools; the next request
+	// to the model lists the new set. Calls already running are not
+	// affected. No-op if not active.
+	SetMCPTools(tools []core.Tool)
+
+	// CancelTools cancels the tool calls in flight without stopping the
+	// turn and reports whether there were any.
+	CancelTools() bool
+
 	// Outbox returns the agent's event channel. Nil if not active.
 	Outbox() <-chan core.Event
 
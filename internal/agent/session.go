@@ -6,6 +6,8 @@ import (
 	"sync"
 
 	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/core/system"
+	"github.com/yanmxa/gencode/internal/tool"
 )
 
 type service struct {
@@ -13,7 +15,9 @@ type service struct {
 	agent              core.Agent
 	permBridge         *PermissionBridge
 	cancel             context.CancelFunc
+	running            *tool.RunningCalls
 	pendingPermRequest *PermBridgeRequest
+	mcpTools           []string // names of the MCP tools added to agent
 }
 
 func (s *service) Start(params BuildParams, messages []core.Message) error {
@@ -24,12 +28,16 @@ func (s *service) Start(params BuildParams, messages []core.Message) error {
 		return fmt.Errorf("agent session already active")
 	}
 
-	ag, pb, err := buildAgent(params)
+	running := &tool.RunningCalls{}
+	params.RunningCalls = running
+	ag, pb, err := Build(params)
 	if err != nil {
 		return err
 	}
 	s.agent = ag
 	s.permBridge = pb
+	s.running = running
+	s.mcpTools = toolNames(params.MCPTools)
 
 	if len(messages) > 0 {
 		s.agent.SetMessages(messages)
@@ -62,7 +70,9 @@ func (s *service) stopLocked() {
 	}
 	s.agent = nil
 	s.permBridge = nil
+	s.running = nil
 	s.pendingPermRequest = nil
+	s.mcpTools = nil
 }
 
 func (s *service) Active() bool {
@@ -81,6 +91,64 @@ func (s *service) Send(content string, images []core.Image) {
 	ag.Inbox() <- core.Message{Role: core.RoleUser, Content: content, Images: images}
 }
 
+func (s *service) SetInstructions(user, project string) {
+	s.mu.RLock()
+	ag := s.agent
+	s.mu.RUnlock()
+	if ag == nil {
+		return
+	}
+	if layer, ok := system.InstructionsLayer(user, project); ok {
+		ag.System().Set(layer)
+	} else {
+		ag.System().Remove("instructions")
+	}
+}
+
+func (s *service) SetMessages(messages []core.Message) {
+	s.mu.RLock()
+	ag := s.agent
+	s.mu.RUnlock()
+	if ag == nil {
+		return
+	}
+	ag.SetMessages(messages)
+}
+
+func (s *service) SetMCPTools(tools []core.Tool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.agent == nil {
+		return
+	}
+	set := s.agent.Tools()
+	for _, name := range s.mcpTools {
+		set.Remove(name)
+	}
+	for _, t := range tools {
+		set.Add(t)
+	}
+	s.mcpTools = toolNames(tools)
+}
+
+func toolNames(tools []core.Tool) []string {
+	names := make([]string, len(tools))
+	for i, t := range tools {
+		names[i] = t.Name()
+	}
+	return names
+}
+
+func (s *service) CancelTools() bool {
+	s.mu.RLock()
+	running := s.running
+	s.mu.RUnlock()
+	if running == nil {
+		return false
+	}
+	return running.Cancel()
+}
+
 func (s *service) Outbox() <-chan core.Event {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -4,8 +4,10 @@ package app
 
 import (
 	"context"
+	"fmt"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
 
 	"github.com/yanmxa/gencode/internal/agent"
 	"github.com/yanmxa/gencode/internal/app/conv"
@@ -15,7 +17,6 @@ import (
 	"github.com/yanmxa/gencode/internal/hook"
 	"github.com/yanmxa/gencode/internal/llm"
 	"github.com/yanmxa/gencode/internal/log"
-	"github.com/yanmxa/gencode/internal/mcp"
 	"github.com/yanmxa/gencode/internal/setting"
 	"github.com/yanmxa/gencode/internal/subagent"
 	"github.com/yanmxa/gencode/internal/tool"
@@ -32,23 +33,20 @@ func (m *model) buildAgentParams() agent.BuildParams {
 		extra = append(extra, system.ExtraLayer{Name: "skill-invocation", Content: m.userInput.Skill.ActiveInvocation})
 	}
 
-	var mcpTools []core.Tool
-	if m.services.MCP.Registry() != nil {
-		schemas := m.services.MCP.Registry().GetToolSchemas()
-		mcpCaller := mcp.NewCaller(m.services.MCP.Registry())
-		mcpTools = mcp.AsCoreTools(schemas, mcpCaller)
-	}
-
 	return agent.BuildParams{
 		Provider:       m.env.LLMProvider,
 		ModelID:        m.env.GetModelID(),
-		MaxTokens:      kit.GetMaxTokens(m.services.LLM.Store(), m.env.CurrentModel, setting.DefaultMaxTokens),
+		MaxTokens:      m.maxTokens(),
 		ThinkingEffort: m.env.EffectiveThinkingEffort(),
+		Failover:       m.failover(),
+
+		CompactThreshold: m.services.Setting.AutoCompactThreshold(),
 
 		CWD:     m.env.CWD,
 		CWDFunc: func() string { return m.env.CWD },
 		IsGit:   m.env.IsGit,
 
+		CustomPrompt:        m.env.CachedCustomPrompt,
 		UserInstructions:    m.env.CachedUserInstructions,
 		ProjectInstructions: m.env.CachedProjectInstructions,
 		SkillsPrompt:        m.services.Skill.PromptSection(),
@@ -56,13 +54,17 @@ func (m *model) buildAgentParams() agent.BuildParams {
 		DeferredToolsPrompt: m.services.Tool.FormatDeferredToolsPrompt(),
 		Extra:               extra,
 
-		DisabledTools: m.services.Setting.DisabledTools(),
-		MCPTools:      mcpTools,
+		DisabledTools: m.disabledTools(),
+		MCPTools:      m.mcpCoreTools(),
+
+		MaxToolParallel: m.services.Setting.MaxToolParallel(),
 
 		InteractionFunc: func(ctx context.Context, req *tool.QuestionRequest) (*tool.QuestionResponse, error) {
 			return m.conv.ProgressHub.Ask(ctx, 0, req)
 		},
 
+		PreToolHook: m.preToolHook(),
+
 		PermissionDecider: func(name string, args map[string]any) agent.PermDecisionResult {
 			decision := m.services.Setting.HasPermissionToUseTool(name, args, m.env.SessionPermissions)
 			switch decision.Behavior {
@@ -82,6 +84,61 @@ func (m *model) buildAgentParams() agent.BuildParams {
 	}
 }
 
+// maxTokens returns the output token limit for the current model, capped by
+// the maxTokens setting when one is configured. --max-tokens overrides both.
+func (m *model) maxTokens() int {
+	if m.env.MaxTokens > 0 {
+		return m.env.MaxTokens
+	}
+	limit := kit.GetMaxTokens(m.services.LLM.Store(), m.env.CurrentModel, 0)
+	configured := 0
+	if m.services.Setting != nil {
+		configured = m.services.Setting.MaxTokens()
+	}
+	switch {
+	case configured > 0 && (limit == 0 || configured < limit):
+		return configured
+	case limit > 0:
+		return limit
+	default:
+		return setting.DefaultMaxTokens
+	}
+}
+
+// agentErrorNotice describes an agent failure for the conversation.
+func (m *model) agentErrorNotice(err error) string {
+	if notFound, ok := llm.AsModelNotFound(err); ok {
+		return modelNotFoundMessage(notFound, m.services.LLM.Store(), "Run /model to pick another.")
+	}
+	return fmt.Sprintf("Agent error: %v", err)
+}
+
+// modelNotFoundMessage tells the user the model is gone, suggests the
+// closest model in the cached model list when there is one, and ends with
+// hint on how to choose another model.
+func modelNotFoundMessage(err *llm.ModelNotFoundError, store *llm.Store, hint string) string {
+	msg := fmt.Sprintf("Model %s not found.", err.Model)
+	if store != nil {
+		if suggestion := store.SuggestModel(llm.Name(err.Provider), err.Model); suggestion != "" {
+			msg += fmt.Sprintf(" Did you mean %s?", suggestion)
+		}
+	}
+	return msg + " " + hint
+}
+
+// failover retries failed streams along the /model fallback-chain.
+func (m *model) failover() *llm.Failover {
+	f := llm.StoreFailover(m.services.LLM.Store())
+	if f == nil {
+		return nil
+	}
+	f.OnFailover = func(from string, to llm.FallbackEntry, err error) {
+		log.Logger().Warn("model request failed, trying fallback",
+			zap.String("from", from), zap.String("to", to.String()), zap.Error(err))
+	}
+	return f
+}
+
 // ============================================================
 // Agent lifecycle (delegates to services.Agent)
 // ============================================================
@@ -158,12 +215,24 @@ func (m *model) HandlePermBridge(req *conv.PermBridgeRequest) tea.Cmd {
 	if req == nil {
 		return nil
 	}
-
-	permReq := m.preparePermissionRequest(req)
-	m.userInput.Approval.Show(permReq, m.env.Width, m.env.Height)
+	// An MCP sampling consent prompt is open; the tool request is shown
+	// once that one is answered (see handleSamplingDecision).
+	if m.env.PendingSampling != nil {
+		return nil
+	}
+	m.showPendingPermission()
 	return nil
 }
 
+// showPendingPermission opens the approval prompt for the queued tool request.
+func (m *model) showPendingPermission() {
+	req := m.services.Agent.PendingPermission()
+	if req == nil {
+		return
+	}
+	m.userInput.Approval.Show(m.preparePermissionRequest(req), m.env.Width, m.env.Height)
+}
+
 // ============================================================
 // Agent tool configuration
 // ============================================================
@@ -218,7 +287,8 @@ func (m *model) ReconfigureAgentTool() {
 // ============================================================
 
 func (m *model) buildLLMClient() *llm.Client {
-	c := llm.NewClient(m.env.LLMProvider, m.env.GetModelID(), kit.GetMaxTokens(m.services.LLM.Store(), m.env.CurrentModel, setting.DefaultMaxTokens))
+	c := llm.NewClient(m.env.LLMProvider, m.env.GetModelID(), m.maxTokens())
 	c.SetThinkingEffort(m.env.EffectiveThinkingEffort())
+	c.SetTemperature(m.env.Temperature)
 	return c
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/yanmxa/gencode/internal/core/system"
 	"github.com/yanmxa/gencode/internal/hook"
 	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/tool/scratchpad"
 )
 
 // --- Message types ---
@@ -23,7 +24,13 @@ type CompactResultMsg struct {
 	Summary       string
 	OriginalCount int
 	Trigger       string // "manual" or "auto"
+	Focus         string
 	Error         error
+
+	// Rough token sizes (chars/4) of the history and its summary, used to show
+	// the expected savings before the summary is applied.
+	OriginalTokens int
+	SummaryTokens  int
 }
 
 // --- Compact state ---
@@ -53,6 +60,12 @@ func (c *CompactState) ClearResult() {
 	c.LastError = false
 }
 
+// AwaitReview stops the progress indicator while the summary is previewed.
+func (c *CompactState) AwaitReview() {
+	c.Active = false
+	c.Phase = ""
+}
+
 func (c *CompactState) Complete(result string, isError bool) {
 	c.Active = false
 	c.Focus = ""
@@ -191,6 +204,15 @@ func CompactCmd(req CompactRequest) tea.Cmd {
 			}
 		}
 		summary, count, err := CompactConversation(ctx, req.Client, req.Messages, focus)
-		return CompactResultMsg{Summary: summary, OriginalCount: count, Trigger: req.Trigger, Error: err}
+		summary = scratchpad.AppendToSummary(scratchpad.Main, summary)
+		return CompactResultMsg{
+			Summary:        summary,
+			OriginalCount:  count,
+			Trigger:        req.Trigger,
+			Focus:          req.Focus,
+			Error:          err,
+			OriginalTokens: len(core.BuildConversationText(req.Messages)) / 4,
+			SummaryTokens:  len(summary) / 4,
+		}
 	}
 }
@@ -1,22 +1,42 @@
 package conv
 
 import (
+	"slices"
+	"time"
+
 	"github.com/yanmxa/gencode/internal/core"
 )
 
 type StreamState struct {
 	Active       bool
 	BuildingTool string
+	// ThinkingStart is when the current response's first thinking chunk
+	// arrived; zero when the response is not (or no longer) thinking.
+	ThinkingStart time.Time
+	// Notice is a transient status from the provider, such as a retry,
+	// shown in place of "Thinking..." until output arrives.
+	Notice string
+	// StreamingTool names the tool call the model is streaming, and
+	// ToolInput accumulates its input. The input is partial JSON until the
+	// response is done, so it is only measured for progress, never parsed.
+	StreamingTool string
+	ToolInput     string
 }
 
 func (s *StreamState) Stop() {
 	s.Active = false
 	s.BuildingTool = ""
+	s.ThinkingStart = time.Time{}
+	s.Notice = ""
+	s.StreamingTool = ""
+	s.ToolInput = ""
 }
 
 type ConversationModel struct {
 	Messages       []core.ChatMessage
 	CommittedCount int
+	// PersistedCount is the number of messages included in the last session save.
+	PersistedCount int
 	Stream         StreamState
 	Compact        CompactState
 	Modal          ModalState
@@ -37,12 +57,30 @@ func (m *ConversationModel) Append(msg core.ChatMessage) {
 func (m *ConversationModel) Clear() {
 	m.Messages = []core.ChatMessage{}
 	m.CommittedCount = 0
+	m.PersistedCount = 0
 }
 
 func (m *ConversationModel) AddNotice(content string) {
 	m.Messages = append(m.Messages, core.ChatMessage{Role: core.RoleNotice, Content: content})
 }
 
+// AddNoticeBeforeStream inserts a notice ahead of the assistant message that
+// is still streaming, so later chunks keep appending to that message.
+func (m *ConversationModel) AddNoticeBeforeStream(content string) {
+	n := len(m.Messages)
+	if n == 0 || n-1 < m.CommittedCount || m.Messages[n-1].Role != core.RoleAssistant {
+		m.AddNotice(content)
+		return
+	}
+	m.Messages = slices.Insert(m.Messages, n-1, core.ChatMessage{Role: core.RoleNotice, Content: content})
+}
+
+// AddContextNote appends a note that is rendered like a notice but is also
+// included in the provider conversation.
+func (m *ConversationModel) AddContextNote(content string) {
+	m.Messages = append(m.Messages, core.ChatMessage{Role: core.RoleContext, Content: content})
+}
+
 func (m *ConversationModel) AppendToLast(text, thinking string) {
 	if len(m.Messages) == 0 {
 		return
@@ -65,6 +103,21 @@ func (m *ConversationModel) SetLastToolCalls(calls []core.ToolCall) {
 	}
 }
 
+// SetLastThinkingDuration records how long the last assistant message spent
+// thinking before its answer began.
+func (m *ConversationModel) SetLastThinkingDuration(d time.Duration) {
+	if len(m.Messages) > 0 && m.Messages[len(m.Messages)-1].Role == core.RoleAssistant {
+		m.Messages[len(m.Messages)-1].ThinkingDuration = d
+	}
+}
+
+// SetLastCitations records the sources the last assistant message cites.
+func (m *ConversationModel) SetLastCitations(citations []core.Citation) {
+	if len(m.Messages) > 0 && m.Messages[len(m.Messages)-1].Role == core.RoleAssistant {
+		m.Messages[len(m.Messages)-1].Citations = citations
+	}
+}
+
 func (m *ConversationModel) SetLastThinkingSignature(sig string) {
 	if len(m.Messages) > 0 && sig != "" {
 		m.Messages[len(m.Messages)-1].ThinkingSignature = sig
@@ -122,7 +175,7 @@ func (m *ConversationModel) ToggleMostRecentExpandable() {
 	for i := len(m.Messages) - 1; i >= 0; i-- {
 		msg := &m.Messages[i]
 		switch {
-		case msg.ToolResult != nil:
+		case msg.ToolResult != nil, isDiffNotice(*msg):
 			msg.Expanded = !msg.Expanded
 			return
 		case len(msg.ToolCalls) > 0:
@@ -136,14 +189,14 @@ func (m *ConversationModel) ToggleAllExpandable() {
 	anyExpanded := false
 	for i := 0; i < len(m.Messages); i++ {
 		msg := m.Messages[i]
-		if (msg.ToolResult != nil && msg.Expanded) ||
+		if ((msg.ToolResult != nil || isDiffNotice(msg)) && msg.Expanded) ||
 			(len(msg.ToolCalls) > 0 && msg.ToolCallsExpanded) {
 			anyExpanded = true
 			break
 		}
 	}
 	for i := 0; i < len(m.Messages); i++ {
-		if m.Messages[i].ToolResult != nil {
+		if m.Messages[i].ToolResult != nil || isDiffNotice(m.Messages[i]) {
 			m.Messages[i].Expanded = !anyExpanded
 		}
 		if len(m.Messages[i].ToolCalls) > 0 {
@@ -152,6 +205,48 @@ func (m *ConversationModel) ToggleAllExpandable() {
 	}
 }
 
+// ToggleAllToolCalls collapses every tool call group when any is expanded,
+// otherwise expands them all. Tool results are left as they are.
+func (m *ConversationModel) ToggleAllToolCalls() {
+	anyExpanded := false
+	for _, msg := range m.Messages {
+		if len(msg.ToolCalls) > 0 && msg.ToolCallsExpanded {
+			anyExpanded = true
+			break
+		}
+	}
+	for i := range m.Messages {
+		if len(m.Messages[i].ToolCalls) > 0 {
+			m.Messages[i].ToolCallsExpanded = !anyExpanded
+		}
+	}
+}
+
+// ToggleAllToolResults collapses every tool result when any is expanded,
+// otherwise expands them all. Tool calls are left as they are.
+func (m *ConversationModel) ToggleAllToolResults() {
+	anyExpanded := false
+	for _, msg := range m.Messages {
+		if msg.ToolResult != nil && msg.Expanded {
+			anyExpanded = true
+			break
+		}
+	}
+	for i := range m.Messages {
+		if m.Messages[i].ToolResult != nil {
+			m.Messages[i].Expanded = !anyExpanded
+		}
+	}
+}
+
+// CollapseAll collapses every tool call, tool result, and /diff file.
+func (m *ConversationModel) CollapseAll() {
+	for i := range m.Messages {
+		m.Messages[i].Expanded = false
+		m.Messages[i].ToolCallsExpanded = false
+	}
+}
+
 func (m *ConversationModel) HasAllToolResults(idx int) bool {
 	if idx < 0 || idx >= len(m.Messages) {
 		return true
@@ -209,6 +304,13 @@ func (m ConversationModel) ConvertToProviderFrom(startIdx int) []core.Message {
 		if msg.Role == core.RoleNotice {
 			continue
 		}
+		if msg.Role == core.RoleContext {
+			providerMsgs = append(providerMsgs, core.Message{
+				Role:    core.RoleUser,
+				Content: core.ContextNotePrefix + msg.Content,
+			})
+			continue
+		}
 
 		providerMsg := core.Message{
 			Role:              msg.Role,
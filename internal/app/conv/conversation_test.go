@@ -0,0 +1,25 @@
+package conv
+
+import (
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+func TestConvertToProviderIncludesContextNotes(t *testing.T) {
+	m := NewConversation()
+	m.Append(core.ChatMessage{Role: core.RoleUser, Content: "hello"})
+	m.AddNotice("command output")
+	m.AddContextNote("prefer table-driven tests")
+
+	msgs := m.ConvertToProvider()
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 provider messages, got %d", len(msgs))
+	}
+	if msgs[1].Role != core.RoleUser {
+		t.Errorf("context note role = %q, want %q", msgs[1].Role, core.RoleUser)
+	}
+	if want := core.ContextNotePrefix + "prefer table-driven tests"; msgs[1].Content != want {
+		t.Errorf("context note content = %q, want %q", msgs[1].Content, want)
+	}
+}
@@ -0,0 +1,98 @@
+package conv
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/yanmxa/gencode/internal/app/kit"
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+// diffNoticeName marks a RoleNotice message that holds one file of /diff
+// output: Content is the file's unified diff and DisplayContent its label.
+const diffNoticeName = "/diff"
+
+// diffCollapsedLines is how many diff lines a collapsed /diff file shows.
+const diffCollapsedLines = 20
+
+// AddDiffNotice appends one file of /diff output. Like a tool result, a long
+// diff is collapsed until it is expanded with ctrl+o.
+func (m *ConversationModel) AddDiffNotice(label, diff string) {
+	m.Messages = append(m.Messages, core.ChatMessage{
+		Role:           core.RoleNotice,
+		ToolName:       diffNoticeName,
+		DisplayContent: label,
+		Content:        diff,
+	})
+}
+
+func isDiffNotice(msg core.ChatMessage) bool {
+	return msg.Role == core.RoleNotice && msg.ToolName == diffNoticeName
+}
+
+// RenderDiffNotice renders a file's unified diff with the same added and
+// removed line styles as the approval diff preview.
+func RenderDiffNotice(label, diff string, expanded bool) string {
+	lines := diffBody(diff)
+	var added, removed int
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+
+	var sb strings.Builder
+	header := lipgloss.NewStyle().Foreground(kit.CurrentTheme.Primary).Bold(true).Render(label)
+	counts := lipgloss.NewStyle().Foreground(kit.CurrentTheme.Success).Render(fmt.Sprintf("+%d", added)) + " " +
+		lipgloss.NewStyle().Foreground(kit.CurrentTheme.Error).Render(fmt.Sprintf("-%d", removed))
+	sb.WriteString(toolResultStyle.Render("  ⎿  ") + header + "  " + counts + "\n")
+
+	show := lines
+	if !expanded && len(lines) > diffCollapsedLines {
+		show = lines[:diffCollapsedLines]
+	}
+	hunkStyle := lipgloss.NewStyle().Foreground(kit.CurrentTheme.Accent)
+	contextStyle := lipgloss.NewStyle().Foreground(kit.CurrentTheme.Muted)
+	for _, line := range show {
+		var rendered string
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			rendered = hunkStyle.Render(line)
+		case strings.HasPrefix(line, "+"):
+			rendered = kit.DiffAddedLineStyle().Render(line)
+		case strings.HasPrefix(line, "-"):
+			rendered = kit.DiffRemovedLineStyle().Render(line)
+		default:
+			rendered = contextStyle.Render(line)
+		}
+		sb.WriteString("     " + rendered + "\n")
+	}
+	if hidden := len(lines) - len(show); hidden > 0 {
+		sb.WriteString(ThinkingStyle.Render(fmt.Sprintf("     ... %d more lines (ctrl+o to expand)", hidden)) + "\n")
+	}
+	return sb.String()
+}
+
+// diffBody drops the "diff --git", index, and ---/+++ header lines of a
+// single-file diff, keeping everything from the first hunk on. Diffs without
+// hunks (binary files, mode changes) keep their remaining header lines.
+func diffBody(diff string) []string {
+	lines := strings.Split(strings.TrimRight(diff, "\n"), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "@@") {
+			return lines[i:]
+		}
+	}
+	var body []string
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "diff --git ") && !strings.HasPrefix(line, "index ") {
+			body = append(body, line)
+		}
+	}
+	return body
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/yanmxa/gencode/internal/core"
 	"github.com/yanmxa/gencode/internal/llm"
 	"github.com/yanmxa/gencode/internal/tool"
+	"github.com/yanmxa/gencode/internal/tool/toolresult"
 )
 
 // OperationMode mirrors OperationMode to avoid importing setting in the render layer.
@@ -28,9 +30,6 @@ const (
 	// minWrapWidth is the minimum markdown wrap width.
 	minWrapWidth = 40
 
-	// autoCompactThreshold is the percentage of context usage that triggers auto-compact.
-	autoCompactThreshold = 95
-
 	// agentContentIndent is the extra indent for agent prompt/response content
 	// beyond toolResultExpandedStyle's PaddingLeft(4). Total indent = 4 + 4 = 8 chars.
 	agentContentIndent = "    "
@@ -51,6 +50,22 @@ func RenderWelcome() string {
 	return "\n" + icon
 }
 
+// RenderProtectedDirWarning renders the startup banner shown when gen is
+// launched in a directory matching a protected path.
+func RenderProtectedDirWarning(cwd, entry string) string {
+	titleStyle := lipgloss.NewStyle().Foreground(kit.CurrentTheme.Warning).Bold(true)
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(kit.CurrentTheme.Warning).
+		Padding(0, 1).
+		MarginLeft(3)
+
+	body := titleStyle.Render("⚠ Protected directory: "+cwd) + "\n" +
+		fmt.Sprintf("Matches protectedPaths entry %q.", entry) + "\n" +
+		"Mode is locked to normal and every Bash command asks for confirmation."
+	return "\n" + boxStyle.Render(body)
+}
+
 // OperationModeParams holds the parameters needed for rendering mode status.
 type OperationModeParams struct {
 	Mode             OperationMode
@@ -59,19 +74,23 @@ type OperationModeParams struct {
 	InputLimit       int
 	ModelName        string
 	StatusMessage    string
-	ConversationCost llm.Money
+	ConversationCost []llm.Money // one entry per currency; nil hides the cost
 	Width            int
 	ThinkingEffort   string
 	ShowThinking     bool
 	QueueCount       int
 	WaitingCount     int
+	JobCount         int    // background Bash commands still running
+	CycleModeKey     string // key that cycles the mode, "" = shift+tab
+	CompactThreshold int    // context usage percent that triggers auto-compact, 0 = default, <0 = off
+	CustomPrompt     bool   // project .gen/prompt.md replaces the default system prompt
 }
 
 // RenderModeStatus renders the combined mode status line.
 func RenderModeStatus(params OperationModeParams) string {
 	var leftParts []string
 
-	if modeStatus := RenderOperationModeIndicator(params.Mode); modeStatus != "" {
+	if modeStatus := RenderOperationModeIndicator(params.Mode, params.CycleModeKey); modeStatus != "" {
 		leftParts = append(leftParts, modeStatus)
 	}
 
@@ -81,16 +100,23 @@ func RenderModeStatus(params OperationModeParams) string {
 		}
 	}
 
+	if params.CustomPrompt {
+		leftParts = append(leftParts, RenderCustomPromptIndicator())
+	}
+
 	if queueBadge := renderQueueBadge(params.QueueCount); queueBadge != "" {
 		leftParts = append(leftParts, queueBadge)
 	}
 	if waitingBadge := renderWaitingBadge(params.WaitingCount); waitingBadge != "" {
 		leftParts = append(leftParts, waitingBadge)
 	}
+	if jobsBadge := renderJobsBadge(params.JobCount); jobsBadge != "" {
+		leftParts = append(leftParts, jobsBadge)
+	}
 
 	left := strings.Join(leftParts, "  ")
 
-	right := renderModelWithTokens(params.ModelName, params.StatusMessage, params.InputTokens, params.InputLimit, params.ConversationCost)
+	right := renderModelWithTokens(params.ModelName, params.StatusMessage, params.InputTokens, params.InputLimit, params.CompactThreshold, params.ConversationCost)
 	if right == "" || params.Width <= 0 {
 		return left
 	}
@@ -100,7 +126,7 @@ func RenderModeStatus(params OperationModeParams) string {
 }
 
 // renderModelWithTokens renders the model name with token usage on the right side.
-func renderModelWithTokens(modelName, statusMessage string, inputTokens, inputLimit int, conversationCost llm.Money) string {
+func renderModelWithTokens(modelName, statusMessage string, inputTokens, inputLimit, compactThreshold int, conversationCost []llm.Money) string {
 	if modelName == "" {
 		return ""
 	}
@@ -111,8 +137,8 @@ func renderModelWithTokens(modelName, statusMessage string, inputTokens, inputLi
 	}
 
 	if inputTokens == 0 {
-		if !conversationCost.IsZero() {
-			parts = append(parts, kit.FormatMoney(conversationCost))
+		if len(conversationCost) > 0 {
+			parts = append(parts, kit.FormatMoneyTotals(conversationCost))
 		}
 		return muted.Render(strings.Join(parts, " · "))
 	}
@@ -120,13 +146,13 @@ func renderModelWithTokens(modelName, statusMessage string, inputTokens, inputLi
 	if inputLimit > 0 {
 		pct := float64(inputTokens) / float64(inputLimit) * 100
 		ctxSegment := fmt.Sprintf("%s/%s (%.0f%%)", kit.FormatTokenCount(inputTokens), kit.FormatTokenCount(inputLimit), pct)
-		if hint := compactStatusHint(pct); hint != "" {
+		if hint := compactStatusHint(pct, compactThreshold); hint != "" {
 			ctxSegment += " · " + hint
 		}
 		parts = append(parts, ctxSegment)
 	}
-	if !conversationCost.IsZero() {
-		parts = append(parts, kit.FormatMoney(conversationCost))
+	if len(conversationCost) > 0 {
+		parts = append(parts, kit.FormatMoneyTotals(conversationCost))
 	}
 	return muted.Render(strings.Join(parts, " · "))
 }
@@ -147,19 +173,26 @@ func RenderTurnUsageSummary(inputTokens, outputTokens, width int) string {
 	return strings.Repeat(" ", gap) + summary
 }
 
-func compactStatusHint(percent float64) string {
+func compactStatusHint(percent float64, threshold int) string {
+	if threshold < 0 {
+		return ""
+	}
+	if threshold == 0 {
+		threshold = core.DefaultCompactThreshold
+	}
 	switch {
-	case percent >= autoCompactThreshold:
+	case percent >= float64(threshold):
 		return "auto-compact"
-	case percent >= 85:
-		return fmt.Sprintf("compact at %d%%", autoCompactThreshold)
+	case percent >= float64(threshold-10):
+		return fmt.Sprintf("compact at %d%%", threshold)
 	default:
 		return ""
 	}
 }
 
-// RenderOperationModeIndicator returns the mode status indicator for auto-accept or bypass mode.
-func RenderOperationModeIndicator(mode OperationMode) string {
+// RenderOperationModeIndicator returns the mode status indicator for
+// auto-accept or bypass mode, with a hint naming the key that cycles modes.
+func RenderOperationModeIndicator(mode OperationMode, cycleKey string) string {
 	var icon, label string
 	var color lipgloss.TerminalColor
 
@@ -177,7 +210,10 @@ func RenderOperationModeIndicator(mode OperationMode) string {
 	}
 
 	style := lipgloss.NewStyle().Foreground(color)
-	hint := lipgloss.NewStyle().Foreground(kit.CurrentTheme.Muted).Render("  shift+tab to toggle")
+	if cycleKey == "" {
+		cycleKey = "shift+tab"
+	}
+	hint := lipgloss.NewStyle().Foreground(kit.CurrentTheme.Muted).Render("  " + cycleKey + " to toggle")
 	return "  " + style.Render(icon+label) + hint
 }
 
@@ -189,6 +225,12 @@ func RenderThinkingIndicator(effort string) string {
 	return "  " + style.Render("✦ "+effort)
 }
 
+// RenderCustomPromptIndicator marks that the project's .gen/prompt.md
+// replaced the default system prompt.
+func RenderCustomPromptIndicator() string {
+	return "  " + lipgloss.NewStyle().Foreground(kit.CurrentTheme.Muted).Render("✎ custom prompt")
+}
+
 // toolResultIcon returns the icon for tool results based on error state.
 func toolResultIcon(isError bool) string {
 	if isError {
@@ -199,11 +241,11 @@ func toolResultIcon(isError bool) string {
 
 // tokenUsageColorAndHint returns the color and hint text for token usage percentage.
 func tokenUsageColorAndHint(percent float64) (lipgloss.TerminalColor, string) {
-	if percent >= autoCompactThreshold {
+	if percent >= core.DefaultCompactThreshold {
 		return kit.CurrentTheme.Error, " ⚠ auto-compact"
 	}
 	if percent >= 85 {
-		return kit.CurrentTheme.Warning, fmt.Sprintf(" (compact at %d%%)", autoCompactThreshold)
+		return kit.CurrentTheme.Warning, fmt.Sprintf(" (compact at %d%%)", core.DefaultCompactThreshold)
 	}
 	if percent >= 70 {
 		return kit.CurrentTheme.Accent, ""
@@ -223,9 +265,9 @@ func RenderTokenWarning(inputTokens, inputLimit int, compactSuppressed bool) str
 		return ""
 	}
 
-	untilCompact := max(int(autoCompactThreshold-percent), 0)
+	untilCompact := max(int(core.DefaultCompactThreshold-percent), 0)
 
-	if percent >= autoCompactThreshold {
+	if percent >= core.DefaultCompactThreshold {
 		style := lipgloss.NewStyle().Foreground(kit.CurrentTheme.Error)
 		return "  " + style.Render(fmt.Sprintf("⚠ Context nearly full (%d%% used) — auto-compact imminent", int(percent)))
 	}
@@ -238,57 +280,70 @@ func RenderTokenWarning(inputTokens, inputLimit int, compactSuppressed bool) str
 }
 
 var (
-	userMsgStyle      = lipgloss.NewStyle()
-	assistantMsgStyle = lipgloss.NewStyle()
+	userMsgStyle            lipgloss.Style
+	assistantMsgStyle       lipgloss.Style
+	InputPromptStyle        lipgloss.Style
+	aiPromptStyle           lipgloss.Style
+	SeparatorStyle          lipgloss.Style
+	ThinkingStyle           lipgloss.Style
+	systemMsgStyle          lipgloss.Style
+	contextNoteStyle        lipgloss.Style
+	toolCallStyle           lipgloss.Style
+	toolResultStyle         lipgloss.Style
+	toolResultExpandedStyle lipgloss.Style
+	agentLabelStyle         lipgloss.Style
+	trackerPendingStyle     lipgloss.Style
+	trackerInProgressStyle  lipgloss.Style
+	trackerCompletedStyle   lipgloss.Style
+	PendingImageStyle       lipgloss.Style
+	SelectedImageStyle      lipgloss.Style
+)
 
-	InputPromptStyle = lipgloss.NewStyle().
-				Foreground(kit.CurrentTheme.Primary).
-				Bold(true)
+func init() { kit.OnThemeChange(initMessageStyles) }
 
+// initMessageStyles builds the message styles from the current theme.
+func initMessageStyles() {
+	userMsgStyle = lipgloss.NewStyle()
+	assistantMsgStyle = lipgloss.NewStyle()
+	InputPromptStyle = lipgloss.NewStyle().
+		Foreground(kit.CurrentTheme.Primary).
+		Bold(true)
 	aiPromptStyle = lipgloss.NewStyle().
-			Foreground(kit.CurrentTheme.AI).
-			Bold(true)
-
+		Foreground(kit.CurrentTheme.AI).
+		Bold(true)
 	SeparatorStyle = lipgloss.NewStyle().
-			Foreground(kit.CurrentTheme.Separator)
-
+		Foreground(kit.CurrentTheme.Separator)
 	ThinkingStyle = lipgloss.NewStyle().
-			Foreground(kit.CurrentTheme.Muted)
-
+		Foreground(kit.CurrentTheme.Muted)
 	systemMsgStyle = lipgloss.NewStyle().
-			Foreground(kit.CurrentTheme.TextDim).
-			PaddingLeft(2)
-
+		Foreground(kit.CurrentTheme.TextDim).
+		PaddingLeft(2)
+	contextNoteStyle = lipgloss.NewStyle().
+		Foreground(kit.CurrentTheme.Accent).
+		PaddingLeft(2)
 	toolCallStyle = lipgloss.NewStyle().
-			Foreground(kit.CurrentTheme.Text)
-
+		Foreground(kit.CurrentTheme.Text)
 	toolResultStyle = toolCallStyle
-
 	toolResultExpandedStyle = lipgloss.NewStyle().
-				Foreground(kit.CurrentTheme.TextDim).
-				PaddingLeft(4)
-
+		Foreground(kit.CurrentTheme.TextDim).
+		PaddingLeft(4)
 	agentLabelStyle = lipgloss.NewStyle().
-			Foreground(kit.CurrentTheme.Success)
-
+		Foreground(kit.CurrentTheme.Success)
 	trackerPendingStyle = lipgloss.NewStyle().
-				Foreground(kit.CurrentTheme.Muted)
-
+		Foreground(kit.CurrentTheme.Muted)
 	trackerInProgressStyle = lipgloss.NewStyle().
-				Foreground(kit.CurrentTheme.Primary).
-				Bold(true)
-
+		Foreground(kit.CurrentTheme.Primary).
+		Bold(true)
 	trackerCompletedStyle = lipgloss.NewStyle().
-				Foreground(kit.CurrentTheme.Success)
-
+		Foreground(kit.CurrentTheme.Success)
 	PendingImageStyle = lipgloss.NewStyle().
-				Foreground(kit.CurrentTheme.Primary)
-
+		Foreground(kit.CurrentTheme.Primary)
 	SelectedImageStyle = lipgloss.NewStyle().
-				Foreground(kit.CurrentTheme.TextBright).
-				Background(kit.CurrentTheme.Primary).
-				Bold(true)
-)
+		Foreground(kit.CurrentTheme.TextBright).
+		Background(kit.CurrentTheme.Primary).
+		Bold(true)
+}
+
 var inlineImageTokenPattern = regexp.MustCompile(`\[Image #\d+\]`)
 
 // RenderUserMessage renders a user message with prompt and optional images.
@@ -336,6 +391,9 @@ func styleInlineImageTokens(content string) string {
 type AssistantParams struct {
 	Content           string
 	Thinking          string
+	ThinkingCollapsed bool // show a one-line summary instead of the reasoning
+	ThinkingDuration  time.Duration
+	Citations         []core.Citation
 	ToolCalls         []core.ToolCall
 	ToolCallsExpanded bool
 	StreamActive      bool
@@ -344,6 +402,9 @@ type AssistantParams struct {
 	MDRenderer        *MDRenderer
 	Width             int
 	ExecutingTool     string
+	StreamingTool     string // tool call whose input is still streaming in
+	ToolInputSize     int    // bytes of StreamingTool's input so far
+	Notice            string // provider status, e.g. a retry, shown while waiting for output
 }
 
 // RenderAssistantMessage renders an assistant message with thinking, content, and tool calls.
@@ -354,7 +415,9 @@ func RenderAssistantMessage(params AssistantParams) string {
 		aiIcon = aiPromptStyle.Render(params.SpinnerView + " ")
 	}
 
-	if params.Thinking != "" {
+	if params.Thinking != "" && params.ThinkingCollapsed {
+		sb.WriteString(ThinkingStyle.Render(formatCollapsedThinking(params.Thinking)) + "\n\n")
+	} else if params.Thinking != "" {
 		wrapWidth := max(params.Width-2, minWrapWidth)
 		wrapped := lipgloss.NewStyle().Width(wrapWidth).Render(params.Thinking)
 		var lines []string
@@ -369,21 +432,74 @@ func RenderAssistantMessage(params AssistantParams) string {
 		thinkingIcon := ThinkingStyle.Render("✦ ")
 		sb.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, thinkingIcon, strings.Join(lines, "\n")) + "\n\n")
 	}
+	if params.ThinkingDuration > 0 {
+		sb.WriteString(ThinkingStyle.Render(formatThinkingDuration(params.ThinkingDuration)) + "\n")
+	}
 
 	content := formatAssistantContent(params)
 	if content != "" {
 		sb.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, aiIcon, content) + "\n")
 	}
+	if len(params.Citations) > 0 && !(params.StreamActive && params.IsLast) {
+		sb.WriteString(formatCitations(params.Citations))
+	}
 
 	return sb.String()
 }
 
+// formatCitations renders the numbered "Sources" list shown after an answer.
+func formatCitations(citations []core.Citation) string {
+	var sb strings.Builder
+	sb.WriteString(ThinkingStyle.Render("  Sources:") + "\n")
+	for i, c := range citations {
+		line := fmt.Sprintf("    [%d] %s", i+1, c.URL)
+		if c.Title != "" && c.Title != c.URL {
+			line = fmt.Sprintf("    [%d] %s — %s", i+1, c.Title, c.URL)
+		}
+		sb.WriteString(ThinkingStyle.Render(line) + "\n")
+	}
+	return sb.String()
+}
+
+// formatCollapsedThinking renders the one-line stand-in for collapsed reasoning.
+func formatCollapsedThinking(thinking string) string {
+	lines := 0
+	for _, line := range strings.Split(thinking, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines++
+		}
+	}
+	unit := "lines"
+	if lines == 1 {
+		unit = "line"
+	}
+	return fmt.Sprintf("✦ Thinking · %d %s (alt+4 to expand)", lines, unit)
+}
+
+// formatThinkingDuration renders the "Thought for 4.2s" line shown above an answer.
+func formatThinkingDuration(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("Thought for %.1fs", d.Seconds())
+	}
+	return fmt.Sprintf("Thought for %dm %ds", int(d.Minutes()), int(d.Seconds())%60)
+}
+
 // formatAssistantContent formats the assistant message content based on streaming state.
 func formatAssistantContent(params AssistantParams) string {
+	if params.StreamActive && params.IsLast && params.StreamingTool != "" && len(params.ToolCalls) == 0 {
+		progress := ThinkingStyle.Render(formatToolArgsProgress(params.StreamingTool, params.ToolInputSize))
+		if params.Content == "" {
+			return progress
+		}
+		return assistantMsgStyle.Render(params.Content) + "\n" + progress
+	}
 	if params.Content == "" && len(params.ToolCalls) == 0 && params.StreamActive && params.Thinking == "" {
 		if params.ExecutingTool != "" {
 			return ThinkingStyle.Render(getToolExecutionDesc(params.ExecutingTool))
 		}
+		if params.Notice != "" && params.IsLast {
+			return ThinkingStyle.Render(params.Notice)
+		}
 		return ThinkingStyle.Render("Thinking...")
 	}
 
@@ -402,6 +518,16 @@ func formatAssistantContent(params AssistantParams) string {
 	return params.Content
 }
 
+// formatToolArgsProgress renders the line shown while a tool call's input
+// streams in, such as "⚡Edit (building args... 1.2 KB)". Only the size of
+// the partial input is shown; it is not valid JSON until the call is done.
+func formatToolArgsProgress(toolName string, size int) string {
+	if size == 0 {
+		return fmt.Sprintf("⚡%s (building args...)", toolName)
+	}
+	return fmt.Sprintf("⚡%s (building args... %s)", toolName, toolresult.FormatSize(int64(size)))
+}
+
 // renderMarkdownContent renders content through the markdown renderer.
 func renderMarkdownContent(mdRenderer *MDRenderer, content string) string {
 	rendered, err := mdRenderer.Render(content)
@@ -418,7 +544,7 @@ func getToolExecutionDesc(toolName string) string {
 		return "Reading file..."
 	case "Write":
 		return "Writing file..."
-	case "Edit":
+	case "Edit", "MultiEdit":
 		return "Editing file..."
 	case "Bash":
 		return "Executing command..."
@@ -444,6 +570,26 @@ func RenderSystemMessage(content string) string {
 	return systemMsgStyle.Render(content) + "\n"
 }
 
+// RenderContextNote renders a model-visible context note added via /note.
+func RenderContextNote(content string) string {
+	return contextNoteStyle.Render("📌 "+content) + "\n"
+}
+
+// renderFencedNote renders a notice or context note made of a heading line
+// followed by a fenced code block, such as /diff output, so the block gets
+// markdown highlighting. It reports false for any other content.
+func renderFencedNote(style lipgloss.Style, icon, content string, md *MDRenderer) (string, bool) {
+	heading, body, ok := strings.Cut(content, "\n")
+	if !ok || md == nil || !strings.HasPrefix(body, "```") {
+		return "", false
+	}
+	rendered, err := md.Render(body)
+	if err != nil {
+		return "", false
+	}
+	return style.Render(icon+heading) + "\n" + rendered + "\n", true
+}
+
 // ToolCallsParams holds the parameters for rendering tool calls.
 type ToolCallsParams struct {
 	ToolCalls         []core.ToolCall
@@ -581,28 +727,35 @@ type QueuePreviewItem struct {
 }
 
 var (
-	queueBadgeStyle = lipgloss.NewStyle().
-			Foreground(kit.CurrentTheme.Accent).
-			Bold(true)
+	queueBadgeStyle           lipgloss.Style
+	queueContentStyle         lipgloss.Style
+	queueSelectedBadgeStyle   lipgloss.Style
+	queueSelectedContentStyle lipgloss.Style
+	queueOverflowStyle        lipgloss.Style
+	queueWaitingStyle         lipgloss.Style
+)
 
-	queueContentStyle = lipgloss.NewStyle().
-				Foreground(kit.CurrentTheme.TextDim)
+func init() { kit.OnThemeChange(initQueueStyles) }
 
+// initQueueStyles builds the queue preview styles from the current theme.
+func initQueueStyles() {
+	queueBadgeStyle = lipgloss.NewStyle().
+		Foreground(kit.CurrentTheme.Accent).
+		Bold(true)
+	queueContentStyle = lipgloss.NewStyle().
+		Foreground(kit.CurrentTheme.TextDim)
 	queueSelectedBadgeStyle = lipgloss.NewStyle().
-				Foreground(kit.CurrentTheme.TextBright).
-				Bold(true)
-
+		Foreground(kit.CurrentTheme.TextBright).
+		Bold(true)
 	queueSelectedContentStyle = lipgloss.NewStyle().
-					Foreground(kit.CurrentTheme.Text)
-
+		Foreground(kit.CurrentTheme.Text)
 	queueOverflowStyle = lipgloss.NewStyle().
-				Foreground(kit.CurrentTheme.Muted).
-				Italic(true)
-
+		Foreground(kit.CurrentTheme.Muted).
+		Italic(true)
 	queueWaitingStyle = lipgloss.NewStyle().
-				Foreground(kit.CurrentTheme.Muted).
-				Italic(true)
-)
+		Foreground(kit.CurrentTheme.Muted).
+		Italic(true)
+}
 
 // RenderQueuePreview renders queued input items above the input area.
 // selectedIdx is the currently selected item index (-1 = none).
@@ -671,6 +824,17 @@ func renderWaitingBadge(count int) string {
 	return queueWaitingStyle.Render(fmt.Sprintf(" [%d waiting]", count))
 }
 
+func renderJobsBadge(count int) string {
+	switch count {
+	case 0:
+		return ""
+	case 1:
+		return queueBadgeStyle.Render(" [1 job running · /jobs]")
+	default:
+		return queueBadgeStyle.Render(fmt.Sprintf(" [%d jobs running · /jobs]", count))
+	}
+}
+
 func truncateQueueContent(s string, maxLen int) string {
 	s = strings.ReplaceAll(s, "\n", " ")
 	s = strings.Join(strings.Fields(s), " ")
@@ -3,6 +3,7 @@ package conv
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/yanmxa/gencode/internal/core"
 	"github.com/yanmxa/gencode/internal/llm"
@@ -95,7 +96,7 @@ func TestRenderModeStatusShowsTokenUsageWithModel(t *testing.T) {
 		InputTokens:      1234,
 		OutputTokens:     56,
 		InputLimit:       10000,
-		ConversationCost: llm.Money{Amount: 0.1234, Currency: llm.CurrencyCNY},
+		ConversationCost: []llm.Money{{Amount: 0.1234, Currency: llm.CurrencyCNY}},
 		Width:            100,
 	})
 
@@ -114,6 +115,17 @@ func TestRenderModeStatusShowsTokenUsageWithModel(t *testing.T) {
 	}
 }
 
+func TestRenderModeStatusShowsCustomPromptIndicator(t *testing.T) {
+	params := OperationModeParams{ModelName: "m", Width: 100}
+	if rendered := RenderModeStatus(params); strings.Contains(rendered, "custom prompt") {
+		t.Fatalf("RenderModeStatus() = %q, should not show the indicator by default", rendered)
+	}
+	params.CustomPrompt = true
+	if rendered := RenderModeStatus(params); !strings.Contains(rendered, "custom prompt") {
+		t.Fatalf("RenderModeStatus() = %q, want custom prompt indicator", rendered)
+	}
+}
+
 func TestRenderModeStatusKeepsContextDisplayOnRightOnly(t *testing.T) {
 	rendered := RenderModeStatus(OperationModeParams{
 		ModelName:      "kimi-k2.6",
@@ -338,3 +350,121 @@ func Test_renderTaskOutputResultInlineShowsErrorText(t *testing.T) {
 		t.Fatalf("expected TaskOutput error text, got %q", rendered)
 	}
 }
+
+func TestRenderAssistantMessageShowsThinkingDuration(t *testing.T) {
+	got := RenderAssistantMessage(AssistantParams{
+		Content:          "answer",
+		Thinking:         "pondering",
+		ThinkingDuration: 4200 * time.Millisecond,
+		Width:            80,
+	})
+	thought := strings.Index(got, "Thought for 4.2s")
+	if thought < 0 {
+		t.Fatalf("expected thinking duration line, got:\n%s", got)
+	}
+	if answer := strings.Index(got, "answer"); answer < thought {
+		t.Errorf("thinking duration should render above the answer, got:\n%s", got)
+	}
+
+	if got := formatThinkingDuration(75 * time.Second); got != "Thought for 1m 15s" {
+		t.Errorf("formatThinkingDuration(75s) = %q", got)
+	}
+}
+
+func TestRenderAssistantMessageCollapsesThinking(t *testing.T) {
+	params := AssistantParams{
+		Content:           "answer",
+		Thinking:          "step one\n\nstep two",
+		ThinkingCollapsed: true,
+		Width:             80,
+	}
+	got := RenderAssistantMessage(params)
+	if !strings.Contains(got, "Thinking · 2 lines (alt+4 to expand)") || strings.Contains(got, "step one") {
+		t.Fatalf("collapsed thinking should be a one-line summary, got:\n%s", got)
+	}
+
+	params.ThinkingCollapsed = false
+	if got := RenderAssistantMessage(params); !strings.Contains(got, "step one") {
+		t.Errorf("expanded thinking should show the reasoning, got:\n%s", got)
+	}
+}
+
+func TestRenderSingleMessageHidesThinking(t *testing.T) {
+	params := MessageRenderParams{
+		Messages:     []core.ChatMessage{{Role: core.RoleAssistant, Thinking: "secret plan", Content: "answer"}},
+		Width:        80,
+		HideThinking: true,
+	}
+	got := RenderSingleMessage(params, 0)
+	if strings.Contains(got, "secret plan") || strings.Contains(got, "Thinking ·") {
+		t.Errorf("showThinking: false should hide reasoning, got:\n%s", got)
+	}
+	if !strings.Contains(got, "answer") {
+		t.Errorf("answer missing, got:\n%s", got)
+	}
+}
+
+func TestRenderAssistantMessageListsCitations(t *testing.T) {
+	params := AssistantParams{
+		Content: "answer",
+		Citations: []core.Citation{
+			{Title: "Go", URL: "https://go.dev"},
+			{URL: "https://pkg.go.dev"},
+		},
+		Width: 80,
+	}
+	got := RenderAssistantMessage(params)
+	for _, want := range []string{"Sources:", "[1] Go — https://go.dev", "[2] https://pkg.go.dev"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+	if strings.Index(got, "Sources:") < strings.Index(got, "answer") {
+		t.Errorf("sources should render after the answer, got:\n%s", got)
+	}
+
+	params.StreamActive, params.IsLast = true, true
+	if got := RenderAssistantMessage(params); strings.Contains(got, "Sources:") {
+		t.Errorf("sources should wait for the answer to finish, got:\n%s", got)
+	}
+}
+
+func TestRenderAssistantMessageShowsStreamNotice(t *testing.T) {
+	params := AssistantParams{
+		StreamActive: true,
+		IsLast:       true,
+		Notice:       "Retrying (2/3) in 2s: overloaded...",
+		Width:        80,
+	}
+	if got := RenderAssistantMessage(params); !strings.Contains(got, "Retrying (2/3)") || strings.Contains(got, "Thinking...") {
+		t.Errorf("expected the notice in place of Thinking..., got:\n%s", got)
+	}
+
+	params.Content = "answer"
+	if got := RenderAssistantMessage(params); strings.Contains(got, "Retrying") {
+		t.Errorf("notice should give way to output, got:\n%s", got)
+	}
+}
+
+func TestRenderAssistantMessageShowsToolArgsProgress(t *testing.T) {
+	params := AssistantParams{
+		StreamActive:  true,
+		IsLast:        true,
+		StreamingTool: "Edit",
+		ToolInputSize: 2048,
+		Width:         80,
+	}
+	if got := RenderAssistantMessage(params); !strings.Contains(got, "⚡Edit (building args... 2.0 KB)") || strings.Contains(got, "Thinking...") {
+		t.Errorf("expected the building-args line, got:\n%s", got)
+	}
+
+	params.Content = "Let me fix that."
+	if got := RenderAssistantMessage(params); !strings.Contains(got, "Let me fix that.") || !strings.Contains(got, "⚡Edit") {
+		t.Errorf("expected the text followed by the building-args line, got:\n%s", got)
+	}
+
+	params.ToolCalls = []core.ToolCall{{ID: "1", Name: "Edit", Input: `{"file_path":"a.go"}`}}
+	if got := RenderAssistantMessage(params); strings.Contains(got, "building args") {
+		t.Errorf("finished tool calls should replace the progress line, got:\n%s", got)
+	}
+}
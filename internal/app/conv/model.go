@@ -13,6 +13,10 @@ type OutputModel struct {
 	TaskProgress map[int][]string
 	ProgressHub  *ProgressHub
 	ShowTasks    bool
+	// TasksCollapsed shrinks the task panel to its header and status counts.
+	TasksCollapsed bool
+	// ThinkingCollapsed shrinks every reasoning block to a one-line summary.
+	ThinkingCollapsed bool
 }
 
 type Model struct {
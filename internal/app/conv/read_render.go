@@ -0,0 +1,99 @@
+package conv
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// readHighlightMaxLines caps how many lines of an expanded Read result are
+// highlighted, matching the Read tool's own line limit.
+const readHighlightMaxLines = 2000
+
+// readContentIndent lines highlighted Read output up with other expanded
+// tool results.
+const readContentIndent = "    "
+
+// renderReadResultHighlighted renders an expanded Read result as a fenced
+// code block so glamour highlights it by the file's language. ok is false
+// when the result can't be highlighted (unknown file, or content that would
+// break out of the fence) and the caller should fall back to plain text.
+func renderReadResultHighlighted(data ToolResultData, mdRenderer *MDRenderer) (string, bool) {
+	if mdRenderer == nil {
+		return "", false
+	}
+	var params struct {
+		FilePath string `json:"file_path"`
+	}
+	if err := json.Unmarshal([]byte(data.ToolInput), &params); err != nil || params.FilePath == "" {
+		return "", false
+	}
+
+	header, code, ok := splitReadContent(data.Content)
+	if !ok {
+		return "", false
+	}
+	hidden := 0
+	if len(code) > readHighlightMaxLines {
+		hidden = len(code) - readHighlightMaxLines
+		code = code[:readHighlightMaxLines]
+	}
+
+	source := "```" + readFenceLanguage(params.FilePath) + "\n" + strings.Join(code, "\n") + "\n```"
+	width := mdRenderer.width - len(readContentIndent)
+	rendered, err := NewMDRenderer(width).Render(source)
+	if err != nil {
+		return "", false
+	}
+
+	var sb strings.Builder
+	if header != "" {
+		sb.WriteString(toolResultExpandedStyle.Render(header) + "\n")
+	}
+	clip := lipgloss.NewStyle().MaxWidth(width)
+	for line := range strings.SplitSeq(rendered, "\n") {
+		sb.WriteString(readContentIndent + clip.Render(line) + "\n")
+	}
+	if hidden > 0 {
+		sb.WriteString(truncatedStyle.Render(fmt.Sprintf("%s... %d more lines", readContentIndent, hidden)) + "\n")
+	}
+	return sb.String(), true
+}
+
+// splitReadContent undoes the Read tool's "%6d\t" line-number gutter,
+// returning the optional range header and the bare file lines.
+func splitReadContent(content string) (header string, code []string, ok bool) {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "[Showing lines ") {
+		header, lines = lines[0], lines[1:]
+	}
+	if len(lines) == 0 {
+		return "", nil, false
+	}
+	code = make([]string, len(lines))
+	for i, line := range lines {
+		num, text, found := strings.Cut(line, "\t")
+		if !found || strings.TrimSpace(num) == "" || strings.Trim(num, " 0123456789") != "" {
+			return "", nil, false
+		}
+		trimmed := strings.TrimSpace(text)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			return "", nil, false
+		}
+		code[i] = text
+	}
+	return header, code, true
+}
+
+// readFenceLanguage picks the fence info string for path. Chroma resolves
+// lexers by extension as well as by name, so the bare extension is enough;
+// extensionless files such as Makefile fall back to their base name.
+func readFenceLanguage(path string) string {
+	if ext := strings.TrimPrefix(filepath.Ext(path), "."); ext != "" {
+		return strings.ToLower(ext)
+	}
+	return strings.ToLower(filepath.Base(path))
+}
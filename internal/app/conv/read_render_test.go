@@ -0,0 +1,46 @@
+package conv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitReadContent(t *testing.T) {
+	header, code, ok := splitReadContent("[Showing lines 9-10 of 40]\n     9\tfunc a() {\n    10\t\treturn\n")
+	if !ok || header != "[Showing lines 9-10 of 40]" || strings.Join(code, "|") != "func a() {|\treturn" {
+		t.Errorf("got %q, %q, %v", header, code, ok)
+	}
+
+	for _, content := range []string{"", "no gutter here", "     1\t```go"} {
+		if _, _, ok := splitReadContent(content); ok {
+			t.Errorf("%q should not be highlighted", content)
+		}
+	}
+}
+
+func TestRenderReadResultHighlighted(t *testing.T) {
+	data := ToolResultData{
+		ToolName:  "Read",
+		Content:   "     1\tpackage main\n",
+		Expanded:  true,
+		ToolInput: `{"file_path":"/tmp/main.go"}`,
+	}
+	out := stripANSI(RenderToolResultInline(data, NewMDRenderer(80)))
+	if !strings.Contains(out, "package main") || strings.Contains(out, "1    package") {
+		t.Errorf("expected highlighted code without the line gutter:\n%s", out)
+	}
+
+	data.ToolInput = ""
+	out = stripANSI(RenderToolResultInline(data, NewMDRenderer(80)))
+	if !strings.Contains(out, "1    package main") {
+		t.Errorf("without a file path the plain view should be kept:\n%s", out)
+	}
+}
+
+func TestReadFenceLanguage(t *testing.T) {
+	for path, want := range map[string]string{"/a/b.GO": "go", "x.tar.gz": "gz", "/src/Makefile": "makefile"} {
+		if got := readFenceLanguage(path); got != want {
+			t.Errorf("readFenceLanguage(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -15,40 +15,47 @@ import (
 )
 
 var (
-	headerStyle = lipgloss.NewStyle().
-			BorderStyle(lipgloss.RoundedBorder()).
-			BorderForeground(kit.CurrentTheme.Border).
-			Padding(0, 1)
+	headerStyle         lipgloss.Style
+	headerTitleStyle    lipgloss.Style
+	headerSubtitleStyle lipgloss.Style
+	headerMetaStyle     lipgloss.Style
+	lineNumberStyle     lipgloss.Style
+	matchStyle          lipgloss.Style
+	filePathStyle       lipgloss.Style
+	truncatedStyle      lipgloss.Style
+	errorStyle          lipgloss.Style
+)
 
-	headerTitleStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(kit.CurrentTheme.Primary)
+func init() { kit.OnThemeChange(initToolRenderStyles) }
 
+// initToolRenderStyles builds the tool result styles from the current theme.
+func initToolRenderStyles() {
+	headerStyle = lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(kit.CurrentTheme.Border).
+		Padding(0, 1)
+	headerTitleStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(kit.CurrentTheme.Primary)
 	headerSubtitleStyle = lipgloss.NewStyle().
-				Foreground(kit.CurrentTheme.Text)
-
+		Foreground(kit.CurrentTheme.Text)
 	headerMetaStyle = lipgloss.NewStyle().
-			Foreground(kit.CurrentTheme.Muted)
-
+		Foreground(kit.CurrentTheme.Muted)
 	lineNumberStyle = lipgloss.NewStyle().
-			Foreground(kit.CurrentTheme.Muted).
-			Width(5).
-			Align(lipgloss.Right)
-
+		Foreground(kit.CurrentTheme.Muted).
+		Width(5).
+		Align(lipgloss.Right)
 	matchStyle = lipgloss.NewStyle().
-			Foreground(kit.CurrentTheme.Warning).
-			Bold(true)
-
+		Foreground(kit.CurrentTheme.Warning).
+		Bold(true)
 	filePathStyle = lipgloss.NewStyle().
-			Foreground(kit.CurrentTheme.Primary)
-
+		Foreground(kit.CurrentTheme.Primary)
 	truncatedStyle = lipgloss.NewStyle().
-			Foreground(kit.CurrentTheme.Muted).
-			Italic(true)
-
+		Foreground(kit.CurrentTheme.Muted).
+		Italic(true)
 	errorStyle = lipgloss.NewStyle().
-			Foreground(kit.CurrentTheme.Error)
-)
+		Foreground(kit.CurrentTheme.Error)
+}
 
 // RenderToolResult renders a complete tool result with header and content.
 func RenderToolResult(result toolresult.ToolResult, width int) string {
@@ -123,6 +130,13 @@ func RenderToolResultInline(data ToolResultData, mdRenderer *MDRenderer) string
 	summary := toolResultStyle.Render(fmt.Sprintf("  %s  %s → %s", icon, toolName, sizeInfo))
 	sb.WriteString(summary + "\n")
 
+	if toolName == "Read" && data.Expanded && !data.IsError {
+		if rendered, ok := renderReadResultHighlighted(data, mdRenderer); ok {
+			sb.WriteString(rendered)
+			return sb.String()
+		}
+	}
+
 	if data.Expanded || data.IsError {
 		for line := range strings.SplitSeq(data.Content, "\n") {
 			sb.WriteString(toolResultExpandedStyle.Render(line) + "\n")
@@ -778,7 +792,7 @@ func formatToolResultSize(toolName, content string) string {
 	switch toolName {
 	case "WebFetch":
 		return toolresult.FormatSize(int64(len(content)))
-	case "Write", "Edit":
+	case "Write", "Edit", "MultiEdit":
 		return extractParenContent(content, "completed")
 	default:
 		return formatLineCount(content)
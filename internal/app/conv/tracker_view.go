@@ -21,6 +21,8 @@ type TrackerListParams struct {
 	Width        int
 	SpinnerView  string
 	Blockers     func(taskID string) []string
+	Collapsed    bool      // header and status counts only
+	Now          time.Time // render clock for the blink and elapsed time; zero = time.Now()
 }
 
 // RenderTrackerList renders a compact task list above the input area.
@@ -45,9 +47,17 @@ func RenderTrackerList(params TrackerListParams) string {
 		pct = completed * 100 / len(params.Tasks)
 	}
 	sb.WriteString("  " + headerStyle.Render("Tasks") + " " + mutedStyle.Render(fmt.Sprintf("(%d%%)", pct)))
+	if params.Collapsed {
+		sb.WriteString("  " + renderTaskStatusSummary(counts) + "\n")
+		return sb.String()
+	}
 	sb.WriteString("\n")
 
 	idWidth := taskIDWidth(params.Tasks)
+	now := params.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
 
 	sorted := make([]*tracker.Task, 0, len(params.Tasks))
 	var active []*tracker.Task
@@ -67,14 +77,16 @@ func RenderTrackerList(params TrackerListParams) string {
 		if rendered >= maxVisibleTasks && t.Status != tracker.StatusInProgress {
 			continue
 		}
-		sb.WriteString(renderTask(t, params.Width, idWidth, params.Blockers))
+		sb.WriteString(renderTask(t, params.Width, idWidth, params.Blockers, now))
 		rendered++
 	}
 
 	return sb.String()
 }
 
-func renderTask(t *tracker.Task, width, idWidth int, blockers func(string) []string) string {
+// renderTask renders one task line. In-progress icons blink on a half-second
+// clock derived from now.
+func renderTask(t *tracker.Task, width, idWidth int, blockers func(string) []string, now time.Time) string {
 	indent := "  "
 	idTag := fmt.Sprintf("%-*s", idWidth, "#"+t.ID)
 	maxTextLen := width - len(indent) - idWidth - 8
@@ -100,12 +112,12 @@ func renderTask(t *tracker.Task, width, idWidth int, blockers func(string) []str
 		}
 		activeIcon := "●"
 		activeStyle := trackerInProgressStyle
-		if time.Now().UnixNano()/500000000%2 == 0 {
+		if now.UnixNano()/int64(500*time.Millisecond)%2 == 0 {
 			activeIcon = "◌"
 			activeStyle = lipgloss.NewStyle().Foreground(kit.CurrentTheme.Muted)
 		}
 		detail := ""
-		if elapsed := formatElapsedTime(t.StatusChangedAt); elapsed != "" {
+		if elapsed := formatElapsedTime(t.StatusChangedAt, now); elapsed != "" {
 			detail = mutedStyle.Render(elapsed)
 		}
 		return renderTaskLine(indent, activeStyle.Render(activeIcon), idTag, displayText, detail)
@@ -191,11 +203,11 @@ func taskIDWidth(tasks []*tracker.Task) int {
 	return width
 }
 
-func formatElapsedTime(since time.Time) string {
+func formatElapsedTime(since, now time.Time) string {
 	if since.IsZero() {
 		return ""
 	}
-	d := time.Since(since)
+	d := now.Sub(since)
 	if d < time.Second {
 		return ""
 	}
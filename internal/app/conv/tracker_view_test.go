@@ -3,6 +3,7 @@ package conv
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/yanmxa/gencode/internal/task/tracker"
 )
@@ -61,12 +62,11 @@ func TestRenderTrackerListShowsTaskStatus(t *testing.T) {
 func TestRenderTaskAnimatesInProgressItem(t *testing.T) {
 	task := &tracker.Task{ID: "1", Subject: "Fix auth module", Status: tracker.StatusInProgress}
 
-	onFrame := stripANSI(renderTask(task, 80, 2, "◐", nil))
-	offFrame := stripANSI(renderTask(task, 80, 2, "◓", nil))
+	// The icon blinks on a half-second clock: even half-seconds are dim.
+	epoch := time.Unix(1_700_000_000, 0)
+	offFrame := stripANSI(renderTask(task, 80, 2, nil, epoch))
+	onFrame := stripANSI(renderTask(task, 80, 2, nil, epoch.Add(500*time.Millisecond)))
 
-	if onFrame == offFrame {
-		t.Fatalf("in-progress task should change across frames:\n%s", onFrame)
-	}
 	if !strings.Contains(onFrame, "●") {
 		t.Fatalf("on frame = %q, want solid active icon", onFrame)
 	}
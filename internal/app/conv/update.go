@@ -2,6 +2,8 @@
 package conv
 
 import (
+	"time"
+
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/yanmxa/gencode/internal/app/kit"
@@ -139,6 +141,9 @@ func applyPreInfer(rt Runtime, m *Model) tea.Cmd {
 	rt.BeginInferTurn()
 	m.Stream.Active = true
 	m.Stream.BuildingTool = ""
+	m.Stream.Notice = ""
+	m.Stream.StreamingTool = ""
+	m.Stream.ToolInput = ""
 	commitCmds := rt.CommitMessages()
 	m.Append(core.ChatMessage{Role: core.RoleAssistant, Content: ""})
 	cmds := append(commitCmds, m.Spinner.Tick)
@@ -153,9 +158,36 @@ func applyChunk(rt Runtime, m *Model, ev core.Event) tea.Cmd {
 	if !ok {
 		return nil
 	}
+	if chunk.Notice != "" {
+		m.Stream.Notice = chunk.Notice
+	} else if chunk.Text != "" || chunk.Thinking != "" || chunk.Fallback != "" || chunk.ToolStart != "" || chunk.Done {
+		m.Stream.Notice = ""
+	}
+	if chunk.ToolStart != "" {
+		m.Stream.StreamingTool = chunk.ToolStart
+		m.Stream.ToolInput = ""
+	}
+	m.Stream.ToolInput += chunk.ToolInput
+	if chunk.Done {
+		m.Stream.StreamingTool = ""
+		m.Stream.ToolInput = ""
+	}
+	if chunk.Fallback != "" {
+		m.AddNoticeBeforeStream(chunk.Fallback)
+	}
+	if chunk.Thinking != "" && m.Stream.ThinkingStart.IsZero() {
+		m.Stream.ThinkingStart = time.Now()
+	}
 	if chunk.Text != "" || chunk.Thinking != "" {
 		m.AppendToLast(chunk.Text, chunk.Thinking)
 	}
+	if (chunk.Text != "" || chunk.Done) && !m.Stream.ThinkingStart.IsZero() {
+		m.SetLastThinkingDuration(time.Since(m.Stream.ThinkingStart))
+		m.Stream.ThinkingStart = time.Time{}
+	}
+	if chunk.Done && chunk.Response != nil && len(chunk.Response.Citations) > 0 {
+		m.SetLastCitations(chunk.Response.Citations)
+	}
 	if chunk.Done && chunk.Response != nil && len(chunk.Response.ToolCalls) == 0 {
 		m.Stream.Active = false
 		commitCmds := rt.CommitMessages()
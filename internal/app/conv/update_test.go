@@ -60,3 +60,49 @@ func TestMarkToolCallCompleteAdvancesAndClearsPendingState(t *testing.T) {
 		t.Fatalf("CurrentIdx = %d, want 0", state.CurrentIdx)
 	}
 }
+
+func TestApplyChunkFallbackAddsNoticeBeforeStreamingMessage(t *testing.T) {
+	m := &Model{ConversationModel: NewConversation()}
+	m.Append(core.ChatMessage{Role: core.RoleUser, Content: "hi"})
+	m.CommittedCount = 1
+	m.Append(core.ChatMessage{Role: core.RoleAssistant})
+	m.Stream.Notice = "Retrying (1/3) in 1s: rate limited..."
+
+	applyChunk(nil, m, core.ChunkEvent("main", core.Chunk{Fallback: "a:x failed (rate limited); switched to b:y"}))
+	applyChunk(nil, m, core.ChunkEvent("main", core.Chunk{Text: "answer"}))
+
+	if m.Stream.Notice != "" {
+		t.Errorf("Stream.Notice = %q, want cleared", m.Stream.Notice)
+	}
+	if len(m.Messages) != 3 {
+		t.Fatalf("messages = %#v, want user, notice, assistant", m.Messages)
+	}
+	if m.Messages[1].Role != core.RoleNotice || m.Messages[1].Content != "a:x failed (rate limited); switched to b:y" {
+		t.Errorf("messages[1] = %#v, want the fallback notice", m.Messages[1])
+	}
+	if m.Messages[2].Role != core.RoleAssistant || m.Messages[2].Content != "answer" {
+		t.Errorf("messages[2] = %#v, want the streamed answer", m.Messages[2])
+	}
+}
+
+func TestApplyChunkAccumulatesStreamingToolInput(t *testing.T) {
+	m := &Model{ConversationModel: NewConversation()}
+	m.Append(core.ChatMessage{Role: core.RoleAssistant})
+
+	applyChunk(nil, m, core.ChunkEvent("main", core.Chunk{ToolStart: "Edit"}))
+	applyChunk(nil, m, core.ChunkEvent("main", core.Chunk{ToolInput: `{"file_path":`}))
+	applyChunk(nil, m, core.ChunkEvent("main", core.Chunk{ToolInput: `"a.go"`}))
+	if m.Stream.StreamingTool != "Edit" || m.Stream.ToolInput != `{"file_path":"a.go"` {
+		t.Fatalf("stream = %q %q, want Edit and the partial input", m.Stream.StreamingTool, m.Stream.ToolInput)
+	}
+
+	applyChunk(nil, m, core.ChunkEvent("main", core.Chunk{ToolStart: "Read"}))
+	if m.Stream.StreamingTool != "Read" || m.Stream.ToolInput != "" {
+		t.Errorf("a new tool call should restart the input, got %q %q", m.Stream.StreamingTool, m.Stream.ToolInput)
+	}
+
+	applyChunk(nil, m, core.ChunkEvent("main", core.Chunk{Done: true}))
+	if m.Stream.StreamingTool != "" || m.Stream.ToolInput != "" {
+		t.Errorf("done should clear the streaming tool, got %q %q", m.Stream.StreamingTool, m.Stream.ToolInput)
+	}
+}
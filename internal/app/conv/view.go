@@ -11,6 +11,9 @@ type MessageRenderParams struct {
 	CommittedCount          int
 	StreamActive            bool
 	BuildingTool            string
+	StreamingTool           string // tool call whose input is streaming in
+	ToolInputSize           int    // bytes of StreamingTool's input so far
+	StreamNotice            string
 	PendingCalls            []core.ToolCall
 	CurrentIdx              int
 	Width                   int
@@ -19,6 +22,8 @@ type MessageRenderParams struct {
 	TaskProgress            map[int][]string
 	TaskOwnerMap            map[string]string
 	InteractivePromptActive bool
+	ThinkingCollapsed       bool // reasoning shown as a one-line summary
+	HideThinking            bool // reasoning not shown at all (showThinking: false)
 }
 
 // BuildSkipIndices returns a set of message indices that should be skipped during rendering.
@@ -98,7 +103,19 @@ func RenderMessageAt(p MessageRenderParams, idx int, isStreaming bool) string {
 			sb.WriteString(RenderUserMessage(msg.Content, msg.DisplayContent, msg.Images, p.MDRenderer, p.Width))
 		}
 	case core.RoleNotice:
-		sb.WriteString(RenderSystemMessage(msg.Content))
+		if isDiffNotice(msg) {
+			sb.WriteString(RenderDiffNotice(msg.DisplayContent, msg.Content, msg.Expanded))
+		} else if rendered, ok := renderFencedNote(systemMsgStyle, "", msg.Content, p.MDRenderer); ok {
+			sb.WriteString(rendered)
+		} else {
+			sb.WriteString(RenderSystemMessage(msg.Content))
+		}
+	case core.RoleContext:
+		if rendered, ok := renderFencedNote(contextNoteStyle, "📌 ", msg.Content, p.MDRenderer); ok {
+			sb.WriteString(rendered)
+		} else {
+			sb.WriteString(RenderContextNote(msg.Content))
+		}
 	case core.RoleAssistant:
 		sb.WriteString(renderAssistantWithTools(p, msg, idx, isStreaming))
 	}
@@ -107,16 +124,26 @@ func RenderMessageAt(p MessageRenderParams, idx int, isStreaming bool) string {
 }
 
 func renderAssistantWithTools(p MessageRenderParams, msg core.ChatMessage, idx int, isLast bool) string {
+	thinking := msg.Thinking
+	if p.HideThinking {
+		thinking = ""
+	}
 	base := RenderAssistantMessage(AssistantParams{
-		Content:       msg.Content,
-		Thinking:      msg.Thinking,
-		ToolCalls:     msg.ToolCalls,
-		StreamActive:  p.StreamActive,
-		IsLast:        isLast,
-		SpinnerView:   p.SpinnerView,
-		MDRenderer:    p.MDRenderer,
-		Width:         p.Width,
-		ExecutingTool: p.BuildingTool,
+		Content:           msg.Content,
+		Thinking:          thinking,
+		ThinkingCollapsed: p.ThinkingCollapsed,
+		ThinkingDuration:  msg.ThinkingDuration,
+		Citations:         msg.Citations,
+		ToolCalls:         msg.ToolCalls,
+		StreamActive:      p.StreamActive,
+		IsLast:            isLast,
+		SpinnerView:       p.SpinnerView,
+		MDRenderer:        p.MDRenderer,
+		Width:             p.Width,
+		ExecutingTool:     p.BuildingTool,
+		StreamingTool:     p.StreamingTool,
+		ToolInputSize:     p.ToolInputSize,
+		Notice:            p.StreamNotice,
 	})
 
 	if len(msg.ToolCalls) == 0 {
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yanmxa/gencode/internal/app/input"
+	"github.com/yanmxa/gencode/internal/app/kit"
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+// editLastMessage loads the last prompt the user typed back into the input.
+// Submitting it replaces that prompt and everything after it.
+func (m *model) editLastMessage() tea.Cmd {
+	return m.editMessage(input.LastEditableMessage(m.conv.Messages))
+}
+
+// editMessage loads the prompt at idx back into the input. Nothing changes
+// until it is submitted, so Esc still cancels.
+func (m *model) editMessage(idx int) tea.Cmd {
+	if idx < 0 || idx >= len(m.conv.Messages) {
+		return nil
+	}
+	msg := m.conv.Messages[idx]
+	if len(msg.Images) > 0 {
+		token := m.userInput.Provider.SetStatusMessage("messages with images can't be edited")
+		return kit.StatusTimer(3*time.Second, token)
+	}
+	m.userInput.BeginEdit(idx, msg)
+	return nil
+}
+
+// RewindConversation drops the message at index and everything after it
+// from the conversation, the running agent's history, and the scrollback.
+// The session file follows on the next save. File edits and other tool side
+// effects are not undone.
+func (m *model) RewindConversation(index int) tea.Cmd {
+	if index < 0 || index >= len(m.conv.Messages) || m.conv.Messages[index].Role != core.RoleUser {
+		return nil
+	}
+	m.conv.Messages = m.conv.Messages[:index]
+	m.conv.CommittedCount = min(m.conv.CommittedCount, index)
+	m.conv.PersistedCount = min(m.conv.PersistedCount, index)
+	m.syncAgentMessages()
+	// The context display measured the longer conversation.
+	m.env.ResetTokens()
+	return m.reflowScrollback()
+}
+
+// syncAgentMessages replaces the running agent's history with the
+// conversation, after it was changed outside an agent turn.
+func (m *model) syncAgentMessages() {
+	m.services.Agent.SetMessages(m.conv.ConvertToProvider())
+}
+
+// retryLastPrompt drops the last prompt the user typed and everything after
+// it, then sends the prompt again, images included, for a fresh answer.
+func (m *model) retryLastPrompt() tea.Cmd {
+	idx := input.LastEditableMessage(m.conv.Messages)
+	if m.conv.Stream.Active || idx < 0 {
+		return nil
+	}
+	prompt := m.conv.Messages[idx]
+	rewind := m.RewindConversation(idx)
+	m.conv.Append(prompt)
+	return tea.Sequence(rewind, m.StartProviderTurn(prompt.Content))
+}
+
+// renderEditHint shows that the input replaces an earlier message.
+func (m model) renderEditHint() string {
+	if !m.userInput.Edit.Active {
+		return ""
+	}
+	if m.userInput.Edit.Index != input.LastEditableMessage(m.conv.Messages) {
+		return ghostTextStyle.Render("  ✎ editing an earlier message · enter to resend and drop the messages after it · esc to cancel")
+	}
+	return ghostTextStyle.Render("  ✎ editing last message · enter to resend · esc to cancel")
+}
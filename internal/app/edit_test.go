@@ -0,0 +1,182 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yanmxa/gencode/internal/agent"
+	"github.com/yanmxa/gencode/internal/app/input"
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/task/tracker"
+)
+
+func TestEscEditsLastMessageAndSubmitRewinds(t *testing.T) {
+	agent.Initialize(agent.Options{})
+	t.Cleanup(agent.ResetService)
+	m := &model{}
+	m.services.Agent = agent.Default()
+	m.services.Tracker = tracker.NewStore()
+	m.userInput = input.New("", 80, nil, input.SelectorDeps{})
+	m.conv.Messages = []core.ChatMessage{
+		{Role: core.RoleUser, Content: "first question"},
+		{Role: core.RoleAssistant, Content: "first answer"},
+		{Role: core.RoleUser, Content: "sceond question"},
+		{Role: core.RoleAssistant, ToolCalls: []core.ToolCall{{ID: "1", Name: "Read"}}},
+		{Role: core.RoleUser, ToolResult: &core.ToolResult{ToolCallID: "1"}},
+		{Role: core.RoleAssistant, Content: "second answer"},
+		{Role: core.RoleNotice, Content: "note"},
+	}
+	m.conv.CommittedCount = len(m.conv.Messages)
+	m.conv.PersistedCount = len(m.conv.Messages)
+
+	if _, handled := m.handleInputKey(tea.KeyMsg{Type: tea.KeyEsc}); !handled {
+		t.Fatal("Esc was not handled")
+	}
+	if !m.userInput.Edit.Active || m.userInput.Edit.Index != 2 {
+		t.Fatalf("Edit = %+v, want the second question", m.userInput.Edit)
+	}
+	if got := m.userInput.Textarea.Value(); got != "sceond question" {
+		t.Fatalf("input = %q, want the message text", got)
+	}
+
+	if cmd := m.RewindConversation(m.userInput.Edit.Index); cmd == nil {
+		t.Fatal("RewindConversation should reflow the scrollback")
+	}
+	if len(m.conv.Messages) != 2 || m.conv.Messages[1].Content != "first answer" {
+		t.Fatalf("messages after rewind = %+v", m.conv.Messages)
+	}
+	if m.conv.CommittedCount != 2 || m.conv.PersistedCount != 2 {
+		t.Errorf("committed/persisted = %d/%d, want 2/2", m.conv.CommittedCount, m.conv.PersistedCount)
+	}
+
+	m.handleInputKey(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.userInput.Edit.Active {
+		t.Fatal("a second Esc should start editing, not leave a stale edit")
+	}
+}
+
+func TestEscCancelsEdit(t *testing.T) {
+	m := &model{}
+	m.userInput = input.New("", 80, nil, input.SelectorDeps{})
+	m.conv.Messages = []core.ChatMessage{{Role: core.RoleUser, Content: "hello"}}
+
+	m.handleInputKey(tea.KeyMsg{Type: tea.KeyEsc})
+	if !m.userInput.Edit.Active {
+		t.Fatal("Esc on an empty prompt should edit the last message")
+	}
+	m.handleInputKey(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.userInput.Edit.Active || m.userInput.Textarea.Value() != "" {
+		t.Fatal("Esc while editing should cancel the edit and clear the input")
+	}
+	if len(m.conv.Messages) != 1 {
+		t.Fatal("cancelling must not change the conversation")
+	}
+}
+
+func TestRewindIgnoresStaleIndex(t *testing.T) {
+	m := &model{}
+	m.conv.Messages = []core.ChatMessage{{Role: core.RoleUser, Content: "hi"}, {Role: core.RoleAssistant, Content: "hello"}}
+	if m.RewindConversation(1) != nil || m.RewindConversation(5) != nil || len(m.conv.Messages) != 2 {
+		t.Fatal("rewind must only drop from a user message")
+	}
+}
+
+func TestRetryResendsLastPrompt(t *testing.T) {
+	agent.Initialize(agent.Options{})
+	t.Cleanup(agent.ResetService)
+	m := &model{}
+	m.services.Agent = agent.Default()
+	m.services.Tracker = tracker.NewStore()
+	m.userInput = input.New("", 80, nil, input.SelectorDeps{})
+	img := core.Image{MediaType: "image/png", Data: "aGk="}
+	m.conv.Messages = []core.ChatMessage{
+		{Role: core.RoleUser, Content: "first question"},
+		{Role: core.RoleAssistant, Content: "first answer"},
+		{Role: core.RoleUser, Content: "second question", Images: []core.Image{img}},
+		{Role: core.RoleAssistant, ToolCalls: []core.ToolCall{{ID: "1", Name: "Read"}}},
+		{Role: core.RoleUser, ToolResult: &core.ToolResult{ToolCallID: "1"}},
+		{Role: core.RoleAssistant, Content: "second answer"},
+	}
+	m.conv.CommittedCount = len(m.conv.Messages)
+
+	if m.retryLastPrompt() == nil {
+		t.Fatal("retry should rewind and start a new turn")
+	}
+	// No provider is connected, so the new turn ends with a notice.
+	if len(m.conv.Messages) != 4 || m.conv.Messages[1].Content != "first answer" {
+		t.Fatalf("messages after retry = %+v", m.conv.Messages)
+	}
+	if got := m.conv.Messages[2]; got.Content != "second question" || len(got.Images) != 1 {
+		t.Errorf("resent prompt = %+v, want the second question with its image", got)
+	}
+
+	m.conv.Stream.Active = true
+	if m.retryLastPrompt() != nil {
+		t.Error("retry must not run while streaming")
+	}
+}
+
+func TestEditSelectedMsgLoadsEarlierPrompt(t *testing.T) {
+	agent.Initialize(agent.Options{})
+	t.Cleanup(agent.ResetService)
+	m := &model{}
+	m.services.Agent = agent.Default()
+	m.services.Tracker = tracker.NewStore()
+	m.userInput = input.New("", 80, nil, input.SelectorDeps{})
+	m.conv.Messages = []core.ChatMessage{
+		{Role: core.RoleUser, Content: "frist question"},
+		{Role: core.RoleAssistant, ToolCalls: []core.ToolCall{{ID: "1", Name: "Read"}}},
+		{Role: core.RoleUser, ToolResult: &core.ToolResult{ToolCallID: "1"}},
+		{Role: core.RoleAssistant, Content: "first answer"},
+		{Role: core.RoleUser, Content: "second question"},
+	}
+	m.env.InputTokens = 5000
+
+	m.Update(input.EditSelectedMsg{Index: 0})
+	if !m.userInput.Edit.Active || m.userInput.Textarea.Value() != "frist question" {
+		t.Fatalf("Edit = %+v, input = %q", m.userInput.Edit, m.userInput.Textarea.Value())
+	}
+	if len(m.conv.Messages) != 5 {
+		t.Fatal("loading a prompt must not change the conversation until it is sent")
+	}
+	if !strings.Contains(m.renderEditHint(), "earlier message") {
+		t.Errorf("hint = %q, want it to say an earlier message is edited", m.renderEditHint())
+	}
+
+	m.RewindConversation(m.userInput.Edit.Index)
+	if len(m.conv.Messages) != 0 {
+		t.Fatalf("rewind should drop the prompt with its tool calls and results, got %+v", m.conv.Messages)
+	}
+	if m.env.InputTokens != 0 {
+		t.Error("rewind should reset the context display")
+	}
+}
+
+// historyAgent is an agent.Service that records the history it is given.
+type historyAgent struct {
+	agent.Service
+	messages []core.Message
+}
+
+func (a *historyAgent) SetMessages(messages []core.Message) { a.messages = messages }
+
+func TestNoteCommandReachesRunningAgent(t *testing.T) {
+	ag := &historyAgent{}
+	m := &model{}
+	m.services.Agent = ag
+	m.conv.Messages = []core.ChatMessage{{Role: core.RoleUser, Content: "hello"}}
+	ctrl := input.NewCommandController(input.CommandDeps{Conversation: &m.conv.ConversationModel, SyncAgentMessages: m.syncAgentMessages})
+
+	if _, _, handled := ctrl.Execute(context.Background(), "/note prefer table-driven tests"); !handled {
+		t.Fatal("/note was not handled")
+	}
+	if len(ag.messages) != 2 {
+		t.Fatalf("agent history = %+v, want the prompt and the note", ag.messages)
+	}
+	if last := ag.messages[1]; !strings.Contains(last.Content, "prefer table-driven tests") {
+		t.Errorf("last agent message = %+v, want the context note", last)
+	}
+}
@@ -5,9 +5,12 @@ package app
 import (
 	"strings"
 
+	"github.com/yanmxa/gencode/internal/changelog"
 	"github.com/yanmxa/gencode/internal/filecache"
 	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/mcp"
 	"github.com/yanmxa/gencode/internal/setting"
+	"github.com/yanmxa/gencode/internal/undo"
 )
 
 type env struct {
@@ -18,6 +21,9 @@ type env struct {
 	Height        int
 	Ready         bool
 	InitialPrompt string
+	WrapWidth     int // markdown/tool-output wrap override; 0 = follow terminal width
+	Keys          keymap
+	KeysWarning   string // invalid keybindings, shown once the TUI is ready
 
 	// ── Provider (mutable — changes via SwitchProvider) ─────────
 	LLMProvider  llm.Provider
@@ -34,17 +40,30 @@ type env struct {
 	TurnInputTokens  int
 	TurnOutputTokens int
 	turnUsageActive  bool
-	ConversationCost llm.Money
+	Spend            llm.SpendLedger // per-model usage and cost, cleared on /clear
 	ThinkingEffort   string
+	MaxTokens        int      // --max-tokens override; 0 = model limit and settings
+	Temperature      *float64 // --temperature override; nil = provider default
 
 	// ── Permission (mutable — changes per mode cycle) ───────────
 	OperationMode      setting.OperationMode
 	SessionPermissions *setting.SessionPermissions
+	PendingSampling    *mcp.SamplingConsentRequest // MCP sampling consent prompt awaiting an answer
+
+	// ── Workspace (set by /workspace or --workspace) ────────────
+	Workspace      string          // active workspace name, empty when none
+	WorkspaceTools map[string]bool // enabled built-in tools; nil = no restriction
+	WorkspaceMCP   []string        // MCP servers to connect at startup; nil = auto-connect all
 
 	// ── Cache (session-scoped) ──────────────────────────────────
 	FileCache                 *filecache.Cache
+	Changes                   *changelog.Log // files modified by tools, cleared on /clear
+	Undo                      *undo.Stack    // file snapshots for /undo, cleared on /clear
 	CachedUserInstructions    string
 	CachedProjectInstructions string
+	CachedCustomPrompt        string              // project .gen/prompt.md, "" when absent
+	MemoryIndex               *memoryIndex        // section embeddings for semanticMemory; nil when memory is small
+	MCPTools                  map[string][]string // tool names per connected MCP server, for change notices
 }
 
 func newEnv(llmSvc llm.Service, cwd string, isGit bool) env {
@@ -59,7 +78,18 @@ func newEnv(llmSvc llm.Service, cwd string, isGit bool) env {
 		CurrentModel: llmSvc.CurrentModel(),
 
 		FileCache: filecache.New(),
+		Changes:   changelog.New(),
+		Undo:      undo.New(undo.DefaultLimit),
+	}
+}
+
+// ContentWidth returns the width used to wrap markdown and tool output: the
+// wrap override when set, capped at the terminal width.
+func (m *env) ContentWidth() int {
+	if m.WrapWidth > 0 && m.WrapWidth < m.Width {
+		return m.WrapWidth
 	}
+	return m.Width
 }
 
 func (m *env) GetModelID() string {
@@ -106,10 +136,26 @@ func (m *env) ApplyBypassPermissions() {
 }
 
 func (m *env) EnableAutoAcceptMode(cwd string) {
+	if m.IsProtectedDir() {
+		return
+	}
 	m.ApplyAutoAcceptPermissions(cwd)
 	m.OperationMode = setting.ModeAutoAccept
 }
 
+// EnterProtectedDir pins the session to Normal mode and makes every Bash
+// call prompt, because the session started in a protected directory.
+func (m *env) EnterProtectedDir(entry string) {
+	m.SessionPermissions.ProtectedDir = entry
+	m.OperationMode = setting.ModeNormal
+	m.ApplyModePermissions(m.CWD)
+}
+
+// IsProtectedDir reports whether the session started in a protected directory.
+func (m *env) IsProtectedDir() bool {
+	return m.SessionPermissions.ProtectedDir != ""
+}
+
 func (m *env) DetectThinkingKeywords(input string) {
 	lower := strings.ToLower(input)
 	efforts := llm.ThinkingEfforts(m.LLMProvider, m.GetModelID())
@@ -137,6 +183,9 @@ func (m *env) DetectThinkingKeywords(input string) {
 }
 
 func (m *env) ApplyModePermissions(cwd string) {
+	if m.IsProtectedDir() {
+		m.OperationMode = setting.ModeNormal
+	}
 	m.ResetSessionPermissions()
 
 	if m.OperationMode == setting.ModeAutoAccept {
@@ -151,6 +200,8 @@ func (m *env) ApplyModePermissions(cwd string) {
 func (m *env) ClearCachedInstructions() {
 	m.CachedUserInstructions = ""
 	m.CachedProjectInstructions = ""
+	m.CachedCustomPrompt = ""
+	m.MemoryIndex = nil
 }
 
 func (m *env) SessionMode() string {
@@ -165,7 +216,6 @@ func (m *env) SessionMode() string {
 func (m *env) ResetContextDisplay() {
 	m.InputTokens = 0
 	m.OutputTokens = 0
-	m.ConversationCost = llm.Money{}
 }
 
 func (m *env) ResetTokens() {
@@ -13,6 +13,7 @@ import (
 	"github.com/yanmxa/gencode/internal/core/system"
 	"github.com/yanmxa/gencode/internal/hook"
 	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/tool"
 )
 
 func (m *model) firePostToolHook(tr core.ToolResult, sideEffect any) {
@@ -38,6 +39,33 @@ func (m *model) firePostToolHook(tr core.ToolResult, sideEffect any) {
 	m.services.Hook.ExecuteAsync(eventType, input)
 }
 
+// preToolHook runs PreToolUse hooks, including those contributed by plugins,
+// before each tool call of the main agent. It runs on the agent's goroutine,
+// so it only touches the hook service.
+func (m *model) preToolHook() tool.PreToolHook {
+	hooks := m.services.Hook
+	if hooks == nil {
+		return nil
+	}
+	return func(ctx context.Context, name string, input map[string]any) (map[string]any, string) {
+		if !hooks.HasHooks(hook.PreToolUse) {
+			return input, ""
+		}
+		outcome := hooks.Execute(ctx, hook.PreToolUse, hook.HookInput{
+			ToolName:  name,
+			ToolInput: input,
+			ToolUseID: core.ToolCallIDFromContext(ctx),
+		})
+		if outcome.ShouldBlock {
+			return nil, outcome.BlockReason
+		}
+		if outcome.UpdatedInput != nil {
+			return outcome.UpdatedInput, ""
+		}
+		return input, ""
+	}
+}
+
 func (m *model) fireStopFailureHook(lastAssistantContent string, err error) {
 	if m.services.Hook == nil {
 		return
@@ -118,6 +146,8 @@ func (m *model) switchProvider(p llm.Provider) {
 	if m.services.Hook != nil {
 		m.services.Hook.SetLLMCompleter(buildHookCompleter(p), m.env.GetModelID())
 	}
+	m.syncSamplingCompleter()
+	m.resizeTextarea()
 }
 
 func (m *model) refreshMemoryContext(cwd, loadReason string) {
@@ -140,6 +170,8 @@ func (m *model) refreshMemoryContext(cwd, loadReason string) {
 	}
 	m.env.CachedUserInstructions = joinSections(userParts)
 	m.env.CachedProjectInstructions = joinSections(projectParts)
+	m.env.CachedCustomPrompt = system.LoadCustomPrompt(cwd)
+	m.env.MemoryIndex = newMemoryIndex(files)
 }
 
 func (m *model) syncSettingsToHookEngine() {
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/hook"
+	"github.com/yanmxa/gencode/internal/setting"
+)
+
+func TestPreToolHookRunsPreToolUseHooks(t *testing.T) {
+	m := &model{}
+	if m.preToolHook() != nil {
+		t.Fatal("no hook service should mean no pre-tool hook")
+	}
+
+	settings := setting.NewSettings()
+	settings.Hooks["PreToolUse"] = []setting.Hook{{
+		Matcher: "Bash",
+		Hooks:   []setting.HookCmd{{Type: "command", Command: `echo "no force pushes" >&2; exit 2`}},
+	}}
+	m.services.Hook = hook.NewEngine(settings, "test-session", t.TempDir(), "")
+	run := m.preToolHook()
+
+	input := map[string]any{"command": "git push --force"}
+	if _, reason := run(context.Background(), "Bash", input); reason != "no force pushes" {
+		t.Errorf("Bash reason = %q, want the hook's stderr", reason)
+	}
+	got, reason := run(context.Background(), "Read", map[string]any{"file_path": "a.go"})
+	if reason != "" || got["file_path"] != "a.go" {
+		t.Errorf("Read = %v, %q; unmatched tools should run unchanged", got, reason)
+	}
+}
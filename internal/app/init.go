@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -80,15 +81,42 @@ func initExtensions(cwd string) {
 	skill.Initialize(skill.Options{CWD: cwd})
 	command.Initialize(command.Options{
 		CWD:                cwd,
-		DynamicProviders:   []func() []command.Info{skillCommandInfos},
+		DynamicProviders:   []func() []command.Info{skillCommandInfos, input.MCPPromptCommandInfos},
 		PluginCommandPaths: pluginCommandPaths,
 	})
 	if err := subagent.Initialize(subagent.Options{CWD: cwd, PluginAgentPaths: pluginAgentPaths}); err != nil {
 		log.Logger().Warn("Failed to initialize subagent", zap.Error(err))
 	}
-	if err := mcp.Initialize(mcp.Options{CWD: cwd, PluginServers: pluginMCPServers}); err != nil {
+	if err := mcp.Initialize(mcp.Options{CWD: cwd, PluginServers: pluginMCPServers, Sampling: samplingGate.Handle}); err != nil {
 		log.Logger().Warn("Failed to initialize mcp", zap.Error(err))
 	}
+	registerPluginTools()
+}
+
+// registerPluginTools replaces the registered command-backed tools with those
+// declared by the currently enabled plugins. Tools whose names collide with
+// an existing tool are skipped with a warning.
+func registerPluginTools() {
+	tool.ResetCommandTools()
+	for _, pt := range plugin.GetPluginTools() {
+		env := append(plugin.PluginEnv(), setting.EnvPair("PLUGIN_ROOT", pt.Root)...)
+		for k, v := range pt.Config.Env {
+			env = append(env, k+"="+v)
+		}
+		err := tool.RegisterCommandTool(tool.CommandSpec{
+			Name:        pt.Name,
+			Description: pt.Config.Description,
+			InputSchema: pt.Config.InputSchema,
+			Command:     pt.Config.Command,
+			Args:        pt.Config.Args,
+			Env:         env,
+			Timeout:     time.Duration(pt.Config.Timeout) * time.Second,
+			Source:      "plugin:" + pt.Plugin,
+		})
+		if err != nil {
+			log.Logger().Warn("Skipping plugin tool", zap.String("plugin", pt.Plugin), zap.Error(err))
+		}
+	}
 }
 
 func pluginCommandPaths() []command.PluginCommandPath {
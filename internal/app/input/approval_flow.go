@@ -139,7 +139,7 @@ func buildPermissionSuggestions(req *perm.PermissionRequest) []hook.PermissionSu
 		}
 		suggestions = append(suggestions, hook.PermissionSuggestion{Type: "addDirectories", Directories: []string{dir}, Destination: "session"})
 	}
-	if req.ToolName == "Edit" || req.ToolName == "Write" {
+	if req.ToolName == "Edit" || req.ToolName == "MultiEdit" || req.ToolName == "Write" {
 		suggestions = append(suggestions, hook.PermissionSuggestion{Type: "setMode", Mode: "acceptEdits", Destination: "session"})
 	}
 	return suggestions
@@ -38,6 +38,7 @@ type Model struct {
 	TerminalHeight   int
 	PastedChunks     []PastedChunk
 	Queue            Queue
+	Edit             EditState
 
 	// Selectors / overlays
 	Approval ApprovalModel
@@ -50,6 +51,14 @@ type Model struct {
 	Plugin   PluginSelector
 	Provider ProviderState
 	Tool     ToolSelector
+	Settings SettingsEditor
+	Theme    ThemePicker
+	Clear    ClearConfirm
+	Branch   BranchPicker
+	Find     FindState
+	Jobs     JobsViewer
+
+	CompactPreview CompactPreview
 }
 
 type PendingImage struct {
@@ -95,6 +104,8 @@ type SelectorDeps struct {
 	Setting        coresetting.Service
 	LoadDisabled   func(userLevel bool) map[string]bool
 	UpdateDisabled func(disabled map[string]bool, userLevel bool) error
+	LoadSettingsAt func(userLevel bool) *coresetting.Settings
+	SaveSettingAt  func(userLevel bool, key string, value any) error
 }
 
 func New(cwd string, width int, matchFunc suggest.Matcher, deps SelectorDeps) Model {
@@ -116,6 +127,8 @@ func New(cwd string, width int, matchFunc suggest.Matcher, deps SelectorDeps) Mo
 		Plugin:   NewPluginSelector(deps.PluginRegistry),
 		Provider: ProviderState{Selector: NewProviderSelector()},
 		Tool:     NewToolSelector(deps.LoadDisabled, deps.UpdateDisabled),
+		Settings: NewSettingsEditor(deps.LoadSettingsAt, deps.SaveSettingAt),
+		Theme:    NewThemePicker(deps.SaveSettingAt),
 	}
 }
 
@@ -131,8 +144,18 @@ func newTextarea(width int) textarea.Model {
 	ta.FocusedStyle.CursorLine = lipgloss.NewStyle()
 	ta.FocusedStyle.Base = lipgloss.NewStyle()
 	ta.FocusedStyle.Prompt = lipgloss.NewStyle()
-	ta.BlurredStyle.Base = lipgloss.NewStyle().Foreground(kit.CurrentTheme.Muted)
-	ta.FocusedStyle.Placeholder = lipgloss.NewStyle().Foreground(kit.CurrentTheme.Muted)
+	styleTextarea(&ta)
 	ta.KeyMap.InsertNewline.SetEnabled(true)
 	return ta
 }
+
+// styleTextarea applies the theme colors of the input box.
+func styleTextarea(ta *textarea.Model) {
+	ta.BlurredStyle.Base = lipgloss.NewStyle().Foreground(kit.CurrentTheme.Muted)
+	ta.FocusedStyle.Placeholder = lipgloss.NewStyle().Foreground(kit.CurrentTheme.Muted)
+}
+
+// ApplyTheme restyles the input box after the theme changes.
+func (m *Model) ApplyTheme() {
+	styleTextarea(&m.Textarea)
+}
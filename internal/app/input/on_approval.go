@@ -97,6 +97,10 @@ func (p *ApprovalModel) GetRequest() *perm.PermissionRequest {
 	return p.request
 }
 
+// ApprovalMCPSampling is the ToolName of consent prompts for MCP servers
+// that request LLM completions through sampling.
+const ApprovalMCPSampling = "MCPSampling"
+
 // ApprovalRequestMsg is sent when a tool needs permission
 type ApprovalRequestMsg struct {
 	Request  *perm.PermissionRequest
@@ -266,7 +270,7 @@ func (p *ApprovalModel) renderInline() string {
 func (p *ApprovalModel) getTitle() string {
 	var title string
 	switch p.request.ToolName {
-	case "Edit":
+	case "Edit", "MultiEdit":
 		title = "Edit file"
 	case "Write":
 		title = "Write to file"
@@ -288,7 +292,9 @@ func (p *ApprovalModel) getTitle() string {
 
 func (p *ApprovalModel) getAllSessionLabel() string {
 	switch p.request.ToolName {
-	case "Edit":
+	case ApprovalMCPSampling:
+		return "Yes, allow this server during this session"
+	case "Edit", "MultiEdit":
 		return "Yes, allow all edits during this session"
 	case "Write":
 		return "Yes, allow all writes during this session"
@@ -45,11 +45,11 @@ func approvalDiffRemovedStyle() lipgloss.Style {
 }
 
 func approvalDiffAddedBgStyle() lipgloss.Style {
-	return lipgloss.NewStyle().Foreground(kit.CurrentTheme.Success).Background(kit.CurrentTheme.SuccessBg)
+	return kit.DiffAddedLineStyle()
 }
 
 func approvalDiffRemovedBgStyle() lipgloss.Style {
-	return lipgloss.NewStyle().Foreground(kit.CurrentTheme.Error).Background(kit.CurrentTheme.ErrorBg)
+	return kit.DiffRemovedLineStyle()
 }
 
 func approvalDiffContextStyle() lipgloss.Style {
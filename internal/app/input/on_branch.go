@@ -0,0 +1,171 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/yanmxa/gencode/internal/app/kit"
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+// BranchSelectedMsg is sent when a branch point is picked. Keep is the
+// number of leading conversation messages the branch starts with.
+type BranchSelectedMsg struct {
+	Keep int
+}
+
+// branchPoint is a prompt the conversation can be branched after.
+type branchPoint struct {
+	turn    int    // 1-based prompt number
+	text    string // first line of the prompt
+	keep    int    // messages up to the next prompt
+	replies int    // messages answering the prompt
+}
+
+// BranchPicker lists the prompts of the current conversation so one can be
+// picked as the point to branch from. Type to filter, as in /resume.
+type BranchPicker struct {
+	active   bool
+	points   []branchPoint
+	filtered []branchPoint
+	nav      kit.ListNav
+	width    int
+	height   int
+}
+
+// branchPoints returns a branch point for every typed prompt in msgs,
+// newest first. Each keeps the prompt and everything up to the next one.
+func branchPoints(msgs []core.ChatMessage) []branchPoint {
+	prompts := typedPrompts(msgs)
+	points := make([]branchPoint, 0, len(prompts))
+	for n := len(prompts) - 1; n >= 0; n-- {
+		keep := len(msgs)
+		if n+1 < len(prompts) {
+			keep = prompts[n+1]
+		}
+		points = append(points, branchPoint{
+			turn:    n + 1,
+			text:    sessionTruncateToFirstLine(editText(msgs[prompts[n]]), 200),
+			keep:    keep,
+			replies: keep - prompts[n] - 1,
+		})
+	}
+	return points
+}
+
+// Enter opens the picker over msgs, pre-filtered by query.
+func (p *BranchPicker) Enter(msgs []core.ChatMessage, query string, width, height int) error {
+	points := branchPoints(msgs)
+	if len(points) == 0 {
+		return fmt.Errorf("no prompts to branch from")
+	}
+	*p = BranchPicker{
+		active: true,
+		points: points,
+		width:  width,
+		height: height,
+		nav:    kit.ListNav{MaxVisible: max(3, min(height-8, 20)), Search: query},
+	}
+	p.updateFilter()
+	return nil
+}
+
+func (p *BranchPicker) IsActive() bool {
+	return p.active
+}
+
+func (p *BranchPicker) Cancel() {
+	*p = BranchPicker{}
+}
+
+func (p *BranchPicker) updateFilter() {
+	query := strings.ToLower(p.nav.Search)
+	p.filtered = p.filtered[:0]
+	for _, point := range p.points {
+		if query != "" && !kit.FuzzyMatch(strings.ToLower(point.text), query) {
+			continue
+		}
+		p.filtered = append(p.filtered, point)
+	}
+	p.nav.ResetCursor()
+	p.nav.Total = len(p.filtered)
+}
+
+func (p *BranchPicker) Select() tea.Cmd {
+	if p.nav.Selected >= len(p.filtered) {
+		return nil
+	}
+	keep := p.filtered[p.nav.Selected].keep
+	p.Cancel()
+	return func() tea.Msg {
+		return BranchSelectedMsg{Keep: keep}
+	}
+}
+
+func (p *BranchPicker) HandleKeypress(key tea.KeyMsg) tea.Cmd {
+	if key.Type == tea.KeyEnter {
+		return p.Select()
+	}
+
+	searchChanged, consumed := p.nav.HandleKey(key)
+	if searchChanged {
+		p.updateFilter()
+	}
+	if consumed {
+		return nil
+	}
+
+	if key.Type == tea.KeyEsc {
+		p.Cancel()
+		return func() tea.Msg { return kit.DismissedMsg{} }
+	}
+	return nil
+}
+
+func (p *BranchPicker) Render() string {
+	if !p.active {
+		return ""
+	}
+
+	var sb strings.Builder
+	title := fmt.Sprintf("Branch Conversation (%d/%d)", len(p.filtered), len(p.points))
+	sb.WriteString(kit.SelectorTitleStyle().Render(title) + "\n")
+
+	searchLine := "🔍 Type to filter prompts..."
+	searchStyle := kit.SelectorHintStyle()
+	if p.nav.Search != "" {
+		searchLine = "> " + p.nav.Search + "_"
+		searchStyle = kit.SelectorBreadcrumbStyle()
+	}
+	sb.WriteString(searchStyle.Render(searchLine) + "\n\n")
+
+	if len(p.filtered) == 0 {
+		sb.WriteString(kit.SelectorHintStyle().Render("  No prompts match the filter") + "\n")
+	} else {
+		start, end := p.nav.VisibleRange()
+		if start > 0 {
+			sb.WriteString(kit.SelectorHintStyle().Render("  ↑ more above") + "\n")
+		}
+		metaStyle := lipgloss.NewStyle().Foreground(kit.CurrentTheme.Muted)
+		for i := start; i < end; i++ {
+			point := p.filtered[i]
+			style, indent := kit.SelectorItemStyle(), "  "
+			if i == p.nav.Selected {
+				style, indent = kit.SelectorSelectedStyle(), "> "
+			}
+			label := fmt.Sprintf("%s%d. ", indent, point.turn)
+			meta := sessionPluralize(point.replies, "message")
+			text := kit.TruncateText(point.text, max(10, p.width-len(label)-len(meta)-6))
+			sb.WriteString(style.Render(label+text) + "  " + metaStyle.Render(meta) + "\n")
+		}
+		if end < len(p.filtered) {
+			sb.WriteString(kit.SelectorHintStyle().Render("  ↓ more below") + "\n")
+		}
+	}
+
+	sb.WriteString("\n" + kit.SelectorHintStyle().Render("Keeps the chosen prompt and its replies · Enter branch · Esc clear/cancel"))
+	return sb.String()
+}
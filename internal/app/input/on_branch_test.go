@@ -0,0 +1,62 @@
+package input
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+func branchTestMessages() []core.ChatMessage {
+	return []core.ChatMessage{
+		{Role: core.RoleUser, Content: "add a login page"},
+		{Role: core.RoleAssistant, ToolCalls: []core.ToolCall{{ID: "t1", Name: "Write"}}},
+		{Role: core.RoleUser, ToolResult: &core.ToolResult{ToolCallID: "t1", Content: "ok"}},
+		{Role: core.RoleAssistant, Content: "done"},
+		{Role: core.RoleUser, Content: "/changes"},
+		{Role: core.RoleNotice, Content: "1 file changed"},
+		{Role: core.RoleUser, Content: "now style it\nwith tailwind"},
+		{Role: core.RoleAssistant, Content: "styled"},
+	}
+}
+
+func TestBranchPoints(t *testing.T) {
+	points := branchPoints(branchTestMessages())
+	want := []branchPoint{
+		{turn: 2, text: "now style it", keep: 8, replies: 1},
+		{turn: 1, text: "add a login page", keep: 6, replies: 5},
+	}
+	if len(points) != len(want) {
+		t.Fatalf("points = %+v, want %+v", points, want)
+	}
+	for i := range want {
+		if points[i] != want[i] {
+			t.Errorf("points[%d] = %+v, want %+v", i, points[i], want[i])
+		}
+	}
+}
+
+func TestBranchPickerFilterAndSelect(t *testing.T) {
+	var p BranchPicker
+	if err := p.Enter(nil, "", 80, 24); err == nil {
+		t.Fatal("Enter with no prompts should fail")
+	}
+	if err := p.Enter(branchTestMessages(), "login", 80, 24); err != nil {
+		t.Fatalf("Enter: %v", err)
+	}
+	if len(p.filtered) != 1 || p.filtered[0].turn != 1 {
+		t.Fatalf("filtered = %+v, want the login prompt", p.filtered)
+	}
+
+	cmd := p.HandleKeypress(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Enter should select the prompt")
+	}
+	if msg, ok := cmd().(BranchSelectedMsg); !ok || msg.Keep != 6 {
+		t.Errorf("selected = %#v, want BranchSelectedMsg{Keep: 6}", cmd())
+	}
+	if p.IsActive() {
+		t.Error("picker should close after selecting")
+	}
+}
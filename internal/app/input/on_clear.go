@@ -0,0 +1,133 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/yanmxa/gencode/internal/app/kit"
+)
+
+// clearConfirmThreshold is the number of messages above which /clear asks
+// for confirmation when the conversation has not been saved.
+const clearConfirmThreshold = 4
+
+// ClearAction is the user's choice in the /clear confirmation prompt.
+type ClearAction int
+
+const (
+	ClearActionCancel ClearAction = iota
+	ClearActionClear
+	ClearActionSaveThenClear
+)
+
+// ClearConfirmMsg is emitted when the user answers the /clear confirmation.
+type ClearConfirmMsg struct {
+	Action ClearAction
+}
+
+type clearOption struct {
+	action ClearAction
+	label  string
+}
+
+var clearOptions = []clearOption{
+	{action: ClearActionClear, label: "Clear"},
+	{action: ClearActionSaveThenClear, label: "Save session, then clear"},
+	{action: ClearActionCancel, label: "Cancel"},
+}
+
+// ClearConfirm is a small overlay asking whether to discard an unsaved conversation.
+type ClearConfirm struct {
+	active       bool
+	selectedIdx  int
+	messageCount int
+	width        int
+	height       int
+}
+
+func (c *ClearConfirm) Enter(messageCount, width, height int) {
+	c.active = true
+	c.selectedIdx = 0
+	c.messageCount = messageCount
+	c.width = width
+	c.height = height
+}
+
+func (c *ClearConfirm) IsActive() bool {
+	return c.active
+}
+
+func (c *ClearConfirm) Cancel() {
+	c.active = false
+	c.selectedIdx = 0
+}
+
+func (c *ClearConfirm) choose(action ClearAction) tea.Cmd {
+	c.Cancel()
+	return func() tea.Msg {
+		return ClearConfirmMsg{Action: action}
+	}
+}
+
+func (c *ClearConfirm) HandleKeypress(key tea.KeyMsg) tea.Cmd {
+	switch key.Type {
+	case tea.KeyUp, tea.KeyCtrlP:
+		if c.selectedIdx > 0 {
+			c.selectedIdx--
+		}
+		return nil
+	case tea.KeyDown, tea.KeyCtrlN:
+		if c.selectedIdx < len(clearOptions)-1 {
+			c.selectedIdx++
+		}
+		return nil
+	case tea.KeyEnter:
+		return c.choose(clearOptions[c.selectedIdx].action)
+	case tea.KeyEsc:
+		return c.choose(ClearActionCancel)
+	}
+
+	switch key.String() {
+	case "j":
+		if c.selectedIdx < len(clearOptions)-1 {
+			c.selectedIdx++
+		}
+	case "k":
+		if c.selectedIdx > 0 {
+			c.selectedIdx--
+		}
+	case "y":
+		return c.choose(ClearActionClear)
+	case "s":
+		return c.choose(ClearActionSaveThenClear)
+	case "n":
+		return c.choose(ClearActionCancel)
+	}
+	return nil
+}
+
+func (c *ClearConfirm) Render() string {
+	if !c.active {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(kit.SelectorTitleStyle().Render("Clear conversation?"))
+	sb.WriteString("\n\n")
+	sb.WriteString(kit.DimStyle().Render(fmt.Sprintf("%d messages have not been saved. Clearing also resets the task list.", c.messageCount)))
+	sb.WriteString("\n\n")
+	for i, opt := range clearOptions {
+		sb.WriteString(kit.RenderSelectableRow(opt.label, i == c.selectedIdx))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(kit.DimStyle().Render("↑/↓ navigate · Enter select · y clear · s save & clear · Esc cancel"))
+
+	box := kit.SelectorBorderStyle().
+		Width(kit.CalculateBoxWidth(c.width)).
+		Render(sb.String())
+	return lipgloss.Place(c.width, c.height-2, lipgloss.Center, lipgloss.Top, box)
+}
@@ -0,0 +1,87 @@
+package input
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yanmxa/gencode/internal/app/conv"
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+func newClearTestController(msgCount int, confirm, saved bool) (CommandController, *Model) {
+	state := &Model{}
+	conversation := conv.NewConversation()
+	for i := 0; i < msgCount; i++ {
+		conversation.Append(core.ChatMessage{Role: core.RoleUser, Content: "msg"})
+	}
+	ctrl := NewCommandController(CommandDeps{
+		Input:          state,
+		Conversation:   &conversation,
+		Width:          80,
+		Height:         24,
+		ConfirmClear:   confirm,
+		IsSessionSaved: func() bool { return saved },
+	})
+	return ctrl, state
+}
+
+func TestClearCommandAsksWhenUnsaved(t *testing.T) {
+	ctrl, state := newClearTestController(clearConfirmThreshold+1, true, false)
+	if _, _, err := ctrl.handleClearCommand(context.Background(), ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !state.Clear.IsActive() {
+		t.Fatal("expected clear confirmation to be shown")
+	}
+}
+
+func TestClearConfirmNeededRespectsSettingAndSaveState(t *testing.T) {
+	tests := []struct {
+		name    string
+		count   int
+		confirm bool
+		saved   bool
+		want    bool
+	}{
+		{"unsaved long conversation", clearConfirmThreshold + 1, true, false, true},
+		{"setting disabled", clearConfirmThreshold + 1, false, false, false},
+		{"already saved", clearConfirmThreshold + 1, true, true, false},
+		{"short conversation", clearConfirmThreshold, true, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl, _ := newClearTestController(tt.count, tt.confirm, tt.saved)
+			if got := ctrl.needsClearConfirmation(); got != tt.want {
+				t.Errorf("needsClearConfirmation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClearConfirmKeys(t *testing.T) {
+	tests := []struct {
+		key  string
+		want ClearAction
+	}{
+		{"y", ClearActionClear},
+		{"s", ClearActionSaveThenClear},
+		{"n", ClearActionCancel},
+	}
+	for _, tt := range tests {
+		var c ClearConfirm
+		c.Enter(10, 80, 24)
+		cmd := c.HandleKeypress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(tt.key)})
+		if cmd == nil {
+			t.Fatalf("key %q: expected command", tt.key)
+		}
+		msg, ok := cmd().(ClearConfirmMsg)
+		if !ok || msg.Action != tt.want {
+			t.Errorf("key %q: got %#v, want action %v", tt.key, msg, tt.want)
+		}
+		if c.IsActive() {
+			t.Errorf("key %q: expected prompt to close", tt.key)
+		}
+	}
+}
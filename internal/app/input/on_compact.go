@@ -0,0 +1,163 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/yanmxa/gencode/internal/app/conv"
+	"github.com/yanmxa/gencode/internal/app/kit"
+)
+
+// compactPreviewLines caps how much of the summary is shown in the preview box.
+const compactPreviewLines = 12
+
+// CompactPreviewAction is the user's choice after reviewing a /compact summary.
+type CompactPreviewAction int
+
+const (
+	CompactPreviewCancel CompactPreviewAction = iota
+	CompactPreviewAccept
+	CompactPreviewRegenerate
+)
+
+// CompactPreviewMsg is emitted when the user accepts, regenerates, or cancels
+// a pending compaction.
+type CompactPreviewMsg struct {
+	Action CompactPreviewAction
+	Result conv.CompactResultMsg
+	Focus  string
+}
+
+// CompactPreview shows a generated summary before it replaces the conversation.
+type CompactPreview struct {
+	active     bool
+	result     conv.CompactResultMsg
+	editing    bool
+	focusInput textinput.Model
+	width      int
+	height     int
+}
+
+func (p *CompactPreview) Enter(result conv.CompactResultMsg, width, height int) {
+	p.active = true
+	p.result = result
+	p.editing = false
+	p.width = width
+	p.height = height
+}
+
+func (p *CompactPreview) IsActive() bool {
+	return p.active
+}
+
+func (p *CompactPreview) Cancel() {
+	p.active = false
+	p.editing = false
+	p.result = conv.CompactResultMsg{}
+}
+
+func (p *CompactPreview) choose(action CompactPreviewAction, focus string) tea.Cmd {
+	result := p.result
+	p.Cancel()
+	return func() tea.Msg {
+		return CompactPreviewMsg{Action: action, Result: result, Focus: focus}
+	}
+}
+
+func (p *CompactPreview) startEditing() {
+	ti := textinput.New()
+	ti.Placeholder = "what the summary should focus on"
+	ti.SetValue(p.result.Focus)
+	ti.Focus()
+	ti.CharLimit = 256
+	ti.Width = 50
+	p.focusInput = ti
+	p.editing = true
+}
+
+func (p *CompactPreview) HandleKeypress(key tea.KeyMsg) tea.Cmd {
+	if p.editing {
+		switch key.Type {
+		case tea.KeyEnter:
+			return p.choose(CompactPreviewRegenerate, strings.TrimSpace(p.focusInput.Value()))
+		case tea.KeyEsc:
+			p.editing = false
+			return nil
+		}
+		var cmd tea.Cmd
+		p.focusInput, cmd = p.focusInput.Update(key)
+		return cmd
+	}
+
+	switch key.Type {
+	case tea.KeyEnter:
+		return p.choose(CompactPreviewAccept, "")
+	case tea.KeyEsc:
+		return p.choose(CompactPreviewCancel, "")
+	}
+
+	switch key.String() {
+	case "a", "y":
+		return p.choose(CompactPreviewAccept, "")
+	case "r":
+		p.startEditing()
+	case "c", "n":
+		return p.choose(CompactPreviewCancel, "")
+	}
+	return nil
+}
+
+func (p *CompactPreview) Render() string {
+	if !p.active {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(kit.SelectorTitleStyle().Render("Compact conversation?"))
+	sb.WriteString("\n\n")
+	sb.WriteString(kit.DimStyle().Render(p.savingsLine()))
+	sb.WriteString("\n\n")
+
+	lines := strings.Split(p.result.Summary, "\n")
+	if len(lines) > compactPreviewLines {
+		hidden := len(lines) - compactPreviewLines
+		lines = append(lines[:compactPreviewLines], kit.DimStyle().Render(fmt.Sprintf("… %d more lines", hidden)))
+	}
+	sb.WriteString(strings.Join(lines, "\n"))
+	sb.WriteString("\n\n")
+
+	if p.editing {
+		sb.WriteString("Focus: " + p.focusInput.View())
+		sb.WriteString("\n\n")
+		sb.WriteString(kit.DimStyle().Render("Enter regenerate · Esc back"))
+	} else {
+		sb.WriteString(kit.DimStyle().Render("Enter/a accept · r regenerate with focus · Esc cancel"))
+	}
+
+	boxWidth := kit.CalculateBoxWidth(p.width)
+	box := kit.SelectorBorderStyle().
+		Width(boxWidth).
+		Render(lipgloss.NewStyle().Width(boxWidth - 4).Render(sb.String()))
+	return lipgloss.Place(p.width, p.height-2, lipgloss.Center, lipgloss.Top, box)
+}
+
+func (p *CompactPreview) savingsLine() string {
+	r := p.result
+	line := fmt.Sprintf("%d messages → 1 summary", r.OriginalCount)
+	if r.OriginalTokens > 0 {
+		line += fmt.Sprintf(" · ~%s → ~%s tokens",
+			kit.FormatTokenCount(r.OriginalTokens), kit.FormatTokenCount(r.SummaryTokens))
+		// A summary can be longer than a short history; only report real savings.
+		if saved := r.OriginalTokens - r.SummaryTokens; saved > 0 {
+			line += fmt.Sprintf(" (saves ~%d%%)", saved*100/r.OriginalTokens)
+		}
+	}
+	if r.Focus != "" {
+		line += " · focus: " + r.Focus
+	}
+	return line
+}
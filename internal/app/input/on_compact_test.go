@@ -0,0 +1,62 @@
+package input
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yanmxa/gencode/internal/app/conv"
+)
+
+func TestCompactPreviewAcceptKeepsResult(t *testing.T) {
+	var p CompactPreview
+	p.Enter(conv.CompactResultMsg{Summary: "summary", OriginalCount: 12}, 80, 24)
+
+	cmd := p.HandleKeypress(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected command")
+	}
+	msg, ok := cmd().(CompactPreviewMsg)
+	if !ok || msg.Action != CompactPreviewAccept || msg.Result.Summary != "summary" {
+		t.Fatalf("got %#v, want accept with original result", msg)
+	}
+	if p.IsActive() {
+		t.Fatal("expected preview to close")
+	}
+}
+
+func TestCompactPreviewRegenerateWithFocus(t *testing.T) {
+	var p CompactPreview
+	p.Enter(conv.CompactResultMsg{Summary: "summary", OriginalCount: 12}, 80, 24)
+
+	if cmd := p.HandleKeypress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")}); cmd != nil {
+		cmd()
+	}
+	if !p.editing {
+		t.Fatal("expected focus input after r")
+	}
+	p.HandleKeypress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("api changes")})
+
+	cmd := p.HandleKeypress(tea.KeyMsg{Type: tea.KeyEnter})
+	msg, ok := cmd().(CompactPreviewMsg)
+	if !ok || msg.Action != CompactPreviewRegenerate || msg.Focus != "api changes" {
+		t.Fatalf("got %#v, want regenerate with focus", msg)
+	}
+}
+
+func TestCompactPreviewShowsSavings(t *testing.T) {
+	var p CompactPreview
+	p.Enter(conv.CompactResultMsg{Summary: "summary", OriginalCount: 12, OriginalTokens: 4000, SummaryTokens: 1000}, 100, 40)
+	if got := p.savingsLine(); !strings.Contains(got, "75%") {
+		t.Errorf("savingsLine() = %q, want 75%% savings", got)
+	}
+	if p.Render() == "" {
+		t.Error("expected preview to render")
+	}
+
+	p.Enter(conv.CompactResultMsg{Summary: "summary", OriginalCount: 2, OriginalTokens: 300, SummaryTokens: 500}, 100, 40)
+	if got := p.savingsLine(); strings.Contains(got, "saves") {
+		t.Errorf("savingsLine() = %q, want no savings when the summary is longer", got)
+	}
+}
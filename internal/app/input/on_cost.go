@@ -0,0 +1,172 @@
+package input
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yanmxa/gencode/internal/app/kit"
+	"github.com/yanmxa/gencode/internal/llm"
+)
+
+const costUsage = `Usage:
+  /cost                                   - Show the session's spend per model
+  /cost price <input> <output> [cache-read] [cache-write] [currency]
+                                          - Set the current model's price per million tokens;
+                                            cache rates default to the input rate
+  /cost price reset                       - Go back to the built-in price`
+
+// HandleCostCommand shows the session's token usage and cost per model, or
+// overrides the current model's price with /cost price.
+func HandleCostCommand(spend *llm.SpendLedger, store *llm.Store, current *llm.CurrentModelInfo, args string) (string, error) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return formatSessionCost(spend), nil
+	}
+	if fields[0] != "price" {
+		return costUsage, nil
+	}
+	if current == nil {
+		return "No model selected. Use /model to select a model first.", nil
+	}
+	if store == nil {
+		return "Provider store unavailable.", nil
+	}
+
+	modelID := current.ModelID
+	if len(fields) == 2 && fields[1] == "reset" {
+		if err := store.ClearPricing(modelID); err != nil {
+			return "", fmt.Errorf("failed to reset pricing: %w", err)
+		}
+		if p, ok := llm.LookupPricing(store, modelID); ok {
+			return fmt.Sprintf("Reset %s to the built-in price: %s", modelID, formatPricing(p)), nil
+		}
+		return fmt.Sprintf("Removed the price override for %s; no built-in price is known.", modelID), nil
+	}
+
+	p, err := parsePricing(fields[1:])
+	if err != nil {
+		return fmt.Sprintf("%v\n\n%s", err, costUsage), nil
+	}
+	if err := store.SetPricing(modelID, p); err != nil {
+		return "", fmt.Errorf("failed to save pricing: %w", err)
+	}
+	return fmt.Sprintf("Set price for %s: %s\nApplies to requests from now on.", modelID, formatPricing(p)), nil
+}
+
+// parsePricing parses "<input> <output> [cache-read] [cache-write] [currency]".
+// Cache rates that are not given default to the input rate, so cached tokens
+// are never counted as free.
+func parsePricing(args []string) (llm.Pricing, error) {
+	currency := llm.CurrencyUSD
+	if n := len(args); n > 2 {
+		if _, err := strconv.ParseFloat(args[n-1], 64); err != nil {
+			switch c := llm.Currency(strings.ToUpper(args[n-1])); c {
+			case llm.CurrencyUSD, llm.CurrencyCNY:
+				currency = c
+			default:
+				return llm.Pricing{}, fmt.Errorf("unknown currency %q (use USD or CNY)", args[n-1])
+			}
+			args = args[:n-1]
+		}
+	}
+	if len(args) < 2 || len(args) > 4 {
+		return llm.Pricing{}, fmt.Errorf("price takes an input and an output rate, and optionally cache read and write rates")
+	}
+
+	names := []string{"input", "output", "cache read", "cache write"}
+	rates := make([]float64, len(args))
+	for i, arg := range args {
+		rate, err := strconv.ParseFloat(arg, 64)
+		if err != nil || rate < 0 {
+			return llm.Pricing{}, fmt.Errorf("invalid %s rate %q", names[i], arg)
+		}
+		rates[i] = rate
+	}
+	p := llm.Pricing{Input: rates[0], Output: rates[1], CacheRead: rates[0], CacheWrite: rates[0], Currency: currency}
+	if len(rates) > 2 {
+		p.CacheRead = rates[2]
+	}
+	if len(rates) > 3 {
+		p.CacheWrite = rates[3]
+	}
+	return p, nil
+}
+
+func formatPricing(p llm.Pricing) string {
+	currency := cmp.Or(p.Currency, llm.CurrencyUSD)
+	unit := func(rate float64) string {
+		return kit.FormatMoney(llm.Money{Amount: rate, Currency: currency})
+	}
+	cache := ""
+	if p.CacheRead > 0 || p.CacheWrite > 0 {
+		cache = fmt.Sprintf(", %s cache read, %s cache write", unit(p.CacheRead), unit(p.CacheWrite))
+	}
+	return fmt.Sprintf("%s input, %s output%s per million tokens", unit(p.Input), unit(p.Output), cache)
+}
+
+func formatSessionCost(spend *llm.SpendLedger) string {
+	var models []llm.ModelSpend
+	if spend != nil {
+		models = spend.Models()
+	}
+	if len(models) == 0 {
+		return "No model usage in this session yet."
+	}
+
+	width := 0
+	for _, s := range models {
+		width = max(width, len(s.ModelID))
+	}
+	var sb strings.Builder
+	sb.WriteString("Session cost:\n")
+	unpriced := false
+	for _, s := range models {
+		requests := "1 request"
+		if s.Requests != 1 {
+			requests = fmt.Sprintf("%d requests", s.Requests)
+		}
+		tokens := fmt.Sprintf("↑%s ↓%s", kit.FormatTokenCount(s.Usage.InputTokens), kit.FormatTokenCount(s.Usage.OutputTokens))
+		if s.Usage.CacheReadInputTokens > 0 || s.Usage.CacheCreationInputTokens > 0 {
+			saved := ""
+			if s.CacheSavings.Amount > 0 {
+				saved = ", saved " + kit.FormatMoney(s.CacheSavings)
+			}
+			tokens += fmt.Sprintf(" (cache %s read, %s write%s)",
+				kit.FormatTokenCount(s.Usage.CacheReadInputTokens), kit.FormatTokenCount(s.Usage.CacheCreationInputTokens), saved)
+		}
+		cost := "pricing unavailable"
+		if s.Priced {
+			cost = kit.FormatMoney(s.Cost)
+		} else {
+			unpriced = true
+		}
+		fmt.Fprintf(&sb, "  %-*s  %s · %s · %s\n", width, s.ModelID, requests, tokens, cost)
+	}
+
+	usage := spend.TotalUsage()
+	fmt.Fprintf(&sb, "\nTokens: %s input, %s output",
+		kit.FormatTokenCount(usage.InputTokens), kit.FormatTokenCount(usage.OutputTokens))
+	if cached := usage.CacheReadInputTokens + usage.CacheCreationInputTokens; cached > 0 {
+		fmt.Fprintf(&sb, " (%s cached)", kit.FormatTokenCount(cached))
+	}
+	// Savings are negative while cache writes outweigh reads; show only gains.
+	saved := slices.DeleteFunc(spend.CacheSavingsTotals(), func(m llm.Money) bool { return m.Amount <= 0 })
+	if len(saved) > 0 {
+		fmt.Fprintf(&sb, "\nPrompt cache saved: %s", kit.FormatMoneyTotals(saved))
+	}
+	if totals := spend.Totals(); len(totals) > 0 {
+		fmt.Fprintf(&sb, "\nTotal: %s", kit.FormatMoneyTotals(totals))
+		if unpriced {
+			sb.WriteString(" (excludes models without pricing)")
+		}
+	} else {
+		sb.WriteString("\nTotal: pricing unavailable")
+	}
+	if unpriced {
+		sb.WriteString("\nSet a model's price with /cost price <input> <output> [cache-read] [cache-write].")
+	}
+	return sb.String()
+}
@@ -0,0 +1,91 @@
+package input
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/llm"
+)
+
+func TestHandleCostCommandReport(t *testing.T) {
+	result, _ := HandleCostCommand(&llm.SpendLedger{}, nil, nil, "")
+	if !strings.Contains(result, "No model usage") {
+		t.Errorf("empty session: %q", result)
+	}
+
+	var spend llm.SpendLedger
+	price := llm.Pricing{Input: 3, Output: 15}
+	spend.Record(llm.Anthropic, "claude-sonnet-4-5", llm.Usage{InputTokens: 100_000, OutputTokens: 2_000}, &price)
+	spend.Record(llm.Ollama, "llama3.1", llm.Usage{InputTokens: 1_200, OutputTokens: 80}, nil)
+
+	result, err := HandleCostCommand(&spend, nil, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"claude-sonnet-4-5", "$0.330", "llama3.1", "↑1.2k ↓80", "pricing unavailable", "Tokens: 101.2k input, 2.1k output", "Total: $0.330 (excludes models without pricing)"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("report missing %q:\n%s", want, result)
+		}
+	}
+}
+
+func TestHandleCostCommandCacheSavings(t *testing.T) {
+	var spend llm.SpendLedger
+	price := llm.Pricing{Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75}
+	spend.Record(llm.Anthropic, "claude-sonnet-4-5", llm.Usage{InputTokens: 1_000, CacheReadInputTokens: 1_000_000}, &price)
+
+	result, _ := HandleCostCommand(&spend, nil, nil, "")
+	for _, want := range []string{"(cache 1.0M read, 0 write, saved $2.700)", "Prompt cache saved: $2.700"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("report missing %q:\n%s", want, result)
+		}
+	}
+
+	var writesOnly llm.SpendLedger
+	writesOnly.Record(llm.Anthropic, "claude-sonnet-4-5", llm.Usage{CacheCreationInputTokens: 10_000}, &price)
+	if result, _ := HandleCostCommand(&writesOnly, nil, nil, ""); strings.Contains(result, "saved") {
+		t.Errorf("cache writes alone should not report savings:\n%s", result)
+	}
+}
+
+func TestHandleCostCommandPrice(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	store, err := llm.NewStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := &llm.CurrentModelInfo{ModelID: "llama3.1", Provider: llm.Ollama}
+
+	result, err := HandleCostCommand(nil, store, current, "price 0.5 1.5")
+	if err != nil || !strings.Contains(result, "Set price for llama3.1") {
+		t.Fatalf("result=%q err=%v", result, err)
+	}
+	if p, ok := store.GetPricing("llama3.1"); !ok || p.Input != 0.5 || p.Output != 1.5 || p.Currency != llm.CurrencyUSD {
+		t.Fatalf("stored pricing = %+v, %v", p, ok)
+	}
+	if p, _ := store.GetPricing("llama3.1"); p.CacheRead != 0.5 || p.CacheWrite != 0.5 {
+		t.Errorf("cache rates = %v/%v, want the input rate when not given", p.CacheRead, p.CacheWrite)
+	}
+
+	if _, err := HandleCostCommand(nil, store, current, "price 3 15 0.3 3.75 cny"); err != nil {
+		t.Fatal(err)
+	}
+	want := llm.Pricing{Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75, Currency: llm.CurrencyCNY}
+	if p, _ := store.GetPricing("llama3.1"); p != want {
+		t.Errorf("stored pricing = %+v, want %+v", p, want)
+	}
+	if result, _ := HandleCostCommand(nil, store, current, "price 3 15 -1"); !strings.Contains(result, "invalid cache read rate") {
+		t.Errorf("bad cache rate: %q", result)
+	}
+
+	if result, _ := HandleCostCommand(nil, store, current, "price 1 x"); !strings.Contains(result, "invalid output rate") {
+		t.Errorf("bad rate: %q", result)
+	}
+
+	if _, err := HandleCostCommand(nil, store, current, "price reset"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.GetPricing("llama3.1"); ok {
+		t.Error("price reset left the override in place")
+	}
+}
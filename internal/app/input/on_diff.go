@@ -0,0 +1,175 @@
+package input
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/yanmxa/gencode/internal/setting"
+	"github.com/yanmxa/gencode/internal/tool/fs"
+)
+
+const diffUsage = `Usage: /diff [--staged] [--send] [path]
+
+Shows uncommitted changes (git diff --staged and git diff), one block per
+file. Long files are collapsed; ctrl+o expands the latest, double ctrl+o all.
+  --staged  only show staged changes
+  --send    add the diff to the conversation so the model sees it with your next message
+  path      only show changes to this file or directory`
+
+const (
+	gitDiffStagedCommand   = "git --no-pager diff --staged --no-color --no-ext-diff"
+	gitDiffUnstagedCommand = "git --no-pager diff --no-color --no-ext-diff"
+)
+
+// DiffFile is one file's changes in /diff output.
+type DiffFile struct {
+	Path   string
+	Staged bool
+	Diff   string // unified diff starting at its "diff --git" line
+}
+
+// Label names the file, marking staged changes.
+func (f DiffFile) Label() string {
+	if f.Staged {
+		return f.Path + " (staged)"
+	}
+	return f.Path
+}
+
+// DiffOutput is the result of /diff. Message is shown as a notice ahead of
+// Files; with --send, Note holds the whole diff for the conversation instead.
+type DiffOutput struct {
+	Message string
+	Files   []DiffFile
+	Note    string
+}
+
+type diffOptions struct {
+	staged bool
+	send   bool
+	path   string
+}
+
+func parseDiffArgs(args string) (diffOptions, bool) {
+	var opts diffOptions
+	for _, field := range strings.Fields(args) {
+		switch {
+		case field == "--staged" || field == "--cached":
+			opts.staged = true
+		case field == "--send":
+			opts.send = true
+		case strings.HasPrefix(field, "-") || opts.path != "":
+			return opts, false
+		default:
+			opts.path = field
+		}
+	}
+	return opts, true
+}
+
+// HandleDiffCommand collects the uncommitted changes in cwd by running git
+// diff through the Bash tool and splits them per file. Outside a git
+// repository it reports that nothing changed.
+func HandleDiffCommand(ctx context.Context, cwd, args string) (DiffOutput, error) {
+	opts, ok := parseDiffArgs(args)
+	if !ok {
+		return DiffOutput{Message: diffUsage}, nil
+	}
+
+	what := "uncommitted changes"
+	if opts.staged {
+		what = "staged changes"
+	}
+	if opts.path != "" {
+		what += " in " + opts.path
+	}
+	title := strings.ToUpper(what[:1]) + what[1:]
+
+	if !setting.IsGitRepo(cwd) {
+		return DiffOutput{Message: fmt.Sprintf("No %s (%s is not a git repository).", what, cwd)}, nil
+	}
+
+	files, err := gitDiff(ctx, cwd, opts)
+	if err != nil {
+		return DiffOutput{}, err
+	}
+	if len(files) == 0 {
+		return DiffOutput{Message: fmt.Sprintf("No %s.", what)}, nil
+	}
+
+	if opts.send {
+		diffs := make([]string, len(files))
+		for i, f := range files {
+			diffs[i] = f.Diff
+		}
+		return DiffOutput{Note: title + ":\n```diff\n" + strings.Join(diffs, "\n") + "\n```"}, nil
+	}
+
+	noun := "files"
+	if len(files) == 1 {
+		noun = "file"
+	}
+	return DiffOutput{
+		Message: fmt.Sprintf("%s (%d %s):", title, len(files), noun),
+		Files:   files,
+	}, nil
+}
+
+// gitDiff returns the staged changes followed by the unstaged ones, unless
+// opts.staged limits it to the former.
+func gitDiff(ctx context.Context, cwd string, opts diffOptions) ([]DiffFile, error) {
+	stagedRuns := []bool{true}
+	if !opts.staged {
+		stagedRuns = append(stagedRuns, false)
+	}
+
+	bash := &fs.BashTool{}
+	var files []DiffFile
+	for _, staged := range stagedRuns {
+		command := gitDiffUnstagedCommand
+		if staged {
+			command = gitDiffStagedCommand
+		}
+		if opts.path != "" {
+			command += " -- " + quoteShellArg(opts.path)
+		}
+		res := bash.Execute(ctx, map[string]any{"command": command}, cwd)
+		if !res.Success {
+			return nil, fmt.Errorf("%s: %s", command, strings.TrimSpace(res.Output+" "+res.Error))
+		}
+		files = append(files, splitDiffFiles(res.Output, staged)...)
+	}
+	return files, nil
+}
+
+// splitDiffFiles splits git diff output at each "diff --git a/<old> b/<new>"
+// line.
+func splitDiffFiles(out string, staged bool) []DiffFile {
+	var files []DiffFile
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		header := lines[start]
+		path := header
+		if i := strings.LastIndex(header, " b/"); i >= 0 {
+			path = header[i+len(" b/"):]
+		}
+		files = append(files, DiffFile{Path: path, Staged: staged, Diff: strings.Join(lines[start:end], "\n")})
+	}
+	for i, line := range lines {
+		if strings.HasPrefix(line, "diff --git ") {
+			flush(i)
+			start = i
+		}
+	}
+	flush(len(lines))
+	return files
+}
+
+func quoteShellArg(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
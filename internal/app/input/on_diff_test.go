@@ -0,0 +1,117 @@
+package input
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runGitDiffTest(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+func newDiffTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repo := t.TempDir()
+	runGitDiffTest(t, repo, "init")
+	runGitDiffTest(t, repo, "config", "user.email", "test@example.com")
+	runGitDiffTest(t, repo, "config", "user.name", "Test")
+	for _, name := range []string{"staged.txt", "unstaged.txt"} {
+		if err := os.WriteFile(filepath.Join(repo, name), []byte("old\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runGitDiffTest(t, repo, "add", ".")
+	runGitDiffTest(t, repo, "commit", "-m", "init")
+	return repo
+}
+
+func TestHandleDiffCommand(t *testing.T) {
+	ctx := context.Background()
+	repo := newDiffTestRepo(t)
+
+	out, err := HandleDiffCommand(ctx, repo, "")
+	if err != nil || out.Message != "No uncommitted changes." || out.Files != nil || out.Note != "" {
+		t.Fatalf("clean repo = %+v, %v", out, err)
+	}
+
+	for _, name := range []string{"staged.txt", "unstaged.txt"} {
+		if err := os.WriteFile(filepath.Join(repo, name), []byte("new\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runGitDiffTest(t, repo, "add", "staged.txt")
+
+	out, err = HandleDiffCommand(ctx, repo, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Message != "Uncommitted changes (2 files):" || out.Note != "" {
+		t.Errorf("got %+v, want a summary and no note without --send", out)
+	}
+	if len(out.Files) != 2 || out.Files[0].Label() != "staged.txt (staged)" || out.Files[1].Label() != "unstaged.txt" {
+		t.Fatalf("files = %+v, want staged then unstaged changes", out.Files)
+	}
+	if f := out.Files[1]; !strings.HasPrefix(f.Diff, "diff --git a/unstaged.txt") || !strings.Contains(f.Diff, "\n-old\n+new") {
+		t.Errorf("unstaged diff = %q", f.Diff)
+	}
+
+	out, _ = HandleDiffCommand(ctx, repo, "--staged")
+	if out.Message != "Staged changes (1 file):" || len(out.Files) != 1 || !out.Files[0].Staged {
+		t.Errorf("--staged = %+v, want only the staged file", out)
+	}
+
+	out, _ = HandleDiffCommand(ctx, repo, "unstaged.txt")
+	if len(out.Files) != 1 || out.Files[0].Path != "unstaged.txt" {
+		t.Errorf("path filter = %+v, want only unstaged.txt", out.Files)
+	}
+
+	out, err = HandleDiffCommand(ctx, repo, "--send")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Message != "" || out.Files != nil || !strings.HasPrefix(out.Note, "Uncommitted changes:\n```diff\ndiff --git a/staged.txt") || !strings.HasSuffix(out.Note, "\n```") {
+		t.Errorf("--send = %+v, want the diff as a fenced note", out)
+	}
+}
+
+func TestHandleDiffCommandOutsideRepo(t *testing.T) {
+	dir := t.TempDir()
+	out, err := HandleDiffCommand(context.Background(), dir, "")
+	if err != nil || out.Message != "No uncommitted changes ("+dir+" is not a git repository)." || out.Files != nil {
+		t.Errorf("got %+v, %v", out, err)
+	}
+
+	for _, args := range []string{"--bogus", "a.txt b.txt"} {
+		out, _ = HandleDiffCommand(context.Background(), dir, args)
+		if !strings.HasPrefix(out.Message, "Usage: /diff") {
+			t.Errorf("%q should print usage, got %q", args, out.Message)
+		}
+	}
+}
+
+func TestSplitDiffFiles(t *testing.T) {
+	out := "diff --git a/a.go b/a.go\nindex 1..2\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-x\n+y\n" +
+		"diff --git a/old.txt b/new.txt\nrename from old.txt\nrename to new.txt\n"
+	files := splitDiffFiles(out, false)
+	if len(files) != 2 || files[0].Path != "a.go" || files[1].Path != "new.txt" {
+		t.Fatalf("files = %+v", files)
+	}
+	if !strings.HasSuffix(files[0].Diff, "+y") || strings.Contains(files[0].Diff, "rename") {
+		t.Errorf("first diff = %q", files[0].Diff)
+	}
+	if splitDiffFiles("", true) != nil {
+		t.Error("empty output should have no files")
+	}
+}
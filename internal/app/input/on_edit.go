@@ -0,0 +1,121 @@
+package input
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+// EditState tracks a sent message loaded back into the prompt. Submitting
+// replaces that message, and everything after it, with the edited text.
+type EditState struct {
+	Active bool
+	Index  int // position of the message in the conversation
+}
+
+// LastEditableMessage returns the index of the last prompt the user typed,
+// or -1 when there is none. Tool results, notices, context notes, and slash
+// commands are skipped.
+func LastEditableMessage(msgs []core.ChatMessage) int {
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if isTypedPrompt(msgs[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// typedPrompts returns the indexes of the prompts the user typed, oldest
+// first. /edit and /branch number prompts by their position in this list.
+func typedPrompts(msgs []core.ChatMessage) []int {
+	var prompts []int
+	for i, msg := range msgs {
+		if isTypedPrompt(msg) {
+			prompts = append(prompts, i)
+		}
+	}
+	return prompts
+}
+
+// isTypedPrompt reports whether msg is a prompt the user typed, as opposed
+// to a tool result, notice, context note, or slash command.
+func isTypedPrompt(msg core.ChatMessage) bool {
+	if msg.Role != core.RoleUser || msg.ToolResult != nil {
+		return false
+	}
+	return !strings.HasPrefix(strings.TrimSpace(editText(msg)), "/")
+}
+
+// BeginEdit loads msg, found at index in the conversation, into the prompt.
+func (m *Model) BeginEdit(index int, msg core.ChatMessage) {
+	m.Reset()
+	m.History.Index = -1
+	m.Textarea.SetValue(editText(msg))
+	m.Textarea.CursorEnd()
+	m.UpdateHeight()
+	m.Edit = EditState{Active: true, Index: index}
+}
+
+// editText returns a message as the user typed it.
+func editText(msg core.ChatMessage) string {
+	if msg.DisplayContent != "" {
+		return msg.DisplayContent
+	}
+	return msg.Content
+}
+
+// RetryMsg asks for a fresh answer to the last prompt the user typed.
+type RetryMsg struct{}
+
+// handleRetryCommand drops the answer to the last typed prompt and sends
+// the prompt again. The work is done on RetryMsg, after the command line
+// has been cleared and committed.
+func (c *CommandController) handleRetryCommand(_ context.Context, _ string) (string, tea.Cmd, error) {
+	if c.deps.Conversation.Stream.Active {
+		return "Can't retry while a response is streaming.", nil, nil
+	}
+	if LastEditableMessage(c.deps.Conversation.Messages) < 0 {
+		return "Nothing to retry — no prompt sent yet.", nil, nil
+	}
+	return "", func() tea.Msg { return RetryMsg{} }, nil
+}
+
+// EditSelectedMsg asks to load the message at Index into the prompt for
+// editing.
+type EditSelectedMsg struct {
+	Index int
+}
+
+// handleEditCommand loads the nth prompt of the conversation back into the
+// input. Without an argument it lists the prompts with their numbers.
+func (c *CommandController) handleEditCommand(_ context.Context, args string) (string, tea.Cmd, error) {
+	if c.deps.Conversation.Stream.Active {
+		return "Can't edit while a response is streaming.", nil, nil
+	}
+	msgs := c.deps.Conversation.Messages
+	prompts := typedPrompts(msgs)
+	if len(prompts) == 0 {
+		return "Nothing to edit — no prompts sent yet.", nil, nil
+	}
+
+	args = strings.TrimSpace(args)
+	if args == "" {
+		var sb strings.Builder
+		sb.WriteString("Prompts (use /edit <n> to edit one):")
+		for n, idx := range prompts {
+			fmt.Fprintf(&sb, "\n  %d. %s", n+1, sessionTruncateToFirstLine(editText(msgs[idx]), 80))
+		}
+		return sb.String(), nil, nil
+	}
+	n, err := strconv.Atoi(args)
+	if err != nil || n < 1 || n > len(prompts) {
+		return fmt.Sprintf("Usage: /edit <n>, where n is a prompt number from 1 to %d", len(prompts)), nil, nil
+	}
+	idx := prompts[n-1]
+	return "", func() tea.Msg { return EditSelectedMsg{Index: idx} }, nil
+}
@@ -0,0 +1,154 @@
+package input
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yanmxa/gencode/internal/app/conv"
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+type editRuntime struct {
+	calls []string
+	conv  *conv.ConversationModel
+}
+
+func (r *editRuntime) CommitMessages() []tea.Cmd       { return nil }
+func (r *editRuntime) QuitWithCancel() (tea.Cmd, bool) { return nil, true }
+func (r *editRuntime) SendToActiveAgent(string, []core.Image) tea.Cmd {
+	return nil
+}
+
+func (r *editRuntime) StartProviderTurn(content string) tea.Cmd {
+	r.calls = append(r.calls, "start:"+content)
+	return func() tea.Msg { return nil }
+}
+
+func (r *editRuntime) RewindConversation(index int) tea.Cmd {
+	r.calls = append(r.calls, "rewind")
+	r.conv.Messages = r.conv.Messages[:index]
+	return func() tea.Msg { return nil }
+}
+
+func TestLastEditableMessage(t *testing.T) {
+	msgs := []core.ChatMessage{
+		{Role: core.RoleUser, Content: "explain", DisplayContent: "explain @main.go"},
+		{Role: core.RoleAssistant, Content: "answer"},
+		{Role: core.RoleUser, Content: "/changes"},
+		{Role: core.RoleNotice, Content: "No files changed this session."},
+		{Role: core.RoleContext, Content: "a note"},
+		{Role: core.RoleUser, ToolResult: &core.ToolResult{ToolCallID: "1"}},
+	}
+	if got := LastEditableMessage(msgs); got != 0 {
+		t.Fatalf("LastEditableMessage = %d, want 0", got)
+	}
+	if got := LastEditableMessage(msgs[1:]); got != -1 {
+		t.Fatalf("LastEditableMessage without prompts = %d, want -1", got)
+	}
+
+	m := New("", 40, nil, SelectorDeps{})
+	m.BeginEdit(0, msgs[0])
+	if got := m.Textarea.Value(); got != "explain @main.go" {
+		t.Errorf("input = %q, want the text as typed", got)
+	}
+}
+
+func TestSubmitWhileEditingRewindsFirst(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	conversation := conv.NewConversation()
+	conversation.Append(core.ChatMessage{Role: core.RoleUser, Content: "helo"})
+	conversation.Append(core.ChatMessage{Role: core.RoleAssistant, Content: "Hi!"})
+	rt := &editRuntime{conv: &conversation}
+	in := New("", 40, nil, SelectorDeps{})
+	deps := SubmitDeps{
+		Actions:         rt,
+		Input:           &in,
+		Conversation:    &conversation,
+		CheckPromptHook: func(context.Context, string) (bool, string) { return false, "" },
+		HandleCommand:   func(string) (tea.Cmd, bool) { return nil, false },
+	}
+
+	in.BeginEdit(0, conversation.Messages[0])
+	in.Textarea.SetValue("hello")
+	if cmd := HandleSubmit(deps); cmd == nil {
+		t.Fatal("HandleSubmit returned no command")
+	}
+	if len(rt.calls) != 2 || rt.calls[0] != "rewind" || rt.calls[1] != "start:hello" {
+		t.Fatalf("calls = %v, want rewind then start", rt.calls)
+	}
+	if len(conversation.Messages) != 1 || conversation.Messages[0].Content != "hello" {
+		t.Fatalf("messages = %+v, want only the edited prompt", conversation.Messages)
+	}
+	if in.Edit.Active {
+		t.Error("the edit should end after submitting")
+	}
+
+	rt.calls = nil
+	in.Textarea.SetValue("next")
+	HandleSubmit(deps)
+	if len(rt.calls) != 1 || rt.calls[0] != "start:next" {
+		t.Fatalf("calls = %v, want a plain submit", rt.calls)
+	}
+}
+
+func TestRetryCommandGuards(t *testing.T) {
+	conversation := conv.NewConversation()
+	ctrl := NewCommandController(CommandDeps{Input: &Model{}, Conversation: &conversation})
+
+	if msg, cmd, _ := ctrl.handleRetryCommand(context.Background(), ""); cmd != nil || msg == "" {
+		t.Fatalf("retry with no prompt = %q, cmd=%v; want a note and no retry", msg, cmd != nil)
+	}
+
+	conversation.Append(core.ChatMessage{Role: core.RoleUser, Content: "hello"})
+	conversation.Stream.Active = true
+	if _, cmd, _ := ctrl.handleRetryCommand(context.Background(), ""); cmd != nil {
+		t.Fatal("retry must not run while streaming")
+	}
+
+	conversation.Stream.Active = false
+	_, cmd, _ := ctrl.handleRetryCommand(context.Background(), "")
+	if cmd == nil {
+		t.Fatal("retry should be scheduled")
+	}
+	if _, ok := cmd().(RetryMsg); !ok {
+		t.Error("retry command should emit RetryMsg")
+	}
+}
+
+func TestEditCommandSelectsNthPrompt(t *testing.T) {
+	conversation := conv.NewConversation()
+	ctrl := NewCommandController(CommandDeps{Input: &Model{}, Conversation: &conversation})
+	if msg, cmd, _ := ctrl.handleEditCommand(context.Background(), "1"); cmd != nil || msg == "" {
+		t.Fatalf("edit with no prompts = %q, cmd=%v", msg, cmd != nil)
+	}
+
+	conversation.Messages = []core.ChatMessage{
+		{Role: core.RoleUser, Content: "first question"},
+		{Role: core.RoleAssistant, ToolCalls: []core.ToolCall{{ID: "1", Name: "Read"}}},
+		{Role: core.RoleUser, ToolResult: &core.ToolResult{ToolCallID: "1"}},
+		{Role: core.RoleUser, Content: "/cost"},
+		{Role: core.RoleUser, Content: "second question"},
+		{Role: core.RoleAssistant, Content: "answer"},
+	}
+
+	list, cmd, _ := ctrl.handleEditCommand(context.Background(), "")
+	if cmd != nil || !strings.Contains(list, "1. first question") || !strings.Contains(list, "2. second question") {
+		t.Fatalf("/edit list =\n%s", list)
+	}
+	for _, bad := range []string{"0", "3", "two"} {
+		if msg, cmd, _ := ctrl.handleEditCommand(context.Background(), bad); cmd != nil || !strings.Contains(msg, "Usage") {
+			t.Errorf("/edit %s = %q, want usage", bad, msg)
+		}
+	}
+
+	_, cmd, _ = ctrl.handleEditCommand(context.Background(), "2")
+	if cmd == nil {
+		t.Fatal("/edit 2 should select the second prompt")
+	}
+	if got, ok := cmd().(EditSelectedMsg); !ok || got.Index != 4 {
+		t.Errorf("msg = %+v, want EditSelectedMsg{Index: 4}", got)
+	}
+}
@@ -0,0 +1,146 @@
+package input
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+// HandleExportCommand writes the conversation to a Markdown file: the path
+// in args (a directory gets the default name), or gen-session-<timestamp>.md
+// in cwd. Relative paths are resolved against cwd. An existing file is only
+// overwritten when args include --force.
+func HandleExportCommand(msgs []core.ChatMessage, cwd, args string, now time.Time) (string, error) {
+	if !hasExportableMessages(msgs) {
+		return "Nothing to export yet.", nil
+	}
+
+	force := false
+	var rest []string
+	for _, f := range strings.Fields(args) {
+		if f == "--force" {
+			force = true
+			continue
+		}
+		rest = append(rest, f)
+	}
+
+	name := "gen-session-" + now.Format("20060102-150405") + ".md"
+	path := strings.Join(rest, " ")
+	switch {
+	case path == "":
+		path = name
+	case path == "~" || strings.HasPrefix(path, "~/"):
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve home directory: %w", err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cwd, path)
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, name)
+	}
+	if _, err := os.Stat(path); err == nil && !force {
+		return "", fmt.Errorf("%s already exists; use /export --force to overwrite it", path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(FormatTranscript(msgs, now)), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return "Exported conversation to " + path, nil
+}
+
+func hasExportableMessages(msgs []core.ChatMessage) bool {
+	for _, msg := range msgs {
+		if msg.Role != core.RoleNotice {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatTranscript renders the conversation as Markdown. User turns get a
+// "## You" heading, assistant text is kept as-is, and tool calls go in
+// fenced blocks labelled with the tool name. Tool results are fenced too,
+// inside a collapsed <details> section. Notices are UI-only and are left out.
+func FormatTranscript(msgs []core.ChatMessage, now time.Time) string {
+	var sb strings.Builder
+	sb.WriteString("# Conversation\n\n")
+	fmt.Fprintf(&sb, "_Exported %s_\n", now.Format("2006-01-02 15:04"))
+
+	toolNames := make(map[string]string)
+	lastRole := core.Role("")
+	for _, msg := range msgs {
+		switch {
+		case msg.ToolResult != nil:
+			name := msg.ToolResult.ToolName
+			if name == "" {
+				name = cmp.Or(msg.ToolName, toolNames[msg.ToolResult.ToolCallID])
+			}
+			label := "Result: " + name
+			if msg.ToolResult.IsError {
+				label = "Error: " + name
+			}
+			fmt.Fprintf(&sb, "\n<details>\n<summary>%s</summary>\n", label)
+			writeFenced(&sb, "", "", msg.ToolResult.Content)
+			sb.WriteString("\n</details>\n")
+
+		case msg.Role == core.RoleUser:
+			sb.WriteString("\n## You\n\n")
+			sb.WriteString(strings.TrimSpace(cmp.Or(msg.DisplayContent, msg.Content)))
+			sb.WriteString("\n")
+			for _, img := range msg.Images {
+				fmt.Fprintf(&sb, "\n_[image: %s]_\n", cmp.Or(img.FileName, img.MediaType))
+			}
+
+		case msg.Role == core.RoleContext:
+			fmt.Fprintf(&sb, "\n> **Note:** %s\n", strings.TrimSpace(msg.Content))
+
+		case msg.Role == core.RoleAssistant:
+			if lastRole != core.RoleAssistant {
+				sb.WriteString("\n## Assistant\n")
+			}
+			if content := strings.TrimSpace(msg.Content); content != "" {
+				sb.WriteString("\n")
+				sb.WriteString(content)
+				sb.WriteString("\n")
+			}
+			for _, tc := range msg.ToolCalls {
+				toolNames[tc.ID] = tc.Name
+				writeFenced(&sb, "Tool: "+tc.Name, "json", tc.Input)
+			}
+
+		default:
+			continue
+		}
+		// Tool results continue the assistant's turn.
+		if msg.ToolResult == nil {
+			lastRole = msg.Role
+		}
+	}
+	return sb.String()
+}
+
+// writeFenced writes a bold label, if any, followed by content in a code
+// fence long enough not to be closed by backticks inside the content.
+func writeFenced(sb *strings.Builder, label, lang, content string) {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+	if label != "" {
+		fmt.Fprintf(sb, "\n**%s**\n", label)
+	}
+	fmt.Fprintf(sb, "\n%s%s\n%s\n%s\n", fence, lang, strings.TrimRight(content, "\n"), fence)
+}
@@ -0,0 +1,81 @@
+package input
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+var exportTime = time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+
+func exportTestMessages() []core.ChatMessage {
+	return []core.ChatMessage{
+		{Role: core.RoleNotice, Content: "Connected to anthropic"},
+		{Role: core.RoleUser, Content: "expanded skill prompt", DisplayContent: "/review main.go"},
+		{Role: core.RoleAssistant, Content: "Let me look.", ToolCalls: []core.ToolCall{{ID: "tc1", Name: "Read", Input: `{"file_path":"main.go"}`}}},
+		{Role: core.RoleUser, ToolResult: &core.ToolResult{ToolCallID: "tc1", Content: "package main\n```go\n```\n"}},
+		{Role: core.RoleAssistant, Content: "Looks fine."},
+	}
+}
+
+func TestFormatTranscript(t *testing.T) {
+	out := FormatTranscript(exportTestMessages(), exportTime)
+
+	for _, want := range []string{
+		"## You\n\n/review main.go\n",
+		"## Assistant\n\nLet me look.\n",
+		"**Tool: Read**\n\n```json\n{\"file_path\":\"main.go\"}\n```\n",
+		"<details>\n<summary>Result: Read</summary>\n\n````\npackage main\n```go\n```\n````\n\n</details>\n",
+		"\nLooks fine.\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("transcript missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Connected to anthropic") {
+		t.Error("notices should be skipped")
+	}
+	if n := strings.Count(out, "## Assistant"); n != 1 {
+		t.Errorf("assistant heading appears %d times, want one per turn", n)
+	}
+}
+
+func TestHandleExportCommand(t *testing.T) {
+	dir := t.TempDir()
+	msgs := exportTestMessages()
+
+	result, err := HandleExportCommand(msgs, dir, "", exportTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(dir, "gen-session-20260304-050607.md")
+	if !strings.Contains(result, want) {
+		t.Fatalf("result = %q, want the written path %s", result, want)
+	}
+	if data, err := os.ReadFile(want); err != nil || !strings.Contains(string(data), "## You") {
+		t.Fatalf("transcript not written: %v", err)
+	}
+
+	if _, err := HandleExportCommand(msgs, dir, "out/chat.md", exportTime); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out", "chat.md")); err != nil {
+		t.Errorf("relative path not resolved against cwd: %v", err)
+	}
+
+	if _, err := HandleExportCommand(msgs, dir, "out/chat.md", exportTime); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("overwriting without --force: err = %v, want a refusal", err)
+	}
+	if _, err := HandleExportCommand(msgs, dir, "--force out/chat.md", exportTime); err != nil {
+		t.Errorf("--force should overwrite: %v", err)
+	}
+
+	result, _ = HandleExportCommand(msgs[:1], dir, "", exportTime)
+	if !strings.Contains(result, "Nothing to export") {
+		t.Errorf("notices only: result = %q", result)
+	}
+}
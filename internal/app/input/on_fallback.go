@@ -0,0 +1,141 @@
+package input
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yanmxa/gencode/internal/llm"
+)
+
+const fallbackChainUsage = `Usage:
+  /fallback [list]                         - Show the chain
+  /fallback add <provider:model> [pos]     - Add a model (at the end, or at pos)
+  /fallback remove <pos|provider:model>    - Remove a model
+  /fallback move <from> <to>               - Reorder a model
+  /fallback clear                          - Remove all models
+/model fallback-chain takes the same arguments.`
+
+// HandleFallbackChainCommand shows or edits the fallback chain: the models
+// tried in order when a request to the current model fails before answering.
+func HandleFallbackChainCommand(store *llm.Store, args string) (string, error) {
+	if store == nil {
+		return "Provider store unavailable.", nil
+	}
+	chain := store.GetFallbackChain()
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return formatFallbackChain(store, chain), nil
+	}
+
+	var err error
+	switch fields[0] {
+	case "list", "ls":
+		return formatFallbackChain(store, chain), nil
+	case "add":
+		chain, err = addFallback(chain, fields[1:])
+	case "remove", "rm":
+		chain, err = removeFallback(chain, fields[1:])
+	case "move", "mv":
+		chain, err = moveFallback(chain, fields[1:])
+	case "clear":
+		chain = nil
+	default:
+		return fallbackChainUsage, nil
+	}
+	if err != nil {
+		return fmt.Sprintf("%v\n\n%s", err, fallbackChainUsage), nil
+	}
+	if err := store.SetFallbackChain(chain); err != nil {
+		return "", fmt.Errorf("failed to save fallback chain: %w", err)
+	}
+	return formatFallbackChain(store, chain), nil
+}
+
+func formatFallbackChain(store *llm.Store, chain []llm.FallbackEntry) string {
+	if len(chain) == 0 {
+		return "No fallback chain. Add a model with /fallback add <provider:model>."
+	}
+	width := 0
+	for _, e := range chain {
+		width = max(width, len(e.String()))
+	}
+	var sb strings.Builder
+	sb.WriteString("Fallback chain (tried in order when a request fails before answering):\n")
+	for i, e := range chain {
+		status := "connected"
+		if _, ok := store.GetConnection(e.Provider); !ok {
+			status = "not connected, skipped"
+		}
+		fmt.Fprintf(&sb, "  %d. %-*s  %s\n", i+1, width, e.String(), status)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func addFallback(chain []llm.FallbackEntry, args []string) ([]llm.FallbackEntry, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return nil, fmt.Errorf("add takes a model and an optional position")
+	}
+	entry, err := llm.ParseFallbackEntry(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(chain, entry) {
+		return nil, fmt.Errorf("%s is already in the chain", entry)
+	}
+	pos := len(chain) + 1
+	if len(args) == 2 {
+		if pos, err = parseFallbackPos(args[1], len(chain)+1); err != nil {
+			return nil, err
+		}
+	}
+	return slices.Insert(chain, pos-1, entry), nil
+}
+
+func removeFallback(chain []llm.FallbackEntry, args []string) ([]llm.FallbackEntry, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("remove takes a position or provider:model")
+	}
+	if entry, err := llm.ParseFallbackEntry(args[0]); err == nil {
+		i := slices.Index(chain, entry)
+		if i < 0 {
+			return nil, fmt.Errorf("%s is not in the chain", entry)
+		}
+		return slices.Delete(chain, i, i+1), nil
+	}
+	pos, err := parseFallbackPos(args[0], len(chain))
+	if err != nil {
+		return nil, err
+	}
+	return slices.Delete(chain, pos-1, pos), nil
+}
+
+func moveFallback(chain []llm.FallbackEntry, args []string) ([]llm.FallbackEntry, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("move takes two positions")
+	}
+	from, err := parseFallbackPos(args[0], len(chain))
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseFallbackPos(args[1], len(chain))
+	if err != nil {
+		return nil, err
+	}
+	entry := chain[from-1]
+	chain = slices.Delete(chain, from-1, from)
+	return slices.Insert(chain, to-1, entry), nil
+}
+
+// parseFallbackPos parses a 1-based position no greater than limit.
+func parseFallbackPos(s string, limit int) (int, error) {
+	pos, err := strconv.Atoi(s)
+	if err != nil || pos < 1 || pos > limit {
+		if limit == 0 {
+			return 0, fmt.Errorf("the fallback chain is empty")
+		}
+		return 0, fmt.Errorf("position must be between 1 and %d", limit)
+	}
+	return pos, nil
+}
@@ -0,0 +1,260 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/yanmxa/gencode/internal/app/kit"
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+// findMatch is a conversation message containing the query.
+type findMatch struct {
+	index   int    // position of the message in the conversation
+	role    string // label shown before the snippet
+	text    string // the searchable text of the message
+	count   int    // occurrences of the query
+	snippet string // one line around the first occurrence
+}
+
+// FindState searches the messages of the current conversation. Matches are
+// listed with a snippet; Enter shows the selected message in full with
+// every occurrence highlighted. Type to change the query.
+type FindState struct {
+	active  bool
+	query   string
+	msgs    []core.ChatMessage
+	matches []findMatch
+	nav     kit.ListNav
+	detail  bool // showing the selected message in full
+	width   int
+	height  int
+}
+
+// Enter opens the search over msgs with an initial query, which may be empty.
+func (f *FindState) Enter(msgs []core.ChatMessage, query string, width, height int) {
+	*f = FindState{
+		active: true,
+		query:  query,
+		msgs:   msgs,
+		width:  width,
+		height: height,
+		nav:    kit.ListNav{MaxVisible: max(3, min(height-8, 20))},
+	}
+	f.updateMatches()
+}
+
+func (f *FindState) IsActive() bool {
+	return f.active
+}
+
+func (f *FindState) Cancel() {
+	*f = FindState{}
+}
+
+// findText returns the part of msg that is searched and shown.
+func findText(msg core.ChatMessage) (role, text string) {
+	switch {
+	case msg.ToolResult != nil:
+		name := msg.ToolName
+		if name == "" {
+			name = msg.ToolResult.ToolName
+		}
+		return "tool " + name, msg.ToolResult.Content
+	case msg.Role == core.RoleUser:
+		return "you", editText(msg)
+	case msg.Role == core.RoleAssistant:
+		return "assistant", msg.Content
+	default:
+		return string(msg.Role), msg.Content
+	}
+}
+
+func (f *FindState) updateMatches() {
+	f.matches = f.matches[:0]
+	f.detail = false
+	query := strings.ToLower(f.query)
+	if query != "" {
+		for i, msg := range f.msgs {
+			role, text := findText(msg)
+			lower := strings.ToLower(text)
+			count := strings.Count(lower, query)
+			if count == 0 {
+				continue
+			}
+			f.matches = append(f.matches, findMatch{
+				index:   i,
+				role:    role,
+				text:    text,
+				count:   count,
+				snippet: findSnippet(text, strings.Index(lower, query), len(query), 30),
+			})
+		}
+	}
+	f.nav.ResetCursor()
+	f.nav.Total = len(f.matches)
+}
+
+// findSnippet returns the text around the match at pos on one line, with
+// context characters of padding on either side.
+func findSnippet(text string, pos, length, context int) string {
+	start, end := max(0, pos-context), min(len(text), pos+length+context)
+	// Keep multi-byte runes whole.
+	for start > 0 && !isRuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !isRuneStart(text[end]) {
+		end++
+	}
+	snippet := strings.Join(strings.Fields(text[start:end]), " ")
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(text) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// highlightMatches renders every case-insensitive occurrence of query in
+// text with style. Text whose length changes when lower-cased is returned
+// as-is, since the match offsets would not line up.
+func highlightMatches(text, query string, style lipgloss.Style) string {
+	lower, q := strings.ToLower(text), strings.ToLower(query)
+	if q == "" || len(lower) != len(text) {
+		return text
+	}
+	var sb strings.Builder
+	for {
+		i := strings.Index(lower, q)
+		if i < 0 {
+			sb.WriteString(text)
+			return sb.String()
+		}
+		sb.WriteString(text[:i])
+		sb.WriteString(style.Render(text[i : i+len(q)]))
+		text, lower = text[i+len(q):], lower[i+len(q):]
+	}
+}
+
+// move steps the selection by delta, wrapping around at either end.
+func (f *FindState) move(delta int) {
+	if len(f.matches) == 0 {
+		return
+	}
+	f.nav.Selected = (f.nav.Selected + delta + len(f.matches)) % len(f.matches)
+	f.nav.EnsureVisible()
+}
+
+func (f *FindState) HandleKeypress(key tea.KeyMsg) tea.Cmd {
+	switch key.Type {
+	case tea.KeyUp, tea.KeyCtrlP, tea.KeyShiftTab:
+		f.move(-1)
+	case tea.KeyDown, tea.KeyCtrlN, tea.KeyTab:
+		f.move(1)
+	case tea.KeyEnter:
+		if len(f.matches) > 0 {
+			f.detail = !f.detail
+		}
+	case tea.KeyEsc:
+		if f.detail {
+			f.detail = false
+			return nil
+		}
+		f.Cancel()
+		return func() tea.Msg { return kit.DismissedMsg{} }
+	case tea.KeyBackspace:
+		if f.query != "" {
+			r := []rune(f.query)
+			f.query = string(r[:len(r)-1])
+			f.updateMatches()
+		}
+	case tea.KeyRunes:
+		f.query += string(key.Runes)
+		f.updateMatches()
+	case tea.KeySpace:
+		f.query += " "
+		f.updateMatches()
+	}
+	return nil
+}
+
+func (f *FindState) Render() string {
+	if !f.active {
+		return ""
+	}
+
+	var sb strings.Builder
+	title := fmt.Sprintf("Search Conversation (%d)", len(f.matches))
+	if len(f.matches) > 0 {
+		title = fmt.Sprintf("Search Conversation (%d/%d)", f.nav.Selected+1, len(f.matches))
+	}
+	sb.WriteString(kit.SelectorTitleStyle().Render(title) + "\n")
+
+	searchLine := "🔍 Type to search messages..."
+	searchStyle := kit.SelectorHintStyle()
+	if f.query != "" {
+		searchLine = "> " + f.query + "_"
+		searchStyle = kit.SelectorBreadcrumbStyle()
+	}
+	sb.WriteString(searchStyle.Render(searchLine) + "\n\n")
+
+	mark := lipgloss.NewStyle().Reverse(true)
+	metaStyle := lipgloss.NewStyle().Foreground(kit.CurrentTheme.Muted)
+	switch {
+	case f.query == "":
+	case len(f.matches) == 0:
+		sb.WriteString(kit.SelectorHintStyle().Render("  No messages match") + "\n")
+	case f.detail:
+		m := f.matches[f.nav.Selected]
+		sb.WriteString(metaStyle.Render(fmt.Sprintf("  message %d · %s", m.index+1, m.role)) + "\n")
+		lines := strings.Split(lipgloss.NewStyle().Width(max(20, f.width-4)).Render(m.text), "\n")
+		limit := max(3, f.height-10)
+		if len(lines) > limit {
+			lines = append(lines[:limit], "…")
+		}
+		for _, line := range lines {
+			sb.WriteString("  " + highlightMatches(line, f.query, mark) + "\n")
+		}
+	default:
+		start, end := f.nav.VisibleRange()
+		if start > 0 {
+			sb.WriteString(kit.SelectorHintStyle().Render("  ↑ more above") + "\n")
+		}
+		for i := start; i < end; i++ {
+			m := f.matches[i]
+			indent := "  "
+			if i == f.nav.Selected {
+				indent = "> "
+			}
+			label := fmt.Sprintf("%s%d. %s: ", indent, m.index+1, m.role)
+			meta := ""
+			if m.count > 1 {
+				meta = fmt.Sprintf("%d matches", m.count)
+			}
+			snippet := kit.TruncateText(m.snippet, max(10, f.width-len(label)-len(meta)-6))
+			style := kit.SelectorItemStyle()
+			if i == f.nav.Selected {
+				style = kit.SelectorSelectedStyle()
+			}
+			sb.WriteString(style.Render(label) + highlightMatches(snippet, f.query, mark) + "  " + metaStyle.Render(meta) + "\n")
+		}
+		if end < len(f.matches) {
+			sb.WriteString(kit.SelectorHintStyle().Render("  ↓ more below") + "\n")
+		}
+	}
+
+	hint := "↑/↓ previous/next match · Enter show message · Esc close"
+	if f.detail {
+		hint = "↑/↓ previous/next match · Enter/Esc back to the list"
+	}
+	sb.WriteString("\n" + kit.SelectorHintStyle().Render(hint))
+	return sb.String()
+}
@@ -0,0 +1,94 @@
+package input
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/yanmxa/gencode/internal/app/conv"
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+func TestSearchCommandFindsMessages(t *testing.T) {
+	conversation := conv.NewConversation()
+	conversation.Messages = []core.ChatMessage{
+		{Role: core.RoleUser, Content: "Where is the Config loaded?"},
+		{Role: core.RoleAssistant, ToolCalls: []core.ToolCall{{ID: "1", Name: "Grep"}}},
+		{Role: core.RoleUser, ToolName: "Grep", ToolResult: &core.ToolResult{ToolCallID: "1", Content: "config.go:12: func LoadConfig"}},
+		{Role: core.RoleAssistant, Content: "It has nothing to do with that."},
+		{Role: core.RoleAssistant, Content: "The config is read in LoadConfig; see CONFIG docs."},
+	}
+	state := &Model{}
+	ctrl := NewCommandController(CommandDeps{Input: state, Conversation: &conversation, Width: 80, Height: 24})
+
+	if _, _, err := ctrl.handleSearchCommand(context.Background(), "config"); err != nil {
+		t.Fatal(err)
+	}
+	f := &state.Find
+	if !f.IsActive() {
+		t.Fatal("/search <text> should open the conversation search")
+	}
+	var got []int
+	for _, m := range f.matches {
+		got = append(got, m.index)
+	}
+	if len(got) != 3 || got[0] != 0 || got[1] != 2 || got[2] != 4 || f.matches[2].count != 3 {
+		t.Fatalf("matches = %+v, want messages 0, 2, 4 case-insensitively", f.matches)
+	}
+	if f.matches[1].role != "tool Grep" {
+		t.Errorf("tool result role = %q", f.matches[1].role)
+	}
+
+	f.HandleKeypress(tea.KeyMsg{Type: tea.KeyUp})
+	if f.nav.Selected != 2 {
+		t.Errorf("Up from the first match should wrap to the last, got %d", f.nav.Selected)
+	}
+	f.HandleKeypress(tea.KeyMsg{Type: tea.KeyDown})
+	if f.nav.Selected != 0 {
+		t.Errorf("Down from the last match should wrap to the first, got %d", f.nav.Selected)
+	}
+
+	f.HandleKeypress(tea.KeyMsg{Type: tea.KeyEnter})
+	if !f.detail || !strings.Contains(f.Render(), "Where is the Config loaded?") {
+		t.Fatalf("Enter should show the message in full:\n%s", f.Render())
+	}
+	if cmd := f.HandleKeypress(tea.KeyMsg{Type: tea.KeyEsc}); cmd != nil || !f.IsActive() {
+		t.Fatal("Esc from a message should go back to the list")
+	}
+	f.HandleKeypress(tea.KeyMsg{Type: tea.KeyEsc})
+	if f.IsActive() {
+		t.Error("Esc from the list should close the search")
+	}
+}
+
+func TestFindStateRefinesQuery(t *testing.T) {
+	var f FindState
+	f.Enter([]core.ChatMessage{{Role: core.RoleUser, Content: "run the tests"}, {Role: core.RoleUser, Content: "run the linter"}}, "", 80, 24)
+	if len(f.matches) != 0 {
+		t.Fatal("an empty query matches nothing")
+	}
+	for _, k := range []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("the")}, {Type: tea.KeySpace}, {Type: tea.KeyRunes, Runes: []rune("l")}} {
+		f.HandleKeypress(k)
+	}
+	if f.query != "the l" || len(f.matches) != 1 || f.matches[0].index != 1 {
+		t.Fatalf("query %q matches %+v", f.query, f.matches)
+	}
+	f.HandleKeypress(tea.KeyMsg{Type: tea.KeyBackspace})
+	if len(f.matches) != 2 {
+		t.Errorf("backspace should widen the search, got %d matches", len(f.matches))
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	style := lipgloss.NewStyle().Bold(true)
+	wrap := func(s string) string { return style.Render(s) }
+	if got, want := highlightMatches("Go go GO", "go", style), wrap("Go")+" "+wrap("go")+" "+wrap("GO"); got != want {
+		t.Errorf("highlightMatches = %q, want %q", got, want)
+	}
+	if got := findSnippet(strings.Repeat("x", 50)+" needle\nhere "+strings.Repeat("y", 50), 51, 6, 10); got != "…xxxxxxxxx needle here yyyy…" {
+		t.Errorf("findSnippet = %q", got)
+	}
+}
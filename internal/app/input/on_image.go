@@ -1,9 +1,57 @@
 package input
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/image"
+	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/tool/toolresult"
 )
 
+// ImageAttachMsg attaches Image to the next prompt, as a pasted image is.
+// /image sends it so the image lands in the input after the command line
+// has been cleared.
+type ImageAttachMsg struct {
+	Image core.Image
+}
+
+// handleImageCommand loads the image at args, relative to the working
+// directory, and attaches it to the next prompt. The current model must
+// accept images.
+func (c *CommandController) handleImageCommand(_ context.Context, args string) (string, tea.Cmd, error) {
+	path := strings.TrimPrefix(strings.Trim(strings.TrimSpace(args), `"'`), "@")
+	if path == "" {
+		return "Usage: /image <path>", nil, nil
+	}
+	if c.deps.LLMProvider != nil && c.deps.CurrentModel != nil && !llm.SupportsImages(c.deps.LLMProvider, c.deps.CurrentModel.ModelID) {
+		return "", nil, fmt.Errorf("%s does not accept images; switch to a vision model with /model", c.deps.CurrentModel.ModelID)
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to resolve home directory: %w", err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(c.deps.Cwd, path)
+	}
+	info, err := image.Load(path)
+	if err != nil {
+		return "", nil, err
+	}
+	img := info.ToProviderData()
+	result := fmt.Sprintf("Attached %s (%s) to your next message.", info.FileName, toolresult.FormatSize(int64(info.Size)))
+	return result, func() tea.Msg { return ImageAttachMsg{Image: img} }, nil
+}
+
 // HandleImageSelectKey handles inline image token selection and deletion.
 // Returns (cmd, true) if the key was consumed, (nil, false) otherwise.
 func (m *Model) HandleImageSelectKey(msg tea.KeyMsg) (tea.Cmd, bool) {
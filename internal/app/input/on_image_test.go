@@ -0,0 +1,49 @@
+package input
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/llm"
+)
+
+// textOnlyProvider is a provider whose models do not read images.
+type textOnlyProvider struct{ llm.Provider }
+
+func (textOnlyProvider) SupportsImages(string) bool { return false }
+
+func TestHandleImageCommand(t *testing.T) {
+	dir := t.TempDir()
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...)
+	if err := os.WriteFile(filepath.Join(dir, "shot.png"), png, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctrl := NewCommandController(CommandDeps{Cwd: dir})
+
+	result, cmd, err := ctrl.handleImageCommand(context.Background(), "@shot.png")
+	if err != nil || cmd == nil || !strings.Contains(result, "Attached shot.png") {
+		t.Fatalf("/image shot.png = %q, cmd=%v, err=%v", result, cmd != nil, err)
+	}
+	if msg, ok := cmd().(ImageAttachMsg); !ok || msg.Image.MediaType != "image/png" || msg.Image.FileName != "shot.png" {
+		t.Errorf("cmd() = %#v, want the loaded image", msg)
+	}
+
+	if _, _, err := ctrl.handleImageCommand(context.Background(), "missing.png"); err == nil {
+		t.Error("a missing file should fail")
+	}
+	if result, _, _ := ctrl.handleImageCommand(context.Background(), ""); !strings.Contains(result, "Usage") {
+		t.Errorf("no path: result = %q, want usage", result)
+	}
+
+	ctrl = NewCommandController(CommandDeps{
+		Cwd:          dir,
+		LLMProvider:  textOnlyProvider{},
+		CurrentModel: &llm.CurrentModelInfo{ModelID: "deepseek-chat"},
+	})
+	if _, _, err := ctrl.handleImageCommand(context.Background(), "shot.png"); err == nil || !strings.Contains(err.Error(), "deepseek-chat does not accept images") {
+		t.Errorf("text-only model: err = %v, want a clear refusal", err)
+	}
+}
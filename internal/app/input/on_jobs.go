@@ -0,0 +1,250 @@
+package input
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/yanmxa/gencode/internal/app/kit"
+	"github.com/yanmxa/gencode/internal/task"
+)
+
+const (
+	// jobsRefreshInterval is how often the /jobs viewer re-reads job output.
+	jobsRefreshInterval = 500 * time.Millisecond
+	// jobOutputLines is how many trailing output lines an expanded job shows.
+	jobOutputLines = 12
+)
+
+// JobsTickMsg refreshes the /jobs viewer while it is open. Seq identifies
+// the viewing it belongs to, so reopening the viewer does not leave two
+// refresh loops running.
+type JobsTickMsg struct {
+	Seq int
+}
+
+// Tick schedules the next refresh of the viewer.
+func (j *JobsViewer) Tick() tea.Cmd {
+	seq := j.seq
+	return tea.Tick(jobsRefreshInterval, func(time.Time) tea.Msg { return JobsTickMsg{Seq: seq} })
+}
+
+// HandleTick refreshes the viewer and schedules the next refresh, or stops
+// once the viewer is closed or reopened.
+func (j *JobsViewer) HandleTick(msg JobsTickMsg) tea.Cmd {
+	if !j.active || msg.Seq != j.seq {
+		return nil
+	}
+	j.Refresh()
+	return j.Tick()
+}
+
+// JobsViewer lists the background Bash commands of the session. Enter
+// expands the selected job to show the tail of its output, which follows
+// the command as it runs; x kills the selected job.
+type JobsViewer struct {
+	active   bool
+	tasks    task.Service
+	jobs     []task.TaskInfo
+	nav      kit.ListNav
+	expanded string // ID of the job whose output is shown
+	seq      int    // bumped on each Enter, see JobsTickMsg
+	width    int
+	height   int
+}
+
+// Enter opens the viewer over the Bash jobs of tasks.
+func (j *JobsViewer) Enter(tasks task.Service, width, height int) {
+	*j = JobsViewer{
+		active: true,
+		tasks:  tasks,
+		width:  width,
+		height: height,
+		nav:    kit.ListNav{MaxVisible: max(3, min(height-8, 15))},
+		seq:    j.seq + 1,
+	}
+	j.Refresh()
+}
+
+func (j *JobsViewer) IsActive() bool {
+	return j.active
+}
+
+func (j *JobsViewer) Cancel() {
+	*j = JobsViewer{seq: j.seq}
+}
+
+// Refresh re-reads the jobs, newest first, keeping the selection on the
+// same job when it is still listed.
+func (j *JobsViewer) Refresh() {
+	if !j.active || j.tasks == nil {
+		return
+	}
+	selected := ""
+	if j.nav.Selected < len(j.jobs) {
+		selected = j.jobs[j.nav.Selected].ID
+	}
+	j.jobs = bashJobs(j.tasks)
+	j.nav.Total = len(j.jobs)
+	j.nav.Selected = 0
+	for i, job := range j.jobs {
+		if job.ID == selected {
+			j.nav.Selected = i
+		}
+	}
+	j.nav.EnsureVisible()
+}
+
+// bashJobs returns the Bash tasks of tasks, newest first.
+func bashJobs(tasks task.Service) []task.TaskInfo {
+	var jobs []task.TaskInfo
+	for _, t := range tasks.List() {
+		if t.GetType() == task.TaskTypeBash {
+			jobs = append(jobs, t.GetStatus())
+		}
+	}
+	sort.Slice(jobs, func(a, b int) bool { return jobs[a].StartTime.After(jobs[b].StartTime) })
+	return jobs
+}
+
+// RunningJobs counts the background Bash commands still running.
+func RunningJobs(tasks task.Service) int {
+	if tasks == nil {
+		return 0
+	}
+	n := 0
+	for _, t := range tasks.ListRunning() {
+		if t.GetType() == task.TaskTypeBash {
+			n++
+		}
+	}
+	return n
+}
+
+func (j *JobsViewer) HandleKeypress(key tea.KeyMsg) tea.Cmd {
+	switch key.Type {
+	case tea.KeyUp, tea.KeyCtrlP:
+		j.nav.MoveUp()
+	case tea.KeyDown, tea.KeyCtrlN:
+		j.nav.MoveDown()
+	case tea.KeyEnter:
+		if j.nav.Selected < len(j.jobs) {
+			id := j.jobs[j.nav.Selected].ID
+			if j.expanded == id {
+				id = ""
+			}
+			j.expanded = id
+		}
+	case tea.KeyEsc:
+		if j.expanded != "" {
+			j.expanded = ""
+			return nil
+		}
+		j.Cancel()
+		return func() tea.Msg { return kit.DismissedMsg{} }
+	case tea.KeyRunes:
+		switch key.String() {
+		case "j":
+			j.nav.MoveDown()
+		case "k":
+			j.nav.MoveUp()
+		case "x":
+			return j.killSelected()
+		}
+	}
+	return nil
+}
+
+// killSelected stops the selected job without blocking the UI; the next
+// refresh shows it as killed.
+func (j *JobsViewer) killSelected() tea.Cmd {
+	if j.nav.Selected >= len(j.jobs) || j.jobs[j.nav.Selected].Status != task.StatusRunning {
+		return nil
+	}
+	tasks, id := j.tasks, j.jobs[j.nav.Selected].ID
+	return func() tea.Msg {
+		_ = tasks.Kill(id)
+		return nil
+	}
+}
+
+func (j *JobsViewer) Render() string {
+	if !j.active {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(kit.SelectorTitleStyle().Render(fmt.Sprintf("Background Jobs (%d running)", countRunning(j.jobs))))
+	sb.WriteString("\n\n")
+
+	dim := kit.DimStyle()
+	if len(j.jobs) == 0 {
+		sb.WriteString(dim.Render("No background commands. The model starts them with Bash run_in_background."))
+		sb.WriteString("\n")
+	}
+	start, end := j.nav.VisibleRange()
+	boxWidth := kit.CalculateBoxWidth(j.width)
+	for i := start; i < end; i++ {
+		job := j.jobs[i]
+		label := fmt.Sprintf("%s  %-8s %s", job.ID, jobStatus(job), kit.TruncateText(jobLabel(job), max(10, boxWidth-30)))
+		sb.WriteString(kit.RenderSelectableRow(label, i == j.nav.Selected))
+		sb.WriteString("\n")
+		if job.ID == j.expanded {
+			sb.WriteString(renderJobOutput(job.Output, boxWidth-8))
+		}
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(dim.Render("↑/↓ navigate · Enter show/hide output · x kill · Esc close"))
+
+	box := kit.SelectorBorderStyle().Width(boxWidth).Render(sb.String())
+	return lipgloss.Place(j.width, j.height-2, lipgloss.Center, lipgloss.Top, box)
+}
+
+func countRunning(jobs []task.TaskInfo) int {
+	n := 0
+	for _, job := range jobs {
+		if job.Status == task.StatusRunning {
+			n++
+		}
+	}
+	return n
+}
+
+func jobLabel(job task.TaskInfo) string {
+	if job.Description != "" {
+		return job.Description
+	}
+	return strings.Join(strings.Fields(job.Command), " ")
+}
+
+func jobStatus(job task.TaskInfo) string {
+	if job.Status == task.StatusFailed && job.ExitCode != 0 {
+		return fmt.Sprintf("exit %d", job.ExitCode)
+	}
+	return string(job.Status)
+}
+
+// renderJobOutput returns the last jobOutputLines lines of output, indented
+// under the job's row.
+func renderJobOutput(output string, width int) string {
+	output = strings.TrimRight(output, "\n")
+	if output == "" {
+		return kit.DimStyle().Render("    (no output yet)") + "\n"
+	}
+	lines := strings.Split(output, "\n")
+	var sb strings.Builder
+	if len(lines) > jobOutputLines {
+		sb.WriteString(kit.DimStyle().Render(fmt.Sprintf("    … %d earlier lines", len(lines)-jobOutputLines)) + "\n")
+		lines = lines[len(lines)-jobOutputLines:]
+	}
+	for _, line := range lines {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		sb.WriteString("    " + kit.TruncateText(line, max(10, width)) + "\n")
+	}
+	return sb.String()
+}
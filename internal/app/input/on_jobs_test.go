@@ -0,0 +1,54 @@
+package input
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yanmxa/gencode/internal/task"
+)
+
+func TestJobsViewerExpandsLiveOutput(t *testing.T) {
+	tasks := task.NewManager()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sleep", "10")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	bg := tasks.CreateBashTask(cmd, "npm run dev", "Dev server", ctx, cancel)
+	defer tasks.Clear(task.TaskTypeBash)
+
+	if n := RunningJobs(tasks); n != 1 {
+		t.Fatalf("RunningJobs() = %d, want 1", n)
+	}
+
+	var j JobsViewer
+	j.Enter(tasks, 100, 40)
+	if view := j.Render(); !strings.Contains(view, "Dev server") || strings.Contains(view, "no output yet") {
+		t.Fatalf("collapsed view:\n%s", view)
+	}
+
+	j.HandleKeypress(tea.KeyMsg{Type: tea.KeyEnter})
+	if view := j.Render(); !strings.Contains(view, "no output yet") {
+		t.Fatalf("expanded view without output:\n%s", view)
+	}
+
+	bg.Write([]byte("ready on :3000\n"))
+	if j.HandleTick(JobsTickMsg{Seq: j.seq}) == nil {
+		t.Fatal("an open viewer should keep refreshing")
+	}
+	if view := j.Render(); !strings.Contains(view, "ready on :3000") {
+		t.Errorf("refreshed view should show new output:\n%s", view)
+	}
+
+	stale := JobsTickMsg{Seq: j.seq}
+	j.Cancel()
+	j.Enter(tasks, 100, 40)
+	if j.HandleTick(stale) != nil {
+		t.Error("a tick from an earlier viewing should stop")
+	}
+}
@@ -11,7 +11,10 @@ import (
 
 	"github.com/yanmxa/gencode/internal/app/kit"
 	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/log"
 	coremcp "github.com/yanmxa/gencode/internal/mcp"
+	"github.com/yanmxa/gencode/internal/tool"
+	"go.uber.org/zap"
 )
 
 // ── State ───────────────────────────────────────────────────────────
@@ -37,6 +40,7 @@ type mcpSelectorLevel int
 const (
 	mcpLevelList   mcpSelectorLevel = iota // Server list view
 	mcpLevelDetail                         // Server detail + actions view
+	mcpLevelAdd                            // Add-server wizard
 )
 
 // mcpAction represents an action available for a server in detail view.
@@ -48,7 +52,7 @@ type mcpAction struct {
 // mcpServerItem represents an MCP server in the selector
 type mcpServerItem struct {
 	Name      string
-	Type      string // stdio, http, sse
+	Type      string // stdio, http, sse, ws
 	Status    coremcp.ServerStatus
 	ToolCount int
 	Error     string
@@ -79,6 +83,12 @@ type MCPSelector struct {
 	detailServer *mcpServerItem // server shown in detail view
 	actions      []mcpAction    // context-sensitive action menu
 	actionIdx    int            // selected action
+
+	add mcpAddWizard // add-server wizard state
+
+	// connectLater names a server added with OAuth by /mcp add. It is
+	// connected in the background, since signing in waits for the browser.
+	connectLater string
 }
 
 // ── Message types ───────────────────────────────────────────────────
@@ -111,9 +121,6 @@ type MCPRemoveMsg struct {
 	ServerName string
 }
 
-// MCPAddServerMsg is sent when the user presses "n" to add a new server
-type MCPAddServerMsg struct{}
-
 // MCPEditServerMsg is sent when the user chooses to edit a server's config
 type MCPEditServerMsg struct {
 	ServerName string
@@ -157,6 +164,17 @@ func (s *MCPSelector) EnterSelect(width, height int) error {
 	return nil
 }
 
+// TakeConnectLater returns a command that connects the server left by
+// /mcp add --oauth, if any.
+func (s *MCPSelector) TakeConnectLater() tea.Cmd {
+	name := s.connectLater
+	if name == "" {
+		return nil
+	}
+	s.connectLater = ""
+	return func() tea.Msg { return MCPConnectMsg{ServerName: name} }
+}
+
 // AutoReconnect returns a batch command to reconnect servers in error state.
 // Disconnected servers are left as-is since the user intentionally disconnected them.
 func (s *MCPSelector) AutoReconnect() tea.Cmd {
@@ -423,14 +441,18 @@ func (s *MCPSelector) clampActionIdx() {
 }
 
 // AutoConnect returns a batch of commands to connect all configured MCP servers,
-// skipping servers that the user has explicitly disabled.
-func (s *MCPSelector) AutoConnect() tea.Cmd {
+// skipping servers that the user has explicitly disabled. When localOnly is
+// set, remote (http/sse) servers are left for the user to connect via /mcp.
+func (s *MCPSelector) AutoConnect(localOnly bool) tea.Cmd {
 	if s.registry == nil {
 		return nil
 	}
 	var cmds []tea.Cmd
 	for _, srv := range s.registry.List() {
 		name := srv.Config.Name
+		if localOnly && srv.Config.GetType() != coremcp.TransportSTDIO {
+			continue
+		}
 		if !s.registry.IsDisabled(name) {
 			s.registry.SetConnecting(name, true)
 			cmds = append(cmds, mcpStartConnect(s.registry, name))
@@ -442,6 +464,38 @@ func (s *MCPSelector) AutoConnect() tea.Cmd {
 	return tea.Batch(cmds...)
 }
 
+// ConnectOnly connects the named servers and disconnects every other connected
+// server. Unlike the /mcp actions it does not change which servers are
+// disabled on disk, so a workspace switch only affects the current session.
+// localOnly skips remote servers the same way AutoConnect does.
+func (s *MCPSelector) ConnectOnly(names []string, localOnly bool) tea.Cmd {
+	if s.registry == nil {
+		return nil
+	}
+	want := make(map[string]bool, len(names))
+	for _, name := range names {
+		want[name] = true
+	}
+	var cmds []tea.Cmd
+	for _, srv := range s.registry.List() {
+		name := srv.Config.Name
+		switch {
+		case want[name] && srv.Status != coremcp.StatusConnected:
+			if localOnly && srv.Config.GetType() != coremcp.TransportSTDIO {
+				continue
+			}
+			s.registry.SetConnecting(name, true)
+			cmds = append(cmds, mcpStartConnect(s.registry, name))
+		case !want[name] && srv.Status == coremcp.StatusConnected:
+			_ = s.registry.Disconnect(name)
+		}
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	return tea.Batch(cmds...)
+}
+
 // mcpStartConnect returns a tea.Cmd that connects to an MCP server.
 func mcpStartConnect(reg *coremcp.Registry, name string) tea.Cmd {
 	return func() tea.Msg {
@@ -502,6 +556,17 @@ func UpdateMCP(deps OverlayDeps, state *MCPState, msg tea.Msg) (tea.Cmd, bool) {
 			deps.Conv.Append(core.ChatMessage{Role: core.RoleNotice, Content: content})
 			return tea.Batch(deps.CommitMessages()...), true
 		}
+		if msg.Success {
+			var notes []string
+			if !state.Selector.IsActive() {
+				notes = append(notes, fmt.Sprintf("Connected to %s\nTools available: %d", msg.ServerName, msg.ToolCount))
+			}
+			notes = append(notes, serverToolConflicts(state.Selector.registry, msg.ServerName)...)
+			if len(notes) > 0 {
+				deps.Conv.Append(core.ChatMessage{Role: core.RoleNotice, Content: strings.Join(notes, "\n")})
+				return tea.Batch(deps.CommitMessages()...), true
+			}
+		}
 		return nil, true
 
 	case MCPDisconnectMsg:
@@ -519,10 +584,6 @@ func UpdateMCP(deps OverlayDeps, state *MCPState, msg tea.Msg) (tea.Cmd, bool) {
 		state.Selector.HandleRemove(msg.ServerName)
 		return nil, true
 
-	case MCPAddServerMsg:
-		deps.State.Textarea.SetValue("/mcp add ")
-		return nil, true
-
 	case MCPEditServerMsg:
 		info, err := coremcp.PrepareServerEdit(state.Selector.registry, msg.ServerName)
 		if err != nil {
@@ -580,6 +641,10 @@ func (s *MCPSelector) HandleKeypress(key tea.KeyMsg) tea.Cmd {
 		return nil
 	}
 
+	if s.level == mcpLevelAdd {
+		return s.handleAddKeypress(key)
+	}
+
 	// Detail view keypress handling
 	if s.level == mcpLevelDetail {
 		return s.handleDetailKeypress(key)
@@ -627,8 +692,8 @@ func (s *MCPSelector) handleListKeypress(key tea.KeyMsg) tea.Cmd {
 		s.MoveDown()
 		return nil
 	case tea.KeyCtrlN:
-		s.Cancel()
-		return func() tea.Msg { return MCPAddServerMsg{} }
+		s.startAddWizard()
+		return nil
 	case tea.KeyCtrlD:
 		if len(s.filteredServers) > 0 && s.nav.Selected < len(s.filteredServers) {
 			name := s.filteredServers[s.nav.Selected].Name
@@ -674,3 +739,28 @@ func (s *MCPSelector) handleListKeypress(key tea.KeyMsg) tea.Cmd {
 	}
 	return nil
 }
+
+// serverToolConflicts logs and describes tool name collisions that involve
+// server, either with another connected server or with a built-in tool.
+func serverToolConflicts(registry *coremcp.Registry, server string) []string {
+	if registry == nil {
+		return nil
+	}
+	var builtins []string
+	for _, schema := range tool.GetToolSchemas() {
+		builtins = append(builtins, schema.Name)
+	}
+	var lines []string
+	for _, c := range registry.ToolConflicts(builtins) {
+		if !c.Involves(server) {
+			continue
+		}
+		log.Logger().Warn("MCP tool name conflict",
+			zap.String("tool", c.Tool),
+			zap.Strings("servers", c.Servers),
+			zap.Bool("builtin", c.Builtin),
+			zap.Bool("routing", c.Routing))
+		lines = append(lines, "⚠ "+c.String())
+	}
+	return lines
+}
@@ -0,0 +1,548 @@
+// MCP add-server wizard: step-by-step prompts that build /mcp add arguments.
+package input
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/yanmxa/gencode/internal/app/kit"
+	coremcp "github.com/yanmxa/gencode/internal/mcp"
+)
+
+// mcpAddStep is one prompt in the add-server wizard.
+type mcpAddStep int
+
+const (
+	mcpAddStepName mcpAddStep = iota
+	mcpAddStepTransport
+	mcpAddStepTarget // command for stdio, URL for http/sse/ws
+	mcpAddStepScope
+	mcpAddStepEnv
+	mcpAddStepHeaders // http/sse/ws only
+	mcpAddStepConfirm
+)
+
+var (
+	mcpAddTransports = []string{string(coremcp.TransportSTDIO), string(coremcp.TransportHTTP), string(coremcp.TransportSSE), string(coremcp.TransportWebSocket)}
+	mcpAddScopes     = []string{string(coremcp.ScopeLocal), string(coremcp.ScopeProject), string(coremcp.ScopeUser)}
+)
+
+// mcpAddWizard collects the fields of /mcp add one step at a time. The
+// answers are turned back into /mcp add arguments so the wizard and the
+// command share parseMCPAddArgs.
+type mcpAddWizard struct {
+	step   mcpAddStep
+	input  string // text being typed on text steps
+	choice int    // highlighted option on choice steps
+	err    string
+
+	name      string
+	transport string
+	target    string
+	scope     string
+	env       []string
+	headers   []string
+}
+
+func (w *mcpAddWizard) reset() {
+	*w = mcpAddWizard{}
+}
+
+func (w *mcpAddWizard) isStdio() bool {
+	return w.transport == "" || w.transport == string(coremcp.TransportSTDIO)
+}
+
+// options returns the choices for the current step, or nil on text steps.
+func (w *mcpAddWizard) options() []string {
+	switch w.step {
+	case mcpAddStepTransport:
+		return mcpAddTransports
+	case mcpAddStepScope:
+		return mcpAddScopes
+	}
+	return nil
+}
+
+// next returns the step after s, skipping headers for stdio servers.
+func (w *mcpAddWizard) next(s mcpAddStep) mcpAddStep {
+	s++
+	if s == mcpAddStepHeaders && w.isStdio() {
+		s++
+	}
+	return s
+}
+
+// prev returns the step before s, skipping headers for stdio servers.
+func (w *mcpAddWizard) prev(s mcpAddStep) mcpAddStep {
+	s--
+	if s == mcpAddStepHeaders && w.isStdio() {
+		s--
+	}
+	return s
+}
+
+// advance moves to step s and preloads its input or choice from earlier answers.
+func (w *mcpAddWizard) advance(s mcpAddStep) {
+	w.step = s
+	w.input = ""
+	w.choice = 0
+	w.err = ""
+	switch s {
+	case mcpAddStepName:
+		w.input = w.name
+	case mcpAddStepTarget:
+		w.input = w.target
+	case mcpAddStepTransport:
+		w.choice = max(0, indexOf(mcpAddTransports, w.transport))
+	case mcpAddStepScope:
+		w.choice = max(0, indexOf(mcpAddScopes, w.scope))
+	}
+}
+
+// back returns to the previous step. It reports false when already on the
+// first step, meaning the wizard should close.
+func (w *mcpAddWizard) back() bool {
+	if w.step == mcpAddStepName {
+		return false
+	}
+	w.advance(w.prev(w.step))
+	return true
+}
+
+// submit validates the current step and moves on. It reports true once the
+// confirm step is accepted. exists reports whether a server name is taken.
+func (w *mcpAddWizard) submit(exists func(string) bool) bool {
+	value := strings.TrimSpace(w.input)
+	w.err = ""
+
+	switch w.step {
+	case mcpAddStepName:
+		if w.err = validateMCPServerName(value, exists); w.err != "" {
+			return false
+		}
+		w.name = value
+	case mcpAddStepTransport:
+		if w.transport != mcpAddTransports[w.choice] {
+			w.target = ""
+			w.headers = nil
+		}
+		w.transport = mcpAddTransports[w.choice]
+	case mcpAddStepTarget:
+		if w.err = w.validateTarget(value); w.err != "" {
+			return false
+		}
+		w.target = value
+	case mcpAddStepScope:
+		w.scope = mcpAddScopes[w.choice]
+	case mcpAddStepEnv, mcpAddStepHeaders:
+		// Each Enter adds one entry; an empty line moves on.
+		if value != "" {
+			if w.err = w.addEntry(value); w.err == "" {
+				w.input = ""
+			}
+			return false
+		}
+	case mcpAddStepConfirm:
+		return true
+	}
+
+	w.advance(w.next(w.step))
+	return false
+}
+
+func (w *mcpAddWizard) validateTarget(value string) string {
+	if value == "" {
+		if w.isStdio() {
+			return "Command is required"
+		}
+		return "URL is required"
+	}
+	if w.isStdio() {
+		fields, err := splitMCPCommand(value)
+		if err != nil {
+			return "Invalid command: " + err.Error()
+		}
+		if len(fields) == 0 {
+			return "Command is required"
+		}
+		return ""
+	}
+	u, err := url.Parse(value)
+	if w.transport == string(coremcp.TransportWebSocket) {
+		if err != nil || (u.Scheme != "ws" && u.Scheme != "wss") || u.Host == "" {
+			return "URL must start with ws:// or wss://"
+		}
+		return ""
+	}
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "URL must start with http:// or https://"
+	}
+	return ""
+}
+
+func (w *mcpAddWizard) addEntry(value string) string {
+	if w.step == mcpAddStepEnv {
+		if key, _, ok := strings.Cut(value, "="); !ok || strings.TrimSpace(key) == "" {
+			return "Use KEY=value"
+		}
+		w.env = append(w.env, value)
+		return ""
+	}
+	if key, _, ok := strings.Cut(value, ":"); !ok || strings.TrimSpace(key) == "" {
+		return "Use Name: value"
+	}
+	w.headers = append(w.headers, value)
+	return ""
+}
+
+// args returns the equivalent /mcp add arguments.
+func (w *mcpAddWizard) args() []string {
+	args := []string{"--transport", w.transport, "--scope", w.scope}
+	for _, e := range w.env {
+		args = append(args, "--env", e)
+	}
+	for _, h := range w.headers {
+		args = append(args, "--header", h)
+	}
+	args = append(args, w.name)
+	if w.isStdio() {
+		// validateTarget has already rejected commands that do not parse.
+		fields, _ := splitMCPCommand(w.target)
+		return append(append(args, "--"), fields...)
+	}
+	return append(args, w.target)
+}
+
+// quoteMCPArgs joins args into a command line that splitMCPCommand splits
+// back into the same words.
+func quoteMCPArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if a != "" && !strings.HasPrefix(a, "~") && !strings.ContainsAny(a, " \t\n'\"\\") {
+			quoted[i] = a
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
+
+// splitMCPCommand splits a stdio server command into words with shell
+// quoting rules, so quoted arguments with spaces stay whole. Only an unquoted
+// leading ~ is expanded; $VAR and ${VAR} are saved as typed and expanded by
+// the transport when it connects, so secrets never end up in the config file.
+func splitMCPCommand(command string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune // ' or " while inside quotes
+		escaped bool
+		tilde   bool // the word starts with an unquoted ~
+	)
+	flush := func() {
+		w := word.String()
+		if home, err := os.UserHomeDir(); err == nil && tilde && (w == "~" || strings.HasPrefix(w, "~/")) {
+			w = home + w[1:]
+		}
+		words = append(words, w)
+		word.Reset()
+		inWord, tilde = false, false
+	}
+	for _, r := range command {
+		switch {
+		case escaped:
+			// Inside double quotes only these characters are escapable.
+			if quote == '"' && !strings.ContainsRune("\\\"$`", r) {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inWord = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				flush()
+			}
+		default:
+			if !inWord && r == '~' {
+				tilde = true
+			}
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, errors.New("unterminated quote or trailing backslash")
+	}
+	if inWord {
+		flush()
+	}
+	return words, nil
+}
+
+// validateMCPServerName checks a new server name. Names containing "__"
+// would make mcp__<server>__<tool> routing ambiguous.
+func validateMCPServerName(name string, exists func(string) bool) string {
+	switch {
+	case name == "":
+		return "Name is required"
+	case strings.ContainsAny(name, " \t/\\"):
+		return "Name cannot contain spaces or slashes"
+	case strings.Contains(name, "__"):
+		return `Name cannot contain "__"`
+	case strings.HasPrefix(name, "-"):
+		return `Name cannot start with "-"`
+	case exists != nil && exists(name):
+		return fmt.Sprintf("A server named '%s' already exists", name)
+	}
+	return ""
+}
+
+func indexOf(items []string, v string) int {
+	for i, item := range items {
+		if item == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// ── Selector integration ────────────────────────────────────────────
+
+// EnterAddWizard opens the selector directly on the add-server wizard.
+func (s *MCPSelector) EnterAddWizard(width, height int) error {
+	if err := s.EnterSelect(width, height); err != nil {
+		return err
+	}
+	s.startAddWizard()
+	return nil
+}
+
+func (s *MCPSelector) startAddWizard() {
+	s.add.reset()
+	s.lastError = ""
+	s.level = mcpLevelAdd
+}
+
+func (s *MCPSelector) serverExists(name string) bool {
+	if s.registry == nil {
+		return false
+	}
+	_, ok := s.registry.GetConfig(name)
+	return ok
+}
+
+// handleAddKeypress handles keypresses in the add-server wizard.
+func (s *MCPSelector) handleAddKeypress(key tea.KeyMsg) tea.Cmd {
+	w := &s.add
+	opts := w.options()
+
+	switch key.Type {
+	case tea.KeyEsc:
+		if !w.back() {
+			s.level = mcpLevelList
+		}
+		return nil
+	case tea.KeyEnter:
+		if w.submit(s.serverExists) {
+			return s.finishAddWizard()
+		}
+		return nil
+	case tea.KeyUp, tea.KeyCtrlP:
+		if len(opts) > 0 && w.choice > 0 {
+			w.choice--
+		}
+		return nil
+	case tea.KeyDown, tea.KeyCtrlN:
+		if len(opts) > 0 && w.choice < len(opts)-1 {
+			w.choice++
+		}
+		return nil
+	}
+
+	if len(opts) > 0 || w.step == mcpAddStepConfirm {
+		return nil
+	}
+	switch key.Type {
+	case tea.KeyBackspace:
+		if w.input != "" {
+			r := []rune(w.input)
+			w.input = string(r[:len(r)-1])
+		}
+	case tea.KeySpace:
+		w.input += " "
+	case tea.KeyRunes:
+		w.input += string(key.Runes)
+	}
+	return nil
+}
+
+// finishAddWizard saves the server and starts connecting to it.
+func (s *MCPSelector) finishAddWizard() tea.Cmd {
+	req, msg := parseMCPAddArgs(s.add.args())
+	if msg != "" {
+		s.add.err = msg
+		return nil
+	}
+	if err := s.registry.AddServer(req.Name, req.Config, coremcp.ParseScope(req.Scope)); err != nil {
+		s.add.err = fmt.Sprintf("Failed to add server: %v", err)
+		return nil
+	}
+
+	s.level = mcpLevelList
+	s.nav.Search = ""
+	s.refreshServers()
+	for i, srv := range s.filteredServers {
+		if srv.Name == req.Name {
+			s.nav.Selected = i
+			s.nav.EnsureVisible()
+		}
+	}
+	s.connecting = true
+	name := req.Name
+	return func() tea.Msg { return MCPConnectMsg{ServerName: name} }
+}
+
+// renderAddWizard renders the add-server wizard.
+func (s *MCPSelector) renderAddWizard() string {
+	w := &s.add
+	var sb strings.Builder
+	labelStyle := lipgloss.NewStyle().Foreground(kit.CurrentTheme.Muted)
+	valueStyle := lipgloss.NewStyle().Foreground(kit.CurrentTheme.TextBright)
+
+	sb.WriteString(kit.SelectorTitleStyle().Render("Add MCP Server"))
+	sb.WriteString("\n\n")
+
+	// Answers so far
+	answered := []struct {
+		label, value string
+		step         mcpAddStep
+	}{
+		{"Name:     ", w.name, mcpAddStepName},
+		{"Transport:", w.transport, mcpAddStepTransport},
+		{w.targetLabel(), w.target, mcpAddStepTarget},
+		{"Scope:    ", w.scope, mcpAddStepScope},
+		{"Env:      ", strings.Join(w.env, ", "), mcpAddStepEnv},
+		{"Headers:  ", strings.Join(w.headers, ", "), mcpAddStepHeaders},
+	}
+	maxValueLen := kit.CalculateToolBoxWidth(s.width) - 20
+	for _, a := range answered {
+		if a.step >= w.step || a.value == "" {
+			continue
+		}
+		fmt.Fprintf(&sb, "  %s  %s\n", labelStyle.Render(a.label), valueStyle.Render(kit.TruncateText(a.value, maxValueLen)))
+	}
+	if w.step > mcpAddStepName {
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(kit.SelectorBreadcrumbStyle().Render(w.prompt()))
+	sb.WriteString("\n")
+
+	if opts := w.options(); len(opts) > 0 {
+		for i, opt := range opts {
+			if i == w.choice {
+				sb.WriteString(kit.SelectorSelectedStyle().Render("> " + opt))
+			} else {
+				sb.WriteString(kit.SelectorItemStyle().Render("  " + opt))
+			}
+			sb.WriteString("\n")
+		}
+	} else if w.step == mcpAddStepConfirm {
+		sb.WriteString(kit.SelectorHintStyle().Render("  /mcp add " + quoteMCPArgs(w.args())))
+		sb.WriteString("\n")
+	} else {
+		for _, entry := range w.entries() {
+			sb.WriteString(kit.SelectorItemStyle().Render("  + " + entry))
+			sb.WriteString("\n")
+		}
+		sb.WriteString(valueStyle.Render("> " + w.input + "│"))
+		sb.WriteString("\n")
+	}
+
+	if w.err != "" {
+		sb.WriteString(kit.SelectorStatusError().Render("    ! " + w.err))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(kit.SelectorHintStyle().Render(w.hint()))
+	return s.renderBox(sb.String())
+}
+
+func (w *mcpAddWizard) targetLabel() string {
+	if w.isStdio() {
+		return "Command:  "
+	}
+	return "URL:      "
+}
+
+func (w *mcpAddWizard) entries() []string {
+	switch w.step {
+	case mcpAddStepEnv:
+		return w.env
+	case mcpAddStepHeaders:
+		return w.headers
+	}
+	return nil
+}
+
+func (w *mcpAddWizard) prompt() string {
+	switch w.step {
+	case mcpAddStepName:
+		return "Server name"
+	case mcpAddStepTransport:
+		return "Transport"
+	case mcpAddStepTarget:
+		if w.isStdio() {
+			return "Command and arguments (e.g. npx -y @modelcontextprotocol/server-filesystem .)"
+		}
+		if w.transport == string(coremcp.TransportWebSocket) {
+			return "Server URL (e.g. wss://api.example.com/mcp)"
+		}
+		return "Server URL (e.g. https://api.example.com/mcp)"
+	case mcpAddStepScope:
+		return "Scope (local: this project, private · project: shared .gen/mcp.json · user: all projects)"
+	case mcpAddStepEnv:
+		return "Environment variables (KEY=value, one per line)"
+	case mcpAddStepHeaders:
+		return "HTTP headers (Name: value, one per line)"
+	default:
+		return "Add and connect?"
+	}
+}
+
+func (w *mcpAddWizard) hint() string {
+	switch w.step {
+	case mcpAddStepTransport, mcpAddStepScope:
+		return "↑↓ select . Enter next . Esc back"
+	case mcpAddStepEnv, mcpAddStepHeaders:
+		return "Enter add . Enter on empty line to continue . Esc back"
+	case mcpAddStepConfirm:
+		return "Enter add and connect . Esc back"
+	case mcpAddStepName:
+		return "Enter next . Esc cancel"
+	default:
+		return "Enter next . Esc back"
+	}
+}
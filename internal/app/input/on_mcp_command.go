@@ -32,7 +32,14 @@ func HandleMCPCommand(ctx context.Context, selector *MCPSelector, width, height
 
 	switch subCmd {
 	case "add":
-		r, err := handleMCPAdd(selector.registry, ctx, parts[1:])
+		if len(parts) == 1 {
+			return "", nil, selector.EnterAddWizard(width, height)
+		}
+		addArgs, err := splitMCPCommand(strings.TrimSpace(args[len(parts[0]):]))
+		if err != nil {
+			return fmt.Sprintf("Invalid /mcp add arguments: %v", err), nil, nil
+		}
+		r, err := handleMCPAdd(selector, ctx, addArgs)
 		return r, nil, err
 	case "edit":
 		return handleMCPEdit(selector.registry, serverName)
@@ -54,6 +61,16 @@ func HandleMCPCommand(ctx context.Context, selector *MCPSelector, width, height
 	case "list", "status":
 		r, err := handleMCPList(selector.registry)
 		return r, nil, err
+	case "resources":
+		r, err := handleMCPResources(selector.registry, ctx, serverName)
+		return r, nil, err
+	case "read":
+		var uri string
+		if len(parts) > 2 {
+			uri = strings.Join(parts[2:], " ")
+		}
+		r, err := handleMCPRead(selector.registry, ctx, serverName, uri)
+		return r, nil, err
 	default:
 		r, err := handleMCPConnect(selector.registry, ctx, subCmd)
 		return r, nil, err
@@ -96,6 +113,7 @@ func handleMCPList(reg *coremcp.Registry) (string, error) {
 	}
 
 	sb.WriteString("\nCommands:\n")
+	sb.WriteString("  /mcp add                Add a server step by step\n")
 	sb.WriteString("  /mcp add <name> ...     Add a server\n")
 	sb.WriteString("  /mcp edit <name>        Edit server config in $EDITOR\n")
 	sb.WriteString("  /mcp remove <name>      Remove a server\n")
@@ -103,6 +121,8 @@ func handleMCPList(reg *coremcp.Registry) (string, error) {
 	sb.WriteString("  /mcp connect <name>     Connect to server\n")
 	sb.WriteString("  /mcp disconnect <name>  Disconnect from server\n")
 	sb.WriteString("  /mcp reconnect <name>   Reconnect to server\n")
+	sb.WriteString("  /mcp resources <name>   List a server's resources\n")
+	sb.WriteString("  /mcp read <name> <uri>  Show a resource's contents\n")
 
 	return sb.String(), nil
 }
@@ -151,9 +171,46 @@ func handleMCPDisconnect(reg *coremcp.Registry, name string) (string, error) {
 	return fmt.Sprintf("Disconnected from %s", name), nil
 }
 
-func handleMCPAdd(reg *coremcp.Registry, ctx context.Context, args []string) (string, error) {
+func handleMCPAdd(selector *MCPSelector, ctx context.Context, args []string) (string, error) {
+	reg := selector.registry
+	req, msg := parseMCPAddArgs(args)
+	if msg != "" {
+		return msg, nil
+	}
+
+	if err := reg.AddServer(req.Name, req.Config, coremcp.ParseScope(req.Scope)); err != nil {
+		return fmt.Sprintf("Failed to add server: %v", err), nil
+	}
+
+	if req.Config.OAuth != nil {
+		selector.connectLater = req.Name
+		return fmt.Sprintf("Added '%s' (%s, %s scope)\nConnecting — sign in to the server in your browser when it opens.", req.Name, req.Config.Type, req.Scope), nil
+	}
+
+	if err := reg.Connect(ctx, req.Name); err != nil {
+		return fmt.Sprintf("Added '%s' to %s scope, but failed to connect: %v", req.Name, req.Scope, err), nil
+	}
+
+	toolCount := 0
+	if client, ok := reg.GetClient(req.Name); ok {
+		toolCount = len(client.GetCachedTools())
+	}
+
+	return fmt.Sprintf("Added and connected to '%s' (%s, %s scope)\nTools available: %d", req.Name, req.Config.Type, req.Scope, toolCount), nil
+}
+
+// mcpAddRequest is a parsed /mcp add invocation.
+type mcpAddRequest struct {
+	Name   string
+	Scope  string
+	Config coremcp.ServerConfig
+}
+
+// parseMCPAddArgs parses /mcp add arguments. On invalid input it returns a
+// non-empty message (usage or error) to show the user instead.
+func parseMCPAddArgs(args []string) (mcpAddRequest, string) {
 	if len(args) == 0 {
-		return mcpAddUsage(), nil
+		return mcpAddRequest{}, mcpAddUsage()
 	}
 
 	var (
@@ -161,6 +218,8 @@ func handleMCPAdd(reg *coremcp.Registry, ctx context.Context, args []string) (st
 		scope      = "local"
 		envVars    []string
 		headers    []string
+		oauth      bool
+		oauthCfg   coremcp.OAuthConfig
 		name       string
 		positional []string
 		dashIdx    = -1
@@ -192,13 +251,35 @@ func handleMCPAdd(reg *coremcp.Registry, ctx context.Context, args []string) (st
 				i++
 				headers = append(headers, args[i])
 			}
+		case "--oauth":
+			oauth = true
+		case "--client-id":
+			if i+1 < len(args) {
+				i++
+				oauthCfg.ClientID = args[i]
+			}
+		case "--auth-url":
+			if i+1 < len(args) {
+				i++
+				oauthCfg.AuthURL = args[i]
+			}
+		case "--token-url":
+			if i+1 < len(args) {
+				i++
+				oauthCfg.TokenURL = args[i]
+			}
+		case "--oauth-scope":
+			if i+1 < len(args) {
+				i++
+				oauthCfg.Scopes = append(oauthCfg.Scopes, args[i])
+			}
 		default:
 			positional = append(positional, args[i])
 		}
 	}
 
 	if len(positional) == 0 {
-		return mcpAddUsage(), nil
+		return mcpAddRequest{}, mcpAddUsage()
 	}
 	name = positional[0]
 
@@ -208,7 +289,7 @@ func handleMCPAdd(reg *coremcp.Registry, ctx context.Context, args []string) (st
 	switch config.Type {
 	case coremcp.TransportSTDIO:
 		if dashIdx == -1 || dashIdx >= len(args)-1 {
-			return "STDIO transport requires: /mcp add <name> -- <command> [args...]", nil
+			return mcpAddRequest{}, "STDIO transport requires: /mcp add <name> -- <command> [args...]"
 		}
 		cmdArgs := args[dashIdx+1:]
 		config.Command = cmdArgs[0]
@@ -216,34 +297,31 @@ func handleMCPAdd(reg *coremcp.Registry, ctx context.Context, args []string) (st
 			config.Args = cmdArgs[1:]
 		}
 
-	case coremcp.TransportHTTP, coremcp.TransportSSE:
+	case coremcp.TransportHTTP, coremcp.TransportSSE, coremcp.TransportWebSocket:
 		if len(positional) < 2 {
-			return fmt.Sprintf("%s transport requires a URL: /mcp add --transport %s <name> <url>", transport, transport), nil
+			return mcpAddRequest{}, fmt.Sprintf("%s transport requires a URL: /mcp add --transport %s <name> <url>", transport, transport)
 		}
 		config.URL = positional[1]
 		config.Headers = coremcp.ParseKeyValues(headers, ":")
 
 	default:
-		return fmt.Sprintf("Unsupported transport type: %s (use stdio, http, or sse)", transport), nil
+		return mcpAddRequest{}, fmt.Sprintf("Unsupported transport type: %s (use stdio, http, sse, or ws)", transport)
 	}
 
 	config.Env = coremcp.ParseKeyValues(envVars, "=")
 
-	mcpScope := coremcp.ParseScope(scope)
-	if err := reg.AddServer(name, config, mcpScope); err != nil {
-		return fmt.Sprintf("Failed to add server: %v", err), nil
+	switch {
+	case oauth && config.Type != coremcp.TransportHTTP && config.Type != coremcp.TransportSSE:
+		return mcpAddRequest{}, "--oauth requires the http or sse transport"
+	case oauth && oauthCfg.ClientID == "":
+		return mcpAddRequest{}, "--oauth requires --client-id <id>"
+	case oauth:
+		config.OAuth = &oauthCfg
+	case oauthCfg.ClientID != "" || oauthCfg.AuthURL != "" || oauthCfg.TokenURL != "" || len(oauthCfg.Scopes) > 0:
+		return mcpAddRequest{}, "--client-id, --auth-url, --token-url, and --oauth-scope require --oauth"
 	}
 
-	if err := reg.Connect(ctx, name); err != nil {
-		return fmt.Sprintf("Added '%s' to %s scope, but failed to connect: %v", name, scope, err), nil
-	}
-
-	toolCount := 0
-	if client, ok := reg.GetClient(name); ok {
-		toolCount = len(client.GetCachedTools())
-	}
-
-	return fmt.Sprintf("Added and connected to '%s' (%s, %s scope)\nTools available: %d", name, transport, scope, toolCount), nil
+	return mcpAddRequest{Name: name, Scope: scope, Config: config}, ""
 }
 
 func handleMCPRemove(reg *coremcp.Registry, name string) (string, error) {
@@ -289,9 +367,12 @@ func handleMCPGet(reg *coremcp.Registry, name string) (string, error) {
 			cmd += " " + strings.Join(config.Args, " ")
 		}
 		fmt.Fprintf(&sb, "Command: %s\n", cmd)
-	case coremcp.TransportHTTP, coremcp.TransportSSE:
+	case coremcp.TransportHTTP, coremcp.TransportSSE, coremcp.TransportWebSocket:
 		fmt.Fprintf(&sb, "URL:    %s\n", config.URL)
 	}
+	if config.OAuth != nil {
+		fmt.Fprintf(&sb, "Auth:   OAuth (client %s)\n", config.OAuth.ClientID)
+	}
 
 	if len(config.Env) > 0 {
 		sb.WriteString("Env:\n")
@@ -357,14 +438,89 @@ func handleMCPReconnect(reg *coremcp.Registry, ctx context.Context, name string)
 	return fmt.Sprintf("Reconnected to %s\nTools available: %d", name, toolCount), nil
 }
 
+// resourceClient returns the connected client for name, or a message to show
+// the user when the server is unknown, disconnected, or has no resources.
+func resourceClient(reg *coremcp.Registry, name string) (*coremcp.Client, string) {
+	if _, ok := reg.GetConfig(name); !ok {
+		return nil, fmt.Sprintf("Server not found: %s\n\nUse /mcp list to see available servers.", name)
+	}
+	client, ok := reg.GetClient(name)
+	if !ok || !client.IsConnected() {
+		return nil, fmt.Sprintf("Server %s is not connected.\n\nConnect it with /mcp connect %s", name, name)
+	}
+	if client.GetCapabilities().Resources == nil {
+		return nil, fmt.Sprintf("Server %s does not provide resources.", name)
+	}
+	return client, ""
+}
+
+func handleMCPResources(reg *coremcp.Registry, ctx context.Context, name string) (string, error) {
+	if name == "" {
+		return "Usage: /mcp resources <server-name>", nil
+	}
+	client, msg := resourceClient(reg, name)
+	if client == nil {
+		return msg, nil
+	}
+
+	// Refresh the list; fall back to what the server advertised on connect.
+	resources, err := client.ListResources(ctx)
+	if err != nil {
+		resources = client.GetCachedResources()
+	}
+	if len(resources) == 0 {
+		return fmt.Sprintf("Server %s has no resources.", name), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Resources from %s (%d):\n\n", name, len(resources))
+	for _, res := range resources {
+		sb.WriteString("  " + res.URI)
+		if res.MimeType != "" {
+			fmt.Fprintf(&sb, " [%s]", res.MimeType)
+		}
+		sb.WriteString("\n")
+		if res.Name != "" && res.Name != res.URI {
+			fmt.Fprintf(&sb, "    %s\n", res.Name)
+		}
+		if res.Description != "" {
+			fmt.Fprintf(&sb, "    %s\n", res.Description)
+		}
+	}
+	fmt.Fprintf(&sb, "\nRead one with /mcp read %s <uri>", name)
+	return sb.String(), nil
+}
+
+func handleMCPRead(reg *coremcp.Registry, ctx context.Context, name, uri string) (string, error) {
+	if name == "" || uri == "" {
+		return "Usage: /mcp read <server-name> <uri>", nil
+	}
+	client, msg := resourceClient(reg, name)
+	if client == nil {
+		return msg, nil
+	}
+
+	contents, err := client.ReadResource(ctx, uri)
+	if err != nil {
+		return fmt.Sprintf("Failed to read %s from %s: %v", uri, name, err), nil
+	}
+	return coremcp.FormatResourceContents(uri, contents), nil
+}
+
 func mcpAddUsage() string {
 	return `Usage: /mcp add [options] <name> [-- <command> [args...]] or <url>
+Run /mcp add with no arguments for a step-by-step wizard.
 
 Options:
-  --transport <type>   Transport: stdio (default), http, sse
+  --transport <type>   Transport: stdio (default), http, sse, ws
   --scope <scope>      Scope: local (default), project, user
   --env KEY=value      Environment variable (repeatable, STDIO only)
   --header Key:Value   HTTP header (repeatable, HTTP/SSE only)
+  --oauth              Sign in with OAuth on first connect (HTTP/SSE only)
+  --client-id <id>     OAuth client ID (required with --oauth)
+  --auth-url <url>     OAuth authorization endpoint (default: server metadata)
+  --token-url <url>    OAuth token endpoint (default: server metadata)
+  --oauth-scope <s>    OAuth scope to request (repeatable)
 
 Short flags: -t, -s, -e, -H
 
@@ -372,5 +528,6 @@ Examples:
   /mcp add myserver -- npx -y @modelcontextprotocol/server-filesystem .
   /mcp add --transport http pubmed https://pubmed.mcp.example.com/mcp
   /mcp add --transport http --scope project myapi https://api.example.com/mcp
+  /mcp add --transport http --oauth --client-id gen myapi https://api.example.com/mcp
   /mcp add --env API_KEY=xxx myserver -- npx -y some-mcp-server`
 }
@@ -0,0 +1,143 @@
+package input
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yanmxa/gencode/internal/app/conv"
+	"github.com/yanmxa/gencode/internal/command"
+	"github.com/yanmxa/gencode/internal/mcp"
+	"github.com/yanmxa/gencode/internal/tool"
+)
+
+// MCPPromptCommandInfos lists the prompts of connected MCP servers as
+// mcp:<server>:<prompt> slash commands.
+func MCPPromptCommandInfos() []command.Info {
+	reg := mcpRegistry(mcp.DefaultIfInit())
+	if reg == nil {
+		return nil
+	}
+	cmds := reg.PromptCommands()
+	infos := make([]command.Info, 0, len(cmds))
+	for _, pc := range cmds {
+		description := pc.Prompt.Description
+		if hint := pc.ArgumentHint(); hint != "" {
+			description += " " + hint
+		}
+		infos = append(infos, command.Info{Name: pc.Name(), Description: description})
+	}
+	return infos
+}
+
+func mcpRegistry(svc mcp.Service) *mcp.Registry {
+	if svc == nil {
+		return nil
+	}
+	return svc.Registry()
+}
+
+// MCPPromptArgsMsg reports the answers to the questions asked for the
+// required arguments of an MCP prompt command that were not typed.
+type MCPPromptArgsMsg struct {
+	Command   mcp.PromptCommand
+	Args      string            // the arguments as typed
+	Values    map[string]string // typed and answered argument values
+	Cancelled bool
+}
+
+// executeMCPPromptCommand fetches an MCP prompt and queues its messages to
+// be sent like a skill invocation. Missing required arguments are asked for
+// with the question prompt first. Argument and server errors are returned
+// as the command result instead.
+func (c CommandController) executeMCPPromptCommand(ctx context.Context, reg *mcp.Registry, pc mcp.PromptCommand, args string) (string, tea.Cmd) {
+	values, err := mcp.ParsePromptValues(pc.Prompt, args)
+	if err != nil {
+		usage := "/" + pc.Name()
+		if hint := pc.ArgumentHint(); hint != "" {
+			usage += " " + hint
+		}
+		return fmt.Sprintf("Error: %v\nUsage: %s", err, usage), nil
+	}
+	if missing := mcp.MissingPromptArguments(pc.Prompt, values); len(missing) > 0 {
+		return "", askMCPPromptArguments(pc, args, values, missing)
+	}
+	return c.runMCPPrompt(ctx, reg, pc, values, args)
+}
+
+// askMCPPromptArguments shows one free-text question per missing argument
+// and reports the answers as an MCPPromptArgsMsg.
+func askMCPPromptArguments(pc mcp.PromptCommand, args string, values map[string]string, missing []mcp.MCPPromptArgument) tea.Cmd {
+	req := &tool.QuestionRequest{ID: "mcp-prompt:" + pc.Name()}
+	for _, arg := range missing {
+		question := arg.Description
+		if question == "" {
+			question = fmt.Sprintf("Value for %s", arg.Name)
+		}
+		req.Questions = append(req.Questions, tool.Question{Header: arg.Name, Question: question})
+	}
+	reply := make(chan *tool.QuestionResponse, 1)
+
+	ask := func() tea.Msg { return conv.QuestionRequestMsg{Request: req, Reply: reply} }
+	wait := func() tea.Msg {
+		resp := <-reply
+		msg := MCPPromptArgsMsg{Command: pc, Args: args, Values: values}
+		if resp == nil || resp.Cancelled {
+			msg.Cancelled = true
+			return msg
+		}
+		for i, arg := range missing {
+			if answer := resp.Answers[i]; len(answer) > 0 {
+				values[arg.Name] = answer[0]
+			}
+		}
+		return msg
+	}
+	return tea.Batch(ask, wait)
+}
+
+// CompleteMCPPrompt runs an MCP prompt command once its missing arguments
+// have been answered.
+func (c CommandController) CompleteMCPPrompt(ctx context.Context, msg MCPPromptArgsMsg) tea.Cmd {
+	var result string
+	var cmd tea.Cmd
+	reg := mcpRegistry(c.deps.MCP)
+	switch {
+	case msg.Cancelled:
+		result = fmt.Sprintf("Cancelled /%s.", msg.Command.Name())
+	case reg == nil:
+		result = "MCP is not available."
+	default:
+		result, cmd = c.runMCPPrompt(ctx, reg, msg.Command, msg.Values, msg.Args)
+	}
+	if result != "" {
+		c.deps.Conversation.AddNotice(result)
+	}
+	cmds := c.deps.CommitMessages()
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+	return tea.Batch(cmds...)
+}
+
+// runMCPPrompt fetches the prompt with the given argument values and queues
+// its messages. args is the argument text shown with the command.
+func (c CommandController) runMCPPrompt(ctx context.Context, reg *mcp.Registry, pc mcp.PromptCommand, values map[string]string, args string) (string, tea.Cmd) {
+	result, err := reg.GetPrompt(ctx, pc, values)
+	if err != nil {
+		return fmt.Sprintf("Failed to get prompt %s from %s: %v", pc.Prompt.Name, pc.Server, err), nil
+	}
+	text := mcp.FormatPromptMessages(result)
+	if text == "" {
+		return fmt.Sprintf("Prompt %s from %s returned no messages.", pc.Prompt.Name, pc.Server), nil
+	}
+
+	c.deps.Input.Skill.PendingInstructions = fmt.Sprintf("<mcp-prompt name=%q>\n%s\n</mcp-prompt>", pc.Name(), text)
+	if args != "" {
+		c.deps.Input.Skill.PendingArgs = fmt.Sprintf("/%s %s", pc.Name(), args)
+	} else {
+		c.deps.Input.Skill.PendingArgs = fmt.Sprintf("/%s", pc.Name())
+	}
+	return "", c.deps.HandleSkillInvocation()
+}
@@ -0,0 +1,50 @@
+package input
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yanmxa/gencode/internal/app/conv"
+	"github.com/yanmxa/gencode/internal/mcp"
+	"github.com/yanmxa/gencode/internal/tool"
+)
+
+func TestAskMCPPromptArgumentsCollectsAnswers(t *testing.T) {
+	pc := mcp.PromptCommand{Server: "git", Prompt: mcp.MCPPrompt{Name: "review", Arguments: []mcp.MCPPromptArgument{
+		{Name: "file", Description: "File to review", Required: true},
+		{Name: "focus"},
+	}}}
+	values := map[string]string{"focus": "tests"}
+	missing := mcp.MissingPromptArguments(pc.Prompt, values)
+
+	batch, ok := askMCPPromptArguments(pc, "focus=tests", values, missing)().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("askMCPPromptArguments should batch the question and the wait, got %T", batch)
+	}
+	ask := batch[0]().(conv.QuestionRequestMsg)
+	if qs := ask.Request.Questions; len(qs) != 1 || qs[0].Header != "file" || qs[0].Question != "File to review" || len(qs[0].Options) != 0 {
+		t.Fatalf("questions = %+v, want one free-text question for file", qs)
+	}
+
+	ask.Reply <- &tool.QuestionResponse{Answers: map[int][]string{0: {"main.go"}}}
+	msg := batch[1]().(MCPPromptArgsMsg)
+	if msg.Cancelled || msg.Values["file"] != "main.go" || msg.Values["focus"] != "tests" || msg.Command.Name() != "mcp:git:review" {
+		t.Errorf("answered msg = %+v", msg)
+	}
+}
+
+func TestAskMCPPromptArgumentsCancelled(t *testing.T) {
+	pc := mcp.PromptCommand{Server: "git", Prompt: mcp.MCPPrompt{Name: "review", Arguments: []mcp.MCPPromptArgument{{Name: "file", Required: true}}}}
+	missing := mcp.MissingPromptArguments(pc.Prompt, nil)
+
+	batch := askMCPPromptArguments(pc, "", map[string]string{}, missing)().(tea.BatchMsg)
+	ask := batch[0]().(conv.QuestionRequestMsg)
+	if q := ask.Request.Questions[0].Question; q != "Value for file" {
+		t.Errorf("question without a description = %q", q)
+	}
+	ask.Reply <- &tool.QuestionResponse{Cancelled: true}
+	if msg := batch[1]().(MCPPromptArgsMsg); !msg.Cancelled {
+		t.Error("cancelling the question should cancel the prompt")
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 
+	tea "github.com/charmbracelet/bubbletea"
+
 	coremcp "github.com/yanmxa/gencode/internal/mcp"
 )
 
@@ -142,6 +145,65 @@ func TestHandleGet_MasksSecretsAndShowsDefaults(t *testing.T) {
 	}
 }
 
+func TestHandleResources_UnavailableServers(t *testing.T) {
+	reg := coremcp.NewRegistryForTest(map[string]coremcp.ServerConfig{
+		"docs": {Name: "docs", Type: coremcp.TransportHTTP, URL: "https://example.com/mcp"},
+	})
+	withTestRegistry(t, reg)
+	selector := NewMCPSelector(reg)
+	ctx := context.Background()
+
+	tests := map[string]string{
+		"resources":                 "Usage: /mcp resources",
+		"read docs":                 "Usage: /mcp read",
+		"resources missing":         "Server not found: missing",
+		"resources docs":            "Server docs is not connected.",
+		"read docs file:///a b.txt": "Server docs is not connected.",
+	}
+	for args, want := range tests {
+		result, _, err := HandleMCPCommand(ctx, &selector, 80, 24, args)
+		if err != nil {
+			t.Fatalf("%q: error = %v", args, err)
+		}
+		if !strings.HasPrefix(result, want) {
+			t.Errorf("%q = %q, want prefix %q", args, result, want)
+		}
+	}
+}
+
+func TestConnectOnly_LocalOnlySkipsRemoteServers(t *testing.T) {
+	configs := func() map[string]coremcp.ServerConfig {
+		return map[string]coremcp.ServerConfig{
+			"local":  {Name: "local", Command: "echo"},
+			"remote": {Name: "remote", Type: coremcp.TransportHTTP, URL: "https://example.com/mcp"},
+		}
+	}
+	tests := []struct {
+		localOnly bool
+		want      []string
+	}{
+		{false, []string{"local", "remote"}},
+		{true, []string{"local"}},
+	}
+	for _, tt := range tests {
+		reg := coremcp.NewRegistryForTest(configs())
+		selector := NewMCPSelector(reg)
+		if cmd := selector.ConnectOnly([]string{"local", "remote"}, tt.localOnly); cmd == nil {
+			t.Fatalf("localOnly=%v: expected a connect command", tt.localOnly)
+		}
+		var connecting []string
+		for _, srv := range reg.List() {
+			if srv.Status == coremcp.StatusConnecting {
+				connecting = append(connecting, srv.Config.Name)
+			}
+		}
+		sort.Strings(connecting)
+		if strings.Join(connecting, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("localOnly=%v: connecting %v, want %v", tt.localOnly, connecting, tt.want)
+		}
+	}
+}
+
 func Test_parseScopeAndKeyValues(t *testing.T) {
 	if coremcp.ParseScope("global") != coremcp.ScopeUser {
 		t.Fatal("expected global alias to map to user scope")
@@ -182,3 +244,173 @@ func TestApplyServerEdit_InvalidJSONReturnsErrorAndRemovesTempFile(t *testing.T)
 		t.Fatalf("expected temp file removal after failure, stat err = %v", statErr)
 	}
 }
+
+func typeMCPWizard(s *MCPSelector, text string) tea.Cmd {
+	for _, r := range text {
+		if r == ' ' {
+			s.HandleKeypress(tea.KeyMsg{Type: tea.KeySpace})
+			continue
+		}
+		s.HandleKeypress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return s.HandleKeypress(tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+func TestMCPAddWizard_HTTPServer(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	reg, err := coremcp.NewRegistry(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	withTestRegistry(t, reg)
+	if err := reg.AddServer("taken", coremcp.ServerConfig{Command: "echo"}, coremcp.ScopeLocal); err != nil {
+		t.Fatalf("AddServer() error = %v", err)
+	}
+	selector := NewMCPSelector(reg)
+
+	if _, _, err := HandleMCPCommand(context.Background(), &selector, 100, 40, "add"); err != nil {
+		t.Fatalf("HandleMCPCommand(add) error = %v", err)
+	}
+	if selector.level != mcpLevelAdd {
+		t.Fatalf("expected /mcp add to open the wizard, level = %v", selector.level)
+	}
+
+	typeMCPWizard(&selector, "taken")
+	if selector.add.err == "" || selector.add.step != mcpAddStepName {
+		t.Fatalf("expected duplicate name to be rejected, step=%v err=%q", selector.add.step, selector.add.err)
+	}
+	selector.add.input = ""
+	typeMCPWizard(&selector, "api")
+
+	selector.HandleKeypress(tea.KeyMsg{Type: tea.KeyDown}) // http
+	selector.HandleKeypress(tea.KeyMsg{Type: tea.KeyEnter})
+
+	typeMCPWizard(&selector, "not a url")
+	if selector.add.err == "" {
+		t.Fatal("expected invalid URL to be rejected")
+	}
+	selector.add.input = ""
+	typeMCPWizard(&selector, "https://example.com/mcp")
+
+	selector.HandleKeypress(tea.KeyMsg{Type: tea.KeyDown}) // project
+	selector.HandleKeypress(tea.KeyMsg{Type: tea.KeyEnter})
+
+	typeMCPWizard(&selector, "TOKEN=abc")
+	typeMCPWizard(&selector, "") // done with env
+	typeMCPWizard(&selector, "Authorization: Bearer abc")
+	typeMCPWizard(&selector, "") // done with headers
+	if selector.add.step != mcpAddStepConfirm {
+		t.Fatalf("expected confirm step, got %v", selector.add.step)
+	}
+
+	cmd := selector.HandleKeypress(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatalf("expected connect command, wizard error = %q", selector.add.err)
+	}
+	if msg, ok := cmd().(MCPConnectMsg); !ok || msg.ServerName != "api" {
+		t.Fatalf("expected MCPConnectMsg for api, got %#v", msg)
+	}
+
+	cfg, ok := reg.GetConfig("api")
+	if !ok {
+		t.Fatal("expected server to be saved")
+	}
+	if cfg.Type != coremcp.TransportHTTP || cfg.URL != "https://example.com/mcp" || cfg.Scope != coremcp.ScopeProject {
+		t.Errorf("unexpected config: %#v", cfg)
+	}
+	if cfg.Env["TOKEN"] != "abc" || cfg.Headers["Authorization"] != "Bearer abc" {
+		t.Errorf("unexpected env/headers: %v %v", cfg.Env, cfg.Headers)
+	}
+}
+
+func TestMCPAddWizard_StdioArgsRoundTrip(t *testing.T) {
+	w := mcpAddWizard{
+		name:      "fs",
+		transport: "stdio",
+		target:    "npx -y server-filesystem .",
+		scope:     "local",
+		env:       []string{"A=1"},
+	}
+	req, msg := parseMCPAddArgs(w.args())
+	if msg != "" {
+		t.Fatalf("parseMCPAddArgs() = %q", msg)
+	}
+	if req.Name != "fs" || req.Config.Command != "npx" || strings.Join(req.Config.Args, " ") != "-y server-filesystem ." {
+		t.Errorf("unexpected request: %#v", req)
+	}
+	if req.Config.Env["A"] != "1" {
+		t.Errorf("expected env A=1, got %v", req.Config.Env)
+	}
+
+	w.target = `node "/opt/my server/index.js" --name 'two words'`
+	if got := w.validateTarget(w.target); got != "" {
+		t.Fatalf("validateTarget(quoted) = %q", got)
+	}
+	req, _ = parseMCPAddArgs(w.args())
+	if want := []string{"/opt/my server/index.js", "--name", "two words"}; strings.Join(req.Config.Args, "|") != strings.Join(want, "|") {
+		t.Errorf("quoted args = %q, want %q", req.Config.Args, want)
+	}
+	if back, _ := splitMCPCommand(quoteMCPArgs(w.args())); strings.Join(back, "|") != strings.Join(w.args(), "|") {
+		t.Errorf("confirm line %q does not split back into %q", quoteMCPArgs(w.args()), w.args())
+	}
+	if got := w.validateTarget(`node "unterminated`); !strings.HasPrefix(got, "Invalid command") {
+		t.Errorf("validateTarget(unbalanced quote) = %q, want an invalid command error", got)
+	}
+
+	t.Setenv("TOKEN", "leaked-secret")
+	w.target = `server --token ${TOKEN} "$TOKEN" ~/data`
+	w.env = []string{"API_KEY=${TOKEN}"}
+	req, _ = parseMCPAddArgs(w.args())
+	home, _ := os.UserHomeDir()
+	if want := []string{"--token", "${TOKEN}", "$TOKEN", home + "/data"}; strings.Join(req.Config.Args, "|") != strings.Join(want, "|") {
+		t.Errorf("args with variables = %q, want %q", req.Config.Args, want)
+	}
+	if req.Config.Env["API_KEY"] != "${TOKEN}" {
+		t.Errorf("env API_KEY = %q, want the unexpanded reference", req.Config.Env["API_KEY"])
+	}
+	if back, _ := splitMCPCommand(quoteMCPArgs(w.args())); strings.Join(back, "|") != strings.Join(w.args(), "|") {
+		t.Errorf("confirm line %q does not split back into %q", quoteMCPArgs(w.args()), w.args())
+	}
+
+	ws := mcpAddWizard{name: "live", transport: "ws", target: "https://example.com", scope: "user"}
+	if got := ws.validateTarget(ws.target); got == "" {
+		t.Error("expected an http URL to be rejected for the ws transport")
+	}
+	ws.target = "wss://example.com/mcp"
+	if got := ws.validateTarget(ws.target); got != "" {
+		t.Errorf("validateTarget(wss) = %q", got)
+	}
+	req, msg = parseMCPAddArgs(ws.args())
+	if msg != "" {
+		t.Fatalf("parseMCPAddArgs(ws) = %q", msg)
+	}
+	if req.Config.Type != coremcp.TransportWebSocket || req.Config.URL != "wss://example.com/mcp" {
+		t.Errorf("unexpected ws request: %#v", req)
+	}
+
+	if got := validateMCPServerName("my__server", nil); got == "" {
+		t.Error(`expected name containing "__" to be rejected`)
+	}
+}
+
+func TestParseMCPAddArgs_OAuth(t *testing.T) {
+	req, msg := parseMCPAddArgs(strings.Fields("--transport http --oauth --client-id gen --oauth-scope read --oauth-scope write docs https://docs.example.com/mcp"))
+	if msg != "" {
+		t.Fatalf("parseMCPAddArgs() = %q", msg)
+	}
+	oauth := req.Config.OAuth
+	if oauth == nil || oauth.ClientID != "gen" || strings.Join(oauth.Scopes, " ") != "read write" || oauth.AuthURL != "" {
+		t.Fatalf("OAuth = %+v", oauth)
+	}
+
+	for args, want := range map[string]string{
+		"--oauth docs -- npx server":                                       "requires the http or sse transport",
+		"--transport http --oauth docs https://x/mcp":                      "requires --client-id",
+		"--transport http --client-id gen docs https://x/mcp":              "require --oauth",
+		"--transport sse --oauth --client-id gen --auth-url https://a d u": "",
+	} {
+		if _, msg := parseMCPAddArgs(strings.Fields(args)); !strings.Contains(msg, want) || (want == "" && msg != "") {
+			t.Errorf("%q = %q, want %q", args, msg, want)
+		}
+	}
+}
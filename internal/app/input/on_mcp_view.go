@@ -45,8 +45,11 @@ func (s *MCPSelector) Render() string {
 		return ""
 	}
 
-	if s.level == mcpLevelDetail {
+	switch s.level {
+	case mcpLevelDetail:
 		return s.renderDetail()
+	case mcpLevelAdd:
+		return s.renderAddWizard()
 	}
 	return s.renderList()
 }
@@ -91,7 +94,7 @@ func (s *MCPSelector) renderList() string {
 	if len(s.filteredServers) == 0 {
 		if len(s.servers) == 0 {
 			sb.WriteString(kit.SelectorHintStyle().Render("  No MCP servers configured\n\n"))
-			sb.WriteString(kit.SelectorHintStyle().Render("  Add servers with:\n"))
+			sb.WriteString(kit.SelectorHintStyle().Render("  Press Ctrl+N to add one, or run:\n"))
 			sb.WriteString(kit.SelectorHintStyle().Render("    gen mcp add <name> -- <command>\n"))
 		} else {
 			sb.WriteString(kit.SelectorHintStyle().Render("  No servers match the filter"))
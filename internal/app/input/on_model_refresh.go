@@ -0,0 +1,127 @@
+package input
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yanmxa/gencode/internal/llm"
+)
+
+// ModelsRefreshedMsg is sent when /model refresh has re-fetched the model
+// lists of the connected providers.
+type ModelsRefreshedMsg struct {
+	Result string
+}
+
+// modelRefresh is the outcome of re-listing one provider's models.
+type modelRefresh struct {
+	provider string
+	total    int
+	added    []string
+	removed  []string
+	err      error
+}
+
+// refreshModelsCmd calls ListModels on every connected provider, bypassing
+// the model cache, and stores the fresh lists with CacheModels. The result
+// reports the models each provider added or removed since the last cache.
+func refreshModelsCmd(store *llm.Store) tea.Cmd {
+	connections := store.GetConnections()
+	cached := store.GetAllCachedModelsIncludeExpired()
+	return func() tea.Msg {
+		ctx := context.Background()
+		results := make([]modelRefresh, 0, len(connections))
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for name, conn := range connections {
+			wg.Add(1)
+			go func(name string, authMethod llm.AuthMethod) {
+				defer wg.Done()
+				r := modelRefresh{provider: name}
+				models, err := listProviderModels(ctx, llm.Name(name), authMethod)
+				if err != nil {
+					r.err = err
+				} else {
+					_ = store.CacheModels(llm.Name(name), authMethod, models)
+					r.total = len(models)
+					r.added, r.removed = diffModelIDs(cached[name+":"+string(authMethod)], models)
+				}
+				mu.Lock()
+				results = append(results, r)
+				mu.Unlock()
+			}(name, conn.AuthMethod)
+		}
+		wg.Wait()
+		return ModelsRefreshedMsg{Result: formatModelRefresh(results)}
+	}
+}
+
+func listProviderModels(ctx context.Context, name llm.Name, authMethod llm.AuthMethod) ([]llm.ModelInfo, error) {
+	p, err := llm.GetProvider(ctx, name, authMethod)
+	if err != nil {
+		return nil, err
+	}
+	models, err := p.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(models) == 0 {
+		// Keep the cached list rather than replacing it with nothing.
+		return nil, fmt.Errorf("no models returned")
+	}
+	return models, nil
+}
+
+// diffModelIDs returns the IDs in fresh but not in old, and in old but not
+// in fresh, each sorted.
+func diffModelIDs(old, fresh []llm.ModelInfo) (added, removed []string) {
+	oldIDs := make(map[string]bool, len(old))
+	for _, m := range old {
+		oldIDs[m.ID] = true
+	}
+	freshIDs := make(map[string]bool, len(fresh))
+	for _, m := range fresh {
+		freshIDs[m.ID] = true
+		if !oldIDs[m.ID] {
+			added = append(added, m.ID)
+		}
+	}
+	for id := range oldIDs {
+		if !freshIDs[id] {
+			removed = append(removed, id)
+		}
+	}
+	slices.Sort(added)
+	slices.Sort(removed)
+	return added, removed
+}
+
+func formatModelRefresh(results []modelRefresh) string {
+	if len(results) == 0 {
+		return "No providers connected. Use /provider to connect one."
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].provider < results[j].provider })
+
+	var sb strings.Builder
+	sb.WriteString("Refreshed models:")
+	for _, r := range results {
+		if r.err != nil {
+			fmt.Fprintf(&sb, "\n  %s: failed, kept the cached list (%v)", r.provider, r.err)
+			continue
+		}
+		fmt.Fprintf(&sb, "\n  %s: %d models, %d added, %d removed", r.provider, r.total, len(r.added), len(r.removed))
+		if len(r.added) > 0 {
+			fmt.Fprintf(&sb, "\n    + %s", strings.Join(r.added, ", "))
+		}
+		if len(r.removed) > 0 {
+			fmt.Fprintf(&sb, "\n    - %s", strings.Join(r.removed, ", "))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,70 @@
+package input
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/llm/fake"
+)
+
+func TestDiffModelIDs(t *testing.T) {
+	old := []llm.ModelInfo{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	fresh := []llm.ModelInfo{{ID: "d"}, {ID: "b"}, {ID: "a"}, {ID: "e"}}
+	added, removed := diffModelIDs(old, fresh)
+	if !slices.Equal(added, []string{"d", "e"}) || !slices.Equal(removed, []string{"c"}) {
+		t.Errorf("diffModelIDs = +%v -%v, want +[d e] -[c]", added, removed)
+	}
+}
+
+func TestModelRefreshCommandUpdatesCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	fake.Install(t)
+	store, err := llm.NewStore()
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if err := store.Connect(fake.Name, llm.AuthAPIKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.CacheModels(fake.Name, llm.AuthAPIKey, []llm.ModelInfo{{ID: "retired-model"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	deps := CommandDeps{Input: &Model{}, ProviderStore: store, SpinnerTickCmd: func() tea.Cmd { return nil }}
+	ctrl := NewCommandController(deps)
+	if _, cmd, err := ctrl.handleModelCommand(context.Background(), "refresh"); err != nil || cmd == nil {
+		t.Fatalf("refresh: cmd=%v err=%v", cmd != nil, err)
+	}
+	if !deps.Input.Provider.RefreshingModels {
+		t.Error("refresh should show the spinner while fetching")
+	}
+
+	msg := refreshModelsCmd(store)().(ModelsRefreshedMsg)
+	for _, want := range []string{"fake: 1 models, 1 added, 1 removed", "+ " + fake.Model, "- retired-model"} {
+		if !strings.Contains(msg.Result, want) {
+			t.Errorf("result missing %q:\n%s", want, msg.Result)
+		}
+	}
+	models, ok := store.GetCachedModels(fake.Name, llm.AuthAPIKey)
+	if !ok || len(models) != 1 || models[0].ID != fake.Model {
+		t.Errorf("cached models = %+v, want the fresh list", models)
+	}
+}
+
+func TestFormatModelRefreshReportsFailures(t *testing.T) {
+	if got := formatModelRefresh(nil); !strings.Contains(got, "No providers connected") {
+		t.Errorf("no providers = %q", got)
+	}
+	got := formatModelRefresh([]modelRefresh{
+		{provider: "openai", total: 3},
+		{provider: "anthropic", err: context.DeadlineExceeded},
+	})
+	if !strings.Contains(got, "anthropic: failed, kept the cached list") || strings.Index(got, "anthropic") > strings.Index(got, "openai") {
+		t.Errorf("formatModelRefresh =\n%s", got)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -19,6 +20,7 @@ import (
 	"github.com/yanmxa/gencode/internal/llm"
 	"github.com/yanmxa/gencode/internal/log"
 	"github.com/yanmxa/gencode/internal/secret"
+	"github.com/yanmxa/gencode/internal/setting"
 )
 
 // ── State ──────────────────────────────────────────────────────────────────────
@@ -27,10 +29,11 @@ import (
 // Domain state (LLM, Store, CurrentModel, tokens, thinking) lives
 // on the parent app model, not here.
 type ProviderState struct {
-	FetchingLimits bool
-	Selector       ProviderSelector
-	StatusMessage  string // Temporary status shown in status bar
-	statusToken    int64
+	FetchingLimits   bool
+	RefreshingModels bool
+	Selector         ProviderSelector
+	StatusMessage    string // Temporary status shown in status bar
+	statusToken      int64
 }
 
 // SetStatusMessage sets the temporary status message displayed in the status bar.
@@ -78,9 +81,26 @@ func handleProviderModelSelected(deps OverlayDeps, state *ProviderState, msg Pro
 	})
 	ctx := context.Background()
 	providerRefreshConnection(deps, state, ctx, llm.Name(msg.ProviderName), msg.AuthMethod)
+	if msg.SaveToProject {
+		return saveProjectModel(deps, msg)
+	}
 	return nil
 }
 
+// saveProjectModel writes the selected model to .gen/settings.json as the
+// model setting, which new sessions in this project start on.
+func saveProjectModel(deps OverlayDeps, msg ProviderModelSelectedMsg) tea.Cmd {
+	spec := llm.FallbackEntry{Provider: llm.Name(msg.ProviderName), ModelID: msg.ModelID}.String()
+	notice := fmt.Sprintf("Saved %s as this project's model in .gen/settings.json", spec)
+	if deps.SaveSettingAt == nil {
+		notice = "Error: project settings are not available"
+	} else if err := deps.SaveSettingAt(false, "model", spec); err != nil {
+		notice = "Error: failed to save project model: " + err.Error()
+	}
+	deps.Conv.Append(core.ChatMessage{Role: core.RoleNotice, Content: notice})
+	return tea.Batch(deps.CommitMessages()...)
+}
+
 func providerRefreshConnection(deps OverlayDeps, state *ProviderState, ctx context.Context, providerName llm.Name, authMethod llm.AuthMethod) {
 	p, err := llm.GetProvider(ctx, providerName, authMethod)
 	if err != nil {
@@ -119,6 +139,7 @@ type providerListItem struct {
 	Provider    *providerProviderItem
 	AuthMethod  *providerAuthMethodItem
 	ProviderIdx int // index into allProviders
+	HiddenCount int // provider headers: models hidden by the curated list
 }
 
 // providerProviderItem represents a provider with its auth methods.
@@ -146,10 +167,22 @@ type providerModelItem struct {
 	ProviderName     string
 	AuthMethod       llm.AuthMethod
 	IsCurrent        bool
+	IsFavorite       bool
 	InputTokenLimit  int
 	OutputTokenLimit int
 }
 
+// label returns the name shown for the model in the selector.
+func (m providerModelItem) label() string {
+	if m.DisplayName != "" {
+		return m.DisplayName
+	}
+	if m.Name != "" {
+		return m.Name
+	}
+	return m.ID
+}
+
 // ProviderSelector holds the state for the unified model & provider kit.
 type ProviderSelector struct {
 	active bool
@@ -185,6 +218,13 @@ type ProviderSelector struct {
 	searchQuery    string
 	filteredModels []providerModelItem
 
+	// showAllModels reveals the full catalog instead of the curated short list
+	// (favorites and current model, or the first few models per provider).
+	showAllModels bool
+
+	// modelSort orders models within each provider group (setting.ModelSort*).
+	modelSort string
+
 	// Provider connection result (shown inline)
 	lastConnectResult  string
 	lastConnectAuthIdx int // item index that triggered the connection
@@ -227,6 +267,9 @@ type ProviderModelSelectedMsg struct {
 	ModelID      string
 	ProviderName string
 	AuthMethod   llm.AuthMethod
+	// SaveToProject also writes the model to the project's settings, so new
+	// sessions in this project start on it.
+	SaveToProject bool
 }
 
 // ProviderConnectResultMsg is sent when inline connection completes.
@@ -286,6 +329,7 @@ func (s *ProviderSelector) switchTab(t providerTab) {
 	s.resetNavigation()
 	s.resetModelSearch()
 	s.resetConnectionResult()
+	s.showAllModels = false
 	s.expandedProviderIdx = -1
 	s.apiKeyActive = false
 	s.rebuildVisibleItems()
@@ -343,6 +387,25 @@ func (s *ProviderSelector) HandleKeypress(key tea.KeyMsg) tea.Cmd {
 		}
 		return nil
 
+	case tea.KeyCtrlF:
+		s.toggleFavorite()
+		return nil
+
+	case tea.KeyCtrlS:
+		if s.selectedIdx >= 0 && s.selectedIdx < len(s.visibleItems) {
+			if item := s.visibleItems[s.selectedIdx]; item.Kind == providerItemModel {
+				return s.selectModel(item.Model, true)
+			}
+		}
+		return nil
+
+	case tea.KeyCtrlA:
+		if s.activeTab == providerTabModels {
+			s.showAllModels = !s.showAllModels
+			s.rebuildVisibleItems()
+		}
+		return nil
+
 	case tea.KeyShiftTab:
 		if s.searchQuery == "" {
 			s.PrevTab()
@@ -458,7 +521,7 @@ func (s *ProviderSelector) Select() tea.Cmd {
 	item := s.visibleItems[s.selectedIdx]
 	switch item.Kind {
 	case providerItemModel:
-		return s.selectModel(item.Model)
+		return s.selectModel(item.Model, false)
 	case providerItemProvider:
 		return s.selectProvider(item)
 	case providerItemAuthMethod:
@@ -468,16 +531,17 @@ func (s *ProviderSelector) Select() tea.Cmd {
 	}
 }
 
-func (s *ProviderSelector) selectModel(m *providerModelItem) tea.Cmd {
+func (s *ProviderSelector) selectModel(m *providerModelItem, saveToProject bool) tea.Cmd {
 	if m == nil {
 		return nil
 	}
 	s.active = false
 	return func() tea.Msg {
 		return ProviderModelSelectedMsg{
-			ModelID:      m.ID,
-			ProviderName: m.ProviderName,
-			AuthMethod:   m.AuthMethod,
+			ModelID:       m.ID,
+			ProviderName:  m.ProviderName,
+			AuthMethod:    m.AuthMethod,
+			SaveToProject: saveToProject,
 		}
 	}
 }
@@ -588,6 +652,8 @@ var providerOrder = []llm.Name{
 	llm.MinMax,
 	llm.Moonshot,
 	llm.Alibaba,
+	llm.DeepSeek,
+	llm.Ollama,
 }
 
 // providerDisplayNames maps provider to human-readable name.
@@ -598,6 +664,8 @@ var providerDisplayNames = map[llm.Name]string{
 	llm.MinMax:    "MiniMax",
 	llm.Moonshot:  "Moonshot",
 	llm.Alibaba:   "Alibaba",
+	llm.DeepSeek:  "DeepSeek",
+	llm.Ollama:    "Ollama",
 }
 
 // Enter opens the unified model & provider kit.
@@ -860,7 +928,11 @@ func (s *ProviderSelector) rebuildVisibleItems() {
 }
 
 // rebuildModelsTab builds visible items for the Models tab.
+// Without a search query and unless showAllModels is set, each provider shows
+// a curated list: pinned favorites plus the current model, or the first
+// modelListLimit models when nothing is pinned.
 func (s *ProviderSelector) rebuildModelsTab() {
+	s.markFavorites()
 	s.applyFilter()
 
 	// Group filtered models by provider
@@ -883,10 +955,15 @@ func (s *ProviderSelector) rebuildModelsTab() {
 			ProviderIdx: i,
 		})
 
-		// Sort models: current first
-		sort.SliceStable(models, func(a, b int) bool {
-			return models[a].IsCurrent && !models[b].IsCurrent
-		})
+		s.sortModelsWithCurrentFirst(models)
+
+		hidden := 0
+		if s.searchQuery == "" && !s.showAllModels {
+			total := len(models)
+			models = s.curateModels(models)
+			hidden = total - len(models)
+		}
+		s.visibleItems[len(s.visibleItems)-1].HiddenCount = hidden
 
 		for j := range models {
 			s.visibleItems = append(s.visibleItems, providerListItem{
@@ -931,6 +1008,99 @@ func (s *ProviderSelector) rebuildProvidersTab() {
 	}
 }
 
+// SetModelSort sets the ordering applied within each provider group.
+func (s *ProviderSelector) SetModelSort(order string) {
+	s.modelSort = order
+}
+
+// sortModelsWithCurrentFirst orders one provider's models: the current model,
+// then favorites, then the rest by the configured ordering. The provider
+// ordering keeps the catalog order.
+func (s *ProviderSelector) sortModelsWithCurrentFirst(models []providerModelItem) {
+	var lastUsed map[string]time.Time
+	if s.modelSort == setting.ModelSortRecency && s.store != nil {
+		lastUsed = make(map[string]time.Time, len(models))
+		for _, m := range models {
+			lastUsed[m.ID] = s.store.GetModelLastUsed(llm.Name(m.ProviderName), m.ID)
+		}
+	}
+
+	sort.SliceStable(models, func(a, b int) bool {
+		if models[a].IsCurrent != models[b].IsCurrent {
+			return models[a].IsCurrent
+		}
+		if models[a].IsFavorite != models[b].IsFavorite {
+			return models[a].IsFavorite
+		}
+		switch s.modelSort {
+		case setting.ModelSortAlpha:
+			return strings.ToLower(models[a].label()) < strings.ToLower(models[b].label())
+		case setting.ModelSortRecency:
+			return lastUsed[models[a].ID].After(lastUsed[models[b].ID])
+		}
+		return false
+	})
+}
+
+// curateModels returns the short default list for one provider's models,
+// which must already be sorted with current and favorites first.
+func (s *ProviderSelector) curateModels(models []providerModelItem) []providerModelItem {
+	pinned, hasFavorites := 0, false
+	for _, m := range models {
+		if m.IsCurrent || m.IsFavorite {
+			pinned++
+		}
+		hasFavorites = hasFavorites || m.IsFavorite
+	}
+	if hasFavorites {
+		return models[:pinned]
+	}
+	return models[:min(len(models), max(pinned, s.modelListLimit()))]
+}
+
+func (s *ProviderSelector) modelListLimit() int {
+	if s.store == nil {
+		return llm.DefaultModelListLimit
+	}
+	return s.store.GetModelListLimit()
+}
+
+// markFavorites refreshes IsFavorite on all models from the store.
+func (s *ProviderSelector) markFavorites() {
+	if s.store == nil {
+		return
+	}
+	favorites := make(map[string]map[string]bool)
+	for i := range s.allModels {
+		m := &s.allModels[i]
+		set, ok := favorites[m.ProviderName]
+		if !ok {
+			set = make(map[string]bool)
+			for _, id := range s.store.GetFavoriteModels(llm.Name(m.ProviderName)) {
+				set[id] = true
+			}
+			favorites[m.ProviderName] = set
+		}
+		m.IsFavorite = set[m.ID]
+	}
+}
+
+// toggleFavorite pins or unpins the selected model.
+func (s *ProviderSelector) toggleFavorite() {
+	if s.activeTab != providerTabModels || s.store == nil ||
+		s.selectedIdx >= len(s.visibleItems) {
+		return
+	}
+	item := s.visibleItems[s.selectedIdx]
+	if item.Kind != providerItemModel || item.Model == nil {
+		return
+	}
+	if _, err := s.store.ToggleFavoriteModel(llm.Name(item.Model.ProviderName), item.Model.ID); err != nil {
+		return
+	}
+	s.rebuildVisibleItems()
+}
+
 func (s *ProviderSelector) applyFilter() {
 	if s.searchQuery == "" {
 		s.filteredModels = s.allModels
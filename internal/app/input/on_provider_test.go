@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/yanmxa/gencode/internal/app/conv"
 	"github.com/yanmxa/gencode/internal/app/kit"
 	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/setting"
 )
 
 type connectFailProvider struct{}
@@ -499,3 +502,102 @@ func TestRebuildVisibleItemsStructure(t *testing.T) {
 		t.Fatalf("selectedIdx should be 1 (first model), got %d", m.selectedIdx)
 	}
 }
+
+func TestRebuildModelsTabCuratesLargeCatalogs(t *testing.T) {
+	m := NewProviderSelector()
+	m.activeTab = providerTabModels
+	for i := 0; i < llm.DefaultModelListLimit+5; i++ {
+		m.allModels = append(m.allModels, providerModelItem{ID: fmt.Sprintf("m%d", i), ProviderName: "openrouter"})
+	}
+	m.connectedProviders = []providerProviderItem{{Provider: "openrouter", DisplayName: "OpenRouter"}}
+
+	m.rebuildVisibleItems()
+	if got := len(m.visibleItems); got != llm.DefaultModelListLimit+1 {
+		t.Fatalf("curated list: expected %d items, got %d", llm.DefaultModelListLimit+1, got)
+	}
+	if m.visibleItems[0].HiddenCount != 5 {
+		t.Fatalf("expected header to report 5 hidden models, got %d", m.visibleItems[0].HiddenCount)
+	}
+
+	m.HandleKeypress(tea.KeyMsg{Type: tea.KeyCtrlA})
+	if got := len(m.visibleItems); got != len(m.allModels)+1 {
+		t.Fatalf("show all: expected %d items, got %d", len(m.allModels)+1, got)
+	}
+}
+
+func TestSortModelsWithCurrentFirst(t *testing.T) {
+	ids := func(models []providerModelItem) string {
+		var out []string
+		for _, m := range models {
+			out = append(out, m.ID)
+		}
+		return strings.Join(out, ",")
+	}
+	catalog := func() []providerModelItem {
+		return []providerModelItem{
+			{ID: "zeta", ProviderName: "openai"},
+			{ID: "beta", ProviderName: "openai", DisplayName: "Beta"},
+			{ID: "cur", ProviderName: "openai", IsCurrent: true},
+			{ID: "alpha", ProviderName: "openai"},
+			{ID: "fav", ProviderName: "openai", IsFavorite: true},
+		}
+	}
+
+	store := newProviderTestStore(t)
+	for _, id := range []string{"zeta", "alpha", "cur"} {
+		if err := store.SetCurrentModel(id, llm.OpenAI, llm.AuthAPIKey); err != nil {
+			t.Fatalf("SetCurrentModel(%s) error = %v", id, err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	tests := []struct {
+		order string
+		want  string
+	}{
+		{setting.ModelSortProvider, "cur,fav,zeta,beta,alpha"},
+		{"", "cur,fav,zeta,beta,alpha"},
+		{setting.ModelSortAlpha, "cur,fav,alpha,beta,zeta"},
+		{setting.ModelSortRecency, "cur,fav,alpha,zeta,beta"},
+	}
+	for _, tt := range tests {
+		m := NewProviderSelector()
+		m.store = store
+		m.SetModelSort(tt.order)
+		models := catalog()
+		m.sortModelsWithCurrentFirst(models)
+		if got := ids(models); got != tt.want {
+			t.Errorf("order %q: got %s, want %s", tt.order, ids(models), tt.want)
+		}
+	}
+}
+
+func TestCtrlSSavesModelForProject(t *testing.T) {
+	m := NewProviderSelector()
+	m.active = true
+	m.activeTab = providerTabModels
+	m.visibleItems = []providerListItem{{Kind: providerItemModel, Model: &providerModelItem{ID: "gpt-5", ProviderName: "openai", AuthMethod: llm.AuthAPIKey}}}
+
+	msg, ok := m.HandleKeypress(tea.KeyMsg{Type: tea.KeyCtrlS})().(ProviderModelSelectedMsg)
+	if !ok || !msg.SaveToProject || msg.ModelID != "gpt-5" {
+		t.Fatalf("Ctrl+S = %+v, want the model selected and saved for the project", msg)
+	}
+
+	var saved []string
+	c := conv.NewConversation()
+	deps := OverlayDeps{
+		Conv:           &c,
+		CommitMessages: func() []tea.Cmd { return nil },
+		SaveSettingAt: func(userLevel bool, key string, value any) error {
+			saved = append(saved, fmt.Sprintf("%v %s=%v", userLevel, key, value))
+			return nil
+		},
+	}
+	saveProjectModel(deps, msg)
+	if len(saved) != 1 || saved[0] != "false model=openai:gpt-5" {
+		t.Errorf("saved %q, want the project-level model setting", saved)
+	}
+	if n := len(c.Messages); n != 1 || !strings.Contains(c.Messages[0].Content, "openai:gpt-5") {
+		t.Errorf("notice = %+v", c.Messages)
+	}
+}
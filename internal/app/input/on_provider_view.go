@@ -243,7 +243,11 @@ func (s *ProviderSelector) renderProviderHeader(item providerListItem) string {
 	if name == "" {
 		name = string(item.Provider.Provider)
 	}
-	return style.Render(name)
+	header := style.Render(name)
+	if item.HiddenCount > 0 {
+		header += kit.DimStyle().Render(fmt.Sprintf("  (+%d more · Ctrl+A show all)", item.HiddenCount))
+	}
+	return header
 }
 
 func (s *ProviderSelector) renderModelRow(item providerListItem, isSelected bool) string {
@@ -256,20 +260,19 @@ func (s *ProviderSelector) renderModelRow(item providerListItem, isSelected bool
 		indicatorStyle = kit.SelectorStatusConnected()
 	}
 
-	displayName := m.DisplayName
-	if displayName == "" {
-		displayName = m.Name
-	}
-	if displayName == "" {
-		displayName = m.ID
-	}
+	displayName := m.label()
 
 	warning := ""
 	if m.InputTokenLimit == 0 && m.OutputTokenLimit == 0 {
 		warning = lipgloss.NewStyle().Foreground(kit.CurrentTheme.Warning).Render(" ⚠")
 	}
 
-	line := fmt.Sprintf("%s %s%s", indicatorStyle.Render(indicator), displayName, warning)
+	favorite := ""
+	if m.IsFavorite {
+		favorite = lipgloss.NewStyle().Foreground(kit.CurrentTheme.Warning).Render(" ★")
+	}
+
+	line := fmt.Sprintf("%s %s%s%s", indicatorStyle.Render(indicator), displayName, favorite, warning)
 	return kit.RenderSelectableRow(line, isSelected)
 }
 
@@ -358,7 +361,12 @@ func (s *ProviderSelector) renderHints() string {
 	if s.activeTab == providerTabProviders {
 		parts = append(parts, "Enter connect/refresh")
 	} else {
-		parts = append(parts, "Enter select")
+		parts = append(parts, "Enter select", "Ctrl+S project default", "Ctrl+F pin")
+		if s.showAllModels {
+			parts = append(parts, "Ctrl+A curated")
+		} else {
+			parts = append(parts, "Ctrl+A show all")
+		}
 	}
 	parts = append(parts, "←/→/Tab switch", "Esc cancel")
 	return kit.DimStyle().Render(strings.Join(parts, " · "))
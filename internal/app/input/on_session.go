@@ -106,8 +106,7 @@ func (s *SessionSelector) updateFilter() {
 	s.filtered = make([]*session.SessionMetadata, 0, len(s.sessions))
 
 	for _, sess := range s.sessions {
-		if query != "" && !kit.FuzzyMatch(strings.ToLower(sess.Title), query) &&
-			!kit.FuzzyMatch(strings.ToLower(sess.Model), query) {
+		if query != "" && !sessionMatchesQuery(sess, query) {
 			continue
 		}
 		s.filtered = append(s.filtered, sess)
@@ -117,6 +116,17 @@ func (s *SessionSelector) updateFilter() {
 	s.nav.Total = len(s.filtered)
 }
 
+// sessionMatchesQuery fuzzy-matches a lowercased query against the session's
+// name, generated title, model, and last prompt.
+func sessionMatchesQuery(sess *session.SessionMetadata, query string) bool {
+	for _, field := range []string{sess.CustomTitle, sess.Title, sess.Model, sess.LastPrompt} {
+		if field != "" && kit.FuzzyMatch(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *SessionSelector) Select() tea.Cmd {
 	if len(s.filtered) == 0 || s.nav.Selected >= len(s.filtered) {
 		return nil
@@ -152,7 +162,11 @@ func (s *SessionSelector) HandleKeypress(key tea.KeyMsg) tea.Cmd {
 }
 
 func sessionFormatCompactMetadata(sess *session.SessionMetadata) string {
-	return fmt.Sprintf("%d msgs · %s", sess.MessageCount, sessionFormatRelativeTime(sess.UpdatedAt))
+	meta := fmt.Sprintf("%d msgs · %s", sess.MessageCount, sessionFormatRelativeTime(sess.UpdatedAt))
+	if sess.Model != "" {
+		meta = sess.Model + " · " + meta
+	}
+	return meta
 }
 
 func sessionTruncateToFirstLine(content string, maxLen int) string {
@@ -268,7 +282,9 @@ func (s *SessionSelector) renderSession(sess *session.SessionMetadata, isSelecte
 	}
 
 	displayTitle := sess.Title
-	if len([]rune(displayTitle)) < session.MinSubstantiveLength {
+	if sess.CustomTitle != "" {
+		displayTitle = sess.CustomTitle
+	} else if len([]rune(displayTitle)) < session.MinSubstantiveLength {
 		if subst := s.getFirstSubstantiveMessage(sess); subst != "" {
 			displayTitle = subst
 		}
@@ -289,13 +305,40 @@ func (s *SessionSelector) renderSession(sess *session.SessionMetadata, isSelecte
 	padding := strings.Repeat(" ", gap)
 	sb.WriteString(titleStyle.Render(fmt.Sprintf("%s%s%s%s", indent, title, padding, metadata)) + "\n")
 
-	if lastMsg := s.getLastMessage(sess); lastMsg != "" {
+	preview := s.getLastMessage(sess)
+	if parent := s.parentLabel(sess); parent != "" {
+		preview = strings.TrimSuffix("↳ from "+parent+" · "+preview, " · ")
+		preview = kit.TruncateText(preview, calculateSessionPreviewLength(s.width))
+	}
+	if preview != "" {
 		previewStyle := lipgloss.NewStyle().Foreground(kit.CurrentTheme.Muted)
-		sb.WriteString(previewStyle.Render(fmt.Sprintf("    %s", lastMsg)))
+		sb.WriteString(previewStyle.Render(fmt.Sprintf("    %s", preview)))
 	}
 	sb.WriteString("\n\n")
 }
 
+// parentLabel names the session sess was forked or branched from, falling
+// back to its ID when the parent is no longer listed. It is "" for a
+// session without a parent.
+func (s *SessionSelector) parentLabel(sess *session.SessionMetadata) string {
+	if sess.ParentSessionID == "" {
+		return ""
+	}
+	for _, parent := range s.sessions {
+		if parent.ID != sess.ParentSessionID {
+			continue
+		}
+		if parent.CustomTitle != "" {
+			return parent.CustomTitle
+		}
+		if parent.Title != "" {
+			return parent.Title
+		}
+		break
+	}
+	return sess.ParentSessionID
+}
+
 func (s *SessionSelector) Render() string {
 	if !s.active {
 		return ""
@@ -306,7 +349,7 @@ func (s *SessionSelector) Render() string {
 	title := fmt.Sprintf("Resume Session - %s (%d/%d)", filepath.Base(s.cwd), len(s.filtered), len(s.sessions))
 	sb.WriteString(kit.SelectorTitleStyle().Render(title) + "\n")
 
-	searchLine := "🔍 Type to filter..."
+	searchLine := "🔍 Type to filter by name, model, or prompt..."
 	searchStyle := kit.SelectorHintStyle()
 	if s.nav.Search != "" {
 		searchLine = "> " + s.nav.Search + "_"
@@ -0,0 +1,65 @@
+package input
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yanmxa/gencode/internal/session"
+)
+
+func TestSessionSelectorFilter(t *testing.T) {
+	s := NewSessionSelector()
+	s.sessions = []*session.SessionMetadata{
+		{ID: "a", Title: "fix login bug", Model: "gpt-4o"},
+		{ID: "b", Title: "write docs", CustomTitle: "release notes", Model: "claude-sonnet-4"},
+		{ID: "c", Title: "refactor", LastPrompt: "split the parser package", Model: "gemini-2.0-flash"},
+	}
+
+	cases := map[string][]string{
+		"":        {"a", "b", "c"},
+		"lgn":     {"a"},
+		"release": {"b"},
+		"sonnet":  {"b"},
+		"parser":  {"c"},
+		"zzz":     {},
+	}
+	for query, want := range cases {
+		s.nav.Search = query
+		s.updateFilter()
+		var got []string
+		for _, sess := range s.filtered {
+			got = append(got, sess.ID)
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("filter %q = %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestSessionFormatCompactMetadata(t *testing.T) {
+	sess := &session.SessionMetadata{MessageCount: 4, UpdatedAt: time.Now(), Model: "gpt-4o"}
+	if got := sessionFormatCompactMetadata(sess); got != "gpt-4o · 4 msgs · just now" {
+		t.Errorf("metadata = %q", got)
+	}
+	sess.Model = ""
+	if got := sessionFormatCompactMetadata(sess); got != "4 msgs · just now" {
+		t.Errorf("metadata without model = %q", got)
+	}
+}
+
+func TestSessionSelectorParentLabel(t *testing.T) {
+	s := NewSessionSelector()
+	s.sessions = []*session.SessionMetadata{
+		{ID: "a", Title: "fix login bug"},
+		{ID: "b", Title: "fix login bug", CustomTitle: "login v2", ParentSessionID: "a"},
+		{ID: "c", Title: "try again", ParentSessionID: "b"},
+		{ID: "d", Title: "orphan", ParentSessionID: "gone"},
+	}
+	want := map[string]string{"a": "", "b": "fix login bug", "c": "login v2", "d": "gone"}
+	for _, sess := range s.sessions {
+		if got := s.parentLabel(sess); got != want[sess.ID] {
+			t.Errorf("parentLabel(%s) = %q, want %q", sess.ID, got, want[sess.ID])
+		}
+	}
+}
@@ -0,0 +1,396 @@
+package input
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/yanmxa/gencode/internal/app/kit"
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/setting"
+)
+
+// SettingChangedMsg is sent after the settings editor writes Key to a
+// settings file.
+type SettingChangedMsg struct {
+	Key string
+}
+
+// SettingsToolsMsg asks the app to open the tool selector from the settings
+// editor.
+type SettingsToolsMsg struct{}
+
+type settingsFieldKind int
+
+const (
+	settingsChoice settingsFieldKind = iota // Enter cycles through choices
+	settingsText                            // Enter opens a text input
+	settingsLink                            // Enter opens another selector
+)
+
+// settingsField is one row of the settings editor, backed by a top-level key
+// of settings.json.
+type settingsField struct {
+	key     string
+	label   string
+	kind    settingsFieldKind
+	choices []string // settingsChoice values; "" is the unset state
+	unset   string   // shown when the key is not set at this level
+	get     func(*setting.Settings) string
+	parse   func(string) (any, error) // settingsText input to the value written; nil removes the key
+}
+
+var settingsFields = []settingsField{
+	{
+		key:     "theme",
+		label:   "Theme",
+		kind:    settingsChoice,
+		choices: append([]string{""}, kit.ThemeNames()...),
+		unset:   "not set",
+		get:     func(s *setting.Settings) string { return s.Theme },
+	},
+	{
+		key:   "model",
+		label: "Default model",
+		kind:  settingsText,
+		unset: "last /model choice",
+		get:   func(s *setting.Settings) string { return s.Model },
+		parse: func(v string) (any, error) { return v, nil },
+	},
+	{
+		key:   "autoCompactThreshold",
+		label: "Auto-compact at",
+		kind:  settingsText,
+		unset: fmt.Sprintf("%d%%", core.DefaultCompactThreshold),
+		get: func(s *setting.Settings) string {
+			switch {
+			case s.AutoCompactThreshold == nil:
+				return ""
+			case *s.AutoCompactThreshold == 0:
+				return "off"
+			default:
+				return fmt.Sprintf("%d%%", *s.AutoCompactThreshold)
+			}
+		},
+		parse: func(v string) (any, error) {
+			if strings.EqualFold(v, "off") {
+				return 0, nil
+			}
+			n, err := strconv.Atoi(strings.TrimSuffix(v, "%"))
+			if err != nil {
+				return nil, fmt.Errorf("enter a percentage between %d and %d, or off", setting.MinAutoCompactThreshold, setting.MaxAutoCompactThreshold)
+			}
+			if err := setting.ValidateAutoCompactThreshold(n); err != nil {
+				return nil, err
+			}
+			return n, nil
+		},
+	},
+	{
+		key:     "defaultMode",
+		label:   "Permission mode",
+		kind:    settingsChoice,
+		choices: []string{"", "normal", "auto", "bypass"},
+		unset:   "normal",
+		get:     func(s *setting.Settings) string { return s.DefaultMode },
+	},
+	{
+		key:   "maxTokens",
+		label: "Max output tokens",
+		kind:  settingsText,
+		unset: "model limit",
+		get: func(s *setting.Settings) string {
+			if s.MaxTokens == 0 {
+				return ""
+			}
+			return strconv.Itoa(s.MaxTokens)
+		},
+		parse: func(v string) (any, error) {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("enter a positive number of tokens")
+			}
+			if n == 0 {
+				return nil, nil
+			}
+			return n, nil
+		},
+	},
+	{
+		key:   "maxToolParallel",
+		label: "Parallel tool calls",
+		kind:  settingsText,
+		unset: strconv.Itoa(core.DefaultMaxToolParallel),
+		get: func(s *setting.Settings) string {
+			if s.MaxToolParallel == 0 {
+				return ""
+			}
+			return strconv.Itoa(s.MaxToolParallel)
+		},
+		parse: func(v string) (any, error) {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("enter how many read-only tool calls may run at once, 1 or more")
+			}
+			return n, nil
+		},
+	},
+	{
+		key:   "disabledTools",
+		label: "Disabled tools",
+		kind:  settingsLink,
+		unset: "none",
+		get: func(s *setting.Settings) string {
+			n := 0
+			for _, disabled := range s.DisabledTools {
+				if disabled {
+					n++
+				}
+			}
+			if n == 0 {
+				return ""
+			}
+			return fmt.Sprintf("%d disabled", n)
+		},
+	},
+}
+
+// SettingsEditor is the /settings overlay: a form over common settings that
+// writes each change straight to the project or user settings file.
+type SettingsEditor struct {
+	active      bool
+	selectedIdx int
+	saveLevel   kit.SaveLevel
+	values      *setting.Settings // the file at saveLevel, not merged
+	width       int
+	height      int
+	err         string
+
+	editing bool
+	input   textinput.Model
+
+	load func(userLevel bool) *setting.Settings
+	save func(userLevel bool, key string, value any) error
+}
+
+// NewSettingsEditor creates a SettingsEditor with injected load/save callbacks.
+func NewSettingsEditor(
+	load func(userLevel bool) *setting.Settings,
+	save func(userLevel bool, key string, value any) error,
+) SettingsEditor {
+	return SettingsEditor{load: load, save: save}
+}
+
+// Enter opens the editor on the project settings file.
+func (e *SettingsEditor) Enter(width, height int) {
+	e.active = true
+	e.selectedIdx = 0
+	e.saveLevel = kit.SaveLevelProject
+	e.width = width
+	e.height = height
+	e.err = ""
+	e.editing = false
+	e.reload()
+}
+
+func (e *SettingsEditor) IsActive() bool {
+	return e.active
+}
+
+func (e *SettingsEditor) Cancel() {
+	e.active = false
+	e.editing = false
+	e.values = nil
+	e.err = ""
+}
+
+func (e *SettingsEditor) reload() {
+	e.values = nil
+	if e.load != nil {
+		e.values = e.load(e.saveLevel == kit.SaveLevelUser)
+	}
+	if e.values == nil {
+		e.values = setting.NewSettings()
+	}
+}
+
+// set writes value for field at the current level; nil removes the key.
+func (e *SettingsEditor) set(field settingsField, value any) tea.Cmd {
+	if e.save == nil {
+		return nil
+	}
+	if err := e.save(e.saveLevel == kit.SaveLevelUser, field.key, value); err != nil {
+		e.err = "Failed to save: " + err.Error()
+		return nil
+	}
+	e.err = ""
+	e.reload()
+	return func() tea.Msg { return SettingChangedMsg{Key: field.key} }
+}
+
+func (e *SettingsEditor) HandleKeypress(key tea.KeyMsg) tea.Cmd {
+	if e.editing {
+		return e.handleInput(key)
+	}
+
+	field := settingsFields[e.selectedIdx]
+	switch key.Type {
+	case tea.KeyUp, tea.KeyCtrlP:
+		e.move(-1)
+		return nil
+	case tea.KeyDown, tea.KeyCtrlN:
+		e.move(1)
+		return nil
+	case tea.KeyTab:
+		if e.saveLevel == kit.SaveLevelProject {
+			e.saveLevel = kit.SaveLevelUser
+		} else {
+			e.saveLevel = kit.SaveLevelProject
+		}
+		e.err = ""
+		e.reload()
+		return nil
+	case tea.KeyEnter, tea.KeySpace, tea.KeyRight:
+		return e.activate(field, 1)
+	case tea.KeyLeft:
+		if field.kind == settingsChoice {
+			return e.activate(field, -1)
+		}
+		return nil
+	case tea.KeyBackspace, tea.KeyDelete:
+		if field.kind == settingsLink || field.get(e.values) == "" {
+			return nil
+		}
+		return e.set(field, nil)
+	case tea.KeyEsc:
+		e.Cancel()
+		return func() tea.Msg { return kit.DismissedMsg{} }
+	}
+
+	switch key.String() {
+	case "j":
+		e.move(1)
+	case "k":
+		e.move(-1)
+	}
+	return nil
+}
+
+func (e *SettingsEditor) move(delta int) {
+	e.selectedIdx = min(max(e.selectedIdx+delta, 0), len(settingsFields)-1)
+	e.err = ""
+}
+
+// activate cycles a choice by step, opens the text input, or follows a link.
+func (e *SettingsEditor) activate(field settingsField, step int) tea.Cmd {
+	switch field.kind {
+	case settingsChoice:
+		i := slices.Index(field.choices, field.get(e.values))
+		next := field.choices[(max(i, 0)+step+len(field.choices))%len(field.choices)]
+		if next == "" {
+			return e.set(field, nil)
+		}
+		return e.set(field, next)
+	case settingsText:
+		ti := textinput.New()
+		ti.Placeholder = field.unset
+		ti.SetValue(strings.TrimSuffix(field.get(e.values), "%"))
+		ti.CursorEnd()
+		ti.Focus()
+		e.input = ti
+		e.editing = true
+		e.err = ""
+		return nil
+	case settingsLink:
+		e.Cancel()
+		return func() tea.Msg { return SettingsToolsMsg{} }
+	}
+	return nil
+}
+
+func (e *SettingsEditor) handleInput(key tea.KeyMsg) tea.Cmd {
+	switch key.Type {
+	case tea.KeyEnter:
+		field := settingsFields[e.selectedIdx]
+		text := strings.TrimSpace(e.input.Value())
+		if text == "" {
+			e.editing = false
+			return e.set(field, nil)
+		}
+		value, err := field.parse(text)
+		if err != nil {
+			e.err = err.Error()
+			return nil
+		}
+		e.editing = false
+		return e.set(field, value)
+	case tea.KeyEsc:
+		e.editing = false
+		e.err = ""
+		return nil
+	default:
+		e.input, _ = e.input.Update(key)
+		return nil
+	}
+}
+
+func (e *SettingsEditor) Render() string {
+	if !e.active {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(kit.SelectorTitleStyle().Render(fmt.Sprintf("Settings  [%s]", e.saveLevel)))
+	sb.WriteString("\n")
+	sb.WriteString(kit.DimStyle().Render(e.path()))
+	sb.WriteString("\n\n")
+
+	const labelCol = 20
+	for i, field := range settingsFields {
+		value := field.get(e.values)
+		valueText := value
+		if value == "" {
+			valueText = kit.DimStyle().Render(field.unset)
+		}
+		if field.kind == settingsLink {
+			valueText += kit.DimStyle().Render("  →")
+		}
+		isSelected := i == e.selectedIdx
+		if isSelected && e.editing {
+			inputBg := lipgloss.AdaptiveColor{Dark: "#1E293B", Light: "#F1F5F9"}
+			valueText = lipgloss.NewStyle().Background(inputBg).Padding(0, 1).Render(e.input.View())
+		}
+		sb.WriteString(kit.RenderSelectableRow(fmt.Sprintf("%-*s%s", labelCol, field.label, valueText), isSelected))
+		sb.WriteString("\n")
+	}
+
+	if e.err != "" {
+		sb.WriteString("\n")
+		sb.WriteString(kit.SelectorStatusError().Render(e.err))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	if e.editing {
+		sb.WriteString(kit.DimStyle().Render("Enter save · empty to unset · Esc cancel"))
+	} else {
+		sb.WriteString(kit.DimStyle().Render("↑/↓ navigate · Enter change · ⌫ unset · Tab level · Esc close"))
+	}
+
+	box := kit.SelectorBorderStyle().
+		Width(kit.CalculateBoxWidth(e.width)).
+		Render(sb.String())
+	return lipgloss.Place(e.width, e.height-2, lipgloss.Center, lipgloss.Top, box)
+}
+
+func (e *SettingsEditor) path() string {
+	if e.saveLevel == kit.SaveLevelUser {
+		return "~/.gen/settings.json"
+	}
+	return ".gen/settings.json"
+}
@@ -0,0 +1,151 @@
+package input
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yanmxa/gencode/internal/setting"
+)
+
+// fakeSettingsFiles stands in for the user and project settings files.
+type fakeSettingsFiles map[bool]map[string]any
+
+func (f fakeSettingsFiles) load(userLevel bool) *setting.Settings {
+	s := setting.NewSettings()
+	for key, v := range f[userLevel] {
+		switch key {
+		case "theme":
+			s.Theme = v.(string)
+		case "defaultMode":
+			s.DefaultMode = v.(string)
+		case "maxTokens":
+			s.MaxTokens = v.(int)
+		case "autoCompactThreshold":
+			n := v.(int)
+			s.AutoCompactThreshold = &n
+		}
+	}
+	return s
+}
+
+func (f fakeSettingsFiles) save(userLevel bool, key string, value any) error {
+	if f[userLevel] == nil {
+		f[userLevel] = map[string]any{}
+	}
+	if value == nil {
+		delete(f[userLevel], key)
+	} else {
+		f[userLevel][key] = value
+	}
+	return nil
+}
+
+func newTestSettingsEditor(files fakeSettingsFiles) *SettingsEditor {
+	e := NewSettingsEditor(files.load, files.save)
+	e.Enter(100, 30)
+	return &e
+}
+
+func selectSettingsField(t *testing.T, e *SettingsEditor, key string) {
+	t.Helper()
+	for i, f := range settingsFields {
+		if f.key == key {
+			e.selectedIdx = i
+			return
+		}
+	}
+	t.Fatalf("no settings field %q", key)
+}
+
+func typeSettingsInput(e *SettingsEditor, text string) tea.Cmd {
+	for _, r := range text {
+		e.HandleKeypress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return e.HandleKeypress(tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+func TestSettingsEditorCyclesChoicesAtLevel(t *testing.T) {
+	files := fakeSettingsFiles{}
+	e := newTestSettingsEditor(files)
+	selectSettingsField(t, e, "theme")
+
+	cmd := e.HandleKeypress(tea.KeyMsg{Type: tea.KeyEnter})
+	if files[false]["theme"] != "dark" {
+		t.Fatalf("project theme = %v, want dark", files[false]["theme"])
+	}
+	if msg, ok := cmd().(SettingChangedMsg); !ok || msg.Key != "theme" {
+		t.Fatalf("expected SettingChangedMsg for theme, got %#v", msg)
+	}
+
+	e.HandleKeypress(tea.KeyMsg{Type: tea.KeyTab})
+	e.HandleKeypress(tea.KeyMsg{Type: tea.KeyRight})
+	e.HandleKeypress(tea.KeyMsg{Type: tea.KeyRight})
+	if files[true]["theme"] != "light" {
+		t.Fatalf("user theme = %v, want light", files[true]["theme"])
+	}
+	if files[false]["theme"] != "dark" {
+		t.Error("editing the user level changed the project file")
+	}
+
+	e.HandleKeypress(tea.KeyMsg{Type: tea.KeyBackspace})
+	if _, ok := files[true]["theme"]; ok {
+		t.Error("backspace should remove the key")
+	}
+}
+
+func TestSettingsEditorValidatesTextInput(t *testing.T) {
+	files := fakeSettingsFiles{}
+	e := newTestSettingsEditor(files)
+	selectSettingsField(t, e, "autoCompactThreshold")
+
+	e.HandleKeypress(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd := typeSettingsInput(e, "20"); cmd != nil || e.err == "" || !e.editing {
+		t.Fatalf("out-of-range threshold should keep the input open with an error, err=%q", e.err)
+	}
+	if _, ok := files[false]["autoCompactThreshold"]; ok {
+		t.Fatal("invalid threshold was saved")
+	}
+
+	e.input.SetValue("")
+	if cmd := typeSettingsInput(e, "80%"); cmd == nil || e.editing {
+		t.Fatal("valid threshold should save and close the input")
+	}
+	if files[false]["autoCompactThreshold"] != 80 {
+		t.Fatalf("threshold = %v, want 80", files[false]["autoCompactThreshold"])
+	}
+	if !strings.Contains(e.Render(), "80%") {
+		t.Error("editor should show the saved threshold")
+	}
+
+	e.HandleKeypress(tea.KeyMsg{Type: tea.KeyEnter})
+	e.input.SetValue("")
+	typeSettingsInput(e, "off")
+	if files[false]["autoCompactThreshold"] != 0 {
+		t.Fatalf("threshold = %v, want 0 to turn auto-compact off", files[false]["autoCompactThreshold"])
+	}
+
+	e.HandleKeypress(tea.KeyMsg{Type: tea.KeyEnter})
+	e.input.SetValue("")
+	typeSettingsInput(e, "")
+	if _, ok := files[false]["autoCompactThreshold"]; ok {
+		t.Error("an empty value should remove the key")
+	}
+}
+
+func TestSettingsEditorOpensToolSelector(t *testing.T) {
+	e := newTestSettingsEditor(fakeSettingsFiles{})
+	selectSettingsField(t, e, "disabledTools")
+
+	cmd := e.HandleKeypress(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected a command")
+	}
+	if _, ok := cmd().(SettingsToolsMsg); !ok {
+		t.Fatal("expected SettingsToolsMsg")
+	}
+	if e.IsActive() {
+		t.Error("editor should close when handing off to the tool selector")
+	}
+}
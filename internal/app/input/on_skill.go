@@ -69,6 +69,24 @@ func (s *SkillState) ConsumeInvocation() (displayMsg, fullMsg string) {
 	return displayMsg, fullMsg
 }
 
+// skillEchoLines caps how much of an injected skill prompt is echoed.
+const skillEchoLines = 6
+
+// SkillEchoNotice formats a collapsed notice showing what a skill invocation
+// injected: the command line followed by the first few instruction lines.
+func SkillEchoNotice(displayMsg, instructions string) string {
+	lines := strings.Split(strings.TrimSpace(instructions), "\n")
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Skill prompt for %s (%d lines):", displayMsg, len(lines))
+	for _, line := range lines[:min(len(lines), skillEchoLines)] {
+		sb.WriteString("\n  " + line)
+	}
+	if hidden := len(lines) - skillEchoLines; hidden > 0 {
+		fmt.Fprintf(&sb, "\n  … %d more lines", hidden)
+	}
+	return sb.String()
+}
+
 // ClearPending resets pending skill state without activating.
 func (s *SkillState) ClearPending() {
 	s.PendingInstructions = ""
@@ -0,0 +1,43 @@
+package input
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSkillEchoNotice(t *testing.T) {
+	long := strings.Repeat("line\n", skillEchoLines+3)
+	tests := []struct {
+		name         string
+		instructions string
+		wantHeader   string
+		wantLines    int
+		wantMore     string
+	}{
+		{"single line", "Review the diff", "Skill prompt for /review (1 lines):", 1, ""},
+		{"trims surrounding blank lines", "\n\nfirst\nsecond\n\n", "Skill prompt for /review (2 lines):", 2, ""},
+		{"exactly at the cap", strings.Repeat("line\n", skillEchoLines), "(6 lines):", skillEchoLines, ""},
+		{"longer than the cap", long, "(9 lines):", skillEchoLines, "… 3 more lines"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SkillEchoNotice("/review", tt.instructions)
+			lines := strings.Split(got, "\n")
+			if !strings.Contains(lines[0], tt.wantHeader) {
+				t.Errorf("header = %q, want it to contain %q", lines[0], tt.wantHeader)
+			}
+			shown := lines[1:]
+			if tt.wantMore != "" {
+				if last := shown[len(shown)-1]; strings.TrimSpace(last) != tt.wantMore {
+					t.Errorf("last line = %q, want %q", last, tt.wantMore)
+				}
+				shown = shown[:len(shown)-1]
+			} else if strings.Contains(got, "more lines") {
+				t.Errorf("unexpected truncation marker in %q", got)
+			}
+			if len(shown) != tt.wantLines {
+				t.Errorf("shown %d prompt lines, want %d: %q", len(shown), tt.wantLines, got)
+			}
+		})
+	}
+}
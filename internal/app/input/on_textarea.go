@@ -352,6 +352,7 @@ func (m *Model) Reset() {
 	m.ClearImages()
 	m.Queue.SelectIdx = -1
 	m.Queue.Stashed = ""
+	m.Edit = EditState{}
 }
 
 func (m *Model) HandleCwdChange(newCwd string) {
@@ -361,14 +362,23 @@ func (m *Model) HandleCwdChange(newCwd string) {
 	}
 }
 
-func (m *Model) RecordSubmission(cwd, input string) {
+// RecordSubmission adds input to the history. The in-memory history keeps
+// entries as typed; redact, when set, is applied to what is written to disk.
+func (m *Model) RecordSubmission(cwd, input string, redact func(string) string) {
 	if input == "" {
 		return
 	}
 	m.History.Items = append(m.History.Items, input)
 	m.History.Index = -1
 	m.History.Stashed = ""
-	history.Save(cwd, m.History.Items)
+	saved := m.History.Items
+	if redact != nil {
+		saved = make([]string, len(m.History.Items))
+		for i, item := range m.History.Items {
+			saved[i] = redact(item)
+		}
+	}
+	history.Save(cwd, saved)
 }
 
 func (m *Model) RestoreImages(images []core.Image) {
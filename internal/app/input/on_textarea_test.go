@@ -1,8 +1,10 @@
 package input
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/yanmxa/gencode/internal/app/kit/history"
 	"github.com/yanmxa/gencode/internal/core"
 )
 
@@ -139,3 +141,26 @@ func TestRemoveImageToken(t *testing.T) {
 		t.Fatalf("unexpected cursor position after removal: %d", m.CursorIndex())
 	}
 }
+
+func TestRecordSubmissionRedactsSavedHistory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cwd := "/tmp/project"
+	redact := func(s string) string {
+		if strings.Contains(s, "hunter2") {
+			return "[redacted]"
+		}
+		return s
+	}
+
+	m := New("", 40, nil, SelectorDeps{})
+	m.RecordSubmission(cwd, "first question", redact)
+	m.RecordSubmission(cwd, "my password is hunter2", redact)
+
+	if got := m.History.Items[1]; got != "my password is hunter2" {
+		t.Errorf("in-memory history = %q, want the typed text", got)
+	}
+	saved := history.Load(cwd)
+	if len(saved) != 2 || saved[0] != "first question" || saved[1] != "[redacted]" {
+		t.Errorf("saved history = %q, want [first question [redacted]]", saved)
+	}
+}
@@ -0,0 +1,109 @@
+package input
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yanmxa/gencode/internal/app/kit"
+)
+
+// ThemePicker lists the built-in themes with a preview of their colors.
+// The chosen theme is saved to the user settings and applied at once.
+type ThemePicker struct {
+	active   bool
+	selected int
+	err      string
+	save     func(userLevel bool, key string, value any) error
+}
+
+func NewThemePicker(save func(userLevel bool, key string, value any) error) ThemePicker {
+	return ThemePicker{save: save}
+}
+
+// Enter opens the picker with the current theme selected.
+func (p *ThemePicker) Enter() {
+	p.active = true
+	p.selected = 0
+	p.err = ""
+	for i, t := range kit.Themes() {
+		if t.Name == kit.CurrentThemeName() {
+			p.selected = i
+		}
+	}
+}
+
+func (p *ThemePicker) IsActive() bool {
+	return p.active
+}
+
+func (p *ThemePicker) Cancel() {
+	p.active = false
+}
+
+// choose saves the named theme to the user settings. The returned command
+// reports the change, which applies the theme.
+func (p *ThemePicker) choose(name string) (tea.Cmd, error) {
+	if !slices.Contains(kit.ThemeNames(), name) {
+		return nil, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(kit.ThemeNames(), ", "))
+	}
+	if p.save != nil {
+		if err := p.save(true, "theme", name); err != nil {
+			return nil, fmt.Errorf("failed to save theme: %w", err)
+		}
+	}
+	return func() tea.Msg { return SettingChangedMsg{Key: "theme"} }, nil
+}
+
+func (p *ThemePicker) HandleKeypress(key tea.KeyMsg) tea.Cmd {
+	themes := kit.Themes()
+	switch key.Type {
+	case tea.KeyUp, tea.KeyCtrlP:
+		if p.selected > 0 {
+			p.selected--
+		}
+	case tea.KeyDown, tea.KeyCtrlN:
+		if p.selected < len(themes)-1 {
+			p.selected++
+		}
+	case tea.KeyEnter:
+		cmd, err := p.choose(themes[p.selected].Name)
+		if err != nil {
+			p.err = err.Error()
+			return nil
+		}
+		p.Cancel()
+		return cmd
+	case tea.KeyEsc:
+		p.Cancel()
+		return func() tea.Msg { return kit.DismissedMsg{} }
+	}
+	return nil
+}
+
+func (p *ThemePicker) Render() string {
+	if !p.active {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(kit.SelectorTitleStyle().Render("Select Theme") + "\n\n")
+	for i, t := range kit.Themes() {
+		style, indent := kit.SelectorItemStyle(), "  "
+		if i == p.selected {
+			style, indent = kit.SelectorSelectedStyle(), "> "
+		}
+		label := fmt.Sprintf("%s%-16s", indent, t.Label)
+		if t.Name == kit.CurrentThemeName() {
+			label = fmt.Sprintf("%s%-16s", indent, t.Label+" ✓")
+		}
+		sb.WriteString(style.Render(label) + " " + kit.ThemeSwatch(t) + "  " + kit.DimStyle().Render(t.Desc) + "\n")
+	}
+	if p.err != "" {
+		sb.WriteString("\n" + kit.SelectorStatusError().Render(p.err) + "\n")
+	}
+	sb.WriteString("\n" + kit.SelectorHintStyle().Render("Saved to ~/.gen/settings.json · Enter apply · Esc cancel"))
+	return sb.String()
+}
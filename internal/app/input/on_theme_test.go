@@ -0,0 +1,42 @@
+package input
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestThemeCommand(t *testing.T) {
+	files := fakeSettingsFiles{}
+	deps := CommandDeps{Input: &Model{Theme: NewThemePicker(files.save)}}
+	ctrl := NewCommandController(deps)
+
+	if _, _, err := ctrl.handleThemeCommand(context.Background(), "neon"); err == nil || !strings.Contains(err.Error(), "nord") {
+		t.Errorf("unknown theme err = %v, want the available themes listed", err)
+	}
+
+	result, cmd, err := ctrl.handleThemeCommand(context.Background(), "Nord")
+	if err != nil || cmd == nil || !strings.Contains(result, "nord") {
+		t.Fatalf("/theme Nord = %q, cmd=%v, err=%v", result, cmd != nil, err)
+	}
+	if files[true]["theme"] != "nord" {
+		t.Errorf("user theme = %v, want nord", files[true]["theme"])
+	}
+	if msg, ok := cmd().(SettingChangedMsg); !ok || msg.Key != "theme" {
+		t.Errorf("expected SettingChangedMsg for theme, got %#v", msg)
+	}
+
+	if _, _, err := ctrl.handleThemeCommand(context.Background(), ""); err != nil || !deps.Input.Theme.IsActive() {
+		t.Fatalf("/theme should open the picker, err=%v", err)
+	}
+	if view := deps.Input.Theme.Render(); !strings.Contains(view, "Solarized Light") {
+		t.Errorf("picker missing a theme:\n%s", view)
+	}
+	deps.Input.Theme.HandleKeypress(tea.KeyMsg{Type: tea.KeyDown})
+	cmd = deps.Input.Theme.HandleKeypress(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || deps.Input.Theme.IsActive() || files[true]["theme"] != "light" {
+		t.Errorf("Enter should save the selected theme and close, got theme %v", files[true]["theme"])
+	}
+}
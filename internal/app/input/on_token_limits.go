@@ -20,9 +20,12 @@ type TokenLimitDeps struct {
 	Provider     llm.Provider
 	Store        *llm.Store
 	InputTokens  int
-	Cwd          string
-	SpinnerTick  tea.Cmd
-	ToolSvc      tool.Service
+	// CompactThreshold is the autoCompactThreshold setting: a percent, 0 for
+	// the default, or setting.AutoCompactOff.
+	CompactThreshold int
+	Cwd              string
+	SpinnerTick      tea.Cmd
+	ToolSvc          tool.Service
 }
 
 // HandleTokenLimitCommand processes the /tokenlimit slash command.
@@ -41,10 +44,12 @@ func HandleTokenLimitCommand(deps TokenLimitDeps, args string) (string, tea.Cmd,
 	return showOrFetchTokenLimits(deps, modelID)
 }
 
+const tokenLimitUsage = "Usage:\n  /tokenlimit                    - Show or auto-fetch limits\n  /tokenlimit <input> <output>   - Set custom limits\n  /model set-limit <input> <output> - Set custom limits"
+
 func setTokenLimits(deps TokenLimitDeps, modelID, args string) (string, tea.Cmd, error) {
 	var inputLimit, outputLimit int
 	if _, err := fmt.Sscanf(args, "%d %d", &inputLimit, &outputLimit); err != nil {
-		return "Usage:\n  /tokenlimit              - Show or auto-fetch limits\n  /tokenlimit <input> <output> - Set custom limits", nil, nil
+		return tokenLimitUsage, nil, nil
 	}
 
 	if inputLimit <= 0 || outputLimit <= 0 {
@@ -57,20 +62,15 @@ func setTokenLimits(deps TokenLimitDeps, modelID, args string) (string, tea.Cmd,
 		}
 	}
 
-	return fmt.Sprintf("Set token limits for %s:\n  Input:  %s tokens\n  Output: %s tokens",
-		modelID, kit.FormatTokenCount(inputLimit), kit.FormatTokenCount(outputLimit)), nil, nil
+	return fmt.Sprintf("Set token limits for %s:\n  Input:  %s tokens\n  Output: %s tokens\n\nSource: %s",
+		modelID, kit.FormatTokenCount(inputLimit), kit.FormatTokenCount(outputLimit),
+		kit.TokenLimitSourceLabel(llm.TokenLimitSourceCustom)), nil, nil
 }
 
 func showOrFetchTokenLimits(deps TokenLimitDeps, modelID string) (string, tea.Cmd, error) {
-	if deps.Store != nil {
-		if customInput, customOutput, ok := deps.Store.GetTokenLimit(modelID); ok {
-			return formatTokenLimitDisplay(modelID, customInput, customOutput, true, deps.InputTokens), nil, nil
-		}
-	}
-
-	inputLimit, outputLimit := kit.GetModelTokenLimits(deps.Store, deps.CurrentModel)
-	if inputLimit > 0 || outputLimit > 0 {
-		return formatTokenLimitDisplay(modelID, inputLimit, outputLimit, false, deps.InputTokens), nil, nil
+	inputLimit, outputLimit, source := kit.GetEffectiveTokenLimits(deps.Store, deps.CurrentModel)
+	if source != llm.TokenLimitSourceNone {
+		return formatTokenLimitDisplay(modelID, inputLimit, outputLimit, source, deps.InputTokens, deps.CompactThreshold), nil, nil
 	}
 
 	return "", tea.Batch(deps.SpinnerTick, fetchTokenLimitsCmd(deps)), nil
@@ -102,7 +102,7 @@ type autoFetchTokenLimitsDeps struct {
 
 func autoFetchTokenLimits(ctx context.Context, deps autoFetchTokenLimitsDeps) (string, error) {
 	if deps.LLM == nil {
-		return "No provider connected. Use /tokenlimit <input> <output> to set manually.", nil
+		return "No provider connected. Use /model set-limit <input> <output> to set manually.", nil
 	}
 
 	modelID := deps.CurrentModel.ModelID
@@ -112,9 +112,9 @@ func autoFetchTokenLimits(ctx context.Context, deps autoFetchTokenLimitsDeps) (s
 		inputLimit, outputLimit, err := fetcher.FetchModelLimits(ctx, modelID)
 		if err == nil && (inputLimit > 0 || outputLimit > 0) {
 			if deps.Store != nil {
-				_ = deps.Store.SetTokenLimit(modelID, inputLimit, outputLimit)
+				_ = deps.Store.SetTokenLimitWithSource(modelID, inputLimit, outputLimit, llm.TokenLimitSourceFetched)
 			}
-			return formatTokenLimitDisplay(modelID, inputLimit, outputLimit, false, 0), nil
+			return formatFetchedTokenLimits(modelID, inputLimit, outputLimit, "provider API"), nil
 		}
 	}
 
@@ -224,7 +224,7 @@ func appendToolCallMessages(ctx context.Context, messages []core.Message, toolCa
 }
 
 func tokenLimitNotFoundMessage(modelID string) string {
-	return fmt.Sprintf("Could not find token limits for %s.\n\nSet manually with: /tokenlimit <input> <output>", modelID)
+	return fmt.Sprintf("Auto-fetch could not find token limits for %s.\n\nSet manually with: /model set-limit <input> <output>", modelID)
 }
 
 func parseTokenLimitResponse(content, modelID string, store *llm.Store) (string, bool) {
@@ -232,10 +232,9 @@ func parseTokenLimitResponse(content, modelID string, store *llm.Store) (string,
 		var inputLimit, outputLimit int
 		if _, err := fmt.Sscanf(content, "FOUND: %d %d", &inputLimit, &outputLimit); err == nil && inputLimit > 0 {
 			if store != nil {
-				_ = store.SetTokenLimit(modelID, inputLimit, outputLimit)
+				_ = store.SetTokenLimitWithSource(modelID, inputLimit, outputLimit, llm.TokenLimitSourceFetched)
 			}
-			return fmt.Sprintf("Found and saved token limits for %s:\n  Input:  %s tokens\n  Output: %s tokens",
-				modelID, formatTokenCount(inputLimit), formatTokenCount(outputLimit)), true
+			return formatFetchedTokenLimits(modelID, inputLimit, outputLimit, "web search"), true
 		}
 	}
 
@@ -246,12 +245,24 @@ func parseTokenLimitResponse(content, modelID string, store *llm.Store) (string,
 	return "", false
 }
 
-func formatTokenLimitDisplay(modelID string, inputLimit, outputLimit int, isCustom bool, currentInputTokens int) string {
-	result := fmt.Sprintf("Token Limits for %s:\n\n  Input:  %s tokens\n  Output: %s tokens",
-		modelID, formatTokenCount(inputLimit), formatTokenCount(outputLimit))
+// formatFetchedTokenLimits labels limits found by the auto-fetch agent so
+// they are not mistaken for provider-reported or user-set values.
+func formatFetchedTokenLimits(modelID string, inputLimit, outputLimit int, via string) string {
+	return fmt.Sprintf("Auto-fetched token limits for %s (via %s):\n  Input:  %s tokens\n  Output: %s tokens\n\nSaved as the active limits. Override with /model set-limit <input> <output>.",
+		modelID, via, formatTokenCount(inputLimit), formatTokenCount(outputLimit))
+}
 
-	if isCustom {
-		result += "\n\n(custom override)"
+func formatTokenLimitDisplay(modelID string, inputLimit, outputLimit int, source llm.TokenLimitSource, currentInputTokens, compactThreshold int) string {
+	result := fmt.Sprintf("Token Limits for %s:\n\n  Input:  %s tokens\n  Output: %s tokens\n\nSource: %s",
+		modelID, formatTokenCount(inputLimit), formatTokenCount(outputLimit), kit.TokenLimitSourceLabel(source))
+
+	if compactThreshold < 0 {
+		result += "\nAuto-compact: off"
+	} else if inputLimit > 0 {
+		if compactThreshold == 0 {
+			compactThreshold = core.DefaultCompactThreshold
+		}
+		result += fmt.Sprintf("\nAuto-compact: at %d%% (%s tokens)", compactThreshold, formatTokenCount(inputLimit*compactThreshold/100))
 	}
 
 	if currentInputTokens > 0 && inputLimit > 0 {
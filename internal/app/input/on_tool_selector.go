@@ -15,6 +15,7 @@ import (
 type toolItem struct {
 	Name        string
 	Description string
+	Source      string // attribution for plugin-provided tools, e.g. "plugin:jira"
 	Enabled     bool
 }
 
@@ -61,6 +62,7 @@ func (s *ToolSelector) EnterSelect(width, height int, disabledTools map[string]b
 		s.tools = append(s.tools, toolItem{
 			Name:        t.Name,
 			Description: t.Description,
+			Source:      coretool.ToolSource(t.Name),
 			Enabled:     !disabledTools[t.Name],
 		})
 	}
@@ -98,7 +100,8 @@ func (s *ToolSelector) updateFilter() {
 		s.filteredTools = make([]toolItem, 0)
 		for _, t := range s.tools {
 			if kit.FuzzyMatch(strings.ToLower(t.Name), query) ||
-				kit.FuzzyMatch(strings.ToLower(t.Description), query) {
+				kit.FuzzyMatch(strings.ToLower(t.Description), query) ||
+				kit.FuzzyMatch(strings.ToLower(t.Source), query) {
 				s.filteredTools = append(s.filteredTools, t)
 			}
 		}
@@ -241,6 +244,9 @@ func (s *ToolSelector) Render() string {
 			if idx := strings.Index(desc, "\n"); idx != -1 {
 				desc = desc[:idx]
 			}
+			if t.Source != "" {
+				desc = "[" + t.Source + "] " + desc
+			}
 			if len(desc) > maxDescLen {
 				desc = desc[:maxDescLen-3] + "..."
 			}
@@ -0,0 +1,65 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/yanmxa/gencode/internal/undo"
+)
+
+const undoUsage = `Usage: /undo [--force]
+
+Reverts the most recent file change made by Edit, Write, or MultiEdit. Run it
+again to step further back. A file the tool created is deleted.
+  --force  revert even if the file was changed after the tool modified it`
+
+// HandleUndoCommand reverts the most recent snapshot on stack and describes
+// what was restored.
+func HandleUndoCommand(stack *undo.Stack, cwd, args string) string {
+	var force bool
+	switch strings.TrimSpace(args) {
+	case "":
+	case "--force", "-f":
+		force = true
+	default:
+		return undoUsage
+	}
+	if stack == nil {
+		return "Nothing to undo."
+	}
+
+	snap, err := stack.Undo(force)
+	path := undoDisplayPath(snap.Path, cwd)
+	switch {
+	case errors.Is(err, undo.ErrEmpty):
+		return "Nothing to undo."
+	case errors.Is(err, undo.ErrModified):
+		return fmt.Sprintf("%s was changed after %s modified it. Run /undo --force to revert it anyway.", path, snap.Tool)
+	case err != nil:
+		return "Undo failed: " + err.Error()
+	}
+
+	var result string
+	if snap.Created {
+		result = fmt.Sprintf("Deleted %s (created by %s).", path, snap.Tool)
+	} else {
+		result = fmt.Sprintf("Reverted %s change to %s.", snap.Tool, path)
+	}
+	if n := stack.Len(); n > 0 {
+		result += fmt.Sprintf(" %d more change(s) can be undone.", n)
+	}
+	return result
+}
+
+func undoDisplayPath(path, cwd string) string {
+	if cwd == "" {
+		return path
+	}
+	rel, err := filepath.Rel(cwd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
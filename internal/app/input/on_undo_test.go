@@ -0,0 +1,37 @@
+package input
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/undo"
+)
+
+func TestHandleUndoCommand(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(path, []byte("edited"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stack := undo.New(0)
+	stack.Push(undo.Snapshot{Path: path, Tool: "Write", Created: true, After: "first"})
+	stack.Push(undo.Snapshot{Path: path, Tool: "Edit", Before: "first", After: "second"})
+
+	if got := HandleUndoCommand(stack, dir, ""); !strings.HasPrefix(got, "a.txt was changed after Edit modified it") {
+		t.Errorf("changed file = %q", got)
+	}
+	if got := HandleUndoCommand(stack, dir, "--force"); got != "Reverted Edit change to a.txt. 1 more change(s) can be undone." {
+		t.Errorf("forced undo = %q", got)
+	}
+	if got := HandleUndoCommand(stack, dir, ""); got != "Deleted a.txt (created by Write)." {
+		t.Errorf("undo create = %q", got)
+	}
+	if got := HandleUndoCommand(stack, dir, ""); got != "Nothing to undo." {
+		t.Errorf("empty stack = %q", got)
+	}
+	if got := HandleUndoCommand(stack, dir, "--bogus"); !strings.HasPrefix(got, "Usage: /undo") {
+		t.Errorf("unknown flag = %q", got)
+	}
+}
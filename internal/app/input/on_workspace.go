@@ -0,0 +1,60 @@
+package input
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// WorkspaceSelectMsg asks the app to switch to the named workspace.
+type WorkspaceSelectMsg struct {
+	Name string
+}
+
+func (c *CommandController) handleWorkspaceCommand(_ context.Context, args string) (string, tea.Cmd, error) {
+	name := strings.TrimSpace(args)
+	if name != "" {
+		return "", func() tea.Msg { return WorkspaceSelectMsg{Name: name} }, nil
+	}
+
+	if len(c.deps.Workspaces) == 0 {
+		return "No workspaces defined. Add a \"workspaces\" map to settings.json, then switch with /workspace <name>.", nil, nil
+	}
+	names := make([]string, 0, len(c.deps.Workspaces))
+	for n := range c.deps.Workspaces {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString("Workspaces:")
+	for _, n := range names {
+		ws := c.deps.Workspaces[n]
+		marker := "  "
+		if n == c.deps.ActiveWorkspace {
+			marker = "* "
+		}
+		var parts []string
+		if ws.Provider != "" || ws.Model != "" {
+			parts = append(parts, strings.Trim(ws.Provider+"/"+ws.Model, "/"))
+		}
+		if len(ws.Tools) > 0 {
+			parts = append(parts, fmt.Sprintf("%d tools", len(ws.Tools)))
+		}
+		if ws.MCPServers != nil {
+			parts = append(parts, fmt.Sprintf("%d MCP servers", len(ws.MCPServers)))
+		}
+		if ws.Mode != "" {
+			parts = append(parts, "mode "+ws.Mode)
+		}
+		fmt.Fprintf(&sb, "\n%s%s", marker, n)
+		if len(parts) > 0 {
+			sb.WriteString(" — " + strings.Join(parts, ", "))
+		}
+	}
+	sb.WriteString("\nSwitch with /workspace <name>.")
+	return sb.String(), nil, nil
+}
@@ -23,4 +23,5 @@ type OverlayDeps struct {
 	FireFileChanged         func(path, tool string)
 	ReloadPluginState       func() error
 	LoadSession             func(string) error
+	SaveSettingAt           func(userLevel bool, key string, value any) error
 }
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 
 	"github.com/yanmxa/gencode/internal/app/conv"
 	"github.com/yanmxa/gencode/internal/app/kit"
+	"github.com/yanmxa/gencode/internal/changelog"
 	"github.com/yanmxa/gencode/internal/command"
 	"github.com/yanmxa/gencode/internal/core"
 	"github.com/yanmxa/gencode/internal/cron"
@@ -20,9 +22,13 @@ import (
 	"github.com/yanmxa/gencode/internal/mcp"
 	"github.com/yanmxa/gencode/internal/plugin"
 	"github.com/yanmxa/gencode/internal/session"
+	"github.com/yanmxa/gencode/internal/setting"
 	"github.com/yanmxa/gencode/internal/skill"
+	"github.com/yanmxa/gencode/internal/task"
 	"github.com/yanmxa/gencode/internal/task/tracker"
 	"github.com/yanmxa/gencode/internal/tool"
+	"github.com/yanmxa/gencode/internal/tool/scratchpad"
+	"github.com/yanmxa/gencode/internal/undo"
 )
 
 type commandHandler func(*CommandController, context.Context, string) (string, tea.Cmd, error)
@@ -36,11 +42,22 @@ type CommandDeps struct {
 	Cwd          string
 
 	// Read-only state
-	DisabledTools map[string]bool
-	ProviderStore *llm.Store
-	LLMProvider   llm.Provider
-	InputTokens   int
-	CurrentModel  *llm.CurrentModelInfo
+	DisabledTools    map[string]bool
+	ProviderStore    *llm.Store
+	LLMProvider      llm.Provider
+	InputTokens      int
+	CurrentModel     *llm.CurrentModelInfo
+	ConfirmClear     bool
+	ModelSort        string
+	CompactThreshold int // autoCompactThreshold setting, see setting.Service
+
+	SessionPermissions *setting.SessionPermissions
+	Workspaces         map[string]setting.Workspace
+	ActiveWorkspace    string
+	WrapWidth          int
+	Changes            *changelog.Log
+	Undo               *undo.Stack
+	Spend              *llm.SpendLedger
 
 	// Domain services
 	Skill   skill.Service
@@ -50,17 +67,21 @@ type CommandDeps struct {
 	Cron    cron.Service
 	ToolSvc tool.Service
 	Command command.Service
+	Task    task.Service
 
 	// State getters (values that may change during command execution)
 	GetSessionID      func() string
+	GetSessionTitle   func() string
 	GetSessionStore   func() *session.Store
 	GetThinkingEffort func() string
+	IsSessionSaved    func() bool
 
 	// Mutation callbacks
 	ResetTokens        func()
 	SetThinkingEffort  func(string)
 	EnsureSessionStore func(cwd string) error
 	ForkSession        func() (originalSessionID string, err error)
+	SetSessionTitle    func(title string)
 	ResetFetched       func()
 
 	// Existing callbacks
@@ -73,6 +94,7 @@ type CommandDeps struct {
 	InitTaskStorage         func()
 	ReconfigureAgentTool    func()
 	StopAgentSession        func()
+	SyncAgentMessages       func() // push the conversation to the running agent
 	FireSessionEnd          func(reason string)
 	BuildCompactRequest     func(focus, trigger string) conv.CompactRequest
 	SpinnerTickCmd          func() tea.Cmd
@@ -92,10 +114,17 @@ func builtinCommandHandlers() map[string]commandHandler {
 		"model":          (*CommandController).handleModelCommand,
 		"clear":          (*CommandController).handleClearCommand,
 		"fork":           (*CommandController).handleForkCommand,
+		"branch":         (*CommandController).handleBranchCommand,
 		"resume":         (*CommandController).handleResumeCommand,
+		"rename":         (*CommandController).handleRenameCommand,
+		"name":           (*CommandController).handleRenameCommand,
+		"fallback":       (*CommandController).handleFallbackCommand,
 		"help":           (*CommandController).handleHelpCommand,
 		"glob":           (*CommandController).handleGlobCommand,
+		"grep":           (*CommandController).handleGrepCommand,
 		"tools":          (*CommandController).handleToolCommand,
+		"settings":       (*CommandController).handleSettingsCommand,
+		"theme":          (*CommandController).handleThemeCommand,
 		"skills":         (*CommandController).handleSkillCommand,
 		"agents":         (*CommandController).handleAgentCommand,
 		"tokenlimit":     (*CommandController).handleTokenLimitCommand,
@@ -108,6 +137,19 @@ func builtinCommandHandlers() map[string]commandHandler {
 		"think":          (*CommandController).handleThinkCommand,
 		"loop":           (*CommandController).handleLoopCommand,
 		"search":         (*CommandController).handleSearchCommand,
+		"note":           (*CommandController).handleNoteCommand,
+		"approve":        (*CommandController).handleApproveCommand,
+		"workspace":      (*CommandController).handleWorkspaceCommand,
+		"width":          (*CommandController).handleWidthCommand,
+		"changes":        (*CommandController).handleChangesCommand,
+		"diff":           (*CommandController).handleDiffCommand,
+		"undo":           (*CommandController).handleUndoCommand,
+		"retry":          (*CommandController).handleRetryCommand,
+		"edit":           (*CommandController).handleEditCommand,
+		"export":         (*CommandController).handleExportCommand,
+		"image":          (*CommandController).handleImageCommand,
+		"cost":           (*CommandController).handleCostCommand,
+		"jobs":           (*CommandController).handleJobsCommand,
 	}
 }
 
@@ -130,7 +172,14 @@ func (c CommandController) Execute(ctx context.Context, inputText string) (strin
 	}
 
 	if pc, ok := c.deps.Command.IsCustomCommand(cmdName); ok {
-		return c.executeCustomCommand(pc, args), c.deps.HandleSkillInvocation(), true
+		return "", c.executeCustomCommand(ctx, pc, args), true
+	}
+
+	if reg := mcpRegistry(c.deps.MCP); reg != nil {
+		if pc, ok := reg.FindPromptCommand(cmdName); ok {
+			result, followUp := c.executeMCPPromptCommand(ctx, reg, pc, args)
+			return result, followUp, true
+		}
 	}
 
 	return unknownCommandResult(cmdName), nil, true
@@ -215,20 +264,71 @@ func ApplySkillInvocation(state *Model, sk *skill.Skill, args string, skillSvc s
 	}
 }
 
-func (c CommandController) executeCustomCommand(pc *command.CustomCommand, args string) string {
-	instructions := pc.GetInstructions()
-	if instructions != "" {
-		c.deps.Input.Skill.PendingInstructions = fmt.Sprintf("<custom-command name=%q>\n%s\n</custom-command>", pc.FullName(), instructions)
+// CustomCommandExpandedMsg carries a custom command's expanded template
+// back to the UI once its !`command` references have run.
+type CustomCommandExpandedMsg struct {
+	Command      *command.CustomCommand
+	Args         string
+	Instructions string
+}
+
+// executeCustomCommand expands the command's template in the background and
+// reports it as a CustomCommandExpandedMsg. Permission checks happen here,
+// on the UI goroutine, so the expansion does not read session state.
+func (c CommandController) executeCustomCommand(ctx context.Context, pc *command.CustomCommand, args string) tea.Cmd {
+	body := pc.GetInstructions()
+	if body == "" {
+		return c.CompleteCustomCommand(CustomCommandExpandedMsg{Command: pc, Args: args})
+	}
+	skip := make(map[string]string)
+	for _, cmd := range command.TemplateCommands(body, args) {
+		skip[cmd] = c.bashSkipReason(cmd)
+	}
+	opts := command.TemplateOptions{
+		Args: args,
+		CWD:  c.deps.Cwd,
+		Skip: func(cmd string) string { return skip[cmd] },
+	}
+	return func() tea.Msg {
+		return CustomCommandExpandedMsg{Command: pc, Args: args, Instructions: command.ExpandTemplate(ctx, body, opts)}
+	}
+}
+
+// CompleteCustomCommand queues an expanded custom command to be sent like a
+// skill invocation.
+func (c CommandController) CompleteCustomCommand(msg CustomCommandExpandedMsg) tea.Cmd {
+	pc := msg.Command
+	if msg.Instructions != "" {
+		c.deps.Input.Skill.PendingInstructions = fmt.Sprintf("<custom-command name=%q>\n%s\n</custom-command>", pc.FullName(), msg.Instructions)
 	}
 	if c.deps.Plugin != nil {
 		c.deps.Plugin.SetActivePluginRoot(c.deps.Plugin.FindPluginRootForPath(pc.FilePath))
 	}
-	if args != "" {
-		c.deps.Input.Skill.PendingArgs = fmt.Sprintf("/%s %s", pc.FullName(), args)
+	if msg.Args != "" {
+		c.deps.Input.Skill.PendingArgs = fmt.Sprintf("/%s %s", pc.FullName(), msg.Args)
 	} else {
 		c.deps.Input.Skill.PendingArgs = fmt.Sprintf("/%s", pc.FullName())
 	}
-	return ""
+	return c.deps.HandleSkillInvocation()
+}
+
+// bashSkipReason returns why a template may not run command with the Bash
+// tool, or "" when the permission rules allow it. Nobody is asked, so a
+// command that would need approval is skipped too. The check does not
+// spend the /approve budget.
+func (c CommandController) bashSkipReason(command string) string {
+	svc := setting.DefaultIfInit()
+	if svc == nil {
+		return ""
+	}
+	switch svc.PreviewPermission("Bash", map[string]any{"command": command}, c.deps.SessionPermissions).Behavior {
+	case setting.Allow:
+		return ""
+	case setting.Deny:
+		return "denied by permission rules"
+	default:
+		return "needs approval; add a Bash allow rule to run it from a command"
+	}
 }
 
 func (c *CommandController) handleHelpCommand(_ context.Context, _ string) (string, tea.Cmd, error) {
@@ -255,10 +355,46 @@ func (c *CommandController) handleHelpCommand(_ context.Context, _ string) (stri
 			fmt.Fprintf(&sb, "  /%s - %s\n", cmd.Name, desc)
 		}
 	}
+	if reg := mcpRegistry(c.deps.MCP); reg != nil {
+		if prompts := reg.PromptCommands(); len(prompts) > 0 {
+			sb.WriteString("\nMCP Prompts:\n\n")
+			for _, pc := range prompts {
+				usage := pc.Name()
+				if hint := pc.ArgumentHint(); hint != "" {
+					usage += " " + hint
+				}
+				desc := pc.Prompt.Description
+				if desc == "" {
+					desc = "(no description)"
+				}
+				fmt.Fprintf(&sb, "  /%s - %s\n", usage, desc)
+			}
+		}
+	}
 	return sb.String(), nil, nil
 }
 
-func (c *CommandController) handleClearCommand(_ context.Context, _ string) (string, tea.Cmd, error) {
+func (c *CommandController) handleClearCommand(_ context.Context, args string) (string, tea.Cmd, error) {
+	force := strings.TrimSpace(args) == "force"
+	if !force && c.needsClearConfirmation() {
+		c.deps.Input.Clear.Enter(len(c.deps.Conversation.Messages), c.deps.Width, c.deps.Height)
+		return "", nil, nil
+	}
+	return "", c.ClearConversation(), nil
+}
+
+// needsClearConfirmation reports whether /clear would discard a non-trivial
+// conversation that has not been persisted.
+func (c *CommandController) needsClearConfirmation() bool {
+	if !c.deps.ConfirmClear || len(c.deps.Conversation.Messages) <= clearConfirmThreshold {
+		return false
+	}
+	return c.deps.IsSessionSaved == nil || !c.deps.IsSessionSaved()
+}
+
+// ClearConversation resets the conversation, tool state, tokens, task list,
+// scratchpad, and file change log, and kills background Bash jobs.
+func (c CommandController) ClearConversation() tea.Cmd {
 	c.deps.StopAgentSession()
 	c.deps.Conversation.Stream.Stop()
 	if c.deps.Tool.Cancel != nil {
@@ -267,11 +403,24 @@ func (c *CommandController) handleClearCommand(_ context.Context, _ string) (str
 	c.deps.Tool.Reset()
 	c.deps.Conversation.Clear()
 	c.deps.ResetTokens()
+	if c.deps.Spend != nil {
+		c.deps.Spend.Reset()
+	}
 	c.deps.Tracker.Reset()
+	scratchpad.Reset(scratchpad.Main)
+	if c.deps.Changes != nil {
+		c.deps.Changes.Reset()
+	}
+	if c.deps.Undo != nil {
+		c.deps.Undo.Reset()
+	}
 	if c.deps.ResetFetched != nil {
 		c.deps.ResetFetched()
 	}
 	c.deps.ResetCronQueue()
+	if c.deps.Task != nil {
+		c.deps.Task.Clear(task.TaskTypeBash)
+	}
 	cmds := []tea.Cmd{tea.ClearScreen}
 	if os.Getenv("TMUX") != "" {
 		cmds = append(cmds, func() tea.Msg {
@@ -279,11 +428,7 @@ func (c *CommandController) handleClearCommand(_ context.Context, _ string) (str
 			return nil
 		})
 	}
-	return "", tea.Batch(cmds...), nil
-}
-
-func (c CommandController) HandleClearForTests(ctx context.Context, args string) (string, tea.Cmd, error) {
-	return c.handleClearCommand(ctx, args)
+	return tea.Batch(cmds...)
 }
 
 func (c *CommandController) handleForkCommand(_ context.Context, _ string) (string, tea.Cmd, error) {
@@ -305,6 +450,13 @@ func (c *CommandController) handleForkCommand(_ context.Context, _ string) (stri
 	return fmt.Sprintf("Forked conversation. You are now in the fork.\nTo resume the original: gen -r %s", originalID), nil, nil
 }
 
+func (c *CommandController) handleBranchCommand(_ context.Context, args string) (string, tea.Cmd, error) {
+	if err := c.deps.Input.Branch.Enter(c.deps.Conversation.Messages, strings.TrimSpace(args), c.deps.Width, c.deps.Height); err != nil {
+		return "Nothing to branch — no prompts in current session.", nil, nil
+	}
+	return "", nil, nil
+}
+
 func (c *CommandController) handleResumeCommand(_ context.Context, _ string) (string, tea.Cmd, error) {
 	if err := c.deps.EnsureSessionStore(c.deps.Cwd); err != nil {
 		return "", nil, fmt.Errorf("failed to initialize session store: %w", err)
@@ -315,14 +467,149 @@ func (c *CommandController) handleResumeCommand(_ context.Context, _ string) (st
 	return "", nil, nil
 }
 
-func (c *CommandController) handleSearchCommand(_ context.Context, _ string) (string, tea.Cmd, error) {
+func (c *CommandController) handleRenameCommand(_ context.Context, args string) (string, tea.Cmd, error) {
+	title := strings.Join(strings.Fields(args), " ")
+	if title == "" {
+		usage := "Usage: /name <title> (or /rename <title>)\n\nNames the current session. The name is shown in /resume instead of the first message."
+		if c.deps.GetSessionTitle != nil {
+			if current := c.deps.GetSessionTitle(); current != "" {
+				usage = fmt.Sprintf("Current session name: %q\n\n%s", current, usage)
+			}
+		}
+		return usage, nil, nil
+	}
+	c.deps.SetSessionTitle(title)
+	if len(c.deps.Conversation.Messages) > 0 {
+		if err := c.deps.PersistSession(); err != nil {
+			return "", nil, fmt.Errorf("failed to save session: %w", err)
+		}
+	}
+	return fmt.Sprintf("Session renamed to %q.", title), nil, nil
+}
+
+// handleSearchCommand searches the conversation for args, or opens the web
+// search engine selector when there are none.
+func (c *CommandController) handleSearchCommand(_ context.Context, args string) (string, tea.Cmd, error) {
+	if query := strings.TrimSpace(args); query != "" {
+		c.deps.Input.Find.Enter(c.deps.Conversation.Messages, query, c.deps.Width, c.deps.Height)
+		return "", nil, nil
+	}
 	if err := c.deps.Input.Search.Enter(c.deps.ProviderStore, c.deps.Width, c.deps.Height); err != nil {
 		return "", nil, err
 	}
 	return "", nil, nil
 }
 
-func (c *CommandController) handleModelCommand(ctx context.Context, _ string) (string, tea.Cmd, error) {
+// handleJobsCommand opens the viewer of background Bash commands, which
+// refreshes itself while open.
+func (c *CommandController) handleJobsCommand(_ context.Context, _ string) (string, tea.Cmd, error) {
+	if c.deps.Task == nil {
+		return "Background jobs are not available in this session.", nil, nil
+	}
+	c.deps.Input.Jobs.Enter(c.deps.Task, c.deps.Width, c.deps.Height)
+	return "", c.deps.Input.Jobs.Tick(), nil
+}
+
+func (c *CommandController) handleNoteCommand(_ context.Context, args string) (string, tea.Cmd, error) {
+	note := strings.TrimSpace(args)
+	if note == "" {
+		return "Usage: /note <text>\n\nAdds a note that is kept in the conversation and sent to the model.", nil, nil
+	}
+	c.deps.Conversation.AddContextNote(note)
+	if c.deps.SyncAgentMessages != nil {
+		c.deps.SyncAgentMessages()
+	}
+	return "", nil, nil
+}
+
+func (c *CommandController) handleApproveCommand(_ context.Context, args string) (string, tea.Cmd, error) {
+	perms := c.deps.SessionPermissions
+	if perms == nil {
+		return "Permissions are not available in this session.", nil, nil
+	}
+
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		grants := perms.PreApproved.Remaining()
+		if len(grants) == 0 {
+			return "No pre-approved tool calls. Usage: /approve <N> [tool] · /approve off", nil, nil
+		}
+		var sb strings.Builder
+		sb.WriteString("Pre-approved tool calls:")
+		for _, g := range grants {
+			class := g.Class
+			if class == setting.AnyToolClass {
+				class = "any tool"
+			}
+			fmt.Fprintf(&sb, "\n  %s: %d", class, g.Count)
+		}
+		return sb.String(), nil, nil
+	}
+
+	if fields[0] == "off" || fields[0] == "clear" {
+		perms.PreApproved.Clear()
+		return "Pre-approvals cleared; tool calls will prompt again.", nil, nil
+	}
+
+	n, err := strconv.Atoi(fields[0])
+	if err != nil || n <= 0 {
+		return "Usage: /approve <N> [tool] — N must be a positive number.", nil, nil
+	}
+	class := setting.AnyToolClass
+	label := "tool calls"
+	if len(fields) > 1 {
+		class = setting.ToolClass(fields[1])
+		label = class + " calls"
+	}
+	perms.PreApproved.Grant(class, n)
+	return fmt.Sprintf("Auto-approving the next %d %s, then prompting resumes. Safety checks and deny rules still apply.", n, label), nil, nil
+}
+
+// WrapWidthMsg asks the app to change the output wrap width (0 = terminal width).
+type WrapWidthMsg struct {
+	Width int
+}
+
+func (c *CommandController) handleWidthCommand(_ context.Context, args string) (string, tea.Cmd, error) {
+	arg := strings.TrimSpace(args)
+	switch arg {
+	case "":
+		if c.deps.WrapWidth == 0 {
+			return fmt.Sprintf("Wrap width follows the terminal (%d columns). Usage: /width <n> · /width auto", c.deps.Width), nil, nil
+		}
+		return fmt.Sprintf("Wrap width: %d columns (terminal %d). Usage: /width <n> · /width auto", c.deps.WrapWidth, c.deps.Width), nil, nil
+	case "auto", "off", "reset":
+		return "", func() tea.Msg { return WrapWidthMsg{} }, nil
+	}
+
+	width, err := strconv.Atoi(arg)
+	if err != nil || width == 0 {
+		return "Usage: /width <n> · /width auto", nil, nil
+	}
+	if err := setting.ValidateWrapWidth(width); err != nil {
+		return "Invalid width: " + err.Error() + ".", nil, nil
+	}
+	return "", func() tea.Msg { return WrapWidthMsg{Width: width} }, nil
+}
+
+func (c *CommandController) handleModelCommand(ctx context.Context, args string) (string, tea.Cmd, error) {
+	switch sub, rest, _ := strings.Cut(strings.TrimSpace(args), " "); sub {
+	case "set-limit":
+		if strings.TrimSpace(rest) == "" {
+			return tokenLimitUsage, nil, nil
+		}
+		return c.handleTokenLimitCommand(ctx, rest)
+	case "fallback-chain":
+		result, err := HandleFallbackChainCommand(c.deps.ProviderStore, rest)
+		return result, nil, err
+	case "refresh":
+		if c.deps.ProviderStore == nil {
+			return "", nil, fmt.Errorf("provider store not initialized")
+		}
+		c.deps.Input.Provider.RefreshingModels = true
+		return "", tea.Batch(c.deps.SpinnerTickCmd(), refreshModelsCmd(c.deps.ProviderStore)), nil
+	}
+	c.deps.Input.Provider.Selector.SetModelSort(c.deps.ModelSort)
 	cmd, err := c.deps.Input.Provider.Selector.Enter(ctx, c.deps.Width, c.deps.Height)
 	if err != nil {
 		return "", nil, err
@@ -330,6 +617,58 @@ func (c *CommandController) handleModelCommand(ctx context.Context, _ string) (s
 	return "", cmd, nil
 }
 
+func (c *CommandController) handleFallbackCommand(_ context.Context, args string) (string, tea.Cmd, error) {
+	result, err := HandleFallbackChainCommand(c.deps.ProviderStore, args)
+	return result, nil, err
+}
+
+func (c *CommandController) handleChangesCommand(_ context.Context, _ string) (string, tea.Cmd, error) {
+	if c.deps.Changes == nil {
+		return "No files changed this session.", nil, nil
+	}
+	summary := changelog.Format(c.deps.Changes.Files(), c.deps.Cwd)
+	if summary == "" {
+		return "No files changed this session.", nil, nil
+	}
+	return summary, nil, nil
+}
+
+func (c *CommandController) handleUndoCommand(_ context.Context, args string) (string, tea.Cmd, error) {
+	return HandleUndoCommand(c.deps.Undo, c.deps.Cwd, args), nil, nil
+}
+
+func (c *CommandController) handleDiffCommand(ctx context.Context, args string) (string, tea.Cmd, error) {
+	out, err := HandleDiffCommand(ctx, c.deps.Cwd, args)
+	if err != nil {
+		return "", nil, err
+	}
+	if out.Note != "" {
+		c.deps.Conversation.AddContextNote(out.Note)
+	}
+	if len(out.Files) == 0 {
+		return out.Message, nil, nil
+	}
+	c.deps.Conversation.AddNotice(out.Message)
+	for _, f := range out.Files {
+		c.deps.Conversation.AddDiffNotice(f.Label(), f.Diff)
+	}
+	return "", nil, nil
+}
+
+func (c *CommandController) handleExportCommand(_ context.Context, args string) (string, tea.Cmd, error) {
+	var msgs []core.ChatMessage
+	if c.deps.Conversation != nil {
+		msgs = c.deps.Conversation.Messages
+	}
+	result, err := HandleExportCommand(msgs, c.deps.Cwd, args, time.Now())
+	return result, nil, err
+}
+
+func (c *CommandController) handleCostCommand(_ context.Context, args string) (string, tea.Cmd, error) {
+	result, err := HandleCostCommand(c.deps.Spend, c.deps.ProviderStore, c.deps.CurrentModel, args)
+	return result, nil, err
+}
+
 func (c *CommandController) handleInitCommand(_ context.Context, args string) (string, tea.Cmd, error) {
 	result, err := HandleInitCommand(c.deps.Cwd, args)
 	return result, nil, err
@@ -358,6 +697,9 @@ func (c *CommandController) handleMCPCommand(ctx context.Context, args string) (
 		c.deps.Input.MCP.EditingScope = editInfo.Scope
 		return result, StartMCPEditor(editInfo.TempFile), nil
 	}
+	if cmd := c.deps.Input.MCP.Selector.TakeConnectLater(); cmd != nil {
+		return result, cmd, nil
+	}
 	if c.deps.Input.MCP.Selector.IsActive() {
 		return result, c.deps.Input.MCP.Selector.AutoReconnect(), nil
 	}
@@ -380,23 +722,81 @@ func (c *CommandController) handleReloadPluginsCommand(ctx context.Context, args
 	if err := c.deps.ReloadPluginBackedState(); err != nil {
 		return "", nil, err
 	}
-	return "Reloaded plugins and refreshed plugin-backed skills, agents, MCP servers, and hooks.", nil, nil
+	return "Reloaded plugins and refreshed plugin-backed skills, agents, tools, MCP servers, and hooks.", nil, nil
 }
 
 func (c *CommandController) handleGlobCommand(ctx context.Context, args string) (string, tea.Cmd, error) {
-	if args == "" {
-		return "Usage: /glob <pattern> [path]", nil, nil
+	pattern, path := parseGlobArgs(args)
+	if pattern == "" {
+		return "Usage: /glob <pattern>[,<pattern>|,!<exclude>...] [path]", nil, nil
 	}
-	params := map[string]any{"pattern": args}
-	parts := strings.SplitN(args, " ", 2)
-	if len(parts) == 2 {
-		params["pattern"] = parts[0]
-		params["path"] = parts[1]
+	params := map[string]any{"pattern": pattern}
+	if path != "" {
+		params["path"] = path
 	}
 	result := c.deps.ToolSvc.Execute(ctx, "glob", params, c.deps.Cwd)
 	return conv.RenderToolResult(result, c.deps.Width), nil, nil
 }
 
+// parseGlobArgs splits /glob arguments into a comma-separated pattern list
+// and an optional path. Patterns may be separated by commas, with or without
+// spaces, and a space-separated "!pattern" is treated as another exclude:
+// "**/*.go !**/*_test.go internal" searches internal/ for non-test Go files.
+func parseGlobArgs(args string) (pattern, path string) {
+	fields := strings.Fields(args)
+	var patterns []string
+	i := 0
+	for ; i < len(fields); i++ {
+		f := fields[i]
+		continuing := i == 0 || strings.HasSuffix(fields[i-1], ",") ||
+			strings.HasPrefix(f, "!") || strings.HasPrefix(f, ",")
+		if !continuing {
+			break
+		}
+		patterns = append(patterns, strings.Trim(f, ","))
+	}
+	return strings.Join(patterns, ","), strings.Join(fields[i:], " ")
+}
+
+func (c *CommandController) handleGrepCommand(ctx context.Context, args string) (string, tea.Cmd, error) {
+	pattern, path, ignoreCase := parseGrepArgs(args)
+	if pattern == "" {
+		return "Usage: /grep [-i] <pattern> [path]   (quote patterns with spaces)", nil, nil
+	}
+	params := map[string]any{
+		"pattern":     pattern,
+		"output_mode": "content",
+		"-i":          ignoreCase,
+		"head_limit":  100,
+	}
+	if path != "" {
+		params["path"] = path
+	}
+	result := c.deps.ToolSvc.Execute(ctx, "grep", params, c.deps.Cwd)
+	return conv.RenderToolResult(result, c.deps.Width), nil, nil
+}
+
+// parseGrepArgs splits /grep arguments into a pattern, an optional path, and
+// the -i flag. A pattern with spaces can be wrapped in single or double
+// quotes: /grep -i "func main" cmd.
+func parseGrepArgs(args string) (pattern, path string, ignoreCase bool) {
+	args = strings.TrimSpace(args)
+	if rest, ok := strings.CutPrefix(args, "-i "); ok {
+		ignoreCase = true
+		args = strings.TrimSpace(rest)
+	}
+	if args == "" {
+		return "", "", ignoreCase
+	}
+	if q := args[0]; q == '"' || q == '\'' {
+		if end := strings.IndexByte(args[1:], q); end >= 0 {
+			return args[1 : end+1], strings.TrimSpace(args[end+2:]), ignoreCase
+		}
+	}
+	pattern, path, _ = strings.Cut(args, " ")
+	return pattern, strings.TrimSpace(path), ignoreCase
+}
+
 func (c *CommandController) handleToolCommand(_ context.Context, _ string) (string, tea.Cmd, error) {
 	var mcpTools func() []core.ToolSchema
 	if c.deps.MCP != nil {
@@ -408,6 +808,26 @@ func (c *CommandController) handleToolCommand(_ context.Context, _ string) (stri
 	return "", nil, nil
 }
 
+func (c *CommandController) handleSettingsCommand(_ context.Context, _ string) (string, tea.Cmd, error) {
+	c.deps.Input.Settings.Enter(c.deps.Width, c.deps.Height)
+	return "", nil, nil
+}
+
+// handleThemeCommand opens the theme picker, or with a theme name applies
+// and saves that theme directly.
+func (c *CommandController) handleThemeCommand(_ context.Context, args string) (string, tea.Cmd, error) {
+	name := strings.ToLower(strings.TrimSpace(args))
+	if name == "" {
+		c.deps.Input.Theme.Enter()
+		return "", nil, nil
+	}
+	cmd, err := c.deps.Input.Theme.choose(name)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("Theme set to %s.", name), cmd, nil
+}
+
 func (c *CommandController) handleSkillCommand(_ context.Context, _ string) (string, tea.Cmd, error) {
 	if err := c.deps.Input.Skill.Selector.EnterSelect(c.deps.Width, c.deps.Height); err != nil {
 		return "", nil, err
@@ -568,13 +988,14 @@ func loopUsage() string {
 
 func (c *CommandController) handleTokenLimitCommand(_ context.Context, args string) (string, tea.Cmd, error) {
 	result, cmd, err := HandleTokenLimitCommand(TokenLimitDeps{
-		CurrentModel: c.deps.CurrentModel,
-		Provider:     c.deps.LLMProvider,
-		Store:        c.deps.ProviderStore,
-		InputTokens:  c.deps.InputTokens,
-		Cwd:          c.deps.Cwd,
-		SpinnerTick:  c.deps.SpinnerTickCmd(),
-		ToolSvc:      c.deps.ToolSvc,
+		CurrentModel:     c.deps.CurrentModel,
+		Provider:         c.deps.LLMProvider,
+		Store:            c.deps.ProviderStore,
+		InputTokens:      c.deps.InputTokens,
+		CompactThreshold: c.deps.CompactThreshold,
+		Cwd:              c.deps.Cwd,
+		SpinnerTick:      c.deps.SpinnerTickCmd(),
+		ToolSvc:          c.deps.ToolSvc,
 	}, args)
 	if cmd != nil {
 		c.deps.Input.Provider.FetchingLimits = true
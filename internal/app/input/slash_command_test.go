@@ -0,0 +1,327 @@
+package input
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yanmxa/gencode/internal/app/conv"
+	"github.com/yanmxa/gencode/internal/command"
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/setting"
+)
+
+func TestWidthCommand(t *testing.T) {
+	ctrl := NewCommandController(CommandDeps{Width: 200})
+	ctx := context.Background()
+
+	tests := []struct {
+		args      string
+		wantWidth int
+		wantMsg   bool
+		wantText  string
+	}{
+		{args: "100", wantWidth: 100, wantMsg: true},
+		{args: "auto", wantWidth: 0, wantMsg: true},
+		{args: "10", wantText: "between 40 and 500"},
+		{args: "wide", wantText: "Usage"},
+		{args: "", wantText: "follows the terminal (200 columns)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			result, cmd, err := ctrl.handleWidthCommand(ctx, tt.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.wantMsg {
+				if cmd != nil {
+					t.Fatalf("expected no command, got one")
+				}
+				if !strings.Contains(result, tt.wantText) {
+					t.Fatalf("result = %q, want it to contain %q", result, tt.wantText)
+				}
+				return
+			}
+			msg, ok := cmd().(WrapWidthMsg)
+			if !ok || msg.Width != tt.wantWidth {
+				t.Fatalf("got %#v, want WrapWidthMsg{Width: %d}", cmd(), tt.wantWidth)
+			}
+		})
+	}
+}
+
+func TestRenameCommand(t *testing.T) {
+	var title string
+	saved := 0
+	conversation := conv.NewConversation()
+	ctrl := NewCommandController(CommandDeps{
+		Conversation:    &conversation,
+		SetSessionTitle: func(s string) { title = s },
+		PersistSession:  func() error { saved++; return nil },
+	})
+	ctx := context.Background()
+
+	result, _, err := ctrl.handleRenameCommand(ctx, "  ")
+	if err != nil || !strings.Contains(result, "Usage") || title != "" {
+		t.Fatalf("empty args: result=%q err=%v title=%q", result, err, title)
+	}
+
+	result, _, err = ctrl.handleRenameCommand(ctx, "auth   refactor")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if title != "auth refactor" || !strings.Contains(result, `"auth refactor"`) {
+		t.Fatalf("result=%q title=%q", result, title)
+	}
+	if saved != 0 {
+		t.Fatalf("empty conversation should not be saved, saved %d times", saved)
+	}
+
+	conversation.Append(core.ChatMessage{Role: core.RoleUser, Content: "hi"})
+	if _, _, err := ctrl.handleRenameCommand(ctx, "login"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if saved != 1 {
+		t.Fatalf("expected the session to be saved once, saved %d times", saved)
+	}
+}
+
+func TestCustomCommandTemplatePermissions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	settings := `{"permissions": {"allow": ["Bash(echo:*)"], "deny": ["Bash(rm:*)"]}}`
+	if err := os.MkdirAll(filepath.Join(dir, ".gen"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".gen", "settings.json"), []byte(settings), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	body := "!`echo $ARGUMENTS`\n!`rm -rf build`\n!`touch asked`\n"
+	cmdFile := filepath.Join(dir, "review.md")
+	if err := os.WriteFile(cmdFile, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	setting.Initialize(setting.Options{CWD: dir})
+	t.Cleanup(setting.ResetService)
+
+	session := setting.NewSessionPermissions()
+	session.PreApproved.Grant(setting.AnyToolClass, 1)
+	state := &Model{}
+	invoked := 0
+	ctrl := NewCommandController(CommandDeps{
+		Input:                 state,
+		Cwd:                   dir,
+		SessionPermissions:    session,
+		HandleSkillInvocation: func() tea.Cmd { invoked++; return nil },
+	})
+	pc := &command.CustomCommand{Name: "review", FilePath: cmdFile}
+
+	cmd := ctrl.executeCustomCommand(context.Background(), pc, "hello")
+	if cmd == nil || state.Skill.PendingInstructions != "" || invoked != 0 {
+		t.Fatal("the template should expand in a command, not while handling the key")
+	}
+	if grants := session.PreApproved.Remaining(); len(grants) != 1 || grants[0].Count != 1 {
+		t.Errorf("checking template commands spent the /approve budget: %v", grants)
+	}
+
+	msg, ok := cmd().(CustomCommandExpandedMsg)
+	if !ok {
+		t.Fatalf("cmd() = %T, want CustomCommandExpandedMsg", cmd())
+	}
+	for _, want := range []string{
+		"hello",
+		"[not run, denied by permission rules: rm -rf build]",
+		"[not run, needs approval; add a Bash allow rule to run it from a command: touch asked]",
+	} {
+		if !strings.Contains(msg.Instructions, want) {
+			t.Errorf("instructions = %q, want %q", msg.Instructions, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "asked")); err == nil {
+		t.Error("a command that needs approval ran without asking")
+	}
+
+	ctrl.CompleteCustomCommand(msg)
+	if invoked != 1 || !strings.Contains(state.Skill.PendingInstructions, `<custom-command name="review">`) ||
+		state.Skill.PendingArgs != "/review hello" {
+		t.Errorf("invoked = %d, pending = %q / %q", invoked, state.Skill.PendingInstructions, state.Skill.PendingArgs)
+	}
+}
+
+func TestModelSetLimitCommand(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	store, err := llm.NewStore()
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	ctrl := NewCommandController(CommandDeps{
+		Input:          &Model{},
+		ProviderStore:  store,
+		CurrentModel:   &llm.CurrentModelInfo{ModelID: "gpt-5", Provider: llm.OpenAI, AuthMethod: llm.AuthAPIKey},
+		SpinnerTickCmd: func() tea.Cmd { return nil },
+	})
+	ctx := context.Background()
+
+	result, _, err := ctrl.handleModelCommand(ctx, "set-limit")
+	if err != nil || !strings.Contains(result, "Usage") {
+		t.Fatalf("set-limit without args = %q, %v; want usage", result, err)
+	}
+
+	result, cmd, err := ctrl.handleModelCommand(ctx, "set-limit 200000 32000")
+	if err != nil || cmd != nil {
+		t.Fatalf("set-limit returned cmd=%v err=%v", cmd != nil, err)
+	}
+	if !strings.Contains(result, "Source: custom override") {
+		t.Fatalf("result = %q, want custom override source", result)
+	}
+	if in, out, ok := store.GetTokenLimit("gpt-5"); !ok || in != 200000 || out != 32000 {
+		t.Fatalf("stored limits = %d/%d (ok=%v)", in, out, ok)
+	}
+
+	result, _, _ = ctrl.handleTokenLimitCommand(ctx, "")
+	if !strings.Contains(result, "Source: custom override") {
+		t.Fatalf("/tokenlimit = %q, want source shown", result)
+	}
+	if !strings.Contains(result, "Auto-compact: at 95% (190.0k tokens)") {
+		t.Fatalf("/tokenlimit = %q, want the default auto-compact threshold", result)
+	}
+
+	ctrl.deps.CompactThreshold = setting.AutoCompactOff
+	if result, _, _ = ctrl.handleTokenLimitCommand(ctx, ""); !strings.Contains(result, "Auto-compact: off") {
+		t.Fatalf("/tokenlimit = %q, want auto-compact shown as off", result)
+	}
+}
+
+func TestModelFallbackChainCommand(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	store, err := llm.NewStore()
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if err := store.Connect(llm.OpenAI, llm.AuthAPIKey); err != nil {
+		t.Fatal(err)
+	}
+	ctrl := NewCommandController(CommandDeps{Input: &Model{}, ProviderStore: store})
+	run := func(args string) string {
+		t.Helper()
+		result, cmd, err := ctrl.handleModelCommand(context.Background(), "fallback-chain "+args)
+		if err != nil || cmd != nil {
+			t.Fatalf("fallback-chain %s: cmd=%v err=%v", args, cmd != nil, err)
+		}
+		return result
+	}
+
+	if got := run(""); !strings.Contains(got, "No fallback chain") {
+		t.Fatalf("empty chain = %q", got)
+	}
+	run("add openai:gpt-5")
+	run("add anthropic/claude-sonnet-4-6")
+	got := run("add google:gemini-2.5-pro 1")
+	for _, want := range []string{"1. google:gemini-2.5-pro", "2. openai:gpt-5", "3. anthropic:claude-sonnet-4-6"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("chain = %q, want %q", got, want)
+		}
+	}
+	lines := strings.Split(got, "\n")
+	if !strings.HasSuffix(lines[2], " connected") || !strings.HasSuffix(lines[3], "not connected, skipped") {
+		t.Errorf("chain = %q, want connection status per entry", got)
+	}
+
+	run("move 3 1")
+	run("remove google:gemini-2.5-pro")
+	want := []llm.FallbackEntry{{Provider: llm.Anthropic, ModelID: "claude-sonnet-4-6"}, {Provider: llm.OpenAI, ModelID: "gpt-5"}}
+	if chain := store.GetFallbackChain(); !slices.Equal(chain, want) {
+		t.Errorf("stored chain = %v, want %v", chain, want)
+	}
+
+	for _, args := range []string{"add openai:gpt-5", "remove 7", "move 1", "add nomodel", "bogus"} {
+		if got := run(args); !strings.Contains(got, "Usage") {
+			t.Errorf("fallback-chain %s = %q, want usage", args, got)
+		}
+	}
+	run("clear")
+	if chain := store.GetFallbackChain(); len(chain) != 0 {
+		t.Errorf("chain after clear = %v", chain)
+	}
+}
+
+func TestFallbackCommand(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	store, err := llm.NewStore()
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	ctrl := NewCommandController(CommandDeps{Input: &Model{}, ProviderStore: store})
+	run := func(args string) string {
+		t.Helper()
+		result, cmd, err := ctrl.handleFallbackCommand(context.Background(), args)
+		if err != nil || cmd != nil {
+			t.Fatalf("/fallback %s: cmd=%v err=%v", args, cmd != nil, err)
+		}
+		return result
+	}
+
+	if got := run("list"); !strings.Contains(got, "/fallback add") {
+		t.Fatalf("/fallback list on an empty chain = %q", got)
+	}
+	run("add openai:gpt-5")
+	if got := run("list"); !strings.Contains(got, "1. openai:gpt-5") {
+		t.Errorf("/fallback list = %q", got)
+	}
+	// /model fallback-chain edits the same chain.
+	if got, _, _ := ctrl.handleModelCommand(context.Background(), "fallback-chain"); !strings.Contains(got, "1. openai:gpt-5") {
+		t.Errorf("/model fallback-chain = %q", got)
+	}
+	run("remove 1")
+	if chain := store.GetFallbackChain(); len(chain) != 0 {
+		t.Errorf("chain after remove = %v", chain)
+	}
+}
+
+func TestParseGrepArgs(t *testing.T) {
+	tests := []struct {
+		args                  string
+		wantPattern, wantPath string
+		wantIgnoreCase        bool
+	}{
+		{"TODO", "TODO", "", false},
+		{"TODO internal/app", "TODO", "internal/app", false},
+		{"-i todo", "todo", "", true},
+		{`-i "func main" cmd`, "func main", "cmd", true},
+		{`'a b'`, "a b", "", false},
+		{"", "", "", false},
+	}
+	for _, tt := range tests {
+		pattern, path, ignoreCase := parseGrepArgs(tt.args)
+		if pattern != tt.wantPattern || path != tt.wantPath || ignoreCase != tt.wantIgnoreCase {
+			t.Errorf("parseGrepArgs(%q) = (%q, %q, %v), want (%q, %q, %v)", tt.args, pattern, path, ignoreCase, tt.wantPattern, tt.wantPath, tt.wantIgnoreCase)
+		}
+	}
+}
+
+func TestParseGlobArgs(t *testing.T) {
+	tests := []struct {
+		args        string
+		wantPattern string
+		wantPath    string
+	}{
+		{"**/*.go", "**/*.go", ""},
+		{"**/*.go internal", "**/*.go", "internal"},
+		{"**/*.go,!**/*_test.go internal", "**/*.go,!**/*_test.go", "internal"},
+		{"**/*.go, !**/*_test.go", "**/*.go,!**/*_test.go", ""},
+		{"**/*.go !**/*_test.go my dir", "**/*.go,!**/*_test.go", "my dir"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		pattern, path := parseGlobArgs(tt.args)
+		if pattern != tt.wantPattern || path != tt.wantPath {
+			t.Errorf("parseGlobArgs(%q) = (%q, %q), want (%q, %q)", tt.args, pattern, path, tt.wantPattern, tt.wantPath)
+		}
+	}
+}
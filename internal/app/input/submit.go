@@ -21,6 +21,8 @@ type SubmitRuntime interface {
 	QuitWithCancel() (tea.Cmd, bool)
 	StartProviderTurn(content string) tea.Cmd
 	SendToActiveAgent(content string, images []core.Image) tea.Cmd
+	// RewindConversation drops the message at index and everything after it.
+	RewindConversation(index int) tea.Cmd
 }
 
 type SubmitDeps struct {
@@ -31,6 +33,7 @@ type SubmitDeps struct {
 	Cwd             string
 	HandleCommand   func(string) (tea.Cmd, bool)
 	ClearPluginRoot func()
+	RedactHistory   func(string) string // hides secrets from the saved input history; nil keeps entries as typed
 }
 
 func HandleSubmit(deps SubmitDeps) tea.Cmd {
@@ -87,7 +90,7 @@ func ExecuteSubmitRequest(deps SubmitDeps, req SubmitRequest) tea.Cmd {
 		return BlockPromptSubmission(deps, reason)
 	}
 
-	deps.Input.RecordSubmission(deps.Cwd, req.Input)
+	deps.Input.RecordSubmission(deps.Cwd, req.Input, deps.RedactHistory)
 
 	if cmd, handled := deps.HandleCommand(req.Input); handled {
 		return cmd
@@ -102,9 +105,17 @@ func ExecuteSubmitRequest(deps SubmitDeps, req SubmitRequest) tea.Cmd {
 	if handled {
 		return cmd
 	}
+	var rewind tea.Cmd
+	if deps.Input.Edit.Active {
+		rewind = deps.Actions.RewindConversation(deps.Input.Edit.Index)
+	}
 	deps.Conversation.Append(userMsg)
 	deps.Input.Reset()
-	return deps.Actions.StartProviderTurn(userMsg.Content)
+	start := deps.Actions.StartProviderTurn(userMsg.Content)
+	if rewind != nil {
+		return tea.Sequence(rewind, start)
+	}
+	return start
 }
 
 func BlockPromptSubmission(deps SubmitDeps, reason string) tea.Cmd {
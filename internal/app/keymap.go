@@ -0,0 +1,184 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keyAction is a prompt action whose key the keybindings setting can remap.
+type keyAction string
+
+const (
+	actionSend         keyAction = "send"
+	actionNewline      keyAction = "newline"
+	actionCycleMode    keyAction = "cycleMode"
+	actionToggleExpand keyAction = "toggleExpand"
+	actionStop         keyAction = "stop"
+	actionClearInput   keyAction = "clearInput"
+)
+
+// defaultKeys binds each remappable action to the key it had before the
+// keybindings setting existed.
+var defaultKeys = map[keyAction]string{
+	actionSend:         "enter",
+	actionNewline:      "alt+enter",
+	actionCycleMode:    "shift+tab",
+	actionToggleExpand: "ctrl+o",
+	actionStop:         "esc",
+	actionClearInput:   "ctrl+c",
+}
+
+// keymap maps keys, as tea.KeyMsg.String spells them, to prompt actions.
+// The zero keymap uses defaultKeys.
+type keymap struct {
+	keys    map[keyAction]string
+	actions map[string]keyAction
+}
+
+// newKeymap applies the keybindings setting over defaultKeys. Unknown
+// actions, unknown keys, and keys bound to two actions are reported as
+// warnings; the actions involved keep their default keys.
+func newKeymap(bindings map[string]string) (keymap, []string) {
+	keys := make(map[keyAction]string, len(defaultKeys))
+	for action, key := range defaultKeys {
+		keys[action] = key
+	}
+
+	var warnings []string
+	custom := make(map[keyAction]bool)
+	for _, name := range sortedKeys(bindings) {
+		action := keyAction(name)
+		if _, ok := defaultKeys[action]; !ok {
+			warnings = append(warnings, fmt.Sprintf("unknown action %q", name))
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(bindings[name]))
+		if !validKey(key) {
+			warnings = append(warnings, fmt.Sprintf("%s: unknown key %q", name, bindings[name]))
+			continue
+		}
+		keys[action] = key
+		custom[action] = true
+	}
+
+	// Restoring a default can collide with another custom key, so repeat
+	// until every key has one action.
+	for {
+		conflicts := conflictingActions(keys)
+		if len(conflicts) == 0 {
+			break
+		}
+		for _, key := range sortedKeys(conflicts) {
+			actions := conflicts[key]
+			reverted := false
+			for _, action := range actions {
+				if custom[action] {
+					keys[action] = defaultKeys[action]
+					custom[action] = false
+					reverted = true
+				}
+			}
+			if reverted {
+				names := make([]string, len(actions))
+				for i, action := range actions {
+					names[i] = string(action)
+				}
+				warnings = append(warnings, fmt.Sprintf("%q is bound to both %s", key, strings.Join(names, " and ")))
+			}
+		}
+	}
+
+	k := keymap{keys: keys, actions: make(map[string]keyAction, len(keys))}
+	for action, key := range keys {
+		k.actions[key] = action
+	}
+	return k, warnings
+}
+
+// conflictingActions returns the keys bound to more than one action, with
+// those actions in a stable order.
+func conflictingActions(keys map[keyAction]string) map[string][]keyAction {
+	byKey := make(map[string][]keyAction)
+	for action, key := range keys {
+		byKey[key] = append(byKey[key], action)
+	}
+	for key, actions := range byKey {
+		if len(actions) < 2 {
+			delete(byKey, key)
+			continue
+		}
+		sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+	}
+	return byKey
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// keyNames holds the named keys Bubble Tea reports, e.g. "enter", "ctrl+s".
+var keyNames = func() map[string]bool {
+	names := make(map[string]bool)
+	for k := tea.KeyType(-128); k < 128; k++ {
+		if s := k.String(); s != "" && k != tea.KeyRunes {
+			names[s] = true
+		}
+	}
+	return names
+}()
+
+// validKey reports whether key names a key Bubble Tea can report: a named
+// key, or "alt+" with a named key or a character. A bare character is not
+// allowed, since binding it would take it away from typing.
+func validKey(key string) bool {
+	if rest, ok := strings.CutPrefix(key, "alt+"); ok {
+		return keyNames[rest] || utf8.RuneCountInString(rest) == 1
+	}
+	return keyNames[key]
+}
+
+// action returns the action bound to msg, or "" when there is none.
+func (k keymap) action(msg tea.KeyMsg) keyAction {
+	if k.actions == nil {
+		k = defaultKeymap
+	}
+	return k.actions[msg.String()]
+}
+
+// key returns the key bound to action.
+func (k keymap) key(action keyAction) string {
+	if k.keys == nil {
+		k = defaultKeymap
+	}
+	return k.keys[action]
+}
+
+var defaultKeymap, _ = newKeymap(nil)
+
+// keyLabel spells key the way the TUI's hints do, e.g. "ctrl+g" as "Ctrl+G".
+func keyLabel(key string) string {
+	parts := strings.Split(key, "+")
+	for i, part := range parts {
+		if r, size := utf8.DecodeRuneInString(part); size > 0 {
+			parts[i] = strings.ToUpper(string(r)) + part[size:]
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+// keymapWarning formats the keybindings warnings as a startup notice.
+func keymapWarning(warnings []string) string {
+	if len(warnings) == 0 {
+		return ""
+	}
+	return "Some keybindings settings were ignored; the actions keep their default keys:\n  - " + strings.Join(warnings, "\n  - ")
+}
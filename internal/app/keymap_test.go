@@ -0,0 +1,91 @@
+package app
+
+import (
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yanmxa/gencode/internal/app/input"
+)
+
+func TestNewKeymapDefaults(t *testing.T) {
+	k, warnings := newKeymap(nil)
+	if len(warnings) != 0 {
+		t.Fatalf("warnings = %q", warnings)
+	}
+	cases := map[keyAction]tea.KeyMsg{
+		actionSend:         {Type: tea.KeyEnter},
+		actionNewline:      {Type: tea.KeyEnter, Alt: true},
+		actionCycleMode:    {Type: tea.KeyShiftTab},
+		actionToggleExpand: {Type: tea.KeyCtrlO},
+		actionStop:         {Type: tea.KeyEsc},
+		actionClearInput:   {Type: tea.KeyCtrlC},
+	}
+	for want, msg := range cases {
+		if got := k.action(msg); got != want {
+			t.Errorf("action(%s) = %q, want %q", msg, got, want)
+		}
+		if got := (keymap{}).action(msg); got != want {
+			t.Errorf("zero keymap action(%s) = %q, want %q", msg, got, want)
+		}
+	}
+}
+
+func TestNewKeymapRemapsAndWarns(t *testing.T) {
+	k, warnings := newKeymap(map[string]string{
+		"send":         "Alt+Enter",
+		"newline":      "enter",
+		"stop":         "ctrl+g",
+		"toggleExpand": "ctrl+g", // conflicts with stop
+		"clearInput":   "u",      // bare characters are for typing
+		"quit":         "ctrl+q",
+	})
+
+	if got := k.action(tea.KeyMsg{Type: tea.KeyEnter, Alt: true}); got != actionSend {
+		t.Errorf("alt+enter = %q, want send", got)
+	}
+	if got := k.action(tea.KeyMsg{Type: tea.KeyEnter}); got != actionNewline {
+		t.Errorf("enter = %q, want newline", got)
+	}
+	for action, want := range map[keyAction]string{actionStop: "esc", actionToggleExpand: "ctrl+o", actionClearInput: "ctrl+c"} {
+		if got := k.key(action); got != want {
+			t.Errorf("key(%s) = %q, want the default %q", action, got, want)
+		}
+	}
+
+	want := []string{
+		`clearInput: unknown key "u"`,
+		`unknown action "quit"`,
+		`"ctrl+g" is bound to both stop and toggleExpand`,
+	}
+	if !slices.Equal(warnings, want) {
+		t.Errorf("warnings = %q, want %q", warnings, want)
+	}
+}
+
+func TestHandleInputKeyUsesKeybindings(t *testing.T) {
+	m := &model{}
+	m.userInput = input.New("", 80, nil, input.SelectorDeps{})
+	m.env.Keys, _ = newKeymap(map[string]string{"newline": "enter", "send": "ctrl+s", "clearInput": "ctrl+k"})
+
+	m.userInput.Textarea.SetValue("first")
+	if _, handled := m.handleInputKey(tea.KeyMsg{Type: tea.KeyEnter}); !handled {
+		t.Fatal("Enter bound to newline was not handled")
+	}
+	if got := m.userInput.Textarea.Value(); got != "first\n" {
+		t.Errorf("after Enter the prompt is %q, want a newline added", got)
+	}
+
+	if _, handled := m.handleInputKey(tea.KeyMsg{Type: tea.KeyCtrlK}); !handled || m.userInput.Textarea.Value() != "" {
+		t.Errorf("Ctrl+K bound to clearInput left %q", m.userInput.Textarea.Value())
+	}
+}
+
+func TestKeyLabel(t *testing.T) {
+	for key, want := range map[string]string{"esc": "Esc", "ctrl+g": "Ctrl+G", "alt+enter": "Alt+Enter"} {
+		if got := keyLabel(key); got != want {
+			t.Errorf("keyLabel(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
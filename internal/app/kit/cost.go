@@ -2,6 +2,7 @@ package kit
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/yanmxa/gencode/internal/llm"
 )
@@ -20,6 +21,16 @@ func FormatMoney(m llm.Money) string {
 	}
 }
 
+// FormatMoneyTotals formats amounts in different currencies as one sum,
+// e.g. "$0.412 + ¥1.030".
+func FormatMoneyTotals(totals []llm.Money) string {
+	parts := make([]string, len(totals))
+	for i, m := range totals {
+		parts[i] = FormatMoney(m)
+	}
+	return strings.Join(parts, " + ")
+}
+
 func formatCurrencyAmount(symbol string, amount float64) string {
 	switch {
 	case amount <= 0:
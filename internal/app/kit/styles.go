@@ -70,6 +70,16 @@ func DimStyle() lipgloss.Style {
 		Foreground(CurrentTheme.TextDim)
 }
 
+// DiffAddedLineStyle highlights an added line in a diff preview.
+func DiffAddedLineStyle() lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(CurrentTheme.Success).Background(CurrentTheme.SuccessBg)
+}
+
+// DiffRemovedLineStyle highlights a removed line in a diff preview.
+func DiffRemovedLineStyle() lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(CurrentTheme.Error).Background(CurrentTheme.ErrorBg)
+}
+
 // TabActiveBg is the background color for active tabs in tabbed panels.
 var TabActiveBg = lipgloss.AdaptiveColor{Dark: "#4F6D9B", Light: "#3B6FC0"}
 
@@ -28,7 +28,9 @@ type Theme struct {
 	Background lipgloss.AdaptiveColor
 }
 
-var CurrentTheme = Theme{
+// defaultPalette is the palette of the dark and light themes. Its adaptive
+// colors follow the terminal background, which those themes pin.
+var defaultPalette = Theme{
 	Muted:     lipgloss.AdaptiveColor{Dark: "#7B8696", Light: "#6B7280"},
 	Accent:    lipgloss.AdaptiveColor{Dark: "#9DB5D4", Light: "#64748B"},
 	Primary:   lipgloss.AdaptiveColor{Dark: "#D0DFEF", Light: "#475569"},
@@ -50,18 +52,128 @@ var CurrentTheme = Theme{
 	Background: lipgloss.AdaptiveColor{Dark: "#18181B", Light: "#FAFAFA"},
 }
 
+// fixed returns a color that is the same on dark and light backgrounds.
+func fixed(hex string) lipgloss.AdaptiveColor {
+	return lipgloss.AdaptiveColor{Dark: hex, Light: hex}
+}
+
+// nordPalette is the Nord arctic palette, for dark terminals.
+var nordPalette = Theme{
+	Muted:     fixed("#7B88A1"),
+	Accent:    fixed("#81A1C1"),
+	Primary:   fixed("#88C0D0"),
+	AI:        fixed("#8FBCBB"),
+	Separator: fixed("#4C566A"),
+
+	Text:         fixed("#D8DEE9"),
+	TextDim:      fixed("#A3ABB9"),
+	TextBright:   fixed("#ECEFF4"),
+	TextDisabled: fixed("#4C566A"),
+
+	Success:   fixed("#A3BE8C"),
+	Error:     fixed("#BF616A"),
+	Warning:   fixed("#EBCB8B"),
+	SuccessBg: fixed("#2F3B33"),
+	ErrorBg:   fixed("#3B2E33"),
+
+	Border:     fixed("#4C566A"),
+	Background: fixed("#2E3440"),
+}
+
+// solarizedLightPalette is Solarized on its light base, for light terminals.
+var solarizedLightPalette = Theme{
+	Muted:     fixed("#93A1A1"),
+	Accent:    fixed("#2AA198"),
+	Primary:   fixed("#268BD2"),
+	AI:        fixed("#6C71C4"),
+	Separator: fixed("#D3CBB7"),
+
+	Text:         fixed("#586E75"),
+	TextDim:      fixed("#839496"),
+	TextBright:   fixed("#073642"),
+	TextDisabled: fixed("#C5BFAE"),
+
+	Success:   fixed("#859900"),
+	Error:     fixed("#DC322F"),
+	Warning:   fixed("#B58900"),
+	SuccessBg: fixed("#EEF1D6"),
+	ErrorBg:   fixed("#FBE3DC"),
+
+	Border:     fixed("#D3CBB7"),
+	Background: fixed("#FDF6E3"),
+}
+
+// ThemeChoice is a built-in theme that the theme setting can name.
+type ThemeChoice struct {
+	Name    string
+	Label   string
+	Desc    string
+	Dark    bool // whether the theme is for a dark terminal background
+	Palette Theme
+}
+
+var themeChoices = []ThemeChoice{
+	{"dark", "Dark", "Dark background terminal", true, defaultPalette},
+	{"light", "Light", "Light background terminal", false, defaultPalette},
+	{"nord", "Nord", "Cool arctic blues on a dark background", true, nordPalette},
+	{"solarized-light", "Solarized Light", "Solarized colors on a light background", false, solarizedLightPalette},
+}
+
+// Themes returns the built-in themes in display order.
+func Themes() []ThemeChoice {
+	return themeChoices
+}
+
+// ThemeNames returns the names of the built-in themes in display order.
+func ThemeNames() []string {
+	names := make([]string, len(themeChoices))
+	for i, t := range themeChoices {
+		names[i] = t.Name
+	}
+	return names
+}
+
+var CurrentTheme = defaultPalette
+
 var (
-	darkModeSet bool
-	darkModeVal bool
+	darkModeSet  bool
+	darkModeVal  bool
+	currentName  string
+	themeRefresh []func()
 )
 
-func InitTheme(t string) {
-	if t != "light" && t != "dark" {
-		return
+// OnThemeChange registers fn to rebuild styles derived from CurrentTheme,
+// and runs it once now. fn runs again every time the theme changes, so
+// package-level styles pick up the new colors without a restart.
+func OnThemeChange(fn func()) {
+	themeRefresh = append(themeRefresh, fn)
+	fn()
+}
+
+// InitTheme switches to the named built-in theme and refreshes the styles
+// registered with OnThemeChange. It reports false, leaving the theme as it
+// was, when t is not a built-in theme.
+func InitTheme(t string) bool {
+	for _, choice := range themeChoices {
+		if choice.Name != t {
+			continue
+		}
+		darkModeSet, darkModeVal = true, choice.Dark
+		lipgloss.SetHasDarkBackground(choice.Dark)
+		CurrentTheme = choice.Palette
+		currentName = t
+		for _, fn := range themeRefresh {
+			fn()
+		}
+		return true
 	}
-	dark := t == "dark"
-	darkModeSet, darkModeVal = true, dark
-	lipgloss.SetHasDarkBackground(dark)
+	return false
+}
+
+// CurrentThemeName returns the name of the theme set by InitTheme, or ""
+// when none has been set.
+func CurrentThemeName() string {
+	return currentName
 }
 
 // ResolveTheme ensures a theme is configured.
@@ -32,11 +32,18 @@ func themeHintStyle() lipgloss.Style {
 	return lipgloss.NewStyle().Foreground(CurrentTheme.TextDim).MarginTop(1)
 }
 
-var themeChoices = []struct {
-	label, value, desc string
-}{
-	{"Light", "light", "Light background terminal"},
-	{"Dark", "dark", "Dark background terminal"},
+// ThemeSwatch renders a row of t's main colors, so a theme can be previewed
+// before it is applied.
+func ThemeSwatch(t ThemeChoice) string {
+	var sb strings.Builder
+	for _, c := range []lipgloss.AdaptiveColor{t.Palette.Primary, t.Palette.AI, t.Palette.Accent, t.Palette.Success, t.Palette.Warning, t.Palette.Error} {
+		hex := c.Light
+		if t.Dark {
+			hex = c.Dark
+		}
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(hex)).Render("●"))
+	}
+	return sb.String()
 }
 
 type themeSelectorModel struct{ cursor int }
@@ -61,7 +68,7 @@ func (m themeSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.cursor++
 		}
 	case "enter", " ":
-		return m, func() tea.Msg { return themeSelectedMsg{Theme: themeChoices[m.cursor].value} }
+		return m, func() tea.Msg { return themeSelectedMsg{Theme: themeChoices[m.cursor].Name} }
 	case "ctrl+c", "q":
 		return m, tea.Quit
 	}
@@ -79,7 +86,7 @@ func (m themeSelectorModel) View() string {
 			cursor = "▶ "
 			style = themeActiveStyle()
 		}
-		fmt.Fprintf(&s, "%s%s  %s\n", cursor, style.Render(opt.label), themeDescStyle().Render(opt.desc))
+		fmt.Fprintf(&s, "%s%s %s  %s\n", cursor, style.Render(fmt.Sprintf("%-16s", opt.Label)), ThemeSwatch(opt), themeDescStyle().Render(opt.Desc))
 	}
 
 	s.WriteString(themeHintStyle().Render("\n↑/↓ to move · enter to confirm · q to quit"))
@@ -105,7 +112,7 @@ func (c themeCapture) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (c themeCapture) View() string { return c.inner.View() }
 
-// RunThemeSelector opens the theme selector and returns the chosen theme ("light" or "dark").
+// RunThemeSelector opens the theme selector and returns the chosen theme's name.
 // Returns an empty string if the user quit without selecting.
 func RunThemeSelector() (string, error) {
 	p := tea.NewProgram(themeCapture{inner: newThemeSelector()})
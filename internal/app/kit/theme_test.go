@@ -0,0 +1,36 @@
+package kit
+
+import "testing"
+
+func TestInitThemeRefreshesRegisteredStyles(t *testing.T) {
+	t.Cleanup(func() { InitTheme("dark") })
+
+	var primary string
+	calls := 0
+	OnThemeChange(func() {
+		calls++
+		primary = CurrentTheme.Primary.Dark
+	})
+	if calls != 1 {
+		t.Fatalf("OnThemeChange should build the styles at once, ran %d times", calls)
+	}
+
+	if !InitTheme("nord") {
+		t.Fatal("InitTheme(nord) = false, want a built-in theme")
+	}
+	if calls != 2 || primary != nordPalette.Primary.Dark || CurrentThemeName() != "nord" || !IsDarkBackground() {
+		t.Errorf("after nord: calls=%d primary=%s name=%q dark=%v", calls, primary, CurrentThemeName(), IsDarkBackground())
+	}
+
+	if InitTheme("neon") {
+		t.Error("InitTheme(neon) = true, want unknown themes rejected")
+	}
+	if calls != 2 || CurrentThemeName() != "nord" {
+		t.Errorf("an unknown theme changed the theme: calls=%d name=%q", calls, CurrentThemeName())
+	}
+
+	InitTheme("solarized-light")
+	if primary != solarizedLightPalette.Primary.Dark || IsDarkBackground() {
+		t.Errorf("after solarized-light: primary=%s dark=%v", primary, IsDarkBackground())
+	}
+}
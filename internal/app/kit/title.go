@@ -0,0 +1,77 @@
+package kit
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// titleRefreshInterval bounds how often TitleProgress rewrites the title.
+const titleRefreshInterval = 250 * time.Millisecond
+
+// TitleProgress shows generation progress in the terminal title (OSC 2) so
+// long print-mode runs whose stdout is redirected still look alive. The
+// previous title is saved with the xterm title stack on start and restored
+// by Done; terminals without a title stack just end with an empty title.
+// A nil *TitleProgress is a no-op.
+type TitleProgress struct {
+	w        io.Writer
+	chars    int
+	start    time.Time
+	lastDraw time.Time
+	now      func() time.Time
+}
+
+// NewTitleProgress returns a TitleProgress writing to stderr, or nil when
+// disabled or stderr is not a terminal.
+func NewTitleProgress(enabled bool) *TitleProgress {
+	if !enabled {
+		return nil
+	}
+	stat, err := os.Stderr.Stat()
+	if err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+	return newTitleProgress(os.Stderr, time.Now)
+}
+
+func newTitleProgress(w io.Writer, now func() time.Time) *TitleProgress {
+	t := &TitleProgress{w: w, now: now, start: now()}
+	fmt.Fprint(w, "\x1b[22;2t") // push the current title
+	t.draw()
+	return t
+}
+
+// Add accounts for streamed text and refreshes the title at most every
+// titleRefreshInterval.
+func (t *TitleProgress) Add(text string) {
+	if t == nil {
+		return
+	}
+	t.chars += len(text)
+	if t.now().Sub(t.lastDraw) >= titleRefreshInterval {
+		t.draw()
+	}
+}
+
+// Done clears the progress title and restores the saved one.
+func (t *TitleProgress) Done() {
+	if t == nil {
+		return
+	}
+	fmt.Fprint(t.w, "\x1b]2;\x07\x1b[23;2t")
+}
+
+func (t *TitleProgress) draw() {
+	t.lastDraw = t.now()
+	title := "gen: generating…"
+	if t.chars > 0 {
+		// ~4 characters per token, the same estimate used for compaction.
+		title += " " + FormatTokenCount(t.chars/4) + " tokens"
+	}
+	if elapsed := t.lastDraw.Sub(t.start); elapsed >= time.Second {
+		title += fmt.Sprintf(" (%ds)", int(elapsed.Seconds()))
+	}
+	fmt.Fprintf(t.w, "\x1b]2;%s\x07", title)
+}
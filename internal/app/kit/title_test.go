@@ -0,0 +1,42 @@
+package kit
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTitleProgress(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Unix(0, 0)
+	tp := newTitleProgress(&buf, func() time.Time { return now })
+
+	if !strings.HasPrefix(buf.String(), "\x1b[22;2t\x1b]2;gen: generating…\x07") {
+		t.Fatalf("start = %q, want title push then initial title", buf.String())
+	}
+
+	buf.Reset()
+	tp.Add(strings.Repeat("x", 4800))
+	if buf.Len() != 0 {
+		t.Fatalf("title redrawn before refresh interval: %q", buf.String())
+	}
+
+	now = now.Add(2 * time.Second)
+	tp.Add("")
+	if got, want := buf.String(), "\x1b]2;gen: generating… 1.2k tokens (2s)\x07"; got != want {
+		t.Fatalf("progress = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	tp.Done()
+	if got, want := buf.String(), "\x1b]2;\x07\x1b[23;2t"; got != want {
+		t.Fatalf("done = %q, want %q", got, want)
+	}
+}
+
+func TestTitleProgressNilIsNoop(t *testing.T) {
+	var tp *TitleProgress
+	tp.Add("text")
+	tp.Done()
+}
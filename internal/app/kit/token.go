@@ -26,12 +26,13 @@ func FormatTokenCount(count int) string {
 }
 
 // ShouldAutoCompact returns true when context usage is high enough to trigger
-// automatic compaction.
-func ShouldAutoCompact(p llm.Provider, messageCount, inputTokens int, store *llm.Store, currentModel *llm.CurrentModelInfo) bool {
+// automatic compaction. threshold is the autoCompactThreshold setting: 0
+// means core.DefaultCompactThreshold and a negative value never compacts.
+func ShouldAutoCompact(p llm.Provider, messageCount, inputTokens, threshold int, store *llm.Store, currentModel *llm.CurrentModelInfo) bool {
 	if p == nil || messageCount < 3 {
 		return false
 	}
-	return core.NeedsCompaction(inputTokens, GetEffectiveInputLimit(store, currentModel))
+	return core.NeedsCompactionAt(inputTokens, GetEffectiveInputLimit(store, currentModel), threshold)
 }
 
 // GetContextUsagePercent returns what percentage of the context window is used.
@@ -70,29 +71,48 @@ func GetModelTokenLimits(store *llm.Store, currentModel *llm.CurrentModelInfo) (
 	return 0, 0
 }
 
-// getEffectiveTokenLimits returns custom limits if set, otherwise cached model limits.
-func getEffectiveTokenLimits(store *llm.Store, currentModel *llm.CurrentModelInfo) (inputLimit, outputLimit int) {
+// GetEffectiveTokenLimits returns the stored override if set, otherwise the
+// cached model limits, along with where the returned limits came from.
+func GetEffectiveTokenLimits(store *llm.Store, currentModel *llm.CurrentModelInfo) (inputLimit, outputLimit int, source llm.TokenLimitSource) {
 	if currentModel == nil {
-		return 0, 0
+		return 0, 0, llm.TokenLimitSourceNone
 	}
 
 	if store != nil {
 		if input, output, ok := store.GetTokenLimit(currentModel.ModelID); ok {
-			return input, output
+			return input, output, store.GetTokenLimitSource(currentModel.ModelID)
 		}
 	}
 
-	return GetModelTokenLimits(store, currentModel)
+	inputLimit, outputLimit = GetModelTokenLimits(store, currentModel)
+	if inputLimit > 0 || outputLimit > 0 {
+		return inputLimit, outputLimit, llm.TokenLimitSourceProvider
+	}
+	return 0, 0, llm.TokenLimitSourceNone
+}
+
+// TokenLimitSourceLabel describes a token limit source for display.
+func TokenLimitSourceLabel(source llm.TokenLimitSource) string {
+	switch source {
+	case llm.TokenLimitSourceCustom:
+		return "custom override"
+	case llm.TokenLimitSourceFetched:
+		return "auto-fetched"
+	case llm.TokenLimitSourceProvider:
+		return "provider model cache"
+	default:
+		return "unknown"
+	}
 }
 
 // GetEffectiveInputLimit returns only the effective input token limit.
 func GetEffectiveInputLimit(store *llm.Store, currentModel *llm.CurrentModelInfo) int {
-	input, _ := getEffectiveTokenLimits(store, currentModel)
+	input, _, _ := GetEffectiveTokenLimits(store, currentModel)
 	return input
 }
 
 // getEffectiveOutputLimit returns only the effective output token limit.
 func getEffectiveOutputLimit(store *llm.Store, currentModel *llm.CurrentModelInfo) int {
-	_, output := getEffectiveTokenLimits(store, currentModel)
+	_, output, _ := GetEffectiveTokenLimits(store, currentModel)
 	return output
 }
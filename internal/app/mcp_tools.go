@@ -0,0 +1,143 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/mcp"
+)
+
+// mcpToolsChangedMsg reports that the tools of the MCP servers changed: a
+// server connected or disconnected, or sent tools/list_changed.
+type mcpToolsChangedMsg struct {
+	changed <-chan struct{}
+}
+
+// watchMCPTools forwards the registry's tools-changed callback to the TUI.
+// Changes that arrive before the last one is handled are coalesced.
+func (m *model) watchMCPTools() tea.Cmd {
+	reg := m.mcpRegistry()
+	if reg == nil {
+		return nil
+	}
+	m.env.MCPTools = mcpToolNames(reg)
+	changed := make(chan struct{}, 1)
+	reg.SetOnToolsChanged(func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	return waitMCPToolsChanged(changed)
+}
+
+func waitMCPToolsChanged(changed <-chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		<-changed
+		return mcpToolsChangedMsg{changed: changed}
+	}
+}
+
+func (m *model) mcpRegistry() *mcp.Registry {
+	if m.services.MCP == nil {
+		return nil
+	}
+	return m.services.MCP.Registry()
+}
+
+// mcpCoreTools returns the tools of the connected MCP servers for the agent.
+func (m *model) mcpCoreTools() []core.Tool {
+	reg := m.mcpRegistry()
+	if reg == nil {
+		return nil
+	}
+	return mcp.AsCoreTools(reg.GetToolSchemas(), mcp.NewCaller(reg))
+}
+
+// handleMCPToolsChanged gives the running agent the current MCP tools,
+// which it lists from the next model request on, so a streaming response
+// is not interrupted. Servers that changed their tools while connected get
+// a notice; connecting and disconnecting report themselves.
+func (m *model) handleMCPToolsChanged(msg mcpToolsChangedMsg) tea.Cmd {
+	reg := m.mcpRegistry()
+	if reg == nil {
+		return nil
+	}
+	tools := mcpToolNames(reg)
+	notes := mcpToolChanges(m.env.MCPTools, tools)
+	m.env.MCPTools = tools
+	m.services.Agent.SetMCPTools(m.mcpCoreTools())
+
+	cmds := []tea.Cmd{waitMCPToolsChanged(msg.changed)}
+	if len(notes) > 0 {
+		m.conv.AddNotice(strings.Join(notes, "\n"))
+		cmds = append(cmds, m.CommitMessages()...)
+	}
+	return tea.Batch(cmds...)
+}
+
+// mcpToolNames lists the tool names of each connected server.
+func mcpToolNames(reg *mcp.Registry) map[string][]string {
+	tools := make(map[string][]string)
+	for _, server := range reg.List() {
+		if server.Status != mcp.StatusConnected {
+			continue
+		}
+		names := make([]string, 0, len(server.Tools))
+		for _, t := range server.Tools {
+			names = append(names, t.Name)
+		}
+		tools[server.Config.Name] = names
+	}
+	return tools
+}
+
+// mcpToolChanges describes how the tools of servers connected both before
+// and after changed, e.g. "MCP server docs added 2 tools, removed 1 tool".
+func mcpToolChanges(before, after map[string][]string) []string {
+	servers := make([]string, 0, len(after))
+	for name := range after {
+		if _, ok := before[name]; ok {
+			servers = append(servers, name)
+		}
+	}
+	sort.Strings(servers)
+
+	var notes []string
+	for _, name := range servers {
+		var added, removed int
+		for _, t := range after[name] {
+			if !slices.Contains(before[name], t) {
+				added++
+			}
+		}
+		for _, t := range before[name] {
+			if !slices.Contains(after[name], t) {
+				removed++
+			}
+		}
+		var parts []string
+		if added > 0 {
+			parts = append(parts, "added "+pluralTools(added))
+		}
+		if removed > 0 {
+			parts = append(parts, "removed "+pluralTools(removed))
+		}
+		if len(parts) > 0 {
+			notes = append(notes, fmt.Sprintf("MCP server %s %s", name, strings.Join(parts, ", ")))
+		}
+	}
+	return notes
+}
+
+func pluralTools(n int) string {
+	if n == 1 {
+		return "1 tool"
+	}
+	return fmt.Sprintf("%d tools", n)
+}
@@ -0,0 +1,24 @@
+package app
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMCPToolChanges(t *testing.T) {
+	before := map[string][]string{
+		"docs":   {"search", "fetch"},
+		"git":    {"status"},
+		"leaves": {"x"},
+	}
+	after := map[string][]string{
+		"docs": {"search", "read", "list"},
+		"git":  {"status"},
+		"new":  {"a", "b"},
+	}
+	got := mcpToolChanges(before, after)
+	want := []string{"MCP server docs added 2 tools, removed 1 tool"}
+	if !slices.Equal(got, want) {
+		t.Errorf("mcpToolChanges() = %q, want %q", got, want)
+	}
+}
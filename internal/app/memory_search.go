@@ -0,0 +1,125 @@
+package app
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+
+	"github.com/yanmxa/gencode/internal/agent"
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/core/system"
+	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/log"
+)
+
+const (
+	// semanticMemoryMinChars is the total memory size below which every file
+	// is included as-is; smaller memory isn't worth narrowing.
+	semanticMemoryMinChars = 16000
+	// semanticMemoryChunkChars caps the size of each embedded section.
+	semanticMemoryChunkChars = 2000
+	// semanticMemoryTopK is how many sections are kept per prompt.
+	semanticMemoryTopK = 8
+	// semanticMemoryTimeout bounds indexing plus the per-prompt query embedding.
+	semanticMemoryTimeout = 15 * time.Second
+)
+
+// memoryIndex holds embeddings for the sections of the loaded memory files.
+// Sections are embedded lazily on the first prompt and reused until memory
+// is reloaded.
+type memoryIndex struct {
+	mu       sync.Mutex
+	chunks   []system.MemoryChunk
+	vectors  [][]float32
+	provider llm.Provider
+}
+
+func newMemoryIndex(files []system.MemoryFile) *memoryIndex {
+	size := 0
+	for _, f := range files {
+		size += len(f.Content)
+	}
+	if size < semanticMemoryMinChars {
+		return nil
+	}
+	return &memoryIndex{chunks: system.ChunkMemoryFiles(files, semanticMemoryChunkChars)}
+}
+
+// relevant returns the user and project instructions made of the sections
+// most similar to query, in their original file order. find is only called
+// the first time, to pick the provider that embeds the sections.
+func (ix *memoryIndex) relevant(ctx context.Context, query string, find func(context.Context) (llm.Provider, error)) (user, project string, err error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if ix.vectors == nil {
+		p, err := find(ctx)
+		if err != nil {
+			return "", "", err
+		}
+		texts := make([]string, len(ix.chunks))
+		for i, c := range ix.chunks {
+			texts[i] = c.Content
+		}
+		vectors, err := llm.GetEmbeddings(ctx, p, texts)
+		if err != nil {
+			return "", "", err
+		}
+		ix.provider, ix.vectors = p, vectors
+	}
+
+	q, err := llm.GetEmbeddings(ctx, ix.provider, []string{query})
+	if err != nil {
+		return "", "", err
+	}
+	matches := llm.RankBySimilarity(q[0], ix.vectors, semanticMemoryTopK)
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Index < matches[j].Index })
+
+	selected := make([]system.MemoryChunk, len(matches))
+	for i, m := range matches {
+		selected[i] = ix.chunks[m.Index]
+	}
+	user, project = system.JoinMemoryChunks(selected)
+	return user, project, nil
+}
+
+// sendWithRelevantMemory narrows the agent's instructions to the memory
+// sections relevant to content before sending it. Without semantic memory
+// enabled, or when memory is small, it is the same as sendToAgent. Any
+// embedding failure falls back to the full instructions.
+func (m *model) sendWithRelevantMemory(content string, images []core.Image) tea.Cmd {
+	if !m.services.Agent.Active() {
+		return nil
+	}
+	ix := m.env.MemoryIndex
+	if ix == nil || m.services.Setting == nil || !m.services.Setting.SemanticMemory() {
+		return m.sendToAgent(content, images)
+	}
+
+	svc := m.services.Agent
+	fullUser, fullProject := m.env.CachedUserInstructions, m.env.CachedProjectInstructions
+	current, store := m.env.LLMProvider, m.services.LLM.Store()
+	find := func(ctx context.Context) (llm.Provider, error) {
+		return llm.FindEmbeddingProvider(ctx, current, store)
+	}
+	return func() tea.Msg {
+		applyRelevantMemory(svc, ix, content, find, fullUser, fullProject)
+		svc.Send(content, images)
+		return nil
+	}
+}
+
+func applyRelevantMemory(svc agent.Service, ix *memoryIndex, query string, find func(context.Context) (llm.Provider, error), fullUser, fullProject string) {
+	ctx, cancel := context.WithTimeout(context.Background(), semanticMemoryTimeout)
+	defer cancel()
+	user, project, err := ix.relevant(ctx, query, find)
+	if err != nil {
+		log.Logger().Debug("Semantic memory unavailable, using full instructions", zap.Error(err))
+		user, project = fullUser, fullProject
+	}
+	svc.SetInstructions(user, project)
+}
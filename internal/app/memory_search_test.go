@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/core/system"
+	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/llm/fake"
+)
+
+func TestNewMemoryIndexSkipsSmallMemory(t *testing.T) {
+	small := []system.MemoryFile{{Path: "GEN.md", Level: "project", Content: "# Build\nRun make."}}
+	if ix := newMemoryIndex(small); ix != nil {
+		t.Fatal("small memory should be included in full, not indexed")
+	}
+}
+
+func TestMemoryIndexRelevantKeepsMatchingSections(t *testing.T) {
+	filler := strings.Repeat("unrelated filler words about nothing in particular. ", 40)
+	var content strings.Builder
+	content.WriteString("# Testing\nrun go test with the race detector before pushing\n\n")
+	for i := 0; i < 20; i++ {
+		content.WriteString("# Notes\n" + filler + "\n\n")
+	}
+	files := []system.MemoryFile{
+		{Path: "~/.gen/GEN.md", Level: "global", Content: "# Style\nprefer short commit messages"},
+		{Path: "GEN.md", Level: "project", Content: content.String()},
+	}
+	ix := newMemoryIndex(files)
+	if ix == nil {
+		t.Fatal("large memory should be indexed")
+	}
+
+	calls := 0
+	find := func(context.Context) (llm.Provider, error) {
+		calls++
+		return fake.New(), nil
+	}
+	_, project, err := ix.relevant(context.Background(), "how do I run go test", find)
+	if err != nil {
+		t.Fatalf("relevant() error = %v", err)
+	}
+	if !strings.HasPrefix(project, "# Testing") {
+		t.Errorf("project instructions should start with the testing section, got %q", project[:min(len(project), 60)])
+	}
+	if len(project) >= len(content.String()) {
+		t.Errorf("project instructions were not narrowed: %d >= %d chars", len(project), len(content.String()))
+	}
+
+	if _, _, err := ix.relevant(context.Background(), "commit style", find); err != nil {
+		t.Fatalf("second relevant() error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("sections should be embedded once, provider lookups = %d", calls)
+	}
+}
+
+func TestMemoryIndexRelevantReportsMissingProvider(t *testing.T) {
+	ix := &memoryIndex{chunks: []system.MemoryChunk{{Level: "project", Content: "x"}}}
+	find := func(context.Context) (llm.Provider, error) { return nil, llm.ErrEmbeddingsUnsupported }
+	if _, _, err := ix.relevant(context.Background(), "q", find); !errors.Is(err, llm.ErrEmbeddingsUnsupported) {
+		t.Errorf("relevant() error = %v, want ErrEmbeddingsUnsupported", err)
+	}
+}
@@ -22,12 +22,12 @@ import (
 	"github.com/yanmxa/gencode/internal/app/input"
 	"github.com/yanmxa/gencode/internal/app/kit"
 	"github.com/yanmxa/gencode/internal/app/trigger"
+	"github.com/yanmxa/gencode/internal/changelog"
 	"github.com/yanmxa/gencode/internal/command"
 	"github.com/yanmxa/gencode/internal/core"
 	"github.com/yanmxa/gencode/internal/filecache"
 	"github.com/yanmxa/gencode/internal/hook"
 	"github.com/yanmxa/gencode/internal/llm"
-	"github.com/yanmxa/gencode/internal/llm/minmax"
 	"github.com/yanmxa/gencode/internal/log"
 	"github.com/yanmxa/gencode/internal/mcp"
 	"github.com/yanmxa/gencode/internal/plugin"
@@ -38,6 +38,8 @@ import (
 	"github.com/yanmxa/gencode/internal/task"
 	"github.com/yanmxa/gencode/internal/task/tracker"
 	"github.com/yanmxa/gencode/internal/tool"
+	"github.com/yanmxa/gencode/internal/tool/scratchpad"
+	"github.com/yanmxa/gencode/internal/undo"
 )
 
 const defaultWidth = 80
@@ -64,12 +66,18 @@ var (
 )
 
 func (m *model) Init() tea.Cmd {
+	mcpConnect := m.userInput.MCP.Selector.AutoConnect(m.noTelemetry())
+	if m.env.WorkspaceMCP != nil {
+		mcpConnect = m.userInput.MCP.Selector.ConnectOnly(m.env.WorkspaceMCP, m.noTelemetry())
+	}
 	cmds := []tea.Cmd{
 		textarea.Blink,
-		m.userInput.MCP.Selector.AutoConnect(),
+		mcpConnect,
 		trigger.TriggerCronTickNow(),
 		trigger.StartCronTicker(),
 		trigger.StartAsyncHookTicker(),
+		pollSamplingConsent(),
+		m.watchMCPTools(),
 	}
 	if m.env.InitialPrompt != "" {
 		prompt := m.env.InitialPrompt
@@ -95,7 +103,17 @@ func newModel(opts setting.RunOptions) (*model, error) {
 	}
 	m.wireTaskLifecycle(hookEngine)
 
+	m.env.WrapWidth = m.services.Setting.WrapWidth()
+	m.applyKeybindings()
+	m.applyDefaultModel()
+	if entry := m.services.Setting.ProtectedPath(m.env.CWD); entry != "" {
+		m.env.EnterProtectedDir(entry)
+	} else {
+		m.applyDefaultMode()
+	}
 	m.configureAsyncHookCallback()
+	m.syncSamplingCompleter()
+	samplingGate.EnablePrompts()
 	m.ensureMemoryContextLoaded()
 	m.ReconfigureAgentTool()
 	m.InitTaskStorage()
@@ -116,6 +134,8 @@ func newBaseModel() model {
 			Setting:        svc.Setting,
 			LoadDisabled:   svc.Setting.GetDisabledToolsAt,
 			UpdateDisabled: svc.Setting.UpdateDisabledToolsAt,
+			LoadSettingsAt: svc.Setting.SettingsAt,
+			SaveSettingAt:  svc.Setting.SetAt,
 		}),
 		conv:        conv.NewModel(defaultWidth),
 		eventHub:    hub.New(),
@@ -140,6 +160,8 @@ func (m *model) applyRunOptions(opts setting.RunOptions) error {
 	if opts.Prompt != "" {
 		m.env.InitialPrompt = opts.Prompt
 	}
+	m.env.MaxTokens = opts.MaxTokens
+	m.env.Temperature = opts.Temperature
 
 	if opts.Continue {
 		if err := m.applyContinueOption(); err != nil {
@@ -148,11 +170,28 @@ func (m *model) applyRunOptions(opts setting.RunOptions) error {
 	}
 
 	if opts.Resume {
-		if err := m.applyResumeOption(opts.ResumeID); err != nil {
+		if err := m.applyResumeOption(opts.ResumeID, opts.ResumeFile); err != nil {
+			return err
+		}
+	}
+
+	if opts.Workspace != "" {
+		if _, err := m.applyWorkspace(opts.Workspace); err != nil {
 			return err
 		}
 	}
 
+	// --provider/--model win over settings and the workspace, and are not
+	// saved as the current model.
+	if opts.Provider != "" || opts.Model != "" {
+		p, current, err := m.resolveWorkspaceModel(setting.Workspace{Provider: opts.Provider, Model: opts.Model})
+		if err != nil {
+			return err
+		}
+		m.env.CurrentModel = current
+		m.switchProvider(p)
+	}
+
 	return nil
 }
 
@@ -160,11 +199,12 @@ func (m *model) ReloadPluginBackedState() error {
 	skill.Initialize(skill.Options{CWD: m.env.CWD})
 	command.Initialize(command.Options{
 		CWD:                m.env.CWD,
-		DynamicProviders:   []func() []command.Info{skillCommandInfos},
+		DynamicProviders:   []func() []command.Info{skillCommandInfos, input.MCPPromptCommandInfos},
 		PluginCommandPaths: pluginCommandPaths,
 	})
 	subagent.Initialize(subagent.Options{CWD: m.env.CWD, PluginAgentPaths: pluginAgentPaths})
-	mcp.Initialize(mcp.Options{CWD: m.env.CWD, PluginServers: pluginMCPServers})
+	mcp.Initialize(mcp.Options{CWD: m.env.CWD, PluginServers: pluginMCPServers, Sampling: samplingGate.Handle})
+	registerPluginTools()
 	setting.Initialize(setting.Options{CWD: m.env.CWD})
 
 	m.services.refreshAfterReload()
@@ -192,11 +232,26 @@ func (m *model) applyContinueOption() error {
 	return nil
 }
 
-func (m *model) applyResumeOption(resumeID string) error {
+func (m *model) applyResumeOption(resumeID, resumeFile string) error {
 	if err := m.services.Session.EnsureStore(m.env.CWD); err != nil {
 		return fmt.Errorf("failed to initialize session store: %w", err)
 	}
 
+	if resumeFile != "" {
+		sess, err := session.LoadFile(resumeFile)
+		if err != nil {
+			return fmt.Errorf("failed to load session file %s: %w", resumeFile, err)
+		}
+		// Import as a new session so saving never overwrites a stored
+		// session that happens to share the file's ID.
+		imported, err := m.services.Session.ForkSnapshot(sess)
+		if err != nil {
+			return fmt.Errorf("failed to import session file %s: %w", resumeFile, err)
+		}
+		m.restoreSessionData(imported)
+		return nil
+	}
+
 	if resumeID != "" {
 		sess, err := m.services.Session.Load(resumeID)
 		if err != nil {
@@ -286,7 +341,27 @@ func (m *model) PersistSession() error {
 		return nil
 	}
 
-	entries := session.ConvertToEntries(m.conv.Messages)
+	sess := m.sessionSnapshot(m.conv.Messages)
+	if err := m.services.Session.Save(sess); err != nil {
+		return err
+	}
+
+	m.services.Session.SetID(sess.Metadata.ID)
+	m.conv.PersistedCount = len(m.conv.Messages)
+	m.initTaskStorage(m.services.Session.ID())
+
+	if m.services.Hook != nil {
+		m.services.Hook.SetTranscriptPath(m.services.Session.GetStore().SessionPath(sess.Metadata.ID))
+	}
+	m.ReconfigureAgentTool()
+
+	return nil
+}
+
+// sessionSnapshot captures messages with the current model, token counters,
+// and tasks for saving to the session store.
+func (m *model) sessionSnapshot(messages []core.ChatMessage) *session.Snapshot {
+	entries := session.ConvertToEntries(redactUserMessages(messages, m.historyRedactor()))
 
 	var providerName, modelID string
 	if m.env.CurrentModel != nil {
@@ -294,39 +369,83 @@ func (m *model) PersistSession() error {
 		modelID = m.env.CurrentModel.ModelID
 	}
 
-	sess := &session.Snapshot{
+	return &session.Snapshot{
 		Metadata: session.SessionMetadata{
-			ID:         m.services.Session.ID(),
-			Provider:   providerName,
-			Model:      modelID,
-			Cwd:        m.env.CWD,
-			LastPrompt: session.ExtractLastUserText(entries),
-			Mode:       m.env.SessionMode(),
+			ID:           m.services.Session.ID(),
+			Title:        session.GenerateTitle(entries),
+			CustomTitle:  m.services.Session.Title(),
+			Provider:     providerName,
+			Model:        modelID,
+			Cwd:          m.env.CWD,
+			LastPrompt:   session.ExtractLastUserText(entries),
+			Mode:         m.env.SessionMode(),
+			InputTokens:  m.env.InputTokens,
+			OutputTokens: m.env.OutputTokens,
 		},
 		Entries: entries,
 		Tasks:   m.services.Tracker.Export(),
 	}
+}
 
-	if sess.Metadata.Title == "" || sess.Metadata.ID == "" {
-		sess.Metadata.Title = session.GenerateTitle(sess.Entries)
+func (m *model) historyRedactor() *setting.HistoryRedactor {
+	if m.services.Setting == nil {
+		return nil
 	}
+	return m.services.Setting.HistoryRedactor()
+}
 
-	if err := m.services.Session.Save(sess); err != nil {
-		return err
+// redactUserMessages returns msgs with every typed user message that r
+// matches replaced by setting.RedactedText. The conversation itself is left
+// untouched, so the model still sees the original for the rest of the session.
+func redactUserMessages(msgs []core.ChatMessage, r *setting.HistoryRedactor) []core.ChatMessage {
+	if r == nil {
+		return msgs
+	}
+	var out []core.ChatMessage
+	for i, msg := range msgs {
+		if msg.Role != core.RoleUser || msg.ToolResult != nil || !(r.Matches(msg.Content) || r.Matches(msg.DisplayContent)) {
+			continue
+		}
+		if out == nil {
+			out = append([]core.ChatMessage(nil), msgs...)
+		}
+		out[i].Content = setting.RedactedText
+		out[i].DisplayContent = ""
 	}
+	if out == nil {
+		return msgs
+	}
+	return out
+}
 
-	m.services.Session.SetID(sess.Metadata.ID)
-	m.initTaskStorage(m.services.Session.ID())
+type persistSessionDoneMsg struct{ err error }
 
-	if m.services.Hook != nil {
-		m.services.Hook.SetTranscriptPath(m.services.Session.GetStore().SessionPath(sess.Metadata.ID))
+// noTelemetry reports whether startup network activity should be suppressed.
+func (m *model) noTelemetry() bool {
+	if m.services.Setting == nil {
+		return os.Getenv(setting.NoTelemetryEnv) == "1"
 	}
-	m.ReconfigureAgentTool()
+	return m.services.Setting.NoTelemetry()
+}
 
-	return nil
+// isSessionSaved reports whether every message in the conversation has been
+// written to the session store.
+func (m *model) isSessionSaved() bool {
+	return m.services.Session.ID() != "" && m.conv.PersistedCount == len(m.conv.Messages)
 }
 
-type persistSessionDoneMsg struct{ err error }
+// autoSaveSessionCmd saves the session once a turn is committed, so that
+// --continue can pick it up even if this run never exits cleanly. The first
+// save runs synchronously because it establishes the session ID.
+func (m *model) autoSaveSessionCmd() tea.Cmd {
+	if m.services.Session.ID() != "" {
+		return m.persistSessionCmd()
+	}
+	if err := m.PersistSession(); err != nil {
+		log.Logger().Warn("failed to save session", zap.Error(err))
+	}
+	return nil
+}
 
 // Only safe when the session ID is already established (i.e. not the first save).
 func (m *model) persistSessionCmd() tea.Cmd {
@@ -338,32 +457,10 @@ func (m *model) persistSessionCmd() tea.Cmd {
 		return nil
 	}
 
-	entries := session.ConvertToEntries(m.conv.Messages)
-
-	var providerName, modelID string
-	if m.env.CurrentModel != nil {
-		providerName = string(m.env.CurrentModel.Provider)
-		modelID = m.env.CurrentModel.ModelID
-	}
-
-	sess := &session.Snapshot{
-		Metadata: session.SessionMetadata{
-			ID:         m.services.Session.ID(),
-			Provider:   providerName,
-			Model:      modelID,
-			Cwd:        m.env.CWD,
-			LastPrompt: session.ExtractLastUserText(entries),
-			Mode:       m.env.SessionMode(),
-		},
-		Entries: entries,
-		Tasks:   m.services.Tracker.Export(),
-	}
-
-	if sess.Metadata.Title == "" {
-		sess.Metadata.Title = session.GenerateTitle(sess.Entries)
-	}
+	sess := m.sessionSnapshot(m.conv.Messages)
 
 	store := m.services.Session.GetStore()
+	m.conv.PersistedCount = len(m.conv.Messages)
 	return func() tea.Msg {
 		if store == nil {
 			return persistSessionDoneMsg{err: fmt.Errorf("no session store")}
@@ -384,21 +481,23 @@ func (m *model) loadSessionByID(id string) error {
 
 	m.services.Tracker.SetStorageDir("")
 	m.restoreSessionData(sess)
+	scratchpad.Reset(scratchpad.Main)
 
 	if len(sess.Tasks) == 0 {
 		m.services.Tracker.Reset()
 	}
 	m.services.Tool.ResetFetched()
 
-	m.env.InputTokens = 0
-	m.env.OutputTokens = 0
-
 	return nil
 }
 
 func (m *model) restoreSessionData(sess *session.Snapshot) {
 	m.conv.Messages = session.ConvertFromEntries(sess.Entries)
+	m.conv.PersistedCount = len(m.conv.Messages)
 	m.services.Session.SetID(sess.Metadata.ID)
+	m.services.Session.SetTitle(sess.Metadata.CustomTitle)
+	m.env.InputTokens = sess.Metadata.InputTokens
+	m.env.OutputTokens = sess.Metadata.OutputTokens
 
 	m.initTaskStorage(m.services.Session.ID())
 
@@ -458,24 +557,27 @@ func (m *model) SetTokenUsage(resp *core.InferResponse) {
 
 	// Bottom-right context usage reflects the latest prompt/output, not a
 	// lifetime sum across the whole session.
-	m.env.InputTokens = resp.TokensIn
+	m.env.InputTokens = resp.PromptTokens()
 	m.env.OutputTokens = resp.TokensOut
-	m.env.TurnInputTokens += resp.TokensIn
+	m.env.TurnInputTokens += resp.PromptTokens()
 	m.env.TurnOutputTokens += resp.TokensOut
 
 	if m.env.CurrentModel != nil {
-		switch m.env.CurrentModel.Provider {
-		case llm.MinMax:
-			cost, ok := minmax.EstimateCost(m.env.CurrentModel.ModelID, llm.Usage{
-				InputTokens:              resp.TokensIn,
-				OutputTokens:             resp.TokensOut,
-				CacheCreationInputTokens: resp.CacheCreateTokens,
-				CacheReadInputTokens:     resp.CacheReadTokens,
-			})
-			if ok {
-				m.env.ConversationCost = m.env.ConversationCost.Add(cost)
-			}
+		modelID := m.env.CurrentModel.ModelID
+		var store *llm.Store
+		if m.services.LLM != nil {
+			store = m.services.LLM.Store()
 		}
+		var pricing *llm.Pricing
+		if p, ok := llm.LookupPricing(store, modelID); ok {
+			pricing = &p
+		}
+		m.env.Spend.Record(m.env.CurrentModel.Provider, modelID, llm.Usage{
+			InputTokens:              resp.TokensIn,
+			OutputTokens:             resp.TokensOut,
+			CacheCreationInputTokens: resp.CacheCreateTokens,
+			CacheReadInputTokens:     resp.CacheReadTokens,
+		}, pricing)
 	}
 }
 
@@ -511,6 +613,9 @@ func (m *model) ProcessTurnEnd(result core.Result) tea.Cmd {
 		if cmd != nil {
 			commitCmds = append(commitCmds, cmd)
 		}
+		if cmd := m.autoSaveSessionCmd(); cmd != nil {
+			commitCmds = append(commitCmds, cmd)
+		}
 		commitCmds = append(commitCmds, m.ContinueOutbox())
 		return tea.Batch(commitCmds...)
 	}
@@ -525,13 +630,16 @@ func (m *model) ProcessAgentStop(err error) tea.Cmd {
 	// /clear and manual stop cancel the active agent context; that is expected
 	// shutdown, not an agent failure the user needs to see.
 	if err != nil && !errors.Is(err, context.Canceled) {
-		m.conv.AddNotice(fmt.Sprintf("Agent error: %v", err))
+		m.conv.AddNotice(m.agentErrorNotice(err))
 		m.fireStopFailureHook(core.LastAssistantChatContent(m.conv.Messages), err)
 	}
 	m.conv.ProgressHub.DrainPendingQuestions()
 	m.conv.Modal.Question.Hide()
 	commitCmds := m.CommitMessages()
 	m.StopAgentSession()
+	if cmd := m.autoSaveSessionCmd(); cmd != nil {
+		commitCmds = append(commitCmds, cmd)
+	}
 	return tea.Batch(commitCmds...)
 }
 
@@ -553,13 +661,41 @@ func (m *model) HandleAgentCompact(info core.CompactInfo) tea.Cmd {
 	return tea.Batch(scrollPart, m.ContinueOutbox(), kit.StatusTimer(3*time.Second, token))
 }
 
-// HandleCompactResult handles manual /compact results.
-// Stops the agent so the next user message restarts it with compacted messages.
+// HandleCompactResult handles manual /compact results by opening a preview;
+// the summary only replaces history once the user accepts it.
 func (m *model) HandleCompactResult(msg conv.CompactResultMsg) tea.Cmd {
 	if msg.Error != nil {
 		m.conv.Compact.Complete(fmt.Sprintf("Compaction could not be completed: %v", msg.Error), true)
 		return tea.Batch(m.CommitMessages()...)
 	}
+	if msg.Trigger != "manual" {
+		return m.applyCompactResult(msg)
+	}
+	m.conv.Compact.AwaitReview()
+	m.userInput.CompactPreview.Enter(msg, m.env.Width, m.env.Height)
+	return nil
+}
+
+// handleCompactPreview applies, regenerates, or discards a previewed summary.
+func (m *model) handleCompactPreview(msg input.CompactPreviewMsg) tea.Cmd {
+	switch msg.Action {
+	case input.CompactPreviewAccept:
+		return m.applyCompactResult(msg.Result)
+	case input.CompactPreviewRegenerate:
+		m.conv.Compact.Active = true
+		m.conv.Compact.Focus = msg.Focus
+		m.conv.Compact.Phase = conv.PhaseSummarizing
+		return tea.Batch(m.SpinnerTickCmd(), conv.CompactCmd(m.BuildCompactRequest(msg.Focus, "manual")))
+	default:
+		m.conv.Compact.Reset()
+		m.conv.AddNotice("Compaction cancelled — conversation history unchanged.")
+		return tea.Batch(m.CommitMessages()...)
+	}
+}
+
+// applyCompactResult replaces history with the summary.
+// Stops the agent so the next user message restarts it with compacted messages.
+func (m *model) applyCompactResult(msg conv.CompactResultMsg) tea.Cmd {
 	m.conv.Compact.Complete(fmt.Sprintf("Condensed %d earlier messages.", msg.OriginalCount), false)
 	scrollbackCmds := m.commitAllMessages()
 	boundaryStyle := lipgloss.NewStyle().Foreground(kit.CurrentTheme.Muted)
@@ -624,12 +760,18 @@ func (m *model) applyToolSideEffects(toolName string, sideEffect any) {
 		if restoredPath := kit.MapString(resp, "restoredPath"); restoredPath != "" {
 			m.changeCwd(restoredPath)
 		}
-	case "Write", "Edit":
+	case "Write", "Edit", "MultiEdit":
 		if filePath := kit.MapString(resp, "filePath"); filePath != "" {
 			m.fireFileChanged(filePath, toolName)
 			if m.env.FileCache != nil {
 				m.env.FileCache.Touch(filePath)
 			}
+			if change, ok := changelog.FromToolResponse(toolName, resp); ok && m.env.Changes != nil {
+				m.env.Changes.Record(change)
+			}
+			if snap, ok := undo.FromToolResponse(toolName, resp); ok && m.env.Undo != nil {
+				m.env.Undo.Push(snap)
+			}
 		}
 	case "Read":
 		if fileData, ok := resp["file"].(map[string]any); ok {
@@ -750,12 +892,8 @@ func (m *model) handleStopHookResult(msg stopHookResultMsg) tea.Cmd {
 	}
 	log.QueueLog("handleStopHookResult: hooks done, persisting")
 	var cmds []tea.Cmd
-	if m.services.Session.ID() != "" {
-		cmds = append(cmds, m.persistSessionCmd())
-	} else {
-		if err := m.PersistSession(); err != nil {
-			log.Logger().Warn("failed to save session", zap.Error(err))
-		}
+	if cmd := m.autoSaveSessionCmd(); cmd != nil {
+		cmds = append(cmds, cmd)
 	}
 	if cmd := input.StartPromptSuggestion(m.promptSuggestionDeps()); cmd != nil {
 		cmds = append(cmds, cmd)
@@ -948,12 +1086,15 @@ func (m *model) overlayDeps() input.OverlayDeps {
 		},
 		SetCurrentModel: func(info *llm.CurrentModelInfo) {
 			m.env.CurrentModel = info
+			m.syncSamplingCompleter()
+			m.resizeTextarea()
 		},
 		ClearCachedInstructions: m.env.ClearCachedInstructions,
 		RefreshMemoryContext:    m.refreshMemoryContext,
 		FireFileChanged:         m.fireFileChanged,
 		ReloadPluginState:       m.ReloadPluginBackedState,
 		LoadSession:             m.loadSessionByID,
+		SaveSettingAt:           m.services.Setting.SetAt,
 	}
 }
 
@@ -994,6 +1135,40 @@ func (m *model) forkSession() (string, error) {
 	return originalID, nil
 }
 
+// branchSession saves the first keep messages as a new session whose parent
+// is the current one, then continues in it. The current session is saved
+// first and is left intact on disk.
+func (m *model) branchSession(keep int) (string, tea.Cmd, error) {
+	if m.conv.Stream.Active {
+		return "", nil, fmt.Errorf("wait for the current response to finish")
+	}
+	if keep <= 0 || keep > len(m.conv.Messages) {
+		return "", nil, fmt.Errorf("invalid branch point")
+	}
+	if err := m.PersistSession(); err != nil {
+		return "", nil, fmt.Errorf("failed to save session before branch: %w", err)
+	}
+	originalID := m.services.Session.ID()
+	branched, err := m.services.Session.ForkSnapshot(m.sessionSnapshot(m.conv.Messages[:keep]))
+	if err != nil {
+		return "", nil, err
+	}
+
+	m.conv.Messages = m.conv.Messages[:keep]
+	m.conv.CommittedCount = min(m.conv.CommittedCount, keep)
+	m.conv.PersistedCount = keep
+	m.services.Agent.SetMessages(m.conv.ConvertToProvider())
+
+	m.services.Session.SetID(branched.Metadata.ID)
+	m.services.Tracker.SetStorageDir("")
+	m.initTaskStorage(branched.Metadata.ID)
+	if m.services.Hook != nil {
+		m.services.Hook.SetTranscriptPath(m.services.Session.GetStore().SessionPath(branched.Metadata.ID))
+	}
+	m.ReconfigureAgentTool()
+	return originalID, m.reflowScrollback(), nil
+}
+
 func (m *model) FireSessionEnd(reason string) {
 	if m.services.Hook != nil {
 		m.services.Hook.Execute(context.Background(), hook.SessionEnd, hook.HookInput{
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/session"
+	"github.com/yanmxa/gencode/internal/setting"
+)
+
+func TestRedactUserMessagesForPersistence(t *testing.T) {
+	r := setting.NewHistoryRedactor([]string{`sk-[a-z]{8}`})
+	msgs := []core.ChatMessage{
+		{Role: core.RoleUser, Content: "deploy with sk-abcdefgh"},
+		{Role: core.RoleAssistant, Content: "Deploying with sk-abcdefgh"},
+		{Role: core.RoleUser, ToolResult: &core.ToolResult{ToolCallID: "1", Content: "sk-abcdefgh"}},
+		{Role: core.RoleUser, Content: "thanks"},
+	}
+
+	got := redactUserMessages(msgs, r)
+	if got[0].Content != setting.RedactedText {
+		t.Errorf("matching user message = %q, want %q", got[0].Content, setting.RedactedText)
+	}
+	if got[1].Content != msgs[1].Content || got[2].ToolResult.Content != "sk-abcdefgh" || got[3].Content != "thanks" {
+		t.Error("only typed user messages should be redacted")
+	}
+	if msgs[0].Content != "deploy with sk-abcdefgh" {
+		t.Error("the live conversation must keep the original message")
+	}
+
+	entries := session.ConvertToEntries(got)
+	if last := session.ExtractLastUserText(entries[:1]); last != setting.RedactedText {
+		t.Errorf("saved user entry = %q, want %q", last, setting.RedactedText)
+	}
+
+	if out := redactUserMessages(msgs, nil); &out[0] != &msgs[0] {
+		t.Error("without patterns the messages should be returned as-is")
+	}
+}
@@ -29,6 +29,18 @@ func TestSetTokenUsageTracksLatestTurnUsage(t *testing.T) {
 	}
 }
 
+func TestSetTokenUsageCountsCachedPromptTokens(t *testing.T) {
+	m := &model{}
+	m.BeginInferTurn()
+
+	// With prompt caching most of the prompt is reported as cache reads;
+	// the context display must still show the whole prompt.
+	m.SetTokenUsage(&core.InferResponse{TokensIn: 300, CacheReadTokens: 50_000, CacheCreateTokens: 1_200, TokensOut: 40})
+	if m.env.InputTokens != 51_500 || m.env.TurnInputTokens != 51_500 {
+		t.Fatalf("input tokens = %d (turn %d), want 51500 including cached tokens", m.env.InputTokens, m.env.TurnInputTokens)
+	}
+}
+
 func TestResumeCommandForSessionRequiresPersistedTranscript(t *testing.T) {
 	transcriptPath := filepath.Join(t.TempDir(), "session.jsonl")
 
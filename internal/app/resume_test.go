@@ -0,0 +1,66 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/agent"
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/session"
+	"github.com/yanmxa/gencode/internal/task"
+	"github.com/yanmxa/gencode/internal/task/tracker"
+)
+
+func TestResumeFileImportsAsNewSession(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	agent.Initialize(agent.Options{})
+	t.Cleanup(agent.ResetService)
+
+	store, err := session.NewStoreWithDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := &session.Snapshot{
+		Metadata: session.SessionMetadata{ID: "sess-1"},
+		Entries: session.ConvertToEntries([]core.ChatMessage{
+			{Role: core.RoleUser, Content: "original question"},
+			{Role: core.RoleAssistant, Content: "original answer"},
+		}),
+	}
+	if err := store.Save(stored); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &model{}
+	m.services.Agent = agent.Default()
+	m.services.Tracker = tracker.NewStore()
+	m.services.Task = task.NewManager()
+	m.services.Session = &session.Setup{Store: store}
+	m.env.CWD = t.TempDir()
+
+	// The file is the stored session's own transcript, so the IDs match.
+	if err := m.applyResumeOption("", store.SessionPath("sess-1")); err != nil {
+		t.Fatalf("applyResumeOption() error: %v", err)
+	}
+	if id := m.services.Session.ID(); id == "" || id == "sess-1" {
+		t.Fatalf("imported session ID = %q, want a fresh ID", id)
+	}
+	if len(m.conv.Messages) != 2 {
+		t.Fatalf("imported %d messages, want 2", len(m.conv.Messages))
+	}
+
+	m.conv.Messages = append(m.conv.Messages,
+		core.ChatMessage{Role: core.RoleUser, Content: "follow-up"},
+		core.ChatMessage{Role: core.RoleAssistant, Content: "more"},
+	)
+	if err := m.PersistSession(); err != nil {
+		t.Fatalf("PersistSession() error: %v", err)
+	}
+
+	original, err := store.Load("sess-1")
+	if err != nil {
+		t.Fatalf("stored session lost: %v", err)
+	}
+	if got := session.ConvertFromEntries(original.Entries); len(got) != 2 || got[0].Content != "original question" {
+		t.Errorf("stored session changed: %+v", got)
+	}
+}
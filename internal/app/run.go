@@ -2,25 +2,38 @@
 package app
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/yanmxa/gencode/internal/app/kit"
 	"github.com/yanmxa/gencode/internal/app/trigger"
+	"github.com/yanmxa/gencode/internal/changelog"
 	"github.com/yanmxa/gencode/internal/core"
 	"github.com/yanmxa/gencode/internal/hook"
 	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/search"
 	"github.com/yanmxa/gencode/internal/setting"
+	"github.com/yanmxa/gencode/internal/task"
 	"github.com/yanmxa/gencode/internal/tool"
+	"github.com/yanmxa/gencode/internal/tool/perm"
 )
 
 // Run routes to either print mode or interactive TUI.
 func Run(opts setting.RunOptions) error {
 	if opts.Print != "" {
-		return runPrint(opts.Print)
+		if cwd, err := os.Getwd(); err == nil {
+			setting.Initialize(setting.Options{CWD: cwd})
+		}
+		configureResponseCache(opts.Cache)
+		configureSearchCache()
+		return runPrint(opts)
 	}
 
 	if userQuit, err := kit.ResolveTheme(setting.LoadTheme(), setting.SaveTheme); userQuit || err != nil {
@@ -31,6 +44,8 @@ func Run(opts setting.RunOptions) error {
 	if err != nil {
 		return err
 	}
+	// Background Bash commands do not outlive the session.
+	defer m.services.Task.Clear(task.TaskTypeBash)
 
 	finalModel, err := tea.NewProgram(m).Run()
 	if err != nil {
@@ -47,6 +62,8 @@ func initModel(opts setting.RunOptions) (*model, error) {
 	if err := initInfrastructure(); err != nil {
 		return nil, err
 	}
+	configureResponseCache(opts.Cache)
+	configureSearchCache()
 	m, err := newModel(opts)
 	if err != nil {
 		return nil, err
@@ -85,10 +102,17 @@ func (m *model) fireStartupHooks() {
 }
 
 func printExitMessage(m *model) {
+	dim := kit.DimStyle()
+	if m.env.Changes != nil {
+		if summary := changelog.Format(m.env.Changes.Files(), m.env.CWD); summary != "" {
+			fmt.Println()
+			fmt.Println(dim.Render(summary))
+		}
+	}
+
 	sessionID := m.services.Session.ID()
 	command := resumeCommandForSession(sessionID, m.services.Session.TranscriptPath())
 	if command != "" {
-		dim := kit.DimStyle()
 		fmt.Println()
 		fmt.Println(dim.Render("Resume this session with:"))
 		fmt.Println(dim.Render(command))
@@ -117,60 +141,252 @@ func formatAsyncHookContinuationContext(result hook.AsyncHookResult, reason stri
 	)
 }
 
-func runPrint(userMessage string) error {
+// configureResponseCache enables the LLM response cache when the
+// responseCache setting or the --cache flag asks for it.
+func configureResponseCache(force bool) {
+	enabled, ttl := false, setting.DefaultResponseCacheTTL
+	if svc := setting.DefaultIfInit(); svc != nil {
+		enabled, ttl = svc.ResponseCache()
+	}
+	if enabled || force {
+		llm.SetResponseCache(llm.DefaultResponseCacheDir(), ttl)
+	}
+}
+
+// configureSearchCache applies the searchCache and searchCacheTTL settings
+// to the web search result cache.
+func configureSearchCache() {
+	persist, ttl := false, setting.DefaultSearchCacheTTL
+	if svc := setting.DefaultIfInit(); svc != nil {
+		persist, ttl = svc.SearchCache()
+	}
+	dir := ""
+	if persist {
+		dir = search.DefaultCacheDir()
+	}
+	search.ConfigureCache(dir, ttl)
+}
+
+// printMaxTurns bounds the model/tool rounds of one print-mode run.
+const printMaxTurns = 50
+
+// runPrint answers opts.Print on stdout. opts.Provider and opts.Model, when
+// set, override the model setting and the stored model for this run only.
+// Nobody can answer a permission prompt, so tool calls are decided up front
+// by opts.PermissionMode (see setting.NewHeadlessPermissions).
+func runPrint(opts setting.RunOptions) error {
 	ctx := context.Background()
 
+	cwd, _ := os.Getwd()
+	perms, err := setting.NewHeadlessPermissions(opts.PermissionMode, cwd)
+	if err != nil {
+		return err
+	}
+
 	store, err := llm.NewStore()
 	if err != nil {
 		return fmt.Errorf("failed to load store: %w", err)
 	}
 
-	var llmProvider llm.Provider
-	var modelID string
+	settingModel := ""
+	if svc := setting.DefaultIfInit(); svc != nil {
+		settingModel = svc.Snapshot().Model
+	}
+	providerName, modelID := printModelChoice(store, opts.Provider, opts.Model, settingModel)
+	llmProvider, modelID, err := resolvePrintModel(ctx, store, providerName, modelID)
+	if err != nil {
+		return err
+	}
+
+	tools := printTools(cwd, perms, cmp.Or(opts.PermissionMode, setting.HeadlessDeny))
 
-	current := store.GetCurrentModel()
-	if current != nil {
-		p, err := llm.GetProvider(ctx, current.Provider, current.AuthMethod)
+	completionOpts := llm.CompletionOptions{
+		Model:        modelID,
+		MaxTokens:    cmp.Or(opts.MaxTokens, setting.DefaultMaxTokens),
+		Temperature:  opts.Temperature,
+		SystemPrompt: setting.DefaultSystemPrompt,
+		Messages:     []core.Message{core.UserMessage(opts.Print, nil)},
+		Tools:        tools.Schemas(),
+	}
+
+	if opts.PermissionMode == setting.HeadlessAcceptAll {
+		fmt.Fprintln(os.Stderr, "WARNING: accept-all lets the model run any command without confirmation")
+	}
+
+	title := kit.NewTitleProgress(printTitleEnabled())
+	defer title.Done()
+
+	for turn := 0; turn < printMaxTurns; turn++ {
+		resp, err := streamPrintTurn(ctx, llmProvider, completionOpts, store, title)
 		if err != nil {
-			return fmt.Errorf("provider %s (%s) not available: %w. Run 'gen' and use /provider to connect",
-				current.Provider, current.AuthMethod, err)
+			return err
 		}
-		llmProvider = p
-		modelID = current.ModelID
-	} else {
-		for providerName, conn := range store.GetConnections() {
-			p, err := llm.GetProvider(ctx, llm.Name(providerName), conn.AuthMethod)
-			if err == nil {
-				llmProvider = p
-				modelID = setting.DefaultModel(providerName, string(conn.AuthMethod))
-				break
+		if resp == nil || len(resp.ToolCalls) == 0 {
+			return nil
+		}
+		assistant := core.AssistantMessage(resp.Content, resp.Thinking, resp.ToolCalls)
+		assistant.ThinkingSignature = resp.ThinkingSignature
+		completionOpts.Messages = append(completionOpts.Messages, assistant)
+		for _, tc := range resp.ToolCalls {
+			result := runPrintTool(ctx, tools, tc)
+			completionOpts.Messages = append(completionOpts.Messages, core.ToolResultMessage(result))
+		}
+	}
+	return fmt.Errorf("stopped after %d turns", printMaxTurns)
+}
+
+// streamPrintTurn streams one model response to stdout and returns it once
+// done, or nil if the stream ended without a response.
+func streamPrintTurn(ctx context.Context, p llm.Provider, opts llm.CompletionOptions, store *llm.Store, title *kit.TitleProgress) (*llm.CompletionResponse, error) {
+	heartbeat := time.NewTicker(time.Second)
+	defer heartbeat.Stop()
+
+	printed := false
+	streamChan := llm.StreamCompletion(ctx, p, opts)
+	for {
+		select {
+		case chunk, ok := <-streamChan:
+			if !ok {
+				return nil, nil
+			}
+			switch chunk.Type {
+			case llm.ChunkTypeNotice:
+				// Keep stdout to the answer alone.
+				fmt.Fprintln(os.Stderr, chunk.Text)
+			case llm.ChunkTypeText:
+				title.Add(chunk.Text)
+				fmt.Print(chunk.Text)
+				printed = true
+			case llm.ChunkTypeThinking:
+				title.Add(chunk.Text)
+			case llm.ChunkTypeError:
+				if notFound, ok := llm.AsModelNotFound(chunk.Error); ok {
+					return nil, errors.New(modelNotFoundMessage(notFound, store, "Pass --model to pick another."))
+				}
+				return nil, chunk.Error
+			case llm.ChunkTypeDone:
+				if printed {
+					fmt.Println()
+				}
+				return chunk.Response, nil
 			}
+		case <-heartbeat.C:
+			title.Add("")
 		}
 	}
+}
+
+// printTools returns the tools print mode offers, each gated by the headless
+// permissions for mode.
+func printTools(cwd string, perms *setting.SessionPermissions, mode string) core.Tools {
+	// Nobody is there to answer questions.
+	schemas := (&tool.Set{Disabled: map[string]bool{tool.ToolAskUserQuestion: true}}).Tools()
+	return tool.WithPermission(tool.AdaptToolRegistry(schemas, func() string { return cwd }),
+		printPermissionGate(perms, mode))
+}
 
-	if llmProvider == nil {
-		return fmt.Errorf("no provider connected. Run 'gen' and use /provider to connect")
+// runPrintTool runs one tool call for print mode. Denied and failed calls
+// are reported on stderr and returned to the model as error results.
+func runPrintTool(ctx context.Context, tools core.Tools, tc core.ToolCall) core.ToolResult {
+	fail := func(msg string) core.ToolResult {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", tc.Name, msg)
+		return *core.ErrorResult(tc, msg)
+	}
+	t := tools.Get(tc.Name)
+	if t == nil {
+		return fail("unknown tool")
+	}
+	input, err := core.ParseToolInput(tc.Input)
+	if err != nil {
+		return fail(err.Error())
+	}
+	out, err := t.Execute(ctx, input)
+	if err != nil {
+		return fail(err.Error())
 	}
+	return core.ToolResult{ToolCallID: tc.ID, ToolName: tc.Name, Content: out}
+}
 
-	completionOpts := llm.CompletionOptions{
-		Model:        modelID,
-		MaxTokens:    setting.DefaultMaxTokens,
-		SystemPrompt: setting.DefaultSystemPrompt,
-		Messages:     []core.Message{core.UserMessage(userMessage, nil)},
-		Tools:        tool.GetToolSchemas(),
-	}
-
-	streamChan := llmProvider.Stream(ctx, completionOpts)
-	for chunk := range streamChan {
-		switch chunk.Type {
-		case llm.ChunkTypeText:
-			fmt.Print(chunk.Text)
-		case llm.ChunkTypeError:
-			return chunk.Error
-		case llm.ChunkTypeDone:
-			fmt.Println()
+// printPermissionGate allows a tool call only if settings rules and the
+// headless session permissions allow it outright; anything that would
+// prompt is denied.
+func printPermissionGate(perms *setting.SessionPermissions, mode string) perm.PermissionFunc {
+	return func(_ context.Context, name string, input map[string]any) (bool, string) {
+		svc := setting.DefaultIfInit()
+		if svc == nil {
+			return false, fmt.Sprintf("tool %s denied: settings are not loaded", name)
 		}
+		d := svc.HasPermissionToUseTool(name, input, perms)
+		if d.Behavior == setting.Allow {
+			return true, ""
+		}
+		return false, fmt.Sprintf("tool %s denied by --permission-mode %s: %s", name, mode, d.Reason)
 	}
+}
 
-	return nil
+// printModelChoice returns the provider and model print mode asks for: the
+// --provider/--model flags when either is given, else the model setting.
+// Both are empty when neither is set, leaving the stored current model.
+func printModelChoice(store *llm.Store, provider, model, settingModel string) (llm.Name, string) {
+	if provider != "" || model != "" {
+		return llm.Name(provider), model
+	}
+	if spec := strings.TrimSpace(settingModel); spec != "" {
+		ws := modelSpec(store, spec)
+		return llm.Name(ws.Provider), ws.Model
+	}
+	return "", ""
+}
+
+// resolvePrintModel connects the provider and picks the model for print
+// mode: the stored current model, or the first connected provider's default
+// when none is stored. A provider override must be connected and uses its
+// default model unless modelID is also given; a model override alone keeps
+// the resolved provider.
+func resolvePrintModel(ctx context.Context, store *llm.Store, providerName llm.Name, modelID string) (llm.Provider, string, error) {
+	current := store.GetCurrentModel()
+	if providerName != "" {
+		conn, ok := store.GetConnection(providerName)
+		if !ok {
+			return nil, "", fmt.Errorf("provider %s is not connected. Run 'gen' and use /provider to connect", providerName)
+		}
+		p, err := llm.GetProvider(ctx, providerName, conn.AuthMethod)
+		if err != nil {
+			return nil, "", fmt.Errorf("provider %s (%s) not available: %w. Run 'gen' and use /provider to connect",
+				providerName, conn.AuthMethod, err)
+		}
+		switch {
+		case modelID != "":
+		case current != nil && current.Provider == providerName:
+			modelID = current.ModelID
+		default:
+			modelID = setting.DefaultModel(string(providerName), string(conn.AuthMethod))
+		}
+		return p, modelID, nil
+	}
+
+	if current != nil {
+		p, err := llm.GetProvider(ctx, current.Provider, current.AuthMethod)
+		if err != nil {
+			return nil, "", fmt.Errorf("provider %s (%s) not available: %w. Run 'gen' and use /provider to connect",
+				current.Provider, current.AuthMethod, err)
+		}
+		return p, cmp.Or(modelID, current.ModelID), nil
+	}
+	for name, conn := range store.GetConnections() {
+		p, err := llm.GetProvider(ctx, llm.Name(name), conn.AuthMethod)
+		if err == nil {
+			return p, cmp.Or(modelID, setting.DefaultModel(name, string(conn.AuthMethod))), nil
+		}
+	}
+	return nil, "", fmt.Errorf("no provider connected. Run 'gen' and use /provider to connect")
+}
+
+// printTitleEnabled reports whether print mode should show progress in the
+// terminal title.
+func printTitleEnabled() bool {
+	if svc := setting.DefaultIfInit(); svc != nil {
+		return svc.TerminalTitle()
+	}
+	return os.Getenv(setting.NoTerminalTitleEnv) != "1"
 }
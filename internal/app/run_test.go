@@ -0,0 +1,170 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/setting"
+	"github.com/yanmxa/gencode/internal/tool"
+)
+
+type stubProvider struct{ name string }
+
+func (p stubProvider) Stream(context.Context, llm.CompletionOptions) <-chan llm.StreamChunk {
+	return nil
+}
+func (p stubProvider) ListModels(context.Context) ([]llm.ModelInfo, error) { return nil, nil }
+func (p stubProvider) Name() string                                        { return p.name }
+
+func registerStubProvider(t *testing.T, name llm.Name) {
+	t.Helper()
+	llm.Register(llm.Meta{Provider: name, AuthMethod: llm.AuthAPIKey}, func(context.Context) (llm.Provider, error) {
+		return stubProvider{name: string(name)}, nil
+	})
+	t.Cleanup(func() { llm.Unregister(name, llm.AuthAPIKey) })
+}
+
+func TestResolvePrintModel(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	registerStubProvider(t, "stub-a")
+	registerStubProvider(t, "stub-b")
+	store, err := llm.NewStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []llm.Name{"stub-a", "stub-b"} {
+		if err := store.Connect(name, llm.AuthAPIKey); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.SetCurrentModel("model-a", "stub-a", llm.AuthAPIKey); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		provider, model         string
+		wantProvider, wantModel string
+	}{
+		{"", "", "stub-a", "model-a"},
+		{"", "model-x", "stub-a", "model-x"},
+		{"stub-b", "model-y", "stub-b", "model-y"},
+		{"stub-a", "", "stub-a", "model-a"},
+	}
+	for _, tt := range tests {
+		p, modelID, err := resolvePrintModel(ctx, store, llm.Name(tt.provider), tt.model)
+		if err != nil {
+			t.Fatalf("provider=%q model=%q: %v", tt.provider, tt.model, err)
+		}
+		if p.Name() != tt.wantProvider || modelID != tt.wantModel {
+			t.Errorf("provider=%q model=%q: got %s/%s, want %s/%s",
+				tt.provider, tt.model, p.Name(), modelID, tt.wantProvider, tt.wantModel)
+		}
+	}
+
+	_, _, err = resolvePrintModel(ctx, store, "openai", "gpt-4o")
+	if err == nil || !strings.Contains(err.Error(), "not connected") || !strings.Contains(err.Error(), "/provider") {
+		t.Errorf("unconnected provider: err = %v", err)
+	}
+	if cur := store.GetCurrentModel(); cur.ModelID != "model-a" {
+		t.Errorf("stored model changed to %s; overrides must not persist", cur.ModelID)
+	}
+}
+
+func TestPrintModelChoice(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	registerStubProvider(t, "stub-a")
+	registerStubProvider(t, "stub-b")
+	store, err := llm.NewStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []llm.Name{"stub-a", "stub-b"} {
+		if err := store.Connect(name, llm.AuthAPIKey); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.SetCurrentModel("model-a", "stub-a", llm.AuthAPIKey); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name                    string
+		provider, model         string
+		setting                 string
+		wantProvider, wantModel string
+	}{
+		{"current model without setting", "", "", "", "stub-a", "model-a"},
+		{"setting beats current model", "", "", "stub-b:model-p", "stub-b", "model-p"},
+		{"bare setting keeps current provider", "", "", "model-p", "stub-a", "model-p"},
+		{"flags beat setting", "", "model-x", "stub-b:model-p", "stub-a", "model-x"},
+		{"provider flag beats setting", "stub-a", "", "stub-b:model-p", "stub-a", "model-a"},
+	}
+	for _, tt := range tests {
+		providerName, modelID := printModelChoice(store, tt.provider, tt.model, tt.setting)
+		p, modelID, err := resolvePrintModel(context.Background(), store, providerName, modelID)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if p.Name() != tt.wantProvider || modelID != tt.wantModel {
+			t.Errorf("%s: got %s/%s, want %s/%s", tt.name, p.Name(), modelID, tt.wantProvider, tt.wantModel)
+		}
+	}
+}
+
+func TestRunPrintToolHonorsPermissionMode(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	setting.Initialize(setting.Options{CWD: dir})
+	t.Cleanup(setting.ResetService)
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(dir, "b.txt")
+	write := core.ToolCall{ID: "w", Name: "Write", Input: fmt.Sprintf(`{"file_path":%q,"content":"x"}`, target)}
+	read := core.ToolCall{ID: "r", Name: "Read", Input: fmt.Sprintf(`{"file_path":%q}`, filepath.Join(dir, "a.txt"))}
+
+	for _, tt := range []struct {
+		mode      string
+		wantWrite bool
+	}{
+		{setting.HeadlessDeny, false},
+		{setting.HeadlessAcceptEdits, true},
+	} {
+		perms, err := setting.NewHeadlessPermissions(tt.mode, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tools := printTools(dir, perms, tt.mode)
+		if _, ok := findSchema(tools.Schemas(), tool.ToolAskUserQuestion); ok {
+			t.Errorf("%s: AskUserQuestion offered in print mode", tt.mode)
+		}
+
+		if r := runPrintTool(context.Background(), tools, read); r.IsError || !strings.Contains(r.Content, "hello") {
+			t.Errorf("%s: Read = %+v, want the file content", tt.mode, r)
+		}
+		r := runPrintTool(context.Background(), tools, write)
+		_, statErr := os.Stat(target)
+		if written := statErr == nil; written != tt.wantWrite || r.IsError == tt.wantWrite {
+			t.Errorf("%s: Write = %+v, written = %v; want written = %v", tt.mode, r, written, tt.wantWrite)
+		}
+		if !tt.wantWrite && !strings.Contains(r.Content, "--permission-mode deny") {
+			t.Errorf("%s: denial %q should name the permission mode", tt.mode, r.Content)
+		}
+	}
+}
+
+func findSchema(schemas []core.ToolSchema, name string) (core.ToolSchema, bool) {
+	for _, s := range schemas {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return core.ToolSchema{}, false
+}
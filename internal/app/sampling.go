@@ -0,0 +1,153 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yanmxa/gencode/internal/app/input"
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/mcp"
+	"github.com/yanmxa/gencode/internal/setting"
+	"github.com/yanmxa/gencode/internal/tool/perm"
+)
+
+// samplingGate answers sampling/createMessage requests from MCP servers.
+// It outlives registry reloads so per-server consent lasts the whole session.
+var samplingGate = mcp.NewSamplingGate(samplingEnabled)
+
+// samplingDefaultMaxTokens caps completions when a server asks for no limit.
+const samplingDefaultMaxTokens = 4096
+
+func samplingEnabled() bool {
+	s := setting.DefaultIfInit()
+	return s == nil || s.MCPSampling()
+}
+
+// buildSamplingCompleter runs sampling requests against p with model.
+func buildSamplingCompleter(p llm.Provider, model string) mcp.SamplingCompleter {
+	if p == nil {
+		return nil
+	}
+	return func(ctx context.Context, params mcp.CreateMessageParams) (*mcp.CreateMessageResult, error) {
+		opts := llm.CompletionOptions{
+			Model:        model,
+			Messages:     samplingMessages(params.Messages),
+			MaxTokens:    params.MaxTokens,
+			Temperature:  params.Temperature,
+			SystemPrompt: params.SystemPrompt,
+		}
+		if opts.MaxTokens <= 0 {
+			opts.MaxTokens = samplingDefaultMaxTokens
+		}
+		resp, err := llm.Complete(ctx, p, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.CreateMessageResult{
+			Role:       string(core.RoleAssistant),
+			Content:    mcp.SamplingContent{Type: "text", Text: resp.Content},
+			Model:      model,
+			StopReason: samplingStopReason(resp.StopReason),
+		}, nil
+	}
+}
+
+// samplingMessages converts MCP sampling messages to provider messages.
+func samplingMessages(msgs []mcp.SamplingMessage) []core.Message {
+	out := make([]core.Message, 0, len(msgs))
+	for _, m := range msgs {
+		msg := core.Message{Role: core.RoleUser}
+		if m.Role == string(core.RoleAssistant) {
+			msg.Role = core.RoleAssistant
+		}
+		switch m.Content.Type {
+		case "image":
+			msg.Images = []core.Image{{MediaType: m.Content.MimeType, Data: m.Content.Data}}
+		default:
+			msg.Content = m.Content.Text
+		}
+		out = append(out, msg)
+	}
+	return out
+}
+
+// samplingStopReason maps provider stop reasons to MCP's camelCase names.
+func samplingStopReason(reason string) string {
+	switch reason {
+	case "end_turn", "stop":
+		return "endTurn"
+	case "max_tokens", "length":
+		return "maxTokens"
+	case "stop_sequence":
+		return "stopSequence"
+	}
+	return reason
+}
+
+// syncSamplingCompleter points MCP sampling at the current provider and model.
+func (m *model) syncSamplingCompleter() {
+	samplingGate.SetCompleter(buildSamplingCompleter(m.env.LLMProvider, m.env.GetModelID()))
+}
+
+// samplingConsentMsg carries a server's first sampling request to the TUI.
+type samplingConsentMsg struct {
+	Request *mcp.SamplingConsentRequest
+}
+
+func pollSamplingConsent() tea.Cmd {
+	return func() tea.Msg {
+		return samplingConsentMsg{Request: samplingGate.Recv()}
+	}
+}
+
+// handleSamplingConsent asks the user whether an MCP server may use the
+// current model. If another prompt is open, it waits for that one first.
+func (m *model) handleSamplingConsent(msg samplingConsentMsg) tea.Cmd {
+	if m.userInput.Approval.IsActive() || m.conv.Modal.Question.IsActive() {
+		return tea.Tick(200*time.Millisecond, func(time.Time) tea.Msg { return msg })
+	}
+	req := msg.Request
+	m.env.PendingSampling = req
+	m.userInput.Approval.Show(&perm.PermissionRequest{
+		ToolName:    input.ApprovalMCPSampling,
+		Description: samplingConsentDescription(req, m.env.GetModelID()),
+	}, m.env.Width, m.env.Height)
+	return nil
+}
+
+func samplingConsentDescription(req *mcp.SamplingConsentRequest, model string) string {
+	if model == "" {
+		model = "the current model"
+	}
+	desc := fmt.Sprintf("MCP server %s wants to use %s", req.Server, model)
+	details := []string{fmt.Sprintf("%d message(s)", len(req.Params.Messages))}
+	if req.Params.MaxTokens > 0 {
+		details = append(details, fmt.Sprintf("up to %d tokens", req.Params.MaxTokens))
+	}
+	return desc + " (" + strings.Join(details, ", ") + ")"
+}
+
+// handleSamplingDecision answers the pending consent request, shows any tool
+// request that arrived while it was open, and resumes polling.
+func (m *model) handleSamplingDecision(decision permissionDecision) tea.Cmd {
+	req := m.env.PendingSampling
+	m.env.PendingSampling = nil
+	if req == nil {
+		return nil
+	}
+	answer := mcp.SamplingDeny
+	switch {
+	case decision.Approved && decision.AllowAll:
+		answer = mcp.SamplingAllowSession
+	case decision.Approved:
+		answer = mcp.SamplingAllowOnce
+	}
+	req.Response <- answer
+	m.showPendingPermission()
+	return pollSamplingConsent()
+}
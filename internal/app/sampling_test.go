@@ -0,0 +1,70 @@
+package app
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yanmxa/gencode/internal/agent"
+	"github.com/yanmxa/gencode/internal/app/conv"
+	"github.com/yanmxa/gencode/internal/app/input"
+	"github.com/yanmxa/gencode/internal/mcp"
+)
+
+// bridgeAgent is an active agent.Service that only tracks the pending
+// permission request.
+type bridgeAgent struct {
+	agent.Service
+	pending *agent.PermBridgeRequest
+}
+
+func (a *bridgeAgent) Active() bool                                    { return true }
+func (a *bridgeAgent) PendingPermission() *agent.PermBridgeRequest     { return a.pending }
+func (a *bridgeAgent) SetPendingPermission(r *agent.PermBridgeRequest) { a.pending = r }
+func (a *bridgeAgent) PermissionBridge() *agent.PermissionBridge       { return nil }
+
+func TestToolApprovalQueuesBehindSamplingConsent(t *testing.T) {
+	m := &model{}
+	m.services.Agent = &bridgeAgent{}
+	m.userInput = input.New("", 80, nil, input.SelectorDeps{})
+	m.conv.Modal = conv.NewModalState()
+
+	sampling := &mcp.SamplingConsentRequest{Server: "docs", Response: make(chan mcp.SamplingConsent, 1)}
+	m.handleSamplingConsent(samplingConsentMsg{Request: sampling})
+
+	toolReq := &agent.PermBridgeRequest{ToolName: "Bash", Description: "run ls", Response: make(chan agent.PermBridgeResponse, 1)}
+	m.HandlePermBridge(toolReq)
+	if got := m.userInput.Approval.GetRequest(); got == nil || got.ToolName != input.ApprovalMCPSampling {
+		t.Fatalf("shown request = %+v, want the sampling consent to stay open", got)
+	}
+
+	yes := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")}
+	m.delegateToActiveModal(yes)
+	select {
+	case got := <-sampling.Response:
+		if got != mcp.SamplingAllowOnce {
+			t.Errorf("sampling answer = %v, want allow once", got)
+		}
+	default:
+		t.Fatal("sampling consent was not answered")
+	}
+	if len(toolReq.Response) != 0 {
+		t.Fatal("the sampling decision was delivered to the tool request")
+	}
+	if got := m.userInput.Approval.GetRequest(); !m.userInput.Approval.IsActive() || got == nil || got.ToolName != "Bash" {
+		t.Fatalf("shown request = %+v, want the queued Bash request", got)
+	}
+
+	m.delegateToActiveModal(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	select {
+	case got := <-toolReq.Response:
+		if got.Allow {
+			t.Error("tool request was allowed, want denied")
+		}
+	default:
+		t.Fatal("tool request was not answered")
+	}
+	if m.services.Agent.PendingPermission() != nil || m.env.PendingSampling != nil {
+		t.Error("pending requests were not cleared")
+	}
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/zap"
+
+	"github.com/yanmxa/gencode/internal/app/kit"
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/log"
+	"github.com/yanmxa/gencode/internal/plugin"
+	"github.com/yanmxa/gencode/internal/setting"
+)
+
+// applyDefaultModel starts the session on the model setting, when set, in
+// place of the last model picked with /model. A setting that cannot be used
+// is logged and ignored.
+func (m *model) applyDefaultModel() {
+	spec := strings.TrimSpace(m.services.Setting.Snapshot().Model)
+	if spec == "" {
+		return
+	}
+	ws := modelSpec(m.services.LLM.Store(), spec)
+	if cur := m.env.CurrentModel; cur != nil && cur.ModelID == ws.Model &&
+		(ws.Provider == "" || string(cur.Provider) == ws.Provider) {
+		return
+	}
+	p, current, err := m.resolveWorkspaceModel(ws)
+	if err != nil {
+		log.Logger().Warn("ignoring model setting", zap.String("model", spec), zap.Error(err))
+		return
+	}
+	m.env.CurrentModel = current
+	m.switchProvider(p)
+}
+
+// modelSpec reads "provider:model" or "provider/model" when the prefix
+// names a connected provider, and a bare model ID of the current provider
+// otherwise (model IDs may themselves contain ":" or "/").
+func modelSpec(store *llm.Store, spec string) setting.Workspace {
+	if entry, err := llm.ParseFallbackEntry(spec); err == nil && store != nil {
+		if _, ok := store.GetConnection(entry.Provider); ok {
+			return setting.Workspace{Provider: string(entry.Provider), Model: entry.ModelID}
+		}
+	}
+	return setting.Workspace{Model: spec}
+}
+
+// applyKeybindings builds the prompt keymap from the keybindings setting.
+// Bindings that cannot be used are reported once the TUI is ready.
+func (m *model) applyKeybindings() {
+	keys, warnings := newKeymap(m.services.Setting.Snapshot().Keybindings)
+	m.env.Keys = keys
+	m.env.KeysWarning = keymapWarning(warnings)
+}
+
+// applyDefaultMode starts the session in the defaultMode setting's mode.
+func (m *model) applyDefaultMode() {
+	mode := m.services.Setting.DefaultMode()
+	if mode == m.env.OperationMode {
+		return
+	}
+	m.env.OperationMode = mode
+	m.env.ApplyModePermissions(m.env.CWD)
+	if m.services.Hook != nil {
+		m.services.Hook.SetPermissionMode(m.env.OperationModeName())
+	}
+}
+
+// handleSettingChanged reloads settings after /settings writes one and
+// applies what can change mid-session. The model and permission mode
+// settings apply to new sessions.
+func (m *model) handleSettingChanged(key string) tea.Cmd {
+	setting.Initialize(setting.Options{CWD: m.env.CWD})
+	m.services.Setting = setting.Default()
+	if m.services.Hook != nil {
+		plugin.MergePluginHooksIntoSettings(m.services.Setting.Snapshot())
+	}
+	m.syncSettingsToHookEngine()
+
+	switch key {
+	case "theme":
+		kit.InitTheme(m.services.Setting.Snapshot().Theme)
+		m.userInput.ApplyTheme()
+	case "autoCompactThreshold", "maxTokens", "maxToolParallel":
+		// The agent reads these when it is built; rebuild it between turns.
+		if !m.conv.Stream.Active {
+			m.StopAgentSession()
+		}
+	}
+	return nil
+}
+
+// openToolSelector opens the /tools selector from the settings editor.
+func (m *model) openToolSelector() tea.Cmd {
+	var mcpTools func() []core.ToolSchema
+	if m.services.MCP != nil {
+		mcpTools = m.services.MCP.ListTools
+	}
+	if err := m.userInput.Tool.EnterSelect(m.env.Width, m.env.Height, m.services.Setting.DisabledTools(), mcpTools); err != nil {
+		m.conv.AddNotice("Error: " + err.Error())
+		return tea.Batch(m.CommitMessages()...)
+	}
+	return nil
+}
@@ -4,8 +4,11 @@ package app
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
@@ -14,6 +17,7 @@ import (
 	"github.com/yanmxa/gencode/internal/app/input"
 	"github.com/yanmxa/gencode/internal/app/kit"
 	"github.com/yanmxa/gencode/internal/app/trigger"
+	"github.com/yanmxa/gencode/internal/clipboard"
 	"github.com/yanmxa/gencode/internal/core"
 	"github.com/yanmxa/gencode/internal/hook"
 	"github.com/yanmxa/gencode/internal/image"
@@ -41,6 +45,8 @@ func (m *model) overlaySelectors() []overlaySelector {
 	return []overlaySelector{
 		&m.userInput.Provider.Selector,
 		&m.userInput.Tool,
+		&m.userInput.Settings,
+		&m.userInput.Theme,
 		&m.userInput.Skill.Selector,
 		&m.userInput.Agent,
 		&m.userInput.MCP.Selector,
@@ -48,6 +54,11 @@ func (m *model) overlaySelectors() []overlaySelector {
 		&m.userInput.Session.Selector,
 		&m.userInput.Memory.Selector,
 		&m.userInput.Search,
+		&m.userInput.Clear,
+		&m.userInput.Branch,
+		&m.userInput.Find,
+		&m.userInput.Jobs,
+		&m.userInput.CompactPreview,
 	}
 }
 
@@ -85,6 +96,41 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	case stopHookResultMsg:
 		return m, m.handleStopHookResult(msg)
+	case input.ClearConfirmMsg:
+		return m, m.handleClearConfirm(msg)
+	case input.BranchSelectedMsg:
+		return m, m.handleBranchSelected(msg)
+	case input.EditSelectedMsg:
+		return m, m.editMessage(msg.Index)
+	case mcpToolsChangedMsg:
+		return m, m.handleMCPToolsChanged(msg)
+	case input.MCPPromptArgsMsg:
+		return m, input.NewCommandController(m.commandDeps()).CompleteMCPPrompt(context.Background(), msg)
+	case input.CustomCommandExpandedMsg:
+		return m, input.NewCommandController(m.commandDeps()).CompleteCustomCommand(msg)
+	case input.RetryMsg:
+		return m, m.retryLastPrompt()
+	case input.JobsTickMsg:
+		return m, m.userInput.Jobs.HandleTick(msg)
+	case input.ImageAttachMsg:
+		m.attachImage(msg.Image)
+		return m, nil
+	case input.ModelsRefreshedMsg:
+		return m, m.handleModelsRefreshed(msg)
+	case input.CompactPreviewMsg:
+		return m, m.handleCompactPreview(msg)
+	case input.WorkspaceSelectMsg:
+		return m, m.handleWorkspaceSelect(msg.Name)
+	case input.WrapWidthMsg:
+		return m, m.handleWrapWidth(msg.Width)
+	case input.SettingChangedMsg:
+		return m, m.handleSettingChanged(msg.Key)
+	case input.SettingsToolsMsg:
+		return m, m.openToolSelector()
+	case samplingConsentMsg:
+		return m, m.handleSamplingConsent(msg)
+	case clipboardCopiedMsg:
+		return m, m.handleClipboardCopied(msg)
 	}
 
 	if cmd, handled := m.routeFeatureUpdate(msg); handled {
@@ -116,7 +162,9 @@ func (m *model) needsSpinner() bool {
 	return m.conv.Stream.Active ||
 		m.conv.Compact.Active ||
 		m.userInput.Provider.FetchingLimits ||
-		m.services.Tracker.HasInProgress()
+		m.userInput.Provider.RefreshingModels ||
+		m.services.Tracker.HasInProgress() ||
+		llm.QueuedRequests() > 0
 }
 
 func (m *model) updateTextarea(msg tea.Msg) tea.Cmd {
@@ -150,6 +198,10 @@ func (m *model) handleKeypress(msg tea.KeyMsg) (tea.Cmd, bool) {
 }
 
 func (m *model) handleInputKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if cmd, handled := m.handleKeyAction(msg); handled {
+		return cmd, true
+	}
+
 	switch msg.Type {
 	case tea.KeyTab, tea.KeyRight:
 		if m.userInput.PromptSuggestion.Text != "" && m.userInput.Textarea.Value() == "" {
@@ -159,14 +211,6 @@ func (m *model) handleInputKey(msg tea.KeyMsg) (tea.Cmd, bool) {
 			return nil, true
 		}
 
-	case tea.KeyShiftTab:
-		if !m.conv.Stream.Active && !m.userInput.Approval.IsActive() &&
-			!m.conv.Modal.Question.IsActive() &&
-			!m.userInput.Provider.Selector.IsActive() && !m.userInput.Suggestions.IsVisible() {
-			m.cycleOperationMode()
-			return nil, true
-		}
-
 	case tea.KeyCtrlT:
 		return m.cycleThinkingEffort(), true
 
@@ -175,9 +219,25 @@ func (m *model) handleInputKey(msg tea.KeyMsg) (tea.Cmd, bool) {
 			m.conv.ShowTasks = !m.conv.ShowTasks
 			return nil, true
 		}
-
-	case tea.KeyCtrlO:
-		return m.handleCtrlO(), true
+		if msg.Alt && len(msg.Runes) == 1 {
+			switch msg.Runes[0] {
+			case '1':
+				m.conv.TasksCollapsed = m.conv.ShowTasks && !m.conv.TasksCollapsed
+				m.conv.ShowTasks = true
+				return nil, true
+			case '2':
+				m.conv.ToggleAllToolCalls()
+				return m.reflowScrollback(), true
+			case '3':
+				m.conv.ToggleAllToolResults()
+				return m.reflowScrollback(), true
+			case '4':
+				m.conv.ThinkingCollapsed = !m.conv.ThinkingCollapsed
+				return m.reflowScrollback(), true
+			case '0':
+				return m.collapseEverything(), true
+			}
+		}
 
 	case tea.KeyCtrlE:
 		return m.expandCollapseAll(), true
@@ -194,14 +254,18 @@ func (m *model) handleInputKey(msg tea.KeyMsg) (tea.Cmd, bool) {
 		}
 		return nil, false
 
-	case tea.KeyCtrlV, tea.KeyCtrlY:
+	case tea.KeyCtrlV:
 		return m.pasteImageFromClipboard()
 
+	case tea.KeyCtrlY:
+		return m.copyLastAssistantMessage(), true
+
+	case tea.KeyCtrlF:
+		m.userInput.Find.Enter(m.conv.Messages, "", m.env.Width, m.env.Height)
+		return nil, true
+
 	case tea.KeyCtrlC:
-		if m.userInput.Textarea.Value() != "" {
-			m.userInput.Reset()
-			m.userInput.History.Index = -1
-			m.userInput.LastCtrlC = time.Time{}
+		if m.clearInput() {
 			return nil, true
 		}
 		if m.conv.Stream.Active {
@@ -226,20 +290,6 @@ func (m *model) handleInputKey(msg tea.KeyMsg) (tea.Cmd, bool) {
 		_, cmd, _ := m.executeCommand(context.Background(), "/clear")
 		return cmd, true
 
-	case tea.KeyEsc:
-		if m.userInput.PromptSuggestion.Text != "" {
-			m.userInput.PromptSuggestion.Clear()
-			return nil, true
-		}
-		if m.userInput.Suggestions.IsVisible() {
-			m.userInput.Suggestions.Hide()
-			return nil, true
-		}
-		if m.conv.Stream.Active {
-			return m.handleStreamCancel(), true
-		}
-		return nil, true
-
 	case tea.KeyUp:
 		if m.userInput.Textarea.Line() == 0 {
 			if m.userInput.Queue.PendingCount() > 0 {
@@ -256,19 +306,87 @@ func (m *model) handleInputKey(msg tea.KeyMsg) (tea.Cmd, bool) {
 			m.userInput.HistoryDown()
 			return nil, true
 		}
+	}
+
+	return nil, false
+}
+
+// handleKeyAction runs the prompt action the keybindings setting binds to
+// msg. It reports false when msg has no action, or the action does not
+// apply right now, so the key falls through to its built-in handling.
+func (m *model) handleKeyAction(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch m.env.Keys.action(msg) {
+	case actionSend:
+		return m.handleSubmit(), true
+
+	case actionNewline:
+		m.userInput.Textarea.InsertString("\n")
+		m.userInput.UpdateHeight()
+		return nil, true
+
+	case actionCycleMode:
+		if !m.conv.Stream.Active && !m.userInput.Approval.IsActive() &&
+			!m.conv.Modal.Question.IsActive() &&
+			!m.userInput.Provider.Selector.IsActive() && !m.userInput.Suggestions.IsVisible() {
+			return m.cycleOperationMode(), true
+		}
+
+	case actionToggleExpand:
+		return m.handleCtrlO(), true
 
-	case tea.KeyEnter:
-		if msg.Alt {
-			m.userInput.Textarea.InsertString("\n")
-			m.userInput.UpdateHeight()
+	case actionStop:
+		return m.handleStop(), true
+
+	case actionClearInput:
+		if m.clearInput() {
 			return nil, true
 		}
-		return m.handleSubmit(), true
 	}
-
 	return nil, false
 }
 
+// handleStop dismisses the prompt suggestion or the suggestion list, stops
+// the running tool call or turn, cancels editing a sent message, or starts
+// editing the last one, whichever comes first.
+func (m *model) handleStop() tea.Cmd {
+	if m.userInput.PromptSuggestion.Text != "" {
+		m.userInput.PromptSuggestion.Clear()
+		return nil
+	}
+	if m.userInput.Suggestions.IsVisible() {
+		m.userInput.Suggestions.Hide()
+		return nil
+	}
+	if m.conv.Stream.Active {
+		// A running tool is cancelled on its own and the model carries
+		// on; with no tool running, stopping again stops the turn.
+		if m.services.Agent.CancelTools() {
+			token := m.userInput.Provider.SetStatusMessage(fmt.Sprintf("tool cancelled · %s again to stop", keyLabel(m.env.Keys.key(actionStop))))
+			return kit.StatusTimer(3*time.Second, token)
+		}
+		return m.handleStreamCancel()
+	}
+	if m.userInput.Edit.Active {
+		m.userInput.Reset()
+		return nil
+	}
+	if m.userInput.Textarea.Value() == "" && m.userInput.Queue.PendingCount() == 0 {
+		return m.editLastMessage()
+	}
+	return nil
+}
+
+// clearInput empties a non-empty prompt and reports whether it did.
+func (m *model) clearInput() bool {
+	if m.userInput.Textarea.Value() == "" {
+		return false
+	}
+	m.userInput.Reset()
+	m.userInput.History.Index = -1
+	m.userInput.LastCtrlC = time.Time{}
+	return true
+}
+
 func (m *model) cycleThinkingEffort() tea.Cmd {
 	current := m.env.EffectiveThinkingEffort()
 	next, ok := llm.NextThinkingEffort(m.env.LLMProvider, m.env.GetModelID(), current)
@@ -296,6 +414,9 @@ func (m *model) delegateToActiveModal(msg tea.KeyMsg) (bool, tea.Cmd) {
 	}
 	if m.userInput.Approval.IsActive() {
 		cmd, resp := m.userInput.Approval.HandleKeypress(msg)
+		if resp != nil && resp.Request != nil && resp.Request.ToolName == input.ApprovalMCPSampling {
+			return true, tea.Batch(cmd, m.handleSamplingDecision(permissionDecision{Approved: resp.Approved, AllowAll: resp.AllowAll || resp.Persist}))
+		}
 		if resp != nil {
 			return true, tea.Batch(cmd, m.handlePermBridgeDecision(permissionDecision{Approved: resp.Approved, AllowAll: resp.AllowAll, Request: resp.Request}))
 		}
@@ -350,13 +471,41 @@ func (m *model) expandCollapseAll() tea.Cmd {
 	return m.reflowScrollback()
 }
 
+// collapseEverything reduces the transcript to its minimal view: every tool
+// call, result, and reasoning block collapsed and the task panel shrunk to
+// its summary line.
+func (m *model) collapseEverything() tea.Cmd {
+	m.conv.CollapseAll()
+	m.conv.TasksCollapsed = true
+	m.conv.ThinkingCollapsed = true
+	return m.reflowScrollback()
+}
+
+// handleWrapWidth applies a /width override and re-renders the scrollback
+// at the new width.
+func (m *model) handleWrapWidth(width int) tea.Cmd {
+	m.env.WrapWidth = width
+	m.conv.ResizeMDRenderer(m.env.ContentWidth())
+	if width == 0 {
+		m.conv.AddNotice("Wrap width follows the terminal.")
+	} else if width >= m.env.Width {
+		m.conv.AddNotice(fmt.Sprintf("Wrap width set to %d columns; the terminal is %d wide, so output is capped at the terminal width.", width, m.env.Width))
+	} else {
+		m.conv.AddNotice(fmt.Sprintf("Wrap width set to %d columns.", width))
+	}
+	if m.conv.CommittedCount == 0 {
+		return tea.Batch(m.CommitMessages()...)
+	}
+	return m.reflowScrollback()
+}
+
 func (m *model) handleWindowResize(msg tea.WindowSizeMsg) tea.Cmd {
 	oldWidth := m.env.Width
 	m.env.Width = msg.Width
 	m.env.Height = msg.Height
 	m.userInput.TerminalHeight = msg.Height
 
-	m.conv.ResizeMDRenderer(msg.Width)
+	m.conv.ResizeMDRenderer(m.env.ContentWidth())
 
 	if !m.env.Ready {
 		m.env.Ready = true
@@ -367,6 +516,13 @@ func (m *model) handleWindowResize(msg tea.WindowSizeMsg) tea.Cmd {
 		} else {
 			cmds = append(cmds, tea.Println(conv.RenderWelcome()))
 		}
+		if m.env.IsProtectedDir() {
+			cmds = append(cmds, tea.Println(conv.RenderProtectedDirWarning(m.env.CWD, m.env.SessionPermissions.ProtectedDir)))
+		}
+		if m.env.KeysWarning != "" {
+			m.conv.AddNotice(m.env.KeysWarning)
+			cmds = append(cmds, m.commitAllMessages()...)
+		}
 
 		if m.userInput.Session.PendingSelector {
 			m.userInput.Session.PendingSelector = false
@@ -375,14 +531,14 @@ func (m *model) handleWindowResize(msg tea.WindowSizeMsg) tea.Cmd {
 			}
 		}
 
-		m.userInput.Textarea.SetWidth(msg.Width - 4 - 2)
+		m.resizeTextarea()
 		if len(cmds) > 0 {
 			return tea.Batch(cmds...)
 		}
 		return nil
 	}
 
-	m.userInput.Textarea.SetWidth(msg.Width - 4 - 2)
+	m.resizeTextarea()
 
 	if oldWidth != msg.Width && m.conv.CommittedCount > 0 {
 		return m.reflowScrollback()
@@ -431,6 +587,7 @@ func (m *model) submitDeps() input.SubmitDeps {
 			return ctrl.HandleSubmit(text)
 		},
 		ClearPluginRoot: m.services.Plugin.ClearActivePluginRoot,
+		RedactHistory:   m.historyRedactor().Redact,
 	}
 }
 
@@ -461,7 +618,7 @@ func (m *model) StartProviderTurn(content string) tea.Cmd {
 		images = lastMsg.Images
 	}
 
-	sendCmd := m.sendToAgent(content, images)
+	sendCmd := m.sendWithRelevantMemory(content, images)
 	if startCmd != nil {
 		return tea.Batch(startCmd, sendCmd)
 	}
@@ -477,11 +634,22 @@ func (m *model) commandDeps() input.CommandDeps {
 		Height:       m.env.Height,
 		Cwd:          m.env.CWD,
 
-		DisabledTools: m.services.Setting.DisabledTools(),
-		ProviderStore: m.services.LLM.Store(),
-		LLMProvider:   m.env.LLMProvider,
-		InputTokens:   m.env.InputTokens,
-		CurrentModel:  m.env.CurrentModel,
+		SessionPermissions: m.env.SessionPermissions,
+		Workspaces:         m.services.Setting.Snapshot().Workspaces,
+		ActiveWorkspace:    m.env.Workspace,
+		WrapWidth:          m.env.WrapWidth,
+		Changes:            m.env.Changes,
+		Undo:               m.env.Undo,
+		Spend:              &m.env.Spend,
+
+		DisabledTools:    m.services.Setting.DisabledTools(),
+		ProviderStore:    m.services.LLM.Store(),
+		LLMProvider:      m.env.LLMProvider,
+		InputTokens:      m.env.InputTokens,
+		CurrentModel:     m.env.CurrentModel,
+		ConfirmClear:     m.services.Setting.ConfirmClear(),
+		ModelSort:        m.services.Setting.ModelSort(),
+		CompactThreshold: m.services.Setting.AutoCompactThreshold(),
 
 		Command: m.services.Command,
 		Skill:   m.services.Skill,
@@ -490,15 +658,19 @@ func (m *model) commandDeps() input.CommandDeps {
 		Tracker: m.services.Tracker,
 		Cron:    m.services.Cron,
 		ToolSvc: m.services.Tool,
+		Task:    m.services.Task,
 
 		GetSessionID:      func() string { return m.services.Session.ID() },
+		GetSessionTitle:   func() string { return m.services.Session.Title() },
 		GetSessionStore:   func() *session.Store { return m.services.Session.GetStore() },
 		GetThinkingEffort: func() string { return m.env.EffectiveThinkingEffort() },
+		IsSessionSaved:    m.isSessionSaved,
 
 		ResetTokens:        m.env.ResetTokens,
 		SetThinkingEffort:  func(effort string) { m.env.ThinkingEffort = effort },
 		EnsureSessionStore: func(cwd string) error { return m.services.Session.EnsureStore(cwd) },
 		ForkSession:        m.forkSession,
+		SetSessionTitle:    func(title string) { m.services.Session.SetTitle(title) },
 		ResetFetched:       m.services.Tool.ResetFetched,
 
 		CommitMessages:          m.CommitMessages,
@@ -510,6 +682,7 @@ func (m *model) commandDeps() input.CommandDeps {
 		InitTaskStorage:         m.InitTaskStorage,
 		ReconfigureAgentTool:    m.ReconfigureAgentTool,
 		StopAgentSession:        m.StopAgentSession,
+		SyncAgentMessages:       m.syncAgentMessages,
 		FireSessionEnd:          m.FireSessionEnd,
 		BuildCompactRequest:     m.BuildCompactRequest,
 		SpinnerTickCmd:          m.SpinnerTickCmd,
@@ -517,6 +690,35 @@ func (m *model) commandDeps() input.CommandDeps {
 	}
 }
 
+func (m *model) handleClearConfirm(msg input.ClearConfirmMsg) tea.Cmd {
+	switch msg.Action {
+	case input.ClearActionSaveThenClear:
+		if err := m.PersistSession(); err != nil {
+			m.conv.AddNotice("Failed to save session: " + err.Error())
+			return tea.Batch(m.CommitMessages()...)
+		}
+	case input.ClearActionCancel:
+		return nil
+	}
+	return input.NewCommandController(m.commandDeps()).ClearConversation()
+}
+
+func (m *model) handleBranchSelected(msg input.BranchSelectedMsg) tea.Cmd {
+	originalID, reflow, err := m.branchSession(msg.Keep)
+	if err != nil {
+		m.conv.AddNotice("Failed to branch session: " + err.Error())
+		return tea.Batch(m.CommitMessages()...)
+	}
+	m.conv.AddNotice(fmt.Sprintf("Branched conversation. You are now in the branch.\nTo resume the original: gen -r %s", originalID))
+	return tea.Sequence(reflow, tea.Batch(m.CommitMessages()...))
+}
+
+func (m *model) handleModelsRefreshed(msg input.ModelsRefreshedMsg) tea.Cmd {
+	m.userInput.Provider.RefreshingModels = false
+	m.conv.AddNotice(msg.Result)
+	return tea.Batch(m.CommitMessages()...)
+}
+
 func (m *model) executeCommand(ctx context.Context, inputText string) (string, tea.Cmd, bool) {
 	return input.NewCommandController(m.commandDeps()).Execute(ctx, inputText)
 }
@@ -536,13 +738,17 @@ func (m *model) approvalDeps() input.ApprovalFlowDeps {
 		HookEngine:         hookEngine,
 		Settings:           m.services.Setting.Snapshot(),
 		SessionPermissions: m.env.SessionPermissions,
-		SetOperationMode:   func(mode setting.OperationMode) { m.env.OperationMode = mode },
-		Tool:               &m.conv.Tool,
-		Width:              m.env.Width,
-		Height:             m.env.Height,
-		Cwd:                m.env.CWD,
-		ProgressHub:        m.conv.ProgressHub,
-		MCPExecutor:        conv.NewMCPExecutor(m.services.MCP),
+		SetOperationMode: func(mode setting.OperationMode) {
+			if !m.env.IsProtectedDir() {
+				m.env.OperationMode = mode
+			}
+		},
+		Tool:        &m.conv.Tool,
+		Width:       m.env.Width,
+		Height:      m.env.Height,
+		Cwd:         m.env.CWD,
+		ProgressHub: m.conv.ProgressHub,
+		MCPExecutor: conv.NewMCPExecutor(m.services.MCP),
 	}
 }
 
@@ -568,7 +774,11 @@ func (m *model) AbortToolWithError(errorMsg string, retry bool) tea.Cmd {
 // Mode handling (operation mode, plan, question, enter-plan)
 // ============================================================
 
-func (m *model) cycleOperationMode() {
+func (m *model) cycleOperationMode() tea.Cmd {
+	if m.env.IsProtectedDir() {
+		token := m.userInput.Provider.SetStatusMessage("protected directory: mode locked to normal")
+		return kit.StatusTimer(3*time.Second, token)
+	}
 	allowBypass := m.services.Setting != nil && m.services.Setting.AllowBypass()
 	m.env.OperationMode = m.env.OperationMode.NextWithBypass(allowBypass)
 	m.env.ApplyModePermissions(m.env.CWD)
@@ -576,6 +786,7 @@ func (m *model) cycleOperationMode() {
 	if m.services.Hook != nil {
 		m.services.Hook.SetPermissionMode(m.env.OperationModeName())
 	}
+	return nil
 }
 
 func (m *model) updateMode(msg tea.Msg) (tea.Cmd, bool) {
@@ -677,8 +888,12 @@ func (m *model) HandleSkillInvocation() tea.Cmd {
 		return tea.Batch(m.CommitMessages()...)
 	}
 
+	instructions := m.userInput.Skill.PendingInstructions
 	displayMsg, fullMsg := m.userInput.Skill.ConsumeInvocation()
 	m.conv.Append(core.ChatMessage{Role: core.RoleUser, Content: fullMsg, DisplayContent: displayMsg})
+	if instructions != "" && m.services.Setting != nil && m.services.Setting.ShowSkillPrompts() {
+		m.conv.AddNotice(input.SkillEchoNotice(displayMsg, instructions))
+	}
 	sendCmd := m.sendToAgent(fullMsg, nil)
 	if startCmd != nil {
 		return tea.Batch(startCmd, sendCmd)
@@ -695,13 +910,66 @@ func (m *model) pasteImageFromClipboard() (tea.Cmd, bool) {
 	if imgData == nil {
 		return nil, false
 	}
-	label := m.userInput.AddPendingImage(*imgData)
+	m.attachImage(*imgData)
+	return nil, true
+}
+
+// attachImage adds img to the input as an inline image token.
+func (m *model) attachImage(img core.Image) {
+	label := m.userInput.AddPendingImage(img)
 	m.userInput.Images.Selection = input.ImageSelection{}
 	m.userInput.Textarea.InsertString(label)
 	m.userInput.UpdateHeight()
-	return nil, true
 }
 
+// copyLastAssistantMessage copies the latest finished answer's raw markdown
+// to the system clipboard and confirms in the status bar.
+func (m *model) copyLastAssistantMessage() tea.Cmd {
+	msgs := m.conv.Messages
+	if m.conv.Stream.Active && len(msgs) > 0 && msgs[len(msgs)-1].Role == core.RoleAssistant {
+		msgs = msgs[:len(msgs)-1] // still streaming
+	}
+	var content string
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role == core.RoleAssistant && strings.TrimSpace(msgs[i].Content) != "" {
+			content = msgs[i].Content
+			break
+		}
+	}
+
+	if content == "" {
+		token := m.userInput.Provider.SetStatusMessage("no response to copy yet")
+		return kit.StatusTimer(3*time.Second, token)
+	}
+	// Clipboard tools can be slow or stuck, so never run them on Update.
+	return func() tea.Msg {
+		return clipboardCopiedMsg{chars: utf8.RuneCountInString(content), err: clipboardWrite(content)}
+	}
+}
+
+// clipboardCopiedMsg reports the result of copyLastAssistantMessage.
+type clipboardCopiedMsg struct {
+	chars int
+	err   error
+}
+
+func (m *model) handleClipboardCopied(msg clipboardCopiedMsg) tea.Cmd {
+	var status string
+	switch {
+	case errors.Is(msg.err, clipboard.ErrUnavailable):
+		status = "copy failed: install pbcopy, wl-copy, xclip or xsel"
+	case msg.err != nil:
+		status = "copy failed: " + msg.err.Error()
+	default:
+		status = fmt.Sprintf("copied last response (%d chars)", msg.chars)
+	}
+	token := m.userInput.Provider.SetStatusMessage(status)
+	return kit.StatusTimer(3*time.Second, token)
+}
+
+// clipboardWrite is replaced in tests.
+var clipboardWrite = clipboard.Write
+
 func (m *model) QuitWithCancel() (tea.Cmd, bool) {
 	m.services.Agent.Stop()
 	m.conv.Stream.Stop()
@@ -6,7 +6,11 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/yanmxa/gencode/internal/clipboard"
+	"github.com/yanmxa/gencode/internal/core"
 	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/setting"
+	"github.com/yanmxa/gencode/internal/task/tracker"
 )
 
 type testThinkingProvider struct {
@@ -75,3 +79,113 @@ func TestAltTTogglesTaskPanel(t *testing.T) {
 		t.Fatal("Alt+T should toggle the task panel")
 	}
 }
+
+func TestShiftTabKeepsNormalModeInProtectedDir(t *testing.T) {
+	m := &model{}
+	m.env.SessionPermissions = setting.NewSessionPermissions()
+	m.env.EnterProtectedDir("~")
+
+	cmd := m.cycleOperationMode()
+	if cmd == nil {
+		t.Fatal("expected a status timer command")
+	}
+	if m.env.OperationMode != setting.ModeNormal {
+		t.Fatalf("OperationMode = %v, want normal", m.env.OperationMode)
+	}
+	m.env.EnableAutoAcceptMode(m.env.CWD)
+	if m.env.OperationMode != setting.ModeNormal || m.env.SessionPermissions.AllowAllEdits {
+		t.Fatal("auto-accept should stay disabled in a protected directory")
+	}
+}
+
+func TestAltDigitsToggleTranscriptSectionsIndependently(t *testing.T) {
+	m := &model{}
+	m.services.Tracker = tracker.NewStore()
+	m.conv.ShowTasks = true
+	m.conv.Messages = []core.ChatMessage{
+		{Role: core.RoleAssistant, ToolCalls: []core.ToolCall{{ID: "1", Name: "Read"}}},
+		{Role: core.RoleUser, ToolResult: &core.ToolResult{ToolCallID: "1"}},
+	}
+	altKey := func(r rune) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}, Alt: true} }
+
+	if _, handled := m.handleInputKey(altKey('2')); !handled {
+		t.Fatal("Alt+2 was not handled")
+	}
+	if !m.conv.Messages[0].ToolCallsExpanded || m.conv.Messages[1].Expanded {
+		t.Fatal("Alt+2 should expand tool calls only")
+	}
+
+	m.handleInputKey(altKey('3'))
+	if !m.conv.Messages[1].Expanded || !m.conv.Messages[0].ToolCallsExpanded {
+		t.Fatal("Alt+3 should expand tool results only")
+	}
+
+	m.handleInputKey(altKey('1'))
+	if !m.conv.TasksCollapsed {
+		t.Fatal("Alt+1 should collapse the task panel")
+	}
+	m.handleInputKey(altKey('1'))
+	if m.conv.TasksCollapsed {
+		t.Fatal("Alt+1 again should expand the task panel")
+	}
+
+	m.handleInputKey(altKey('4'))
+	if !m.conv.ThinkingCollapsed || !m.conv.Messages[1].Expanded {
+		t.Fatal("Alt+4 should collapse thinking only")
+	}
+	m.handleInputKey(altKey('4'))
+
+	m.handleInputKey(altKey('0'))
+	if m.conv.Messages[0].ToolCallsExpanded || m.conv.Messages[1].Expanded || !m.conv.TasksCollapsed || !m.conv.ThinkingCollapsed {
+		t.Fatal("Alt+0 should collapse everything")
+	}
+}
+
+func TestCtrlYCopiesLastAssistantMessage(t *testing.T) {
+	var copied []string
+	prev := clipboardWrite
+	t.Cleanup(func() { clipboardWrite = prev })
+	clipboardWrite = func(text string) error {
+		copied = append(copied, text)
+		return nil
+	}
+
+	m := &model{}
+	if _, handled := m.handleInputKey(tea.KeyMsg{Type: tea.KeyCtrlY}); !handled {
+		t.Fatal("Ctrl+Y was not handled")
+	}
+	if len(copied) != 0 || m.userInput.Provider.StatusMessage != "no response to copy yet" {
+		t.Fatalf("empty conversation: copied=%q status=%q", copied, m.userInput.Provider.StatusMessage)
+	}
+
+	m.conv.Messages = []core.ChatMessage{
+		{Role: core.RoleUser, Content: "question"},
+		{Role: core.RoleAssistant, Content: "# Answer\n\n`code`"},
+		{Role: core.RoleNotice, Content: "notice"},
+		{Role: core.RoleAssistant, Content: "partial"},
+	}
+	// The copy runs in a command; feed its result back like the runtime.
+	pressCtrlY := func() {
+		t.Helper()
+		cmd, _ := m.handleInputKey(tea.KeyMsg{Type: tea.KeyCtrlY})
+		msg, ok := cmd().(clipboardCopiedMsg)
+		if !ok {
+			t.Fatal("Ctrl+Y should copy in a command")
+		}
+		m.Update(msg)
+	}
+	m.conv.Stream.Active = true
+	pressCtrlY()
+	if len(copied) != 1 || copied[0] != "# Answer\n\n`code`" {
+		t.Fatalf("copied = %q, want the last finished answer as raw markdown", copied)
+	}
+	if m.userInput.Provider.StatusMessage != "copied last response (16 chars)" {
+		t.Errorf("status = %q", m.userInput.Provider.StatusMessage)
+	}
+
+	clipboardWrite = func(string) error { return clipboard.ErrUnavailable }
+	pressCtrlY()
+	if got := m.userInput.Provider.StatusMessage; got != "copy failed: install pbcopy, wl-copy, xclip or xsel" {
+		t.Errorf("status without clipboard tool = %q", got)
+	}
+}
@@ -2,17 +2,33 @@
 package app
 
 import (
+	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/yanmxa/gencode/internal/app/conv"
+	"github.com/yanmxa/gencode/internal/app/input"
 	"github.com/yanmxa/gencode/internal/app/kit"
 	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/subagent"
 	"github.com/yanmxa/gencode/internal/task/tracker"
 )
 
-var ghostTextStyle = lipgloss.NewStyle().Foreground(kit.CurrentTheme.TextDim)
+var ghostTextStyle lipgloss.Style
+
+func init() {
+	kit.OnThemeChange(func() {
+		ghostTextStyle = lipgloss.NewStyle().Foreground(kit.CurrentTheme.TextDim)
+	})
+}
+
+// promptModelMaxLen caps the model name shown in the input prompt.
+const promptModelMaxLen = 24
+
+// datedModelSuffix matches release-date suffixes such as "-20250514" or "@20250929".
+var datedModelSuffix = regexp.MustCompile(`[-@]\d{8}$`)
 
 func (m *model) View() string {
 	if !m.env.Ready {
@@ -57,6 +73,10 @@ func (m *model) View() string {
 		view.WriteString("\n")
 		view.WriteString(queuePreview)
 	}
+	if editHint := m.renderEditHint(); editHint != "" {
+		view.WriteString("\n")
+		view.WriteString(editHint)
+	}
 	view.WriteString("\n")
 	view.WriteString(inputView)
 	if suggestions != "" {
@@ -101,6 +121,9 @@ func separatorWrapped(trackerPrefix, separator, content string) string {
 
 func (m model) renderInputView() string {
 	prompt := conv.InputPromptStyle.Render("❯ ")
+	if tag := m.promptModelTag(); tag != "" {
+		prompt += lipgloss.NewStyle().Foreground(kit.CurrentTheme.TextDim).Render(tag) + " "
+	}
 	if m.userInput.PromptSuggestion.Text != "" && m.userInput.Textarea.Value() == "" &&
 		!m.conv.Stream.Active && !m.userInput.Suggestions.IsVisible() {
 		return prompt + ghostTextStyle.Render(m.userInput.PromptSuggestion.Text)
@@ -108,6 +131,56 @@ func (m model) renderInputView() string {
 	return prompt + m.userInput.RenderTextarea()
 }
 
+// statusCost returns the session cost for the status bar, or nil when
+// showCost is off.
+func (m model) statusCost() []llm.Money {
+	if m.services.Setting != nil && !m.services.Setting.ShowCost() {
+		return nil
+	}
+	return m.env.Spend.Totals()
+}
+
+// promptModelTag returns the "[model]" indicator shown after the input
+// prompt when showPromptModel is on, or "" when it is off or no model is set.
+func (m model) promptModelTag() string {
+	if m.services.Setting == nil || !m.services.Setting.ShowPromptModel() {
+		return ""
+	}
+	// Not GetModelID: its fallback would name a model nobody selected.
+	if m.env.CurrentModel == nil || m.env.CurrentModel.ModelID == "" {
+		return ""
+	}
+	return "[" + shortModelName(m.env.CurrentModel.ModelID) + "]"
+}
+
+// shortModelName returns a compact name for modelID: its alias when it has
+// one (sonnet, opus, haiku), otherwise the ID without any vendor prefix or
+// release-date suffix.
+func shortModelName(modelID string) string {
+	if alias := subagent.ModelAlias(modelID); alias != "" {
+		return alias
+	}
+	name := modelID[strings.LastIndex(modelID, "/")+1:]
+	name = datedModelSuffix.ReplaceAllString(name, "")
+	if r := []rune(name); len(r) > promptModelMaxLen {
+		name = string(r[:promptModelMaxLen-1]) + "…"
+	}
+	return name
+}
+
+// resizeTextarea fits the input to the terminal width, leaving room for the
+// prompt and the model indicator.
+func (m *model) resizeTextarea() {
+	if m.env.Width == 0 {
+		return
+	}
+	width := m.env.Width - 4 - 2
+	if tag := m.promptModelTag(); tag != "" {
+		width -= lipgloss.Width(tag) + 1
+	}
+	m.userInput.Textarea.SetWidth(width)
+}
+
 func (m model) renderChatSection(activeContent, trackerView string) string {
 	var parts []string
 
@@ -127,6 +200,23 @@ func (m model) renderChatSection(activeContent, trackerView string) string {
 		parts = append(parts, spinnerView)
 	}
 
+	if m.userInput.Provider.RefreshingModels {
+		spinnerView := conv.ThinkingStyle.Render(m.conv.Spinner.View() + " Refreshing models...")
+		if len(parts) > 0 {
+			spinnerView = "\n" + spinnerView
+		}
+		parts = append(parts, spinnerView)
+	}
+
+	if queued := llm.QueuedRequests(); queued > 0 {
+		queueView := conv.ThinkingStyle.Render(fmt.Sprintf("%s %d request(s) queued — waiting for a free provider slot (limit %d)",
+			m.conv.Spinner.View(), queued, llm.MaxConcurrentRequests()))
+		if len(parts) > 0 {
+			queueView = "\n" + queueView
+		}
+		parts = append(parts, queueView)
+	}
+
 	if compactView := conv.RenderCompactStatus(m.env.Width, m.conv.Spinner.View(), m.conv.Compact); compactView != "" {
 		parts = append(parts, compactView)
 	}
@@ -146,6 +236,7 @@ func (m model) renderTrackerList() string {
 		Width:        m.env.Width,
 		SpinnerView:  m.conv.Spinner.View(),
 		Blockers:     m.services.Tracker.OpenBlockers,
+		Collapsed:    m.conv.TasksCollapsed,
 	})
 }
 
@@ -162,6 +253,10 @@ func (m model) renderModeStatus() string {
 			modelName = status
 		}
 	}
+	compactThreshold := 0
+	if m.services.Setting != nil {
+		compactThreshold = m.services.Setting.AutoCompactThreshold()
+	}
 	return conv.RenderModeStatus(conv.OperationModeParams{
 		Mode:             conv.OperationMode(m.env.OperationMode),
 		InputTokens:      m.env.InputTokens,
@@ -169,12 +264,16 @@ func (m model) renderModeStatus() string {
 		InputLimit:       kit.GetEffectiveInputLimit(m.services.LLM.Store(), m.env.CurrentModel),
 		ModelName:        modelName,
 		StatusMessage:    m.userInput.Provider.StatusMessage,
-		ConversationCost: m.env.ConversationCost,
+		ConversationCost: m.statusCost(),
 		Width:            m.env.Width,
 		ThinkingEffort:   thinkingEffort,
 		ShowThinking:     showThinking,
 		QueueCount:       m.userInput.Queue.PendingCount(),
 		WaitingCount:     m.userInput.Queue.WaitingCount(),
+		JobCount:         input.RunningJobs(m.services.Task),
+		CycleModeKey:     m.env.Keys.key(actionCycleMode),
+		CompactThreshold: compactThreshold,
+		CustomPrompt:     m.env.CachedCustomPrompt != "",
 	})
 }
 
@@ -208,14 +307,19 @@ func (m model) messageRenderParams() conv.MessageRenderParams {
 		CommittedCount:          m.conv.CommittedCount,
 		StreamActive:            m.conv.Stream.Active,
 		BuildingTool:            m.conv.Stream.BuildingTool,
+		StreamingTool:           m.conv.Stream.StreamingTool,
+		ToolInputSize:           len(m.conv.Stream.ToolInput),
+		StreamNotice:            m.conv.Stream.Notice,
 		PendingCalls:            m.conv.Tool.PendingCalls,
 		CurrentIdx:              m.conv.Tool.CurrentIdx,
-		Width:                   m.env.Width,
+		Width:                   m.env.ContentWidth(),
 		MDRenderer:              m.conv.MDRenderer,
 		SpinnerView:             m.conv.Spinner.View(),
 		TaskProgress:            m.conv.TaskProgress,
 		TaskOwnerMap:            buildTaskOwnerMap(m.services.Tracker.List()),
 		InteractivePromptActive: m.conv.Modal.Question != nil && m.conv.Modal.Question.IsActive(),
+		ThinkingCollapsed:       m.conv.ThinkingCollapsed,
+		HideThinking:            m.services.Setting != nil && !m.services.Setting.ShowThinking(),
 	}
 }
 
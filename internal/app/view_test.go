@@ -0,0 +1,39 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/llm"
+)
+
+func TestShortModelName(t *testing.T) {
+	tests := map[string]string{
+		"claude-sonnet-4-20250514":             "sonnet",
+		"claude-haiku-4-5-20251001":            "haiku",
+		"claude-opus-4-1@20250805":             "claude-opus-4-1",
+		"claude-sonnet-4-5-20250929":           "claude-sonnet-4-5",
+		"gpt-4o":                               "gpt-4o",
+		"models/gemini-2.5-pro":                "gemini-2.5-pro",
+		"meta-llama/llama-3.3-70b-instruct":    "llama-3.3-70b-instruct",
+		"some-provider-very-long-model-name-x": "some-provider-very-long…",
+	}
+	for id, want := range tests {
+		if got := shortModelName(id); got != want {
+			t.Errorf("shortModelName(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestPromptModelTag(t *testing.T) {
+	m := newWorkspaceTestModel(t, `{"showPromptModel": true}`)
+	for _, current := range []*llm.CurrentModelInfo{nil, {Provider: "anthropic"}} {
+		m.env.CurrentModel = current
+		if got := m.promptModelTag(); got != "" {
+			t.Errorf("promptModelTag() with model %+v = %q, want empty", current, got)
+		}
+	}
+	m.env.CurrentModel = &llm.CurrentModelInfo{ModelID: "claude-sonnet-4-20250514"}
+	if got := m.promptModelTag(); got != "[sonnet]" {
+		t.Errorf("promptModelTag() = %q, want [sonnet]", got)
+	}
+}
@@ -0,0 +1,167 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/setting"
+	"github.com/yanmxa/gencode/internal/tool"
+)
+
+// applyWorkspace switches provider/model, enabled tools, and operation mode
+// to the named workspace. Everything is validated before any state changes,
+// so a bad workspace leaves the session untouched. MCP servers are switched
+// by the caller (see handleWorkspaceSelect) because connecting is async.
+func (m *model) applyWorkspace(name string) (setting.Workspace, error) {
+	workspaces := m.services.Setting.Snapshot().Workspaces
+	ws, ok := workspaces[name]
+	if !ok {
+		return ws, fmt.Errorf("unknown workspace %q%s", name, workspaceNamesHint(workspaces))
+	}
+
+	mode, err := setting.ParseOperationMode(ws.Mode)
+	if err != nil {
+		return ws, fmt.Errorf("workspace %q: %w", name, err)
+	}
+	if mode == setting.ModeBypassPermissions && !m.services.Setting.AllowBypass() {
+		return ws, fmt.Errorf("workspace %q: bypass mode requires allowBypass in settings", name)
+	}
+
+	var (
+		provider llm.Provider
+		current  *llm.CurrentModelInfo
+	)
+	if ws.Provider != "" || ws.Model != "" {
+		provider, current, err = m.resolveWorkspaceModel(ws)
+		if err != nil {
+			return ws, fmt.Errorf("workspace %q: %w", name, err)
+		}
+	}
+
+	m.StopAgentSession()
+	if provider != nil {
+		m.env.CurrentModel = current
+		m.switchProvider(provider)
+	}
+	if len(ws.Tools) > 0 {
+		m.env.WorkspaceTools = make(map[string]bool, len(ws.Tools))
+		for _, t := range ws.Tools {
+			m.env.WorkspaceTools[t] = true
+		}
+	}
+	if ws.Mode != "" {
+		m.env.OperationMode = mode
+		m.env.ApplyModePermissions(m.env.CWD)
+		if m.services.Hook != nil {
+			m.services.Hook.SetPermissionMode(m.env.OperationModeName())
+		}
+	}
+	m.env.Workspace = name
+	m.env.WorkspaceMCP = ws.MCPServers
+	m.ReconfigureAgentTool()
+	return ws, nil
+}
+
+// resolveWorkspaceModel finds the provider connection and model ID a
+// workspace asks for. A workspace naming only a model keeps the current
+// provider; one naming only a provider uses that provider's default model.
+func (m *model) resolveWorkspaceModel(ws setting.Workspace) (llm.Provider, *llm.CurrentModelInfo, error) {
+	current := m.env.CurrentModel
+	providerName := llm.Name(ws.Provider)
+	if providerName == "" {
+		if current == nil {
+			return nil, nil, fmt.Errorf("no active provider for model %s", ws.Model)
+		}
+		providerName = current.Provider
+	}
+
+	var authMethod llm.AuthMethod
+	if current != nil && current.Provider == providerName {
+		authMethod = current.AuthMethod
+	} else {
+		conn, ok := m.services.LLM.Store().GetConnection(providerName)
+		if !ok {
+			return nil, nil, fmt.Errorf("provider %s is not connected; connect it with /model first", providerName)
+		}
+		authMethod = conn.AuthMethod
+	}
+
+	modelID := ws.Model
+	if modelID == "" {
+		modelID = setting.DefaultModel(string(providerName), string(authMethod))
+	}
+
+	p, err := llm.GetProvider(context.Background(), providerName, authMethod)
+	if err != nil {
+		return nil, nil, fmt.Errorf("provider %s unavailable: %w", providerName, err)
+	}
+	return p, &llm.CurrentModelInfo{ModelID: modelID, Provider: providerName, AuthMethod: authMethod}, nil
+}
+
+// handleWorkspaceSelect applies a workspace chosen with /workspace and
+// reports what changed.
+func (m *model) handleWorkspaceSelect(name string) tea.Cmd {
+	ws, err := m.applyWorkspace(name)
+	if err != nil {
+		m.conv.AddNotice("Error: " + err.Error())
+		return tea.Batch(m.CommitMessages()...)
+	}
+
+	var mcpCmd tea.Cmd
+	if ws.MCPServers != nil {
+		mcpCmd = m.userInput.MCP.Selector.ConnectOnly(ws.MCPServers, m.noTelemetry())
+	}
+	m.conv.AddNotice(describeWorkspace(name, ws, m.env.GetModelID()))
+	return tea.Batch(append(m.CommitMessages(), mcpCmd)...)
+}
+
+// disabledTools returns the settings' disabled tools plus every built-in
+// tool the active workspace does not enable.
+func (m *model) disabledTools() map[string]bool {
+	disabled := m.services.Setting.DisabledTools()
+	if m.env.WorkspaceTools == nil {
+		return disabled
+	}
+	for _, schema := range tool.GetToolSchemas() {
+		if !m.env.WorkspaceTools[schema.Name] {
+			disabled[schema.Name] = true
+		}
+	}
+	return disabled
+}
+
+func describeWorkspace(name string, ws setting.Workspace, modelID string) string {
+	parts := []string{"model " + modelID}
+	if len(ws.Tools) > 0 {
+		parts = append(parts, fmt.Sprintf("%d tools", len(ws.Tools)))
+	}
+	if ws.MCPServers != nil {
+		servers := "none"
+		if len(ws.MCPServers) > 0 {
+			servers = strings.Join(ws.MCPServers, ", ")
+		}
+		parts = append(parts, "MCP: "+servers)
+	}
+	if ws.Mode != "" {
+		mode, _ := setting.ParseOperationMode(ws.Mode)
+		parts = append(parts, "mode: "+mode.String())
+	}
+	return fmt.Sprintf("Switched to workspace %s — %s", name, strings.Join(parts, " · "))
+}
+
+func workspaceNamesHint(workspaces map[string]setting.Workspace) string {
+	if len(workspaces) == 0 {
+		return "; define workspaces in settings.json"
+	}
+	names := make([]string, 0, len(workspaces))
+	for n := range workspaces {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return "; available: " + strings.Join(names, ", ")
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/agent"
+	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/setting"
+)
+
+// storeLLM is an llm.Service that only serves the provider store.
+type storeLLM struct {
+	llm.Service
+	store *llm.Store
+}
+
+func (s storeLLM) Store() *llm.Store { return s.store }
+
+func newWorkspaceTestModel(t *testing.T, settingsJSON string) *model {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".gen"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".gen", "settings.json"), []byte(settingsJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	setting.Initialize(setting.Options{CWD: dir})
+	t.Cleanup(setting.ResetService)
+	agent.Initialize(agent.Options{})
+	t.Cleanup(agent.ResetService)
+
+	store, err := llm.NewStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &model{}
+	m.services.Setting = setting.Default()
+	m.services.Agent = agent.Default()
+	m.services.LLM = storeLLM{store: store}
+	m.env.CWD = dir
+	m.env.OperationMode = setting.ModeNormal
+	m.env.SessionPermissions = setting.NewSessionPermissions()
+	return m
+}
+
+func TestApplyWorkspace(t *testing.T) {
+	m := newWorkspaceTestModel(t, `{"workspaces": {
+		"review": {"tools": ["Read", "Grep"], "mcpServers": ["docs"], "mode": "auto"},
+		"sideways": {"mode": "sideways"},
+		"yolo": {"mode": "bypass"},
+		"offline": {"provider": "stub-missing"}
+	}}`)
+
+	errTests := []struct {
+		name, want string
+	}{
+		{"nope", `unknown workspace "nope"; available: offline, review, sideways, yolo`},
+		{"sideways", `workspace "sideways"`},
+		{"yolo", "bypass mode requires allowBypass"},
+		{"offline", "provider stub-missing is not connected"},
+	}
+	for _, tt := range errTests {
+		if _, err := m.applyWorkspace(tt.name); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("applyWorkspace(%q) error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+	if m.env.Workspace != "" || m.env.WorkspaceTools != nil || m.env.OperationMode != setting.ModeNormal {
+		t.Fatalf("failed switches changed state: workspace=%q tools=%v mode=%v",
+			m.env.Workspace, m.env.WorkspaceTools, m.env.OperationMode)
+	}
+
+	ws, err := m.applyWorkspace("review")
+	if err != nil {
+		t.Fatalf("applyWorkspace(review) error = %v", err)
+	}
+	if m.env.Workspace != "review" || len(ws.MCPServers) != 1 || m.env.WorkspaceMCP[0] != "docs" {
+		t.Errorf("workspace = %q, mcp = %v", m.env.Workspace, m.env.WorkspaceMCP)
+	}
+	if !m.env.WorkspaceTools["Read"] || !m.env.WorkspaceTools["Grep"] || m.env.WorkspaceTools["Bash"] {
+		t.Errorf("WorkspaceTools = %v, want only Read and Grep", m.env.WorkspaceTools)
+	}
+	if m.env.OperationMode != setting.ModeAutoAccept || !m.env.SessionPermissions.AllowAllEdits {
+		t.Errorf("mode = %v, AllowAllEdits = %v; want auto-accept applied",
+			m.env.OperationMode, m.env.SessionPermissions.AllowAllEdits)
+	}
+}
+
+func TestResolveWorkspaceModel(t *testing.T) {
+	m := newWorkspaceTestModel(t, `{}`)
+	registerStubProvider(t, "stub-a")
+	registerStubProvider(t, "stub-b")
+	if err := m.services.LLM.Store().Connect("stub-b", llm.AuthAPIKey); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := m.resolveWorkspaceModel(setting.Workspace{Model: "model-x"}); err == nil ||
+		!strings.Contains(err.Error(), "no active provider") {
+		t.Errorf("model without a provider: err = %v", err)
+	}
+
+	m.env.CurrentModel = &llm.CurrentModelInfo{ModelID: "model-a", Provider: "stub-a", AuthMethod: llm.AuthAPIKey}
+	tests := []struct {
+		name                    string
+		ws                      setting.Workspace
+		wantProvider, wantModel string
+	}{
+		{"model only keeps the current provider", setting.Workspace{Model: "model-x"}, "stub-a", "model-x"},
+		{"current provider needs no saved connection", setting.Workspace{Provider: "stub-a", Model: "model-y"}, "stub-a", "model-y"},
+		{"connected provider", setting.Workspace{Provider: "stub-b", Model: "model-b"}, "stub-b", "model-b"},
+		{"provider only uses its default model", setting.Workspace{Provider: "stub-b"}, "stub-b", setting.DefaultModel("stub-b", string(llm.AuthAPIKey))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, current, err := m.resolveWorkspaceModel(tt.ws)
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if p.Name() != tt.wantProvider || string(current.Provider) != tt.wantProvider || current.ModelID != tt.wantModel {
+				t.Errorf("got %s (%s/%s), want %s/%s", p.Name(), current.Provider, current.ModelID, tt.wantProvider, tt.wantModel)
+			}
+		})
+	}
+
+	if _, _, err := m.resolveWorkspaceModel(setting.Workspace{Provider: "stub-c"}); err == nil ||
+		!strings.Contains(err.Error(), "not connected") {
+		t.Errorf("unconnected provider: err = %v", err)
+	}
+	if m.env.CurrentModel.ModelID != "model-a" {
+		t.Errorf("resolving changed the current model to %s", m.env.CurrentModel.ModelID)
+	}
+}
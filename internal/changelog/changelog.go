@@ -0,0 +1,205 @@
+// Package changelog records the file modifications made by tools during a
+// session so they can be summarized with /changes and on exit. Unlike git
+// diff it attributes changes to the agent and works outside repositories.
+package changelog
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Change is a single file modification made by a tool.
+type Change struct {
+	Path        string
+	Tool        string
+	Created     bool // the file did not exist before this change
+	LinesBefore int
+	LinesAfter  int
+	Time        time.Time
+}
+
+// FileSummary aggregates every change made to one file.
+type FileSummary struct {
+	Path        string
+	Tools       []string // distinct tools, in first-use order
+	Changes     int
+	Created     bool // the first change created the file
+	LinesBefore int  // line count before the first change
+	LinesAfter  int  // line count after the last change
+	LastChanged time.Time
+}
+
+// Log is a session-scoped, concurrency-safe record of file changes.
+type Log struct {
+	mu      sync.Mutex
+	changes []Change
+}
+
+// New creates an empty change log.
+func New() *Log {
+	return &Log{}
+}
+
+// Record appends a change. A zero Time is set to now.
+func (l *Log) Record(c Change) {
+	if c.Path == "" {
+		return
+	}
+	if c.Time.IsZero() {
+		c.Time = time.Now()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.changes = append(l.changes, c)
+}
+
+// Changes returns a copy of every recorded change, oldest first.
+func (l *Log) Changes() []Change {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Change(nil), l.changes...)
+}
+
+// Len reports how many changes have been recorded.
+func (l *Log) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.changes)
+}
+
+// Reset discards all recorded changes.
+func (l *Log) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.changes = nil
+}
+
+// Files groups the recorded changes by path, ordered by first change.
+func (l *Log) Files() []FileSummary {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	index := make(map[string]int)
+	var files []FileSummary
+	for _, c := range l.changes {
+		i, ok := index[c.Path]
+		if !ok {
+			index[c.Path] = len(files)
+			files = append(files, FileSummary{
+				Path:        c.Path,
+				Created:     c.Created,
+				LinesBefore: c.LinesBefore,
+			})
+			i = len(files) - 1
+		}
+		f := &files[i]
+		f.Changes++
+		f.LinesAfter = c.LinesAfter
+		f.LastChanged = c.Time
+		if !slices.Contains(f.Tools, c.Tool) {
+			f.Tools = append(f.Tools, c.Tool)
+		}
+	}
+	return files
+}
+
+// CountLines returns the number of lines in content, counting a final line
+// without a trailing newline. Empty content has zero lines.
+func CountLines(content string) int {
+	if content == "" {
+		return 0
+	}
+	n := strings.Count(content, "\n")
+	if !strings.HasSuffix(content, "\n") {
+		n++
+	}
+	return n
+}
+
+// Format renders a file summary table. Paths under cwd are shown relative
+// to it. Returns an empty string when nothing changed.
+func Format(files []FileSummary, cwd string) string {
+	if len(files) == 0 {
+		return ""
+	}
+	sorted := append([]FileSummary(nil), files...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d file(s) changed this session:\n", len(sorted))
+	for _, f := range sorted {
+		status := "M"
+		lines := fmt.Sprintf("%d → %d lines", f.LinesBefore, f.LinesAfter)
+		if f.Created {
+			status = "A"
+			lines = fmt.Sprintf("%d lines", f.LinesAfter)
+		}
+		edits := "1 change"
+		if f.Changes > 1 {
+			edits = fmt.Sprintf("%d changes", f.Changes)
+		}
+		fmt.Fprintf(&sb, "  %s %s  (%s, %s via %s)\n", status, displayPath(f.Path, cwd), lines, edits, strings.Join(f.Tools, ", "))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func displayPath(path, cwd string) string {
+	if cwd == "" {
+		return path
+	}
+	rel, err := filepath.Rel(cwd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
+
+// FromToolResponse builds a Change from the hook response of a file-writing
+// tool (Write, Edit or MultiEdit). It reports false for other tools or responses that
+// do not describe a file change.
+func FromToolResponse(toolName string, resp map[string]any) (Change, bool) {
+	path, _ := resp["filePath"].(string)
+	if path == "" {
+		return Change{}, false
+	}
+	original, hasOriginal := resp["originalFile"].(string)
+
+	switch toolName {
+	case "Write":
+		content, _ := resp["content"].(string)
+		created := resp["type"] == "create"
+		before := 0
+		if !created && hasOriginal {
+			before = CountLines(original)
+		}
+		return Change{Path: path, Tool: toolName, Created: created, LinesBefore: before, LinesAfter: CountLines(content)}, true
+	case "Edit":
+		oldString, _ := resp["oldString"].(string)
+		newString, _ := resp["newString"].(string)
+		updated := strings.Replace(original, oldString, newString, 1)
+		if replaceAll, _ := resp["replaceAll"].(bool); replaceAll {
+			updated = strings.ReplaceAll(original, oldString, newString)
+		}
+		return Change{Path: path, Tool: toolName, LinesBefore: CountLines(original), LinesAfter: CountLines(updated)}, true
+	case "MultiEdit":
+		edits, _ := resp["edits"].([]any)
+		updated := original
+		for _, e := range edits {
+			edit, _ := e.(map[string]any)
+			oldString, _ := edit["old_string"].(string)
+			newString, _ := edit["new_string"].(string)
+			if replaceAll, _ := edit["replace_all"].(bool); replaceAll {
+				updated = strings.ReplaceAll(updated, oldString, newString)
+			} else {
+				updated = strings.Replace(updated, oldString, newString, 1)
+			}
+		}
+		return Change{Path: path, Tool: toolName, LinesBefore: CountLines(original), LinesAfter: CountLines(updated)}, true
+	}
+	return Change{}, false
+}
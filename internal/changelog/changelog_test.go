@@ -0,0 +1,100 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFromToolResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		tool       string
+		resp       map[string]any
+		wantOK     bool
+		wantBefore int
+		wantAfter  int
+		created    bool
+	}{
+		{
+			name:      "write creates file",
+			tool:      "Write",
+			resp:      map[string]any{"type": "create", "filePath": "/p/a.go", "content": "a\nb\nc\n", "originalFile": nil},
+			wantOK:    true,
+			wantAfter: 3,
+			created:   true,
+		},
+		{
+			name:       "write overwrites file",
+			tool:       "Write",
+			resp:       map[string]any{"type": "update", "filePath": "/p/a.go", "content": "x", "originalFile": "1\n2\n"},
+			wantOK:     true,
+			wantBefore: 2,
+			wantAfter:  1,
+		},
+		{
+			name:       "edit replaces once",
+			tool:       "Edit",
+			resp:       map[string]any{"filePath": "/p/a.go", "originalFile": "a\nb\na\n", "oldString": "a", "newString": "a1\na2"},
+			wantOK:     true,
+			wantBefore: 3,
+			wantAfter:  4,
+		},
+		{
+			name:       "edit replaces all",
+			tool:       "Edit",
+			resp:       map[string]any{"filePath": "/p/a.go", "originalFile": "a\nb\na\n", "oldString": "a", "newString": "a1\na2", "replaceAll": true},
+			wantOK:     true,
+			wantBefore: 3,
+			wantAfter:  5,
+		},
+		{name: "other tool", tool: "Read", resp: map[string]any{"filePath": "/p/a.go"}},
+		{name: "missing path", tool: "Write", resp: map[string]any{"content": "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, ok := FromToolResponse(tt.tool, tt.resp)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if c.LinesBefore != tt.wantBefore || c.LinesAfter != tt.wantAfter || c.Created != tt.created {
+				t.Errorf("got before=%d after=%d created=%v, want %d/%d/%v",
+					c.LinesBefore, c.LinesAfter, c.Created, tt.wantBefore, tt.wantAfter, tt.created)
+			}
+		})
+	}
+}
+
+func TestLogFilesAggregatesPerPath(t *testing.T) {
+	l := New()
+	l.Record(Change{Path: "/p/new.go", Tool: "Write", Created: true, LinesAfter: 10})
+	l.Record(Change{Path: "/p/old.go", Tool: "Edit", LinesBefore: 50, LinesAfter: 52})
+	l.Record(Change{Path: "/p/new.go", Tool: "Edit", LinesBefore: 10, LinesAfter: 12})
+	l.Record(Change{Path: "", Tool: "Edit"})
+
+	files := l.Files()
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(files))
+	}
+	nf := files[0]
+	if nf.Path != "/p/new.go" || !nf.Created || nf.Changes != 2 || nf.LinesAfter != 12 {
+		t.Errorf("unexpected summary for new.go: %+v", nf)
+	}
+	if strings.Join(nf.Tools, ",") != "Write,Edit" {
+		t.Errorf("tools = %v, want Write,Edit", nf.Tools)
+	}
+
+	out := Format(files, "/p")
+	for _, want := range []string{"2 file(s) changed", "A new.go  (12 lines, 2 changes via Write, Edit)", "M old.go  (50 → 52 lines, 1 change via Edit)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Format() missing %q:\n%s", want, out)
+		}
+	}
+
+	l.Reset()
+	if l.Len() != 0 || Format(l.Files(), "/p") != "" {
+		t.Error("expected empty log after Reset")
+	}
+}
@@ -0,0 +1,101 @@
+// Package clipboard writes text to the system clipboard using the platform's
+// command-line tools: pbcopy on macOS, wl-copy, xclip or xsel on Linux, and
+// clip.exe on Windows and WSL.
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// ErrUnavailable is returned when no clipboard tool is installed.
+var ErrUnavailable = errors.New("no clipboard tool found")
+
+// lookPath and getenv are replaced in tests.
+var (
+	lookPath = exec.LookPath
+	getenv   = os.Getenv
+)
+
+// candidates lists the commands that can write to the clipboard on goos, in
+// order of preference.
+func candidates(goos string) [][]string {
+	switch goos {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip.exe"}}
+	}
+
+	var cmds [][]string
+	if getenv("WAYLAND_DISPLAY") != "" {
+		cmds = append(cmds, []string{"wl-copy"})
+	}
+	if getenv("DISPLAY") != "" || getenv("WAYLAND_DISPLAY") != "" {
+		cmds = append(cmds,
+			[]string{"xclip", "-selection", "clipboard"},
+			[]string{"xsel", "--clipboard", "--input"})
+	}
+	// WSL exposes the Windows clipboard through clip.exe.
+	return append(cmds, []string{"clip.exe"})
+}
+
+// command returns the first available clipboard command for goos.
+func command(goos string) ([]string, error) {
+	for _, c := range candidates(goos) {
+		if _, err := lookPath(c[0]); err == nil {
+			return c, nil
+		}
+	}
+	return nil, ErrUnavailable
+}
+
+// waitDelay bounds how long Write waits for stderr to close after the tool
+// exits: xclip and xsel fork a child that keeps serving the selection and
+// inherits the pipe.
+const waitDelay = time.Second
+
+// maxStderr is how much of a failing tool's stderr is kept for the error.
+const maxStderr = 512
+
+// Write copies text to the system clipboard. It returns ErrUnavailable when
+// no clipboard tool is installed.
+func Write(text string) error {
+	args, err := command(runtime.GOOS)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	stderr := &limitedBuffer{max: maxStderr}
+	cmd.Stderr = stderr
+	cmd.WaitDelay = waitDelay
+	if err := cmd.Run(); err != nil && !errors.Is(err, exec.ErrWaitDelay) {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %s", args[0], msg)
+		}
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	return nil
+}
+
+// limitedBuffer keeps the first max bytes written to it and drops the rest.
+type limitedBuffer struct {
+	buf bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string { return b.buf.String() }
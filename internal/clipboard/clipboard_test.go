@@ -0,0 +1,101 @@
+package clipboard
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fakeEnv(t *testing.T, env map[string]string, installed ...string) {
+	t.Helper()
+	prevLook, prevEnv := lookPath, getenv
+	t.Cleanup(func() { lookPath, getenv = prevLook, prevEnv })
+	getenv = func(key string) string { return env[key] }
+	lookPath = func(name string) (string, error) {
+		if slices.Contains(installed, name) {
+			return "/usr/bin/" + name, nil
+		}
+		return "", exec.ErrNotFound
+	}
+}
+
+func TestCommandSelection(t *testing.T) {
+	tests := []struct {
+		name      string
+		goos      string
+		env       map[string]string
+		installed []string
+		want      string
+	}{
+		{"macOS", "darwin", nil, []string{"pbcopy"}, "pbcopy"},
+		{"wayland prefers wl-copy", "linux", map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, []string{"wl-copy", "xclip"}, "wl-copy"},
+		{"X11 uses xclip", "linux", map[string]string{"DISPLAY": ":0"}, []string{"wl-copy", "xclip"}, "xclip"},
+		{"X11 falls back to xsel", "linux", map[string]string{"DISPLAY": ":0"}, []string{"xsel"}, "xsel"},
+		{"WSL uses clip.exe", "linux", nil, []string{"xclip", "clip.exe"}, "clip.exe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeEnv(t, tt.env, tt.installed...)
+			got, err := command(tt.goos)
+			if err != nil || got[0] != tt.want {
+				t.Errorf("command(%s) = %v, %v; want %s", tt.goos, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandUnavailable(t *testing.T) {
+	fakeEnv(t, map[string]string{"DISPLAY": ":0"})
+	if _, err := command("linux"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("err = %v, want ErrUnavailable", err)
+	}
+	if err := Write("text"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Write err = %v, want ErrUnavailable", err)
+	}
+}
+
+// fakeTool installs an executable xclip script on PATH and selects it.
+func fakeTool(t *testing.T, script string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "xclip"), []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	fakeEnv(t, map[string]string{"DISPLAY": ":0"}, "xclip")
+}
+
+func TestWriteDoesNotWaitForForkedChild(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	// Like xclip, stay in the background serving the selection.
+	fakeTool(t, "cat >/dev/null\nsleep 30 &\nexit 0\n")
+	start := time.Now()
+	if err := Write("text"); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Write() took %s waiting on the forked child", elapsed)
+	}
+}
+
+func TestWriteReportsBoundedStderr(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	fakeTool(t, "cat >/dev/null\necho 'Error: Can'\\''t open display' >&2\nhead -c 100000 /dev/zero | tr '\\0' x >&2\nexit 1\n")
+	err := Write("text")
+	if err == nil || !strings.HasPrefix(err.Error(), "xclip: Error: Can't open display") {
+		t.Fatalf("Write() error = %v, want the tool's stderr", err)
+	}
+	if len(err.Error()) > maxStderr+len("xclip: ") {
+		t.Errorf("error keeps %d bytes of stderr, want at most %d", len(err.Error()), maxStderr)
+	}
+}
@@ -3,11 +3,13 @@
 package command
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/yanmxa/gencode/internal/markdown"
 
@@ -25,13 +27,20 @@ type Info struct {
 // This is the single source of truth for command names and descriptions.
 func builtinCommands() []Info {
 	return []Info{
-		{Name: "model", Description: "Select model and manage provider connections"},
-		{Name: "clear", Description: "Clear chat history"},
+		{Name: "model", Description: "Select model and manage provider connections (set-limit <input> <output> to override limits, fallback-chain to edit failover models, refresh to re-fetch model lists)"},
+		{Name: "fallback", Description: "Models to try when a request fails (/fallback add|remove|move|list|clear)"},
+		{Name: "clear", Description: "Clear chat history (/clear force skips confirmation)"},
 		{Name: "fork", Description: "Fork current conversation into a new session"},
+		{Name: "branch", Description: "Branch the conversation from an earlier prompt into a new session (/branch [filter])"},
 		{Name: "resume", Description: "Resume a previous session (opens session selector)"},
+		{Name: "rename", Description: "Name the current session (/rename <title>)"},
+		{Name: "name", Description: "Name the current session, shown in /resume (/name <title>)"},
 		{Name: "help", Description: "Show available commands"},
 		{Name: "glob", Description: "Find files matching a pattern"},
+		{Name: "grep", Description: "Search file contents for a regex"},
 		{Name: "tools", Description: "Manage available tools (enable/disable)"},
+		{Name: "settings", Description: "Edit common settings (theme, model, auto-compact, permission mode, max tokens, tools)"},
+		{Name: "theme", Description: "Choose a color theme (/theme [name])"},
 		{Name: "skills", Description: "Manage skills (enable/disable/activate)"},
 		{Name: "agents", Description: "Manage available agents (enable/disable)"},
 		{Name: "tokenlimit", Description: "View or set token limits for current model"},
@@ -40,10 +49,23 @@ func builtinCommands() []Info {
 		{Name: "memory", Description: "View and manage memory files (list/show/edit) with @import support"},
 		{Name: "mcp", Description: "Manage MCP servers (add/edit/remove/connect/list)"},
 		{Name: "plugin", Description: "Manage plugins (list/install/marketplace/enable/disable/info)"},
-		{Name: "reload-plugins", Description: "Reload plugins and refresh plugin-backed skills, agents, MCP, and hooks"},
+		{Name: "reload-plugins", Description: "Reload plugins and refresh plugin-backed skills, agents, tools, MCP, and hooks"},
 		{Name: "think", Description: "Toggle provider-native thinking effort"},
 		{Name: "loop", Description: "Schedule recurring or one-shot prompts and manage loop jobs"},
-		{Name: "search", Description: "Select search engine for web search"},
+		{Name: "search", Description: "Search the conversation, or select the web search engine with no text"},
+		{Name: "note", Description: "Add a context note that the model sees on every turn"},
+		{Name: "approve", Description: "Auto-approve the next N tool calls (/approve N [tool], /approve off)"},
+		{Name: "workspace", Description: "List workspaces or switch to one (/workspace <name>)"},
+		{Name: "width", Description: "Set the output wrap width (/width <n>, /width auto)"},
+		{Name: "changes", Description: "List files modified by tools this session"},
+		{Name: "edit", Description: "Edit an earlier prompt and re-run the conversation from there"},
+		{Name: "retry", Description: "Drop the last answer and get a fresh one to the same prompt"},
+		{Name: "undo", Description: "Revert the last file change made by Edit, Write, or MultiEdit (/undo [--force])"},
+		{Name: "diff", Description: "Show uncommitted git changes (/diff [--send] to share them with the model)"},
+		{Name: "image", Description: "Attach an image file to your next message (/image <path>)"},
+		{Name: "export", Description: "Save the conversation as Markdown (/export [--force] [path])"},
+		{Name: "cost", Description: "Show the session's token usage and cost per model"},
+		{Name: "jobs", Description: "Show background shell commands with their live output"},
 	}
 }
 
@@ -107,11 +129,17 @@ func (cc *CustomCommand) GetInstructions() string {
 	return body
 }
 
+// reloadCheckInterval bounds how often the command directories are checked
+// for added, removed, or edited files.
+const reloadCheckInterval = time.Second
+
 // service is the internal implementation of Service.
 type service struct {
 	mu                   sync.RWMutex
 	cwd                  string
 	cachedCustomCommands []CustomCommand
+	cachedStamp          string    // commandDirsStamp when the cache was loaded
+	checkedAt            time.Time // last time the stamp was compared
 	dynamicInfoProviders []func() []Info
 	pluginCommandPaths   func() []PluginCommandPath
 }
@@ -251,10 +279,12 @@ func (s *service) getDynamicInfoProviders() []func() []Info {
 }
 
 // loadAllCustomCommands returns custom commands from all sources, using cache
-// when available. The cache is invalidated by Initialize.
+// when available. The cache is invalidated by Initialize, and reloaded when
+// a file in ~/.gen/commands/ or .gen/commands/ is added, removed, or edited;
+// that is checked at most once per reloadCheckInterval.
 func (s *service) loadAllCustomCommands() []CustomCommand {
 	s.mu.RLock()
-	if s.cachedCustomCommands != nil {
+	if s.cachedCustomCommands != nil && time.Since(s.checkedAt) < reloadCheckInterval {
 		defer s.mu.RUnlock()
 		return s.cachedCustomCommands
 	}
@@ -262,13 +292,54 @@ func (s *service) loadAllCustomCommands() []CustomCommand {
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if s.cachedCustomCommands != nil {
+	if s.cachedCustomCommands != nil && time.Since(s.checkedAt) < reloadCheckInterval {
+		return s.cachedCustomCommands
+	}
+	stamp := commandDirsStamp(s.commandDirs())
+	s.checkedAt = time.Now()
+	if s.cachedCustomCommands != nil && stamp == s.cachedStamp {
 		return s.cachedCustomCommands
 	}
 	s.cachedCustomCommands = s.loadCustomCommandsFromDisk()
+	s.cachedStamp = stamp
 	return s.cachedCustomCommands
 }
 
+// commandDirs returns the user and project command directories.
+func (s *service) commandDirs() []string {
+	var dirs []string
+	if homeDir, _ := os.UserHomeDir(); homeDir != "" {
+		dirs = append(dirs, filepath.Join(homeDir, ".gen", "commands"))
+	}
+	if s.cwd != "" {
+		dirs = append(dirs, filepath.Join(s.cwd, ".gen", "commands"))
+	}
+	return dirs
+}
+
+// commandDirsStamp summarizes the markdown files in dirs by name, size, and
+// modification time, so any change to them changes the stamp.
+func commandDirsStamp(dirs []string) string {
+	var sb strings.Builder
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(&sb, "%s/%s:%d:%d\n", dir, entry.Name(), info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return sb.String()
+}
+
 // loadCustomCommandsFromDisk loads custom commands from all sources in priority order:
 // 1. ~/.gen/commands/        (user level, lowest priority)
 // 2. ~/.gen/plugins/*/commands/ (user-plugin)
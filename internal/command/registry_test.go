@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 // saveAndRestore saves current singleton state and restores it on cleanup.
@@ -357,3 +358,38 @@ func TestPluginCommandScope(t *testing.T) {
 		t.Errorf("scope = %d, want %d (scopeProjectPlugin for IsProject=true)", pc.Scope, scopeProjectPlugin)
 	}
 }
+
+func TestCustomCommandsReloadOnChange(t *testing.T) {
+	saveAndRestore(t)
+	t.Setenv("HOME", t.TempDir())
+	cwd := t.TempDir()
+	dir := filepath.Join(cwd, ".gen", "commands")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	s := initTestService(t, cwd)
+
+	if _, ok := s.IsCustomCommand("review"); ok {
+		t.Fatal("review should not exist yet")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "review.md"), []byte("---\ndescription: Review code\n---\nReview $ARGUMENTS"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.IsCustomCommand("review"); ok {
+		t.Fatal("directories should not be rechecked within reloadCheckInterval")
+	}
+
+	s.checkedAt = time.Time{}
+	cc, ok := s.IsCustomCommand("review")
+	if !ok || cc.Description != "Review code" {
+		t.Fatalf("IsCustomCommand(review) = %+v, %v after the file was added", cc, ok)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "review.md")); err != nil {
+		t.Fatal(err)
+	}
+	s.checkedAt = time.Time{}
+	if _, ok := s.IsCustomCommand("review"); ok {
+		t.Error("review should be gone after the file was removed")
+	}
+}
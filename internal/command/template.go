@@ -0,0 +1,143 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// A custom command body is a prompt template, expanded on each invocation:
+//
+//	$ARGUMENTS  the text typed after the command name
+//	!`command`  the output of a shell command, run in the working directory
+//	@path       the contents of a file, relative to the working directory
+//
+// $ARGUMENTS is replaced first, so the arguments can be used in commands and
+// paths. Output and file contents are inserted as they are, without being
+// expanded again.
+
+const (
+	// templateCommandTimeout bounds each !`command` of a template.
+	templateCommandTimeout = 10 * time.Second
+	// maxTemplateInclude caps the bytes an @path include inserts.
+	maxTemplateInclude = 256 * 1024
+)
+
+// TemplateOptions holds what ExpandTemplate needs for one invocation.
+type TemplateOptions struct {
+	Args string // text after the command name
+	CWD  string // directory commands run in and paths are relative to
+
+	// Skip returns why a !`command` must not run, e.g. because a permission
+	// rule denies it, or "" to run it. Nil runs every command.
+	Skip func(command string) string
+}
+
+// templateRe matches !`command`, or @path at the start of a line or after
+// whitespace.
+var templateRe = regexp.MustCompile("(?m)!`([^`\n]+)`|(?:^|\\s)@([^\\s`]+)")
+
+// TemplateCommands returns the !`command` references of body with args
+// substituted, in order, so callers can check them before expanding.
+func TemplateCommands(body, args string) []string {
+	var commands []string
+	for _, m := range templateRe.FindAllStringSubmatch(substituteArgs(body, args), -1) {
+		if m[1] != "" {
+			commands = append(commands, m[1])
+		}
+	}
+	return commands
+}
+
+// ExpandTemplate expands the $ARGUMENTS, !`command`, and @path references of
+// a custom command body. An @path that names no readable file is left as
+// typed, since it may be a mention rather than an include. It runs commands
+// and reads files, so call it off the UI goroutine.
+func ExpandTemplate(ctx context.Context, body string, opts TemplateOptions) string {
+	body = substituteArgs(body, opts.Args)
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range templateRe.FindAllStringSubmatchIndex(body, -1) {
+		if m[2] >= 0 {
+			sb.WriteString(body[last:m[0]])
+			sb.WriteString(runTemplateCommand(ctx, body[m[2]:m[3]], opts))
+			last = m[1]
+			continue
+		}
+		path := body[m[4]:m[5]]
+		// Trailing punctuation usually ends the sentence, not the path.
+		for _, candidate := range []string{path, strings.TrimRight(path, ".,;:!?)")} {
+			if content, ok := includeTemplateFile(candidate, opts.CWD); ok {
+				sb.WriteString(body[last : m[4]-1]) // up to the "@"
+				sb.WriteString(content)
+				last = m[4] + len(candidate)
+				break
+			}
+		}
+	}
+	sb.WriteString(body[last:])
+	return sb.String()
+}
+
+func substituteArgs(body, args string) string {
+	return strings.ReplaceAll(body, "$ARGUMENTS", strings.TrimSpace(args))
+}
+
+// runTemplateCommand returns the combined output of command. Failures are
+// reported in the output, so the model sees what went wrong.
+func runTemplateCommand(ctx context.Context, command string, opts TemplateOptions) string {
+	if opts.Skip != nil {
+		if reason := opts.Skip(command); reason != "" {
+			return fmt.Sprintf("[not run, %s: %s]", reason, command)
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, templateCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "bash", "-c", command)
+	cmd.Dir = opts.CWD
+	out, err := cmd.CombinedOutput()
+	result := strings.TrimRight(string(out), "\n")
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %s", templateCommandTimeout)
+		}
+		result = strings.TrimLeft(result+fmt.Sprintf("\n[%s: %v]", command, err), "\n")
+	}
+	return result
+}
+
+// includeTemplateFile returns the contents of the file at path, wrapped in
+// a <file> tag, or false when path is not a readable file.
+func includeTemplateFile(path, cwd string) (string, bool) {
+	full := path
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		full = filepath.Join(home, rest)
+	} else if !filepath.IsAbs(path) {
+		full = filepath.Join(cwd, path)
+	}
+	info, err := os.Stat(full)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+	data, err := os.ReadFile(full)
+	if err != nil {
+		return "", false
+	}
+	content := string(data)
+	if len(data) > maxTemplateInclude {
+		content = string(data[:maxTemplateInclude]) + fmt.Sprintf("\n[truncated: %d of %d bytes shown]", maxTemplateInclude, len(data))
+	}
+	return fmt.Sprintf("<file path=%q>\n%s\n</file>", path, strings.TrimRight(content, "\n")), true
+}
@@ -0,0 +1,41 @@
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "notes.md"), []byte("remember the milk\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	body := "Review $ARGUMENTS.\nBranch: !`echo main`\nNotes: @notes.md.\nAsk @alice, not a@notes.md."
+	got := ExpandTemplate(context.Background(), body, TemplateOptions{Args: " notes.md ", CWD: dir})
+	want := "Review notes.md.\nBranch: main\nNotes: <file path=\"notes.md\">\nremember the milk\n</file>.\nAsk @alice, not a@notes.md."
+	if got != want {
+		t.Errorf("ExpandTemplate() =\n%s\nwant\n%s", got, want)
+	}
+	if got := TemplateCommands(body, "notes.md"); len(got) != 1 || got[0] != "echo main" {
+		t.Errorf("TemplateCommands() = %q, want [echo main]", got)
+	}
+}
+
+func TestExpandTemplateCommandFailures(t *testing.T) {
+	skip := func(cmd string) string {
+		if strings.HasPrefix(cmd, "rm ") {
+			return "denied by permission rules"
+		}
+		return ""
+	}
+	body := "!`echo out; exit 3`\n!`rm -rf build`"
+	got := ExpandTemplate(context.Background(), body, TemplateOptions{CWD: t.TempDir(), Skip: skip})
+	want := "out\n[echo out; exit 3: exit status 3]\n[not run, denied by permission rules: rm -rf build]"
+	if got != want {
+		t.Errorf("ExpandTemplate() =\n%s\nwant\n%s", got, want)
+	}
+}
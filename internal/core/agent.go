@@ -97,11 +97,14 @@ type Config struct {
 	AgentType         string                                                    // optional: agent type identifier for hook events
 	Color             string                                                    // optional: display color for TUI (e.g. "#ff6600", "blue")
 	CompactFunc       func(ctx context.Context, msgs []Message) (string, error) // optional: summarize messages for compaction
+	CompactThreshold  int                                                       // context usage percent that triggers compaction, 0 = DefaultCompactThreshold, <0 = never
 	CWD               string
-	MaxTurns          int // max LLM inference rounds per cycle, 0 = unlimited
-	MaxOutputRecovery int // max retries on truncated output, 0 = use default (3)
-	InboxBuf          int // inbox channel buffer size, default 16
-	OutboxBuf         int // outbox channel buffer size, default 64; -1 = no outbox (subagent path)
+	MaxTurns          int                    // max LLM inference rounds per cycle, 0 = unlimited
+	MaxToolParallel   int                    // max tool calls running at once, 0 = DefaultMaxToolParallel
+	ParallelTool      func(name string) bool // optional: whether a call may run alongside others; nil = every call may
+	MaxOutputRecovery int                    // max retries on truncated output, 0 = use default (3)
+	InboxBuf          int                    // inbox channel buffer size, default 16
+	OutboxBuf         int                    // outbox channel buffer size, default 64; -1 = no outbox (subagent path)
 }
 
 // NewAgent creates an agent from config.
@@ -138,9 +141,12 @@ func NewAgent(cfg Config) Agent {
 		system:            cfg.System,
 		tools:             cfg.Tools,
 		compactFunc:       cfg.CompactFunc,
+		compactThreshold:  cfg.CompactThreshold,
 		llm:               cfg.LLM,
 		cwd:               cfg.CWD,
 		maxTurns:          cfg.MaxTurns,
+		maxToolParallel:   cfg.MaxToolParallel,
+		parallelTool:      cfg.ParallelTool,
 		maxOutputRecovery: cfg.MaxOutputRecovery,
 		inbox:             make(chan Message, cfg.InboxBuf),
 		outbox:            outbox,
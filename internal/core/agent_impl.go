@@ -22,9 +22,12 @@ type agent struct {
 	system            System
 	tools             Tools
 	compactFunc       func(ctx context.Context, msgs []Message) (string, error)
+	compactThreshold  int
 	llm               LLM
 	cwd               string
 	maxTurns          int
+	maxToolParallel   int
+	parallelTool      func(name string) bool
 	maxOutputRecovery int
 	inbox             chan Message
 	outbox            chan Event
@@ -192,7 +195,7 @@ func (a *agent) ThinkAct(ctx context.Context) (*Result, error) {
 		// known prompt-token count and current conversation growth.
 		if a.compactFunc != nil && lastInputTokens > 0 {
 			estimatedInputTokens := estimatePromptTokens(lastInputTokens, lastPromptTextLen, currentPromptTextLen)
-			if limit := a.llm.InputLimit(); limit > 0 && NeedsCompaction(estimatedInputTokens, limit) {
+			if limit := a.llm.InputLimit(); limit > 0 && NeedsCompactionAt(estimatedInputTokens, limit, a.compactThreshold) {
 				if a.compact(ctx) {
 					continue
 				}
@@ -211,7 +214,7 @@ func (a *agent) ThinkAct(ctx context.Context) (*Result, error) {
 		}
 
 		turns++
-		lastInputTokens = resp.TokensIn
+		lastInputTokens = resp.PromptTokens()
 		lastPromptTextLen = currentPromptTextLen
 		tokensIn += resp.TokensIn
 		tokensOut += resp.TokensOut
@@ -262,19 +265,20 @@ func estimatePromptTokens(lastInputTokens, lastPromptTextLen, currentPromptTextL
 	return estimated
 }
 
+// DefaultMaxToolParallel is how many tool calls of one batch run at once
+// when Config.MaxToolParallel is not set.
+const DefaultMaxToolParallel = 8
+
 // execTools runs tool calls in three phases:
-//  1. Resolve — emit PreTool event, look up tool
-//  2. Execute — parallel when multiple tools, direct when single
+//  1. Resolve — emit PreTool event, look up tool, parse its input
+//  2. Execute — consecutive calls that may run in parallel do so, at most
+//     maxToolParallel at a time; any other call runs alone, in call order
 //  3. Record results — sequential, in original call order
 //
 // Permission checking is handled by the tool decorator (tool.WithPermission),
 // not by the agent. See docs/permission.md.
 func (a *agent) execTools(ctx context.Context, calls []ToolCall) int {
-	type task struct {
-		call ToolCall
-		tool Tool
-	}
-	var tasks []task
+	var tasks []toolTask
 	for _, tc := range calls {
 		if ctx.Err() != nil {
 			break
@@ -285,50 +289,28 @@ func (a *agent) execTools(ctx context.Context, calls []ToolCall) int {
 			a.appendResult(tc, fmt.Sprintf("unknown tool: %s", tc.Name), true)
 			continue
 		}
-		tasks = append(tasks, task{tc, t})
+		params, err := ParseToolInput(tc.Input)
+		if err != nil {
+			a.appendResult(tc, err.Error(), true)
+			continue
+		}
+		tasks = append(tasks, toolTask{tc, t, params})
 	}
 	if len(tasks) == 0 {
 		return 0
 	}
 
-	// Phase 2: Execute (parallel when multiple, direct when single)
-	type output struct {
-		content string
-		err     error
-	}
-	results := make([]output, len(tasks))
-	if len(tasks) == 1 {
-		func() {
-			defer func() {
-				if r := recover(); r != nil {
-					log.Printf("core/agent: tool %s panicked: %v\n%s", tasks[0].call.Name, r, debug.Stack())
-					results[0] = output{"", fmt.Errorf("tool %s panicked: %v", tasks[0].call.Name, r)}
-				}
-			}()
-			params, _ := ParseToolInput(tasks[0].call.Input)
-			execCtx := WithToolCallID(ctx, tasks[0].call.ID)
-			content, err := tasks[0].tool.Execute(execCtx, params)
-			results[0] = output{content, err}
-		}()
-	} else {
-		var wg sync.WaitGroup
-		for i, t := range tasks {
-			wg.Add(1)
-			go func(i int, t task) {
-				defer wg.Done()
-				defer func() {
-					if r := recover(); r != nil {
-						log.Printf("core/agent: tool %s panicked: %v\n%s", t.call.Name, r, debug.Stack())
-						results[i] = output{"", fmt.Errorf("tool %s panicked: %v", t.call.Name, r)}
-					}
-				}()
-				params, _ := ParseToolInput(t.call.Input)
-				execCtx := WithToolCallID(ctx, t.call.ID)
-				content, err := t.tool.Execute(execCtx, params)
-				results[i] = output{content, err}
-			}(i, t)
+	// Phase 2: Execute
+	results := make([]toolOutput, len(tasks))
+	for start := 0; start < len(tasks); {
+		end := start + 1
+		if a.canRunParallel(tasks[start].call.Name) {
+			for end < len(tasks) && a.canRunParallel(tasks[end].call.Name) {
+				end++
+			}
 		}
-		wg.Wait()
+		a.runToolGroup(ctx, tasks[start:end], results[start:end])
+		start = end
 	}
 
 	// Phase 3: Record results in order + PostTool hooks
@@ -351,6 +333,59 @@ func (a *agent) execTools(ctx context.Context, calls []ToolCall) int {
 	return toolUses
 }
 
+type toolTask struct {
+	call   ToolCall
+	tool   Tool
+	params map[string]any
+}
+
+type toolOutput struct {
+	content string
+	err     error
+}
+
+func (a *agent) canRunParallel(name string) bool {
+	return a.parallelTool == nil || a.parallelTool(name)
+}
+
+// runToolGroup executes tasks concurrently, at most maxToolParallel at a
+// time, storing each outcome at the same index of results. A single task
+// runs directly.
+func (a *agent) runToolGroup(ctx context.Context, tasks []toolTask, results []toolOutput) {
+	if len(tasks) == 1 {
+		results[0] = a.runTool(ctx, tasks[0])
+		return
+	}
+	limit := a.maxToolParallel
+	if limit <= 0 {
+		limit = DefaultMaxToolParallel
+	}
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, t := range tasks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, t toolTask) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = a.runTool(ctx, t)
+		}(i, t)
+	}
+	wg.Wait()
+}
+
+// runTool executes one call, turning a panic into an error result.
+func (a *agent) runTool(ctx context.Context, t toolTask) (out toolOutput) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("core/agent: tool %s panicked: %v\n%s", t.call.Name, r, debug.Stack())
+			out = toolOutput{"", fmt.Errorf("tool %s panicked: %v", t.call.Name, r)}
+		}
+	}()
+	content, err := t.tool.Execute(WithToolCallID(ctx, t.call.ID), t.params)
+	return toolOutput{content, err}
+}
+
 // CompactMaxTokens is the max output tokens for compaction LLM calls.
 const CompactMaxTokens = 4096
 
@@ -431,7 +466,8 @@ func (a *agent) streamInfer(ctx context.Context) (*InferResponse, error) {
 			if chunk.Err != nil {
 				return nil, fmt.Errorf("infer: %w", chunk.Err)
 			}
-			if chunk.Text != "" || chunk.Thinking != "" || chunk.Done {
+			if chunk.Text != "" || chunk.Thinking != "" || chunk.Notice != "" || chunk.Fallback != "" ||
+				chunk.ToolStart != "" || chunk.ToolInput != "" || chunk.Done {
 				a.emit(ctx, ChunkEvent(a.id, chunk))
 			}
 			if chunk.Done {
@@ -1,6 +1,13 @@
 package core
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
 
 func TestEstimatePromptTokensUsesConversationGrowth(t *testing.T) {
 	got := estimatePromptTokens(1000, 2000, 3000)
@@ -15,3 +22,100 @@ func TestEstimatePromptTokensNeverDropsBelowLastKnownPromptSize(t *testing.T) {
 		t.Fatalf("estimatePromptTokens() = %d, want 1000", got)
 	}
 }
+
+// probeTool records how many calls run at once. A call sleeps so that
+// overlapping calls are observed, and fails when its input asks it to.
+type probeTool struct {
+	name    string
+	running *atomic.Int32
+	peak    *atomic.Int32
+}
+
+func (p probeTool) Name() string        { return p.name }
+func (p probeTool) Description() string { return "" }
+func (p probeTool) Schema() ToolSchema  { return ToolSchema{Name: p.name} }
+
+func (p probeTool) Execute(_ context.Context, input map[string]any) (string, error) {
+	n := p.running.Add(1)
+	defer p.running.Add(-1)
+	for {
+		peak := p.peak.Load()
+		if n <= peak || p.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	switch input["mode"] {
+	case "fail":
+		return "", errors.New("boom")
+	case "panic":
+		panic("broken tool")
+	}
+	return fmt.Sprintf("%s %v", p.name, input["id"]), nil
+}
+
+func probeAgent(limit int) (*agent, *atomic.Int32) {
+	var running, peak atomic.Int32
+	tools := NewTools(
+		probeTool{"Read", &running, &peak},
+		probeTool{"Write", &running, &peak},
+	)
+	return &agent{
+		tools:           tools,
+		maxToolParallel: limit,
+		parallelTool:    func(name string) bool { return name == "Read" },
+	}, &peak
+}
+
+func probeCall(name string, id int, mode string) ToolCall {
+	return ToolCall{ID: fmt.Sprint(id), Name: name, Input: fmt.Sprintf(`{"id":%d,"mode":%q}`, id, mode)}
+}
+
+func TestExecToolsKeepsOrderAndIsolatesErrors(t *testing.T) {
+	a, peak := probeAgent(2)
+	calls := []ToolCall{
+		probeCall("Read", 1, ""),
+		probeCall("Read", 2, "fail"),
+		probeCall("Read", 3, "panic"),
+		probeCall("Read", 4, ""),
+	}
+	if uses := a.execTools(context.Background(), calls); uses != 2 {
+		t.Errorf("execTools() = %d successful uses, want 2", uses)
+	}
+	if p := peak.Load(); p != 2 {
+		t.Errorf("peak concurrency = %d, want the limit of 2", p)
+	}
+
+	want := []struct {
+		content string
+		isError bool
+	}{
+		{"Read 1", false},
+		{"boom", true},
+		{"tool Read panicked: broken tool", true},
+		{"Read 4", false},
+	}
+	msgs := a.snapshot()
+	if len(msgs) != len(want) {
+		t.Fatalf("got %d results, want %d", len(msgs), len(want))
+	}
+	for i, w := range want {
+		r := msgs[i].ToolResult
+		if r.ToolCallID != calls[i].ID || r.Content != w.content || r.IsError != w.isError {
+			t.Errorf("result %d = %+v, want %q (error %v)", i, r, w.content, w.isError)
+		}
+	}
+}
+
+func TestExecToolsRunsOtherToolsAlone(t *testing.T) {
+	a, peak := probeAgent(0)
+	calls := []ToolCall{
+		probeCall("Write", 1, ""),
+		probeCall("Write", 2, ""),
+		probeCall("Write", 3, ""),
+	}
+	a.execTools(context.Background(), calls)
+	if p := peak.Load(); p != 1 {
+		t.Errorf("peak concurrency = %d, want calls that are not parallel-safe to run one at a time", p)
+	}
+}
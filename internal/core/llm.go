@@ -33,13 +33,34 @@ type InferResponse struct {
 	TokensOut         int
 	CacheCreateTokens int
 	CacheReadTokens   int
+	Citations         []Citation // sources the answer cites, in first-cited order
+}
+
+// PromptTokens returns how much of the context window the request filled:
+// the uncached input tokens plus those read from or written to the prompt
+// cache, which providers count apart from TokensIn.
+func (r *InferResponse) PromptTokens() int {
+	return r.TokensIn + r.CacheReadTokens + r.CacheCreateTokens
+}
+
+// Citation is a source a provider returned for its answer, such as a web
+// page found by grounded search.
+type Citation struct {
+	Title string `json:"title,omitempty"`
+	URL   string `json:"url"`
 }
 
 // Chunk is one piece of a streaming LLM response.
 type Chunk struct {
 	Text     string // incremental text
 	Thinking string // incremental thinking
-	Done     bool   // true on final chunk
+	Notice   string // status for the user, such as a retry; not model output
+	Fallback string // the request moved to a fallback model; not model output
+	// ToolStart names a tool call the model has started to stream; its
+	// input arrives in the ToolInput of the chunks that follow.
+	ToolStart string
+	ToolInput string // incremental, partial JSON input of the streaming tool call
+	Done      bool   // true on final chunk
 
 	Response *InferResponse // non-nil only when Done=true
 	Err      error          // non-nil on stream error
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Role identifies who produced a message in the conversation.
@@ -16,8 +17,14 @@ const (
 	RoleAssistant Role = "assistant"
 	RoleTool      Role = "tool_result"
 	RoleNotice    Role = "notice"
+	// RoleContext marks a user-authored note that is shown in the UI and also
+	// forwarded to the model, unlike RoleNotice which is UI-only.
+	RoleContext Role = "context"
 )
 
+// ContextNotePrefix labels context notes when they are sent to the model.
+const ContextNotePrefix = "[Context note from user] "
+
 // RoleToolResult is an alias for RoleTool.
 const RoleToolResult = RoleTool
 
@@ -51,6 +58,8 @@ type ChatMessage struct {
 	DisplayContent    string
 	Thinking          string
 	ThinkingSignature string
+	ThinkingDuration  time.Duration // time from first thinking chunk to the answer; display only
+	Citations         []Citation    // sources shown after the answer; display only
 	Images            []Image
 	ToolCalls         []ToolCall
 	ToolCallsExpanded bool
@@ -128,16 +137,23 @@ func ToolResultMessage(result ToolResult) Message {
 // --- Utilities ---
 
 // ParseToolInput deserializes JSON tool input into a params map.
+// Input that fails to parse gets one repair pass (see repairJSON); if that
+// also fails, the returned *ToolInputError quotes the offending snippet.
 func ParseToolInput(input string) (map[string]any, error) {
 	input = strings.TrimSpace(input)
 	if input == "" {
 		return map[string]any{}, nil
 	}
 	var params map[string]any
-	if err := json.Unmarshal([]byte(input), &params); err != nil {
-		return nil, err
+	err := json.Unmarshal([]byte(input), &params)
+	if err == nil {
+		return params, nil
+	}
+	params = nil
+	if json.Unmarshal([]byte(repairJSON(input)), &params) == nil {
+		return params, nil
 	}
-	return params, nil
+	return nil, &ToolInputError{Input: input, Err: err}
 }
 
 // BuildConversationText converts messages to text for summarization.
@@ -208,12 +224,26 @@ func LastAssistantChatContent(msgs []ChatMessage) string {
 	return ""
 }
 
-// NeedsCompaction checks if token usage exceeds the threshold percentage of the input limit.
+// DefaultCompactThreshold is the percentage of the input limit at which the
+// conversation is compacted when no threshold is configured.
+const DefaultCompactThreshold = 95
+
+// NeedsCompaction checks if token usage exceeds DefaultCompactThreshold percent of the input limit.
 func NeedsCompaction(inputTokens, inputLimit int) bool {
-	if inputLimit == 0 || inputTokens == 0 {
+	return NeedsCompactionAt(inputTokens, inputLimit, DefaultCompactThreshold)
+}
+
+// NeedsCompactionAt checks if token usage exceeds threshold percent of the
+// input limit. A threshold of 0 means DefaultCompactThreshold; a negative
+// threshold disables compaction.
+func NeedsCompactionAt(inputTokens, inputLimit, threshold int) bool {
+	if inputLimit == 0 || inputTokens == 0 || threshold < 0 {
 		return false
 	}
-	return float64(inputTokens)/float64(inputLimit)*100 >= 95
+	if threshold <= 0 {
+		threshold = DefaultCompactThreshold
+	}
+	return float64(inputTokens)/float64(inputLimit)*100 >= float64(threshold)
 }
 
 // --- Content Parts ---
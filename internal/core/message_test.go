@@ -21,3 +21,22 @@ func TestBuildConversationTextAggregatesToolCalls(t *testing.T) {
 		t.Fatalf("BuildConversationText() = %q, should not emit repeated raw tool-call lines", text)
 	}
 }
+
+func TestNeedsCompactionAtThreshold(t *testing.T) {
+	tests := []struct {
+		tokens, limit, threshold int
+		want                     bool
+	}{
+		{950, 1000, 0, true},
+		{940, 1000, 0, false},
+		{800, 1000, 80, true},
+		{790, 1000, 80, false},
+		{800, 0, 80, false},
+		{1000, 1000, -1, false},
+	}
+	for _, tt := range tests {
+		if got := NeedsCompactionAt(tt.tokens, tt.limit, tt.threshold); got != tt.want {
+			t.Errorf("NeedsCompactionAt(%d, %d, %d) = %v, want %v", tt.tokens, tt.limit, tt.threshold, got, tt.want)
+		}
+	}
+}
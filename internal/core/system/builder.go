@@ -44,6 +44,7 @@ type Config struct {
 	Cwd                 string
 	IsGit               bool
 	IsSubagent          bool
+	CustomPrompt        string // replaces the built-in identity layer when set
 	UserInstructions    string
 	ProjectInstructions string
 	Skills              string
@@ -57,10 +58,14 @@ type Config struct {
 func Build(cfg Config) core.System {
 	sys := core.NewSystem()
 
-	sys.Set(core.Layer{
+	identity := core.Layer{
 		Name: "identity", Priority: 0,
 		Content: cachedBase, Source: core.Predefined,
-	})
+	}
+	if strings.TrimSpace(cfg.CustomPrompt) != "" {
+		identity.Content, identity.Source = cfg.CustomPrompt, core.FromFile
+	}
+	sys.Set(identity)
 
 	if p := loadProvider(cfg.ProviderName); p != "" {
 		sys.Set(core.Layer{
@@ -74,11 +79,8 @@ func Build(cfg Config) core.System {
 		Content: formatEnv(cfg.Cwd, cfg.IsGit, cfg.ModelID), Source: core.Dynamic,
 	})
 
-	if instr := mergeInstructions(cfg.UserInstructions, cfg.ProjectInstructions); instr != "" {
-		sys.Set(core.Layer{
-			Name: "instructions", Priority: 200,
-			Content: instr, Source: core.FromFile,
-		})
+	if layer, ok := InstructionsLayer(cfg.UserInstructions, cfg.ProjectInstructions); ok {
+		sys.Set(layer)
 	}
 
 	if caps := joinNonEmpty(cfg.Skills, cfg.Agents, cfg.DeferredTools); caps != "" {
@@ -151,6 +153,19 @@ func loadProvider(name string) string {
 	return string(data)
 }
 
+// InstructionsLayer returns the layer holding user and project instructions.
+// It reports false when both are empty and the layer should be omitted.
+func InstructionsLayer(user, project string) (core.Layer, bool) {
+	instr := mergeInstructions(user, project)
+	if instr == "" {
+		return core.Layer{}, false
+	}
+	return core.Layer{
+		Name: "instructions", Priority: 200,
+		Content: instr, Source: core.FromFile,
+	}, true
+}
+
 func mergeInstructions(user, project string) string {
 	var parts []string
 	if user != "" {
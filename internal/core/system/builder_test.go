@@ -64,6 +64,27 @@ func TestBuildPromptContainsInstructions(t *testing.T) {
 	}
 }
 
+func TestBuildPromptCustomPromptReplacesIdentity(t *testing.T) {
+	custom := "You are a terse release engineer for the Foo project."
+	prompt := Build(Config{Cwd: "/tmp/test", CustomPrompt: custom, ProjectInstructions: "Use make."}).Prompt()
+
+	if !strings.Contains(prompt, custom) {
+		t.Error("prompt should contain the custom prompt")
+	}
+	if strings.Contains(prompt, strings.TrimSpace(cachedBase)) {
+		t.Error("custom prompt should replace the default identity")
+	}
+	for _, want := range []string{"Session working directory: /tmp/test", "Use make.", strings.TrimSpace(cachedToolsCore)} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt should still contain %q", want)
+		}
+	}
+
+	if def := Build(Config{Cwd: "/tmp/test", CustomPrompt: "  \n"}).Prompt(); !strings.Contains(def, strings.TrimSpace(cachedBase)) {
+		t.Error("blank custom prompt should keep the default identity")
+	}
+}
+
 func TestBuildPromptDirectFields(t *testing.T) {
 	sys := Build(Config{
 		Cwd:    "/tmp/test",
@@ -39,6 +39,22 @@ func LoadInstructions(cwd string) (user, project string) {
 	return strings.Join(userParts, "\n\n"), strings.Join(projectParts, "\n\n")
 }
 
+// CustomPromptPath returns the project file that replaces the default
+// assistant identity prompt.
+func CustomPromptPath(cwd string) string {
+	return filepath.Join(cwd, ".gen", "prompt.md")
+}
+
+// LoadCustomPrompt returns the contents of the project's .gen/prompt.md, or
+// "" when the file is missing or blank.
+func LoadCustomPrompt(cwd string) string {
+	data, err := os.ReadFile(CustomPromptPath(cwd))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
 // LoadMemoryFiles loads all memory files with metadata.
 // Returns files in order: global, global rules, project, project rules, local.
 func LoadMemoryFiles(cwd string) []MemoryFile {
@@ -270,3 +286,88 @@ func FormatFileSize(size int64) string {
 	}
 	return fmt.Sprintf("%dB", size)
 }
+
+// MemoryChunk is a section of a memory file, sized for embedding.
+type MemoryChunk struct {
+	Path    string
+	Level   string
+	Heading string // nearest markdown heading, empty before the first one
+	Content string
+}
+
+// ChunkMemoryFiles splits memory files at markdown headings. Sections longer
+// than maxChars are further split on blank lines; maxChars <= 0 disables that.
+func ChunkMemoryFiles(files []MemoryFile, maxChars int) []MemoryChunk {
+	var chunks []MemoryChunk
+	for _, f := range files {
+		heading := ""
+		var section []string
+		flush := func() {
+			text := strings.TrimSpace(strings.Join(section, "\n"))
+			section = section[:0]
+			if text == "" {
+				return
+			}
+			for _, part := range splitOnParagraphs(text, maxChars) {
+				chunks = append(chunks, MemoryChunk{Path: f.Path, Level: f.Level, Heading: heading, Content: part})
+			}
+		}
+		inFence := false
+		for _, line := range strings.Split(f.Content, "\n") {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, "```") {
+				inFence = !inFence
+			}
+			if !inFence && strings.HasPrefix(trimmed, "#") {
+				flush()
+				heading = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			}
+			section = append(section, line)
+		}
+		flush()
+	}
+	return chunks
+}
+
+// splitOnParagraphs groups blank-line separated paragraphs into pieces of at
+// most maxChars. A single paragraph longer than maxChars is kept whole.
+func splitOnParagraphs(text string, maxChars int) []string {
+	if maxChars <= 0 || len(text) <= maxChars {
+		return []string{text}
+	}
+	var parts []string
+	var current strings.Builder
+	for _, para := range strings.Split(text, "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		if current.Len() > 0 && current.Len()+2+len(para) > maxChars {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(para)
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+	return parts
+}
+
+// JoinMemoryChunks reassembles chunks into user and project instructions,
+// grouped by level the same way LoadInstructions groups whole files.
+func JoinMemoryChunks(chunks []MemoryChunk) (user, project string) {
+	var userParts, projectParts []string
+	for _, c := range chunks {
+		switch c.Level {
+		case "global":
+			userParts = append(userParts, c.Content)
+		case "project", "local":
+			projectParts = append(projectParts, c.Content)
+		}
+	}
+	return strings.Join(userParts, "\n\n"), strings.Join(projectParts, "\n\n")
+}
@@ -525,3 +525,58 @@ func TestMemory_MissingFile_NoError(t *testing.T) {
 	_, project := LoadInstructions(tmpDir)
 	_ = project
 }
+
+func TestChunkMemoryFiles(t *testing.T) {
+	files := []MemoryFile{
+		{Path: "GEN.md", Level: "project", Content: "Intro line\n\n# Build\nRun make.\n\n## Test\nRun go test.\n```sh\n# not a heading\n```\n"},
+		{Path: "rules/long.md", Level: "project", Content: "# Style\naaaa\n\nbbbb\n\ncccc"},
+	}
+
+	chunks := ChunkMemoryFiles(files, 20)
+	var got []string
+	for _, c := range chunks {
+		got = append(got, c.Path+"|"+c.Heading+"|"+c.Content)
+	}
+	want := []string{
+		"GEN.md||Intro line",
+		"GEN.md|Build|# Build\nRun make.",
+		"GEN.md|Test|## Test\nRun go test.\n```sh\n# not a heading\n```",
+		"rules/long.md|Style|# Style\naaaa\n\nbbbb",
+		"rules/long.md|Style|cccc",
+	}
+	if strings.Join(got, "\n---\n") != strings.Join(want, "\n---\n") {
+		t.Errorf("chunks =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestJoinMemoryChunks(t *testing.T) {
+	user, project := JoinMemoryChunks([]MemoryChunk{
+		{Level: "global", Content: "global one"},
+		{Level: "project", Content: "project one"},
+		{Level: "global", Content: "global two"},
+		{Level: "local", Content: "local one"},
+	})
+	if user != "global one\n\nglobal two" {
+		t.Errorf("user = %q", user)
+	}
+	if project != "project one\n\nlocal one" {
+		t.Errorf("project = %q", project)
+	}
+}
+
+func TestLoadCustomPrompt(t *testing.T) {
+	cwd := t.TempDir()
+	if got := LoadCustomPrompt(cwd); got != "" {
+		t.Fatalf("LoadCustomPrompt() without file = %q, want empty", got)
+	}
+
+	if err := os.MkdirAll(filepath.Join(cwd, ".gen"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(CustomPromptPath(cwd), []byte("\nYou are a pirate.\n\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := LoadCustomPrompt(cwd); got != "You are a pirate." {
+		t.Fatalf("LoadCustomPrompt() = %q, want trimmed file content", got)
+	}
+}
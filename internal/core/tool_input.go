@@ -0,0 +1,108 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// toolInputSnippetRadius is how many bytes around a JSON syntax error are
+// echoed back to the model.
+const toolInputSnippetRadius = 60
+
+// ToolInputError reports tool input that is not valid JSON even after
+// repairJSON. Its message quotes the offending part of the input so the
+// model can correct the call instead of retrying it unchanged.
+type ToolInputError struct {
+	Input string
+	Err   error
+}
+
+func (e *ToolInputError) Error() string {
+	return fmt.Sprintf("invalid JSON in tool input: %v near: %s", e.Err, e.snippet())
+}
+
+func (e *ToolInputError) Unwrap() error { return e.Err }
+
+// snippet returns the part of the input around the syntax error, or the
+// beginning of the input when the position is unknown.
+func (e *ToolInputError) snippet() string {
+	offset := 0
+	var syntaxErr *json.SyntaxError
+	if errors.As(e.Err, &syntaxErr) {
+		offset = int(syntaxErr.Offset)
+	}
+	start := max(0, offset-toolInputSnippetRadius)
+	end := min(len(e.Input), offset+toolInputSnippetRadius)
+	if offset == 0 {
+		end = min(len(e.Input), 2*toolInputSnippetRadius)
+	}
+	s := e.Input[start:end]
+	if start > 0 {
+		s = "…" + s
+	}
+	if end < len(e.Input) {
+		s += "…"
+	}
+	return "`" + s + "`"
+}
+
+// repairJSON fixes the mistakes models most often make when emitting tool
+// arguments: single-quoted strings, raw newlines/tabs inside strings, and
+// trailing commas before a closing bracket. Everything else is copied
+// through unchanged, so valid JSON round-trips byte for byte.
+func repairJSON(input string) string {
+	var sb strings.Builder
+	sb.Grow(len(input))
+
+	var quote byte // 0 outside a string, otherwise the opening quote
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		if quote != 0 {
+			switch {
+			case c == '\\' && i+1 < len(input):
+				next := input[i+1]
+				i++
+				if quote == '\'' && next == '\'' {
+					sb.WriteByte('\'')
+					continue
+				}
+				sb.WriteByte('\\')
+				sb.WriteByte(next)
+			case c == quote:
+				sb.WriteByte('"')
+				quote = 0
+			case c == '"':
+				sb.WriteString(`\"`)
+			case c == '\n':
+				sb.WriteString(`\n`)
+			case c == '\r':
+				sb.WriteString(`\r`)
+			case c == '\t':
+				sb.WriteString(`\t`)
+			default:
+				sb.WriteByte(c)
+			}
+			continue
+		}
+
+		switch c {
+		case '"', '\'':
+			quote = c
+			sb.WriteByte('"')
+		case ',':
+			j := i + 1
+			for j < len(input) && strings.IndexByte(" \t\r\n", input[j]) >= 0 {
+				j++
+			}
+			if j < len(input) && (input[j] == '}' || input[j] == ']') {
+				continue
+			}
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
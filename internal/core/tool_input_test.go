@@ -0,0 +1,59 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseToolInputRepairsCommonMistakes(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]any
+	}{
+		{"valid", `{"path":"a.go","n":2}`, map[string]any{"path": "a.go", "n": float64(2)}},
+		{"empty", "  ", map[string]any{}},
+		{"trailing comma in object", `{"path":"a.go",}`, map[string]any{"path": "a.go"}},
+		{"trailing comma in array", `{"paths":["a","b",
+		]}`, map[string]any{"paths": []any{"a", "b"}}},
+		{"raw newline in string", "{\"content\":\"line1\nline2\tend\"}", map[string]any{"content": "line1\nline2\tend"}},
+		{"single quotes", `{'command':'echo "hi"'}`, map[string]any{"command": `echo "hi"`}},
+		{"escaped single quote", `{'text':'it\'s'}`, map[string]any{"text": "it's"}},
+		{"comma inside string kept", `{"s":"a,}"}`, map[string]any{"s": "a,}"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseToolInput(tt.input)
+			if err != nil {
+				t.Fatalf("ParseToolInput(%q) error: %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseToolInput(%q) = %#v, want %#v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseToolInputReportsSnippetWhenUnrepairable(t *testing.T) {
+	input := `{"file_path": "main.go", "old_string": missing quotes here, "new_string": "x"}`
+	_, err := ParseToolInput(input)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	var inputErr *ToolInputError
+	if !errors.As(err, &inputErr) {
+		t.Fatalf("expected *ToolInputError, got %T", err)
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "invalid JSON in tool input") || !strings.Contains(msg, "missing quotes") {
+		t.Errorf("error should echo the offending snippet, got %q", msg)
+	}
+
+	long := `{"content": "` + strings.Repeat("x", 300) + `" oops}`
+	_, err = ParseToolInput(long)
+	if err == nil || !strings.Contains(err.Error(), "…") || len(err.Error()) > 250 {
+		t.Errorf("expected a truncated snippet, got %q", err)
+	}
+}
@@ -31,8 +31,8 @@ func newClipboardImageInfo(data []byte) (*ImageInfo, error) {
 	if len(data) == 0 {
 		return nil, nil
 	}
-	if len(data) > maxImageSize {
-		return nil, fmt.Errorf("clipboard image too large: %d bytes (max %d)", len(data), maxImageSize)
+	if limit := maxSize(); len(data) > limit {
+		return nil, fmt.Errorf("clipboard image too large: %d bytes (max %d)", len(data), limit)
 	}
 	return &ImageInfo{
 		MediaType: "image/png",
@@ -8,14 +8,32 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 
 	"github.com/yanmxa/gencode/internal/core"
 )
 
-const (
-	// maxImageSize is the maximum allowed image size (5MB)
-	maxImageSize = 5 * 1024 * 1024
-)
+// DefaultMaxSize is the largest image accepted when the imageMaxSize
+// setting is unset (5MB).
+const DefaultMaxSize = 5 * 1024 * 1024
+
+var maxSizeProvider atomic.Value // stores func() int
+
+// SetMaxSizeProvider registers the source of the imageMaxSize setting.
+// fn returns 0 when it is unset.
+func SetMaxSizeProvider(fn func() int) {
+	maxSizeProvider.Store(fn)
+}
+
+// maxSize returns the configured image size limit, else DefaultMaxSize.
+func maxSize() int {
+	if fn, ok := maxSizeProvider.Load().(func() int); ok && fn != nil {
+		if n := fn(); n > 0 {
+			return n
+		}
+	}
+	return DefaultMaxSize
+}
 
 // supportedTypes maps file extensions to MIME types
 var supportedTypes = map[string]string{
@@ -53,8 +71,8 @@ func Load(path string) (*ImageInfo, error) {
 	}
 
 	// Check file size
-	if info.Size() > maxImageSize {
-		return nil, fmt.Errorf("image too large: %d bytes (max %d)", info.Size(), maxImageSize)
+	if limit := maxSize(); info.Size() > int64(limit) {
+		return nil, fmt.Errorf("image too large: %d bytes (max %d)", info.Size(), limit)
 	}
 
 	// Check extension
@@ -27,9 +27,11 @@ func NewAPIKeyClient(ctx context.Context) (llm.Provider, error) {
 		baseURL = "https://dashscope.aliyuncs.com/compatible-mode/v1"
 	}
 
+	// The llm package retries failed streams itself and shows progress.
 	client := openai.NewClient(
 		option.WithAPIKey(secret.Resolve("DASHSCOPE_API_KEY")),
 		option.WithBaseURL(baseURL),
+		option.WithMaxRetries(0),
 	)
 	return NewClient(client, "alibaba:api_key"), nil
 }
@@ -3,6 +3,7 @@ package anthropic
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -175,13 +176,13 @@ func (c *Client) Stream(ctx context.Context, opts llm.CompletionOptions) <-chan
 			params.Thinking = anthropic.ThinkingConfigParamOfEnabled(thinkingBudget)
 		}
 
+		// Extended thinking does not accept a custom temperature.
+		if opts.Temperature != nil && thinkingBudget == 0 {
+			params.Temperature = anthropic.Float(*opts.Temperature)
+		}
+
 		if opts.SystemPrompt != "" {
-			// Mark the last system block as ephemeral to enable prompt caching.
-			// This lets Anthropic cache the system prompt across requests, reducing
-			// cost and latency for long system prompts (tools, memory, CLAUDE.md).
-			params.System = []anthropic.TextBlockParam{
-				{Text: opts.SystemPrompt, CacheControl: anthropic.NewCacheControlEphemeralParam()},
-			}
+			params.System = []anthropic.TextBlockParam{{Text: opts.SystemPrompt}}
 		}
 
 		// Add tools if provided
@@ -189,6 +190,10 @@ func (c *Client) Stream(ctx context.Context, opts llm.CompletionOptions) <-chan
 			params.Tools = convertAnthropicTools(opts.Tools)
 		}
 
+		if opts.EnablePromptCache == nil || *opts.EnablePromptCache {
+			applyPromptCache(&params)
+		}
+
 		// Log request
 		log.LogRequestCtx(ctx, c.name, opts.Model, opts)
 
@@ -231,6 +236,11 @@ func (c *Client) Stream(ctx context.Context, opts llm.CompletionOptions) <-chan
 				case "input_json_delta":
 					state.EmitToolInput(ch, currentToolID, delta.Delta.PartialJSON)
 					currentToolInput.WriteString(delta.Delta.PartialJSON)
+				case "citations_delta":
+					// Only web results carry a link; document citations point
+					// into the request itself.
+					c := delta.Delta.Citation
+					state.AddCitation(c.Title, c.URL)
 				}
 
 			case "content_block_stop":
@@ -263,7 +273,7 @@ func (c *Client) Stream(ctx context.Context, opts llm.CompletionOptions) <-chan
 		}
 
 		if err := stream.Err(); err != nil {
-			state.Fail(ch, err)
+			state.Fail(ch, apiError(err))
 			return
 		}
 
@@ -424,6 +434,32 @@ func mergeConsecutiveMessages(msgs []anthropic.MessageParam) []anthropic.Message
 	return merged
 }
 
+// applyPromptCache sets cache breakpoints so that Anthropic caches the
+// request prefix across turns: the last tool definition, the system prompt,
+// and the last block of the conversation that can carry cache_control
+// (thinking blocks cannot). Each breakpoint caches everything before it, so
+// marking only the last eligible block of each part keeps within the API's
+// limit of four breakpoints per request.
+func applyPromptCache(params *anthropic.MessageNewParams) {
+	if n := len(params.Tools); n > 0 {
+		if cc := params.Tools[n-1].GetCacheControl(); cc != nil {
+			*cc = anthropic.NewCacheControlEphemeralParam()
+		}
+	}
+	if n := len(params.System); n > 0 {
+		params.System[n-1].CacheControl = anthropic.NewCacheControlEphemeralParam()
+	}
+	if n := len(params.Messages); n > 0 {
+		content := params.Messages[n-1].Content
+		for i := len(content) - 1; i >= 0; i-- {
+			if cc := content[i].GetCacheControl(); cc != nil {
+				*cc = anthropic.NewCacheControlEphemeralParam()
+				return
+			}
+		}
+	}
+}
+
 // convertAnthropicTools converts generic llm.ToolSchema definitions to the Anthropic SDK format.
 // The JSON Schema "required" field may arrive as []string or []any (from JSON decoding);
 // anyStrings normalises both forms.
@@ -497,3 +533,13 @@ func assistantContentBlocks(msg core.Message, thinkingBudget int64) []anthropic.
 
 // Ensure Client implements Provider
 var _ llm.Provider = (*Client)(nil)
+
+// apiError attaches the HTTP status of an Anthropic API error so failed
+// requests can be retried.
+func apiError(err error) error {
+	var apierr *anthropic.Error
+	if errors.As(err, &apierr) && apierr.Response != nil {
+		return llm.NewAPIError(err, apierr.StatusCode, apierr.Response.Header)
+	}
+	return err
+}
@@ -1,10 +1,13 @@
 package anthropic
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/llm"
 )
 
 func TestToolIDSanitizer_ValidIDPassthrough(t *testing.T) {
@@ -232,3 +235,66 @@ func TestSanitizeToolResults_AllToolUsesOrphaned(t *testing.T) {
 		t.Fatalf("expected 0 tool_calls after sanitization, got %d", len(result[0].ToolCalls))
 	}
 }
+
+func TestApplyPromptCache_MarksLastEligibleBlocks(t *testing.T) {
+	params := anthropic.MessageNewParams{
+		System: []anthropic.TextBlockParam{{Text: "system"}},
+		Tools: convertAnthropicTools([]llm.ToolSchema{
+			{Name: "Read", Parameters: map[string]any{"type": "object"}},
+			{Name: "Bash", Parameters: map[string]any{"type": "object"}},
+		}),
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewTextBlock("first question")),
+			anthropic.NewAssistantMessage(anthropic.NewTextBlock("first answer")),
+			anthropic.NewUserMessage(
+				anthropic.NewTextBlock("second question"),
+				anthropic.NewTextBlock("with context"),
+			),
+		},
+	}
+	applyPromptCache(&params)
+
+	raw, err := json.Marshal(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(raw), `"cache_control"`); got != 3 {
+		t.Errorf("cache_control count = %d, want 3 (tools, system, last message):\n%s", got, raw)
+	}
+	if params.Tools[0].OfTool.CacheControl.Type != "" || params.Tools[1].OfTool.CacheControl.Type == "" {
+		t.Error("only the last tool should carry cache_control")
+	}
+	if params.System[0].CacheControl.Type == "" {
+		t.Error("system prompt should carry cache_control")
+	}
+	last := params.Messages[2].Content
+	if last[0].OfText.CacheControl.Type != "" || last[1].OfText.CacheControl.Type == "" {
+		t.Error("only the last block of the last message should carry cache_control")
+	}
+	for _, block := range params.Messages[0].Content {
+		if block.OfText.CacheControl.Type != "" {
+			t.Error("earlier messages should not carry cache_control")
+		}
+	}
+}
+
+func TestApplyPromptCache_SkipsThinkingBlocks(t *testing.T) {
+	params := anthropic.MessageNewParams{
+		Messages: []anthropic.MessageParam{
+			anthropic.NewAssistantMessage(
+				anthropic.NewTextBlock("answer"),
+				anthropic.NewThinkingBlock("sig", "reasoning"),
+			),
+		},
+	}
+	applyPromptCache(&params)
+
+	content := params.Messages[0].Content
+	if content[0].OfText.CacheControl.Type == "" {
+		t.Error("the last block that can carry cache_control should be marked")
+	}
+	raw, _ := json.Marshal(params)
+	if got := strings.Count(string(raw), `"cache_control"`); got != 1 {
+		t.Errorf("cache_control count = %d, want 1:\n%s", got, raw)
+	}
+}
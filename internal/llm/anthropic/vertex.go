@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
 	"github.com/anthropics/anthropic-sdk-go/vertex"
 
 	"github.com/yanmxa/gencode/internal/llm"
@@ -68,8 +69,10 @@ func NewVertexClient(ctx context.Context) (llm.Provider, error) {
 	}
 	projectID := os.Getenv("ANTHROPIC_VERTEX_PROJECT_ID")
 
+	// The llm package retries failed streams itself and shows progress.
 	client := anthropic.NewClient(
 		vertex.WithGoogleAuth(ctx, region, projectID),
+		option.WithMaxRetries(0),
 	)
 
 	baseClient := NewClient(client, "anthropic:vertex")
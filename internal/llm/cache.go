@@ -0,0 +1,202 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// responseCache replays earlier responses to byte-identical requests from
+// disk. It is meant for iterative prompt development, so only plain answers
+// are stored: a response that calls tools is never cached because replaying
+// it would skip side effects the model expects to have happened.
+type responseCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// cachedResponse is the on-disk form of a recorded stream.
+type cachedResponse struct {
+	CreatedAt time.Time           `json:"created_at"`
+	Chunks    []cachedChunk       `json:"chunks"`
+	Response  *CompletionResponse `json:"response"`
+}
+
+type cachedChunk struct {
+	Type ChunkType `json:"type"`
+	Text string    `json:"text"`
+}
+
+var respCache = struct {
+	mu    sync.RWMutex
+	cache *responseCache
+}{}
+
+// SetResponseCache enables the on-disk response cache in dir with the given
+// TTL. A zero or negative ttl, or an empty dir, disables caching.
+func SetResponseCache(dir string, ttl time.Duration) {
+	respCache.mu.Lock()
+	defer respCache.mu.Unlock()
+	if dir == "" || ttl <= 0 {
+		respCache.cache = nil
+		return
+	}
+	respCache.cache = &responseCache{dir: dir, ttl: ttl}
+}
+
+// DefaultResponseCacheDir returns ~/.gen/cache/responses.
+func DefaultResponseCacheDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".gen", "cache", "responses")
+}
+
+func currentResponseCache() *responseCache {
+	respCache.mu.RLock()
+	defer respCache.mu.RUnlock()
+	return respCache.cache
+}
+
+// StreamCompletion streams a completion from p, replaying a cached response
+// when the response cache is enabled and holds a fresh entry for opts.
+// Rate-limited and server-error requests are retried per SetRetryConfig, and
+// an unknown model fails with a ModelNotFoundError. For models that do not
+// accept images, a new message with images fails with ErrImagesNotSupported
+// and images from earlier messages are left out, with a note.
+func StreamCompletion(ctx context.Context, p Provider, opts CompletionOptions) <-chan StreamChunk {
+	if err := checkNewImages(p, opts); err != nil {
+		ch := make(chan StreamChunk, 1)
+		ch <- StreamChunk{Type: ChunkTypeError, Error: err}
+		close(ch)
+		return ch
+	}
+	opts = dropUnsupportedImages(p, opts)
+	stream := func() <-chan StreamChunk {
+		return streamModelErrors(ctx, p.Name(), opts.Model, streamRetrying(ctx, p, opts))
+	}
+	c := currentResponseCache()
+	if c == nil {
+		return stream()
+	}
+
+	key, err := responseCacheKey(p.Name(), opts)
+	if err != nil {
+		return stream()
+	}
+	if entry, ok := c.load(key); ok {
+		return replayResponse(ctx, entry)
+	}
+	return c.record(ctx, key, stream())
+}
+
+// responseCacheKey hashes everything that determines the model's answer.
+func responseCacheKey(provider string, opts CompletionOptions) (string, error) {
+	data, err := json.Marshal(struct {
+		Provider string
+		CompletionOptions
+	}{provider, opts})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (c *responseCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+func (c *responseCache) load(key string) (*cachedResponse, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry cachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Response == nil {
+		return nil, false
+	}
+	if time.Since(entry.CreatedAt) > c.ttl {
+		_ = os.Remove(c.path(key))
+		return nil, false
+	}
+	return &entry, true
+}
+
+func (c *responseCache) store(key string, entry *cachedResponse) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return
+	}
+	tmp := c.path(key) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, c.path(key))
+}
+
+// record forwards src unchanged and stores the stream once it completes
+// without error or tool calls. A stream cut short by cancelling ctx is never
+// stored, even if the provider still reports it done.
+func (c *responseCache) record(ctx context.Context, key string, src <-chan StreamChunk) <-chan StreamChunk {
+	out := make(chan StreamChunk, 8)
+	go func() {
+		defer close(out)
+		entry := &cachedResponse{}
+		cacheable := true
+		for chunk := range src {
+			switch chunk.Type {
+			case ChunkTypeText, ChunkTypeThinking:
+				entry.Chunks = append(entry.Chunks, cachedChunk{Type: chunk.Type, Text: chunk.Text})
+			case ChunkTypeToolStart, ChunkTypeToolInput, ChunkTypeError:
+				cacheable = false
+			case ChunkTypeDone:
+				if chunk.Response == nil || len(chunk.Response.ToolCalls) > 0 || ctx.Err() != nil {
+					cacheable = false
+				} else if cacheable {
+					entry.Response = chunk.Response
+					entry.CreatedAt = time.Now()
+					c.store(key, entry)
+				}
+			}
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				for range src {
+				}
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// replayResponse emits a cached stream as if it came from the provider.
+func replayResponse(ctx context.Context, entry *cachedResponse) <-chan StreamChunk {
+	out := make(chan StreamChunk, 8)
+	go func() {
+		defer close(out)
+		for _, chunk := range entry.Chunks {
+			select {
+			case out <- StreamChunk{Type: chunk.Type, Text: chunk.Text}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		resp := *entry.Response
+		select {
+		case out <- StreamChunk{Type: ChunkTypeDone, Response: &resp}:
+		case <-ctx.Done():
+		}
+	}()
+	return out
+}
@@ -0,0 +1,114 @@
+package llm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+func enableTestResponseCache(t *testing.T, ttl time.Duration) string {
+	t.Helper()
+	dir := t.TempDir()
+	SetResponseCache(dir, ttl)
+	t.Cleanup(func() { SetResponseCache("", 0) })
+	return dir
+}
+
+func TestResponseCache_ReplaysIdenticalRequest(t *testing.T) {
+	enableTestResponseCache(t, time.Hour)
+	p := &mockLLMProvider{responses: []CompletionResponse{
+		{Content: "first", StopReason: "end_turn"},
+		{Content: "second", StopReason: "end_turn"},
+	}}
+	opts := CompletionOptions{
+		Model:        "m",
+		SystemPrompt: "sys",
+		Messages:     []core.Message{core.UserMessage("hello", nil)},
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err := Complete(context.Background(), p, opts)
+		if err != nil {
+			t.Fatalf("Complete #%d: %v", i+1, err)
+		}
+		if resp.Content != "first" {
+			t.Errorf("Complete #%d content = %q, want %q", i+1, resp.Content, "first")
+		}
+	}
+	if p.callIdx != 1 {
+		t.Errorf("provider called %d times, want 1", p.callIdx)
+	}
+
+	// A different prompt misses the cache.
+	opts.Messages = []core.Message{core.UserMessage("bye", nil)}
+	resp, err := Complete(context.Background(), p, opts)
+	if err != nil || resp.Content != "second" {
+		t.Errorf("different request = (%q, %v), want provider response", resp.Content, err)
+	}
+}
+
+func TestResponseCache_SkipsToolCalls(t *testing.T) {
+	enableTestResponseCache(t, time.Hour)
+	p := &mockLLMProvider{responses: []CompletionResponse{
+		{ToolCalls: []core.ToolCall{{ID: "1", Name: "Bash", Input: `{"command":"ls"}`}}, StopReason: "tool_use"},
+		{Content: "again", StopReason: "end_turn"},
+	}}
+	opts := CompletionOptions{Model: "m", Messages: []core.Message{core.UserMessage("list", nil)}}
+
+	_, _ = Complete(context.Background(), p, opts)
+	resp, _ := Complete(context.Background(), p, opts)
+	if resp.Content != "again" || p.callIdx != 2 {
+		t.Errorf("tool-using response was replayed from cache (calls=%d, content=%q)", p.callIdx, resp.Content)
+	}
+}
+
+func TestResponseCache_ExpiresAfterTTL(t *testing.T) {
+	enableTestResponseCache(t, time.Nanosecond)
+	p := &mockLLMProvider{responses: []CompletionResponse{
+		{Content: "first", StopReason: "end_turn"},
+		{Content: "second", StopReason: "end_turn"},
+	}}
+	opts := CompletionOptions{Model: "m", Messages: []core.Message{core.UserMessage("hi", nil)}}
+
+	_, _ = Complete(context.Background(), p, opts)
+	time.Sleep(time.Millisecond)
+	resp, _ := Complete(context.Background(), p, opts)
+	if resp.Content != "second" {
+		t.Errorf("expired entry was replayed: got %q", resp.Content)
+	}
+}
+
+func TestResponseCache_CancelledStreamIsDrainedAndNotStored(t *testing.T) {
+	c := &responseCache{dir: t.TempDir(), ttl: time.Hour}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := make(chan StreamChunk)
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		defer close(src)
+		for i := 0; i < 20; i++ {
+			src <- StreamChunk{Type: ChunkTypeText, Text: "x"}
+		}
+		<-ctx.Done()
+		src <- StreamChunk{Type: ChunkTypeDone, Response: &CompletionResponse{Content: "partial", StopReason: "end_turn"}}
+	}()
+
+	out := c.record(ctx, "key", src)
+	<-out
+	cancel()
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("provider stream still blocked after cancellation")
+	}
+	for range out {
+	}
+	if _, ok := c.load("key"); ok {
+		t.Error("cancelled stream was stored in the cache")
+	}
+}
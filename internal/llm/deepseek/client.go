@@ -0,0 +1,116 @@
+// Package deepseek implements the Provider interface using the DeepSeek API.
+// DeepSeek's API is OpenAI-compatible, so we reuse the openai-go SDK with a custom base URL.
+package deepseek
+
+import (
+	"context"
+	"slices"
+
+	"github.com/openai/openai-go/v3"
+
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/llm/openaicompat"
+)
+
+// models is DeepSeek's model list. Its /models endpoint returns only IDs,
+// so limits come from here.
+var models = []llm.ModelInfo{
+	{
+		ID:               "deepseek-chat",
+		Name:             "DeepSeek Chat",
+		DisplayName:      "DeepSeek Chat",
+		InputTokenLimit:  128000,
+		OutputTokenLimit: 8192,
+	},
+	{
+		ID:               "deepseek-reasoner",
+		Name:             "DeepSeek Reasoner",
+		DisplayName:      "DeepSeek Reasoner",
+		InputTokenLimit:  128000,
+		OutputTokenLimit: 65536,
+	},
+}
+
+// Both models share one price, in USD per million tokens.
+var pricing = llm.Pricing{Input: 0.28, Output: 0.42, CacheRead: 0.028}
+
+func init() {
+	for _, m := range models {
+		llm.RegisterPricing(m.ID, pricing)
+	}
+}
+
+// Client implements the Provider interface for DeepSeek using the OpenAI SDK.
+type Client struct {
+	client openai.Client
+	name   string
+}
+
+// NewClient creates a new DeepSeek client with the given OpenAI SDK client.
+func NewClient(client openai.Client, name string) *Client {
+	return &Client{
+		client: client,
+		name:   name,
+	}
+}
+
+// Name returns the provider name.
+func (c *Client) Name() string {
+	return c.name
+}
+
+// SupportsImages reports false: neither DeepSeek model reads images.
+func (c *Client) SupportsImages(model string) bool {
+	return false
+}
+
+// convertAssistant sends reasoning back only when the message still carries
+// it; see currentTurnReasoning.
+func convertAssistant(msg core.Message) openai.ChatCompletionMessageParamUnion {
+	if msg.Thinking != "" {
+		return openaicompat.AssistantMessageWithReasoning(msg, msg.Thinking)
+	}
+	return openaicompat.DefaultAssistantMessage(msg)
+}
+
+// currentTurnReasoning drops reasoning from assistant messages that answered
+// earlier user questions. DeepSeek rejects reasoning_content from previous
+// questions but needs it back during the tool calls of the current one.
+func currentTurnReasoning(msgs []core.Message) []core.Message {
+	last := -1
+	for i, m := range msgs {
+		if m.Role == core.RoleUser && m.ToolResult == nil {
+			last = i
+		}
+	}
+	out := slices.Clone(msgs)
+	for i := range out[:max(last, 0)] {
+		out[i].Thinking = ""
+	}
+	return out
+}
+
+// Stream sends a completion request and returns a channel of streaming chunks.
+// deepseek-reasoner's reasoning_content is streamed as thinking.
+func (c *Client) Stream(ctx context.Context, opts llm.CompletionOptions) <-chan llm.StreamChunk {
+	opts.Messages = currentTurnReasoning(opts.Messages)
+	return openaicompat.StreamChatCompletions(ctx, openaicompat.ChatStreamConfig{
+		Client:           c.client,
+		ProviderName:     c.name,
+		Options:          opts,
+		ConvertAssistant: convertAssistant,
+		ExtractReasoning: true,
+	})
+}
+
+// ListModels returns DeepSeek's known models.
+func (c *Client) ListModels(ctx context.Context) ([]llm.ModelInfo, error) {
+	return slices.Clone(models), nil
+}
+
+// Ensure Client implements Provider
+var (
+	_ llm.Provider       = (*Client)(nil)
+	_ llm.VisionProvider = (*Client)(nil)
+)
@@ -0,0 +1,109 @@
+package deepseek
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/llm"
+)
+
+type captureTransport struct {
+	body []byte
+}
+
+func (t *captureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		t.body, _ = io.ReadAll(req.Body)
+	}
+
+	streamBody := "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":{\"reasoning_content\":\"weighing options\"}}]}\n\n" +
+		"data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"ok\"},\"finish_reason\":\"stop\"}]}\n\n" +
+		"data: [DONE]\n\n"
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:       io.NopCloser(strings.NewReader(streamBody)),
+	}, nil
+}
+
+func newTestClient(transport http.RoundTripper) *Client {
+	return NewClient(openai.NewClient(
+		option.WithAPIKey("test"),
+		option.WithBaseURL("https://example.com"),
+		option.WithHTTPClient(&http.Client{Transport: transport}),
+	), "deepseek:test")
+}
+
+func TestDeepSeekStreamsReasoningAsThinking(t *testing.T) {
+	transport := &captureTransport{}
+	c := newTestClient(transport)
+
+	messages := []core.Message{
+		{Role: core.RoleUser, Content: "first question"},
+		{Role: core.RoleAssistant, Content: "first answer", Thinking: "old reasoning"},
+		{Role: core.RoleUser, Content: "second question"},
+		{Role: core.RoleAssistant, Thinking: "current reasoning", ToolCalls: []core.ToolCall{{ID: "tc1", Name: "Read", Input: "{}"}}},
+		{Role: core.RoleUser, ToolResult: &core.ToolResult{ToolCallID: "tc1", Content: "file"}},
+	}
+
+	var thinking, text string
+	for chunk := range c.Stream(context.Background(), llm.CompletionOptions{Model: "deepseek-reasoner", Messages: messages}) {
+		switch chunk.Type {
+		case llm.ChunkTypeThinking:
+			thinking += chunk.Text
+		case llm.ChunkTypeText:
+			text += chunk.Text
+		case llm.ChunkTypeError:
+			t.Fatalf("stream error: %v", chunk.Error)
+		}
+	}
+	if thinking != "weighing options" || text != "ok" {
+		t.Errorf("thinking=%q text=%q", thinking, text)
+	}
+
+	var payload struct {
+		Messages []map[string]any `json:"messages"`
+	}
+	if err := json.Unmarshal(transport.body, &payload); err != nil {
+		t.Fatalf("invalid json body: %v", err)
+	}
+	var reasoning []any
+	for _, msg := range payload.Messages {
+		if msg["role"] == "assistant" {
+			reasoning = append(reasoning, msg["reasoning_content"])
+		}
+	}
+	// Reasoning from an earlier question is dropped; the current tool loop keeps it.
+	if len(reasoning) != 2 || reasoning[0] != nil || reasoning[1] != "current reasoning" {
+		t.Errorf("assistant reasoning_content = %v, want [<nil> current reasoning]", reasoning)
+	}
+	if messages[1].Thinking != "old reasoning" {
+		t.Error("Stream modified the caller's messages")
+	}
+}
+
+func TestDeepSeekListModelsAndPricing(t *testing.T) {
+	models, err := newTestClient(http.DefaultTransport).ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	if len(models) != 2 || models[0].ID != "deepseek-chat" || models[1].ID != "deepseek-reasoner" {
+		t.Fatalf("models = %+v", models)
+	}
+	if models[0].OutputTokenLimit != 8192 || models[1].InputTokenLimit != 128000 {
+		t.Errorf("unexpected limits: %+v", models)
+	}
+	if p, ok := llm.LookupPricing(nil, "deepseek-reasoner"); !ok || p.Output != 0.42 {
+		t.Errorf("LookupPricing(deepseek-reasoner) = %+v, %v", p, ok)
+	}
+}
@@ -0,0 +1,109 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// EmbeddingProvider is an optional interface for providers that can turn
+// text into embedding vectors. Providers without an embeddings endpoint
+// simply don't implement it.
+type EmbeddingProvider interface {
+	// GetEmbeddings returns one vector per input text, in input order.
+	GetEmbeddings(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// ErrEmbeddingsUnsupported is returned when no provider can generate embeddings.
+var ErrEmbeddingsUnsupported = errors.New("no connected provider supports embeddings")
+
+// GetEmbeddings embeds texts with p, or returns ErrEmbeddingsUnsupported
+// when p does not implement EmbeddingProvider.
+func GetEmbeddings(ctx context.Context, p Provider, texts []string) ([][]float32, error) {
+	ep, ok := p.(EmbeddingProvider)
+	if !ok {
+		return nil, ErrEmbeddingsUnsupported
+	}
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	vectors, err := ep.GetEmbeddings(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("%s returned %d embeddings for %d inputs", p.Name(), len(vectors), len(texts))
+	}
+	return vectors, nil
+}
+
+// FindEmbeddingProvider returns a provider that can generate embeddings.
+// The preferred provider is used when it supports them; otherwise the
+// store's connected providers are tried, so embeddings keep working while
+// chatting with a provider that has no embeddings endpoint.
+func FindEmbeddingProvider(ctx context.Context, preferred Provider, store *Store) (Provider, error) {
+	if _, ok := preferred.(EmbeddingProvider); ok {
+		return preferred, nil
+	}
+	if store == nil {
+		return nil, ErrEmbeddingsUnsupported
+	}
+	connections := store.GetConnections()
+	names := make([]string, 0, len(connections))
+	for name := range connections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p, err := GetProvider(ctx, Name(name), connections[name].AuthMethod)
+		if err != nil {
+			continue
+		}
+		if _, ok := p.(EmbeddingProvider); ok {
+			return p, nil
+		}
+	}
+	return nil, ErrEmbeddingsUnsupported
+}
+
+// CosineSimilarity returns the cosine of the angle between a and b, or 0
+// when either vector is empty, zero, or the lengths differ.
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// EmbeddingMatch is one ranked result from RankBySimilarity.
+type EmbeddingMatch struct {
+	Index int // position in the candidate slice
+	Score float64
+}
+
+// RankBySimilarity orders candidates by cosine similarity to query, best
+// first. Ties keep candidate order. A positive limit truncates the result.
+func RankBySimilarity(query []float32, candidates [][]float32, limit int) []EmbeddingMatch {
+	matches := make([]EmbeddingMatch, len(candidates))
+	for i, c := range candidates {
+		matches[i] = EmbeddingMatch{Index: i, Score: CosineSimilarity(query, c)}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+type embeddingStub struct {
+	mockLLMProvider
+	vectors [][]float32
+}
+
+func (s *embeddingStub) GetEmbeddings(context.Context, []string) ([][]float32, error) {
+	return s.vectors, nil
+}
+
+func TestGetEmbeddings(t *testing.T) {
+	ctx := context.Background()
+
+	if _, err := GetEmbeddings(ctx, &mockLLMProvider{}, []string{"a"}); !errors.Is(err, ErrEmbeddingsUnsupported) {
+		t.Errorf("provider without embeddings: err = %v, want ErrEmbeddingsUnsupported", err)
+	}
+
+	stub := &embeddingStub{vectors: [][]float32{{1, 0}}}
+	if _, err := GetEmbeddings(ctx, stub, []string{"a", "b"}); err == nil {
+		t.Error("expected error when vector count does not match input count")
+	}
+	got, err := GetEmbeddings(ctx, stub, []string{"a"})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("GetEmbeddings = %v, %v", got, err)
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float32
+		want float64
+	}{
+		{"identical", []float32{1, 2, 3}, []float32{1, 2, 3}, 1},
+		{"orthogonal", []float32{1, 0}, []float32{0, 1}, 0},
+		{"opposite", []float32{1, 1}, []float32{-1, -1}, -1},
+		{"length mismatch", []float32{1}, []float32{1, 0}, 0},
+		{"zero vector", []float32{0, 0}, []float32{1, 0}, 0},
+	}
+	for _, tt := range tests {
+		if got := CosineSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: CosineSimilarity = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRankBySimilarity(t *testing.T) {
+	query := []float32{1, 0}
+	candidates := [][]float32{{0, 1}, {1, 0}, {1, 1}, {0, 1}}
+
+	got := RankBySimilarity(query, candidates, 3)
+	want := []int{1, 2, 0}
+	if len(got) != len(want) {
+		t.Fatalf("got %d matches, want %d", len(got), len(want))
+	}
+	for i, m := range got {
+		if m.Index != want[i] {
+			t.Errorf("match %d = candidate %d, want %d", i, m.Index, want[i])
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FallbackEntry is one model in the fallback chain.
+type FallbackEntry struct {
+	Provider Name   `json:"provider"`
+	ModelID  string `json:"model"`
+}
+
+// String returns the entry as provider:model.
+func (e FallbackEntry) String() string {
+	return string(e.Provider) + ":" + e.ModelID
+}
+
+// ParseFallbackEntry parses "provider:model" or "provider/model".
+func ParseFallbackEntry(s string) (FallbackEntry, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexAny(s, ":/")
+	if i <= 0 || i == len(s)-1 {
+		return FallbackEntry{}, fmt.Errorf("invalid fallback %q: want provider:model", s)
+	}
+	return FallbackEntry{Provider: Name(s[:i]), ModelID: s[i+1:]}, nil
+}
+
+// Failover lets a Client retry a failed stream on other models.
+//
+// When a stream fails before producing any output with a rate limit, server
+// error, or dropped connection, the Client walks Chain in order and streams
+// the same request from the first entry that connects and answers, emitting
+// a ChunkTypeFallback before each attempt. Other errors are returned as is.
+// Each request starts again from the Client's own model.
+type Failover struct {
+	// Chain returns the fallback chain. It is read on every failure, so
+	// edits apply to the next request.
+	Chain func() []FallbackEntry
+	// Connect returns a provider for an entry, or an error when it is not
+	// connected.
+	Connect func(ctx context.Context, provider Name) (Provider, error)
+	// OnFailover, if set, is called before each fallback attempt.
+	OnFailover func(from string, to FallbackEntry, err error)
+}
+
+// SetFailover enables failover for later streams. Nil disables it.
+func (l *Client) SetFailover(f *Failover) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.failover = f
+}
+
+// streamWithFailover streams opts from p, falling back along the failover
+// chain when the stream fails before any output with a retryable error
+// (rate limit, server error, or dropped connection).
+func (l *Client) streamWithFailover(ctx context.Context, p Provider, opts CompletionOptions) <-chan StreamChunk {
+	l.mu.RLock()
+	f := l.failover
+	maxTokens := l.maxTokens
+	l.mu.RUnlock()
+	if f == nil || f.Chain == nil || f.Connect == nil {
+		return StreamCompletion(ctx, p, opts)
+	}
+
+	out := make(chan StreamChunk, 8)
+	go func() {
+		defer close(out)
+		err := forwardStream(ctx, StreamCompletion(ctx, p, opts), out)
+		if err == nil {
+			return
+		}
+		// A bad request or rejected credentials is not an outage; other
+		// models would only hide the error.
+		chain := f.Chain()
+		if _, ok := retryableError(err); !ok {
+			chain = nil
+		}
+		from := p.Name() + ":" + opts.Model
+		for _, entry := range chain {
+			if ctx.Err() != nil || (entry.ModelID == opts.Model && isProvider(p, entry.Provider)) {
+				continue
+			}
+			fp, connErr := f.Connect(ctx, entry.Provider)
+			if connErr != nil {
+				continue
+			}
+			if f.OnFailover != nil {
+				f.OnFailover(from, entry, err)
+			}
+			select {
+			case out <- StreamChunk{Type: ChunkTypeFallback, Text: fallbackNotice(from, entry, err)}:
+			case <-ctx.Done():
+				return
+			}
+			fopts := opts
+			fopts.Model = entry.ModelID
+			fopts.MaxTokens = resolveMaxTokens(maxTokens, fp, entry.ModelID)
+			if err = forwardStream(ctx, StreamCompletion(ctx, fp, fopts), out); err == nil {
+				return
+			}
+			from = entry.String()
+		}
+		select {
+		case out <- StreamChunk{Type: ChunkTypeError, Error: err}:
+		case <-ctx.Done():
+		}
+	}()
+	return out
+}
+
+// fallbackNotice describes a switch to a fallback model, e.g.
+// "anthropic:claude-x failed (rate limited); switched to openai:gpt-y".
+func fallbackNotice(from string, to FallbackEntry, err error) string {
+	reason := "request failed"
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		reason = statusReason(apiErr.StatusCode)
+	}
+	return fmt.Sprintf("%s failed (%s); switched to %s", from, reason, to)
+}
+
+// forwardStream copies src to out. It returns the stream's error, without
+// forwarding it, only when the stream failed before any output; later
+// errors are forwarded because the partial answer cannot be retried.
+// Notices and fallback switches are forwarded but do not count as output.
+// When ctx ends it drains src so the producer is not left blocked.
+func forwardStream(ctx context.Context, src <-chan StreamChunk, out chan<- StreamChunk) error {
+	started := false
+	for chunk := range src {
+		if chunk.Type == ChunkTypeError && !started {
+			for range src {
+			}
+			return chunk.Error
+		}
+		if chunk.Type != ChunkTypeNotice && chunk.Type != ChunkTypeFallback {
+			started = true
+		}
+		select {
+		case out <- chunk:
+		case <-ctx.Done():
+			for range src {
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// isProvider reports whether p is an instance of the named provider.
+// Provider names may carry an auth method suffix ("moonshot:api_key").
+func isProvider(p Provider, name Name) bool {
+	n, _, _ := strings.Cut(p.Name(), ":")
+	return n == string(name)
+}
+
+// StoreFailover returns a Failover that follows the chain saved in store and
+// connects entries whose provider has a saved connection.
+func StoreFailover(store *Store) *Failover {
+	if store == nil {
+		return nil
+	}
+	return &Failover{
+		Chain: store.GetFallbackChain,
+		Connect: func(ctx context.Context, provider Name) (Provider, error) {
+			conn, ok := store.GetConnection(provider)
+			if !ok {
+				return nil, fmt.Errorf("provider %s is not connected", provider)
+			}
+			return GetProvider(ctx, provider, conn.AuthMethod)
+		},
+	}
+}
@@ -0,0 +1,209 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+// scriptedProvider streams one queued script per call.
+type scriptedProvider struct {
+	name    string
+	scripts [][]StreamChunk
+	models  []string
+}
+
+func (p *scriptedProvider) Stream(_ context.Context, opts CompletionOptions) <-chan StreamChunk {
+	p.models = append(p.models, opts.Model)
+	var script []StreamChunk
+	if len(p.scripts) > 0 {
+		script, p.scripts = p.scripts[0], p.scripts[1:]
+	}
+	ch := make(chan StreamChunk, len(script))
+	for _, c := range script {
+		ch <- c
+	}
+	close(ch)
+	return ch
+}
+
+func (p *scriptedProvider) ListModels(context.Context) ([]ModelInfo, error) { return nil, nil }
+func (p *scriptedProvider) Name() string                                    { return p.name }
+
+// errorScript fails with a 503, which fails over.
+func errorScript(msg string) []StreamChunk {
+	return []StreamChunk{{Type: ChunkTypeError, Error: NewAPIError(errors.New(msg), 503, nil)}}
+}
+
+func textScript(text string) []StreamChunk {
+	return []StreamChunk{
+		{Type: ChunkTypeText, Text: text},
+		{Type: ChunkTypeDone, Response: &CompletionResponse{Content: text, StopReason: "end_turn"}},
+	}
+}
+
+func collectInfer(t *testing.T, c *Client) (string, error) {
+	t.Helper()
+	ch, err := c.Infer(context.Background(), core.InferRequest{Messages: []core.Message{core.UserMessage("hi", nil)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var text string
+	for chunk := range ch {
+		if chunk.Err != nil {
+			return text, chunk.Err
+		}
+		text += chunk.Text
+	}
+	return text, nil
+}
+
+func TestClientFailsOverAlongChain(t *testing.T) {
+	recordRetryWaits(t, RetryConfig{})
+	primary := &scriptedProvider{name: "anthropic", scripts: [][]StreamChunk{errorScript("overloaded"), textScript("ok again")}}
+	backup := &scriptedProvider{name: "openai", scripts: [][]StreamChunk{textScript("from backup")}}
+	providers := map[Name]Provider{"openai": backup}
+
+	var switched []string
+	c := NewClient(primary, "claude-sonnet-4-6", 1024)
+	c.SetFailover(&Failover{
+		Chain: func() []FallbackEntry {
+			return []FallbackEntry{
+				{Provider: Anthropic, ModelID: "claude-sonnet-4-6"}, // the primary itself is skipped
+				{Provider: Google, ModelID: "gemini-2.5-pro"},       // not connected
+				{Provider: OpenAI, ModelID: "gpt-5"},
+			}
+		},
+		Connect: func(_ context.Context, name Name) (Provider, error) {
+			if p, ok := providers[name]; ok {
+				return p, nil
+			}
+			return nil, errors.New("not connected")
+		},
+		OnFailover: func(from string, to FallbackEntry, err error) {
+			switched = append(switched, from+" -> "+to.String()+": "+err.Error())
+		},
+	})
+
+	text, err := collectInfer(t, c)
+	if err != nil || text != "from backup" {
+		t.Fatalf("Infer = %q, %v; want the backup's answer", text, err)
+	}
+	if !slices.Equal(backup.models, []string{"gpt-5"}) {
+		t.Errorf("backup models = %v", backup.models)
+	}
+	if want := []string{"anthropic:claude-sonnet-4-6 -> openai:gpt-5: overloaded"}; !slices.Equal(switched, want) {
+		t.Errorf("OnFailover calls = %v, want %v", switched, want)
+	}
+
+	// The next request starts from the primary again.
+	if text, err := collectInfer(t, c); err != nil || text != "ok again" {
+		t.Fatalf("second Infer = %q, %v", text, err)
+	}
+}
+
+func TestClientFailoverKeepsPartialAnswersAndLastError(t *testing.T) {
+	recordRetryWaits(t, RetryConfig{})
+	primary := &scriptedProvider{name: "anthropic", scripts: [][]StreamChunk{
+		{{Type: ChunkTypeText, Text: "partial"}, {Type: ChunkTypeError, Error: errors.New("reset")}},
+		errorScript("down"),
+	}}
+	backup := &scriptedProvider{name: "openai", scripts: [][]StreamChunk{errorScript("also down")}}
+	c := NewClient(primary, "m", 1024)
+	c.SetFailover(&Failover{
+		Chain:   func() []FallbackEntry { return []FallbackEntry{{Provider: OpenAI, ModelID: "gpt-5"}} },
+		Connect: func(context.Context, Name) (Provider, error) { return backup, nil },
+	})
+
+	text, err := collectInfer(t, c)
+	if text != "partial" || err == nil || err.Error() != "reset" {
+		t.Fatalf("Infer = %q, %v; want the partial answer and its error", text, err)
+	}
+	if len(backup.models) != 0 {
+		t.Error("a stream that already produced output must not fail over")
+	}
+
+	if _, err := collectInfer(t, c); err == nil || err.Error() != "also down" {
+		t.Fatalf("err = %v, want the last fallback's error", err)
+	}
+}
+
+func TestClientFailoverEmitsFallbackNotice(t *testing.T) {
+	recordRetryWaits(t, RetryConfig{}) // rate limits fail over without retrying
+	primary := &scriptedProvider{name: "anthropic", scripts: [][]StreamChunk{apiErrorScript(429, "")}}
+	backup := &scriptedProvider{name: "openai", scripts: [][]StreamChunk{textScript("from backup")}}
+	c := NewClient(primary, "claude-sonnet-4-6", 1024)
+	c.SetFailover(&Failover{
+		Chain:   func() []FallbackEntry { return []FallbackEntry{{Provider: OpenAI, ModelID: "gpt-5"}} },
+		Connect: func(context.Context, Name) (Provider, error) { return backup, nil },
+	})
+
+	ch, err := c.Infer(context.Background(), core.InferRequest{Messages: []core.Message{core.UserMessage("hi", nil)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fallbacks []string
+	var text string
+	for chunk := range ch {
+		if chunk.Fallback != "" {
+			fallbacks = append(fallbacks, chunk.Fallback)
+		}
+		text += chunk.Text
+	}
+	want := []string{"anthropic:claude-sonnet-4-6 failed (rate limited); switched to openai:gpt-5"}
+	if !slices.Equal(fallbacks, want) {
+		t.Errorf("fallback chunks = %q, want %q", fallbacks, want)
+	}
+	if text != "from backup" {
+		t.Errorf("text = %q, want the backup's answer", text)
+	}
+}
+
+func TestClientFailoverSkipsNonRetryableErrors(t *testing.T) {
+	backup := &scriptedProvider{name: "openai"}
+	tests := []struct {
+		name   string
+		script []StreamChunk
+	}{
+		{"bad request", apiErrorScript(400, "")},
+		{"unauthorized", apiErrorScript(401, "")},
+		{"plain error", []StreamChunk{{Type: ChunkTypeError, Error: errors.New("invalid tool schema")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &scriptedProvider{name: "anthropic", scripts: [][]StreamChunk{tt.script}}
+			c := NewClient(primary, "claude-sonnet-4-6", 1024)
+			c.SetFailover(&Failover{
+				Chain:   func() []FallbackEntry { return []FallbackEntry{{Provider: OpenAI, ModelID: "gpt-5"}} },
+				Connect: func(context.Context, Name) (Provider, error) { return backup, nil },
+			})
+			if _, err := collectInfer(t, c); err == nil || err.Error() != tt.script[0].Error.Error() {
+				t.Errorf("err = %v, want the primary's error %v", err, tt.script[0].Error)
+			}
+			if len(backup.models) != 0 {
+				t.Errorf("failed over to %v on a non-retryable error", backup.models)
+			}
+		})
+	}
+}
+
+func TestParseFallbackEntry(t *testing.T) {
+	for in, want := range map[string]FallbackEntry{
+		"openai:gpt-5":                {Provider: OpenAI, ModelID: "gpt-5"},
+		"google/gemini-2.5-pro":       {Provider: Google, ModelID: "gemini-2.5-pro"},
+		"alibaba:qwen/qwen3-coder":    {Provider: Alibaba, ModelID: "qwen/qwen3-coder"},
+		" anthropic:claude-opus-4-1 ": {Provider: Anthropic, ModelID: "claude-opus-4-1"},
+	} {
+		if got, err := ParseFallbackEntry(in); err != nil || got != want {
+			t.Errorf("ParseFallbackEntry(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "gpt-5", ":gpt-5", "openai:"} {
+		if _, err := ParseFallbackEntry(in); err == nil {
+			t.Errorf("ParseFallbackEntry(%q) should fail", in)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// Package fake provides a scripted, network-free LLM provider for tests.
+//
+// The provider registers itself as "fake:api_key" when the package is
+// imported, so only test binaries that blank-import it (or use it directly)
+// can resolve it through llm.GetProvider. Responses are queued up front and
+// replayed as StreamChunks in order, mirroring how the MCP tests drive a
+// server through FakeTransport.
+//
+// Usage:
+//
+//	p := fake.Install(t)
+//	p.EnqueueToolCall("tc1", "Read", `{"file_path":"a.txt"}`)
+//	p.EnqueueText("done")
+//	client := llm.NewClient(p, fake.Model, 0)
+package fake
+
+import (
+	"context"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"testing"
+	"unicode"
+
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/llm"
+)
+
+// Name is the provider name the fake registers under.
+const Name llm.Name = "fake"
+
+// Model is the only model the fake provider advertises.
+const Model = "fake-model"
+
+// Meta is the registry metadata for the fake provider.
+var Meta = llm.Meta{
+	Provider:    Name,
+	AuthMethod:  llm.AuthAPIKey,
+	DisplayName: "Scripted (tests)",
+}
+
+// defaultProvider is handed out by the registry factory until Install
+// swaps in a fresh instance.
+var (
+	defaultMu       sync.Mutex
+	defaultProvider = New()
+)
+
+func init() {
+	llm.Register(Meta, func(context.Context) (llm.Provider, error) {
+		return Default(), nil
+	})
+}
+
+// Default returns the provider instance currently served by the registry.
+func Default() *Provider {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	return defaultProvider
+}
+
+// Install replaces the registered provider with a fresh, empty one and
+// restores the previous instance when the test finishes.
+func Install(t testing.TB) *Provider {
+	t.Helper()
+	p := New()
+	defaultMu.Lock()
+	prev := defaultProvider
+	defaultProvider = p
+	defaultMu.Unlock()
+	t.Cleanup(func() {
+		defaultMu.Lock()
+		defaultProvider = prev
+		defaultMu.Unlock()
+	})
+	return p
+}
+
+// Provider is an llm.Provider that replays scripted streams.
+// Each call to Stream consumes one queued script. When the queue is empty
+// it answers with a plain "no more responses" end_turn.
+type Provider struct {
+	mu       sync.Mutex
+	scripts  [][]llm.StreamChunk
+	requests []llm.CompletionOptions
+}
+
+// New creates an empty fake provider.
+func New() *Provider {
+	return &Provider{}
+}
+
+// Enqueue appends one raw stream script. The chunks are emitted verbatim, so
+// the caller is responsible for ending it with a Done or Error chunk.
+func (p *Provider) Enqueue(chunks ...llm.StreamChunk) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.scripts = append(p.scripts, chunks)
+}
+
+// EnqueueResponse queues a complete response, streamed the way real
+// providers do: text deltas, tool_start for each call, then Done.
+func (p *Provider) EnqueueResponse(resp llm.CompletionResponse) {
+	var chunks []llm.StreamChunk
+	if resp.Thinking != "" {
+		chunks = append(chunks, llm.StreamChunk{Type: llm.ChunkTypeThinking, Text: resp.Thinking})
+	}
+	if resp.Content != "" {
+		chunks = append(chunks, llm.StreamChunk{Type: llm.ChunkTypeText, Text: resp.Content})
+	}
+	for _, tc := range resp.ToolCalls {
+		chunks = append(chunks, llm.StreamChunk{Type: llm.ChunkTypeToolStart, ToolID: tc.ID, ToolName: tc.Name})
+		if tc.Input != "" {
+			chunks = append(chunks, llm.StreamChunk{Type: llm.ChunkTypeToolInput, ToolID: tc.ID, Text: tc.Input})
+		}
+	}
+	if resp.StopReason == "" {
+		resp.StopReason = "end_turn"
+		if len(resp.ToolCalls) > 0 {
+			resp.StopReason = "tool_use"
+		}
+	}
+	if resp.Usage == (llm.Usage{}) {
+		resp.Usage = llm.Usage{InputTokens: 10, OutputTokens: 5}
+	}
+	chunks = append(chunks, llm.StreamChunk{Type: llm.ChunkTypeDone, Response: &resp})
+	p.Enqueue(chunks...)
+}
+
+// EnqueueText queues an end_turn response carrying text.
+func (p *Provider) EnqueueText(text string) {
+	p.EnqueueResponse(llm.CompletionResponse{Content: text})
+}
+
+// EnqueueToolCall queues a tool_use response invoking a single tool.
+func (p *Provider) EnqueueToolCall(id, name, input string) {
+	p.EnqueueToolCalls(core.ToolCall{ID: id, Name: name, Input: input})
+}
+
+// EnqueueToolCalls queues a tool_use response invoking several tools at once.
+func (p *Provider) EnqueueToolCalls(calls ...core.ToolCall) {
+	p.EnqueueResponse(llm.CompletionResponse{ToolCalls: calls})
+}
+
+// EnqueueError queues a stream that fails immediately with err.
+func (p *Provider) EnqueueError(err error) {
+	p.Enqueue(llm.StreamChunk{Type: llm.ChunkTypeError, Error: err})
+}
+
+// Pending reports how many scripts have not been consumed yet.
+func (p *Provider) Pending() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.scripts)
+}
+
+// Requests returns a copy of every CompletionOptions received, in order.
+func (p *Provider) Requests() []llm.CompletionOptions {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]llm.CompletionOptions, len(p.requests))
+	copy(out, p.requests)
+	return out
+}
+
+// Stream implements llm.Provider.
+func (p *Provider) Stream(ctx context.Context, opts llm.CompletionOptions) <-chan llm.StreamChunk {
+	p.mu.Lock()
+	p.requests = append(p.requests, opts)
+	var script []llm.StreamChunk
+	if len(p.scripts) > 0 {
+		script = p.scripts[0]
+		p.scripts = p.scripts[1:]
+	} else {
+		script = []llm.StreamChunk{{
+			Type:     llm.ChunkTypeDone,
+			Response: &llm.CompletionResponse{Content: "no more responses", StopReason: "end_turn"},
+		}}
+	}
+	p.mu.Unlock()
+
+	ch := make(chan llm.StreamChunk)
+	go func() {
+		defer close(ch)
+		for _, chunk := range script {
+			select {
+			case ch <- chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// ListModels implements llm.Provider.
+func (p *Provider) ListModels(context.Context) ([]llm.ModelInfo, error) {
+	return []llm.ModelInfo{{ID: Model, Name: Model, DisplayName: "Fake Model"}}, nil
+}
+
+// Name implements llm.Provider.
+func (p *Provider) Name() string {
+	return string(Name)
+}
+
+// EmbeddingDims is the vector length GetEmbeddings produces.
+const EmbeddingDims = 64
+
+// GetEmbeddings implements llm.EmbeddingProvider with a deterministic
+// bag-of-words hash, so texts sharing words score as similar.
+func (p *Provider) GetEmbeddings(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		v := make([]float32, EmbeddingDims)
+		for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			h := fnv.New32a()
+			h.Write([]byte(word))
+			v[h.Sum32()%EmbeddingDims]++
+		}
+		vectors[i] = v
+	}
+	return vectors, nil
+}
+
+var _ llm.EmbeddingProvider = (*Provider)(nil)
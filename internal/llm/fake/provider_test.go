@@ -0,0 +1,97 @@
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/llm/fake"
+)
+
+func collect(ch <-chan llm.StreamChunk) []llm.StreamChunk {
+	var out []llm.StreamChunk
+	for c := range ch {
+		out = append(out, c)
+	}
+	return out
+}
+
+func TestProvider_StreamsToolCallLikeRealProviders(t *testing.T) {
+	p := fake.New()
+	p.EnqueueToolCall("tc1", "Read", `{"file_path":"a.txt"}`)
+
+	chunks := collect(p.Stream(context.Background(), llm.CompletionOptions{Model: fake.Model}))
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d: %+v", len(chunks), chunks)
+	}
+	if chunks[0].Type != llm.ChunkTypeToolStart || chunks[0].ToolName != "Read" || chunks[0].ToolID != "tc1" {
+		t.Errorf("unexpected tool_start chunk: %+v", chunks[0])
+	}
+	if chunks[1].Type != llm.ChunkTypeToolInput || chunks[1].Text != `{"file_path":"a.txt"}` {
+		t.Errorf("unexpected tool_input chunk: %+v", chunks[1])
+	}
+	done := chunks[2]
+	if done.Type != llm.ChunkTypeDone || done.Response == nil {
+		t.Fatalf("expected done chunk, got %+v", done)
+	}
+	if done.Response.StopReason != "tool_use" || len(done.Response.ToolCalls) != 1 {
+		t.Errorf("unexpected response: %+v", done.Response)
+	}
+}
+
+func TestProvider_QueueOrderAndExhaustion(t *testing.T) {
+	p := fake.New()
+	p.EnqueueText("first")
+	p.EnqueueError(errors.New("boom"))
+
+	resp, err := llm.Complete(context.Background(), p, llm.CompletionOptions{})
+	if err != nil || resp.Content != "first" {
+		t.Fatalf("first call = (%q, %v), want (\"first\", nil)", resp.Content, err)
+	}
+	if _, err := llm.Complete(context.Background(), p, llm.CompletionOptions{}); err == nil || err.Error() != "boom" {
+		t.Fatalf("second call error = %v, want boom", err)
+	}
+	resp, err = llm.Complete(context.Background(), p, llm.CompletionOptions{})
+	if err != nil || resp.Content != "no more responses" {
+		t.Fatalf("exhausted call = (%q, %v)", resp.Content, err)
+	}
+	if got := len(p.Requests()); got != 3 {
+		t.Errorf("expected 3 recorded requests, got %d", got)
+	}
+	if p.Pending() != 0 {
+		t.Errorf("expected empty queue, got %d pending", p.Pending())
+	}
+}
+
+func TestInstall_ServesProviderThroughRegistry(t *testing.T) {
+	p := fake.Install(t)
+	p.EnqueueText("via registry")
+
+	got, err := llm.GetProvider(context.Background(), fake.Name, llm.AuthAPIKey)
+	if err != nil {
+		t.Fatalf("GetProvider() error: %v", err)
+	}
+	if got != llm.Provider(p) {
+		t.Fatal("registry did not return the installed provider")
+	}
+	resp, err := llm.Complete(context.Background(), got, llm.CompletionOptions{})
+	if err != nil || resp.Content != "via registry" {
+		t.Fatalf("Complete() = (%q, %v)", resp.Content, err)
+	}
+}
+
+func TestProvider_EmbeddingsRankSharedWordsHigher(t *testing.T) {
+	p := fake.New()
+	vectors, err := llm.GetEmbeddings(context.Background(), p, []string{
+		"run the unit tests", "how do I run tests", "deploy to production",
+	})
+	if err != nil {
+		t.Fatalf("GetEmbeddings: %v", err)
+	}
+	related := llm.CosineSimilarity(vectors[0], vectors[1])
+	unrelated := llm.CosineSimilarity(vectors[0], vectors[2])
+	if related <= unrelated {
+		t.Errorf("similarity(related)=%.3f should exceed similarity(unrelated)=%.3f", related, unrelated)
+	}
+}
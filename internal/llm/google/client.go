@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	stdlog "log"
@@ -163,8 +164,8 @@ func (c *Client) Stream(ctx context.Context, opts llm.CompletionOptions) <-chan
 			config.MaxOutputTokens = int32(opts.MaxTokens)
 		}
 
-		if opts.Temperature > 0 {
-			temp := float32(opts.Temperature)
+		if opts.Temperature != nil {
+			temp := float32(*opts.Temperature)
 			config.Temperature = &temp
 		}
 
@@ -202,6 +203,10 @@ func (c *Client) Stream(ctx context.Context, opts llm.CompletionOptions) <-chan
 
 		for result, err := range c.client.Models.GenerateContentStream(ctx, opts.Model, contents, config) {
 			if err != nil {
+				var apierr genai.APIError
+				if errors.As(err, &apierr) {
+					err = llm.NewAPIError(err, apierr.Code, nil)
+				}
 				state.Fail(ch, err)
 				return
 			}
@@ -209,6 +214,7 @@ func (c *Client) Stream(ctx context.Context, opts llm.CompletionOptions) <-chan
 
 			// Process candidates
 			for _, candidate := range result.Candidates {
+				addGroundingCitations(state, candidate.GroundingMetadata)
 				if candidate.Content == nil {
 					continue
 				}
@@ -255,7 +261,8 @@ func (c *Client) Stream(ctx context.Context, opts llm.CompletionOptions) <-chan
 
 			// Handle usage
 			if result.UsageMetadata != nil {
-				state.UpdateUsage(int(result.UsageMetadata.PromptTokenCount), int(result.UsageMetadata.CandidatesTokenCount))
+				usage := result.UsageMetadata
+				state.UpdatePromptUsage(int(usage.PromptTokenCount), int(usage.CachedContentTokenCount), int(usage.CandidatesTokenCount))
 			}
 		}
 
@@ -266,6 +273,18 @@ func (c *Client) Stream(ctx context.Context, opts llm.CompletionOptions) <-chan
 	return ch
 }
 
+// addGroundingCitations records the web sources of a grounded answer.
+func addGroundingCitations(state *streamutil.State, md *genai.GroundingMetadata) {
+	if md == nil {
+		return
+	}
+	for _, chunk := range md.GroundingChunks {
+		if chunk != nil && chunk.Web != nil {
+			state.AddCitation(chunk.Web.Title, chunk.Web.URI)
+		}
+	}
+}
+
 // ListModels returns the available models for Google using the API.
 // Results are cached after a successful fetch; a failed fetch (e.g. cancelled
 // context) is not cached so subsequent calls can retry.
@@ -376,5 +395,32 @@ func (c *Client) DefaultThinkingEffort(model string) string {
 	return "off"
 }
 
-// Ensure Client implements Provider
-var _ llm.Provider = (*Client)(nil)
+// embeddingModel is the model used by GetEmbeddings.
+const embeddingModel = "gemini-embedding-001"
+
+// GetEmbeddings generates one embedding vector per input text.
+func (c *Client) GetEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+	contents := make([]*genai.Content, len(texts))
+	for i, text := range texts {
+		contents[i] = genai.NewContentFromText(text, genai.RoleUser)
+	}
+	resp, err := c.client.Models.EmbedContent(ctx, embeddingModel, contents, nil)
+	if err != nil {
+		return nil, fmt.Errorf("google embeddings: %w", err)
+	}
+	vectors := make([][]float32, 0, len(resp.Embeddings))
+	for _, e := range resp.Embeddings {
+		if e == nil {
+			vectors = append(vectors, nil)
+			continue
+		}
+		vectors = append(vectors, e.Values)
+	}
+	return vectors, nil
+}
+
+// Ensure Client implements Provider and EmbeddingProvider
+var (
+	_ llm.Provider          = (*Client)(nil)
+	_ llm.EmbeddingProvider = (*Client)(nil)
+)
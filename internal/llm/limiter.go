@@ -0,0 +1,141 @@
+package llm
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultMaxConcurrentRequests bounds simultaneous in-flight requests to a
+// single provider. Sub-agents, compaction, and the main loop all share this
+// budget so the app does not trip per-account concurrency limits on its own.
+const DefaultMaxConcurrentRequests = 4
+
+// requestLimiter is a counting semaphore for one provider.
+type requestLimiter struct {
+	slots   chan struct{}
+	mu      sync.Mutex
+	waiting int
+}
+
+func (l *requestLimiter) acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	l.mu.Lock()
+	l.waiting++
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		l.waiting--
+		l.mu.Unlock()
+	}()
+
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *requestLimiter) release() {
+	<-l.slots
+}
+
+func (l *requestLimiter) queued() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.waiting
+}
+
+var limiters = struct {
+	mu    sync.Mutex
+	max   int
+	byKey map[string]*requestLimiter
+}{
+	max:   DefaultMaxConcurrentRequests,
+	byKey: make(map[string]*requestLimiter),
+}
+
+// SetMaxConcurrentRequests sets the per-provider in-flight request limit.
+// Zero restores the default; a negative value disables limiting. Requests
+// already holding a slot are unaffected.
+func SetMaxConcurrentRequests(n int) {
+	if n == 0 {
+		n = DefaultMaxConcurrentRequests
+	}
+	limiters.mu.Lock()
+	defer limiters.mu.Unlock()
+	limiters.max = n
+	limiters.byKey = make(map[string]*requestLimiter)
+}
+
+// MaxConcurrentRequests returns the per-provider in-flight request limit
+// (negative when limiting is disabled).
+func MaxConcurrentRequests() int {
+	limiters.mu.Lock()
+	defer limiters.mu.Unlock()
+	return limiters.max
+}
+
+// QueuedRequests returns how many requests are waiting for a free slot
+// across all providers.
+func QueuedRequests() int {
+	limiters.mu.Lock()
+	defer limiters.mu.Unlock()
+	total := 0
+	for _, l := range limiters.byKey {
+		total += l.queued()
+	}
+	return total
+}
+
+func limiterFor(provider string) *requestLimiter {
+	limiters.mu.Lock()
+	defer limiters.mu.Unlock()
+	if limiters.max < 0 {
+		return nil
+	}
+	l, ok := limiters.byKey[provider]
+	if !ok {
+		l = &requestLimiter{slots: make(chan struct{}, limiters.max)}
+		limiters.byKey[provider] = l
+	}
+	return l
+}
+
+// streamLimited calls p.Stream once a slot for p's provider is free. Excess
+// requests queue until an earlier stream finishes or ctx is cancelled.
+func streamLimited(ctx context.Context, p Provider, opts CompletionOptions) <-chan StreamChunk {
+	l := limiterFor(p.Name())
+	if l == nil {
+		return p.Stream(ctx, opts)
+	}
+
+	out := make(chan StreamChunk, 8)
+	go func() {
+		defer close(out)
+		if err := l.acquire(ctx); err != nil {
+			out <- StreamChunk{Type: ChunkTypeError, Error: err}
+			return
+		}
+		defer l.release()
+
+		src := p.Stream(ctx, opts)
+		for chunk := range src {
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				// Drain so the provider's goroutine can finish before the
+				// slot is released.
+				for range src {
+				}
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -32,8 +32,10 @@ type Client struct {
 	provider       Provider
 	model          string
 	maxTokens      int
+	temperature    *float64
 	thinkingEffort string
 	tokens         TokenUsage
+	failover       *Failover
 }
 
 // NewClient wraps an existing provider as a core.LLM with streaming and
@@ -52,6 +54,7 @@ func (l *Client) Infer(ctx context.Context, req core.InferRequest) (<-chan core.
 	p := l.provider
 	model := l.model
 	maxTokens := l.maxTokens
+	temperature := l.temperature
 	thinking := l.thinkingEffort
 	l.mu.RUnlock()
 
@@ -61,10 +64,11 @@ func (l *Client) Infer(ctx context.Context, req core.InferRequest) (<-chan core.
 		Tools:          req.Tools,
 		SystemPrompt:   req.System,
 		MaxTokens:      resolveMaxTokens(maxTokens, p, model),
+		Temperature:    temperature,
 		ThinkingEffort: thinking,
 	}
 
-	srcCh := p.Stream(ctx, opts)
+	srcCh := l.streamWithFailover(ctx, p, opts)
 
 	ch := make(chan core.Chunk, 8)
 	go func() {
@@ -75,6 +79,14 @@ func (l *Client) Infer(ctx context.Context, req core.InferRequest) (<-chan core.
 				ch <- core.Chunk{Text: sc.Text}
 			case ChunkTypeThinking:
 				ch <- core.Chunk{Thinking: sc.Text}
+			case ChunkTypeNotice:
+				ch <- core.Chunk{Notice: sc.Text}
+			case ChunkTypeFallback:
+				ch <- core.Chunk{Fallback: sc.Text}
+			case ChunkTypeToolStart:
+				ch <- core.Chunk{ToolStart: sc.ToolName}
+			case ChunkTypeToolInput:
+				ch <- core.Chunk{ToolInput: sc.Text}
 			case ChunkTypeDone:
 				ch <- core.Chunk{Done: true, Response: toInferResponse(sc.Response)}
 			case ChunkTypeError:
@@ -98,6 +110,14 @@ func (l *Client) SetThinkingEffort(effort string) {
 	l.thinkingEffort = effort
 }
 
+// SetTemperature sets the sampling temperature of later requests. nil uses
+// the provider default.
+func (l *Client) SetTemperature(temperature *float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.temperature = temperature
+}
+
 // ThinkingEffort returns the current native thinking/reasoning effort value.
 func (l *Client) ThinkingEffort() string {
 	l.mu.RLock()
@@ -113,7 +133,7 @@ func (l *Client) ThinkingEffort() string {
 func (l *Client) Stream(ctx context.Context, msgs []core.Message,
 	tools []ToolSchema, sysPrompt string,
 ) <-chan StreamChunk {
-	return l.provider.Stream(ctx, l.completionOpts(msgs, tools, sysPrompt))
+	return l.streamWithFailover(ctx, l.provider, l.completionOpts(msgs, tools, sysPrompt))
 }
 
 // Complete sends a one-shot completion (custom max tokens, no tools).
@@ -238,6 +258,7 @@ func (l *Client) completionOpts(msgs []core.Message, tools []ToolSchema, sysProm
 	l.mu.RLock()
 	model := l.model
 	maxTokens := l.maxTokens
+	temperature := l.temperature
 	thinking := l.thinkingEffort
 	p := l.provider
 	l.mu.RUnlock()
@@ -246,6 +267,7 @@ func (l *Client) completionOpts(msgs []core.Message, tools []ToolSchema, sysProm
 		Model:          model,
 		Messages:       msgs,
 		MaxTokens:      resolveMaxTokens(maxTokens, p, model),
+		Temperature:    temperature,
 		Tools:          tools,
 		SystemPrompt:   sysPrompt,
 		ThinkingEffort: thinking,
@@ -333,5 +355,6 @@ func toInferResponse(r *CompletionResponse) *core.InferResponse {
 		TokensOut:         r.Usage.OutputTokens,
 		CacheCreateTokens: r.Usage.CacheCreationInputTokens,
 		CacheReadTokens:   r.Usage.CacheReadInputTokens,
+		Citations:         r.Citations,
 	}
 }
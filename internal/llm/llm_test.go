@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/yanmxa/gencode/internal/core"
 )
@@ -362,3 +363,118 @@ func TestFakeLLMCustomNames(t *testing.T) {
 		t.Errorf("expected 'gpt-4', got '%s'", fake.ModelID())
 	}
 }
+
+// blockingProvider holds each stream open until release is closed.
+type blockingProvider struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingProvider) Stream(_ context.Context, _ CompletionOptions) <-chan StreamChunk {
+	ch := make(chan StreamChunk, 1)
+	b.started <- struct{}{}
+	go func() {
+		defer close(ch)
+		<-b.release
+		ch <- StreamChunk{Type: ChunkTypeDone, Response: &CompletionResponse{StopReason: "end_turn"}}
+	}()
+	return ch
+}
+
+func (b *blockingProvider) ListModels(_ context.Context) ([]ModelInfo, error) { return nil, nil }
+func (b *blockingProvider) Name() string                                      { return "blocking" }
+
+func TestStreamLimitedQueuesExcessRequests(t *testing.T) {
+	SetMaxConcurrentRequests(1)
+	t.Cleanup(func() { SetMaxConcurrentRequests(0) })
+
+	p := &blockingProvider{started: make(chan struct{}, 2), release: make(chan struct{})}
+	ctx := context.Background()
+
+	first := streamLimited(ctx, p, CompletionOptions{})
+	<-p.started
+	second := streamLimited(ctx, p, CompletionOptions{})
+
+	deadline := time.After(time.Second)
+	for QueuedRequests() != 1 {
+		select {
+		case <-deadline:
+			t.Fatalf("QueuedRequests() = %d, want 1", QueuedRequests())
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	select {
+	case <-p.started:
+		t.Fatal("second request started while the first held the only slot")
+	default:
+	}
+
+	close(p.release)
+	for range first {
+	}
+	for range second {
+	}
+	if got := QueuedRequests(); got != 0 {
+		t.Errorf("QueuedRequests() after completion = %d, want 0", got)
+	}
+}
+
+func TestStreamLimitedHonorsCancellation(t *testing.T) {
+	SetMaxConcurrentRequests(1)
+	t.Cleanup(func() { SetMaxConcurrentRequests(0) })
+
+	p := &blockingProvider{started: make(chan struct{}, 1), release: make(chan struct{})}
+	defer close(p.release)
+
+	_ = streamLimited(context.Background(), p, CompletionOptions{})
+	<-p.started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	queued := streamLimited(ctx, p, CompletionOptions{})
+	cancel()
+
+	chunk := <-queued
+	if chunk.Type != ChunkTypeError || !errors.Is(chunk.Error, context.Canceled) {
+		t.Fatalf("got %#v, want context.Canceled error chunk", chunk)
+	}
+}
+
+// floodingProvider sends chunks on an unbuffered stream until ctx is done and
+// closes finished once its goroutine exits.
+type floodingProvider struct {
+	finished chan struct{}
+}
+
+func (f *floodingProvider) Stream(ctx context.Context, _ CompletionOptions) <-chan StreamChunk {
+	ch := make(chan StreamChunk)
+	go func() {
+		defer close(f.finished)
+		defer close(ch)
+		for i := 0; i < 100; i++ {
+			ch <- StreamChunk{Type: ChunkTypeText, Text: "x"}
+		}
+	}()
+	return ch
+}
+
+func (f *floodingProvider) ListModels(_ context.Context) ([]ModelInfo, error) { return nil, nil }
+func (f *floodingProvider) Name() string                                      { return "flooding" }
+
+func TestStreamLimitedDrainsAfterCancellation(t *testing.T) {
+	SetMaxConcurrentRequests(1)
+	t.Cleanup(func() { SetMaxConcurrentRequests(0) })
+
+	p := &floodingProvider{finished: make(chan struct{})}
+	ctx, cancel := context.WithCancel(context.Background())
+	out := streamLimited(ctx, p, CompletionOptions{})
+	<-out
+	cancel()
+
+	select {
+	case <-p.finished:
+	case <-time.After(time.Second):
+		t.Fatal("provider stream still blocked after cancellation")
+	}
+	for range out {
+	}
+}
@@ -28,7 +28,13 @@ var _ interface{ LogRawMessages() any } = CompletionOptions{}
 func (o CompletionOptions) LogMaxTokens() int { return o.MaxTokens }
 
 // LogTemperature returns the temperature setting for log output.
-func (o CompletionOptions) LogTemperature() float64 { return o.Temperature }
+// A nil temperature (provider default) is logged as 0.
+func (o CompletionOptions) LogTemperature() float64 {
+	if o.Temperature == nil {
+		return 0
+	}
+	return *o.Temperature
+}
 
 // LogSystemPrompt returns the system prompt for log output.
 func (o CompletionOptions) LogSystemPrompt() string { return o.SystemPrompt }
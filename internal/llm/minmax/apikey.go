@@ -31,9 +31,11 @@ func NewAPIKeyClient(ctx context.Context) (llm.Provider, error) {
 	}
 	apiKey := secret.Resolve("MINIMAX_API_KEY")
 
+	// The llm package retries failed streams itself and shows progress.
 	client := anthropicsdk.NewClient(
 		anthropicoption.WithAPIKey(apiKey),
 		anthropicoption.WithBaseURL(baseURL),
+		anthropicoption.WithMaxRetries(0),
 	)
 	modelClient := openai.NewClient(
 		openaioption.WithAPIKey(apiKey),
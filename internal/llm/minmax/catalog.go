@@ -104,18 +104,27 @@ func CatalogModel(modelID string) (llm.ModelInfo, bool) {
 	return llm.ModelInfo{}, false
 }
 
+func init() {
+	for _, entry := range catalog {
+		llm.RegisterPricing(entry.info.ID, entry.pricing.llmPricing())
+	}
+}
+
+func (p pricing) llmPricing() llm.Pricing {
+	return llm.Pricing{
+		Input:      p.inputPerMTokens,
+		Output:     p.outputPerMTokens,
+		CacheRead:  p.cacheReadPerMTokens,
+		CacheWrite: p.cacheWritePerMTokens,
+		Currency:   llm.CurrencyCNY,
+	}
+}
+
 func EstimateCost(modelID string, usage llm.Usage) (llm.Money, bool) {
 	for _, entry := range catalog {
-		if entry.info.ID != modelID {
-			continue
+		if entry.info.ID == modelID {
+			return entry.pricing.llmPricing().Cost(usage), true
 		}
-
-		const perMillion = 1_000_000.0
-		cost := (float64(usage.InputTokens) / perMillion) * entry.pricing.inputPerMTokens
-		cost += (float64(usage.OutputTokens) / perMillion) * entry.pricing.outputPerMTokens
-		cost += (float64(usage.CacheReadInputTokens) / perMillion) * entry.pricing.cacheReadPerMTokens
-		cost += (float64(usage.CacheCreationInputTokens) / perMillion) * entry.pricing.cacheWritePerMTokens
-		return llm.Money{Amount: cost, Currency: llm.CurrencyCNY}, true
 	}
 	return llm.Money{}, false
 }
@@ -31,6 +31,11 @@ func (c *Client) Name() string {
 	return c.inner.Name()
 }
 
+// SupportsImages reports false: MiniMax's chat models take text only.
+func (c *Client) SupportsImages(model string) bool {
+	return false
+}
+
 func (c *Client) Stream(ctx context.Context, opts llm.CompletionOptions) <-chan llm.StreamChunk {
 	return c.inner.Stream(ctx, opts)
 }
@@ -0,0 +1,119 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/yanmxa/gencode/internal/fuzzy"
+)
+
+// ModelNotFoundError reports that a provider does not serve the requested
+// model, usually because the model was renamed or retired. Another model may
+// still work, so callers should ask the user to pick one rather than give up.
+type ModelNotFoundError struct {
+	Provider string
+	Model    string
+	Err      error
+}
+
+func (e *ModelNotFoundError) Error() string {
+	return fmt.Sprintf("model %s not found: %v", e.Model, e.Err)
+}
+
+func (e *ModelNotFoundError) Unwrap() error { return e.Err }
+
+// AsModelNotFound returns the ModelNotFoundError in err's chain, if any.
+func AsModelNotFound(err error) (*ModelNotFoundError, bool) {
+	var notFound *ModelNotFoundError
+	if errors.As(err, &notFound) {
+		return notFound, true
+	}
+	return nil, false
+}
+
+// isModelNotFound reports whether err is a provider's answer that the
+// requested model does not exist. Providers say so in different ways: a 404
+// mentioning the model (Anthropic, OpenAI, Gemini, Ollama) or a 400 whose
+// message says the model does not exist (DeepSeek, Moonshot).
+func isModelNotFound(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	msg := strings.ToLower(apiErr.Error())
+	if !strings.Contains(msg, "model") {
+		return false
+	}
+	if apiErr.StatusCode == http.StatusNotFound {
+		return true
+	}
+	if apiErr.StatusCode != http.StatusBadRequest {
+		return false
+	}
+	for _, phrase := range []string{"not found", "not_found", "not exist", "does not exist"} {
+		if strings.Contains(msg, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// streamModelErrors forwards src, replacing an error that says the model
+// does not exist with a ModelNotFoundError for provider and model.
+func streamModelErrors(ctx context.Context, provider, model string, src <-chan StreamChunk) <-chan StreamChunk {
+	out := make(chan StreamChunk, 8)
+	go func() {
+		defer close(out)
+		for chunk := range src {
+			if chunk.Type == ChunkTypeError && isModelNotFound(chunk.Error) {
+				chunk.Error = &ModelNotFoundError{Provider: provider, Model: model, Err: chunk.Error}
+			}
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				for range src {
+				}
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// ClosestModel returns the ID in candidates nearest to model by edit
+// distance, or "" when none is close enough to be a likely replacement.
+func ClosestModel(model string, candidates []string) string {
+	want := strings.ToLower(model)
+	best, bestDist := "", -1
+	for _, id := range candidates {
+		if id == "" || strings.EqualFold(id, model) {
+			continue
+		}
+		d := fuzzy.Levenshtein(want, strings.ToLower(id))
+		if bestDist < 0 || d < bestDist || (d == bestDist && id < best) {
+			best, bestDist = id, d
+		}
+	}
+	if bestDist < 0 || bestDist > max(3, len([]rune(model))/2) {
+		return ""
+	}
+	return best
+}
+
+// SuggestModel returns the cached model of provider closest to model, or ""
+// when the model list has not been fetched or nothing is close.
+func (s *Store) SuggestModel(provider Name, model string) string {
+	var ids []string
+	for key, models := range s.GetAllCachedModelsIncludeExpired() {
+		if !strings.HasPrefix(key, string(provider)+":") {
+			continue
+		}
+		for _, m := range models {
+			ids = append(ids, m.ID)
+		}
+	}
+	return ClosestModel(model, ids)
+}
@@ -0,0 +1,70 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestIsModelNotFound(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		msg    string
+		want   bool
+	}{
+		{name: "anthropic 404", status: http.StatusNotFound, msg: `not_found_error: model: claude-old`, want: true},
+		{name: "openai 404", status: http.StatusNotFound, msg: "The model `gpt-old` does not exist or you do not have access to it.", want: true},
+		{name: "deepseek 400", status: http.StatusBadRequest, msg: "Model Not Exist", want: true},
+		{name: "unrelated 404", status: http.StatusNotFound, msg: "page not found", want: false},
+		{name: "other 400", status: http.StatusBadRequest, msg: "max_tokens: must be positive for this model", want: false},
+		{name: "server error", status: http.StatusInternalServerError, msg: "model not found", want: false},
+	}
+	for _, tc := range cases {
+		err := NewAPIError(errors.New(tc.msg), tc.status, nil)
+		if got := isModelNotFound(err); got != tc.want {
+			t.Errorf("%s: isModelNotFound = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+	if isModelNotFound(errors.New("model not found")) {
+		t.Error("plain errors are not provider answers")
+	}
+}
+
+func TestStreamReportsModelNotFound(t *testing.T) {
+	recordRetryWaits(t, DefaultRetryConfig)
+	p := &scriptedProvider{name: "openai", scripts: [][]StreamChunk{{{
+		Type:  ChunkTypeError,
+		Error: NewAPIError(errors.New("The model `gpt-old` does not exist"), http.StatusNotFound, nil),
+	}}}}
+
+	_, _, err := collectStream(StreamCompletion(context.Background(), p, CompletionOptions{Model: "gpt-old"}))
+	notFound, ok := AsModelNotFound(err)
+	if !ok {
+		t.Fatalf("err = %v, want ModelNotFoundError", err)
+	}
+	if notFound.Provider != "openai" || notFound.Model != "gpt-old" {
+		t.Errorf("ModelNotFoundError = %+v", notFound)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("the provider's APIError should stay in the chain: %v", err)
+	}
+}
+
+func TestClosestModel(t *testing.T) {
+	models := []string{"claude-sonnet-4-20250514", "claude-opus-4-20250514", "claude-3-5-haiku-20241022"}
+	cases := map[string]string{
+		"claude-sonnet-4-20250101":  "claude-sonnet-4-20250514",
+		"claude-3-5-haiku-20240307": "claude-3-5-haiku-20241022",
+		"claude-sonnet-4-20250514":  "claude-opus-4-20250514",
+		"llama3.1":                  "",
+		"":                          "",
+	}
+	for model, want := range cases {
+		if got := ClosestModel(model, models); got != want {
+			t.Errorf("ClosestModel(%q) = %q, want %q", model, got, want)
+		}
+	}
+}
@@ -27,9 +27,11 @@ func NewAPIKeyClient(ctx context.Context) (llm.Provider, error) {
 		baseURL = "https://api.moonshot.cn/v1"
 	}
 
+	// The llm package retries failed streams itself and shows progress.
 	client := openai.NewClient(
 		option.WithAPIKey(secret.Resolve("MOONSHOT_API_KEY")),
 		option.WithBaseURL(baseURL),
+		option.WithMaxRetries(0),
 	)
 	return NewClient(client, "moonshot:api_key"), nil
 }
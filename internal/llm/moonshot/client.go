@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"slices"
+	"strings"
 
 	"github.com/openai/openai-go/v3"
 
@@ -43,6 +44,12 @@ func (c *Client) DefaultThinkingEffort(model string) string {
 	return "medium"
 }
 
+// SupportsImages reports whether model reads images: the vision previews
+// and the multimodal Kimi models do; the other moonshot-v1 models do not.
+func (c *Client) SupportsImages(model string) bool {
+	return strings.Contains(model, "vision") || strings.HasPrefix(model, "kimi-latest") || strings.HasPrefix(model, "kimi-k2.5")
+}
+
 // convertAssistant converts an assistant message for Moonshot.
 // Moonshot requires reasoning_content on all assistant messages when thinking
 // is enabled — we always include the field (empty string if no thinking content).
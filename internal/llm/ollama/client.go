@@ -0,0 +1,391 @@
+// Package ollama implements the Provider interface for a local Ollama server
+// using its native /api/chat endpoint, which streams newline-delimited JSON.
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/llm/openaicompat"
+	streamutil "github.com/yanmxa/gencode/internal/llm/stream"
+	"github.com/yanmxa/gencode/internal/log"
+)
+
+// Client implements the Provider interface for an Ollama server.
+type Client struct {
+	baseURL string
+	http    *http.Client
+	name    string
+}
+
+// NewClient creates a new Ollama client for the server at baseURL.
+func NewClient(baseURL string, httpClient *http.Client, name string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    httpClient,
+		name:    name,
+	}
+}
+
+// Name returns the provider name.
+func (c *Client) Name() string {
+	return c.name
+}
+
+// --- /api/chat wire types ---
+
+type chatRequest struct {
+	Model    string         `json:"model"`
+	Messages []chatMessage  `json:"messages"`
+	Tools    []chatTool     `json:"tools,omitempty"`
+	Stream   bool           `json:"stream"`
+	Options  map[string]any `json:"options,omitempty"`
+}
+
+type chatMessage struct {
+	Role      string         `json:"role"`
+	Content   string         `json:"content"`
+	Thinking  string         `json:"thinking,omitempty"`
+	Images    []string       `json:"images,omitempty"`
+	ToolCalls []chatToolCall `json:"tool_calls,omitempty"`
+	ToolName  string         `json:"tool_name,omitempty"`
+}
+
+type chatToolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+type chatTool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Parameters  any    `json:"parameters,omitempty"`
+	} `json:"function"`
+}
+
+// chatChunk is one line of the /api/chat stream.
+type chatChunk struct {
+	Message         chatMessage `json:"message"`
+	Done            bool        `json:"done"`
+	DoneReason      string      `json:"done_reason"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+	EvalCount       int         `json:"eval_count"`
+	Error           string      `json:"error"`
+}
+
+// Stream sends a completion request and returns a channel of streaming chunks.
+func (c *Client) Stream(ctx context.Context, opts llm.CompletionOptions) <-chan llm.StreamChunk {
+	ch := make(chan llm.StreamChunk)
+
+	go func() {
+		defer close(ch)
+
+		log.LogRequestCtx(ctx, c.name, opts.Model, opts)
+		state := streamutil.NewState(c.name)
+
+		body, err := json.Marshal(buildChatRequest(opts))
+		if err != nil {
+			state.Fail(ch, fmt.Errorf("failed to encode request: %w", err))
+			return
+		}
+		resp, err := c.post(ctx, "/api/chat", body)
+		if err != nil {
+			state.Fail(ch, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		toolCalls := make(map[int]*core.ToolCall)
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var chunk chatChunk
+			if err := json.Unmarshal(line, &chunk); err != nil {
+				state.Fail(ch, fmt.Errorf("invalid stream chunk from ollama: %w", err))
+				return
+			}
+			if chunk.Error != "" {
+				state.Fail(ch, fmt.Errorf("ollama: %s", chunk.Error))
+				return
+			}
+			state.Count()
+
+			state.EmitThinking(ch, chunk.Message.Thinking)
+			state.EmitText(ch, chunk.Message.Content)
+			// Ollama sends each tool call whole and without an ID, so give it a
+			// random one: IDs must stay unique across turns of a conversation.
+			for _, tc := range chunk.Message.ToolCalls {
+				idx := len(toolCalls)
+				call := &core.ToolCall{
+					ID:    newToolCallID(),
+					Name:  tc.Function.Name,
+					Input: toolArguments(tc.Function.Arguments),
+				}
+				toolCalls[idx] = call
+				state.EmitToolStart(ch, call.ID, call.Name)
+				state.EmitToolInput(ch, call.ID, call.Input)
+			}
+
+			if chunk.Done {
+				state.Response.StopReason = openaicompat.MapFinishReason(chunk.DoneReason)
+				state.UpdateUsage(chunk.PromptEvalCount, chunk.EvalCount)
+				break
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			state.Fail(ch, err)
+			return
+		}
+
+		state.AddToolCallsSorted(toolCalls)
+		if len(toolCalls) > 0 {
+			state.Response.StopReason = "tool_use"
+		}
+		state.Finish(ctx, ch)
+	}()
+
+	return ch
+}
+
+func buildChatRequest(opts llm.CompletionOptions) chatRequest {
+	req := chatRequest{
+		Model:    opts.Model,
+		Messages: convertMessages(opts.Messages, opts.SystemPrompt),
+		Stream:   true,
+	}
+	for _, t := range opts.Tools {
+		var tool chatTool
+		tool.Type = "function"
+		tool.Function.Name = t.Name
+		tool.Function.Description = t.Description
+		tool.Function.Parameters = t.Parameters
+		req.Tools = append(req.Tools, tool)
+	}
+	options := map[string]any{}
+	if opts.MaxTokens > 0 {
+		options["num_predict"] = opts.MaxTokens
+	}
+	if opts.Temperature != nil {
+		options["temperature"] = *opts.Temperature
+	}
+	if len(options) > 0 {
+		req.Options = options
+	}
+	return req
+}
+
+// convertMessages converts the internal message slice to /api/chat messages.
+// Tool results are sent as "tool" messages named after the call they answer.
+func convertMessages(msgs []core.Message, systemPrompt string) []chatMessage {
+	msgs = openaicompat.SanitizeToolMessages(msgs)
+	msgs = openaicompat.DropEmptyMessages(msgs)
+	out := make([]chatMessage, 0, len(msgs)+1)
+
+	if systemPrompt != "" {
+		out = append(out, chatMessage{Role: "system", Content: systemPrompt})
+	}
+
+	toolNames := make(map[string]string)
+	for _, msg := range msgs {
+		if msg.ToolResult != nil {
+			name := msg.ToolResult.ToolName
+			if name == "" {
+				name = toolNames[msg.ToolResult.ToolCallID]
+			}
+			out = append(out, chatMessage{Role: "tool", Content: msg.ToolResult.Content, ToolName: name})
+			continue
+		}
+		switch msg.Role {
+		case core.RoleUser:
+			m := chatMessage{Role: "user", Content: msg.Content}
+			for _, img := range msg.Images {
+				m.Images = append(m.Images, img.Data)
+			}
+			out = append(out, m)
+		case core.RoleAssistant:
+			m := chatMessage{Role: "assistant", Content: msg.Content, Thinking: msg.Thinking}
+			for _, tc := range msg.ToolCalls {
+				toolNames[tc.ID] = tc.Name
+				var call chatToolCall
+				call.Function.Name = tc.Name
+				call.Function.Arguments = json.RawMessage(toolArguments(json.RawMessage(tc.Input)))
+				m.ToolCalls = append(m.ToolCalls, call)
+			}
+			out = append(out, m)
+		default:
+			out = append(out, chatMessage{Role: "system", Content: msg.Content})
+		}
+	}
+	return out
+}
+
+// toolArguments returns raw tool arguments as a JSON object, or "{}" when
+// they are missing or not valid JSON.
+func toolArguments(raw json.RawMessage) string {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" || !json.Valid(raw) {
+		return "{}"
+	}
+	return string(raw)
+}
+
+// ListModels returns the models pulled into the Ollama server, with their
+// context window when /api/show reports one.
+func (c *Client) ListModels(ctx context.Context) ([]llm.ModelInfo, error) {
+	var tags struct {
+		Models []struct {
+			Name    string `json:"name"`
+			Model   string `json:"model"`
+			Details struct {
+				ParameterSize     string `json:"parameter_size"`
+				QuantizationLevel string `json:"quantization_level"`
+			} `json:"details"`
+		} `json:"models"`
+	}
+	if err := c.getJSON(ctx, "/api/tags", &tags); err != nil {
+		return nil, err
+	}
+
+	models := make([]llm.ModelInfo, 0, len(tags.Models))
+	for _, m := range tags.Models {
+		id := cmp.Or(m.Model, m.Name)
+		display := id
+		if size := m.Details.ParameterSize; size != "" {
+			display = fmt.Sprintf("%s (%s)", id, size)
+		}
+		info := llm.ModelInfo{ID: id, Name: m.Name, DisplayName: display}
+		if limit, err := c.contextLength(ctx, id); err == nil {
+			info.InputTokenLimit = limit
+		}
+		models = append(models, info)
+	}
+
+	if len(models) == 0 {
+		return nil, fmt.Errorf("ollama has no models; pull one with `ollama pull %s`", defaultModel)
+	}
+
+	slices.SortFunc(models, func(a, b llm.ModelInfo) int { return cmp.Compare(a.ID, b.ID) })
+	return models, nil
+}
+
+// defaultModel is suggested when the server has no models yet.
+const defaultModel = "llama3.1"
+
+// FetchModelLimits reads the model's context window from /api/show.
+// Ollama does not report an output limit.
+func (c *Client) FetchModelLimits(ctx context.Context, modelID string) (inputLimit, outputLimit int, err error) {
+	limit, err := c.contextLength(ctx, modelID)
+	return limit, 0, err
+}
+
+// contextLength reads "<architecture>.context_length" from the model_info
+// that /api/show returns.
+func (c *Client) contextLength(ctx context.Context, modelID string) (int, error) {
+	body, err := json.Marshal(map[string]string{"model": modelID})
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.post(ctx, "/api/show", body)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var show struct {
+		ModelInfo map[string]any `json:"model_info"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&show); err != nil {
+		return 0, err
+	}
+	if arch, ok := show.ModelInfo["general.architecture"].(string); ok {
+		if n, ok := show.ModelInfo[arch+".context_length"].(float64); ok {
+			return int(n), nil
+		}
+	}
+	return 0, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (c *Client) post(ctx context.Context, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req)
+}
+
+// do sends req and turns connection failures and error statuses into
+// actionable errors. The caller closes the body of a successful response.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("cannot reach Ollama at %s (is `ollama serve` running? set OLLAMA_HOST for another address): %w", c.baseURL, err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var apiErr struct {
+		Error string `json:"error"`
+	}
+	msg := strings.TrimSpace(string(data))
+	if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+		msg = apiErr.Error
+	}
+	if msg == "" {
+		msg = resp.Status
+	}
+	return nil, llm.NewAPIError(fmt.Errorf("ollama: %s", msg), resp.StatusCode, resp.Header)
+}
+
+// Ensure Client implements Provider and ModelLimitsFetcher
+var _ llm.Provider = (*Client)(nil)
+var _ llm.ModelLimitsFetcher = (*Client)(nil)
+
+// newToolCallID returns a random tool call ID such as "call_1a2b3c4d5e6f".
+func newToolCallID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		panic("crypto/rand failed: " + err.Error())
+	}
+	return "call_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,188 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/llm"
+)
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return NewClient(srv.URL, srv.Client(), "ollama:test")
+}
+
+func TestStreamParsesNDJSON(t *testing.T) {
+	var got chatRequest
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("path = %s, want /api/chat", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Fatalf("bad request body: %v", err)
+		}
+		io.WriteString(w, `{"message":{"role":"assistant","content":"Hel"},"done":false}`+"\n")
+		io.WriteString(w, `{"message":{"role":"assistant","content":"lo"},"done":false}`+"\n")
+		io.WriteString(w, `{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"Read","arguments":{"file_path":"a.go"}}}]},"done":false}`+"\n")
+		io.WriteString(w, `{"message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":12,"eval_count":5}`+"\n")
+	})
+
+	temperature := 0.0
+	ch := c.Stream(context.Background(), llm.CompletionOptions{
+		Model:        "llama3.1",
+		SystemPrompt: "sys",
+		MaxTokens:    100,
+		Temperature:  &temperature,
+		Messages: []core.Message{
+			{Role: core.RoleUser, Content: "hi"},
+			{Role: core.RoleAssistant, ToolCalls: []core.ToolCall{{ID: "tc1", Name: "Glob", Input: `{"pattern":"*"}`}}},
+			{Role: core.RoleUser, ToolResult: &core.ToolResult{ToolCallID: "tc1", Content: "a.go"}},
+		},
+		Tools: []llm.ToolSchema{{Name: "Read", Description: "read", Parameters: map[string]any{"type": "object"}}},
+	})
+
+	var text strings.Builder
+	var resp *llm.CompletionResponse
+	for chunk := range ch {
+		switch chunk.Type {
+		case llm.ChunkTypeText:
+			text.WriteString(chunk.Text)
+		case llm.ChunkTypeDone:
+			resp = chunk.Response
+		case llm.ChunkTypeError:
+			t.Fatalf("stream error: %v", chunk.Error)
+		}
+	}
+
+	if text.String() != "Hello" {
+		t.Errorf("text = %q, want Hello", text.String())
+	}
+	if resp == nil {
+		t.Fatal("no done chunk")
+	}
+	if resp.StopReason != "tool_use" {
+		t.Errorf("stop reason = %q, want tool_use", resp.StopReason)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "Read" || resp.ToolCalls[0].Input != `{"file_path":"a.go"}` || resp.ToolCalls[0].ID == "" {
+		t.Errorf("tool calls = %+v", resp.ToolCalls)
+	}
+	if resp.Usage.InputTokens != 12 || resp.Usage.OutputTokens != 5 {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+
+	if temp, ok := got.Options["temperature"]; !ok || temp != float64(0) {
+		t.Errorf("temperature option = %v (set %v), want an explicit 0", temp, ok)
+	}
+	if !got.Stream || got.Model != "llama3.1" || got.Options["num_predict"] != float64(100) {
+		t.Errorf("request = %+v", got)
+	}
+	if len(got.Messages) != 4 || got.Messages[0].Role != "system" {
+		t.Fatalf("messages = %+v", got.Messages)
+	}
+	if tool := got.Messages[3]; tool.Role != "tool" || tool.ToolName != "Glob" || tool.Content != "a.go" {
+		t.Errorf("tool result message = %+v", tool)
+	}
+	if len(got.Tools) != 1 || got.Tools[0].Function.Name != "Read" {
+		t.Errorf("tools = %+v", got.Tools)
+	}
+}
+
+func TestToolCallIDsAreUniqueAcrossTurns(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"Read","arguments":{}}}]},"done":true,"done_reason":"stop"}`+"\n")
+	})
+
+	seen := map[string]bool{}
+	for turn := 0; turn < 2; turn++ {
+		for chunk := range c.Stream(context.Background(), llm.CompletionOptions{Model: "llama3.1"}) {
+			if chunk.Type == llm.ChunkTypeError {
+				t.Fatalf("stream error: %v", chunk.Error)
+			}
+			if chunk.Type == llm.ChunkTypeDone {
+				for _, tc := range chunk.Response.ToolCalls {
+					if seen[tc.ID] {
+						t.Fatalf("tool call ID %q reused in turn %d", tc.ID, turn+1)
+					}
+					seen[tc.ID] = true
+				}
+			}
+		}
+	}
+	if len(seen) != 2 {
+		t.Errorf("tool call IDs = %v, want one per turn", seen)
+	}
+}
+
+func TestStreamReportsServerError(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"error":"model \"nope\" not found, try pulling it first"}`)
+	})
+
+	var err error
+	for chunk := range c.Stream(context.Background(), llm.CompletionOptions{Model: "nope"}) {
+		if chunk.Type == llm.ChunkTypeError {
+			err = chunk.Error
+		}
+	}
+	if err == nil || !strings.Contains(err.Error(), "try pulling it first") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestListModelsIncludesContextLength(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			io.WriteString(w, `{"models":[{"name":"qwen3:8b","model":"qwen3:8b","details":{"parameter_size":"8.2B"}},{"name":"llama3.1:latest","model":"llama3.1:latest"}]}`)
+		case "/api/show":
+			var req struct{ Model string }
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.Model == "llama3.1:latest" {
+				io.WriteString(w, `{"model_info":{"general.architecture":"llama","llama.context_length":131072}}`)
+				return
+			}
+			io.WriteString(w, `{"model_info":{}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	models, err := c.ListModels(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 2 || models[0].ID != "llama3.1:latest" || models[1].ID != "qwen3:8b" {
+		t.Fatalf("models = %+v", models)
+	}
+	if models[0].InputTokenLimit != 131072 {
+		t.Errorf("llama3.1 context = %d, want 131072", models[0].InputTokenLimit)
+	}
+	if models[1].InputTokenLimit != 0 || models[1].DisplayName != "qwen3:8b (8.2B)" {
+		t.Errorf("qwen3 = %+v", models[1])
+	}
+}
+
+func TestHostURL(t *testing.T) {
+	tests := map[string]string{
+		"":                        "http://localhost:11434",
+		"0.0.0.0":                 "http://0.0.0.0:11434",
+		"gpu-box:8080":            "http://gpu-box:8080",
+		"https://ollama.example/": "https://ollama.example",
+		"http://127.0.0.1:11434":  "http://127.0.0.1:11434",
+	}
+	for in, want := range tests {
+		if got := hostURL(in); got != want {
+			t.Errorf("hostURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
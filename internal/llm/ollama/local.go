@@ -0,0 +1,47 @@
+package ollama
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/yanmxa/gencode/internal/llm"
+)
+
+const defaultHost = "http://localhost:11434"
+
+// LocalMeta is the metadata for a local Ollama server. It needs no
+// credentials; OLLAMA_HOST optionally points at a non-default address.
+var LocalMeta = llm.Meta{
+	Provider:    llm.Ollama,
+	AuthMethod:  llm.AuthLocal,
+	DisplayName: "Local",
+}
+
+// NewLocalClient creates a new Ollama client for the server at OLLAMA_HOST,
+// or localhost:11434 when it is unset.
+func NewLocalClient(ctx context.Context) (llm.Provider, error) {
+	return NewClient(hostURL(os.Getenv("OLLAMA_HOST")), http.DefaultClient, "ollama:local"), nil
+}
+
+// hostURL turns an OLLAMA_HOST value into a base URL. Like the ollama CLI it
+// accepts a bare host or host:port, which default to http and port 11434.
+func hostURL(host string) string {
+	host = strings.TrimRight(strings.TrimSpace(host), "/")
+	if host == "" {
+		return defaultHost
+	}
+	if strings.Contains(host, "://") {
+		return host
+	}
+	if !strings.Contains(host, ":") {
+		host += ":11434"
+	}
+	return "http://" + host
+}
+
+// init registers the local provider
+func init() {
+	llm.Register(LocalMeta, NewLocalClient)
+}
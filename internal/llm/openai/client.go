@@ -122,8 +122,8 @@ func (c *Client) streamResponses(ctx context.Context, opts llm.CompletionOptions
 			params.MaxOutputTokens = openai.Opt(int64(opts.MaxTokens))
 		}
 
-		if opts.Temperature > 0 {
-			params.Temperature = openai.Opt(opts.Temperature)
+		if opts.Temperature != nil {
+			params.Temperature = openai.Opt(*opts.Temperature)
 		}
 
 		if opts.ThinkingEffort != "" {
@@ -182,6 +182,14 @@ func (c *Client) streamResponses(ctx context.Context, opts llm.CompletionOptions
 				delta := event.AsResponseReasoningTextDelta()
 				state.EmitThinking(ch, delta.Delta)
 
+			case "response.output_text.annotation.added":
+				added := event.AsResponseOutputTextAnnotationAdded()
+				if a, ok := added.Annotation.(map[string]any); ok && a["type"] == "url_citation" {
+					title, _ := a["title"].(string)
+					url, _ := a["url"].(string)
+					state.AddCitation(title, url)
+				}
+
 			case "response.output_item.added":
 				itemEvent := event.AsResponseOutputItemAdded()
 				if itemEvent.Item.Type == "function_call" {
@@ -206,7 +214,7 @@ func (c *Client) streamResponses(ctx context.Context, opts llm.CompletionOptions
 				resp := completed.Response
 
 				// Map usage
-				state.UpdateUsage(int(resp.Usage.InputTokens), int(resp.Usage.OutputTokens))
+				state.UpdatePromptUsage(int(resp.Usage.InputTokens), int(resp.Usage.InputTokensDetails.CachedTokens), int(resp.Usage.OutputTokens))
 
 				// Determine stop reason
 				switch resp.Status {
@@ -332,5 +340,35 @@ func messageHasResponseContent(msg core.Message) bool {
 	return strings.TrimSpace(msg.Content) != "" || len(msg.Images) > 0
 }
 
-// Ensure Client implements Provider
-var _ llm.Provider = (*Client)(nil)
+// embeddingModel is the model used by GetEmbeddings.
+const embeddingModel = openai.EmbeddingModelTextEmbedding3Small
+
+// GetEmbeddings generates one embedding vector per input text.
+func (c *Client) GetEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+	resp, err := c.client.Embeddings.New(ctx, openai.EmbeddingNewParams{
+		Input:          openai.EmbeddingNewParamsInputUnion{OfArrayOfStrings: texts},
+		Model:          embeddingModel,
+		EncodingFormat: openai.EmbeddingNewParamsEncodingFormatFloat,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("openai embeddings: %w", err)
+	}
+	vectors := make([][]float32, len(texts))
+	for _, d := range resp.Data {
+		if d.Index < 0 || int(d.Index) >= len(vectors) {
+			continue
+		}
+		v := make([]float32, len(d.Embedding))
+		for i, f := range d.Embedding {
+			v[i] = float32(f)
+		}
+		vectors[d.Index] = v
+	}
+	return vectors, nil
+}
+
+// Ensure Client implements Provider and EmbeddingProvider
+var (
+	_ llm.Provider          = (*Client)(nil)
+	_ llm.EmbeddingProvider = (*Client)(nil)
+)
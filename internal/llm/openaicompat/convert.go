@@ -1,5 +1,5 @@
 // Package openaicompat provides shared helpers for OpenAI-compatible providers
-// (OpenAI, Moonshot, Alibaba/Qwen). All three use the openai-go SDK with the
+// (OpenAI, Moonshot, Alibaba/Qwen, DeepSeek). All of them use the openai-go SDK with the
 // same message format; only model-specific parameters differ.
 package openaicompat
 
@@ -285,8 +285,8 @@ func MapFinishReason(reason string) string {
 }
 
 // ExtractReasoningContent parses the reasoning_content field from a raw JSON
-// stream delta. This extension field is used by Moonshot (Kimi) and Alibaba
-// (Qwen) thinking models and is not part of the standard OpenAI SDK struct.
+// stream delta. This extension field is used by Moonshot (Kimi), Alibaba
+// (Qwen) and DeepSeek thinking models and is not part of the standard OpenAI SDK struct.
 // Returns empty string if the field is absent or the JSON is malformed.
 func ExtractReasoningContent(rawJSON string) string {
 	if rawJSON == "" {
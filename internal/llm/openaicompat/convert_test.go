@@ -155,3 +155,22 @@ func TestConvertMessagesDoesNotSendEmptyTextOnlyUserMessages(t *testing.T) {
 		}
 	}
 }
+
+func TestConvertMessagesDoesNotSendThinkingAsContent(t *testing.T) {
+	msgs := []core.Message{
+		{Role: core.RoleUser, Content: "hi"},
+		{Role: core.RoleAssistant, Thinking: "private reasoning", Content: "hello"},
+	}
+
+	raw, err := json.Marshal(ConvertMessages(msgs, "", DefaultAssistantMessage))
+	if err != nil {
+		t.Fatalf("marshal converted messages: %v", err)
+	}
+	got := string(raw)
+	if strings.Contains(got, "private reasoning") {
+		t.Fatalf("thinking leaked into the request:\n%s", got)
+	}
+	if !strings.Contains(got, `"content":"hello"`) {
+		t.Fatalf("answer missing from the request:\n%s", got)
+	}
+}
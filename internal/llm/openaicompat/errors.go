@@ -7,6 +7,8 @@ import (
 	"strings"
 
 	"github.com/openai/openai-go/v3"
+
+	"github.com/yanmxa/gencode/internal/llm"
 )
 
 // NormalizeAPIError converts OpenAI-compatible auth failures into actionable
@@ -18,7 +20,11 @@ func NormalizeAPIError(providerName string, err error) error {
 	}
 
 	if apierr.StatusCode != http.StatusUnauthorized && apierr.StatusCode != http.StatusForbidden {
-		return err
+		var header http.Header
+		if apierr.Response != nil {
+			header = apierr.Response.Header
+		}
+		return llm.NewAPIError(err, apierr.StatusCode, header)
 	}
 
 	providerLabel, envVar := providerAuthHelp(providerName)
@@ -55,6 +61,8 @@ func providerAuthHelp(providerName string) (label string, envVar string) {
 		return "Alibaba", "DASHSCOPE_API_KEY"
 	case "minmax":
 		return "MiniMax", "MINIMAX_API_KEY"
+	case "deepseek":
+		return "DeepSeek", "DEEPSEEK_API_KEY"
 	default:
 		if base == "" {
 			return "Provider", ""
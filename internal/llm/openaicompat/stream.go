@@ -42,8 +42,8 @@ func StreamChatCompletions(ctx context.Context, cfg ChatStreamConfig) <-chan llm
 		if opts.MaxTokens > 0 {
 			params.MaxCompletionTokens = openai.Int(int64(opts.MaxTokens))
 		}
-		if opts.Temperature > 0 {
-			params.Temperature = openai.Float(opts.Temperature)
+		if opts.Temperature != nil {
+			params.Temperature = openai.Float(*opts.Temperature)
 		}
 		if len(opts.Tools) > 0 {
 			params.Tools = ConvertTools(opts.Tools)
@@ -89,7 +89,7 @@ func StreamChatCompletions(ctx context.Context, cfg ChatStreamConfig) <-chan llm
 				}
 			}
 
-			state.UpdateUsage(int(chunk.Usage.PromptTokens), int(chunk.Usage.CompletionTokens))
+			state.UpdatePromptUsage(int(chunk.Usage.PromptTokens), int(chunk.Usage.PromptTokensDetails.CachedTokens), int(chunk.Usage.CompletionTokens))
 		}
 
 		if err := stream.Err(); err != nil {
@@ -27,7 +27,7 @@ func (t *streamCaptureTransport) RoundTrip(req *http.Request) (*http.Response, e
 
 	streamBody := strings.Join([]string{
 		`data: {"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"ok"},"finish_reason":"stop"}]}`,
-		`data: {"id":"1","object":"chat.completion.chunk","choices":[],"usage":{"prompt_tokens":11,"completion_tokens":7,"total_tokens":18}}`,
+		`data: {"id":"1","object":"chat.completion.chunk","choices":[],"usage":{"prompt_tokens":11,"completion_tokens":7,"total_tokens":18,"prompt_tokens_details":{"cached_tokens":4}}}`,
 		`data: [DONE]`,
 		``,
 	}, "\n\n")
@@ -95,8 +95,9 @@ func TestStreamChatCompletionsRequestsAndReadsUsage(t *testing.T) {
 	if done == nil {
 		t.Fatal("missing done chunk")
 	}
-	if done.Usage.InputTokens != 11 || done.Usage.OutputTokens != 7 {
-		t.Fatalf("usage = in:%d out:%d, want in:11 out:7", done.Usage.InputTokens, done.Usage.OutputTokens)
+	// Cached prompt tokens are split out of prompt_tokens, as Anthropic reports them.
+	if u := done.Usage; u.InputTokens != 7 || u.CacheReadInputTokens != 4 || u.OutputTokens != 7 {
+		t.Fatalf("usage = in:%d cached:%d out:%d, want in:7 cached:4 out:7", u.InputTokens, u.CacheReadInputTokens, u.OutputTokens)
 	}
 }
 
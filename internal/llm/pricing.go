@@ -0,0 +1,218 @@
+package llm
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Pricing is what a model charges per million tokens.
+type Pricing struct {
+	Input      float64  `json:"input"`
+	Output     float64  `json:"output"`
+	CacheRead  float64  `json:"cacheRead,omitempty"`
+	CacheWrite float64  `json:"cacheWrite,omitempty"`
+	Currency   Currency `json:"currency,omitempty"` // empty = USD
+}
+
+// Cost returns the price of usage at these rates.
+func (p Pricing) Cost(u Usage) Money {
+	const perMillion = 1_000_000.0
+	amount := float64(u.InputTokens) / perMillion * p.Input
+	amount += float64(u.OutputTokens) / perMillion * p.Output
+	amount += float64(u.CacheReadInputTokens) / perMillion * p.CacheRead
+	amount += float64(u.CacheCreationInputTokens) / perMillion * p.CacheWrite
+	return Money{Amount: amount, Currency: cmp.Or(p.Currency, CurrencyUSD)}
+}
+
+// CacheSavings returns how much cheaper usage was thanks to prompt caching:
+// cached reads at the input rate minus their cache rate, less the premium
+// paid for cache writes. It is zero when the price has no cache read rate,
+// since the cost of cached tokens is then unknown.
+func (p Pricing) CacheSavings(u Usage) Money {
+	currency := cmp.Or(p.Currency, CurrencyUSD)
+	if p.CacheRead <= 0 {
+		return Money{Currency: currency}
+	}
+	const perMillion = 1_000_000.0
+	amount := float64(u.CacheReadInputTokens) / perMillion * (p.Input - p.CacheRead)
+	if p.CacheWrite > 0 {
+		amount -= float64(u.CacheCreationInputTokens) / perMillion * (p.CacheWrite - p.Input)
+	}
+	return Money{Amount: amount, Currency: currency}
+}
+
+// builtinPricing holds list prices in USD, keyed by model ID without a
+// release-date suffix. Provider packages add their own via RegisterPricing.
+var builtinPricing = map[string]Pricing{
+	"claude-opus-4":     {Input: 15, Output: 75, CacheRead: 1.5, CacheWrite: 18.75},
+	"claude-opus-4-1":   {Input: 15, Output: 75, CacheRead: 1.5, CacheWrite: 18.75},
+	"claude-opus-4-5":   {Input: 5, Output: 25, CacheRead: 0.5, CacheWrite: 6.25},
+	"claude-sonnet-4":   {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+	"claude-sonnet-4-5": {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+	"claude-haiku-4-5":  {Input: 1, Output: 5, CacheRead: 0.1, CacheWrite: 1.25},
+	"claude-3-7-sonnet": {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+	"claude-3-5-haiku":  {Input: 0.8, Output: 4, CacheRead: 0.08, CacheWrite: 1},
+
+	"gpt-5":        {Input: 1.25, Output: 10, CacheRead: 0.125},
+	"gpt-5-mini":   {Input: 0.25, Output: 2, CacheRead: 0.025},
+	"gpt-5-nano":   {Input: 0.05, Output: 0.4, CacheRead: 0.005},
+	"gpt-4.1":      {Input: 2, Output: 8, CacheRead: 0.5},
+	"gpt-4.1-mini": {Input: 0.4, Output: 1.6, CacheRead: 0.1},
+	"gpt-4.1-nano": {Input: 0.1, Output: 0.4, CacheRead: 0.025},
+	"gpt-4o":       {Input: 2.5, Output: 10, CacheRead: 1.25},
+	"gpt-4o-mini":  {Input: 0.15, Output: 0.6, CacheRead: 0.075},
+	"o3":           {Input: 2, Output: 8, CacheRead: 0.5},
+	"o4-mini":      {Input: 1.1, Output: 4.4, CacheRead: 0.275},
+
+	"gemini-2.5-pro":        {Input: 1.25, Output: 10, CacheRead: 0.31},
+	"gemini-2.5-flash":      {Input: 0.3, Output: 2.5, CacheRead: 0.075},
+	"gemini-2.5-flash-lite": {Input: 0.1, Output: 0.4, CacheRead: 0.025},
+	"gemini-2.0-flash":      {Input: 0.1, Output: 0.4, CacheRead: 0.025},
+}
+
+var pricingMu sync.RWMutex
+
+// RegisterPricing adds or replaces the built-in price of a model. Provider
+// packages call it from init for the models in their catalogs.
+func RegisterPricing(modelID string, p Pricing) {
+	pricingMu.Lock()
+	defer pricingMu.Unlock()
+	builtinPricing[modelID] = p
+}
+
+// versionSuffix matches the release-date or alias suffix providers append to
+// a base model ID: -20250514, @20250514 (Vertex), -2024-08-06, -latest.
+var versionSuffix = regexp.MustCompile(`^[-@](\d{8}|\d{4}-\d{2}-\d{2}|latest)$`)
+
+// LookupPricing returns the price of a model: the override in store when
+// there is one, otherwise the built-in table. Dated IDs such as
+// claude-sonnet-4-20250514 fall back to their base ID.
+func LookupPricing(store *Store, modelID string) (Pricing, bool) {
+	if store != nil {
+		if p, ok := store.GetPricing(modelID); ok {
+			return p, true
+		}
+	}
+
+	pricingMu.RLock()
+	defer pricingMu.RUnlock()
+	if p, ok := builtinPricing[modelID]; ok {
+		return p, true
+	}
+	best := ""
+	for id := range builtinPricing {
+		if len(id) > len(best) && strings.HasPrefix(modelID, id) && versionSuffix.MatchString(modelID[len(id):]) {
+			best = id
+		}
+	}
+	if best == "" {
+		return Pricing{}, false
+	}
+	return builtinPricing[best], true
+}
+
+// ModelSpend is one model's usage over a session.
+type ModelSpend struct {
+	Provider Name
+	ModelID  string
+	Requests int
+	Usage    Usage
+	Cost     Money // zero when the model has no known pricing
+	Priced   bool
+	// CacheSavings is what prompt caching saved against the plain input
+	// rate; negative while cache writes outweigh reads.
+	CacheSavings Money
+}
+
+// SpendLedger totals usage and cost per model across a session. The zero
+// value is ready to use.
+type SpendLedger struct {
+	models []ModelSpend
+}
+
+// Record adds one request's usage for a model. pricing is nil when the
+// model's price is unknown; the tokens are still counted.
+func (l *SpendLedger) Record(provider Name, modelID string, usage Usage, pricing *Pricing) {
+	idx := slices.IndexFunc(l.models, func(s ModelSpend) bool {
+		return s.Provider == provider && s.ModelID == modelID
+	})
+	if idx < 0 {
+		l.models = append(l.models, ModelSpend{Provider: provider, ModelID: modelID})
+		idx = len(l.models) - 1
+	}
+	s := &l.models[idx]
+	s.Requests++
+	s.Usage.InputTokens += usage.InputTokens
+	s.Usage.OutputTokens += usage.OutputTokens
+	s.Usage.CacheReadInputTokens += usage.CacheReadInputTokens
+	s.Usage.CacheCreationInputTokens += usage.CacheCreationInputTokens
+	if pricing != nil {
+		cost := pricing.Cost(usage)
+		if !s.Cost.IsZero() && s.Cost.Currency != cost.Currency {
+			// The price was overridden in another currency mid-session;
+			// keep the newer one rather than mixing the two.
+			s.Cost = Money{}
+		}
+		s.Cost = s.Cost.Add(cost)
+		s.Priced = true
+		if saved := pricing.CacheSavings(usage); !saved.IsZero() {
+			if !s.CacheSavings.IsZero() && s.CacheSavings.Currency != saved.Currency {
+				s.CacheSavings = Money{}
+			}
+			s.CacheSavings = s.CacheSavings.Add(saved)
+		}
+	}
+}
+
+// Models returns the per-model breakdown in the order models were first used.
+func (l *SpendLedger) Models() []ModelSpend {
+	return slices.Clone(l.models)
+}
+
+// TotalUsage returns the tokens used across all models.
+func (l *SpendLedger) TotalUsage() Usage {
+	var total Usage
+	for _, s := range l.models {
+		total.InputTokens += s.Usage.InputTokens
+		total.OutputTokens += s.Usage.OutputTokens
+		total.CacheReadInputTokens += s.Usage.CacheReadInputTokens
+		total.CacheCreationInputTokens += s.Usage.CacheCreationInputTokens
+	}
+	return total
+}
+
+// Totals returns the session cost, one entry per currency.
+func (l *SpendLedger) Totals() []Money {
+	return l.sumByCurrency(func(s ModelSpend) Money { return s.Cost })
+}
+
+// CacheSavingsTotals returns what prompt caching saved this session, one
+// entry per currency.
+func (l *SpendLedger) CacheSavingsTotals() []Money {
+	return l.sumByCurrency(func(s ModelSpend) Money { return s.CacheSavings })
+}
+
+func (l *SpendLedger) sumByCurrency(amount func(ModelSpend) Money) []Money {
+	var totals []Money
+	for _, s := range l.models {
+		m := amount(s)
+		if m.IsZero() {
+			continue
+		}
+		idx := slices.IndexFunc(totals, func(t Money) bool { return t.Currency == m.Currency })
+		if idx < 0 {
+			totals = append(totals, m)
+		} else {
+			totals[idx] = totals[idx].Add(m)
+		}
+	}
+	return totals
+}
+
+// Reset clears the ledger.
+func (l *SpendLedger) Reset() {
+	l.models = nil
+}
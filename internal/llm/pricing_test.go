@@ -0,0 +1,111 @@
+package llm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPricingCost(t *testing.T) {
+	p := Pricing{Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75}
+	cost := p.Cost(Usage{InputTokens: 1_000_000, OutputTokens: 100_000, CacheReadInputTokens: 1_000_000, CacheCreationInputTokens: 200_000})
+	if cost.Currency != CurrencyUSD || math.Abs(cost.Amount-5.55) > 1e-9 {
+		t.Fatalf("cost = %+v, want $5.55", cost)
+	}
+}
+
+func TestPricingCacheSavings(t *testing.T) {
+	p := Pricing{Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75}
+	// 1M cached reads save $2.70; 1M cache writes cost $0.75 over plain input.
+	got := p.CacheSavings(Usage{InputTokens: 500, CacheReadInputTokens: 1_000_000, CacheCreationInputTokens: 1_000_000})
+	if math.Abs(got.Amount-1.95) > 1e-9 || got.Currency != CurrencyUSD {
+		t.Errorf("CacheSavings = %+v, want $1.95", got)
+	}
+	if got := (Pricing{Input: 3, Output: 15}).CacheSavings(Usage{CacheReadInputTokens: 1_000_000}); !got.IsZero() {
+		t.Errorf("CacheSavings without a cache rate = %+v, want zero", got)
+	}
+}
+
+func TestLookupPricing(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	store, err := NewStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"claude-sonnet-4-5", "claude-sonnet-4-5-20250929", "claude-sonnet-4-5@20250929", "gpt-4o-2024-08-06", "gpt-4o-mini"} {
+		if _, ok := LookupPricing(store, id); !ok {
+			t.Errorf("LookupPricing(%q) found nothing", id)
+		}
+	}
+	if p, _ := LookupPricing(store, "gpt-4o-mini-2024-07-18"); p.Input != 0.15 {
+		t.Errorf("dated gpt-4o-mini matched %+v, want the longest base ID", p)
+	}
+	for _, id := range []string{"claude-opus-4-9", "gpt-5.2", "llama3.1"} {
+		if p, ok := LookupPricing(store, id); ok {
+			t.Errorf("LookupPricing(%q) = %+v, want unknown", id, p)
+		}
+	}
+
+	override := Pricing{Input: 1, Output: 2, Currency: CurrencyCNY}
+	if err := store.SetPricing("llama3.1", override); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := NewStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := LookupPricing(reloaded, "llama3.1"); !ok || p != override {
+		t.Fatalf("override after reload = %+v, %v", p, ok)
+	}
+	if err := reloaded.ClearPricing("llama3.1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := LookupPricing(reloaded, "llama3.1"); ok {
+		t.Error("cleared override still applies")
+	}
+}
+
+func TestSpendLedger(t *testing.T) {
+	var l SpendLedger
+	sonnet := Pricing{Input: 3, Output: 15}
+	minimax := Pricing{Input: 2.1, Output: 8.4, Currency: CurrencyCNY}
+
+	l.Record(Anthropic, "claude-sonnet-4-5", Usage{InputTokens: 1_000_000}, &sonnet)
+	l.Record(Anthropic, "claude-sonnet-4-5", Usage{OutputTokens: 1_000_000}, &sonnet)
+	l.Record(MinMax, "MiniMax-M2", Usage{InputTokens: 1_000_000}, &minimax)
+	l.Record(Ollama, "llama3.1", Usage{InputTokens: 500, OutputTokens: 20}, nil)
+
+	models := l.Models()
+	if len(models) != 3 {
+		t.Fatalf("models = %+v, want one entry per model", models)
+	}
+	if s := models[0]; s.Requests != 2 || !s.Priced || s.Cost.Amount != 18 {
+		t.Errorf("sonnet = %+v, want 2 requests costing $18", s)
+	}
+	if s := models[2]; s.Priced || !s.Cost.IsZero() || s.Usage.InputTokens != 500 {
+		t.Errorf("unpriced model = %+v, want tokens without cost", s)
+	}
+
+	totals := l.Totals()
+	if len(totals) != 2 || totals[0] != (Money{18, CurrencyUSD}) || totals[1] != (Money{2.1, CurrencyCNY}) {
+		t.Errorf("totals = %+v, want one per currency", totals)
+	}
+
+	if u := l.TotalUsage(); u.InputTokens != 2_000_500 || u.OutputTokens != 1_000_020 {
+		t.Errorf("TotalUsage = %+v, want tokens summed across models", u)
+	}
+
+	if saved := l.CacheSavingsTotals(); saved != nil {
+		t.Errorf("CacheSavingsTotals = %+v, want none without cached tokens", saved)
+	}
+	haiku := Pricing{Input: 1, Output: 5, CacheRead: 0.1}
+	l.Record(Anthropic, "claude-haiku-4-5", Usage{CacheReadInputTokens: 1_000_000}, &haiku)
+	if saved := l.CacheSavingsTotals(); len(saved) != 1 || math.Abs(saved[0].Amount-0.9) > 1e-9 {
+		t.Errorf("CacheSavingsTotals = %+v, want $0.90", saved)
+	}
+
+	l.Reset()
+	if len(l.Models()) != 0 || l.Totals() != nil {
+		t.Error("Reset left entries behind")
+	}
+}
@@ -0,0 +1,209 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yanmxa/gencode/internal/log"
+)
+
+// APIError is an error response from a provider's HTTP API. Providers wrap
+// SDK errors in it so retries can tell transient failures from permanent ones.
+type APIError struct {
+	StatusCode int
+	RetryAfter time.Duration // from the Retry-After header; 0 when absent
+	Err        error
+}
+
+// NewAPIError wraps err with the status code and Retry-After header of the
+// response that caused it.
+func NewAPIError(err error, statusCode int, header http.Header) *APIError {
+	return &APIError{StatusCode: statusCode, RetryAfter: parseRetryAfter(header), Err: err}
+}
+
+func (e *APIError) Error() string { return e.Err.Error() }
+func (e *APIError) Unwrap() error { return e.Err }
+
+// Retryable reports whether the request may succeed if sent again:
+// rate limits (429) and server errors (5xx).
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(header http.Header) time.Duration {
+	v := header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
+
+// RetryConfig controls how a request that fails with a retryable APIError
+// before producing any output is sent again.
+type RetryConfig struct {
+	MaxRetries int           // retries after the first attempt; 0 disables retrying
+	BaseDelay  time.Duration // delay before the first retry, doubled for each later one
+	MaxDelay   time.Duration // cap on any single delay, including Retry-After
+}
+
+// DefaultRetryConfig is used until SetRetryConfig is called.
+var DefaultRetryConfig = RetryConfig{
+	MaxRetries: 3,
+	BaseDelay:  time.Second,
+	MaxDelay:   30 * time.Second,
+}
+
+var retryConfig = struct {
+	mu  sync.Mutex
+	cfg RetryConfig
+}{cfg: DefaultRetryConfig}
+
+// SetRetryConfig sets how failed requests are retried for all providers.
+// Zero durations fall back to DefaultRetryConfig's.
+func SetRetryConfig(cfg RetryConfig) {
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = DefaultRetryConfig.BaseDelay
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = DefaultRetryConfig.MaxDelay
+	}
+	retryConfig.mu.Lock()
+	defer retryConfig.mu.Unlock()
+	retryConfig.cfg = cfg
+}
+
+// CurrentRetryConfig returns the retry configuration in effect.
+func CurrentRetryConfig() RetryConfig {
+	retryConfig.mu.Lock()
+	defer retryConfig.mu.Unlock()
+	return retryConfig.cfg
+}
+
+// delay returns how long to wait before retry number attempt (0-based):
+// the server's Retry-After when given, otherwise exponential backoff with
+// jitter in [d/2, d). err is nil for connection failures.
+func (c RetryConfig) delay(attempt int, err *APIError) time.Duration {
+	if err != nil && err.RetryAfter > 0 {
+		return min(err.RetryAfter, c.MaxDelay)
+	}
+	d := min(c.BaseDelay<<attempt, c.MaxDelay)
+	return d/2 + rand.N(d/2+1)
+}
+
+// sleepRetry waits between attempts; tests replace it.
+var sleepRetry = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryableError reports whether a request that failed with err before any
+// output may succeed if sent again: a retryable APIError, or a dropped or
+// timed-out connection. It returns the APIError when there is one.
+func retryableError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, apiErr.Retryable()
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return nil, true
+	}
+	return nil, errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// streamRetrying streams opts from p and sends the request again, with
+// backoff, while it fails with a transient error before any output. Before
+// each wait it emits a ChunkTypeNotice so callers can show progress.
+// opts.MaxRetries, when set, overrides the configured retry count.
+func streamRetrying(ctx context.Context, p Provider, opts CompletionOptions) <-chan StreamChunk {
+	cfg := CurrentRetryConfig()
+	if opts.MaxRetries != 0 {
+		cfg.MaxRetries = opts.MaxRetries
+	}
+	if cfg.MaxRetries <= 0 {
+		return streamLimited(ctx, p, opts)
+	}
+
+	out := make(chan StreamChunk, 8)
+	go func() {
+		defer close(out)
+		for attempt := 0; ; attempt++ {
+			err := forwardStream(ctx, streamLimited(ctx, p, opts), out)
+			if err == nil {
+				return
+			}
+			apiErr, retryable := retryableError(err)
+			if attempt >= cfg.MaxRetries || !retryable || ctx.Err() != nil {
+				select {
+				case out <- StreamChunk{Type: ChunkTypeError, Error: err}:
+				case <-ctx.Done():
+				}
+				return
+			}
+
+			wait := cfg.delay(attempt, apiErr)
+			log.Logger().Warn("model request failed, retrying",
+				zap.String("provider", p.Name()), zap.Int("attempt", attempt+1),
+				zap.Duration("wait", wait), zap.Error(err))
+			notice := StreamChunk{Type: ChunkTypeNotice, Text: retryNotice(apiErr, wait, attempt+1, cfg.MaxRetries)}
+			select {
+			case out <- notice:
+			case <-ctx.Done():
+				return
+			}
+			if sleepRetry(ctx, wait) != nil {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// retryNotice describes a retry, e.g. "Retrying (2/3) in 4s: rate limited...".
+// err is nil for connection failures.
+func retryNotice(err *APIError, wait time.Duration, attempt, maxRetries int) string {
+	reason := "connection error"
+	if err != nil {
+		reason = statusReason(err.StatusCode)
+	}
+	return fmt.Sprintf("Retrying (%d/%d) in %s: %s...", attempt, maxRetries, wait.Round(100*time.Millisecond), reason)
+}
+
+// statusReason describes a failed request's HTTP status for the user.
+func statusReason(status int) string {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return "rate limited"
+	case status == 529:
+		return "overloaded"
+	case status >= 500:
+		return fmt.Sprintf("server error %d", status)
+	default:
+		return fmt.Sprintf("error %d", status)
+	}
+}
@@ -0,0 +1,212 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func apiErrorScript(status int, retryAfter string) []StreamChunk {
+	header := http.Header{}
+	if retryAfter != "" {
+		header.Set("Retry-After", retryAfter)
+	}
+	err := NewAPIError(errors.New(http.StatusText(status)), status, header)
+	return []StreamChunk{{Type: ChunkTypeError, Error: err}}
+}
+
+// recordRetryWaits replaces the retry sleep for the test and returns the
+// waits it was asked for.
+func recordRetryWaits(t *testing.T, cfg RetryConfig) *[]time.Duration {
+	t.Helper()
+	prevCfg, prevSleep := CurrentRetryConfig(), sleepRetry
+	t.Cleanup(func() {
+		SetRetryConfig(prevCfg)
+		sleepRetry = prevSleep
+	})
+	SetRetryConfig(cfg)
+	var waits []time.Duration
+	sleepRetry = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return &waits
+}
+
+func collectStream(ch <-chan StreamChunk) (text, notices string, err error) {
+	for chunk := range ch {
+		switch {
+		case chunk.Type == ChunkTypeNotice:
+			notices += chunk.Text + "\n"
+		case chunk.Type == ChunkTypeText:
+			text += chunk.Text
+		case chunk.Type == ChunkTypeError:
+			err = chunk.Error
+		}
+	}
+	return text, notices, err
+}
+
+func TestStreamRetriesTransientErrors(t *testing.T) {
+	waits := recordRetryWaits(t, RetryConfig{MaxRetries: 3, BaseDelay: time.Second, MaxDelay: 30 * time.Second})
+	p := &scriptedProvider{name: "anthropic", scripts: [][]StreamChunk{
+		apiErrorScript(http.StatusTooManyRequests, "2"),
+		apiErrorScript(529, ""),
+		textScript("ok"),
+	}}
+
+	text, notices, err := collectStream(StreamCompletion(context.Background(), p, CompletionOptions{Model: "m"}))
+	if err != nil || text != "ok" {
+		t.Fatalf("text=%q err=%v, want ok after retries", text, err)
+	}
+	if len(p.models) != 3 {
+		t.Errorf("attempts = %d, want 3", len(p.models))
+	}
+	if len(*waits) != 2 || (*waits)[0] != 2*time.Second {
+		t.Fatalf("waits = %v, want Retry-After 2s first", *waits)
+	}
+	if w := (*waits)[1]; w < time.Second || w > 2*time.Second {
+		t.Errorf("second wait = %v, want backoff with jitter in [1s, 2s]", w)
+	}
+	if !strings.Contains(notices, "Retrying (1/3) in 2s: rate limited...") || !strings.Contains(notices, "Retrying (2/3)") ||
+		!strings.Contains(notices, "overloaded") {
+		t.Errorf("notices = %q", notices)
+	}
+}
+
+func TestStreamDoesNotRetryClientErrors(t *testing.T) {
+	waits := recordRetryWaits(t, DefaultRetryConfig)
+	p := &scriptedProvider{name: "openai", scripts: [][]StreamChunk{
+		apiErrorScript(http.StatusBadRequest, ""),
+		textScript("unreachable"),
+	}}
+
+	_, _, err := collectStream(StreamCompletion(context.Background(), p, CompletionOptions{Model: "m"}))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("err = %v, want the 400", err)
+	}
+	if len(p.models) != 1 || len(*waits) != 0 {
+		t.Errorf("attempts = %d, waits = %v; 4xx must fail immediately", len(p.models), *waits)
+	}
+}
+
+func TestStreamGivesUpAfterMaxRetries(t *testing.T) {
+	waits := recordRetryWaits(t, RetryConfig{MaxRetries: 2, BaseDelay: time.Second, MaxDelay: 5 * time.Second})
+	p := &scriptedProvider{name: "openai", scripts: [][]StreamChunk{
+		apiErrorScript(http.StatusServiceUnavailable, "60"),
+		apiErrorScript(http.StatusServiceUnavailable, ""),
+		apiErrorScript(http.StatusServiceUnavailable, ""),
+		textScript("unreachable"),
+	}}
+
+	_, _, err := collectStream(StreamCompletion(context.Background(), p, CompletionOptions{Model: "m"}))
+	if err == nil {
+		t.Fatal("expected the last error after retries ran out")
+	}
+	if len(p.models) != 3 {
+		t.Errorf("attempts = %d, want 1 + 2 retries", len(p.models))
+	}
+	if (*waits)[0] != 5*time.Second {
+		t.Errorf("Retry-After wait = %v, want it capped at MaxDelay", (*waits)[0])
+	}
+}
+
+func TestStreamRetriesDroppedConnections(t *testing.T) {
+	waits := recordRetryWaits(t, RetryConfig{MaxRetries: 1, BaseDelay: time.Second, MaxDelay: time.Second})
+	p := &scriptedProvider{name: "anthropic", scripts: [][]StreamChunk{
+		{{Type: ChunkTypeError, Error: fmt.Errorf("read body: %w", io.ErrUnexpectedEOF)}},
+		textScript("ok"),
+	}}
+
+	text, notices, err := collectStream(StreamCompletion(context.Background(), p, CompletionOptions{Model: "m"}))
+	if err != nil || text != "ok" || len(*waits) != 1 {
+		t.Fatalf("text=%q err=%v waits=%v, want one retry", text, err, *waits)
+	}
+	if !strings.HasPrefix(notices, "Retrying (1/1) in ") || !strings.Contains(notices, ": connection error...") {
+		t.Errorf("notices = %q", notices)
+	}
+}
+
+func TestStreamDoesNotRetryAfterOutput(t *testing.T) {
+	waits := recordRetryWaits(t, DefaultRetryConfig)
+	p := &scriptedProvider{name: "anthropic", scripts: [][]StreamChunk{
+		{{Type: ChunkTypeText, Text: "partial"}, {Type: ChunkTypeError, Error: NewAPIError(errors.New("overloaded"), 529, nil)}},
+		textScript("unreachable"),
+	}}
+
+	text, _, err := collectStream(StreamCompletion(context.Background(), p, CompletionOptions{Model: "m"}))
+	if err == nil || text != "partial" {
+		t.Fatalf("text=%q err=%v, want the partial answer and its error", text, err)
+	}
+	if len(p.models) != 1 || len(*waits) != 0 {
+		t.Errorf("attempts = %d, waits = %v; a started answer must not be retried", len(p.models), *waits)
+	}
+}
+
+func TestStreamMaxRetriesOption(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxRetries   int
+		wantAttempts int
+	}{
+		{"default", 0, 3},
+		{"override", 1, 2},
+		{"disabled", -1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recordRetryWaits(t, RetryConfig{MaxRetries: 2})
+			p := &scriptedProvider{name: "openai", scripts: [][]StreamChunk{
+				apiErrorScript(http.StatusServiceUnavailable, ""),
+				apiErrorScript(http.StatusServiceUnavailable, ""),
+				apiErrorScript(http.StatusServiceUnavailable, ""),
+			}}
+			opts := CompletionOptions{Model: "m", MaxRetries: tt.maxRetries}
+			if _, _, err := collectStream(StreamCompletion(context.Background(), p, opts)); err == nil {
+				t.Fatal("expected an error")
+			}
+			if len(p.models) != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", len(p.models), tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryNoticesDoNotBlockFailover(t *testing.T) {
+	recordRetryWaits(t, RetryConfig{MaxRetries: 1})
+	primary := &scriptedProvider{name: "anthropic:api_key", scripts: [][]StreamChunk{
+		apiErrorScript(http.StatusServiceUnavailable, ""),
+		apiErrorScript(http.StatusServiceUnavailable, ""),
+	}}
+	backup := &scriptedProvider{name: "openai:api_key", scripts: [][]StreamChunk{textScript("from backup")}}
+
+	c := NewClient(primary, "claude", 0)
+	c.SetFailover(&Failover{
+		Chain:   func() []FallbackEntry { return []FallbackEntry{{Provider: OpenAI, ModelID: "gpt"}} },
+		Connect: func(context.Context, Name) (Provider, error) { return backup, nil },
+	})
+
+	text, err := collectInfer(t, c)
+	if err != nil || !strings.HasSuffix(text, "from backup") {
+		t.Fatalf("text=%q err=%v, want failover after retries", text, err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d := parseRetryAfter(http.Header{"Retry-After": {"3"}}); d != 3*time.Second {
+		t.Errorf("seconds: %v", d)
+	}
+	date := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+	if d := parseRetryAfter(http.Header{"Retry-After": {date}}); d < 8*time.Second || d > 10*time.Second {
+		t.Errorf("HTTP date: %v", d)
+	}
+	if d := parseRetryAfter(nil); d != 0 {
+		t.Errorf("missing header: %v", d)
+	}
+}
@@ -38,6 +38,8 @@ func Initialize(opts Options) {
 		return
 	}
 
+	SetMaxConcurrentRequests(store.GetMaxConcurrentRequests())
+
 	defaultSetup.mu.Lock()
 	defaultSetup.Store = store
 	defaultSetup.CurrentModel = store.GetCurrentModel()
@@ -7,6 +7,7 @@ import (
 	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 	"time"
 )
@@ -35,19 +36,36 @@ type CurrentModelInfo struct {
 	AuthMethod AuthMethod `json:"authMethod"`
 }
 
+// TokenLimitSource describes where the active token limits of a model came from.
+type TokenLimitSource string
+
+const (
+	TokenLimitSourceNone     TokenLimitSource = ""             // no limits known
+	TokenLimitSourceProvider TokenLimitSource = "provider"     // provider model cache (ListModels)
+	TokenLimitSourceCustom   TokenLimitSource = "custom"       // set by the user
+	TokenLimitSourceFetched  TokenLimitSource = "auto-fetched" // found by /tokenlimit auto-fetch
+)
+
 // tokenLimitOverride stores custom token limits for a model
 type tokenLimitOverride struct {
-	InputTokenLimit  int `json:"inputTokenLimit"`
-	OutputTokenLimit int `json:"outputTokenLimit"`
+	InputTokenLimit  int              `json:"inputTokenLimit"`
+	OutputTokenLimit int              `json:"outputTokenLimit"`
+	Source           TokenLimitSource `json:"source,omitempty"` // empty = custom (older stores)
 }
 
 // storeData is the persisted data structure
 type storeData struct {
-	Connections    map[string]ConnectionInfo     `json:"connections"`              // key: provider
-	Models         map[string]modelCache         `json:"models"`                   // key: provider:authMethod
-	Current        *CurrentModelInfo             `json:"current"`                  // current model with provider info
-	SearchProvider *string                       `json:"searchProvider,omitempty"` // search provider name (exa, serper, brave)
-	TokenLimits    map[string]tokenLimitOverride `json:"tokenLimits,omitempty"`    // key: modelID
+	Connections    map[string]ConnectionInfo     `json:"connections"`                     // key: provider
+	Models         map[string]modelCache         `json:"models"`                          // key: provider:authMethod
+	Current        *CurrentModelInfo             `json:"current"`                         // current model with provider info
+	SearchProvider *string                       `json:"searchProvider,omitempty"`        // search provider name (exa, serper, brave)
+	TokenLimits    map[string]tokenLimitOverride `json:"tokenLimits,omitempty"`           // key: modelID
+	FavoriteModels map[string][]string           `json:"favoriteModels,omitempty"`        // key: provider
+	ModelListLimit int                           `json:"modelListLimit,omitempty"`        // models shown per provider before "show all"
+	MaxConcurrent  int                           `json:"maxConcurrentRequests,omitempty"` // in-flight requests per provider; <0 = unlimited
+	ModelLastUsed  map[string]time.Time          `json:"modelLastUsed,omitempty"`         // key: provider:modelID
+	FallbackChain  []FallbackEntry               `json:"fallbackChain,omitempty"`         // models tried in order when a stream fails
+	Pricing        map[string]Pricing            `json:"pricing,omitempty"`               // key: modelID; overrides built-in prices
 }
 
 // Store manages provider configuration persistence
@@ -115,6 +133,12 @@ func (s *Store) ensureMapsInitialized() {
 	if s.data.TokenLimits == nil {
 		s.data.TokenLimits = make(map[string]tokenLimitOverride)
 	}
+	if s.data.FavoriteModels == nil {
+		s.data.FavoriteModels = make(map[string][]string)
+	}
+	if s.data.ModelLastUsed == nil {
+		s.data.ModelLastUsed = make(map[string]time.Time)
+	}
 }
 
 // save writes the store data to disk
@@ -253,9 +277,20 @@ func (s *Store) SetCurrentModel(modelID string, provider Name, authMethod AuthMe
 		Provider:   provider,
 		AuthMethod: authMethod,
 	}
+	s.ensureMapsInitialized()
+	s.data.ModelLastUsed[string(provider)+":"+modelID] = time.Now()
 	return s.save()
 }
 
+// GetModelLastUsed returns when a model was last selected, or the zero time
+// if it never was.
+func (s *Store) GetModelLastUsed(provider Name, modelID string) time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.data.ModelLastUsed[string(provider)+":"+modelID]
+}
+
 // GetCurrentModel returns the current model info
 func (s *Store) GetCurrentModel() *CurrentModelInfo {
 	s.mu.RLock()
@@ -287,6 +322,11 @@ func (s *Store) SetSearchProvider(name string) error {
 // SetTokenLimit sets custom token limits for a model.
 // It also updates the model cache so subsequent model listings reflect these limits.
 func (s *Store) SetTokenLimit(modelID string, inputLimit, outputLimit int) error {
+	return s.SetTokenLimitWithSource(modelID, inputLimit, outputLimit, TokenLimitSourceCustom)
+}
+
+// SetTokenLimitWithSource is SetTokenLimit recording where the limits came from.
+func (s *Store) SetTokenLimitWithSource(modelID string, inputLimit, outputLimit int, source TokenLimitSource) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -294,6 +334,7 @@ func (s *Store) SetTokenLimit(modelID string, inputLimit, outputLimit int) error
 	s.data.TokenLimits[modelID] = tokenLimitOverride{
 		InputTokenLimit:  inputLimit,
 		OutputTokenLimit: outputLimit,
+		Source:           source,
 	}
 
 	// Update the model cache entry so model listings show the limits.
@@ -336,3 +377,124 @@ func (s *Store) GetTokenLimit(modelID string) (inputLimit, outputLimit int, ok b
 	}
 	return override.InputTokenLimit, override.OutputTokenLimit, true
 }
+
+// GetTokenLimitSource reports where the stored token limits of a model came
+// from, or TokenLimitSourceNone when none are stored.
+func (s *Store) GetTokenLimitSource(modelID string) TokenLimitSource {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	override, exists := s.data.TokenLimits[modelID]
+	if !exists {
+		return TokenLimitSourceNone
+	}
+	if override.Source == "" {
+		return TokenLimitSourceCustom
+	}
+	return override.Source
+}
+
+// GetFavoriteModels returns the pinned model IDs for a provider.
+func (s *Store) GetFavoriteModels(provider Name) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]string(nil), s.data.FavoriteModels[string(provider)]...)
+}
+
+// IsFavoriteModel reports whether a model is pinned for a provider.
+func (s *Store) IsFavoriteModel(provider Name, modelID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Contains(s.data.FavoriteModels[string(provider)], modelID)
+}
+
+// ToggleFavoriteModel pins or unpins a model and reports whether it is now pinned.
+func (s *Store) ToggleFavoriteModel(provider Name, modelID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ensureMapsInitialized()
+	key := string(provider)
+	favorites := s.data.FavoriteModels[key]
+	pinned := true
+	if idx := slices.Index(favorites, modelID); idx >= 0 {
+		favorites = slices.Delete(slices.Clone(favorites), idx, idx+1)
+		pinned = false
+	} else {
+		favorites = append(slices.Clone(favorites), modelID)
+	}
+	if len(favorites) == 0 {
+		delete(s.data.FavoriteModels, key)
+	} else {
+		s.data.FavoriteModels[key] = favorites
+	}
+	return pinned, s.save()
+}
+
+// DefaultModelListLimit is the number of models shown per provider in the
+// model selector before the full catalog is revealed.
+const DefaultModelListLimit = 10
+
+// GetModelListLimit returns how many models the selector shows per provider
+// before the full catalog is revealed.
+func (s *Store) GetModelListLimit() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.data.ModelListLimit > 0 {
+		return s.data.ModelListLimit
+	}
+	return DefaultModelListLimit
+}
+
+// GetMaxConcurrentRequests returns the configured per-provider in-flight
+// request limit, or 0 when unset (use DefaultMaxConcurrentRequests).
+func (s *Store) GetMaxConcurrentRequests() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.MaxConcurrent
+}
+
+// GetFallbackChain returns the ordered fallback chain.
+func (s *Store) GetFallbackChain() []FallbackEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.data.FallbackChain)
+}
+
+// SetFallbackChain replaces the fallback chain.
+func (s *Store) SetFallbackChain(chain []FallbackEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.FallbackChain = slices.Clone(chain)
+	return s.save()
+}
+
+// GetPricing returns the user's price override for a model.
+func (s *Store) GetPricing(modelID string) (Pricing, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.data.Pricing[modelID]
+	return p, ok
+}
+
+// SetPricing overrides the price of a model.
+func (s *Store) SetPricing(modelID string, p Pricing) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.Pricing == nil {
+		s.data.Pricing = make(map[string]Pricing)
+	}
+	s.data.Pricing[modelID] = p
+	return s.save()
+}
+
+// ClearPricing removes a model's price override.
+func (s *Store) ClearPricing(modelID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data.Pricing, modelID)
+	return s.save()
+}
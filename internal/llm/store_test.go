@@ -85,3 +85,81 @@ func TestStore_SetTokenLimitUpdatesCachedModelCopy(t *testing.T) {
 		t.Fatalf("expected previously returned cached slice to remain unchanged, got %#v", cachedBefore[0])
 	}
 }
+
+func TestStore_ToggleFavoriteModelPersists(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	store, err := NewStore()
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	pinned, err := store.ToggleFavoriteModel(OpenAI, "gpt-5")
+	if err != nil || !pinned {
+		t.Fatalf("ToggleFavoriteModel() = %v, %v; want pinned", pinned, err)
+	}
+
+	reloaded, err := NewStore()
+	if err != nil {
+		t.Fatalf("NewStore(reload) error = %v", err)
+	}
+	if !reloaded.IsFavoriteModel(OpenAI, "gpt-5") {
+		t.Fatal("expected favorite to persist")
+	}
+	if pinned, _ := reloaded.ToggleFavoriteModel(OpenAI, "gpt-5"); pinned {
+		t.Fatal("expected second toggle to unpin")
+	}
+	if len(reloaded.GetFavoriteModels(OpenAI)) != 0 {
+		t.Fatal("expected no favorites after unpin")
+	}
+}
+
+func TestStore_TokenLimitSourcePersists(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+
+	store, err := NewStore()
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	if got := store.GetTokenLimitSource("gpt-5"); got != TokenLimitSourceNone {
+		t.Fatalf("source without override = %q, want none", got)
+	}
+	if err := store.SetTokenLimit("gpt-5", 200000, 32000); err != nil {
+		t.Fatalf("SetTokenLimit() error = %v", err)
+	}
+	if err := store.SetTokenLimitWithSource("claude", 200000, 64000, TokenLimitSourceFetched); err != nil {
+		t.Fatalf("SetTokenLimitWithSource() error = %v", err)
+	}
+
+	reloaded, err := NewStore()
+	if err != nil {
+		t.Fatalf("NewStore(reload) error = %v", err)
+	}
+	if got := reloaded.GetTokenLimitSource("gpt-5"); got != TokenLimitSourceCustom {
+		t.Errorf("gpt-5 source = %q, want %q", got, TokenLimitSourceCustom)
+	}
+	if got := reloaded.GetTokenLimitSource("claude"); got != TokenLimitSourceFetched {
+		t.Errorf("claude source = %q, want %q", got, TokenLimitSourceFetched)
+	}
+}
+
+func TestStore_FallbackChainPersists(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	store, err := NewStore()
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	chain := []FallbackEntry{{Provider: OpenAI, ModelID: "gpt-5"}, {Provider: Google, ModelID: "gemini-2.5-pro"}}
+	if err := store.SetFallbackChain(chain); err != nil {
+		t.Fatalf("SetFallbackChain() error = %v", err)
+	}
+	reloaded, err := NewStore()
+	if err != nil {
+		t.Fatalf("NewStore(reload) error = %v", err)
+	}
+	got := reloaded.GetFallbackChain()
+	if len(got) != 2 || got[0] != chain[0] || got[1] != chain[1] {
+		t.Fatalf("reloaded chain = %v, want %v", got, chain)
+	}
+}
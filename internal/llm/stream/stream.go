@@ -91,6 +91,16 @@ func (s *State) UpdateUsage(inputTokens, outputTokens int) {
 	}
 }
 
+// UpdatePromptUsage records usage from providers whose prompt count includes
+// the tokens read from their prompt cache (OpenAI, Gemini). The cached tokens
+// are split out so that Usage means the same as for Anthropic: InputTokens
+// are the uncached prompt tokens and CacheReadInputTokens the cached ones.
+func (s *State) UpdatePromptUsage(promptTokens, cachedTokens, outputTokens int) {
+	cachedTokens = min(max(cachedTokens, 0), promptTokens)
+	s.UpdateUsage(promptTokens-cachedTokens, outputTokens)
+	s.UpdateCacheUsage(0, cachedTokens)
+}
+
 // UpdateCacheUsage records prompt-caching token counts from the provider response.
 func (s *State) UpdateCacheUsage(cacheCreation, cacheRead int) {
 	if cacheCreation > 0 {
@@ -115,6 +125,20 @@ func (s *State) AddToolCallsByKey(toolCalls map[string]*core.ToolCall) {
 	}
 }
 
+// AddCitation records a cited source once, keeping first-cited order.
+// Citations without a URL are ignored.
+func (s *State) AddCitation(title, url string) {
+	if url == "" {
+		return
+	}
+	for _, c := range s.Response.Citations {
+		if c.URL == url {
+			return
+		}
+	}
+	s.Response.Citations = append(s.Response.Citations, core.Citation{Title: title, URL: url})
+}
+
 // EnsureToolUseStopReason infers tool_use when tool calls exist but no stop reason was set.
 func (s *State) EnsureToolUseStopReason() {
 	if len(s.Response.ToolCalls) > 0 && s.Response.StopReason == "" {
@@ -90,6 +90,27 @@ func TestStateEnsureToolUseStopReason(t *testing.T) {
 	}
 }
 
+func TestStateAddCitationDedupesByURL(t *testing.T) {
+	state := NewState("test")
+	state.AddCitation("Go", "https://go.dev")
+	state.AddCitation("", "")
+	state.AddCitation("Go again", "https://go.dev")
+	state.AddCitation("", "https://pkg.go.dev")
+
+	want := []core.Citation{
+		{Title: "Go", URL: "https://go.dev"},
+		{URL: "https://pkg.go.dev"},
+	}
+	if len(state.Response.Citations) != len(want) {
+		t.Fatalf("citations = %#v, want %#v", state.Response.Citations, want)
+	}
+	for i := range want {
+		if state.Response.Citations[i] != want[i] {
+			t.Fatalf("citations[%d] = %#v, want %#v", i, state.Response.Citations[i], want[i])
+		}
+	}
+}
+
 func TestStateFailAndFinishEmitTerminalChunks(t *testing.T) {
 	ch := make(chan llm.StreamChunk, 4)
 	state := NewState("test")
@@ -18,6 +18,8 @@ const (
 	Moonshot  Name = "moonshot"
 	Alibaba   Name = "alibaba"
 	MinMax    Name = "minmax"
+	DeepSeek  Name = "deepseek"
+	Ollama    Name = "ollama"
 )
 
 // AuthMethod represents an authentication method for an LLM provider.
@@ -27,6 +29,7 @@ const (
 	AuthAPIKey  AuthMethod = "api_key"
 	AuthVertex  AuthMethod = "vertex"
 	AuthBedrock AuthMethod = "bedrock"
+	AuthLocal   AuthMethod = "local" // local server, no credentials
 )
 
 // Meta contains static metadata about a provider
@@ -126,10 +129,15 @@ type CompletionOptions struct {
 	Model          string
 	Messages       []core.Message
 	MaxTokens      int
-	Temperature    float64
+	Temperature    *float64 // nil uses the provider default
 	Tools          []ToolSchema
 	SystemPrompt   string
 	ThinkingEffort string
+	MaxRetries     int `json:"-"` // retries on transient failures; 0 uses the configured default, negative disables
+	// EnablePromptCache marks the stable prefix of the request (tools,
+	// system prompt, conversation so far) for provider-side prompt caching.
+	// nil uses the provider default, which is on for Anthropic.
+	EnablePromptCache *bool `json:"-"`
 }
 
 // --- Completion Response Types ---
@@ -142,6 +150,7 @@ type CompletionResponse struct {
 	ToolCalls         []core.ToolCall `json:"tool_calls,omitempty"`
 	StopReason        string          `json:"stop_reason"`
 	Usage             Usage           `json:"usage"`
+	Citations         []core.Citation `json:"citations,omitempty"`
 }
 
 // Logging accessors — satisfy duck-typed interfaces in the log package so
@@ -186,6 +195,8 @@ const (
 	ChunkTypeToolInput ChunkType = "tool_input"
 	ChunkTypeDone      ChunkType = "done"
 	ChunkTypeError     ChunkType = "error"
+	ChunkTypeNotice    ChunkType = "notice"   // status for the user, such as a retry; not model output
+	ChunkTypeFallback  ChunkType = "fallback" // the request moved to a fallback model; not model output
 )
 
 // StreamChunk represents a chunk in a streaming response from a provider.
@@ -227,7 +238,7 @@ type Factory func(ctx context.Context) (Provider, error)
 func Complete(ctx context.Context, provider Provider, opts CompletionOptions) (CompletionResponse, error) {
 	var response CompletionResponse
 
-	streamChan := provider.Stream(ctx, opts)
+	streamChan := StreamCompletion(ctx, provider, opts)
 
 	gotDone := false
 	for chunk := range streamChan {
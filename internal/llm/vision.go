@@ -0,0 +1,67 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+// VisionProvider is implemented by providers whose models do not all accept
+// image input. Providers that don't implement it are assumed to accept
+// images for every model.
+type VisionProvider interface {
+	SupportsImages(model string) bool
+}
+
+// SupportsImages reports whether model on provider p accepts image input.
+func SupportsImages(p Provider, model string) bool {
+	vp, ok := p.(VisionProvider)
+	return !ok || vp.SupportsImages(model)
+}
+
+// ErrImagesNotSupported is the error a request fails with when its newest
+// message carries images for a model that cannot read them.
+var ErrImagesNotSupported = errors.New("model does not accept images")
+
+// checkNewImages rejects a request whose newest message attaches images the
+// model cannot read, so the user learns of it rather than the images being
+// left out. Images in earlier messages, sent while a vision model was in
+// use, are dropped by dropUnsupportedImages instead.
+func checkNewImages(p Provider, opts CompletionOptions) error {
+	if len(opts.Messages) == 0 || SupportsImages(p, opts.Model) {
+		return nil
+	}
+	if n := len(opts.Messages[len(opts.Messages)-1].Images); n > 0 {
+		return fmt.Errorf("%w: %s cannot read the %d attached image(s); switch to a vision model or remove them", ErrImagesNotSupported, opts.Model, n)
+	}
+	return nil
+}
+
+// dropUnsupportedImages removes the images from opts when the model cannot
+// read them. Each message that lost images gets a note in its text instead,
+// so the model knows something was attached. opts.Messages is not modified.
+func dropUnsupportedImages(p Provider, opts CompletionOptions) CompletionOptions {
+	if SupportsImages(p, opts.Model) {
+		return opts
+	}
+	var msgs []core.Message
+	for i, msg := range opts.Messages {
+		if len(msg.Images) == 0 {
+			continue
+		}
+		if msgs == nil {
+			msgs = slices.Clone(opts.Messages)
+		}
+		note := fmt.Sprintf("[%d image(s) not sent: %s does not accept images]", len(msg.Images), opts.Model)
+		msg.Content = strings.TrimSpace(msg.Content + "\n\n" + note)
+		msg.Images = nil
+		msgs[i] = msg
+	}
+	if msgs != nil {
+		opts.Messages = msgs
+	}
+	return opts
+}
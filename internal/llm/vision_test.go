@@ -0,0 +1,64 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+// textOnlyProvider is a mock provider whose models do not read images.
+type textOnlyProvider struct{ mockLLMProvider }
+
+func (p *textOnlyProvider) SupportsImages(string) bool { return false }
+
+func TestStreamCompletionDropsImagesForTextOnlyModels(t *testing.T) {
+	img := core.Image{MediaType: "image/png", Data: "aGk=", FileName: "shot.png"}
+	msgs := []core.Message{
+		{Role: core.RoleUser, Content: "what is this?", Images: []core.Image{img}},
+		{Role: core.RoleAssistant, Content: "A chart."},
+		{Role: core.RoleUser, Content: "summarize it"},
+	}
+
+	text := &textOnlyProvider{}
+	drain(StreamCompletion(context.Background(), text, CompletionOptions{Model: "text-model", Messages: msgs}))
+	got := text.lastOpts.Messages[0]
+	if len(got.Images) != 0 || !strings.Contains(got.Content, "what is this?") || !strings.Contains(got.Content, "[1 image(s) not sent: text-model does not accept images]") {
+		t.Errorf("text-only request = %q with %d images", got.Content, len(got.Images))
+	}
+	if len(msgs[0].Images) != 1 {
+		t.Error("the caller's messages must keep their images")
+	}
+
+	vision := &mockLLMProvider{}
+	drain(StreamCompletion(context.Background(), vision, CompletionOptions{Model: "vision-model", Messages: msgs}))
+	if got := vision.lastOpts.Messages[0]; len(got.Images) != 1 || got.Content != "what is this?" {
+		t.Errorf("vision request = %q with %d images, want the image sent", got.Content, len(got.Images))
+	}
+}
+
+func TestStreamCompletionRejectsNewImagesForTextOnlyModels(t *testing.T) {
+	img := core.Image{MediaType: "image/png", Data: "aGk=", FileName: "shot.png"}
+	msgs := []core.Message{{Role: core.RoleUser, Content: "what is this?", Images: []core.Image{img}}}
+
+	text := &textOnlyProvider{}
+	var err error
+	for chunk := range StreamCompletion(context.Background(), text, CompletionOptions{Model: "text-model", Messages: msgs}) {
+		if chunk.Type == ChunkTypeError {
+			err = chunk.Error
+		}
+	}
+	if !errors.Is(err, ErrImagesNotSupported) || !strings.Contains(err.Error(), "text-model") {
+		t.Errorf("err = %v, want ErrImagesNotSupported naming the model", err)
+	}
+	if text.lastOpts.Model != "" {
+		t.Error("the request should not reach the provider")
+	}
+}
+
+func drain(ch <-chan StreamChunk) {
+	for range ch {
+	}
+}
@@ -38,6 +38,10 @@ type Client struct {
 	// This allows tests to inject a fake transport.
 	TransportFactory func() (transport.Transport, error)
 
+	// Sampling answers sampling/createMessage requests from the server.
+	// When nil, the sampling capability is not advertised.
+	Sampling SamplingHandler
+
 	mu           sync.RWMutex
 	connected    bool
 	capabilities ServerCapabilities
@@ -100,17 +104,33 @@ func (c *Client) createTransport() (transport.Transport, error) {
 		return transport.NewHTTPTransport(transport.HTTPConfig{
 			URL:     c.config.URL,
 			Headers: c.config.Headers,
+			Auth:    c.tokenSource(),
 		}), nil
 	case TransportSSE:
 		return transport.NewSSETransport(transport.SSEConfig{
 			URL:     c.config.URL,
 			Headers: c.config.Headers,
+			Auth:    c.tokenSource(),
+		}), nil
+	case TransportWebSocket:
+		return transport.NewWebSocketTransport(transport.WebSocketConfig{
+			URL:     c.config.URL,
+			Headers: c.config.Headers,
 		}), nil
 	default:
 		return nil, fmt.Errorf("unknown transport type: %s", c.config.GetType())
 	}
 }
 
+// tokenSource returns the OAuth token source of the server, or nil when it
+// does not use OAuth.
+func (c *Client) tokenSource() transport.TokenSource {
+	if c.config.OAuth == nil {
+		return nil
+	}
+	return newOAuthTokenSource(c.config.Name, c.config.URL, *c.config.OAuth)
+}
+
 // Connect establishes a connection to the MCP server
 func (c *Client) Connect(ctx context.Context) error {
 	c.mu.Lock()
@@ -150,6 +170,14 @@ func (c *Client) Connect(ctx context.Context) error {
 		},
 	}
 
+	// Answer server-initiated requests where the transport supports them
+	if rs, ok := c.transport.(transport.RequestHandlerSetter); ok {
+		rs.SetRequestHandler(c.handleRequest)
+		if c.Sampling != nil {
+			initParams.Capabilities.Sampling = &SamplingCapability{}
+		}
+	}
+
 	req := newRequest(MethodInitialize, initParams)
 	resp, err := c.transport.Send(ctx, req)
 	if err != nil {
@@ -453,15 +481,28 @@ func (c *Client) SetOnToolsChanged(callback func()) {
 
 // handleNotification processes incoming notifications from the server.
 // Runs in a goroutine to avoid deadlocking when Connect() holds mu.
+// Prompt changes use the same callback, since prompts are offered as
+// slash commands alongside the tools.
 func (c *Client) handleNotification(method string, _ []byte) {
-	if method != MethodToolsListChanged {
+	var refresh func(context.Context) error
+	switch method {
+	case MethodToolsListChanged:
+		refresh = func(ctx context.Context) error {
+			_, err := c.ListTools(ctx)
+			return err
+		}
+	case MethodPromptsListChanged:
+		refresh = func(ctx context.Context) error {
+			_, err := c.ListPrompts(ctx)
+			return err
+		}
+	default:
 		return
 	}
 
 	go func() {
-		ctx := context.Background()
-		if _, err := c.ListTools(ctx); err != nil {
-			return // Tool list refresh failed; will retry on next notification
+		if err := refresh(context.Background()); err != nil {
+			return // List refresh failed; will retry on next notification
 		}
 
 		c.mu.RLock()
@@ -144,6 +144,21 @@ func TestServerConfig_GetType(t *testing.T) {
 			config:   ServerConfig{Type: TransportSSE, URL: "https://example.com"},
 			expected: TransportSSE,
 		},
+		{
+			name:     "infer ws from ws URL",
+			config:   ServerConfig{URL: "ws://localhost:8080/mcp"},
+			expected: TransportWebSocket,
+		},
+		{
+			name:     "infer ws from wss URL",
+			config:   ServerConfig{URL: "WSS://example.com/mcp"},
+			expected: TransportWebSocket,
+		},
+		{
+			name:     "explicit type wins over ws URL",
+			config:   ServerConfig{Type: TransportHTTP, URL: "wss://example.com"},
+			expected: TransportHTTP,
+		},
 	}
 
 	for _, tt := range tests {
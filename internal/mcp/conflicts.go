@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ToolConflict describes an MCP tool name that is ambiguous to the user or to
+// tool-call routing.
+type ToolConflict struct {
+	Tool    string   // unprefixed tool name as reported by the server
+	Servers []string // servers exposing this name, sorted
+	Builtin bool     // the name matches a built-in tool
+	Routing bool     // mcp__<server>__<tool> would not route back to this server
+}
+
+func (c ToolConflict) String() string {
+	switch {
+	case c.Routing:
+		return fmt.Sprintf("MCP tool %q on server %q cannot be routed unambiguously (server or tool name contains \"__\"); it is hidden from the model",
+			c.Tool, strings.Join(c.Servers, ", "))
+	case c.Builtin && len(c.Servers) > 1:
+		return fmt.Sprintf("MCP tool %q is exposed by servers %s and shadows the built-in %s tool",
+			c.Tool, strings.Join(c.Servers, ", "), c.Tool)
+	case c.Builtin:
+		return fmt.Sprintf("MCP tool %q on server %q has the same name as the built-in %s tool; call it as %s",
+			c.Tool, c.Servers[0], c.Tool, mcpToolName(c.Servers[0], c.Tool))
+	default:
+		return fmt.Sprintf("MCP tool %q is exposed by multiple servers: %s",
+			c.Tool, strings.Join(c.Servers, ", "))
+	}
+}
+
+// Involves reports whether server is one of the servers in the conflict.
+func (c ToolConflict) Involves(server string) bool {
+	for _, s := range c.Servers {
+		if s == server {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectToolConflicts finds tool names shared by several servers, names that
+// shadow a built-in tool, and names whose prefixed form would be parsed back
+// into a different server/tool pair. serverTools maps server name to the
+// unprefixed tool names it exposes. Results are sorted by tool name.
+func DetectToolConflicts(serverTools map[string][]string, builtins []string) []ToolConflict {
+	builtin := make(map[string]bool, len(builtins))
+	for _, name := range builtins {
+		builtin[name] = true
+	}
+
+	var conflicts []ToolConflict
+	byTool := make(map[string][]string)
+	for server, tools := range serverTools {
+		for _, t := range tools {
+			if !routable(server, t) {
+				conflicts = append(conflicts, ToolConflict{Tool: t, Servers: []string{server}, Routing: true})
+				continue
+			}
+			byTool[t] = append(byTool[t], server)
+		}
+	}
+
+	for t, servers := range byTool {
+		if len(servers) < 2 && !builtin[t] {
+			continue
+		}
+		sort.Strings(servers)
+		conflicts = append(conflicts, ToolConflict{Tool: t, Servers: servers, Builtin: builtin[t]})
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Tool != conflicts[j].Tool {
+			return conflicts[i].Tool < conflicts[j].Tool
+		}
+		return conflicts[i].Servers[0] < conflicts[j].Servers[0]
+	})
+	return conflicts
+}
+
+// ToolConflicts runs DetectToolConflicts over all connected servers.
+func (r *Registry) ToolConflicts(builtins []string) []ToolConflict {
+	r.mu.RLock()
+	serverTools := make(map[string][]string, len(r.clients))
+	for name, client := range r.clients {
+		if !client.IsConnected() {
+			continue
+		}
+		for _, t := range client.GetCachedTools() {
+			serverTools[name] = append(serverTools[name], t.Name)
+		}
+	}
+	r.mu.RUnlock()
+	return DetectToolConflicts(serverTools, builtins)
+}
+
+func mcpToolName(server, tool string) string {
+	return fmt.Sprintf("mcp__%s__%s", server, tool)
+}
+
+// routable reports whether the prefixed name parses back to server and tool.
+func routable(server, tool string) bool {
+	s, t, ok := parseMCPToolName(mcpToolName(server, tool))
+	return ok && s == server && t == tool
+}
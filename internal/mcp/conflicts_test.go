@@ -0,0 +1,60 @@
+package mcp
+
+import "testing"
+
+func TestDetectToolConflicts_DuplicateAcrossServers(t *testing.T) {
+	serverTools := map[string][]string{
+		"github": {"search", "create_issue"},
+		"gitlab": {"search", "create_mr"},
+	}
+
+	conflicts := DetectToolConflicts(serverTools, nil)
+	if len(conflicts) != 1 {
+		t.Fatalf("expected 1 conflict, got %d: %+v", len(conflicts), conflicts)
+	}
+	c := conflicts[0]
+	if c.Tool != "search" || c.Builtin || c.Routing {
+		t.Errorf("unexpected conflict: %+v", c)
+	}
+	if len(c.Servers) != 2 || c.Servers[0] != "github" || c.Servers[1] != "gitlab" {
+		t.Errorf("expected servers [github gitlab], got %v", c.Servers)
+	}
+
+	// Prefixed names must still route to the right server.
+	for _, server := range []string{"github", "gitlab"} {
+		s, tool, ok := parseMCPToolName(mcpToolName(server, "search"))
+		if !ok || s != server || tool != "search" {
+			t.Errorf("routing for %s: got (%q, %q, %v)", server, s, tool, ok)
+		}
+	}
+}
+
+func TestDetectToolConflicts_Builtin(t *testing.T) {
+	conflicts := DetectToolConflicts(map[string][]string{"fs": {"Read", "stat"}}, []string{"Read", "Write"})
+	if len(conflicts) != 1 || conflicts[0].Tool != "Read" || !conflicts[0].Builtin {
+		t.Fatalf("expected builtin conflict on Read, got %+v", conflicts)
+	}
+	if !conflicts[0].Involves("fs") || conflicts[0].Involves("other") {
+		t.Errorf("Involves returned wrong result for %+v", conflicts[0])
+	}
+}
+
+func TestDetectToolConflicts_Routing(t *testing.T) {
+	conflicts := DetectToolConflicts(map[string][]string{"my__server": {"search"}}, nil)
+	if len(conflicts) != 1 || !conflicts[0].Routing {
+		t.Fatalf("expected routing conflict, got %+v", conflicts)
+	}
+	if routable("my__server", "search") {
+		t.Error("expected my__server/search to be unroutable")
+	}
+	if !routable("server", "tool__name") {
+		t.Error("expected server/tool__name to be routable")
+	}
+}
+
+func TestDetectToolConflicts_None(t *testing.T) {
+	conflicts := DetectToolConflicts(map[string][]string{"a": {"x"}, "b": {"y"}}, []string{"Read"})
+	if len(conflicts) != 0 {
+		t.Errorf("expected no conflicts, got %+v", conflicts)
+	}
+}
@@ -0,0 +1,366 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OAuthConfig enables OAuth 2.0 for an HTTP or SSE server. Tokens are
+// obtained with the authorization-code flow and PKCE, cached under
+// ~/.gen/mcp-oauth, and refreshed before they expire.
+type OAuthConfig struct {
+	ClientID string `json:"clientId"`
+	// AuthURL and TokenURL default to the endpoints the server publishes
+	// at /.well-known/oauth-authorization-server.
+	AuthURL  string   `json:"authUrl,omitempty"`
+	TokenURL string   `json:"tokenUrl,omitempty"`
+	Scopes   []string `json:"scopes,omitempty"`
+}
+
+const (
+	// oauthRefreshSkew is how long before expiry a token is refreshed.
+	oauthRefreshSkew = time.Minute
+	// oauthConsentTimeout bounds the wait for the user to approve access.
+	oauthConsentTimeout = 5 * time.Minute
+)
+
+// openBrowser opens url in the user's browser. Replaced in tests.
+var openBrowser = func(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+// oauthToken is the cached token of one server.
+type oauthToken struct {
+	ServerURL    string    `json:"serverUrl"`
+	AccessToken  string    `json:"accessToken"`
+	RefreshToken string    `json:"refreshToken,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// fresh reports whether the access token can be used without refreshing.
+// A token without an expiry is used until the server rejects it.
+func (t *oauthToken) fresh() bool {
+	return t != nil && t.AccessToken != "" && (t.Expiry.IsZero() || time.Until(t.Expiry) > oauthRefreshSkew)
+}
+
+// oauthTokenSource implements transport.TokenSource for one server.
+type oauthTokenSource struct {
+	server    string
+	serverURL string
+	config    OAuthConfig
+	path      string // token cache file
+	client    *http.Client
+
+	mu    sync.Mutex
+	token *oauthToken
+}
+
+// newOAuthTokenSource returns the token source for the named server, with
+// its token cached in ~/.gen/mcp-oauth/<server>.json.
+func newOAuthTokenSource(server, serverURL string, config OAuthConfig) *oauthTokenSource {
+	homeDir, _ := os.UserHomeDir()
+	return &oauthTokenSource{
+		server:    server,
+		serverURL: serverURL,
+		config:    config,
+		path:      filepath.Join(homeDir, ".gen", "mcp-oauth", server+".json"),
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Token returns a valid access token. A cached token is refreshed when it
+// is about to expire; without one, or when refreshing fails, the user is
+// sent to the browser to approve access.
+func (s *oauthTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == nil {
+		s.token = s.load()
+	}
+	if s.token.fresh() {
+		return s.token.AccessToken, nil
+	}
+
+	authURL, tokenURL, err := s.endpoints(ctx)
+	if err != nil {
+		return "", err
+	}
+	var token *oauthToken
+	if s.token != nil && s.token.RefreshToken != "" {
+		token, err = s.refresh(ctx, tokenURL, s.token.RefreshToken)
+	}
+	if token == nil {
+		token, err = s.authorize(ctx, authURL, tokenURL)
+		if err != nil {
+			return "", err
+		}
+	}
+	s.token = token
+	if err := s.save(token); err != nil {
+		return "", fmt.Errorf("failed to cache token: %w", err)
+	}
+	return token.AccessToken, nil
+}
+
+// load reads the cached token. A token issued for another URL is ignored.
+func (s *oauthTokenSource) load() *oauthToken {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil
+	}
+	var token oauthToken
+	if json.Unmarshal(data, &token) != nil || token.ServerURL != s.serverURL {
+		return nil
+	}
+	return &token
+}
+
+func (s *oauthTokenSource) save(token *oauthToken) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// endpoints returns the configured authorization and token URLs, looking
+// up the server's metadata for any that are missing.
+func (s *oauthTokenSource) endpoints(ctx context.Context) (authURL, tokenURL string, err error) {
+	authURL, tokenURL = s.config.AuthURL, s.config.TokenURL
+	if authURL != "" && tokenURL != "" {
+		return authURL, tokenURL, nil
+	}
+
+	u, err := url.Parse(os.ExpandEnv(s.serverURL))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid server URL: %w", err)
+	}
+	metaURL := u.Scheme + "://" + u.Host + "/.well-known/oauth-authorization-server"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metaURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to fetch OAuth metadata: %w", err)
+	}
+	defer resp.Body.Close()
+	var meta struct {
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+	}
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&meta) != nil {
+		return "", "", fmt.Errorf("no OAuth metadata at %s (status %d); set authUrl and tokenUrl", metaURL, resp.StatusCode)
+	}
+	if authURL == "" {
+		authURL = meta.AuthorizationEndpoint
+	}
+	if tokenURL == "" {
+		tokenURL = meta.TokenEndpoint
+	}
+	if authURL == "" || tokenURL == "" {
+		return "", "", fmt.Errorf("OAuth metadata at %s lacks an authorization or token endpoint", metaURL)
+	}
+	return authURL, tokenURL, nil
+}
+
+// authorize runs the authorization-code flow with PKCE: it opens the
+// consent page in the browser, waits for the redirect to a local callback,
+// and exchanges the code for a token.
+func (s *oauthTokenSource) authorize(ctx context.Context, authURL, tokenURL string) (*oauthToken, error) {
+	verifier, err := randomURLString(32)
+	if err != nil {
+		return nil, err
+	}
+	state, err := randomURLString(16)
+	if err != nil {
+		return nil, err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the OAuth callback: %w", err)
+	}
+	redirectURI := fmt.Sprintf("http://%s/callback", ln.Addr())
+
+	type callback struct {
+		code string
+		err  error
+	}
+	done := make(chan callback, 1)
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/callback" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		if q.Get("state") != state {
+			// Not the redirect we are waiting for; keep waiting.
+			http.Error(w, "OAuth state mismatch", http.StatusBadRequest)
+			return
+		}
+		var cb callback
+		switch {
+		case q.Get("error") != "":
+			cb.err = fmt.Errorf("authorization denied: %s %s", q.Get("error"), q.Get("error_description"))
+		case q.Get("code") == "":
+			cb.err = fmt.Errorf("OAuth callback without a code")
+		default:
+			cb.code = q.Get("code")
+		}
+		if cb.err != nil {
+			http.Error(w, cb.err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintf(w, "Signed in to %s. You can close this window and return to gen.", s.server)
+		}
+		select {
+		case done <- cb:
+		default:
+		}
+	})}
+	go func() { _ = srv.Serve(ln) }()
+	defer srv.Close()
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {s.config.ClientID},
+		"redirect_uri":          {redirectURI},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+		"state":                 {state},
+	}
+	if len(s.config.Scopes) > 0 {
+		params.Set("scope", strings.Join(s.config.Scopes, " "))
+	}
+	consentURL := authURL + "?" + params.Encode()
+	if strings.Contains(authURL, "?") {
+		consentURL = authURL + "&" + params.Encode()
+	}
+	if err := openBrowser(consentURL); err != nil {
+		return nil, fmt.Errorf("failed to open a browser; sign in at %s: %w", consentURL, err)
+	}
+
+	var cb callback
+	select {
+	case cb = <-done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(oauthConsentTimeout):
+		return nil, fmt.Errorf("timed out waiting for %s sign-in", s.server)
+	}
+	if cb.err != nil {
+		return nil, cb.err
+	}
+
+	return s.requestToken(ctx, tokenURL, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {cb.code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {s.config.ClientID},
+		"code_verifier": {verifier},
+	})
+}
+
+// refresh exchanges a refresh token for a new access token. The refresh
+// token is kept when the server does not issue a new one.
+func (s *oauthTokenSource) refresh(ctx context.Context, tokenURL, refreshToken string) (*oauthToken, error) {
+	token, err := s.requestToken(ctx, tokenURL, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"client_id":     {s.config.ClientID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+	return token, nil
+}
+
+// requestToken posts form to the token endpoint and parses the token.
+func (s *oauthTokenSource) requestToken(ctx context.Context, tokenURL string, form url.Values) (*oauthToken, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	var result struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+		Error        string `json:"error"`
+		Description  string `json:"error_description"`
+	}
+	_ = json.Unmarshal(body, &result)
+	if resp.StatusCode != http.StatusOK || result.AccessToken == "" {
+		if result.Error != "" {
+			return nil, fmt.Errorf("token request failed: %s %s", result.Error, result.Description)
+		}
+		return nil, fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	token := &oauthToken{
+		ServerURL:    s.serverURL,
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+	}
+	if result.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// randomURLString returns n random bytes encoded for use in a URL.
+func randomURLString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
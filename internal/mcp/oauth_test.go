@@ -0,0 +1,202 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeOAuthServer serves OAuth metadata and a token endpoint. The token
+// endpoint checks the PKCE verifier against the challenge the browser sent.
+type fakeOAuthServer struct {
+	*httptest.Server
+	challenge    string
+	tokenCalls   atomic.Int32
+	lastGrant    string
+	refreshToken string
+}
+
+func newFakeOAuthServer(t *testing.T) *fakeOAuthServer {
+	t.Helper()
+	f := &fakeOAuthServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"authorization_endpoint": f.URL + "/authorize",
+			"token_endpoint":         f.URL + "/token",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		f.tokenCalls.Add(1)
+		r.ParseForm()
+		f.lastGrant = r.Form.Get("grant_type")
+		if r.Form.Get("client_id") != "gen-test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+		switch f.lastGrant {
+		case "authorization_code":
+			sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+			if r.Form.Get("code") != "the-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != f.challenge {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "access-1", "refresh_token": "refresh-1", "expires_in": 3600})
+		case "refresh_token":
+			f.refreshToken = r.Form.Get("refresh_token")
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "access-2", "expires_in": 3600})
+		}
+	})
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+// stubBrowser replaces openBrowser with fn for the test.
+func stubBrowser(t *testing.T, fn func(string) error) {
+	t.Helper()
+	orig := openBrowser
+	openBrowser = fn
+	t.Cleanup(func() { openBrowser = orig })
+}
+
+func TestOAuthTokenSource_AuthorizesWithPKCE(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	f := newFakeOAuthServer(t)
+	stubBrowser(t, func(consent string) error {
+		u, err := url.Parse(consent)
+		if err != nil {
+			return err
+		}
+		q := u.Query()
+		if u.Path != "/authorize" || q.Get("code_challenge_method") != "S256" || q.Get("scope") != "read write" {
+			t.Errorf("consent URL = %s", consent)
+		}
+		f.challenge = q.Get("code_challenge")
+		// Act as the browser: follow the redirect back to gen.
+		go func() {
+			resp, err := http.Get(q.Get("redirect_uri") + "?code=the-code&state=" + url.QueryEscape(q.Get("state")))
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	})
+
+	src := newOAuthTokenSource("docs", f.URL+"/mcp", OAuthConfig{ClientID: "gen-test", Scopes: []string{"read", "write"}})
+	token, err := src.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if token != "access-1" || f.lastGrant != "authorization_code" {
+		t.Fatalf("token = %q after %q grant", token, f.lastGrant)
+	}
+
+	info, err := os.Stat(src.path)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("token cache = %v, %v; want a 0600 file", info, err)
+	}
+
+	// A new source, as after a restart, uses the cached token.
+	stubBrowser(t, func(string) error { return errors.New("should not sign in again") })
+	again := newOAuthTokenSource("docs", f.URL+"/mcp", OAuthConfig{ClientID: "gen-test"})
+	if token, err := again.Token(context.Background()); err != nil || token != "access-1" {
+		t.Fatalf("cached Token() = %q, %v", token, err)
+	}
+	if n := f.tokenCalls.Load(); n != 1 {
+		t.Errorf("token endpoint called %d times, want 1", n)
+	}
+}
+
+func TestOAuthTokenSource_RefreshesBeforeExpiry(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	f := newFakeOAuthServer(t)
+	stubBrowser(t, func(string) error { return errors.New("should refresh, not sign in") })
+
+	src := newOAuthTokenSource("docs", f.URL+"/mcp", OAuthConfig{ClientID: "gen-test", TokenURL: f.URL + "/token", AuthURL: f.URL + "/authorize"})
+	expiring := &oauthToken{ServerURL: f.URL + "/mcp", AccessToken: "old", RefreshToken: "refresh-1", Expiry: time.Now().Add(30 * time.Second)}
+	if err := src.save(expiring); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := src.Token(context.Background())
+	if err != nil || token != "access-2" {
+		t.Fatalf("Token() = %q, %v; want the refreshed token", token, err)
+	}
+	if f.refreshToken != "refresh-1" {
+		t.Errorf("refresh sent %q", f.refreshToken)
+	}
+	if src.load().RefreshToken != "refresh-1" {
+		t.Error("the refresh token should be kept when the server does not rotate it")
+	}
+}
+
+func TestOAuthTokenSource_IgnoresTokenForOtherURL(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	src := newOAuthTokenSource("docs", "https://new.example.com/mcp", OAuthConfig{ClientID: "gen-test"})
+	if err := src.save(&oauthToken{ServerURL: "https://old.example.com/mcp", AccessToken: "stale"}); err != nil {
+		t.Fatal(err)
+	}
+	if src.load() != nil {
+		t.Error("a token cached for another server URL must not be sent")
+	}
+}
+
+func TestClient_ConnectSendsOAuthBearer(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	var unauthorized atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer cached-token" {
+			unauthorized.Add(1)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"id"`) {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		var req struct {
+			ID uint64 `json:"id"`
+		}
+		json.Unmarshal(body, &req)
+		json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  map[string]any{"protocolVersion": ProtocolVersion, "capabilities": map[string]any{}, "serverInfo": map[string]any{"name": "fake"}},
+		})
+	}))
+	defer srv.Close()
+
+	src := newOAuthTokenSource("docs", srv.URL, OAuthConfig{ClientID: "gen-test"})
+	if err := src.save(&oauthToken{ServerURL: srv.URL, AccessToken: "cached-token", Expiry: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".gen", "mcp-oauth", "docs.json")); err != nil {
+		t.Fatalf("token not cached in the MCP config directory: %v", err)
+	}
+
+	client := NewClient(ServerConfig{Name: "docs", Type: TransportHTTP, URL: srv.URL, OAuth: &OAuthConfig{ClientID: "gen-test"}})
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer client.Disconnect()
+	if n := unauthorized.Load(); n != 0 {
+		t.Errorf("%d requests without the bearer token", n)
+	}
+}
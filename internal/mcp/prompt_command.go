@@ -0,0 +1,170 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PromptCommandPrefix starts the slash command name of every MCP prompt,
+// which is namespaced as mcp:<server>:<prompt>.
+const PromptCommandPrefix = "mcp:"
+
+// PromptCommand is an MCP prompt exposed as a slash command.
+type PromptCommand struct {
+	Server string
+	Prompt MCPPrompt
+}
+
+// Name returns the slash command name, without the leading slash.
+func (pc PromptCommand) Name() string {
+	return PromptCommandPrefix + pc.Server + ":" + pc.Prompt.Name
+}
+
+// ArgumentHint describes the prompt's arguments for command suggestions,
+// e.g. "<file> [focus]".
+func (pc PromptCommand) ArgumentHint() string {
+	parts := make([]string, 0, len(pc.Prompt.Arguments))
+	for _, arg := range pc.Prompt.Arguments {
+		if arg.Required {
+			parts = append(parts, "<"+arg.Name+">")
+		} else {
+			parts = append(parts, "["+arg.Name+"]")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// PromptCommands lists the prompts of every connected server that advertises
+// the prompts capability, sorted by command name.
+func (r *Registry) PromptCommands() []PromptCommand {
+	r.mu.RLock()
+	clients := make(map[string]*Client, len(r.clients))
+	for name, client := range r.clients {
+		clients[name] = client
+	}
+	r.mu.RUnlock()
+
+	var cmds []PromptCommand
+	for name, client := range clients {
+		if !client.IsConnected() || client.GetCapabilities().Prompts == nil {
+			continue
+		}
+		for _, prompt := range client.GetCachedPrompts() {
+			cmds = append(cmds, PromptCommand{Server: name, Prompt: prompt})
+		}
+	}
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].Name() < cmds[j].Name() })
+	return cmds
+}
+
+// FindPromptCommand looks up a prompt command by name. Slash command names
+// are lowercased when parsed, so the match ignores case.
+func (r *Registry) FindPromptCommand(name string) (PromptCommand, bool) {
+	if !strings.HasPrefix(strings.ToLower(name), PromptCommandPrefix) {
+		return PromptCommand{}, false
+	}
+	for _, pc := range r.PromptCommands() {
+		if strings.EqualFold(pc.Name(), name) {
+			return pc, true
+		}
+	}
+	return PromptCommand{}, false
+}
+
+// GetPrompt fetches the prompt from its server with the given arguments.
+func (r *Registry) GetPrompt(ctx context.Context, pc PromptCommand, arguments map[string]string) (*PromptResult, error) {
+	client, ok := r.GetClient(pc.Server)
+	if !ok {
+		return nil, fmt.Errorf("server %s is not connected", pc.Server)
+	}
+	return client.GetPrompt(ctx, pc.Prompt.Name, arguments)
+}
+
+// ParsePromptArguments maps slash command arguments onto the prompt's
+// declared arguments with ParsePromptValues. It fails when a required
+// argument is missing.
+func ParsePromptArguments(prompt MCPPrompt, args string) (map[string]string, error) {
+	values, err := ParsePromptValues(prompt, args)
+	if err != nil {
+		return nil, err
+	}
+	if missing := MissingPromptArguments(prompt, values); len(missing) > 0 {
+		return nil, fmt.Errorf("prompt %s requires argument %q", prompt.Name, missing[0].Name)
+	}
+	return values, nil
+}
+
+// ParsePromptValues maps slash command arguments onto the prompt's declared
+// arguments. name=value sets an argument by name; other words fill the
+// remaining arguments in order, and any extra words are appended to the last
+// one so free text can be passed without quoting.
+func ParsePromptValues(prompt MCPPrompt, args string) (map[string]string, error) {
+	declared := make(map[string]bool, len(prompt.Arguments))
+	for _, arg := range prompt.Arguments {
+		declared[arg.Name] = true
+	}
+
+	values := make(map[string]string)
+	var positional []string
+	for _, word := range strings.Fields(args) {
+		if name, value, ok := strings.Cut(word, "="); ok && declared[name] {
+			values[name] = value
+			continue
+		}
+		positional = append(positional, word)
+	}
+
+	var last string
+	for _, arg := range prompt.Arguments {
+		if _, set := values[arg.Name]; set {
+			continue
+		}
+		if len(positional) == 0 {
+			break
+		}
+		values[arg.Name], positional = positional[0], positional[1:]
+		last = arg.Name
+	}
+	if len(positional) > 0 {
+		if last == "" {
+			return nil, fmt.Errorf("prompt %s takes no more arguments: %s", prompt.Name, strings.Join(positional, " "))
+		}
+		values[last] += " " + strings.Join(positional, " ")
+	}
+	return values, nil
+}
+
+// MissingPromptArguments returns the required arguments of prompt that have
+// no value, in declaration order.
+func MissingPromptArguments(prompt MCPPrompt, values map[string]string) []MCPPromptArgument {
+	var missing []MCPPromptArgument
+	for _, arg := range prompt.Arguments {
+		if arg.Required && values[arg.Name] == "" {
+			missing = append(missing, arg)
+		}
+	}
+	return missing
+}
+
+// FormatPromptMessages joins the messages of a prompt into text for the
+// conversation. A lone user message is returned as is; otherwise each
+// message is labelled with its role. Non-text content is noted by type.
+func FormatPromptMessages(result *PromptResult) string {
+	if result == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(result.Messages))
+	for _, msg := range result.Messages {
+		text := msg.Content.Text
+		if msg.Content.Type != "" && msg.Content.Type != "text" {
+			text = fmt.Sprintf("[%s content]", msg.Content.Type)
+		}
+		if len(result.Messages) > 1 || msg.Role != "user" {
+			text = "[" + msg.Role + "]\n" + text
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, "\n\n")
+}
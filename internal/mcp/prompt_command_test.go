@@ -0,0 +1,148 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/mcp/transport"
+)
+
+// promptTransport is a fake server that advertises prompts, or no prompts
+// capability at all when prompts is nil, and echoes prompts/get arguments.
+type promptTransport struct {
+	prompts []MCPPrompt
+}
+
+func (ft *promptTransport) Start(context.Context) error                          { return nil }
+func (ft *promptTransport) Close() error                                         { return nil }
+func (ft *promptTransport) IsAlive() bool                                        { return true }
+func (ft *promptTransport) SetNotificationHandler(transport.NotificationHandler) {}
+func (ft *promptTransport) SendNotification(context.Context, *transport.JSONRPCNotification) error {
+	return nil
+}
+
+func (ft *promptTransport) Send(_ context.Context, req *transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	var result any
+	switch req.Method {
+	case MethodInitialize:
+		caps := ServerCapabilities{}
+		if ft.prompts != nil {
+			caps.Prompts = &PromptsCapability{}
+		}
+		result = InitializeResult{Capabilities: caps}
+	case MethodPromptsList:
+		result = PromptsListResult{Prompts: ft.prompts}
+	case MethodPromptsGet:
+		params := req.Params.(PromptsGetParams)
+		result = PromptResult{Messages: []PromptMessage{{
+			Role:    "user",
+			Content: PromptMessageContent{Type: "text", Text: "Review " + params.Arguments["file"]},
+		}}}
+	default:
+		result = map[string]any{}
+	}
+	data, _ := json.Marshal(result)
+	return &transport.JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: data}, nil
+}
+
+func connectPromptServer(t *testing.T, reg *Registry, name string, ft *promptTransport) {
+	t.Helper()
+	client := NewClient(ServerConfig{Name: name, Command: "fake"})
+	client.TransportFactory = func() (transport.Transport, error) { return ft, nil }
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect(%s) error = %v", name, err)
+	}
+	reg.mu.Lock()
+	reg.clients[name] = client
+	reg.mu.Unlock()
+}
+
+func TestRegistry_PromptCommands(t *testing.T) {
+	reg := NewRegistryForTest(map[string]ServerConfig{})
+	connectPromptServer(t, reg, "git", &promptTransport{prompts: []MCPPrompt{
+		{Name: "Code-Review", Description: "Review a file", Arguments: []MCPPromptArgument{{Name: "file", Required: true}}},
+	}})
+	connectPromptServer(t, reg, "plain", &promptTransport{})
+
+	cmds := reg.PromptCommands()
+	if len(cmds) != 1 || cmds[0].Name() != "mcp:git:Code-Review" || cmds[0].ArgumentHint() != "<file>" {
+		t.Fatalf("PromptCommands() = %+v, want only the git prompt", cmds)
+	}
+
+	pc, ok := reg.FindPromptCommand("mcp:git:code-review")
+	if !ok {
+		t.Fatal("FindPromptCommand should ignore case")
+	}
+	if _, ok := reg.FindPromptCommand("git:code-review"); ok {
+		t.Error("names without the mcp: prefix should not match")
+	}
+
+	result, err := reg.GetPrompt(context.Background(), pc, map[string]string{"file": "main.go"})
+	if err != nil {
+		t.Fatalf("GetPrompt() error = %v", err)
+	}
+	if got := FormatPromptMessages(result); got != "Review main.go" {
+		t.Errorf("FormatPromptMessages() = %q", got)
+	}
+}
+
+func TestParsePromptArguments(t *testing.T) {
+	prompt := MCPPrompt{Name: "review", Arguments: []MCPPromptArgument{
+		{Name: "file", Required: true},
+		{Name: "focus"},
+	}}
+
+	tests := []struct {
+		args    string
+		want    map[string]string
+		wantErr string
+	}{
+		{args: "main.go", want: map[string]string{"file": "main.go"}},
+		{args: "main.go error handling", want: map[string]string{"file": "main.go", "focus": "error handling"}},
+		{args: "focus=tests main.go", want: map[string]string{"file": "main.go", "focus": "tests"}},
+		{args: "", wantErr: `requires argument "file"`},
+	}
+	for _, tt := range tests {
+		got, err := ParsePromptArguments(prompt, tt.args)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%q: error = %v, want %q", tt.args, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || len(got) != len(tt.want) {
+			t.Errorf("%q: got %v, %v; want %v", tt.args, got, err, tt.want)
+			continue
+		}
+		for k, v := range tt.want {
+			if got[k] != v {
+				t.Errorf("%q: %s = %q, want %q", tt.args, k, got[k], v)
+			}
+		}
+	}
+
+	if _, err := ParsePromptArguments(MCPPrompt{Name: "status"}, "extra"); err == nil {
+		t.Error("a prompt without arguments should reject extra words")
+	}
+
+	values, err := ParsePromptValues(prompt, "focus=tests")
+	if err != nil {
+		t.Fatalf("ParsePromptValues() error = %v", err)
+	}
+	if missing := MissingPromptArguments(prompt, values); len(missing) != 1 || missing[0].Name != "file" {
+		t.Errorf("MissingPromptArguments() = %+v, want only file", missing)
+	}
+}
+
+func TestFormatPromptMessages(t *testing.T) {
+	result := &PromptResult{Messages: []PromptMessage{
+		{Role: "user", Content: PromptMessageContent{Type: "text", Text: "Check this"}},
+		{Role: "assistant", Content: PromptMessageContent{Type: "image"}},
+	}}
+	want := "[user]\nCheck this\n\n[assistant]\n[image content]"
+	if got := FormatPromptMessages(result); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -41,6 +41,10 @@ type Registry struct {
 	// the app layer to avoid mcp importing plugin (same-layer dependency).
 	PluginServers func() []PluginServer
 
+	// Sampling answers sampling/createMessage requests from connected
+	// servers. Injected by the app layer; nil disables sampling.
+	Sampling SamplingHandler
+
 	// Callback when tool schemas change
 	onToolsChanged func()
 }
@@ -198,6 +202,7 @@ func (r *Registry) Connect(ctx context.Context, name string) error {
 
 	// Create and connect client
 	client := NewClient(config)
+	client.Sampling = r.Sampling
 	if err := client.Connect(ctx); err != nil {
 		return fmt.Errorf("failed to connect to %s: %w", name, err)
 	}
@@ -314,7 +319,8 @@ var emptySchema = map[string]any{
 	"properties": map[string]any{},
 }
 
-// GetToolSchemas returns core.ToolSchema schemas for all connected MCP servers
+// GetToolSchemas returns core.ToolSchema schemas for all connected MCP servers,
+// plus a generated read_resource tool for each server that exposes resources.
 func (r *Registry) GetToolSchemas() []core.ToolSchema {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -326,12 +332,21 @@ func (r *Registry) GetToolSchemas() []core.ToolSchema {
 		}
 
 		for _, mcpTool := range client.GetCachedTools() {
+			// A name that would parse back to a different server/tool pair
+			// would be routed to the wrong server; ToolConflicts reports it.
+			if !routable(serverName, mcpTool.Name) {
+				continue
+			}
 			tools = append(tools, core.ToolSchema{
-				Name:        fmt.Sprintf("mcp__%s__%s", serverName, mcpTool.Name),
+				Name:        mcpToolName(serverName, mcpTool.Name),
 				Description: mcpTool.Description,
 				Parameters:  parseInputSchema(mcpTool.InputSchema),
 			})
 		}
+
+		if servesReadResource(serverName, client) {
+			tools = append(tools, readResourceSchema(serverName, client))
+		}
 	}
 
 	return tools
@@ -362,6 +377,10 @@ func (r *Registry) CallTool(ctx context.Context, fullName string, arguments map[
 		return nil, fmt.Errorf("MCP server not connected: %s", serverName)
 	}
 
+	if toolName == ReadResourceTool && servesReadResource(serverName, client) {
+		return readResource(ctx, client, arguments)
+	}
+
 	return client.CallTool(ctx, toolName, arguments)
 }
 
@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+// ReadResourceTool is the unprefixed name of the tool generated for each
+// connected server that exposes resources. A server tool with the same name
+// takes precedence.
+const ReadResourceTool = "read_resource"
+
+// maxListedResources caps how many resource URIs are listed in the generated
+// tool's description.
+const maxListedResources = 50
+
+// hasTool reports whether the server itself exposes a tool named name.
+func (c *Client) hasTool(name string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, t := range c.tools {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// servesReadResource reports whether the registry answers ReadResourceTool
+// for this client instead of forwarding it to the server.
+func servesReadResource(serverName string, client *Client) bool {
+	return routable(serverName, ReadResourceTool) &&
+		len(client.GetCachedResources()) > 0 &&
+		!client.hasTool(ReadResourceTool)
+}
+
+// readResourceSchema returns the generated read_resource tool for a server,
+// listing the resources it advertised when it connected.
+func readResourceSchema(serverName string, client *Client) core.ToolSchema {
+	resources := client.GetCachedResources()
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Read a resource from the %s MCP server by URI. Available resources:\n", serverName)
+	for i, res := range resources {
+		if i == maxListedResources {
+			fmt.Fprintf(&sb, "- ... and %d more\n", len(resources)-i)
+			break
+		}
+		sb.WriteString("- " + res.URI)
+		if res.Name != "" && res.Name != res.URI {
+			sb.WriteString(" (" + res.Name + ")")
+		}
+		if res.Description != "" {
+			sb.WriteString(": " + res.Description)
+		}
+		sb.WriteString("\n")
+	}
+
+	return core.ToolSchema{
+		Name:        mcpToolName(serverName, ReadResourceTool),
+		Description: strings.TrimSuffix(sb.String(), "\n"),
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"uri": map[string]any{
+					"type":        "string",
+					"description": "The URI of the resource to read",
+				},
+			},
+			"required": []string{"uri"},
+		},
+	}
+}
+
+// readResource answers a read_resource call by reading the resource from the
+// server and returning its contents as text.
+func readResource(ctx context.Context, client *Client, arguments map[string]any) (*ToolResult, error) {
+	uri, _ := arguments["uri"].(string)
+	if uri == "" {
+		return nil, fmt.Errorf("uri is required")
+	}
+
+	contents, err := client.ReadResource(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ToolResult{
+		Content: []ToolResultContent{{Type: "text", Text: FormatResourceContents(uri, contents)}},
+	}, nil
+}
+
+// FormatResourceContents renders resource contents as text. Text is
+// returned as is; binary content is described by MIME type and size.
+func FormatResourceContents(uri string, contents []ResourceContent) string {
+	if len(contents) == 0 {
+		return fmt.Sprintf("Resource %s is empty", uri)
+	}
+
+	parts := make([]string, 0, len(contents))
+	for _, c := range contents {
+		contentURI := c.URI
+		if contentURI == "" {
+			contentURI = uri
+		}
+		var text string
+		switch {
+		case c.Blob != "":
+			mimeType := c.MimeType
+			if mimeType == "" {
+				mimeType = "application/octet-stream"
+			}
+			text = fmt.Sprintf("[binary resource %s: %s, %d bytes]", contentURI, mimeType, blobSize(c.Blob))
+		case c.Text != "":
+			text = c.Text
+		default:
+			text = fmt.Sprintf("Resource %s is empty", contentURI)
+		}
+		if len(contents) > 1 {
+			text = "--- " + contentURI + " ---\n" + text
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// blobSize returns the decoded size of base64 content.
+func blobSize(blob string) int {
+	if data, err := base64.StdEncoding.DecodeString(blob); err == nil {
+		return len(data)
+	}
+	return base64.StdEncoding.DecodedLen(len(blob))
+}
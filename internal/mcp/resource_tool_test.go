@@ -0,0 +1,178 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/mcp/transport"
+)
+
+// resourceTransport is a fake server that advertises the given tools and
+// resources and answers resources/read from contents.
+type resourceTransport struct {
+	tools     []MCPTool
+	resources []MCPResource
+	contents  map[string][]ResourceContent
+}
+
+func (ft *resourceTransport) Start(context.Context) error                          { return nil }
+func (ft *resourceTransport) Close() error                                         { return nil }
+func (ft *resourceTransport) IsAlive() bool                                        { return true }
+func (ft *resourceTransport) SetNotificationHandler(transport.NotificationHandler) {}
+func (ft *resourceTransport) SendNotification(context.Context, *transport.JSONRPCNotification) error {
+	return nil
+}
+
+func (ft *resourceTransport) Send(_ context.Context, req *transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	var result any
+	switch req.Method {
+	case MethodInitialize:
+		result = InitializeResult{Capabilities: ServerCapabilities{
+			Tools:     &ToolsCapability{},
+			Resources: &ResourcesCapability{},
+		}}
+	case MethodToolsList:
+		result = ToolsListResult{Tools: ft.tools}
+	case MethodResourcesList:
+		result = ResourcesListResult{Resources: ft.resources}
+	case MethodResourcesRead:
+		uri := req.Params.(ResourcesReadParams).URI
+		contents, ok := ft.contents[uri]
+		if !ok {
+			return &transport.JSONRPCResponse{JSONRPC: "2.0", ID: req.ID,
+				Error: &transport.JSONRPCError{Code: -32002, Message: "resource not found"}}, nil
+		}
+		result = ResourcesReadResult{Contents: contents}
+	case MethodToolsCall:
+		result = ToolResult{Content: []ToolResultContent{{Type: "text", Text: "server tool"}}}
+	default:
+		result = map[string]any{}
+	}
+	data, _ := json.Marshal(result)
+	return &transport.JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: data}, nil
+}
+
+func connectFakeServer(t *testing.T, reg *Registry, name string, ft *resourceTransport) {
+	t.Helper()
+	client := NewClient(ServerConfig{Name: name, Command: "fake"})
+	client.TransportFactory = func() (transport.Transport, error) { return ft, nil }
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect(%s) error = %v", name, err)
+	}
+	reg.mu.Lock()
+	reg.clients[name] = client
+	reg.mu.Unlock()
+}
+
+// schemasByName indexes tool schemas by their prefixed name.
+func schemasByName(schemas []core.ToolSchema) map[string]core.ToolSchema {
+	byName := make(map[string]core.ToolSchema, len(schemas))
+	for _, s := range schemas {
+		byName[s.Name] = s
+	}
+	return byName
+}
+
+func TestRegistry_ReadResourceTool(t *testing.T) {
+	reg := NewRegistryForTest(map[string]ServerConfig{})
+	connectFakeServer(t, reg, "docs", &resourceTransport{
+		resources: []MCPResource{
+			{URI: "file:///readme.md", Name: "readme", Description: "Project readme"},
+			{URI: "file:///logo.png", Name: "logo"},
+		},
+		contents: map[string][]ResourceContent{
+			"file:///readme.md": {{URI: "file:///readme.md", MimeType: "text/markdown", Text: "# Hello"}},
+			"file:///logo.png":  {{URI: "file:///logo.png", MimeType: "image/png", Blob: "iVBORw0KGgo="}},
+		},
+	})
+	connectFakeServer(t, reg, "plain", &resourceTransport{tools: []MCPTool{{Name: "echo"}}})
+
+	schemas := schemasByName(reg.GetToolSchemas())
+	if len(schemas) != 2 {
+		t.Fatalf("got %d tool schemas, want echo and docs' read_resource", len(schemas))
+	}
+	if _, ok := schemas["mcp__plain__read_resource"]; ok {
+		t.Error("server without resources should not get read_resource")
+	}
+	readTool, ok := schemas["mcp__docs__read_resource"]
+	if !ok {
+		t.Fatal("expected mcp__docs__read_resource schema")
+	}
+	if !strings.Contains(readTool.Description, "file:///readme.md (readme): Project readme") {
+		t.Errorf("description does not list resources:\n%s", readTool.Description)
+	}
+
+	ctx := context.Background()
+	result, err := reg.CallTool(ctx, "mcp__docs__read_resource", map[string]any{"uri": "file:///readme.md"})
+	if err != nil {
+		t.Fatalf("CallTool(readme) error = %v", err)
+	}
+	if got := ExtractContent(result.Content); got != "# Hello" {
+		t.Errorf("readme content = %q", got)
+	}
+
+	result, err = reg.CallTool(ctx, "mcp__docs__read_resource", map[string]any{"uri": "file:///logo.png"})
+	if err != nil {
+		t.Fatalf("CallTool(logo) error = %v", err)
+	}
+	if got := ExtractContent(result.Content); got != "[binary resource file:///logo.png: image/png, 8 bytes]" {
+		t.Errorf("binary content = %q", got)
+	}
+
+	if _, err := reg.CallTool(ctx, "mcp__docs__read_resource", map[string]any{"uri": "file:///missing"}); err == nil || !strings.Contains(err.Error(), "resource not found") {
+		t.Errorf("missing resource error = %v", err)
+	}
+	if _, err := reg.CallTool(ctx, "mcp__docs__read_resource", map[string]any{}); err == nil {
+		t.Error("expected an error without uri")
+	}
+}
+
+func TestRegistry_ReadResourceToolDefersToServerTool(t *testing.T) {
+	reg := NewRegistryForTest(map[string]ServerConfig{})
+	connectFakeServer(t, reg, "docs", &resourceTransport{
+		tools:     []MCPTool{{Name: ReadResourceTool}},
+		resources: []MCPResource{{URI: "file:///readme.md"}},
+	})
+
+	if schemas := reg.GetToolSchemas(); len(schemas) != 1 {
+		t.Fatalf("expected only the server's own read_resource tool, got %d schemas", len(schemas))
+	}
+	result, err := reg.CallTool(context.Background(), "mcp__docs__read_resource", map[string]any{"uri": "file:///readme.md"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ExtractContent(result.Content); got != "server tool" {
+		t.Errorf("call was not forwarded to the server tool, got %q", got)
+	}
+}
+
+func TestFormatResourceContents(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents []ResourceContent
+		want     string
+	}{
+		{name: "empty", want: "Resource file:///a is empty"},
+		{name: "text", contents: []ResourceContent{{Text: "hi"}}, want: "hi"},
+		{
+			name:     "binary without mime type",
+			contents: []ResourceContent{{Blob: "AAEC"}},
+			want:     "[binary resource file:///a: application/octet-stream, 3 bytes]",
+		},
+		{
+			name:     "multiple",
+			contents: []ResourceContent{{URI: "file:///a/1", Text: "one"}, {URI: "file:///a/2", Text: "two"}},
+			want:     "--- file:///a/1 ---\none\n\n--- file:///a/2 ---\ntwo",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatResourceContents("file:///a", tt.contents); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
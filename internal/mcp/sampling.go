@@ -0,0 +1,170 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/yanmxa/gencode/internal/mcp/transport"
+)
+
+// ErrSamplingDeclined is returned when settings or the user refuse a
+// sampling request.
+var ErrSamplingDeclined = errors.New("sampling request declined")
+
+// samplingDeclinedCode is the error code MCP uses for rejected sampling requests.
+const samplingDeclinedCode = -1
+
+// SamplingHandler performs an LLM completion requested by a server through
+// sampling/createMessage. Injected by the app layer so mcp does not depend
+// on llm.
+type SamplingHandler func(ctx context.Context, server string, params CreateMessageParams) (*CreateMessageResult, error)
+
+// SamplingConsent is the user's answer to a SamplingConsentRequest.
+type SamplingConsent int
+
+const (
+	SamplingDeny         SamplingConsent = iota // decline this request
+	SamplingAllowOnce                           // allow this request only
+	SamplingAllowSession                        // allow this server until the gate is discarded
+)
+
+// SamplingConsentRequest asks the user whether a server may request LLM
+// completions. The receiver answers on Response.
+type SamplingConsentRequest struct {
+	Server   string
+	Params   CreateMessageParams
+	Response chan SamplingConsent
+}
+
+// SamplingGate guards sampling with a settings toggle and a consent prompt
+// until the user allows a server for the session.
+type SamplingGate struct {
+	enabled  func() bool
+	requests chan *SamplingConsentRequest
+
+	mu        sync.Mutex
+	complete  SamplingCompleter
+	prompting bool
+	approved  map[string]bool
+}
+
+// SamplingCompleter runs an approved sampling request against a model.
+type SamplingCompleter func(ctx context.Context, params CreateMessageParams) (*CreateMessageResult, error)
+
+// NewSamplingGate creates a gate. A nil enabled func means sampling is
+// always enabled. Requests fail until SetCompleter is called.
+func NewSamplingGate(enabled func() bool) *SamplingGate {
+	return &SamplingGate{
+		enabled:  enabled,
+		requests: make(chan *SamplingConsentRequest),
+		approved: make(map[string]bool),
+	}
+}
+
+// SetCompleter sets the function that runs approved requests, e.g. after
+// the user switches models.
+func (g *SamplingGate) SetCompleter(complete SamplingCompleter) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.complete = complete
+}
+
+// EnablePrompts marks that a UI answers consent requests through Recv.
+// Until then, requests that need consent are declined.
+func (g *SamplingGate) EnablePrompts() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.prompting = true
+}
+
+// Handle implements SamplingHandler.
+func (g *SamplingGate) Handle(ctx context.Context, server string, params CreateMessageParams) (*CreateMessageResult, error) {
+	if g.enabled != nil && !g.enabled() {
+		return nil, fmt.Errorf("%w: sampling is disabled in settings", ErrSamplingDeclined)
+	}
+	if !g.consent(ctx, server, params) {
+		return nil, fmt.Errorf("%w by user", ErrSamplingDeclined)
+	}
+	g.mu.Lock()
+	complete := g.complete
+	g.mu.Unlock()
+	if complete == nil {
+		return nil, fmt.Errorf("no model is connected")
+	}
+	return complete(ctx, params)
+}
+
+// consent returns true if server was allowed for the session, or asks the
+// user. Headless runs never enable prompts, so nobody can answer and the
+// request is declined.
+func (g *SamplingGate) consent(ctx context.Context, server string, params CreateMessageParams) bool {
+	g.mu.Lock()
+	ok, prompting := g.approved[server], g.prompting
+	g.mu.Unlock()
+	if ok {
+		return true
+	}
+	if !prompting {
+		return false
+	}
+
+	req := &SamplingConsentRequest{Server: server, Params: params, Response: make(chan SamplingConsent, 1)}
+	select {
+	case g.requests <- req:
+	case <-ctx.Done():
+		return false
+	}
+
+	select {
+	case answer := <-req.Response:
+		if answer == SamplingAllowSession {
+			g.mu.Lock()
+			g.approved[server] = true
+			g.mu.Unlock()
+		}
+		return answer != SamplingDeny
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Recv blocks until a server needs the user's consent.
+func (g *SamplingGate) Recv() *SamplingConsentRequest {
+	return <-g.requests
+}
+
+// handleRequest answers requests initiated by the server.
+func (c *Client) handleRequest(ctx context.Context, method string, params json.RawMessage) (any, *transport.JSONRPCError) {
+	switch method {
+	case MethodPing:
+		return struct{}{}, nil
+	case MethodSamplingCreateMessage:
+		if c.Sampling != nil {
+			return c.handleSampling(ctx, params)
+		}
+	}
+	return nil, &transport.JSONRPCError{Code: transport.ErrCodeMethodNotFound, Message: "method not found: " + method}
+}
+
+// handleSampling runs a sampling/createMessage request through c.Sampling.
+func (c *Client) handleSampling(ctx context.Context, raw json.RawMessage) (any, *transport.JSONRPCError) {
+	var params CreateMessageParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, &transport.JSONRPCError{Code: transport.ErrCodeInvalidParams, Message: "invalid sampling params: " + err.Error()}
+	}
+	if len(params.Messages) == 0 {
+		return nil, &transport.JSONRPCError{Code: transport.ErrCodeInvalidParams, Message: "sampling request has no messages"}
+	}
+
+	result, err := c.Sampling(ctx, c.config.Name, params)
+	if errors.Is(err, ErrSamplingDeclined) {
+		return nil, &transport.JSONRPCError{Code: samplingDeclinedCode, Message: err.Error()}
+	}
+	if err != nil {
+		return nil, &transport.JSONRPCError{Code: transport.ErrCodeInternal, Message: err.Error()}
+	}
+	return result, nil
+}
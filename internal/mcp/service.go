@@ -35,6 +35,7 @@ var _ Service = (*service)(nil)
 type Options struct {
 	CWD           string
 	PluginServers func() []PluginServer
+	Sampling      SamplingHandler
 }
 
 // Initialize creates and configures the MCP registry singleton.
@@ -47,6 +48,7 @@ func Initialize(opts Options) error {
 		reg.PluginServers = opts.PluginServers
 		reg.configs = reg.mergePluginMCPConfigs(reg.configs)
 	}
+	reg.Sampling = opts.Sampling
 	SetDefault(&service{reg: reg})
 	return nil
 }
@@ -0,0 +1,27 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// TokenSource supplies the OAuth bearer token sent with each HTTP request.
+// Token may refresh the token, or ask the user to sign in, before returning.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// setBearer sets the Authorization header from ts. A nil ts leaves the
+// request unchanged. It runs after the configured headers so the token wins.
+func setBearer(ctx context.Context, req *http.Request, ts TokenSource) error {
+	if ts == nil {
+		return nil
+	}
+	token, err := ts.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("OAuth: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
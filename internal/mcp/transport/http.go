@@ -18,6 +18,7 @@ import (
 type HTTPConfig struct {
 	URL     string
 	Headers map[string]string
+	Auth    TokenSource // optional OAuth bearer token
 }
 
 // HTTPTransport implements Transport for HTTP-based MCP servers.
@@ -29,6 +30,7 @@ type HTTPTransport struct {
 	mu            sync.Mutex
 	alive         bool
 	notifyHandler NotificationHandler
+	reqHandler    RequestHandler
 	sessionID     string
 }
 
@@ -72,6 +74,9 @@ func (t *HTTPTransport) newJSONRequest(ctx context.Context, body []byte) (*http.
 	for k, v := range t.config.Headers {
 		req.Header.Set(k, v)
 	}
+	if err := setBearer(ctx, req, t.config.Auth); err != nil {
+		return nil, err
+	}
 	// Include session ID for MCP Streamable HTTP session tracking
 	t.mu.Lock()
 	if t.sessionID != "" {
@@ -185,6 +190,15 @@ func (t *HTTPTransport) parseSSEResponse(r io.Reader, requestID uint64) (*JSONRP
 		if line == "" {
 			// Empty line = end of event, process accumulated data
 			if data != "" {
+				t.mu.Lock()
+				handler := t.notifyHandler
+				reqHandler := t.reqHandler
+				t.mu.Unlock()
+				// Requests from the server are answered with a separate POST.
+				if dispatchServerRequest([]byte(data), reqHandler, t.postResponse) {
+					data = ""
+					continue
+				}
 				var resp JSONRPCResponse
 				if err := json.Unmarshal([]byte(data), &resp); err == nil {
 					if resp.ID == requestID {
@@ -192,9 +206,6 @@ func (t *HTTPTransport) parseSSEResponse(r io.Reader, requestID uint64) (*JSONRP
 					}
 				}
 				// Dispatch notifications
-				t.mu.Lock()
-				handler := t.notifyHandler
-				t.mu.Unlock()
 				if handler != nil {
 					parseAndDispatchNotification([]byte(data), handler)
 				}
@@ -274,3 +285,24 @@ func (t *HTTPTransport) SetNotificationHandler(handler NotificationHandler) {
 	defer t.mu.Unlock()
 	t.notifyHandler = handler
 }
+
+// SetRequestHandler stores a handler for server requests received in SSE streams.
+func (t *HTTPTransport) SetRequestHandler(handler RequestHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reqHandler = handler
+}
+
+// postResponse sends the client's answer to a server request.
+func (t *HTTPTransport) postResponse(data []byte) error {
+	ctx := context.Background()
+	resp, err := t.doRequest(ctx, func() (*http.Request, error) {
+		return t.newJSONRequest(ctx, data)
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -84,3 +84,78 @@ func parseAndDispatchNotification(data []byte, handler NotificationHandler) bool
 	handler(notif.Method, notif.Params)
 	return true
 }
+
+// Standard JSON-RPC error codes used when answering server requests.
+const (
+	ErrCodeInvalidParams  = -32602
+	ErrCodeMethodNotFound = -32601
+	ErrCodeInternal       = -32603
+)
+
+// RequestHandler answers a request initiated by the MCP server, such as
+// sampling/createMessage. It returns either a result or a JSON-RPC error.
+type RequestHandler func(ctx context.Context, method string, params json.RawMessage) (any, *JSONRPCError)
+
+// RequestHandlerSetter is implemented by transports that can receive
+// server-initiated requests.
+type RequestHandlerSetter interface {
+	SetRequestHandler(handler RequestHandler)
+}
+
+// serverRequest is a JSON-RPC request sent from the server to the client.
+// The ID is kept raw because servers may use string IDs.
+type serverRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// serverResponse answers a serverRequest.
+type serverResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *JSONRPCError   `json:"error,omitempty"`
+}
+
+// parseServerRequest reports whether data is a request from the server,
+// i.e. a message carrying both an id and a method.
+func parseServerRequest(data []byte) (*serverRequest, bool) {
+	var req serverRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, false
+	}
+	if req.Method == "" || len(req.ID) == 0 || string(req.ID) == "null" {
+		return nil, false
+	}
+	return &req, true
+}
+
+// dispatchServerRequest answers a server request in the background and
+// writes the response with write. Returns false if data is not a request.
+func dispatchServerRequest(data []byte, handler RequestHandler, write func([]byte) error) bool {
+	req, ok := parseServerRequest(data)
+	if !ok {
+		return false
+	}
+	go func() {
+		resp := serverResponse{JSONRPC: "2.0", ID: req.ID}
+		if handler == nil {
+			resp.Error = &JSONRPCError{Code: ErrCodeMethodNotFound, Message: "method not found: " + req.Method}
+		} else {
+			result, rpcErr := handler(context.Background(), req.Method, req.Params)
+			if rpcErr != nil {
+				resp.Error = rpcErr
+			} else {
+				if result == nil {
+					result = struct{}{}
+				}
+				resp.Result = result
+			}
+		}
+		if out, err := json.Marshal(resp); err == nil {
+			_ = write(out)
+		}
+	}()
+	return true
+}
@@ -17,6 +17,7 @@ import (
 type SSEConfig struct {
 	URL     string
 	Headers map[string]string
+	Auth    TokenSource // optional OAuth bearer token
 }
 
 // SSETransport implements Transport for SSE-based MCP servers.
@@ -30,6 +31,7 @@ type SSETransport struct {
 	pending       map[uint64]chan *JSONRPCResponse
 	alive         bool
 	notifyHandler NotificationHandler
+	reqHandler    RequestHandler
 	cancel        context.CancelFunc
 	readLoopDone  chan struct{}
 }
@@ -79,6 +81,10 @@ func (t *SSETransport) Start(ctx context.Context) error {
 	for k, v := range t.config.Headers {
 		req.Header.Set(k, v)
 	}
+	if err := setBearer(ctx, req, t.config.Auth); err != nil {
+		cancel()
+		return err
+	}
 
 	resp, err := t.client.Do(req)
 	if err != nil {
@@ -156,11 +162,19 @@ func (t *SSETransport) handleSSEEvent(_, data string) {
 		return
 	}
 
-	// Snapshot the handler under lock to avoid racing with SetNotificationHandler.
+	// Snapshot the handlers under lock to avoid racing with their setters.
 	t.mu.Lock()
 	handler := t.notifyHandler
+	reqHandler := t.reqHandler
 	t.mu.Unlock()
 
+	// Requests from the server are answered on the message endpoint.
+	if dispatchServerRequest([]byte(data), reqHandler, func(out []byte) error {
+		return t.postMessage(context.Background(), out)
+	}) {
+		return
+	}
+
 	// Try to parse as JSON-RPC response
 	var resp JSONRPCResponse
 	if err := json.Unmarshal([]byte(data), &resp); err != nil {
@@ -201,6 +215,9 @@ func (t *SSETransport) postMessage(ctx context.Context, data []byte) error {
 	for k, v := range t.config.Headers {
 		httpReq.Header.Set(k, v)
 	}
+	if err := setBearer(ctx, httpReq, t.config.Auth); err != nil {
+		return err
+	}
 
 	resp, err := t.client.Do(httpReq)
 	if err != nil {
@@ -304,6 +321,13 @@ func (t *SSETransport) SetNotificationHandler(handler NotificationHandler) {
 	t.notifyHandler = handler
 }
 
+// SetRequestHandler sets the handler for requests initiated by the server
+func (t *SSETransport) SetRequestHandler(handler RequestHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reqHandler = handler
+}
+
 // appendURLPath appends a path segment to a base URL, handling trailing slashes.
 func appendURLPath(base, segment string) string {
 	if strings.HasSuffix(base, "/"+segment) {
@@ -33,6 +33,7 @@ type STDIOTransport struct {
 	pending       map[uint64]chan *JSONRPCResponse
 	alive         bool
 	notifyHandler NotificationHandler
+	reqHandler    RequestHandler
 	readLoopDone  chan struct{}
 }
 
@@ -114,11 +115,17 @@ func (t *STDIOTransport) readLoop() {
 			continue
 		}
 
-		// Snapshot the handler under lock to avoid racing with SetNotificationHandler.
+		// Snapshot the handlers under lock to avoid racing with their setters.
 		t.mu.Lock()
 		handler := t.notifyHandler
+		reqHandler := t.reqHandler
 		t.mu.Unlock()
 
+		// Requests from the server carry both an id and a method.
+		if dispatchServerRequest([]byte(line), reqHandler, t.writeLine) {
+			continue
+		}
+
 		// Try to parse as response
 		var resp JSONRPCResponse
 		if err := json.Unmarshal([]byte(line), &resp); err != nil {
@@ -163,9 +170,13 @@ func (t *STDIOTransport) writeJSON(v any) error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal: %w", err)
 	}
+	return t.writeLine(data)
+}
 
+// writeLine writes an already-encoded JSON message to stdin with a newline
+func (t *STDIOTransport) writeLine(data []byte) error {
 	t.mu.Lock()
-	_, err = t.stdin.Write(append(data, '\n'))
+	_, err := t.stdin.Write(append(data, '\n'))
 	t.mu.Unlock()
 
 	if err != nil {
@@ -275,3 +286,10 @@ func (t *STDIOTransport) SetNotificationHandler(handler NotificationHandler) {
 	defer t.mu.Unlock()
 	t.notifyHandler = handler
 }
+
+// SetRequestHandler sets the handler for requests initiated by the server
+func (t *STDIOTransport) SetRequestHandler(handler RequestHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reqHandler = handler
+}
@@ -0,0 +1,438 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket reconnection limits. A dropped connection is redialed with
+// exponential backoff before the transport gives up and reports not alive.
+const (
+	wsMaxReconnects    = 5
+	wsInitialBackoff   = 500 * time.Millisecond
+	wsMaxBackoff       = 8 * time.Second
+	wsHandshakeTimeout = 10 * time.Second
+)
+
+// Handshake methods recorded so they can be replayed after a reconnect.
+const (
+	wsMethodInitialize  = "initialize"
+	wsMethodInitialized = "notifications/initialized"
+)
+
+// WebSocketConfig contains configuration for WebSocket transport
+type WebSocketConfig struct {
+	URL     string
+	Headers map[string]string
+}
+
+// WebSocketTransport implements Transport for MCP servers reachable over a
+// WebSocket. Each text frame carries one JSON-RPC message.
+//
+// A new connection is a new MCP session, so after a dropped connection is
+// redialed the transport replays the initialize request and initialized
+// notification it saw on the first connection. Requests in flight when the
+// connection dropped fail with "connection closed".
+type WebSocketTransport struct {
+	config  WebSocketConfig
+	dialer  *websocket.Dialer
+	wsURL   string
+	headers http.Header
+
+	// writeMu serializes frame writes; gorilla allows one concurrent writer.
+	writeMu sync.Mutex
+
+	mu            sync.Mutex
+	conn          *websocket.Conn
+	pending       map[uint64]chan *JSONRPCResponse
+	alive         bool
+	closed        bool
+	notifyHandler NotificationHandler
+	reqHandler    RequestHandler
+	initReq       []byte
+	initNotif     []byte
+	readLoopDone  chan struct{}
+
+	// backoff returns the delay before reconnect attempt n (starting at 1).
+	// Overridable in tests.
+	backoff func(n int) time.Duration
+}
+
+// NewWebSocketTransport creates a new WebSocket transport
+func NewWebSocketTransport(config WebSocketConfig) *WebSocketTransport {
+	return &WebSocketTransport{
+		config: config,
+		dialer: &websocket.Dialer{
+			Proxy:            http.ProxyFromEnvironment,
+			HandshakeTimeout: wsHandshakeTimeout,
+		},
+		pending:      make(map[uint64]chan *JSONRPCResponse),
+		readLoopDone: make(chan struct{}),
+		backoff:      wsBackoff,
+	}
+}
+
+// wsBackoff doubles the delay for each attempt, capped at wsMaxBackoff.
+func wsBackoff(n int) time.Duration {
+	d := wsInitialBackoff
+	for i := 1; i < n && d < wsMaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, wsMaxBackoff)
+}
+
+// Start dials the WebSocket server
+func (t *WebSocketTransport) Start(ctx context.Context) error {
+	t.mu.Lock()
+	if t.alive {
+		t.mu.Unlock()
+		return fmt.Errorf("WebSocket transport already started")
+	}
+	t.mu.Unlock()
+
+	// Expand environment variables in config
+	t.wsURL = expandEnv(t.config.URL)
+	t.config.Headers = expandEnvMap(t.config.Headers)
+
+	// Validate URL
+	if t.wsURL == "" {
+		return fmt.Errorf("URL is required for WebSocket transport")
+	}
+	u, err := url.Parse(t.wsURL)
+	if err != nil || (u.Scheme != "ws" && u.Scheme != "wss") || u.Host == "" {
+		return fmt.Errorf("WebSocket URL must start with ws:// or wss://: %s", t.wsURL)
+	}
+
+	t.headers = make(http.Header, len(t.config.Headers))
+	for k, v := range t.config.Headers {
+		t.headers.Set(k, v)
+	}
+
+	conn, err := t.dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.conn = conn
+	t.alive = true
+	t.mu.Unlock()
+
+	go t.readLoop(conn)
+
+	return nil
+}
+
+// dial opens a new connection to the server
+func (t *WebSocketTransport) dial(ctx context.Context) (*websocket.Conn, error) {
+	conn, resp, err := t.dialer.DialContext(ctx, t.wsURL, t.headers)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("WebSocket connection failed with status %d: %w", resp.StatusCode, err)
+		}
+		return nil, fmt.Errorf("failed to connect to WebSocket endpoint: %w", err)
+	}
+	return conn, nil
+}
+
+// readLoop reads frames until the connection drops, then reconnects and
+// continues on the new connection. It exits when the transport is closed or
+// reconnecting fails.
+func (t *WebSocketTransport) readLoop(conn *websocket.Conn) {
+	defer close(t.readLoopDone)
+
+	for {
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				break
+			}
+			t.handleMessage(data)
+		}
+		_ = conn.Close()
+
+		t.mu.Lock()
+		t.failPendingLocked()
+		closed := t.closed
+		t.mu.Unlock()
+		if closed {
+			return
+		}
+
+		conn = t.reconnect()
+		if conn == nil {
+			t.mu.Lock()
+			t.alive = false
+			t.conn = nil
+			t.mu.Unlock()
+			return
+		}
+	}
+}
+
+// failPendingLocked wakes every waiting Send with a closed channel.
+func (t *WebSocketTransport) failPendingLocked() {
+	for id, ch := range t.pending {
+		close(ch)
+		delete(t.pending, id)
+	}
+}
+
+// reconnect redials with backoff and replays the MCP handshake. It returns
+// nil if the transport was closed or every attempt failed.
+func (t *WebSocketTransport) reconnect() *websocket.Conn {
+	for attempt := 1; attempt <= wsMaxReconnects; attempt++ {
+		time.Sleep(t.backoff(attempt))
+
+		t.mu.Lock()
+		closed := t.closed
+		t.mu.Unlock()
+		if closed {
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), wsHandshakeTimeout)
+		conn, err := t.dial(ctx)
+		if err == nil {
+			err = t.replayHandshake(ctx, conn)
+			if err != nil {
+				_ = conn.Close()
+			}
+		}
+		cancel()
+		if err != nil {
+			continue
+		}
+
+		t.mu.Lock()
+		if t.closed {
+			t.mu.Unlock()
+			_ = conn.Close()
+			return nil
+		}
+		t.conn = conn
+		t.mu.Unlock()
+		return conn
+	}
+	return nil
+}
+
+// replayHandshake re-sends the initialize request on a fresh connection and
+// waits for its response before sending the initialized notification.
+func (t *WebSocketTransport) replayHandshake(ctx context.Context, conn *websocket.Conn) error {
+	t.mu.Lock()
+	initReq, initNotif := t.initReq, t.initNotif
+	t.mu.Unlock()
+	if initReq == nil {
+		return nil
+	}
+
+	var req JSONRPCRequest
+	if err := json.Unmarshal(initReq, &req); err != nil {
+		return err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetReadDeadline(deadline)
+		defer conn.SetReadDeadline(time.Time{})
+	}
+	if err := t.writeConn(conn, initReq); err != nil {
+		return err
+	}
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		var resp JSONRPCResponse
+		if err := json.Unmarshal(data, &resp); err == nil && resp.ID == req.ID && (resp.Result != nil || resp.Error != nil) {
+			if resp.Error != nil {
+				return fmt.Errorf("initialize failed: %s", resp.Error.Message)
+			}
+			break
+		}
+	}
+	if initNotif != nil {
+		return t.writeConn(conn, initNotif)
+	}
+	return nil
+}
+
+// handleMessage dispatches one JSON-RPC message from the server
+func (t *WebSocketTransport) handleMessage(data []byte) {
+	// Snapshot the handlers under lock to avoid racing with their setters.
+	t.mu.Lock()
+	handler := t.notifyHandler
+	reqHandler := t.reqHandler
+	t.mu.Unlock()
+
+	// Requests from the server are answered on the same socket.
+	if dispatchServerRequest(data, reqHandler, t.write) {
+		return
+	}
+
+	var resp JSONRPCResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		parseAndDispatchNotification(data, handler)
+		return
+	}
+
+	// Check if this is a response (has ID) or notification
+	if resp.ID == 0 && resp.Result == nil && resp.Error == nil {
+		parseAndDispatchNotification(data, handler)
+		return
+	}
+
+	t.mu.Lock()
+	ch, ok := t.pending[resp.ID]
+	if ok {
+		delete(t.pending, resp.ID)
+	}
+	t.mu.Unlock()
+
+	if ok {
+		ch <- &resp
+	}
+}
+
+// write sends one frame on the current connection
+func (t *WebSocketTransport) write(data []byte) error {
+	t.mu.Lock()
+	conn := t.conn
+	t.mu.Unlock()
+	if conn == nil {
+		return fmt.Errorf("transport is not connected")
+	}
+	return t.writeConn(conn, data)
+}
+
+func (t *WebSocketTransport) writeConn(conn *websocket.Conn, data []byte) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		return fmt.Errorf("WebSocket write failed: %w", err)
+	}
+	return nil
+}
+
+// Send sends a request and waits for response
+func (t *WebSocketTransport) Send(ctx context.Context, req *JSONRPCRequest) (*JSONRPCResponse, error) {
+	if !t.IsAlive() {
+		return nil, fmt.Errorf("transport is not connected")
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	respCh := make(chan *JSONRPCResponse, 1)
+
+	t.mu.Lock()
+	t.pending[req.ID] = respCh
+	if req.Method == wsMethodInitialize {
+		t.initReq = data
+	}
+	t.mu.Unlock()
+
+	defer func() {
+		t.mu.Lock()
+		delete(t.pending, req.ID)
+		t.mu.Unlock()
+	}()
+
+	if err := t.write(data); err != nil {
+		return nil, err
+	}
+
+	timeout := 60 * time.Second
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+
+	select {
+	case result := <-respCh:
+		if result == nil {
+			return nil, fmt.Errorf("connection closed")
+		}
+		return result, nil
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("request timeout")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// SendNotification sends a notification (no response expected)
+func (t *WebSocketTransport) SendNotification(ctx context.Context, notif *JSONRPCNotification) error {
+	if !t.IsAlive() {
+		return fmt.Errorf("transport is not connected")
+	}
+
+	data, err := json.Marshal(notif)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	if notif.Method == wsMethodInitialized {
+		t.mu.Lock()
+		t.initNotif = data
+		t.mu.Unlock()
+	}
+
+	return t.write(data)
+}
+
+// Close closes the transport
+func (t *WebSocketTransport) Close() error {
+	t.mu.Lock()
+	t.alive = false
+	t.closed = true
+	conn := t.conn
+	t.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+
+	// WriteControl may run concurrently with WriteMessage.
+	_ = conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(time.Second))
+	_ = conn.Close()
+
+	select {
+	case <-t.readLoopDone:
+	case <-time.After(2 * time.Second):
+	}
+
+	return nil
+}
+
+// IsAlive returns true if the transport is connected or reconnecting
+func (t *WebSocketTransport) IsAlive() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.alive
+}
+
+// SetNotificationHandler sets the handler for incoming notifications
+func (t *WebSocketTransport) SetNotificationHandler(handler NotificationHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.notifyHandler = handler
+}
+
+// SetRequestHandler sets the handler for requests initiated by the server
+func (t *WebSocketTransport) SetRequestHandler(handler RequestHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reqHandler = handler
+}
@@ -0,0 +1,198 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeWSServer is a minimal MCP server over WebSocket. It answers
+// initialize and echo requests, and drops the connection on "drop".
+type fakeWSServer struct {
+	*httptest.Server
+	initializes atomic.Int32
+	initialized atomic.Int32
+	authHeader  atomic.Value
+}
+
+func newFakeWSServer(t *testing.T) *fakeWSServer {
+	t.Helper()
+	s := &fakeWSServer{}
+	upgrader := websocket.Upgrader{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.authHeader.Store(r.Header.Get("Authorization"))
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var msg struct {
+				ID     uint64          `json:"id"`
+				Method string          `json:"method"`
+				Params json.RawMessage `json:"params"`
+			}
+			if err := json.Unmarshal(data, &msg); err != nil {
+				return
+			}
+			var result any
+			switch msg.Method {
+			case "initialize":
+				s.initializes.Add(1)
+				result = map[string]any{"protocolVersion": "2024-11-05"}
+			case "notifications/initialized":
+				s.initialized.Add(1)
+				continue
+			case "drop":
+				return
+			case "notify":
+				_ = conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "method": "notifications/tools/list_changed"})
+				result = map[string]any{}
+			default:
+				result = map[string]any{"echo": msg.Method}
+			}
+			_ = conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "id": msg.ID, "result": result})
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeWSServer) wsURL() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+func startWSTransport(t *testing.T, url string, headers map[string]string) *WebSocketTransport {
+	t.Helper()
+	tr := NewWebSocketTransport(WebSocketConfig{URL: url, Headers: headers})
+	tr.backoff = func(int) time.Duration { return 10 * time.Millisecond }
+	if err := tr.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = tr.Close() })
+	return tr
+}
+
+func TestWebSocketTransport_SendAndNotify(t *testing.T) {
+	srv := newFakeWSServer(t)
+	tr := startWSTransport(t, srv.wsURL(), map[string]string{"Authorization": "Bearer abc"})
+
+	if got := srv.authHeader.Load(); got != "Bearer abc" {
+		t.Errorf("Authorization header = %v, want %q", got, "Bearer abc")
+	}
+
+	var mu sync.Mutex
+	var methods []string
+	tr.SetNotificationHandler(func(method string, _ []byte) {
+		mu.Lock()
+		methods = append(methods, method)
+		mu.Unlock()
+	})
+
+	ctx := context.Background()
+	resp, err := tr.Send(ctx, &JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if resp.ID != 1 || !strings.Contains(string(resp.Result), "tools/list") {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	if _, err := tr.Send(ctx, &JSONRPCRequest{JSONRPC: "2.0", ID: 2, Method: "notify"}); err != nil {
+		t.Fatalf("Send(notify) error = %v", err)
+	}
+	mu.Lock()
+	got := strings.Join(methods, ",")
+	mu.Unlock()
+	if got != "notifications/tools/list_changed" {
+		t.Errorf("notifications = %q", got)
+	}
+
+	if err := tr.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if tr.IsAlive() {
+		t.Error("expected transport to be closed")
+	}
+	if _, err := tr.Send(ctx, &JSONRPCRequest{JSONRPC: "2.0", ID: 3, Method: "x"}); err == nil {
+		t.Error("expected Send after Close to fail")
+	}
+}
+
+func TestWebSocketTransport_ReconnectReplaysHandshake(t *testing.T) {
+	srv := newFakeWSServer(t)
+	tr := startWSTransport(t, srv.wsURL(), nil)
+	ctx := context.Background()
+
+	if _, err := tr.Send(ctx, &JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: "initialize"}); err != nil {
+		t.Fatalf("initialize error = %v", err)
+	}
+	if err := tr.SendNotification(ctx, &JSONRPCNotification{JSONRPC: "2.0", Method: "notifications/initialized"}); err != nil {
+		t.Fatalf("initialized error = %v", err)
+	}
+
+	// The server drops the socket without answering; the request fails.
+	if _, err := tr.Send(ctx, &JSONRPCRequest{JSONRPC: "2.0", ID: 2, Method: "drop"}); err == nil || !strings.Contains(err.Error(), "connection closed") {
+		t.Fatalf("Send(drop) error = %v, want connection closed", err)
+	}
+
+	var resp *JSONRPCResponse
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		var err error
+		if resp, err = tr.Send(ctx, &JSONRPCRequest{JSONRPC: "2.0", ID: 3, Method: "ping"}); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if resp == nil || resp.ID != 3 {
+		t.Fatalf("expected a response after reconnecting, got %+v", resp)
+	}
+	if !tr.IsAlive() {
+		t.Error("expected transport to be alive after reconnecting")
+	}
+	if n := srv.initializes.Load(); n != 2 {
+		t.Errorf("initialize sent %d times, want 2 (original + replay)", n)
+	}
+	if n := srv.initialized.Load(); n != 2 {
+		t.Errorf("initialized sent %d times, want 2 (original + replay)", n)
+	}
+}
+
+func TestWebSocketTransport_GivesUpWhenServerGone(t *testing.T) {
+	srv := newFakeWSServer(t)
+	tr := startWSTransport(t, srv.wsURL(), nil)
+
+	// Stop accepting connections, then have the server drop the open one;
+	// hijacked WebSocket connections outlive Close.
+	srv.Close()
+	_, _ = tr.Send(context.Background(), &JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: "drop"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for tr.IsAlive() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if tr.IsAlive() {
+		t.Fatal("expected transport to stop after reconnect attempts fail")
+	}
+}
+
+func TestWebSocketTransport_RejectsNonWebSocketURL(t *testing.T) {
+	tr := NewWebSocketTransport(WebSocketConfig{URL: "https://example.com/mcp"})
+	err := tr.Start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "ws://") {
+		t.Fatalf("Start() error = %v, want ws:// scheme error", err)
+	}
+}
@@ -1,16 +1,20 @@
 // Package mcp implements Model Context Protocol (MCP) client functionality.
-// It provides support for connecting to MCP servers via STDIO, HTTP, and SSE transports.
+// It provides support for connecting to MCP servers via STDIO, HTTP, SSE, and WebSocket transports.
 package mcp
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"strings"
+)
 
 // TransportType defines the type of MCP transport
 type TransportType string
 
 const (
-	TransportSTDIO TransportType = "stdio"
-	TransportHTTP  TransportType = "http"
-	TransportSSE   TransportType = "sse"
+	TransportSTDIO     TransportType = "stdio"
+	TransportHTTP      TransportType = "http"
+	TransportSSE       TransportType = "sse"
+	TransportWebSocket TransportType = "ws"
 )
 
 // Scope defines where the MCP configuration is stored
@@ -27,7 +31,7 @@ type ServerConfig struct {
 	// Name is the unique identifier for this server
 	Name string `json:"name,omitempty"`
 
-	// Type is the transport type (stdio, http, sse). Default is stdio.
+	// Type is the transport type (stdio, http, sse, ws). Default is stdio.
 	Type TransportType `json:"type,omitempty"`
 
 	// STDIO transport fields
@@ -35,17 +39,25 @@ type ServerConfig struct {
 	Args    []string          `json:"args,omitempty"`
 	Env     map[string]string `json:"env,omitempty"`
 
-	// HTTP/SSE transport fields
+	// HTTP/SSE/WebSocket transport fields
 	URL     string            `json:"url,omitempty"`
 	Headers map[string]string `json:"headers,omitempty"`
 
+	// OAuth enables OAuth 2.0 sign-in (HTTP/SSE only)
+	OAuth *OAuthConfig `json:"oauth,omitempty"`
+
 	// Scope indicates where this config was loaded from
 	Scope Scope `json:"-"`
 }
 
-// GetType returns the transport type, defaulting to stdio if not set
+// GetType returns the transport type, defaulting to stdio if not set.
+// Without an explicit type, a ws:// or wss:// URL selects WebSocket and any
+// other URL selects HTTP.
 func (c *ServerConfig) GetType() TransportType {
 	if c.Type == "" {
+		if isWebSocketURL(c.URL) {
+			return TransportWebSocket
+		}
 		if c.URL != "" {
 			return TransportHTTP
 		}
@@ -54,6 +66,12 @@ func (c *ServerConfig) GetType() TransportType {
 	return c.Type
 }
 
+// isWebSocketURL reports whether u uses the ws or wss scheme.
+func isWebSocketURL(u string) bool {
+	u = strings.ToLower(u)
+	return strings.HasPrefix(u, "ws://") || strings.HasPrefix(u, "wss://")
+}
+
 // MCPConfig represents the mcp.json configuration file format
 type MCPConfig struct {
 	MCPServers map[string]ServerConfig `json:"mcpServers"`
@@ -199,6 +217,12 @@ const (
 	MethodPromptsGet       = "prompts/get"
 	MethodPing             = "ping"
 	MethodToolsListChanged = "notifications/tools/list_changed"
+
+	// MethodPromptsListChanged is sent when the server's prompts change
+	MethodPromptsListChanged = "notifications/prompts/list_changed"
+
+	// MethodSamplingCreateMessage is sent by servers to request an LLM completion
+	MethodSamplingCreateMessage = "sampling/createMessage"
 )
 
 // InitializeParams represents parameters for the initialize request
@@ -210,9 +234,12 @@ type InitializeParams struct {
 
 // ClientCapabilities represents the capabilities of the MCP client
 type ClientCapabilities struct {
-	// Currently empty, but can be extended
+	Sampling *SamplingCapability `json:"sampling,omitempty"`
 }
 
+// SamplingCapability advertises that the client handles sampling/createMessage
+type SamplingCapability struct{}
+
 // ClientInfo represents information about the MCP client
 type ClientInfo struct {
 	Name    string `json:"name"`
@@ -261,3 +288,49 @@ type PromptsGetParams struct {
 
 // PromptsGetResult is an alias for PromptResult (same structure)
 type PromptsGetResult = PromptResult
+
+// CreateMessageParams represents parameters for sampling/createMessage
+type CreateMessageParams struct {
+	Messages         []SamplingMessage `json:"messages"`
+	ModelPreferences *ModelPreferences `json:"modelPreferences,omitempty"`
+	SystemPrompt     string            `json:"systemPrompt,omitempty"`
+	IncludeContext   string            `json:"includeContext,omitempty"`
+	Temperature      *float64          `json:"temperature,omitempty"`
+	MaxTokens        int               `json:"maxTokens"`
+	StopSequences    []string          `json:"stopSequences,omitempty"`
+}
+
+// SamplingMessage represents a message in a sampling request or result
+type SamplingMessage struct {
+	Role    string          `json:"role"` // "user" or "assistant"
+	Content SamplingContent `json:"content"`
+}
+
+// SamplingContent represents the content of a sampling message
+type SamplingContent struct {
+	Type     string `json:"type"` // "text" or "image"
+	Text     string `json:"text,omitempty"`
+	Data     string `json:"data,omitempty"` // base64 encoded image
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+// ModelPreferences carries the server's hints for model selection
+type ModelPreferences struct {
+	Hints                []ModelHint `json:"hints,omitempty"`
+	CostPriority         *float64    `json:"costPriority,omitempty"`
+	SpeedPriority        *float64    `json:"speedPriority,omitempty"`
+	IntelligencePriority *float64    `json:"intelligencePriority,omitempty"`
+}
+
+// ModelHint suggests a model name or family
+type ModelHint struct {
+	Name string `json:"name,omitempty"`
+}
+
+// CreateMessageResult represents the result of sampling/createMessage
+type CreateMessageResult struct {
+	Role       string          `json:"role"`
+	Content    SamplingContent `json:"content"`
+	Model      string          `json:"model"`
+	StopReason string          `json:"stopReason,omitempty"`
+}
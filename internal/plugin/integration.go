@@ -1,8 +1,9 @@
 // Package plugin provides integration helpers for loading plugin components
-// into the skill, agent, hooks, and MCP registries.
+// into the skill, agent, hooks, MCP, and tool registries.
 package plugin
 
 import (
+	"sort"
 	"strings"
 	"sync"
 
@@ -104,6 +105,39 @@ func GetPluginMCPServers() []PluginMCPServer {
 	return servers
 }
 
+// PluginTool represents a command-backed tool from a plugin.
+type PluginTool struct {
+	Name   string     // Tool name as declared by the plugin
+	Plugin string     // Name of the plugin that provides the tool
+	Root   string     // Plugin root directory
+	Config ToolConfig // Tool declaration
+	Scope  Scope      // Plugin scope
+}
+
+// GetPluginTools returns all command-backed tools from enabled plugins,
+// sorted by plugin and tool name.
+func GetPluginTools() []PluginTool {
+	var tools []PluginTool
+	for _, p := range defaultRegistry.GetEnabled() {
+		for name, cfg := range p.Components.Tools {
+			tools = append(tools, PluginTool{
+				Name:   name,
+				Plugin: p.Name(),
+				Root:   p.Path,
+				Config: cfg,
+				Scope:  p.Scope,
+			})
+		}
+	}
+	sort.Slice(tools, func(i, j int) bool {
+		if tools[i].Plugin != tools[j].Plugin {
+			return tools[i].Plugin < tools[j].Plugin
+		}
+		return tools[i].Name < tools[j].Name
+	})
+	return tools
+}
+
 // GetPluginNamespace extracts the namespace from a plugin path or source.
 func GetPluginNamespace(source string) string {
 	name, _ := ParsePluginRef(source)
@@ -93,6 +93,7 @@ func resolveComponents(manifest *Manifest, pluginPath string) Components {
 		Hooks:    ResolveHooksConfig(manifest.Hooks, pluginPath),
 		MCP:      ResolveMCPServers(manifest.MCPServers, pluginPath),
 		LSP:      ResolveLSPServers(manifest.LSPServers, pluginPath),
+		Tools:    ResolveTools(manifest.Tools, pluginPath),
 	}
 }
 
@@ -164,6 +164,53 @@ You are a test agent.
 	}
 }
 
+func TestResolveTools(t *testing.T) {
+	tmpDir := t.TempDir()
+	toolsJSON := `{
+  "tools": {
+    "jira_search": {
+      "description": "Search Jira issues",
+      "command": "${GEN_PLUGIN_ROOT}/bin/jira",
+      "args": ["search"],
+      "env": {"JIRA_CONFIG": "${GEN_PLUGIN_ROOT}/jira.json"},
+      "inputSchema": {"type": "object", "properties": {"query": {"type": "string"}}},
+      "timeout": 30
+    }
+  }
+}`
+	if err := os.WriteFile(filepath.Join(tmpDir, "tools.json"), []byte(toolsJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tools := ResolveTools(nil, tmpDir)
+	cfg, ok := tools["jira_search"]
+	if !ok {
+		t.Fatalf("ResolveTools() = %v, want jira_search", tools)
+	}
+	if cfg.Command != filepath.Join(tmpDir, "bin", "jira") {
+		t.Errorf("Command = %q, want plugin root expanded", cfg.Command)
+	}
+	if len(cfg.Args) != 1 || cfg.Args[0] != "search" {
+		t.Errorf("Args = %v, want [search]", cfg.Args)
+	}
+	if cfg.Env["JIRA_CONFIG"] != filepath.Join(tmpDir, "jira.json") {
+		t.Errorf("Env = %v, want plugin root expanded", cfg.Env)
+	}
+	if cfg.Timeout != 30 {
+		t.Errorf("Timeout = %d, want 30", cfg.Timeout)
+	}
+	if cfg.InputSchema["type"] != "object" {
+		t.Errorf("InputSchema = %v, want object schema", cfg.InputSchema)
+	}
+
+	inline := ResolveTools(map[string]any{
+		"echo": map[string]any{"command": "cat"},
+	}, tmpDir)
+	if inline["echo"].Command != "cat" {
+		t.Errorf("inline tools = %v, want echo", inline)
+	}
+}
+
 func TestRegistry(t *testing.T) {
 	// Create a test plugin
 	tmpDir := t.TempDir()
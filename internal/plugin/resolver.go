@@ -176,6 +176,11 @@ func ResolveLSPServers(field any, pluginPath string) map[string]LSPServerConfig
 	return resolveConfigMap(field, pluginPath, ".lsp.json", loadLSPFile, parseLSPMap)
 }
 
+// ResolveTools resolves command-backed tool declarations from the plugin.
+func ResolveTools(field any, pluginPath string) map[string]ToolConfig {
+	return resolveConfigMap(field, pluginPath, "tools.json", loadToolsFile, parseToolsMap)
+}
+
 // resolveConfigMap is a generic resolver for config file or inline map fields.
 func resolveConfigMap[T any](
 	field any,
@@ -450,3 +455,61 @@ func parseLSPMap(m map[string]any, pluginPath string) map[string]LSPServerConfig
 	}
 	return result
 }
+
+// loadToolsFile loads tool declarations from a JSON file.
+func loadToolsFile(path string, pluginPath string) map[string]ToolConfig {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+	// Handle both direct tool map and wrapped tools field
+	if tools, ok := raw["tools"].(map[string]any); ok {
+		return parseToolsMap(tools, pluginPath)
+	}
+	return parseToolsMap(raw, pluginPath)
+}
+
+// parseToolsMap parses tool declarations from a map.
+func parseToolsMap(m map[string]any, pluginPath string) map[string]ToolConfig {
+	result := make(map[string]ToolConfig)
+	for name, configAny := range m {
+		configMap, ok := configAny.(map[string]any)
+		if !ok {
+			continue
+		}
+		config := ToolConfig{}
+		if d, ok := configMap["description"].(string); ok {
+			config.Description = d
+		}
+		if c, ok := configMap["command"].(string); ok {
+			config.Command = ExpandPluginRoot(c, pluginPath)
+		}
+		if args, ok := configMap["args"].([]any); ok {
+			for _, arg := range args {
+				if s, ok := arg.(string); ok {
+					config.Args = append(config.Args, ExpandPluginRoot(s, pluginPath))
+				}
+			}
+		}
+		if env, ok := configMap["env"].(map[string]any); ok {
+			config.Env = make(map[string]string)
+			for k, v := range env {
+				if s, ok := v.(string); ok {
+					config.Env[k] = ExpandPluginRoot(s, pluginPath)
+				}
+			}
+		}
+		if schema, ok := configMap["inputSchema"].(map[string]any); ok {
+			config.InputSchema = schema
+		}
+		if t, ok := configMap["timeout"].(float64); ok {
+			config.Timeout = int(t)
+		}
+		result[name] = config
+	}
+	return result
+}
@@ -102,6 +102,9 @@ type Components struct {
 
 	// LSP contains LSP server configurations
 	LSP map[string]LSPServerConfig
+
+	// Tools contains command-backed tool declarations
+	Tools map[string]ToolConfig
 }
 
 // HooksConfig represents the hooks configuration from a plugin.
@@ -117,7 +120,7 @@ type HookMatcher struct {
 
 // MCPServerConfig represents an MCP server configuration from a plugin.
 type MCPServerConfig struct {
-	// Type is the transport type (stdio, http, sse)
+	// Type is the transport type (stdio, http, sse, ws)
 	Type string `json:"type,omitempty"`
 
 	// STDIO transport
@@ -137,6 +140,18 @@ type LSPServerConfig struct {
 	ExtensionToLanguage map[string]string `json:"extensionToLanguage,omitempty"`
 }
 
+// ToolConfig declares a native tool backed by a command from a plugin.
+// The command receives the tool input as JSON on stdin and its stdout is
+// returned to the model.
+type ToolConfig struct {
+	Description string            `json:"description"`
+	Command     string            `json:"command"`
+	Args        []string          `json:"args,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+	InputSchema map[string]any    `json:"inputSchema,omitempty"`
+	Timeout     int               `json:"timeout,omitempty"` // seconds
+}
+
 // Manifest represents plugin metadata from plugin.json.
 // Compatible with Claude Code .claude-plugin/plugin.json format.
 type Manifest struct {
@@ -160,6 +175,7 @@ type Manifest struct {
 	Hooks      any `json:"hooks,omitempty"`
 	MCPServers any `json:"mcpServers,omitempty"`
 	LSPServers any `json:"lspServers,omitempty"`
+	Tools      any `json:"tools,omitempty"`
 }
 
 // Author represents plugin author information.
@@ -0,0 +1,226 @@
+package search
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCacheTTL is how long search results are reused when no
+	// searchCacheTTL is configured.
+	DefaultCacheTTL = time.Hour
+
+	// cacheCapacity is the number of queries kept in memory before the
+	// least recently used one is evicted.
+	cacheCapacity = 128
+)
+
+// resultCache is an LRU cache of search results keyed by provider and
+// query. Entries expire after ttl. When dir is set, entries are also written
+// to disk so later runs can reuse them.
+type resultCache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	dir      string
+	capacity int
+	order    *list.List // front is most recently used
+	entries  map[string]*list.Element
+	now      func() time.Time
+}
+
+// cacheEntry is one cached search, in memory and on disk.
+type cacheEntry struct {
+	Key       string         `json:"key"`
+	CreatedAt time.Time      `json:"created_at"`
+	Results   []SearchResult `json:"results"`
+}
+
+func newResultCache(capacity int, ttl time.Duration, dir string) *resultCache {
+	return &resultCache{
+		ttl:      ttl,
+		dir:      dir,
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+var searchCache = struct {
+	mu    sync.RWMutex
+	cache *resultCache
+}{cache: newResultCache(cacheCapacity, DefaultCacheTTL, "")}
+
+// ConfigureCache replaces the search result cache. Entries live for ttl, or
+// DefaultCacheTTL when ttl is not positive. A non-empty dir also persists
+// entries there across runs.
+func ConfigureCache(dir string, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	searchCache.mu.Lock()
+	defer searchCache.mu.Unlock()
+	searchCache.cache = newResultCache(cacheCapacity, ttl, dir)
+}
+
+// DefaultCacheDir returns ~/.gen/cache/search.
+func DefaultCacheDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".gen", "cache", "search")
+}
+
+func currentCache() *resultCache {
+	searchCache.mu.RLock()
+	defer searchCache.mu.RUnlock()
+	return searchCache.cache
+}
+
+// cacheKey hashes the provider, query, and the options that change which
+// results come back.
+func cacheKey(provider ProviderName, query string, opts SearchOptions) string {
+	data, _ := json.Marshal(struct {
+		Provider       ProviderName
+		Query          string
+		NumResults     int
+		AllowedDomains []string
+		BlockedDomains []string
+	}{provider, query, opts.NumResults, opts.AllowedDomains, opts.BlockedDomains})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// get returns fresh cached results for key, falling back to disk when the
+// entry is not in memory.
+func (c *resultCache) get(key string) ([]SearchResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*cacheEntry)
+		if c.expired(entry) {
+			c.removeLocked(el)
+			return nil, false
+		}
+		c.order.MoveToFront(el)
+		return entry.Results, true
+	}
+
+	entry, ok := c.loadLocked(key)
+	if !ok {
+		return nil, false
+	}
+	c.insertLocked(entry)
+	return entry.Results, true
+}
+
+// put stores results for key, evicting the least recently used entry when
+// the cache is full.
+func (c *resultCache) put(key string, results []SearchResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &cacheEntry{Key: key, CreatedAt: c.now(), Results: results}
+	if el, ok := c.entries[key]; ok {
+		c.removeLocked(el)
+	}
+	c.insertLocked(entry)
+	c.storeLocked(entry)
+}
+
+func (c *resultCache) expired(entry *cacheEntry) bool {
+	return c.now().Sub(entry.CreatedAt) > c.ttl
+}
+
+func (c *resultCache) insertLocked(entry *cacheEntry) {
+	c.entries[entry.Key] = c.order.PushFront(entry)
+	for c.order.Len() > c.capacity {
+		c.removeLocked(c.order.Back())
+	}
+}
+
+// removeLocked drops an entry from memory. Disk entries are left for their
+// TTL so an evicted query can still be served from disk.
+func (c *resultCache) removeLocked(el *list.Element) {
+	entry := c.order.Remove(el).(*cacheEntry)
+	delete(c.entries, entry.Key)
+}
+
+func (c *resultCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+func (c *resultCache) loadLocked(key string) (*cacheEntry, bool) {
+	if c.dir == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key {
+		return nil, false
+	}
+	if c.expired(&entry) {
+		_ = os.Remove(c.path(key))
+		return nil, false
+	}
+	return &entry, true
+}
+
+func (c *resultCache) storeLocked(entry *cacheEntry) {
+	if c.dir == "" {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return
+	}
+	tmp := c.path(entry.Key) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, c.path(entry.Key))
+}
+
+// cachedProvider serves repeated searches from the result cache. Failed
+// searches are never cached.
+type cachedProvider struct {
+	Provider
+}
+
+func withCache(p Provider) Provider {
+	return &cachedProvider{Provider: p}
+}
+
+// Search returns cached results for the same provider, query, and filters
+// unless opts.NoCache asks for a fresh search.
+func (p *cachedProvider) Search(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error) {
+	c := currentCache()
+	key := cacheKey(p.Name(), query, opts)
+	if !opts.NoCache {
+		if results, ok := c.get(key); ok {
+			return results, nil
+		}
+	}
+
+	results, err := p.Provider.Search(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	c.put(key, results)
+	return results, nil
+}
@@ -0,0 +1,142 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// countingProvider returns one result naming the query and counts calls.
+type countingProvider struct {
+	calls int
+	err   error
+}
+
+func (p *countingProvider) Name() ProviderName   { return "counting" }
+func (p *countingProvider) DisplayName() string  { return "Counting" }
+func (p *countingProvider) RequiresAPIKey() bool { return false }
+func (p *countingProvider) EnvVars() []string    { return nil }
+func (p *countingProvider) IsAvailable() bool    { return true }
+
+func (p *countingProvider) Search(_ context.Context, query string, _ SearchOptions) ([]SearchResult, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []SearchResult{{Title: query, URL: "https://example.com/" + query}}, nil
+}
+
+func useCache(t *testing.T, c *resultCache) {
+	t.Helper()
+	searchCache.mu.Lock()
+	prev := searchCache.cache
+	searchCache.cache = c
+	searchCache.mu.Unlock()
+	t.Cleanup(func() {
+		searchCache.mu.Lock()
+		searchCache.cache = prev
+		searchCache.mu.Unlock()
+	})
+}
+
+func TestCachedProviderReusesResults(t *testing.T) {
+	useCache(t, newResultCache(cacheCapacity, time.Hour, ""))
+	inner := &countingProvider{}
+	p := withCache(inner)
+	ctx := context.Background()
+
+	for range 2 {
+		results, err := p.Search(ctx, "golang", SearchOptions{})
+		if err != nil || len(results) != 1 || results[0].Title != "golang" {
+			t.Fatalf("Search() = %v, %v", results, err)
+		}
+	}
+	if inner.calls != 1 {
+		t.Errorf("provider called %d times, want 1", inner.calls)
+	}
+
+	// Different filters are a different search.
+	if _, err := p.Search(ctx, "golang", SearchOptions{AllowedDomains: []string{"go.dev"}}); err != nil {
+		t.Fatal(err)
+	}
+	if inner.calls != 2 {
+		t.Errorf("provider called %d times after changing filters, want 2", inner.calls)
+	}
+
+	// NoCache forces a fresh search.
+	if _, err := p.Search(ctx, "golang", SearchOptions{NoCache: true}); err != nil {
+		t.Fatal(err)
+	}
+	if inner.calls != 3 {
+		t.Errorf("provider called %d times with NoCache, want 3", inner.calls)
+	}
+}
+
+func TestCachedProviderSkipsErrors(t *testing.T) {
+	useCache(t, newResultCache(cacheCapacity, time.Hour, ""))
+	inner := &countingProvider{err: errors.New("rate limited")}
+	p := withCache(inner)
+
+	for range 2 {
+		if _, err := p.Search(context.Background(), "golang", SearchOptions{}); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+	if inner.calls != 2 {
+		t.Errorf("provider called %d times, want 2 (errors are not cached)", inner.calls)
+	}
+}
+
+func TestResultCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newResultCache(2, time.Hour, "")
+	c.put("a", []SearchResult{{Title: "a"}})
+	c.put("b", []SearchResult{{Title: "b"}})
+	if _, ok := c.get("a"); !ok { // a is now most recently used
+		t.Fatal("expected a to be cached")
+	}
+	c.put("c", []SearchResult{{Title: "c"}})
+
+	if _, ok := c.get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.get(key); !ok {
+			t.Errorf("expected %s to be cached", key)
+		}
+	}
+}
+
+func TestResultCacheExpiresAfterTTL(t *testing.T) {
+	now := time.Now()
+	c := newResultCache(cacheCapacity, time.Hour, t.TempDir())
+	c.now = func() time.Time { return now }
+	c.put("q", []SearchResult{{Title: "q"}})
+
+	now = now.Add(59 * time.Minute)
+	if _, ok := c.get("q"); !ok {
+		t.Fatal("expected entry to be fresh before the TTL")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.get("q"); ok {
+		t.Error("expected entry to expire after the TTL")
+	}
+	if _, ok := c.loadLocked("q"); ok {
+		t.Error("expected expired disk entry to be removed")
+	}
+}
+
+func TestResultCachePersistsToDisk(t *testing.T) {
+	dir := t.TempDir()
+	newResultCache(cacheCapacity, time.Hour, dir).put("q", []SearchResult{{Title: "q", URL: "https://example.com"}})
+
+	results, ok := newResultCache(cacheCapacity, time.Hour, dir).get("q")
+	if !ok || len(results) != 1 || results[0].URL != "https://example.com" {
+		t.Fatalf("get() from a new cache = %v, %v; want the persisted entry", results, ok)
+	}
+
+	if _, ok := newResultCache(cacheCapacity, time.Hour, "").get("q"); ok {
+		t.Error("memory-only cache should not read from disk")
+	}
+}
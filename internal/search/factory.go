@@ -16,18 +16,19 @@ func Preferred() Provider {
 	return GetDefaultProvider()
 }
 
-// CreateProvider creates a search provider by name.
+// CreateProvider creates a search provider by name. Its results are served
+// from the search cache when possible.
 // API keys are resolved via os.Getenv first, then the persistent secret store.
 func CreateProvider(name ProviderName) Provider {
 	switch name {
 	case ProviderSerper:
-		return NewSerperProvider(secret.Resolve(serperEnvKey))
+		return withCache(NewSerperProvider(secret.Resolve(serperEnvKey)))
 	case ProviderBrave:
-		return NewBraveProvider(secret.Resolve(braveEnvKey))
+		return withCache(NewBraveProvider(secret.Resolve(braveEnvKey)))
 	case ProviderExa:
 		fallthrough
 	default:
-		return NewExaProvider()
+		return withCache(NewExaProvider())
 	}
 }
 
@@ -35,7 +36,7 @@ func CreateProvider(name ProviderName) Provider {
 // Priority: Exa (no key needed) > Serper > Brave
 func GetDefaultProvider() Provider {
 	// Exa is always available (no API key required)
-	return NewExaProvider()
+	return withCache(NewExaProvider())
 }
 
 // matchesDomainFilter checks if a URL matches the domain filter criteria
@@ -29,6 +29,7 @@ type SearchOptions struct {
 	AllowedDomains []string
 	BlockedDomains []string
 	Timeout        time.Duration
+	NoCache        bool // skip cached results and search again
 }
 
 // truncateSnippet truncates a snippet to maxLength runes
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+// eventWriter writes Server-Sent Events with JSON data.
+type eventWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func (e *eventWriter) send(name string, data any) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(e.w, "event: %s\ndata: %s\n\n", name, payload)
+	e.flusher.Flush()
+}
+
+// event forwards the agent events a client can act on: streamed text and
+// thinking, and each tool call with its result.
+func (e *eventWriter) event(ev core.Event) {
+	switch ev.Type {
+	case core.OnChunk:
+		c, _ := ev.Chunk()
+		if c.Text != "" {
+			e.send("text", map[string]string{"text": c.Text})
+		}
+		if c.Thinking != "" {
+			e.send("thinking", map[string]string{"text": c.Thinking})
+		}
+		if c.Notice != "" {
+			e.send("notice", map[string]string{"text": c.Notice})
+		}
+	case core.PreTool:
+		tc, _ := ev.ToolCall()
+		e.send("tool_use", map[string]any{"id": tc.ID, "name": tc.Name, "input": toolInput(tc.Input)})
+	case core.PostTool:
+		tr, _ := ev.ToolResult()
+		e.send("tool_result", map[string]any{
+			"id":       tr.ToolCallID,
+			"name":     tr.ToolName,
+			"content":  tr.Content,
+			"is_error": tr.IsError,
+		})
+	}
+}
+
+// toolInput returns the tool input as JSON when it parses, else as a string.
+func toolInput(input string) any {
+	if json.Valid([]byte(input)) {
+		return json.RawMessage(input)
+	}
+	return input
+}
@@ -0,0 +1,393 @@
+// Package server exposes the agent loop over a small local HTTP API so
+// editors and scripts can use gen without the TUI (gen serve).
+//
+// POST /v1/messages sends a message and streams the answer as Server-Sent
+// Events. Conversations are kept in memory per session ID until the server
+// exits or the session is deleted.
+//
+// Requests must carry the server's bearer token, and a loopback server only
+// answers requests addressed to a loopback host, so a web page cannot reach
+// it through DNS rebinding.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/yanmxa/gencode/internal/agent"
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/core/system"
+	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/setting"
+	"github.com/yanmxa/gencode/internal/tool"
+	"github.com/yanmxa/gencode/internal/tool/perm"
+	"github.com/yanmxa/gencode/internal/tool/scratchpad"
+)
+
+// Options configures a Server.
+type Options struct {
+	CWD      string
+	Provider llm.Provider // provider for requests that don't name one
+	ModelID  string       // model for requests that don't name one
+
+	// Connect returns a connected provider by name and its default model,
+	// for requests that select another provider. Nil rejects such requests.
+	Connect func(ctx context.Context, name string) (llm.Provider, string, error)
+
+	// PermissionMode is a setting.Headless* mode applied to every session.
+	// Nobody can answer a prompt, so anything that would ask is denied.
+	PermissionMode string
+
+	MaxTokens int // 0 = setting.DefaultMaxTokens
+
+	// Token, when set, must be sent as "Authorization: Bearer <token>".
+	Token string
+
+	// LoopbackOnly rejects requests whose Host header is not localhost or a
+	// loopback address.
+	LoopbackOnly bool
+}
+
+// Server answers API requests. Create it with New and mount it as an
+// http.Handler.
+type Server struct {
+	opts     Options
+	perms    *setting.SessionPermissions
+	mux      *http.ServeMux
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+// session is one conversation. mu is held while a message is answered, so a
+// session handles one request at a time.
+type session struct {
+	mu       sync.Mutex
+	id       string
+	agent    core.Agent
+	provider llm.Provider
+	modelID  string
+}
+
+// MessageRequest is the body of POST /v1/messages.
+type MessageRequest struct {
+	Message   string `json:"message"`
+	SessionID string `json:"session_id,omitempty"` // empty starts a new session
+	Provider  string `json:"provider,omitempty"`   // connected provider; empty keeps the session's
+	Model     string `json:"model,omitempty"`      // model ID; empty keeps the session's
+}
+
+// New validates opts and returns a Server.
+func New(opts Options) (*Server, error) {
+	if opts.Provider == nil {
+		return nil, errors.New("no provider connected. Run 'gen' and use /provider to connect")
+	}
+	perms, err := setting.NewHeadlessPermissions(opts.PermissionMode, opts.CWD)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MaxTokens <= 0 {
+		opts.MaxTokens = setting.DefaultMaxTokens
+	}
+	s := &Server{
+		opts:     opts,
+		perms:    perms,
+		mux:      http.NewServeMux(),
+		sessions: make(map[string]*session),
+	}
+	s.mux.HandleFunc("POST /v1/messages", s.handleMessage)
+	s.mux.HandleFunc("GET /v1/sessions", s.handleListSessions)
+	s.mux.HandleFunc("DELETE /v1/sessions/{id}", s.handleDeleteSession)
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.opts.LoopbackOnly && !isLoopbackHost(r.Host) {
+		writeError(w, http.StatusForbidden, fmt.Sprintf("host %s is not allowed", r.Host))
+		return
+	}
+	if s.opts.Token != "" && !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.Token)) == 1
+}
+
+// isLoopbackHost reports whether a Host header names this machine. A
+// rebound DNS name resolves to 127.0.0.1 but keeps its own name here.
+func isLoopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (s *Server) handleMessage(w http.ResponseWriter, r *http.Request) {
+	// Requiring JSON keeps browsers from posting here without a CORS
+	// preflight, which this server never answers.
+	if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		return
+	}
+	var req MessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if req.Message == "" {
+		writeError(w, http.StatusBadRequest, "message is required")
+		return
+	}
+
+	sess, status, err := s.session(req.SessionID)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	if !sess.mu.TryLock() {
+		writeError(w, http.StatusConflict, fmt.Sprintf("session %s is busy with another message", sess.id))
+		return
+	}
+	defer sess.mu.Unlock()
+
+	if err := s.selectModel(r.Context(), sess, req.Provider, req.Model); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	stream := &eventWriter{w: w, flusher: flusher}
+	stream.send("session", map[string]string{"session_id": sess.id, "model": sess.modelID})
+	s.run(r.Context(), sess, req.Message, stream)
+}
+
+// session returns the session with id, or a new one when id is empty.
+func (s *Server) session(id string) (*session, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id == "" {
+		sess := &session{id: generateID(), provider: s.opts.Provider, modelID: s.opts.ModelID}
+		s.sessions[sess.id] = sess
+		return sess, 0, nil
+	}
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil, http.StatusNotFound, fmt.Errorf("session %s not found", id)
+	}
+	return sess, 0, nil
+}
+
+// selectModel switches sess to another provider or model. The agent is
+// rebuilt with the conversation so far.
+func (s *Server) selectModel(ctx context.Context, sess *session, providerName, modelID string) error {
+	provider := sess.provider
+	if providerName != "" && providerName != provider.Name() {
+		if s.opts.Connect == nil {
+			return fmt.Errorf("cannot switch to provider %s", providerName)
+		}
+		p, defaultModel, err := s.opts.Connect(ctx, providerName)
+		if err != nil {
+			return fmt.Errorf("provider %s not available: %w", providerName, err)
+		}
+		provider = p
+		if modelID == "" {
+			modelID = defaultModel
+		}
+	}
+	if modelID == "" {
+		modelID = sess.modelID
+	}
+	if provider == sess.provider && modelID == sess.modelID {
+		return nil
+	}
+	prevProvider, prevModel := sess.provider, sess.modelID
+	sess.provider, sess.modelID = provider, modelID
+	if sess.agent == nil {
+		return nil
+	}
+	ag, err := s.buildAgent(sess)
+	if err != nil {
+		sess.provider, sess.modelID = prevProvider, prevModel
+		return err
+	}
+	ag.SetMessages(sess.agent.Messages())
+	sess.agent = ag
+	return nil
+}
+
+func (s *Server) buildAgent(sess *session) (core.Agent, error) {
+	user, project := system.LoadInstructions(s.opts.CWD)
+	ag, _, err := agent.Build(agent.BuildParams{
+		Provider:            sess.provider,
+		ModelID:             sess.modelID,
+		MaxTokens:           s.opts.MaxTokens,
+		CWD:                 s.opts.CWD,
+		IsGit:               setting.IsGitRepo(s.opts.CWD),
+		CustomPrompt:        system.LoadCustomPrompt(s.opts.CWD),
+		UserInstructions:    user,
+		ProjectInstructions: project,
+		// Nobody is there to answer questions.
+		DisabledTools:     map[string]bool{tool.ToolAskUserQuestion: true},
+		PermissionDecider: s.decidePermission,
+		ScratchpadID:      "serve:" + sess.id,
+	})
+	return ag, err
+}
+
+func (s *Server) decidePermission(name string, args map[string]any) agent.PermDecisionResult {
+	d := setting.Default().HasPermissionToUseTool(name, args, s.perms)
+	if d.Behavior == setting.Allow {
+		return agent.PermDecisionResult{Decision: perm.Permit, Reason: d.Reason}
+	}
+	return agent.PermDecisionResult{
+		Decision: perm.Reject,
+		Reason:   fmt.Sprintf("tool %s denied by --permission-mode %s: %s", name, s.permissionMode(), d.Reason),
+	}
+}
+
+func (s *Server) permissionMode() string {
+	if s.opts.PermissionMode == "" {
+		return setting.HeadlessDeny
+	}
+	return s.opts.PermissionMode
+}
+
+// run answers message in sess, streaming agent events until the turn ends
+// or the client goes away.
+func (s *Server) run(ctx context.Context, sess *session, message string, stream *eventWriter) {
+	if sess.agent == nil {
+		ag, err := s.buildAgent(sess)
+		if err != nil {
+			stream.send("error", map[string]string{"error": err.Error()})
+			return
+		}
+		sess.agent = ag
+	}
+	ag := sess.agent
+
+	type outcome struct {
+		result *core.Result
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		ag.Append(ctx, core.UserMessage(message, nil))
+		result, err := ag.ThinkAct(ctx)
+		done <- outcome{result, err}
+	}()
+
+	for {
+		select {
+		case ev := <-ag.Outbox():
+			stream.event(ev)
+		case out := <-done:
+			for drained := false; !drained; {
+				select {
+				case ev := <-ag.Outbox():
+					stream.event(ev)
+				default:
+					drained = true
+				}
+			}
+			if out.err != nil {
+				stream.send("error", map[string]string{"error": out.err.Error()})
+				return
+			}
+			stream.send("done", doneEvent(sess.id, out.result))
+			return
+		}
+	}
+}
+
+func (s *Server) handleListSessions(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	ids := make([]string, 0, len(s.sessions))
+	for id := range s.sessions {
+		ids = append(ids, id)
+	}
+	s.mu.Unlock()
+	sort.Strings(ids)
+	writeJSON(w, http.StatusOK, map[string][]string{"sessions": ids})
+}
+
+func (s *Server) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	s.mu.Lock()
+	_, ok := s.sessions[id]
+	delete(s.sessions, id)
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("session %s not found", id))
+		return
+	}
+	scratchpad.Reset("serve:" + id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func doneEvent(sessionID string, r *core.Result) map[string]any {
+	return map[string]any{
+		"session_id":    sessionID,
+		"content":       r.Content,
+		"stop_reason":   r.StopReason,
+		"turns":         r.Turns,
+		"tool_uses":     r.ToolUses,
+		"input_tokens":  r.TokensIn,
+		"output_tokens": r.TokensOut,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+func generateID() string {
+	return randomHex(8)
+}
+
+// NewToken returns a random bearer token for Options.Token.
+func NewToken() string {
+	return randomHex(32)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic("crypto/rand.Read failed: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,237 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/llm/fake"
+	"github.com/yanmxa/gencode/internal/setting"
+	_ "github.com/yanmxa/gencode/internal/tool/registry"
+)
+
+type sseEvent struct {
+	Name string
+	Data map[string]any
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, *fake.Provider, string) {
+	t.Helper()
+	dir := t.TempDir()
+	setting.Initialize(setting.Options{CWD: dir})
+	p := fake.New()
+	srv, err := New(Options{CWD: dir, Provider: p, ModelID: "fake-model"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	return ts, p, dir
+}
+
+func postMessage(t *testing.T, ts *httptest.Server, body string) (*http.Response, []sseEvent) {
+	t.Helper()
+	resp, err := http.Post(ts.URL+"/v1/messages", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST /v1/messages: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+	var events []sseEvent
+	var name string
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			var data map[string]any
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &data); err != nil {
+				t.Fatalf("event %s has invalid data %q: %v", name, line, err)
+			}
+			events = append(events, sseEvent{Name: name, Data: data})
+		}
+	}
+	return resp, events
+}
+
+func eventNames(events []sseEvent) string {
+	names := make([]string, len(events))
+	for i, e := range events {
+		names[i] = e.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func TestServer_StreamsAnswerAndKeepsSession(t *testing.T) {
+	ts, p, _ := newTestServer(t)
+	p.EnqueueText("hello there")
+
+	_, events := postMessage(t, ts, `{"message":"hi"}`)
+	if got := eventNames(events); got != "session,text,done" {
+		t.Fatalf("events = %s, want session,text,done", got)
+	}
+	if events[1].Data["text"] != "hello there" {
+		t.Errorf("text = %v", events[1].Data["text"])
+	}
+	id, _ := events[0].Data["session_id"].(string)
+	if id == "" || events[2].Data["session_id"] != id {
+		t.Fatalf("session ids = %v / %v", events[0].Data["session_id"], events[2].Data["session_id"])
+	}
+	if events[2].Data["content"] != "hello there" {
+		t.Errorf("done content = %v", events[2].Data["content"])
+	}
+
+	p.EnqueueText("second answer")
+	_, events = postMessage(t, ts, `{"message":"again","session_id":"`+id+`","model":"other-model"}`)
+	if got := eventNames(events); got != "session,text,done" {
+		t.Fatalf("second events = %s", got)
+	}
+	if events[0].Data["model"] != "other-model" {
+		t.Errorf("session model = %v, want other-model", events[0].Data["model"])
+	}
+	reqs := p.Requests()
+	last := reqs[len(reqs)-1]
+	if last.Model != "other-model" {
+		t.Errorf("request model = %q, want other-model", last.Model)
+	}
+	if len(last.Messages) != 3 {
+		t.Errorf("second request carried %d messages, want the previous exchange plus the new message", len(last.Messages))
+	}
+}
+
+func TestServer_StreamsToolEvents(t *testing.T) {
+	ts, p, dir := newTestServer(t)
+	path := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(path, []byte("secret sauce"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	input, _ := json.Marshal(map[string]string{"file_path": path})
+	p.EnqueueToolCall("call-1", "Read", string(input))
+	p.EnqueueText("it says secret sauce")
+
+	_, events := postMessage(t, ts, `{"message":"read notes"}`)
+	if got := eventNames(events); got != "session,tool_use,tool_result,text,done" {
+		t.Fatalf("events = %s", got)
+	}
+	use, result := events[1].Data, events[2].Data
+	if use["id"] != "call-1" || use["name"] != "Read" {
+		t.Errorf("tool_use = %v", use)
+	}
+	if in, _ := use["input"].(map[string]any); in["file_path"] != path {
+		t.Errorf("tool_use input = %v, want parsed JSON", use["input"])
+	}
+	if result["id"] != "call-1" || result["is_error"] == true || !strings.Contains(result["content"].(string), "secret sauce") {
+		t.Errorf("tool_result = %v", result)
+	}
+}
+
+func TestServer_RejectsBadRequests(t *testing.T) {
+	ts, _, _ := newTestServer(t)
+
+	resp, err := http.Post(ts.URL+"/v1/messages", "text/plain", strings.NewReader(`{"message":"hi"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("text/plain status = %d, want 415", resp.StatusCode)
+	}
+
+	for body, want := range map[string]int{
+		`{"message":""}`:                        http.StatusBadRequest,
+		`not json`:                              http.StatusBadRequest,
+		`{"message":"hi","session_id":"nope"}`:  http.StatusNotFound,
+		`{"message":"hi","provider":"missing"}`: http.StatusBadRequest,
+	} {
+		if resp, _ := postMessage(t, ts, body); resp.StatusCode != want {
+			t.Errorf("%s: status = %d, want %d", body, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestServer_ListAndDeleteSessions(t *testing.T) {
+	ts, p, _ := newTestServer(t)
+	p.EnqueueText("ok")
+	_, events := postMessage(t, ts, `{"message":"hi"}`)
+	id := events[0].Data["session_id"].(string)
+
+	resp, err := http.Get(ts.URL + "/v1/sessions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list struct{ Sessions []string }
+	_ = json.NewDecoder(resp.Body).Decode(&list)
+	resp.Body.Close()
+	if len(list.Sessions) != 1 || list.Sessions[0] != id {
+		t.Errorf("sessions = %v, want [%s]", list.Sessions, id)
+	}
+
+	del := func() int {
+		req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/v1/sessions/"+id, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if got := del(); got != http.StatusNoContent {
+		t.Errorf("first DELETE = %d, want 204", got)
+	}
+	if got := del(); got != http.StatusNotFound {
+		t.Errorf("second DELETE = %d, want 404", got)
+	}
+}
+
+func TestServer_RequiresTokenAndLoopbackHost(t *testing.T) {
+	dir := t.TempDir()
+	setting.Initialize(setting.Options{CWD: dir})
+	srv, err := New(Options{CWD: dir, Provider: fake.New(), Token: "secret", LoopbackOnly: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	tests := []struct {
+		name, host, auth string
+		want             int
+	}{
+		{"no token", "", "", http.StatusUnauthorized},
+		{"wrong token", "", "Bearer nope", http.StatusUnauthorized},
+		{"not a bearer token", "", "secret", http.StatusUnauthorized},
+		{"valid token", "", "Bearer secret", http.StatusOK},
+		{"localhost", "localhost:7878", "Bearer secret", http.StatusOK},
+		{"IPv6 loopback", "[::1]:7878", "Bearer secret", http.StatusOK},
+		{"rebound name", "attacker.example:7878", "Bearer secret", http.StatusForbidden},
+		{"LAN address", "192.168.1.20:7878", "Bearer secret", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, ts.URL+"/v1/sessions", nil)
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package session
 
 import (
+	"slices"
+
 	"github.com/yanmxa/gencode/internal/core"
 )
 
@@ -49,6 +51,13 @@ func ConvertToEntries(messages []core.ChatMessage) []Entry {
 				Content: assistantContentToBlocks(msg.Content, msg.Thinking, msg.ThinkingSignature, msg.ToolCalls),
 			}
 
+		case core.RoleContext:
+			entry.Type = EntryContext
+			entry.Message = &EntryMessage{
+				Role:    "context",
+				Content: userContentToBlocks(msg.Content, "", nil),
+			}
+
 		case core.RoleTool:
 			entry.Type = EntryUser
 			if msg.ToolResult != nil {
@@ -90,5 +99,66 @@ func ConvertFromEntries(entries []Entry) []core.ChatMessage {
 		messages = append(messages, chatMsg)
 	}
 
-	return messages
+	return RepairToolResults(messages)
+}
+
+// InterruptedToolResult is the content of the result recorded for a tool call
+// whose result was never saved, e.g. because the previous run crashed while
+// the tool was executing.
+const InterruptedToolResult = "Tool execution was interrupted before it completed."
+
+// RepairToolResults makes a restored conversation safe to send to a provider.
+// Tool results that do not answer a call of the preceding assistant message,
+// or that answer a call already answered, are dropped. Calls left without a
+// result get an error result right after the ones that were saved.
+func RepairToolResults(messages []core.ChatMessage) []core.ChatMessage {
+	out := make([]core.ChatMessage, 0, len(messages))
+	var pending []core.ToolCall
+	answered := make(map[string]bool)
+	insertAt := 0
+
+	flush := func() {
+		var missing []core.ChatMessage
+		for _, tc := range pending {
+			if answered[tc.ID] {
+				continue
+			}
+			missing = append(missing, core.ChatMessage{
+				Role:     core.RoleUser,
+				ToolName: tc.Name,
+				ToolResult: &core.ToolResult{
+					ToolCallID: tc.ID,
+					ToolName:   tc.Name,
+					Content:    InterruptedToolResult,
+					IsError:    true,
+				},
+			})
+		}
+		out = slices.Insert(out, insertAt, missing...)
+		pending = nil
+		clear(answered)
+	}
+
+	for _, msg := range messages {
+		if msg.ToolResult != nil {
+			id := msg.ToolResult.ToolCallID
+			if answered[id] || !slices.ContainsFunc(pending, func(tc core.ToolCall) bool { return tc.ID == id }) {
+				continue
+			}
+			answered[id] = true
+			out = append(out, msg)
+			insertAt = len(out)
+			continue
+		}
+		if msg.Role == core.RoleAssistant {
+			flush()
+			pending = msg.ToolCalls
+			out = append(out, msg)
+			insertAt = len(out)
+			continue
+		}
+		out = append(out, msg)
+	}
+	flush()
+	return out
 }
@@ -0,0 +1,58 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+func TestRepairToolResults(t *testing.T) {
+	result := func(id string) core.ChatMessage {
+		return core.ChatMessage{Role: core.RoleUser, ToolResult: &core.ToolResult{ToolCallID: id, Content: "ok"}}
+	}
+	msgs := []core.ChatMessage{
+		{Role: core.RoleUser, Content: "hi"},
+		result("stale"),
+		{Role: core.RoleAssistant, ToolCalls: []core.ToolCall{{ID: "a", Name: "Read"}, {ID: "b", Name: "Bash"}}},
+		result("a"),
+		result("a"),
+		{Role: core.RoleUser, Content: "next"},
+		{Role: core.RoleAssistant, ToolCalls: []core.ToolCall{{ID: "c", Name: "Edit"}}},
+	}
+
+	got := RepairToolResults(msgs)
+
+	want := []struct {
+		role    core.Role
+		toolID  string
+		isError bool
+	}{
+		{role: core.RoleUser},
+		{role: core.RoleAssistant},
+		{role: core.RoleUser, toolID: "a"},
+		{role: core.RoleUser, toolID: "b", isError: true},
+		{role: core.RoleUser},
+		{role: core.RoleAssistant},
+		{role: core.RoleUser, toolID: "c", isError: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("len = %d, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		msg := got[i]
+		if msg.Role != w.role {
+			t.Errorf("[%d] role = %s, want %s", i, msg.Role, w.role)
+		}
+		var id string
+		var isError bool
+		if msg.ToolResult != nil {
+			id, isError = msg.ToolResult.ToolCallID, msg.ToolResult.IsError
+		}
+		if id != w.toolID || isError != w.isError {
+			t.Errorf("[%d] tool result = %q (error %v), want %q (error %v)", i, id, isError, w.toolID, w.isError)
+		}
+	}
+	if got[3].ToolName != "Bash" || got[3].ToolResult.Content != InterruptedToolResult {
+		t.Errorf("interrupted result = %+v", got[3])
+	}
+}
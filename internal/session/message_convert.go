@@ -89,6 +89,13 @@ func EntriesToMessages(entries []Entry) []core.Message {
 				extractAssistantContent(entry.Message.Content, &msg)
 			}
 			msgs = append(msgs, msg)
+		case EntryContext:
+			msg := core.Message{Role: core.RoleContext}
+			if entry.Message != nil {
+				extractUserContent(entry.Message.Content, &msg)
+				msg.DisplayContent = ""
+			}
+			msgs = append(msgs, msg)
 		}
 	}
 	return msgs
@@ -43,11 +43,16 @@ func TranscriptFromSnapshot(sess *Snapshot, nodes []transcript.Node, tasks []tra
 		Model:     sess.Metadata.Model,
 		Messages:  nodes,
 		State: transcript.State{
-			Title:      sess.Metadata.Title,
-			LastPrompt: sess.Metadata.LastPrompt,
-			Tag:        sess.Metadata.Tag,
-			Mode:       sess.Metadata.Mode,
-			Tasks:      transcript.TrackerTaskViewsFromTasks(tasks),
+			Title:       sess.Metadata.Title,
+			CustomTitle: sess.Metadata.CustomTitle,
+			LastPrompt:  sess.Metadata.LastPrompt,
+			Tag:         sess.Metadata.Tag,
+			Mode:        sess.Metadata.Mode,
+			Tokens: transcript.TokenUsage{
+				Input:  sess.Metadata.InputTokens,
+				Output: sess.Metadata.OutputTokens,
+			},
+			Tasks: transcript.TrackerTaskViewsFromTasks(tasks),
 		},
 	}
 }
@@ -87,6 +87,8 @@ func entryTypeForRole(role string) string {
 	switch role {
 	case "assistant":
 		return EntryAssistant
+	case "context":
+		return EntryContext
 	default:
 		return EntryUser
 	}
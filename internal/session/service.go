@@ -14,6 +14,8 @@ type Service interface {
 	ID() string
 	SetID(id string)
 	TranscriptPath() string
+	Title() string
+	SetTitle(title string)
 
 	// store access
 	GetStore() *Store
@@ -26,6 +28,7 @@ type Service interface {
 	LoadLatest() (*Snapshot, error)
 	List() ([]*SessionMetadata, error)
 	Fork(id string) (*Snapshot, error)
+	ForkSnapshot(src *Snapshot) (*Snapshot, error)
 }
 
 // Compile-time check: *Setup implements Service.
@@ -14,6 +14,10 @@ type Setup struct {
 
 	Store     *Store
 	SessionID string
+
+	// title is the custom title set by /rename, saved as the session's
+	// CustomTitle. Empty means the title is generated from the conversation.
+	title string
 }
 
 // EnsureStore lazily initializes the session store for the given cwd.
@@ -54,6 +58,20 @@ func (s *Setup) SetID(id string) {
 	s.SessionID = id
 }
 
+// Title returns the current session's custom title, or "" if none is set.
+func (s *Setup) Title() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.title
+}
+
+// SetTitle sets the current session's custom title.
+func (s *Setup) SetTitle(title string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.title = title
+}
+
 // TranscriptPath returns the transcript file path for the current session,
 // or empty string if the store is nil.
 func (s *Setup) TranscriptPath() string {
@@ -134,3 +152,14 @@ func (s *Setup) Fork(id string) (*Snapshot, error) {
 	}
 	return st.Fork(id)
 }
+
+// ForkSnapshot saves a copy of a snapshot as a child session via the store.
+func (s *Setup) ForkSnapshot(src *Snapshot) (*Snapshot, error) {
+	s.mu.RLock()
+	st := s.Store
+	s.mu.RUnlock()
+	if st == nil {
+		return nil, fmt.Errorf("session store not initialized")
+	}
+	return st.ForkSnapshot(src)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 	"time"
 
@@ -76,10 +77,16 @@ func (s *Store) SessionPath(sessionID string) string {
 }
 
 func (s *Store) List() ([]*SessionMetadata, error) {
+	return s.ListRecent(0)
+}
+
+// ListRecent returns metadata for the n most recently updated sessions,
+// newest first. n <= 0 lists every session.
+func (s *Store) ListRecent(n int) ([]*SessionMetadata, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	items, err := s.transcriptStore.List(context.Background(), s.projectID, transcript.ListOptions{})
+	items, err := s.transcriptStore.List(context.Background(), s.projectID, transcript.ListOptions{Limit: n})
 	if err != nil {
 		return nil, err
 	}
@@ -92,6 +99,9 @@ func (s *Store) List() ([]*SessionMetadata, error) {
 	return out, nil
 }
 
+// GetLatest loads the most recently updated session. A session that can no
+// longer be loaded, e.g. one whose transcript was corrupted by a crash, is
+// skipped in favour of the next most recent one.
 func (s *Store) GetLatest() (*Snapshot, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -103,8 +113,17 @@ func (s *Store) GetLatest() (*Snapshot, error) {
 	if len(items) == 0 {
 		return nil, fmt.Errorf("no sessions found")
 	}
-	meta := transcript.MetadataFromListItem(items[0], s.cwd)
-	return s.loadSnapshot(context.Background(), meta.ID)
+	var firstErr error
+	for _, item := range items {
+		sess, err := s.loadSnapshot(context.Background(), item.TranscriptID)
+		if err == nil {
+			return sess, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
 }
 
 func (s *Store) Delete(id string) error {
@@ -172,6 +191,73 @@ func (s *Store) Fork(sourceID string) (*Snapshot, error) {
 	return forked, nil
 }
 
+// ForkSnapshot saves a deep copy of src as a new session whose parent is
+// src, leaving src untouched. Unlike Fork, which copies a saved transcript
+// as is, the copy holds exactly the entries in src, so callers can branch
+// from an earlier point by passing a truncated snapshot.
+func (s *Store) ForkSnapshot(src *Snapshot) (*Snapshot, error) {
+	if src == nil {
+		return nil, fmt.Errorf("session is nil")
+	}
+	forked := &Snapshot{
+		Metadata: SessionMetadata{
+			ID:              generateSessionID(),
+			CustomTitle:     src.Metadata.CustomTitle,
+			Mode:            src.Metadata.Mode,
+			Provider:        src.Metadata.Provider,
+			Model:           src.Metadata.Model,
+			Cwd:             src.Metadata.Cwd,
+			ParentSessionID: src.Metadata.ID,
+			InputTokens:     src.Metadata.InputTokens,
+			OutputTokens:    src.Metadata.OutputTokens,
+		},
+		Entries: cloneEntries(src.Entries),
+		Tasks:   slices.Clone(src.Tasks),
+	}
+	for i := range forked.Entries {
+		forked.Entries[i].SessionID = forked.Metadata.ID
+	}
+	if err := s.Save(forked); err != nil {
+		return nil, err
+	}
+	return forked, nil
+}
+
+func cloneEntries(entries []Entry) []Entry {
+	out := make([]Entry, len(entries))
+	for i, entry := range entries {
+		if entry.ParentUuid != nil {
+			parent := *entry.ParentUuid
+			entry.ParentUuid = &parent
+		}
+		if entry.Message != nil {
+			entry.Message = &EntryMessage{
+				Role:    entry.Message.Role,
+				Content: cloneContentBlocks(entry.Message.Content),
+			}
+		}
+		out[i] = entry
+	}
+	return out
+}
+
+func cloneContentBlocks(blocks []ContentBlock) []ContentBlock {
+	if blocks == nil {
+		return nil
+	}
+	out := make([]ContentBlock, len(blocks))
+	for i, block := range blocks {
+		block.Input = slices.Clone(block.Input)
+		block.Content = cloneContentBlocks(block.Content)
+		if block.Source != nil {
+			source := *block.Source
+			block.Source = &source
+		}
+		out[i] = block
+	}
+	return out
+}
+
 func (s *Store) PersistToolResult(sessionID, toolCallID, content string) error {
 	// Sanitize both sessionID and toolCallID to prevent path traversal
 	safeSessionID := filepath.Base(sessionID)
@@ -252,8 +338,27 @@ func (s *Store) loadSnapshot(ctx context.Context, sessionID string) (*Snapshot,
 	if tx == nil {
 		return nil, fmt.Errorf("session not found: %s", sessionID)
 	}
+	return snapshotFromTranscript(tx, s.toolResultsDir(tx.ID)), nil
+}
+
+// LoadFile reads a session from a transcript file outside the store, such
+// as one copied from another machine. Tool results saved next to it, in the
+// layout the store uses, are restored too.
+func LoadFile(path string) (*Snapshot, error) {
+	tx, err := transcript.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if tx.ID == "" || len(tx.Messages) == 0 {
+		return nil, fmt.Errorf("%s has no session messages", path)
+	}
+	projectDir := filepath.Dir(filepath.Dir(path))
+	return snapshotFromTranscript(tx, filepath.Join(projectDir, "blobs", "tool-result", tx.ID)), nil
+}
+
+func snapshotFromTranscript(tx *transcript.Transcript, toolResultsDir string) *Snapshot {
 	transcript.HydrateToolResultNodes(tx.ID, tx.Messages, func(toolCallID string) (string, error) {
-		data, err := os.ReadFile(filepath.Join(s.toolResultsDir(tx.ID), toolCallID))
+		data, err := os.ReadFile(filepath.Join(toolResultsDir, toolCallID))
 		if err != nil {
 			return "", err
 		}
@@ -271,5 +376,5 @@ func (s *Store) loadSnapshot(ctx context.Context, sessionID string) (*Snapshot,
 	if sess.Metadata.LastPrompt == "" {
 		sess.Metadata.LastPrompt = ExtractLastUserText(sess.Entries)
 	}
-	return sess, nil
+	return sess
 }
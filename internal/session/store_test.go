@@ -0,0 +1,62 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+func TestLoadFile(t *testing.T) {
+	store, err := NewStoreWithDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs := []core.ChatMessage{
+		{Role: core.RoleUser, Content: "list files"},
+		{Role: core.RoleAssistant, ToolCalls: []core.ToolCall{{ID: "call-1", Name: "Bash", Input: `{"command":"ls"}`}}},
+		{Role: core.RoleUser, ToolName: "Bash", ToolResult: &core.ToolResult{ToolCallID: "call-1", Content: "go.mod"}},
+		{Role: core.RoleAssistant, Content: "One file."},
+	}
+	snap := &Snapshot{Metadata: SessionMetadata{ID: "sess-1"}, Entries: ConvertToEntries(msgs)}
+	if err := store.Save(snap); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadFile(store.SessionPath("sess-1"))
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	got := ConvertFromEntries(loaded.Entries)
+	if loaded.Metadata.ID != "sess-1" || len(got) != len(msgs) {
+		t.Fatalf("loaded %q with %d messages, want sess-1 with %d", loaded.Metadata.ID, len(got), len(msgs))
+	}
+	if tc := got[1].ToolCalls; len(tc) != 1 || tc[0].Name != "Bash" || tc[0].ID != "call-1" {
+		t.Errorf("tool call = %+v", tc)
+	}
+	if tr := got[2].ToolResult; tr == nil || tr.ToolCallID != "call-1" || tr.Content != "go.mod" {
+		t.Errorf("tool result = %+v", tr)
+	}
+}
+
+func TestLoadFileRejectsMalformedFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"not-json.jsonl":    "this is not a transcript\n",
+		"empty.jsonl":       "",
+		"no-messages.jsonl": `{"id":"r1","transcriptId":"t1","type":"transcript.started"}` + "\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadFile(path); err == nil {
+			t.Errorf("LoadFile(%s) succeeded, want an error", name)
+		}
+	}
+	if _, err := LoadFile(filepath.Join(dir, "missing.jsonl")); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("missing file error = %v", err)
+	}
+}
@@ -11,10 +11,18 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yanmxa/gencode/internal/log"
 )
 
 const transcriptIndexFile = "transcripts-index.json"
 
+// transcriptIndexVersion is bumped when index entries gain fields, so that
+// older indexes are rebuilt from the transcripts instead of listing blanks.
+const transcriptIndexVersion = 3
+
 type FileStore struct {
 	mu        sync.RWMutex
 	baseDir   string
@@ -33,10 +41,14 @@ type fileIndexEntry struct {
 	CreatedAt    time.Time `json:"createdAt"`
 	UpdatedAt    time.Time `json:"updatedAt"`
 	Title        string    `json:"title,omitempty"`
+	CustomTitle  string    `json:"customTitle,omitempty"`
 	LastPrompt   string    `json:"lastPrompt,omitempty"`
 	MessageCount int       `json:"messageCount"`
 	GitBranch    string    `json:"gitBranch,omitempty"`
 	IsSidechain  bool      `json:"isSidechain,omitempty"`
+	Provider     string    `json:"provider,omitempty"`
+	Model        string    `json:"model,omitempty"`
+	ParentID     string    `json:"parentId,omitempty"`
 }
 
 func NewFileStore(baseDir, projectID string) (*FileStore, error) {
@@ -259,6 +271,11 @@ func (s *FileStore) Replace(ctx context.Context, cmd ReplaceCommand) error {
 			return fmt.Errorf("write transcript record: %w", err)
 		}
 	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("sync transcript file: %w", err)
+	}
 	if err := f.Close(); err != nil {
 		_ = os.Remove(tmpPath)
 		return fmt.Errorf("close transcript file: %w", err)
@@ -310,10 +327,14 @@ func (s *FileStore) List(ctx context.Context, projectID string, opts ListOptions
 			CreatedAt:    entry.CreatedAt,
 			UpdatedAt:    entry.UpdatedAt,
 			Title:        entry.Title,
+			CustomTitle:  entry.CustomTitle,
 			LastPrompt:   entry.LastPrompt,
 			MessageCount: entry.MessageCount,
 			GitBranch:    entry.GitBranch,
 			IsSidechain:  entry.IsSidechain,
+			Provider:     entry.Provider,
+			Model:        entry.Model,
+			ParentID:     entry.ParentID,
 		})
 	}
 
@@ -471,9 +492,11 @@ func recordsForTranscript(tx Transcript) ([]Record, error) {
 
 	ops := []PatchOp{
 		PatchTitle(tx.State.Title),
+		PatchCustomTitle(tx.State.CustomTitle),
 		PatchLastPrompt(tx.State.LastPrompt),
 		patchTag(tx.State.Tag),
 		patchMode(tx.State.Mode),
+		patchTokens(tx.State.Tokens),
 	}
 	if len(tx.State.Tasks) > 0 {
 		ops = append(ops, PatchTasks(TrackerTasksFromView(tx.State.Tasks)))
@@ -521,6 +544,23 @@ func (s *FileStore) messageExistsLocked(path, messageID string) (bool, error) {
 }
 
 func (s *FileStore) loadRecordsLocked(path string) ([]Record, error) {
+	return readRecords(path)
+}
+
+// LoadFile reads a transcript from a JSONL file outside any store, such as
+// one copied from another machine or project.
+func LoadFile(path string) (*Transcript, error) {
+	records, err := readRecords(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no transcript records in %s", path)
+	}
+	return Project(records)
+}
+
+func readRecords(path string) ([]Record, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -531,6 +571,9 @@ func (s *FileStore) loadRecordsLocked(path string) ([]Record, error) {
 	defer f.Close()
 
 	var records []Record
+	// A record that fails to decode is only tolerated as the last line: that
+	// is an append torn by a crash, and the records before it are intact.
+	var decodeErr error
 	scanner := bufio.NewScanner(f)
 	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
 	for scanner.Scan() {
@@ -538,15 +581,22 @@ func (s *FileStore) loadRecordsLocked(path string) ([]Record, error) {
 		if line == "" {
 			continue
 		}
+		if decodeErr != nil {
+			return nil, decodeErr
+		}
 		var rec Record
 		if err := json.Unmarshal([]byte(line), &rec); err != nil {
-			return nil, fmt.Errorf("decode transcript record: %w", err)
+			decodeErr = fmt.Errorf("decode transcript record: %w", err)
+			continue
 		}
 		records = append(records, rec)
 	}
 	if err := scanner.Err(); err != nil {
 		return nil, fmt.Errorf("scan transcript file: %w", err)
 	}
+	if decodeErr != nil {
+		log.Logger().Warn("transcript: dropping torn trailing record", zap.String("path", path), zap.Error(decodeErr))
+	}
 	return records, nil
 }
 
@@ -559,6 +609,9 @@ func (s *FileStore) loadIndexLocked() (*fileIndex, error) {
 	if err := json.Unmarshal(data, &index); err != nil {
 		return nil, err
 	}
+	if index.Version < transcriptIndexVersion {
+		return nil, fmt.Errorf("transcript index version %d is outdated", index.Version)
+	}
 	return &index, nil
 }
 
@@ -586,7 +639,7 @@ func (s *FileStore) rebuildIndexLocked() error {
 	}
 
 	index := &fileIndex{
-		Version:   1,
+		Version:   transcriptIndexVersion,
 		ProjectID: s.projectID,
 		Entries:   make([]fileIndexEntry, 0, len(entries)),
 	}
@@ -599,17 +652,7 @@ func (s *FileStore) rebuildIndexLocked() error {
 		if err != nil {
 			continue
 		}
-		index.Entries = append(index.Entries, fileIndexEntry{
-			TranscriptID: item.TranscriptID,
-			FullPath:     item.FullPath,
-			CreatedAt:    item.CreatedAt,
-			UpdatedAt:    item.UpdatedAt,
-			Title:        item.Title,
-			LastPrompt:   item.LastPrompt,
-			MessageCount: item.MessageCount,
-			GitBranch:    item.GitBranch,
-			IsSidechain:  item.IsSidechain,
-		})
+		index.Entries = append(index.Entries, indexEntryFromListItem(item))
 	}
 	return s.saveIndexLocked(index)
 }
@@ -617,10 +660,9 @@ func (s *FileStore) rebuildIndexLocked() error {
 func (s *FileStore) refreshIndexLocked(transcriptID string) error {
 	index, err := s.loadIndexLocked()
 	if err != nil {
-		index = &fileIndex{
-			Version:   1,
-			ProjectID: s.projectID,
-		}
+		// A missing or outdated index is rebuilt from every transcript, which
+		// includes this one.
+		return s.rebuildIndexLocked()
 	}
 
 	item, err := s.buildListItemLocked(transcriptID)
@@ -628,26 +670,34 @@ func (s *FileStore) refreshIndexLocked(transcriptID string) error {
 		return err
 	}
 
-	entry := fileIndexEntry{
+	entry := indexEntryFromListItem(item)
+
+	for i := range index.Entries {
+		if index.Entries[i].TranscriptID == transcriptID {
+			index.Entries[i] = entry
+			return s.saveIndexLocked(index)
+		}
+	}
+	index.Entries = append(index.Entries, entry)
+	return s.saveIndexLocked(index)
+}
+
+func indexEntryFromListItem(item ListItem) fileIndexEntry {
+	return fileIndexEntry{
 		TranscriptID: item.TranscriptID,
 		FullPath:     item.FullPath,
 		CreatedAt:    item.CreatedAt,
 		UpdatedAt:    item.UpdatedAt,
 		Title:        item.Title,
+		CustomTitle:  item.CustomTitle,
 		LastPrompt:   item.LastPrompt,
 		MessageCount: item.MessageCount,
 		GitBranch:    item.GitBranch,
 		IsSidechain:  item.IsSidechain,
+		Provider:     item.Provider,
+		Model:        item.Model,
+		ParentID:     item.ParentID,
 	}
-
-	for i := range index.Entries {
-		if index.Entries[i].TranscriptID == transcriptID {
-			index.Entries[i] = entry
-			return s.saveIndexLocked(index)
-		}
-	}
-	index.Entries = append(index.Entries, entry)
-	return s.saveIndexLocked(index)
 }
 
 func (s *FileStore) buildListItemLocked(transcriptID string) (ListItem, error) {
@@ -660,7 +710,7 @@ func (s *FileStore) buildListItemLocked(transcriptID string) (ListItem, error) {
 		return ListItem{}, err
 	}
 
-	title := transcript.State.Title
+	title := coalesce(transcript.State.CustomTitle, transcript.State.Title)
 	if title == "" {
 		title = firstUserText(transcript.Messages)
 	}
@@ -671,10 +721,14 @@ func (s *FileStore) buildListItemLocked(transcriptID string) (ListItem, error) {
 		CreatedAt:    transcript.CreatedAt,
 		UpdatedAt:    transcript.UpdatedAt,
 		Title:        title,
+		CustomTitle:  transcript.State.CustomTitle,
 		LastPrompt:   coalesce(transcript.State.LastPrompt, lastUserText(transcript.Messages)),
 		MessageCount: len(transcript.Messages),
 		GitBranch:    lastGitBranch(transcript.Messages),
 		IsSidechain:  anySidechain(transcript.Messages),
+		Provider:     transcript.Provider,
+		Model:        transcript.Model,
+		ParentID:     transcript.ParentID,
 	}, nil
 }
 
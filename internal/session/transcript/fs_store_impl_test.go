@@ -2,6 +2,8 @@ package transcript
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -65,6 +67,21 @@ func TestFileStoreStartAppendListLoad(t *testing.T) {
 	if items[0].LastPrompt != "hello" {
 		t.Fatalf("LastPrompt = %q, want %q", items[0].LastPrompt, "hello")
 	}
+	if items[0].Provider != "openai" || items[0].Model != "gpt-test" {
+		t.Fatalf("Provider/Model = %q/%q, want openai/gpt-test", items[0].Provider, items[0].Model)
+	}
+
+	// An index written before entries carried the model is rebuilt on list.
+	if err := os.WriteFile(filepath.Join(dir, transcriptIndexFile), []byte(`{"version":1,"entries":[{"transcriptId":"tx-1","title":"stale"}]}`), 0o644); err != nil {
+		t.Fatalf("WriteFile(index): %v", err)
+	}
+	items, err = store.List(context.Background(), "proj-1", ListOptions{})
+	if err != nil {
+		t.Fatalf("List() after outdated index: %v", err)
+	}
+	if len(items) != 1 || items[0].Title != "Fix bug" || items[0].Model != "gpt-test" {
+		t.Fatalf("List() after outdated index = %+v", items)
+	}
 
 	transcript, err := store.Load(context.Background(), "tx-1")
 	if err != nil {
@@ -143,6 +160,20 @@ func TestFileStoreCompactAndFork(t *testing.T) {
 	if forked.ParentID != "tx-1" {
 		t.Fatalf("fork ParentID = %q, want %q", forked.ParentID, "tx-1")
 	}
+
+	items, err := store.List(context.Background(), "proj-1", ListOptions{})
+	if err != nil {
+		t.Fatalf("List(): %v", err)
+	}
+	for _, item := range items {
+		want := ""
+		if item.TranscriptID == "tx-2" {
+			want = "tx-1"
+		}
+		if item.ParentID != want {
+			t.Errorf("%s ParentID = %q, want %q", item.TranscriptID, item.ParentID, want)
+		}
+	}
 }
 
 func TestFileStoreReplace(t *testing.T) {
@@ -168,6 +199,7 @@ func TestFileStoreReplace(t *testing.T) {
 			State: State{
 				Title:      "Saved via replace",
 				LastPrompt: "hello",
+				Tokens:     TokenUsage{Input: 1200, Output: 80},
 			},
 		},
 	})
@@ -185,4 +217,58 @@ func TestFileStoreReplace(t *testing.T) {
 	if len(transcript.Messages) != 2 {
 		t.Fatalf("len(Messages) = %d, want 2", len(transcript.Messages))
 	}
+	if transcript.State.Tokens != (TokenUsage{Input: 1200, Output: 80}) {
+		t.Fatalf("Tokens = %+v", transcript.State.Tokens)
+	}
+}
+
+func TestFileStoreLoadToleratesTornTrailingRecord(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir, "proj-1")
+	if err != nil {
+		t.Fatalf("NewFileStore(): %v", err)
+	}
+
+	now := time.Date(2026, 4, 6, 16, 20, 0, 0, time.UTC)
+	err = store.Replace(context.Background(), ReplaceCommand{
+		Transcript: Transcript{
+			ID:        "tx-1",
+			CreatedAt: now,
+			Messages: []Node{
+				{ID: "m1", Role: "user", Time: now, Content: []ContentBlock{{Type: "text", Text: "hello"}}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Replace(): %v", err)
+	}
+
+	path := store.TranscriptPath("tx-1")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatalf("OpenFile(): %v", err)
+	}
+	if _, err := f.WriteString(`{"id":"tx-1:m2","transcriptId":"tx-1","type":"message.app`); err != nil {
+		t.Fatalf("WriteString(): %v", err)
+	}
+	f.Close()
+
+	transcript, err := store.Load(context.Background(), "tx-1")
+	if err != nil {
+		t.Fatalf("Load() with torn trailing record: %v", err)
+	}
+	if len(transcript.Messages) != 1 {
+		t.Fatalf("len(Messages) = %d, want 1", len(transcript.Messages))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile(): %v", err)
+	}
+	if err := os.WriteFile(path, append([]byte("not json\n"), data...), 0o644); err != nil {
+		t.Fatalf("WriteFile(): %v", err)
+	}
+	if _, err := store.Load(context.Background(), "tx-1"); err == nil {
+		t.Fatal("Load() with a corrupt record before valid ones should fail")
+	}
 }
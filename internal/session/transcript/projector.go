@@ -126,6 +126,12 @@ func applyStatePatch(state *State, patch *StateRecord) error {
 				return fmt.Errorf("patch %s: %w", op.Path, err)
 			}
 			state.Title = v
+		case PatchPathCustomTitle:
+			var v string
+			if err := json.Unmarshal(op.Value, &v); err != nil {
+				return fmt.Errorf("patch %s: %w", op.Path, err)
+			}
+			state.CustomTitle = v
 		case PatchPathLastPrompt:
 			var v string
 			if err := json.Unmarshal(op.Value, &v); err != nil {
@@ -160,6 +166,12 @@ func applyStatePatch(state *State, patch *StateRecord) error {
 				return fmt.Errorf("patch %s: %w", op.Path, err)
 			}
 			state.Worktree = &wt
+		case PatchPathTokens:
+			var v TokenUsage
+			if err := json.Unmarshal(op.Value, &v); err != nil {
+				return fmt.Errorf("patch %s: %w", op.Path, err)
+			}
+			state.Tokens = v
 		default:
 			return fmt.Errorf("unknown state patch path: %s", op.Path)
 		}
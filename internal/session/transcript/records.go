@@ -14,12 +14,14 @@ const (
 )
 
 const (
-	PatchPathTitle      = "title"
-	PatchPathLastPrompt = "lastPrompt"
-	PatchPathTag        = "tag"
-	PatchPathMode       = "mode"
-	PatchPathTasks      = "tasks"
-	PatchPathWorktree   = "worktree"
+	PatchPathTitle       = "title"
+	PatchPathCustomTitle = "customTitle"
+	PatchPathLastPrompt  = "lastPrompt"
+	PatchPathTag         = "tag"
+	PatchPathMode        = "mode"
+	PatchPathTasks       = "tasks"
+	PatchPathWorktree    = "worktree"
+	PatchPathTokens      = "tokens"
 )
 
 type Record struct {
@@ -87,6 +89,13 @@ type ImageSource struct {
 	Data      string `json:"data"`
 }
 
+// TokenUsage is the token count of the latest request in a transcript, saved
+// so a resumed session shows the same context usage it ended with.
+type TokenUsage struct {
+	Input  int `json:"input"`
+	Output int `json:"output"`
+}
+
 type WorktreeState struct {
 	OriginalCwd    string `json:"originalCwd"`
 	WorktreePath   string `json:"worktreePath"`
@@ -54,4 +54,7 @@ func TestPatchPathConstantsAreStable(t *testing.T) {
 	if PatchPathWorktree != "worktree" {
 		t.Fatalf("PatchPathWorktree = %q", PatchPathWorktree)
 	}
+	if PatchPathTokens != "tokens" {
+		t.Fatalf("PatchPathTokens = %q", PatchPathTokens)
+	}
 }
@@ -64,6 +64,12 @@ func PatchTitle(title string) PatchOp {
 	return mustPatch(PatchPathTitle, title)
 }
 
+// PatchCustomTitle sets a user-chosen title that takes precedence over the
+// generated one.
+func PatchCustomTitle(title string) PatchOp {
+	return mustPatch(PatchPathCustomTitle, title)
+}
+
 func PatchLastPrompt(prompt string) PatchOp {
 	return mustPatch(PatchPathLastPrompt, prompt)
 }
@@ -84,6 +90,10 @@ func patchWorktree(worktree *WorktreeState) PatchOp {
 	return mustPatch(PatchPathWorktree, worktree)
 }
 
+func patchTokens(usage TokenUsage) PatchOp {
+	return mustPatch(PatchPathTokens, usage)
+}
+
 func mustPatch(path string, v any) PatchOp {
 	data, err := json.Marshal(v)
 	if err != nil {
@@ -29,10 +29,12 @@ type Node struct {
 }
 
 type State struct {
-	Title      string
-	LastPrompt string
-	Tag        string
-	Mode       string
+	Title       string
+	CustomTitle string
+	LastPrompt  string
+	Tag         string
+	Mode        string
+	Tokens      TokenUsage
 
 	Tasks    []TrackerTaskView
 	Worktree *WorktreeState
@@ -59,9 +61,13 @@ type ListItem struct {
 	UpdatedAt    time.Time
 
 	Title        string
+	CustomTitle  string
 	LastPrompt   string
 	MessageCount int
 	GitBranch    string
+	Provider     string
+	Model        string
+	ParentID     string
 
 	IsSidechain bool
 }
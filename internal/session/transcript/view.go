@@ -9,6 +9,7 @@ import (
 type MetadataView struct {
 	ID              string
 	Title           string
+	CustomTitle     string
 	LastPrompt      string
 	Tag             string
 	Mode            string
@@ -19,6 +20,8 @@ type MetadataView struct {
 	Cwd             string
 	MessageCount    int
 	ParentSessionID string
+	InputTokens     int
+	OutputTokens    int
 }
 
 func MetadataFromTranscript(t *Transcript) MetadataView {
@@ -28,6 +31,7 @@ func MetadataFromTranscript(t *Transcript) MetadataView {
 	return MetadataView{
 		ID:              t.ID,
 		Title:           t.State.Title,
+		CustomTitle:     t.State.CustomTitle,
 		LastPrompt:      t.State.LastPrompt,
 		Tag:             t.State.Tag,
 		Mode:            t.State.Mode,
@@ -38,18 +42,24 @@ func MetadataFromTranscript(t *Transcript) MetadataView {
 		Cwd:             t.Cwd,
 		MessageCount:    len(t.Messages),
 		ParentSessionID: t.ParentID,
+		InputTokens:     t.State.Tokens.Input,
+		OutputTokens:    t.State.Tokens.Output,
 	}
 }
 
 func MetadataFromListItem(item ListItem, cwd string) MetadataView {
 	return MetadataView{
-		ID:           item.TranscriptID,
-		Title:        item.Title,
-		LastPrompt:   item.LastPrompt,
-		CreatedAt:    item.CreatedAt,
-		UpdatedAt:    item.UpdatedAt,
-		Cwd:          cwd,
-		MessageCount: item.MessageCount,
+		ID:              item.TranscriptID,
+		Title:           item.Title,
+		CustomTitle:     item.CustomTitle,
+		LastPrompt:      item.LastPrompt,
+		CreatedAt:       item.CreatedAt,
+		UpdatedAt:       item.UpdatedAt,
+		Provider:        item.Provider,
+		Model:           item.Model,
+		Cwd:             cwd,
+		MessageCount:    item.MessageCount,
+		ParentSessionID: item.ParentID,
 	}
 }
 
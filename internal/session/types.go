@@ -23,6 +23,9 @@ func GetAppVersion() string {
 const (
 	EntryUser      = "user"
 	EntryAssistant = "assistant"
+	// EntryContext holds a context note. It is stored under its own type so
+	// the note keeps its role when the session is restored.
+	EntryContext = "context"
 )
 
 type ContentBlock = transcript.ContentBlock
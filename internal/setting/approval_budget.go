@@ -0,0 +1,87 @@
+package setting
+
+import (
+	"sort"
+	"sync"
+)
+
+// AnyToolClass is the ApprovalBudget class that matches every tool.
+const AnyToolClass = "*"
+
+// ToolClass groups tools that a single pre-approval should cover together.
+// File edits share one class so "/approve 5 Edit" also covers Write.
+func ToolClass(toolName string) string {
+	switch toolName {
+	case "Edit", "Write", "MultiEdit", "NotebookEdit":
+		return "Edit"
+	default:
+		return toolName
+	}
+}
+
+// ApprovalBudget counts tool calls the user pre-approved with /approve.
+// Each auto-approved call decrements its class; at zero prompting resumes.
+// It is safe for concurrent use since permission checks run on the agent
+// goroutine while the TUI grants budget.
+type ApprovalBudget struct {
+	mu        sync.Mutex
+	remaining map[string]int
+}
+
+// Grant sets the number of pre-approved calls for class (AnyToolClass for all tools).
+func (b *ApprovalBudget) Grant(class string, n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.remaining == nil {
+		b.remaining = make(map[string]int)
+	}
+	if n <= 0 {
+		delete(b.remaining, class)
+		return
+	}
+	b.remaining[class] = n
+}
+
+// Consume spends one pre-approval for toolName, preferring its own class over
+// AnyToolClass. It returns the calls left in that class and whether one was spent.
+func (b *ApprovalBudget) Consume(toolName string) (left int, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, class := range []string{ToolClass(toolName), AnyToolClass} {
+		if n := b.remaining[class]; n > 0 {
+			n--
+			if n == 0 {
+				delete(b.remaining, class)
+			} else {
+				b.remaining[class] = n
+			}
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// Remaining returns the outstanding pre-approvals keyed by class, sorted by class.
+func (b *ApprovalBudget) Remaining() []ApprovalGrant {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	grants := make([]ApprovalGrant, 0, len(b.remaining))
+	for class, n := range b.remaining {
+		grants = append(grants, ApprovalGrant{Class: class, Count: n})
+	}
+	sort.Slice(grants, func(i, j int) bool { return grants[i].Class < grants[j].Class })
+	return grants
+}
+
+// Clear drops all outstanding pre-approvals.
+func (b *ApprovalBudget) Clear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.remaining = nil
+}
+
+// ApprovalGrant is one class's outstanding pre-approval count.
+type ApprovalGrant struct {
+	Class string
+	Count int
+}
@@ -0,0 +1,149 @@
+package setting
+
+import (
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Bash allow and deny lists (permissions.bashAllow / permissions.bashDeny)
+// hold patterns matched against the commands inside a Bash call rather than
+// the call as a whole:
+//
+//   - A pattern without * or ? is a command prefix that ends on a word
+//     boundary: "git status" matches "git status -s" but not "git statuses".
+//   - Otherwise it is a glob over the command text where * matches anything,
+//     e.g. "npm run *" or "curl * | sh".
+//
+// A deny pattern matching any command in the call, or any pipeline such as
+// "curl -s x | sh", denies it. An allow pattern only approves a call when
+// every command in it is allowed, so "git status && rm -rf bar" is not
+// approved by "git status". Deny wins over allow.
+
+// bashDenyPattern returns the bashDeny pattern that matches cmd, or "".
+func (s *Settings) bashDenyPattern(cmd string) string {
+	if len(s.Permissions.BashDeny) == 0 {
+		return ""
+	}
+	candidates := bashStatements(cmd)
+	for _, sub := range bashSubcommands(cmd) {
+		candidates = append(candidates, sub.String())
+	}
+	for _, pattern := range s.Permissions.BashDeny {
+		for _, candidate := range candidates {
+			if matchBashPattern(candidate, pattern) {
+				return pattern
+			}
+		}
+	}
+	return ""
+}
+
+// bashAllowPattern returns a bashAllow pattern that approves cmd, or "" when
+// any command in it is not allowed. Calls that cannot be parsed, redirect
+// output, or run code outside the simple commands matched here are never
+// approved by the list.
+func (s *Settings) bashAllowPattern(cmd string) string {
+	if len(s.Permissions.BashAllow) == 0 {
+		return ""
+	}
+	if file := parseBashAST(cmd); file == nil || hasHiddenBashCode(file) {
+		return ""
+	}
+	subs := bashSubcommands(cmd)
+	if len(subs) > 1 {
+		// cd only changes where the other commands run.
+		filtered := subs[:0:0]
+		for _, sub := range subs {
+			if sub.Name != "cd" {
+				filtered = append(filtered, sub)
+			}
+		}
+		if len(filtered) > 0 {
+			subs = filtered
+		}
+	}
+
+	var first string
+	for _, sub := range subs {
+		if len(sub.RedirPaths) > 0 {
+			return ""
+		}
+		pattern := allowingPattern(sub.String(), s.Permissions.BashAllow)
+		if pattern == "" {
+			return ""
+		}
+		if first == "" {
+			first = pattern
+		}
+	}
+	return first
+}
+
+// hasHiddenBashCode reports whether file can run code that is not one of its
+// simple commands' words: command or process substitution, assignments
+// (which may hold a substitution or change how a command runs), [[ ]] and
+// (( )) expressions, and for/case header words.
+func hasHiddenBashCode(file *syntax.File) bool {
+	found := false
+	syntax.Walk(file, func(node syntax.Node) bool {
+		switch node.(type) {
+		case *syntax.CmdSubst, *syntax.ProcSubst, *syntax.Assign,
+			*syntax.TestClause, *syntax.ArithmCmd, *syntax.ForClause, *syntax.CaseClause:
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func allowingPattern(cmd string, patterns []string) string {
+	for _, pattern := range patterns {
+		if matchBashPattern(cmd, pattern) {
+			return pattern
+		}
+	}
+	return ""
+}
+
+// bashSubcommands returns the simple commands in cmd, from the AST when it
+// parses and from splitting on && and ; otherwise.
+func bashSubcommands(cmd string) []parsedCommand {
+	if file := parseBashAST(cmd); file != nil {
+		return extractCommandsAST(file)
+	}
+	var subs []parsedCommand
+	for _, part := range extractBashCommands(cmd) {
+		fields := strings.Fields(part)
+		subs = append(subs, parsedCommand{Name: fields[0], Args: fields[1:]})
+	}
+	return subs
+}
+
+// bashStatements splits cmd on &&, ||, ; and newlines, keeping pipelines
+// whole with their pipes spaced out, so patterns like "curl * | sh" can
+// match.
+func bashStatements(cmd string) []string {
+	cmd = strings.NewReplacer("&&", "\n", "||", "\n", ";", "\n", "|", " | ").Replace(cmd)
+	var statements []string
+	for line := range strings.SplitSeq(cmd, "\n") {
+		if s := strings.Join(strings.Fields(line), " "); s != "" {
+			statements = append(statements, s)
+		}
+	}
+	return statements
+}
+
+// matchBashPattern reports whether a command matches a bashAllow or bashDeny
+// pattern.
+func matchBashPattern(cmd, pattern string) bool {
+	cmd = strings.Join(strings.Fields(cmd), " ")
+	pattern = strings.Join(strings.Fields(pattern), " ")
+	if pattern == "" {
+		return false
+	}
+	if strings.ContainsAny(pattern, "*?") {
+		return matchSimpleWildcard(cmd, pattern)
+	}
+	return cmd == pattern || strings.HasPrefix(cmd, pattern+" ")
+}
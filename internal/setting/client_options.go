@@ -23,6 +23,10 @@ func DefaultModel(providerName string, authMethod string) string {
 		return "qwen-plus"
 	case "minmax":
 		return "MiniMax-M2.7"
+	case "deepseek":
+		return "deepseek-chat"
+	case "ollama":
+		return "llama3.1"
 	default:
 		return "claude-sonnet-4-20250514"
 	}
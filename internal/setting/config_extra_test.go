@@ -2,9 +2,12 @@ package setting
 
 import (
 	"context"
+	"encoding/json"
+	"maps"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 // TestConfig_LocalOverridesProject verifies that settings.local.json takes
@@ -316,3 +319,315 @@ func TestConfig_UserLevelOverriddenByProject(t *testing.T) {
 		t.Errorf("Expected FROM='project' to override user env, got %q", settings.Env["FROM"])
 	}
 }
+
+// TestConfig_NoTelemetry verifies that GEN_NO_TELEMETRY=1 disables background
+// network activity even when the setting is absent.
+func TestConfig_NoTelemetry(t *testing.T) {
+	enabled := true
+	tests := []struct {
+		name     string
+		env      string
+		settings *Settings
+		want     bool
+	}{
+		{"default", "", NewSettings(), false},
+		{"setting", "", &Settings{NoTelemetry: &enabled}, true},
+		{"env", "1", NewSettings(), true},
+		{"env other value", "0", NewSettings(), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(NoTelemetryEnv, tt.env)
+			svc := &settingsService{settings: tt.settings}
+			if got := svc.NoTelemetry(); got != tt.want {
+				t.Errorf("NoTelemetry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestConfig_TerminalTitle verifies the terminal title defaults to on and can
+// be turned off by the setting or GEN_NO_TERMINAL_TITLE=1.
+func TestConfig_TerminalTitle(t *testing.T) {
+	disabled := false
+	tests := []struct {
+		name     string
+		env      string
+		settings *Settings
+		want     bool
+	}{
+		{"default", "", NewSettings(), true},
+		{"setting", "", &Settings{TerminalTitle: &disabled}, false},
+		{"env", "1", NewSettings(), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(NoTerminalTitleEnv, tt.env)
+			svc := &settingsService{settings: tt.settings}
+			if got := svc.TerminalTitle(); got != tt.want {
+				t.Errorf("TerminalTitle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestConfig_MCPSampling verifies MCP sampling defaults to on and that a
+// project setting can turn it off.
+func TestConfig_MCPSampling(t *testing.T) {
+	if svc := (&settingsService{settings: NewSettings()}); !svc.MCPSampling() {
+		t.Error("MCPSampling() should default to true")
+	}
+	off := false
+	merged := mergeSettings(NewSettings(), &Settings{MCPSampling: &off})
+	if svc := (&settingsService{settings: merged.Clone()}); svc.MCPSampling() {
+		t.Error("MCPSampling() should be false when a settings file disables it")
+	}
+}
+
+// TestConfig_SemanticMemory verifies semantic memory is opt-in.
+func TestConfig_SemanticMemory(t *testing.T) {
+	if svc := (&settingsService{settings: NewSettings()}); svc.SemanticMemory() {
+		t.Error("SemanticMemory() should default to false")
+	}
+	on := true
+	merged := mergeSettings(NewSettings(), &Settings{SemanticMemory: &on})
+	if svc := (&settingsService{settings: merged.Clone()}); !svc.SemanticMemory() {
+		t.Error("SemanticMemory() should be true when a settings file enables it")
+	}
+}
+
+// TestConfig_ToolTimeouts verifies toolTimeouts merge by tool name and that
+// unset tools report no setting.
+func TestConfig_ToolTimeouts(t *testing.T) {
+	user := &Settings{ToolTimeouts: map[string]int{"Bash": 300, "WebFetch": 10}}
+	project := &Settings{ToolTimeouts: map[string]int{"WebFetch": 0}}
+	merged := mergeSettings(mergeSettings(NewSettings(), user), project)
+	svc := &settingsService{settings: merged.Clone()}
+
+	if d, ok := svc.ToolTimeout("Bash"); !ok || d != 300*time.Second {
+		t.Errorf("Bash = %v, %v; want 300s from user settings", d, ok)
+	}
+	if d, ok := svc.ToolTimeout("WebFetch"); !ok || d != 0 {
+		t.Errorf("WebFetch = %v, %v; want 0 (no limit) from project settings", d, ok)
+	}
+	if _, ok := svc.ToolTimeout("Read"); ok {
+		t.Error("Read has no setting")
+	}
+}
+
+// TestConfig_ShowCost verifies the status bar cost is shown unless disabled.
+func TestConfig_ShowCost(t *testing.T) {
+	if svc := (&settingsService{settings: NewSettings()}); !svc.ShowCost() {
+		t.Error("ShowCost() should default to true")
+	}
+	off := false
+	merged := mergeSettings(NewSettings(), &Settings{ShowCost: &off})
+	if svc := (&settingsService{settings: merged.Clone()}); svc.ShowCost() {
+		t.Error("ShowCost() should be false when a settings file disables it")
+	}
+}
+
+// TestConfig_ShowThinking verifies reasoning is shown unless disabled.
+func TestConfig_ShowThinking(t *testing.T) {
+	if svc := (&settingsService{settings: NewSettings()}); !svc.ShowThinking() {
+		t.Error("ShowThinking() should default to true")
+	}
+	off := false
+	merged := mergeSettings(NewSettings(), &Settings{ShowThinking: &off})
+	if svc := (&settingsService{settings: merged.Clone()}); svc.ShowThinking() {
+		t.Error("ShowThinking() should be false when a settings file disables it")
+	}
+}
+
+// TestConfig_ShowPromptModel verifies the prompt model indicator is opt-in.
+func TestConfig_ShowPromptModel(t *testing.T) {
+	if svc := (&settingsService{settings: NewSettings()}); svc.ShowPromptModel() {
+		t.Error("ShowPromptModel() should default to false")
+	}
+	on := true
+	merged := mergeSettings(NewSettings(), &Settings{ShowPromptModel: &on})
+	if svc := (&settingsService{settings: merged.Clone()}); !svc.ShowPromptModel() {
+		t.Error("ShowPromptModel() should be true when a settings file enables it")
+	}
+}
+
+// TestConfig_Keybindings verifies keybindings merge by action.
+func TestConfig_Keybindings(t *testing.T) {
+	user := &Settings{Keybindings: map[string]string{"send": "ctrl+s", "stop": "ctrl+g"}}
+	project := &Settings{Keybindings: map[string]string{"stop": "esc"}}
+	merged := mergeSettings(mergeSettings(NewSettings(), user), project).Clone()
+
+	want := map[string]string{"send": "ctrl+s", "stop": "esc"}
+	if !maps.Equal(merged.Keybindings, want) {
+		t.Errorf("Keybindings = %v, want %v", merged.Keybindings, want)
+	}
+}
+
+func TestConfig_WorkspacesMergeAndClone(t *testing.T) {
+	user := &Settings{Workspaces: map[string]Workspace{
+		"review":   {Model: "user-model", Tools: []string{"Read"}},
+		"research": {Mode: "auto", Tools: []string{"WebFetch"}},
+	}}
+	project := &Settings{Workspaces: map[string]Workspace{
+		"review": {Model: "project-model", MCPServers: []string{}},
+	}}
+
+	merged := mergeSettings(user, project)
+	if got := merged.Workspaces["review"].Model; got != "project-model" {
+		t.Errorf("review model = %q, want project override", got)
+	}
+	if _, ok := merged.Workspaces["research"]; !ok {
+		t.Error("research workspace from user settings was dropped")
+	}
+
+	cloned := merged.Clone()
+	if cloned.Workspaces["review"].MCPServers == nil {
+		t.Error("Clone turned an empty mcpServers list into nil")
+	}
+	cloned.Workspaces["research"].Tools[0] = "Bash"
+	if merged.Workspaces["research"].Tools[0] != "WebFetch" {
+		t.Error("Clone shares workspace slices with the original")
+	}
+}
+
+func TestParseOperationMode(t *testing.T) {
+	tests := map[string]OperationMode{
+		"":            ModeNormal,
+		"normal":      ModeNormal,
+		"auto":        ModeAutoAccept,
+		"acceptEdits": ModeAutoAccept,
+		"bypass":      ModeBypassPermissions,
+		"dontAsk":     ModeDontAsk,
+	}
+	for name, want := range tests {
+		got, err := ParseOperationMode(name)
+		if err != nil || got != want {
+			t.Errorf("ParseOperationMode(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseOperationMode("yolo"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
+
+// TestConfig_EditorSettings verifies the autoCompactThreshold, defaultMode,
+// maxTokens, bashMaxOutput, maxToolParallel, and imageMaxSize settings merge
+// across levels and fall back when invalid.
+func TestConfig_EditorSettings(t *testing.T) {
+	svc := &settingsService{settings: NewSettings()}
+	if svc.AutoCompactThreshold() != 0 || svc.DefaultMode() != ModeNormal || svc.MaxTokens() != 0 {
+		t.Error("editor settings should default to unset")
+	}
+
+	eighty, ninety, off, twenty := 80, 90, 0, 20
+	user := &Settings{AutoCompactThreshold: &eighty, DefaultMode: "auto", MaxTokens: 4096}
+	project := &Settings{AutoCompactThreshold: &ninety}
+	svc = &settingsService{settings: mergeSettings(user, project).Clone()}
+	if got := svc.AutoCompactThreshold(); got != 90 {
+		t.Errorf("AutoCompactThreshold() = %d, want project override 90", got)
+	}
+	if got := svc.DefaultMode(); got != ModeAutoAccept {
+		t.Errorf("DefaultMode() = %v, want auto-accept", got)
+	}
+	if got := svc.MaxTokens(); got != 4096 {
+		t.Errorf("MaxTokens() = %d, want 4096", got)
+	}
+	svc = &settingsService{settings: mergeSettings(&Settings{BashMaxOutput: 50000}, &Settings{}).Clone()}
+	if got := svc.BashMaxOutput(); got != 50000 {
+		t.Errorf("BashMaxOutput() = %d, want 50000", got)
+	}
+	if got := (&settingsService{settings: &Settings{BashMaxOutput: -1}}).BashMaxOutput(); got != 0 {
+		t.Errorf("BashMaxOutput() = %d for a negative setting, want 0", got)
+	}
+	svc = &settingsService{settings: mergeSettings(&Settings{MaxToolParallel: 2}, &Settings{MaxToolParallel: 4}).Clone()}
+	if got := svc.MaxToolParallel(); got != 4 {
+		t.Errorf("MaxToolParallel() = %d, want project override 4", got)
+	}
+	svc = &settingsService{settings: mergeSettings(&Settings{ImageMaxSize: 1 << 20}, &Settings{}).Clone()}
+	if got := svc.ImageMaxSize(); got != 1<<20 {
+		t.Errorf("ImageMaxSize() = %d, want 1048576", got)
+	}
+
+	allow := true
+	tests := []struct {
+		name     string
+		settings *Settings
+		want     OperationMode
+	}{
+		{"bypass without allowBypass", &Settings{DefaultMode: "bypass"}, ModeNormal},
+		{"bypass with allowBypass", &Settings{DefaultMode: "bypass", AllowBypass: &allow}, ModeBypassPermissions},
+		{"dontAsk", &Settings{DefaultMode: "dontAsk"}, ModeNormal},
+		{"unknown", &Settings{DefaultMode: "yolo"}, ModeNormal},
+	}
+	for _, tt := range tests {
+		if got := (&settingsService{settings: tt.settings}).DefaultMode(); got != tt.want {
+			t.Errorf("%s: DefaultMode() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if got := (&settingsService{settings: &Settings{AutoCompactThreshold: &twenty}}).AutoCompactThreshold(); got != 0 {
+		t.Errorf("out-of-range AutoCompactThreshold() = %d, want 0", got)
+	}
+	svc = &settingsService{settings: mergeSettings(user, &Settings{AutoCompactThreshold: &off}).Clone()}
+	if got := svc.AutoCompactThreshold(); got != AutoCompactOff {
+		t.Errorf("AutoCompactThreshold() = %d for an explicit 0, want AutoCompactOff", got)
+	}
+}
+
+// TestSetKeyInFile verifies single-key writes keep the rest of the file, and
+// that a nil value removes the key.
+func TestSetKeyInFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".gen", "settings.json")
+
+	if err := setKeyInFile(path, "theme", "dark"); err != nil {
+		t.Fatalf("setKeyInFile on a missing file: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(`{"theme": "dark", "statusLine": {"type": "command"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := setKeyInFile(path, "maxTokens", 2048); err != nil {
+		t.Fatal(err)
+	}
+	if err := setKeyInFile(path, "theme", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid JSON written: %v\n%s", err, data)
+	}
+	if _, ok := doc["theme"]; ok {
+		t.Error("theme should have been removed")
+	}
+	if doc["maxTokens"] != float64(2048) {
+		t.Errorf("maxTokens = %v, want 2048", doc["maxTokens"])
+	}
+	if _, ok := doc["statusLine"]; !ok {
+		t.Error("keys gen does not know about should be kept")
+	}
+}
+
+// TestConfig_SearchCache verifies disk persistence is opt-in and the TTL
+// falls back to the default when invalid.
+func TestConfig_SearchCache(t *testing.T) {
+	persist, ttl := (&settingsService{settings: NewSettings()}).SearchCache()
+	if persist || ttl != DefaultSearchCacheTTL {
+		t.Errorf("SearchCache() = %v, %v; want false, %v", persist, ttl, DefaultSearchCacheTTL)
+	}
+
+	on := true
+	merged := mergeSettings(NewSettings(), &Settings{SearchCache: &on, SearchCacheTTL: "10m"})
+	persist, ttl = (&settingsService{settings: merged.Clone()}).SearchCache()
+	if !persist || ttl != 10*time.Minute {
+		t.Errorf("SearchCache() = %v, %v; want true, 10m", persist, ttl)
+	}
+
+	merged = mergeSettings(NewSettings(), &Settings{SearchCacheTTL: "soon"})
+	if _, ttl = (&settingsService{settings: merged.Clone()}).SearchCache(); ttl != DefaultSearchCacheTTL {
+		t.Errorf("invalid TTL = %v, want default %v", ttl, DefaultSearchCacheTTL)
+	}
+}
@@ -0,0 +1,37 @@
+package setting
+
+import "fmt"
+
+// Permission modes for non-interactive runs (gen agent run --permission-mode).
+// Nobody can answer a prompt in these runs, so anything that would ask is
+// denied instead.
+const (
+	HeadlessDeny        = "deny"         // only safe tools and explicit allow rules
+	HeadlessAcceptEdits = "accept-edits" // also file edits inside cwd and common dev commands
+	HeadlessAcceptAll   = "accept-all"   // everything except deny rules and bypass-immune checks
+)
+
+// NewHeadlessPermissions returns session permissions for a non-interactive run
+// in cwd. An empty mode means HeadlessDeny.
+func NewHeadlessPermissions(mode, cwd string) (*SessionPermissions, error) {
+	sp := NewSessionPermissions()
+	sp.ShouldAvoidPrompts = true
+
+	switch mode {
+	case "", HeadlessDeny:
+	case HeadlessAcceptEdits:
+		sp.AllowAllEdits = true
+		sp.AllowAllWrites = true
+		sp.AddWorkingDirectory(cwd)
+		for _, pattern := range CommonAllowPatterns {
+			sp.AllowPattern(pattern)
+		}
+	case HeadlessAcceptAll:
+		sp.Mode = ModeBypassPermissions
+		sp.IsBypassAvailable = true
+	default:
+		return nil, fmt.Errorf("unknown permission mode %q (valid: %s, %s, %s)",
+			mode, HeadlessDeny, HeadlessAcceptEdits, HeadlessAcceptAll)
+	}
+	return sp, nil
+}
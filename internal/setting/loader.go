@@ -2,6 +2,7 @@ package setting
 
 import (
 	"encoding/json"
+	"fmt"
 	"maps"
 	"os"
 	"path/filepath"
@@ -307,6 +308,77 @@ func GetDisabledToolsAt(userLevel bool) map[string]bool {
 	return result
 }
 
+// settingsPathAt returns ~/.gen/settings.json (userLevel) or .gen/settings.json.
+func (l *Loader) settingsPathAt(userLevel bool) string {
+	if userLevel {
+		return filepath.Join(l.userDir, "settings.json")
+	}
+	return filepath.Join(l.projectDir, "settings.json")
+}
+
+// SettingsAt returns the settings from a single settings file (not merged).
+// A missing or unreadable file yields empty settings.
+func SettingsAt(userLevel bool) *Settings {
+	loader := NewLoader()
+	s, err := loader.LoadFile(loader.settingsPathAt(userLevel))
+	if err != nil {
+		return NewSettings()
+	}
+	return s
+}
+
+// SetAt writes one top-level key to the user (true) or project (false)
+// settings file, leaving every other key as written. A nil value removes the
+// key.
+func SetAt(userLevel bool, key string, value any) error {
+	loader := NewLoader()
+	if err := setKeyInFile(loader.settingsPathAt(userLevel), key, value); err != nil {
+		return err
+	}
+	loadedSettingsMu.Lock()
+	loadedSettings = nil
+	loadedSettingsMu.Unlock()
+	return nil
+}
+
+func setKeyInFile(path, key string, value any) error {
+	doc := make(map[string]json.RawMessage)
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if value == nil {
+		delete(doc, key)
+	} else {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		doc[key] = raw
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
 // AddAllowRuleAt appends a permission allow rule to project settings rooted at
 // the provided cwd.
 func AddAllowRuleAt(toolName string, args map[string]any, cwd string) error {
@@ -3,7 +3,7 @@ package setting
 import "maps"
 
 // mergeSettings merges two Settings, with overlay taking precedence over base.
-// Slices (permission rules) are deduplicated unions. Maps are merged with overlay winning on conflicts.
+// Slices (permission rules, protected paths) are deduplicated unions. Maps are merged with overlay winning on conflicts.
 func mergeSettings(base, overlay *Settings) *Settings {
 	if base == nil {
 		return overlay
@@ -20,7 +20,33 @@ func mergeSettings(base, overlay *Settings) *Settings {
 	result.Env = mergeMaps(base.Env, overlay.Env)
 	result.EnabledPlugins = mergeMaps(base.EnabledPlugins, overlay.EnabledPlugins)
 	result.DisabledTools = mergeMaps(base.DisabledTools, overlay.DisabledTools)
+	result.ToolTimeouts = mergeMaps(base.ToolTimeouts, overlay.ToolTimeouts)
+	result.Keybindings = mergeMaps(base.Keybindings, overlay.Keybindings)
+	result.Workspaces = mergeMaps(base.Workspaces, overlay.Workspaces)
 	result.AllowBypass = coalesceBool(overlay.AllowBypass, base.AllowBypass)
+	result.ConfirmClear = coalesceBool(overlay.ConfirmClear, base.ConfirmClear)
+	result.NoTelemetry = coalesceBool(overlay.NoTelemetry, base.NoTelemetry)
+	result.ShowSkillPrompts = coalesceBool(overlay.ShowSkillPrompts, base.ShowSkillPrompts)
+	result.ShowPromptModel = coalesceBool(overlay.ShowPromptModel, base.ShowPromptModel)
+	result.ShowCost = coalesceBool(overlay.ShowCost, base.ShowCost)
+	result.ShowThinking = coalesceBool(overlay.ShowThinking, base.ShowThinking)
+	result.WrapWidth = coalesceInt(overlay.WrapWidth, base.WrapWidth)
+	result.ResponseCache = coalesceBool(overlay.ResponseCache, base.ResponseCache)
+	result.SearchCache = coalesceBool(overlay.SearchCache, base.SearchCache)
+	result.TerminalTitle = coalesceBool(overlay.TerminalTitle, base.TerminalTitle)
+	result.MCPSampling = coalesceBool(overlay.MCPSampling, base.MCPSampling)
+	result.SemanticMemory = coalesceBool(overlay.SemanticMemory, base.SemanticMemory)
+	result.ResponseCacheTTL = coalesce(overlay.ResponseCacheTTL, base.ResponseCacheTTL)
+	result.SearchCacheTTL = coalesce(overlay.SearchCacheTTL, base.SearchCacheTTL)
+	result.ModelSort = coalesce(overlay.ModelSort, base.ModelSort)
+	result.AutoCompactThreshold = coalesceIntPtr(overlay.AutoCompactThreshold, base.AutoCompactThreshold)
+	result.DefaultMode = coalesce(overlay.DefaultMode, base.DefaultMode)
+	result.MaxTokens = coalesceInt(overlay.MaxTokens, base.MaxTokens)
+	result.BashMaxOutput = coalesceInt(overlay.BashMaxOutput, base.BashMaxOutput)
+	result.MaxToolParallel = coalesceInt(overlay.MaxToolParallel, base.MaxToolParallel)
+	result.ImageMaxSize = coalesceInt(overlay.ImageMaxSize, base.ImageMaxSize)
+	result.ProtectedPaths = mergeStringSlices(base.ProtectedPaths, overlay.ProtectedPaths)
+	result.HistoryExclude = mergeStringSlices(base.HistoryExclude, overlay.HistoryExclude)
 
 	return result
 }
@@ -30,6 +56,9 @@ func mergePermissions(base, overlay PermissionSettings) PermissionSettings {
 		Allow: mergeStringSlices(base.Allow, overlay.Allow),
 		Deny:  mergeStringSlices(base.Deny, overlay.Deny),
 		Ask:   mergeStringSlices(base.Ask, overlay.Ask),
+
+		BashAllow: mergeStringSlices(base.BashAllow, overlay.BashAllow),
+		BashDeny:  mergeStringSlices(base.BashDeny, overlay.BashDeny),
 	}
 }
 
@@ -65,6 +94,13 @@ func coalesce(a, b string) string {
 	return b
 }
 
+func coalesceInt(a, b int) int {
+	if a != 0 {
+		return a
+	}
+	return b
+}
+
 func coalesceBool(a, b *bool) *bool {
 	if a != nil {
 		return a
@@ -72,6 +108,13 @@ func coalesceBool(a, b *bool) *bool {
 	return b
 }
 
+func coalesceIntPtr(a, b *int) *int {
+	if a != nil {
+		return a
+	}
+	return b
+}
+
 // mergeMaps merges two maps with overlay taking precedence over base.
 func mergeMaps[V any](base, overlay map[string]V) map[string]V {
 	result := make(map[string]V, len(base)+len(overlay))
@@ -1,6 +1,7 @@
 package setting
 
 import (
+	"fmt"
 	"net/url"
 	"path/filepath"
 	"strings"
@@ -15,6 +16,7 @@ var safeTools = map[string]bool{
 	"TaskCreate": true, "TaskGet": true, "TaskList": true, "TaskUpdate": true,
 	"AskUserQuestion": true,
 	"CronList":        true, "ToolSearch": true,
+	"ScratchpadWrite": true, "ScratchpadRead": true,
 }
 
 // PermissionBehavior represents the outcome of a permission check.
@@ -70,17 +72,32 @@ func decide(b PermissionBehavior, reason string) PermissionDecision {
 //
 // Decision pipeline (inspired by Claude Code's hasPermissionsToUseTool):
 //
-//  1. Deny rules + bypass-immune safety checks + ask rules (via checkHardBlocks)
-//     — deny rules cannot be bypassed; safety checks always prompt
+//  1. Deny rules and bashDeny patterns + bypass-immune safety checks + ask
+//     rules (via checkHardBlocks) — deny rules cannot be bypassed; safety
+//     checks always prompt
 //  2. BypassPermissions mode → allow (everything except step 1)
 //  3. Session permissions (runtime overrides)
-//  4. Allow rules
-//  5. Default (safe tools → allow, others → ask)
-//  6. Mode transforms: DontAsk converts ask → deny
+//  4. Allow rules, then bashAllow patterns (every command in the call must
+//     match one)
+//  5. Default (safe tools → allow, others → ask); a pending /approve N
+//     budget turns ask → allow and counts down (not in PreviewPermission)
+//  6. Mode transforms: DontAsk (or a session that cannot prompt) converts
+//     ask → deny
 
 // HasPermissionToUseTool is the central permission gate that determines
 // whether a tool invocation should be allowed, denied, or prompted.
 func (s *Settings) HasPermissionToUseTool(toolName string, args map[string]any, session *SessionPermissions) PermissionDecision {
+	return s.hasPermission(toolName, args, session, true)
+}
+
+// PreviewPermission runs the same checks as HasPermissionToUseTool for a
+// call that gen makes itself, without spending the /approve budget, which
+// is left for the model's tool calls.
+func (s *Settings) PreviewPermission(toolName string, args map[string]any, session *SessionPermissions) PermissionDecision {
+	return s.hasPermission(toolName, args, session, false)
+}
+
+func (s *Settings) hasPermission(toolName string, args map[string]any, session *SessionPermissions, spendBudget bool) PermissionDecision {
 	rule := BuildRule(toolName, args)
 
 	// ── Step 1: Deny rules + bypass-immune safety checks ──
@@ -88,6 +105,9 @@ func (s *Settings) HasPermissionToUseTool(toolName string, args map[string]any,
 		if s.isDenyRule(reason) {
 			return decide(Deny, reason)
 		}
+		if session != nil && session.ShouldAvoidPrompts {
+			return decide(Deny, reason+" (cannot prompt)")
+		}
 		return decide(Ask, reason)
 	}
 
@@ -114,6 +134,11 @@ func (s *Settings) HasPermissionToUseTool(toolName string, args map[string]any,
 			return decide(Allow, "allow rule: "+pattern)
 		}
 	}
+	if cmd, ok := args["command"].(string); ok && toolName == "Bash" {
+		if pattern := s.bashAllowPattern(cmd); pattern != "" {
+			return decide(Allow, "allow rule: bashAllow "+pattern)
+		}
+	}
 
 	// Note: Ask rules are already checked in checkHardBlocks (Step 1).
 
@@ -123,10 +148,20 @@ func (s *Settings) HasPermissionToUseTool(toolName string, args map[string]any,
 		result = decide(Allow, "default: safe tool")
 	}
 
+	// Pre-approved via /approve N: spend one call of the budget instead of asking.
+	if result.Behavior == Ask && session != nil && spendBudget {
+		if left, ok := session.PreApproved.Consume(toolName); ok {
+			return decide(Allow, fmt.Sprintf("session: pre-approved (%d left)", left))
+		}
+	}
+
 	// ── Step 6: Mode transforms ──
 	if result.Behavior == Ask && session != nil && session.Mode == ModeDontAsk {
 		return decide(Deny, "mode: don't ask (auto-deny)")
 	}
+	if result.Behavior == Ask && session != nil && session.ShouldAvoidPrompts {
+		return decide(Deny, "non-interactive: would require confirmation")
+	}
 
 	return result
 }
@@ -142,9 +177,14 @@ func (s *Settings) checkHardBlocks(toolName string, args map[string]any, rule st
 			return "deny rule: " + pattern
 		}
 	}
+	if cmd, ok := args["command"].(string); ok && toolName == "Bash" {
+		if pattern := s.bashDenyPattern(cmd); pattern != "" {
+			return "deny rule: bashDeny " + pattern
+		}
+	}
 
 	// Bypass-immune: sensitive paths
-	if toolName == "Edit" || toolName == "Write" {
+	if toolName == "Edit" || toolName == "MultiEdit" || toolName == "Write" {
 		if fp, ok := args["file_path"].(string); ok {
 			if reason := isSensitivePath(fp); reason != "" {
 				return "bypass-immune: " + reason
@@ -164,9 +204,14 @@ func (s *Settings) checkHardBlocks(toolName string, args map[string]any, rule st
 		}
 	}
 
+	// Bypass-immune: every Bash call prompts in a protected directory
+	if toolName == "Bash" && session != nil && session.ProtectedDir != "" {
+		return "bypass-immune: protected directory " + session.ProtectedDir
+	}
+
 	// Working directory constraints
 	if session != nil && len(session.WorkingDirectories) > 0 {
-		if toolName == "Edit" || toolName == "Write" {
+		if toolName == "Edit" || toolName == "MultiEdit" || toolName == "Write" {
 			if fp, ok := args["file_path"].(string); ok {
 				if !isInWorkingDirectory(fp, session.WorkingDirectories) {
 					return "outside working directory"
@@ -210,11 +255,16 @@ func (s *Settings) CheckPermission(toolName string, args map[string]any, session
 // Different tools extract different parts of args:
 //   - Bash: "Bash(command)" where command is the shell command
 //   - Read/Edit/Write: "Read(file_path)"
+//   - MultiEdit: "Edit(file_path)", so Edit rules also govern MultiEdit
 //   - Glob/Grep: "Glob(pattern)" or "Grep(pattern)"
 //   - WebFetch: "WebFetch(domain:hostname)"
 func BuildRule(toolName string, args map[string]any) string {
 	var argStr string
 
+	if toolName == "MultiEdit" {
+		toolName = "Edit"
+	}
+
 	switch toolName {
 	case "Bash":
 		// For Bash, use the command with prefix matching support
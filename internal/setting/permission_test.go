@@ -79,6 +79,12 @@ func TestBuildRule(t *testing.T) {
 			map[string]any{"file_path": "/path/to/file.txt"},
 			"Read(/path/to/file.txt)",
 		},
+		{
+			"multiedit is governed by edit rules",
+			"MultiEdit",
+			map[string]any{"file_path": "/path/to/file.txt"},
+			"Edit(/path/to/file.txt)",
+		},
 		{
 			"edit file",
 			"Edit",
@@ -361,6 +367,27 @@ func TestDestructiveCommandsRequireConfirmation(t *testing.T) {
 	}
 }
 
+func TestProtectedDirRequiresBashConfirmation(t *testing.T) {
+	settings := &Settings{
+		Permissions: PermissionSettings{Allow: []string{"Bash(ls:*)"}},
+	}
+	session := &SessionPermissions{
+		Mode:            ModeBypassPermissions,
+		AllowAllBash:    true,
+		AllowedTools:    make(map[string]bool),
+		AllowedPatterns: make(map[string]bool),
+		ProtectedDir:    "~",
+	}
+
+	decision := settings.HasPermissionToUseTool("Bash", map[string]any{"command": "ls -la"}, session)
+	if decision.Behavior != Ask {
+		t.Fatalf("Bash in protected dir = %v (%s), want Ask", decision.Behavior, decision.Reason)
+	}
+	if settings.CheckPermission("Read", map[string]any{"file_path": "/tmp/x"}, session) != Allow {
+		t.Error("non-Bash tools should not be affected by the protected directory")
+	}
+}
+
 func Test_isSensitivePath(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -834,7 +861,7 @@ func TestSafeToolAllowlist(t *testing.T) {
 		"Read", "Glob", "Grep", "WebFetch", "WebSearch", "LSP",
 		"TaskCreate", "TaskGet", "TaskList", "TaskUpdate",
 		"AskUserQuestion",
-		"CronList", "ToolSearch",
+		"CronList", "ToolSearch", "ScratchpadWrite", "ScratchpadRead",
 	}
 
 	for _, tool := range allSafeTools {
@@ -967,3 +994,77 @@ func TestOperationModeNext(t *testing.T) {
 		t.Errorf("Bypass.Next() = %v, want Normal", ModeBypassPermissions.Next())
 	}
 }
+
+func TestPreApprovedBudgetCountsDown(t *testing.T) {
+	s := NewSettings()
+	session := NewSessionPermissions()
+	session.PreApproved.Grant(ToolClass("Edit"), 2)
+
+	args := map[string]any{"file_path": "/tmp/project/main.go", "old_string": "a", "new_string": "b"}
+	// Previews neither use nor spend the budget.
+	for range 3 {
+		if got := s.PreviewPermission("Edit", args, session).Behavior; got != Ask {
+			t.Fatalf("PreviewPermission() = %v, want ask", got)
+		}
+	}
+	for i, want := range []PermissionBehavior{Allow, Allow, Ask} {
+		// Write shares the Edit class, so alternate tools to cover both.
+		toolName := "Edit"
+		if i == 1 {
+			toolName = "Write"
+		}
+		if got := s.HasPermissionToUseTool(toolName, args, session).Behavior; got != want {
+			t.Fatalf("call %d (%s): got %v, want %v", i+1, toolName, got, want)
+		}
+	}
+
+	if got := s.HasPermissionToUseTool("Bash", map[string]any{"command": "make test"}, session).Behavior; got != Ask {
+		t.Errorf("Bash should not use the Edit budget, got %v", got)
+	}
+
+	session.PreApproved.Grant(AnyToolClass, 1)
+	if got := s.HasPermissionToUseTool("Bash", map[string]any{"command": "make test"}, session).Behavior; got != Allow {
+		t.Errorf("any-tool budget should allow Bash, got %v", got)
+	}
+	if got := s.HasPermissionToUseTool("Bash", map[string]any{"command": "rm -rf /"}, session).Behavior; got == Allow {
+		t.Error("pre-approval must not bypass destructive command checks")
+	}
+}
+
+func TestHeadlessPermissions(t *testing.T) {
+	cwd := t.TempDir()
+	settings := &Settings{Permissions: PermissionSettings{Allow: []string{"Bash(make:*)"}}}
+
+	tests := []struct {
+		mode     string
+		toolName string
+		args     map[string]any
+		want     PermissionBehavior
+	}{
+		{HeadlessDeny, "Read", map[string]any{"file_path": cwd + "/a.go"}, Allow},
+		{HeadlessDeny, "Bash", map[string]any{"command": "make test"}, Allow},
+		{HeadlessDeny, "Bash", map[string]any{"command": "curl example.com"}, Deny},
+		{HeadlessDeny, "Edit", map[string]any{"file_path": cwd + "/a.go"}, Deny},
+		{HeadlessAcceptEdits, "Edit", map[string]any{"file_path": cwd + "/a.go"}, Allow},
+		{HeadlessAcceptEdits, "Edit", map[string]any{"file_path": "/etc/hosts"}, Deny},
+		{HeadlessAcceptEdits, "Bash", map[string]any{"command": "curl example.com"}, Deny},
+		{HeadlessAcceptAll, "Bash", map[string]any{"command": "curl example.com"}, Allow},
+		{HeadlessAcceptAll, "Bash", map[string]any{"command": "rm -rf /"}, Deny},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode+"/"+BuildRule(tt.toolName, tt.args), func(t *testing.T) {
+			session, err := NewHeadlessPermissions(tt.mode, cwd)
+			if err != nil {
+				t.Fatalf("NewHeadlessPermissions(%q): %v", tt.mode, err)
+			}
+			if got := settings.CheckPermission(tt.toolName, tt.args, session); got != tt.want {
+				t.Errorf("CheckPermission = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewHeadlessPermissions("yolo", cwd); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
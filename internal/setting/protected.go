@@ -0,0 +1,47 @@
+package setting
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultProtectedPaths are checked in addition to Settings.ProtectedPaths:
+// running in the filesystem root or directly in the home directory is almost
+// never intended.
+var DefaultProtectedPaths = []string{"/", "~"}
+
+// MatchProtectedPath returns the entry of paths that cwd falls under, or ""
+// when none applies. An entry matches its exact directory; an entry ending
+// in "/**" also matches everything below it. A leading "~" expands to the
+// home directory. Symlinks are resolved on both sides.
+func MatchProtectedPath(cwd string, paths []string) string {
+	if cwd == "" {
+		return ""
+	}
+	dir := resolvePath(cleanPath(cwd))
+	for _, entry := range paths {
+		pattern, recursive := strings.CutSuffix(entry, "/**")
+		pattern = expandHome(pattern)
+		if pattern == "" {
+			continue
+		}
+		protected := resolvePath(cleanPath(pattern))
+		if dir == protected || (recursive && isSubpath(dir, protected)) {
+			return entry
+		}
+	}
+	return ""
+}
+
+// expandHome replaces a leading "~" with the user's home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
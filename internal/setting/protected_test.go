@@ -0,0 +1,39 @@
+package setting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchProtectedPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	prod := filepath.Join(home, "deploy", "prod")
+	if err := os.MkdirAll(filepath.Join(prod, "app"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		cwd   string
+		paths []string
+		want  string
+	}{
+		{"root", "/", DefaultProtectedPaths, "/"},
+		{"home", home, DefaultProtectedPaths, "~"},
+		{"below home is not protected", filepath.Join(home, "deploy"), DefaultProtectedPaths, ""},
+		{"exact entry", prod, []string{"~/deploy/prod"}, "~/deploy/prod"},
+		{"exact entry does not cover children", filepath.Join(prod, "app"), []string{"~/deploy/prod"}, ""},
+		{"recursive entry covers children", filepath.Join(prod, "app"), []string{prod + "/**"}, prod + "/**"},
+		{"recursive entry covers itself", prod, []string{"~/deploy/prod/**"}, "~/deploy/prod/**"},
+		{"empty cwd", "", DefaultProtectedPaths, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchProtectedPath(tt.cwd, tt.paths); got != tt.want {
+				t.Errorf("MatchProtectedPath(%q, %v) = %q, want %q", tt.cwd, tt.paths, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package setting
+
+import (
+	"regexp"
+
+	"go.uber.org/zap"
+
+	"github.com/yanmxa/gencode/internal/log"
+)
+
+// RedactedText replaces a user message that matches a historyExclude pattern
+// in saved sessions and input history.
+const RedactedText = "[redacted]"
+
+// HistoryRedactor hides user messages that match any of its patterns before
+// they are written to disk. A nil *HistoryRedactor redacts nothing.
+type HistoryRedactor struct {
+	patterns []*regexp.Regexp
+}
+
+// NewHistoryRedactor compiles patterns as Go regular expressions. Invalid
+// patterns are logged and skipped. It returns nil when no pattern compiles.
+func NewHistoryRedactor(patterns []string) *HistoryRedactor {
+	var compiled []*regexp.Regexp
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			log.Logger().Warn("ignoring invalid historyExclude pattern", zap.String("pattern", p), zap.Error(err))
+			continue
+		}
+		compiled = append(compiled, re)
+	}
+	if len(compiled) == 0 {
+		return nil
+	}
+	return &HistoryRedactor{patterns: compiled}
+}
+
+// Matches reports whether text matches any pattern.
+func (r *HistoryRedactor) Matches(text string) bool {
+	if r == nil {
+		return false
+	}
+	for _, re := range r.patterns {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns RedactedText when text matches a pattern, else text.
+func (r *HistoryRedactor) Redact(text string) string {
+	if r.Matches(text) {
+		return RedactedText
+	}
+	return text
+}
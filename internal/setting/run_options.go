@@ -1,12 +1,42 @@
 // Package options defines configuration types shared across app and tui packages.
 package setting
 
+import (
+	"fmt"
+	"math"
+)
+
 // RunOptions contains all options for running the application.
 type RunOptions struct {
-	Print     string // non-empty → non-interactive print mode
-	Prompt    string // initial prompt for interactive TUI
-	PluginDir string
-	Continue  bool   // resume most recent session
-	Resume    bool   // open session selector or resume by ID
-	ResumeID  string // specific session ID to resume
+	Print      string // non-empty → non-interactive print mode
+	Prompt     string // initial prompt for interactive TUI
+	PluginDir  string
+	Continue   bool   // resume most recent session
+	Resume     bool   // open session selector or resume by ID
+	ResumeID   string // specific session ID to resume
+	ResumeFile string // session transcript file to resume instead of a stored session
+	Workspace  string // named workspace to apply at startup
+	Cache      bool   // replay cached responses to identical LLM requests
+	Provider   string // provider for this run only; must be connected
+	Model      string // model for this run only
+
+	PermissionMode string // Headless* mode deciding print-mode tool calls; empty = HeadlessDeny
+
+	MaxTokens   int      // output token limit for this run; 0 = default
+	Temperature *float64 // sampling temperature for this run; nil = provider default
+}
+
+// MaxTemperature is the highest temperature accepted by --temperature. It is
+// the upper bound of the widest provider range (OpenAI, Gemini).
+const MaxTemperature = 2.0
+
+// Validate rejects sampling overrides no provider would accept.
+func (o RunOptions) Validate() error {
+	if o.MaxTokens < 0 {
+		return fmt.Errorf("--max-tokens must not be negative, got %d", o.MaxTokens)
+	}
+	if t := o.Temperature; t != nil && (math.IsNaN(*t) || *t < 0 || *t > MaxTemperature) {
+		return fmt.Errorf("--temperature must be between 0 and %g, got %g", MaxTemperature, *t)
+	}
+	return nil
 }
@@ -0,0 +1,26 @@
+package setting
+
+import "testing"
+
+func TestRunOptionsValidate(t *testing.T) {
+	temp := func(v float64) *float64 { return &v }
+	cases := []struct {
+		name    string
+		opts    RunOptions
+		wantErr bool
+	}{
+		{name: "defaults", opts: RunOptions{}},
+		{name: "max tokens", opts: RunOptions{MaxTokens: 1024}},
+		{name: "zero temperature", opts: RunOptions{Temperature: temp(0)}},
+		{name: "max temperature", opts: RunOptions{Temperature: temp(MaxTemperature)}},
+		{name: "negative max tokens", opts: RunOptions{MaxTokens: -1}, wantErr: true},
+		{name: "negative temperature", opts: RunOptions{Temperature: temp(-0.1)}, wantErr: true},
+		{name: "temperature too high", opts: RunOptions{Temperature: temp(2.5)}, wantErr: true},
+	}
+	for _, tc := range cases {
+		err := tc.opts.Validate()
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tc.name, err, tc.wantErr)
+		}
+	}
+}
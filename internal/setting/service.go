@@ -1,6 +1,20 @@
 package setting
 
-import "sync"
+import (
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NoTelemetryEnv is the environment variable that, when set to "1", disables
+// update checks, analytics, and any network access gen would otherwise start
+// on its own at startup.
+const NoTelemetryEnv = "GEN_NO_TELEMETRY"
+
+// NoTerminalTitleEnv is the environment variable that, when set to "1", stops
+// print mode from writing progress to the terminal title.
+const NoTerminalTitleEnv = "GEN_NO_TERMINAL_TITLE"
 
 // Service is the public contract for the setting module.
 type Service interface {
@@ -10,6 +24,99 @@ type Service interface {
 	// AllowBypass reports whether bypass mode is permitted.
 	AllowBypass() bool
 
+	// ConfirmClear reports whether /clear should ask before discarding an
+	// unsaved conversation. Defaults to true when unset.
+	ConfirmClear() bool
+
+	// NoTelemetry reports whether background network activity is disabled,
+	// either by GEN_NO_TELEMETRY=1 or the noTelemetry setting.
+	NoTelemetry() bool
+
+	// TerminalTitle reports whether print mode may show progress in the
+	// terminal title. Defaults to true; GEN_NO_TERMINAL_TITLE=1 turns it off.
+	TerminalTitle() bool
+
+	// MCPSampling reports whether MCP servers may ask for LLM completions
+	// through sampling/createMessage. Defaults to true; each server still
+	// needs the user's consent on first use.
+	MCPSampling() bool
+
+	// SemanticMemory reports whether large memory and rules files should be
+	// narrowed to the sections most relevant to each prompt. Defaults to false.
+	SemanticMemory() bool
+
+	// HistoryRedactor returns a redactor for the historyExclude patterns, or
+	// nil when none are configured.
+	HistoryRedactor() *HistoryRedactor
+
+	// ShowSkillPrompts reports whether skill invocations should echo the
+	// resolved instructions they inject. Defaults to false.
+	ShowSkillPrompts() bool
+
+	// ShowPromptModel reports whether the input prompt should show the
+	// active model's short name. Defaults to false.
+	ShowPromptModel() bool
+
+	// ShowCost reports whether the status bar should show the session's
+	// running cost. Defaults to true.
+	ShowCost() bool
+
+	// ShowThinking reports whether the model's reasoning should be shown
+	// above its answers. Defaults to true.
+	ShowThinking() bool
+
+	// WrapWidth returns the configured markdown/tool-output wrap width, or 0
+	// to follow the terminal width. Out-of-range values are treated as 0.
+	WrapWidth() int
+
+	// ResponseCache reports whether the on-disk LLM response cache is
+	// enabled and how long entries live. The TTL falls back to
+	// DefaultResponseCacheTTL when unset or invalid.
+	ResponseCache() (enabled bool, ttl time.Duration)
+
+	// SearchCache reports whether web search results should also be cached
+	// on disk and how long cached results live. The TTL falls back to
+	// DefaultSearchCacheTTL when unset or invalid.
+	SearchCache() (persist bool, ttl time.Duration)
+
+	// AutoCompactThreshold returns the context usage percentage that triggers
+	// compaction, 0 for the default, or AutoCompactOff when the setting is 0.
+	// Out-of-range values are treated as unset.
+	AutoCompactThreshold() int
+
+	// DefaultMode returns the operation mode new sessions start in. Bypass
+	// requires allowBypass; it and unknown modes fall back to ModeNormal.
+	DefaultMode() OperationMode
+
+	// ToolTimeout returns the toolTimeouts setting for a tool and whether one
+	// is set. A zero duration means the tool runs without a time limit.
+	ToolTimeout(name string) (time.Duration, bool)
+
+	// MaxTokens returns the configured cap on output tokens per response,
+	// or 0 for the model's own limit.
+	MaxTokens() int
+
+	// BashMaxOutput returns the bashMaxOutput setting: how many bytes of
+	// output a Bash call returns, or 0 for the built-in default.
+	BashMaxOutput() int
+
+	// MaxToolParallel returns the maxToolParallel setting: how many
+	// read-only tool calls of one response run at once, or 0 for the
+	// built-in default.
+	MaxToolParallel() int
+
+	// ImageMaxSize returns the imageMaxSize setting: the largest image, in
+	// bytes, that can be attached to a message, or 0 for the built-in default.
+	ImageMaxSize() int
+
+	// ModelSort returns the model selector ordering, one of the ModelSort*
+	// constants. Unknown values fall back to ModelSortProvider.
+	ModelSort() string
+
+	// ProtectedPath returns the protected path entry (DefaultProtectedPaths
+	// plus the protectedPaths setting) that cwd falls under, or "".
+	ProtectedPath(cwd string) string
+
 	// IsGitRepo checks if the given directory is a git repository.
 	IsGitRepo(cwd string) bool
 
@@ -34,6 +141,10 @@ type Service interface {
 	// HasPermissionToUseTool is the central permission gate.
 	HasPermissionToUseTool(toolName string, args map[string]any, session *SessionPermissions) PermissionDecision
 
+	// PreviewPermission is HasPermissionToUseTool without spending the
+	// /approve budget.
+	PreviewPermission(toolName string, args map[string]any, session *SessionPermissions) PermissionDecision
+
 	// ResolveHookAllow checks if a hook's "allow" decision should be honored.
 	ResolveHookAllow(toolName string, args map[string]any, session *SessionPermissions) bool
 
@@ -43,6 +154,14 @@ type Service interface {
 
 	// UpdateDisabledToolsAt updates disabled tools at user level (true) or project level (false).
 	UpdateDisabledToolsAt(disabledTools map[string]bool, userLevel bool) error
+
+	// SettingsAt returns the settings from a single settings file (not merged).
+	// userLevel=true reads from ~/.gen/settings.json; false reads from .gen/settings.json.
+	SettingsAt(userLevel bool) *Settings
+
+	// SetAt writes one top-level key to the user (true) or project (false)
+	// settings file. A nil value removes the key.
+	SetAt(userLevel bool, key string, value any) error
 }
 
 // Compile-time check: *settingsService implements Service.
@@ -106,6 +225,10 @@ func ResetService() {
 type settingsService struct {
 	mu       sync.RWMutex
 	settings *Settings
+
+	// redactor caches the compiled historyExclude patterns in redactorKey.
+	redactor    *HistoryRedactor
+	redactorKey string
 }
 
 func (s *settingsService) Snapshot() *Settings {
@@ -120,6 +243,221 @@ func (s *settingsService) AllowBypass() bool {
 	return s.settings != nil && s.settings.AllowBypass != nil && *s.settings.AllowBypass
 }
 
+func (s *settingsService) ConfirmClear() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.settings == nil || s.settings.ConfirmClear == nil || *s.settings.ConfirmClear
+}
+
+func (s *settingsService) NoTelemetry() bool {
+	if os.Getenv(NoTelemetryEnv) == "1" {
+		return true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.settings != nil && s.settings.NoTelemetry != nil && *s.settings.NoTelemetry
+}
+
+func (s *settingsService) TerminalTitle() bool {
+	if os.Getenv(NoTerminalTitleEnv) == "1" {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.settings == nil || s.settings.TerminalTitle == nil || *s.settings.TerminalTitle
+}
+
+func (s *settingsService) MCPSampling() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.settings == nil || s.settings.MCPSampling == nil || *s.settings.MCPSampling
+}
+
+func (s *settingsService) SemanticMemory() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.settings != nil && s.settings.SemanticMemory != nil && *s.settings.SemanticMemory
+}
+
+func (s *settingsService) HistoryRedactor() *HistoryRedactor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.settings == nil {
+		return nil
+	}
+	if key := strings.Join(s.settings.HistoryExclude, "\x00"); key != s.redactorKey {
+		s.redactor = NewHistoryRedactor(s.settings.HistoryExclude)
+		s.redactorKey = key
+	}
+	return s.redactor
+}
+
+func (s *settingsService) ShowSkillPrompts() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.settings != nil && s.settings.ShowSkillPrompts != nil && *s.settings.ShowSkillPrompts
+}
+
+func (s *settingsService) ShowPromptModel() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.settings != nil && s.settings.ShowPromptModel != nil && *s.settings.ShowPromptModel
+}
+
+func (s *settingsService) ShowCost() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.settings == nil || s.settings.ShowCost == nil || *s.settings.ShowCost
+}
+
+func (s *settingsService) ShowThinking() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.settings == nil || s.settings.ShowThinking == nil || *s.settings.ShowThinking
+}
+
+func (s *settingsService) WrapWidth() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.settings == nil || ValidateWrapWidth(s.settings.WrapWidth) != nil {
+		return 0
+	}
+	return s.settings.WrapWidth
+}
+
+func (s *settingsService) ResponseCache() (bool, time.Duration) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.settings == nil {
+		return false, DefaultResponseCacheTTL
+	}
+	enabled := s.settings.ResponseCache != nil && *s.settings.ResponseCache
+	ttl, err := time.ParseDuration(s.settings.ResponseCacheTTL)
+	if err != nil || ttl <= 0 {
+		ttl = DefaultResponseCacheTTL
+	}
+	return enabled, ttl
+}
+
+func (s *settingsService) SearchCache() (bool, time.Duration) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.settings == nil {
+		return false, DefaultSearchCacheTTL
+	}
+	persist := s.settings.SearchCache != nil && *s.settings.SearchCache
+	ttl, err := time.ParseDuration(s.settings.SearchCacheTTL)
+	if err != nil || ttl <= 0 {
+		ttl = DefaultSearchCacheTTL
+	}
+	return persist, ttl
+}
+
+func (s *settingsService) AutoCompactThreshold() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.settings == nil || s.settings.AutoCompactThreshold == nil {
+		return 0
+	}
+	switch percent := *s.settings.AutoCompactThreshold; {
+	case percent == 0:
+		return AutoCompactOff
+	case ValidateAutoCompactThreshold(percent) != nil:
+		return 0
+	default:
+		return percent
+	}
+}
+
+func (s *settingsService) DefaultMode() OperationMode {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.settings == nil {
+		return ModeNormal
+	}
+	mode, err := ParseOperationMode(s.settings.DefaultMode)
+	if err != nil || mode == ModeDontAsk {
+		return ModeNormal
+	}
+	if mode == ModeBypassPermissions && (s.settings.AllowBypass == nil || !*s.settings.AllowBypass) {
+		return ModeNormal
+	}
+	return mode
+}
+
+func (s *settingsService) ToolTimeout(name string) (time.Duration, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.settings == nil {
+		return 0, false
+	}
+	secs, ok := s.settings.ToolTimeouts[name]
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(max(secs, 0)) * time.Second, true
+}
+
+func (s *settingsService) MaxTokens() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.settings == nil || s.settings.MaxTokens < 0 {
+		return 0
+	}
+	return s.settings.MaxTokens
+}
+
+func (s *settingsService) BashMaxOutput() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.settings == nil || s.settings.BashMaxOutput < 0 {
+		return 0
+	}
+	return s.settings.BashMaxOutput
+}
+
+func (s *settingsService) MaxToolParallel() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.settings == nil || s.settings.MaxToolParallel < 0 {
+		return 0
+	}
+	return s.settings.MaxToolParallel
+}
+
+func (s *settingsService) ImageMaxSize() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.settings == nil || s.settings.ImageMaxSize < 0 {
+		return 0
+	}
+	return s.settings.ImageMaxSize
+}
+
+func (s *settingsService) ModelSort() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.settings == nil {
+		return ModelSortProvider
+	}
+	switch s.settings.ModelSort {
+	case ModelSortAlpha, ModelSortRecency:
+		return s.settings.ModelSort
+	default:
+		return ModelSortProvider
+	}
+}
+
+func (s *settingsService) ProtectedPath(cwd string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	paths := DefaultProtectedPaths
+	if s.settings != nil {
+		paths = mergeStringSlices(paths, s.settings.ProtectedPaths)
+	}
+	return MatchProtectedPath(cwd, paths)
+}
+
 func (s *settingsService) IsGitRepo(cwd string) bool {
 	return IsGitRepo(cwd)
 }
@@ -207,6 +545,15 @@ func (s *settingsService) HasPermissionToUseTool(toolName string, args map[strin
 	return s.settings.HasPermissionToUseTool(toolName, args, session)
 }
 
+func (s *settingsService) PreviewPermission(toolName string, args map[string]any, session *SessionPermissions) PermissionDecision {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.settings == nil {
+		return decide(Ask, "default: no settings loaded")
+	}
+	return s.settings.PreviewPermission(toolName, args, session)
+}
+
 func (s *settingsService) ResolveHookAllow(toolName string, args map[string]any, session *SessionPermissions) bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -223,3 +570,11 @@ func (s *settingsService) GetDisabledToolsAt(userLevel bool) map[string]bool {
 func (s *settingsService) UpdateDisabledToolsAt(disabledTools map[string]bool, userLevel bool) error {
 	return UpdateDisabledToolsAt(disabledTools, userLevel)
 }
+
+func (s *settingsService) SettingsAt(userLevel bool) *Settings {
+	return SettingsAt(userLevel)
+}
+
+func (s *settingsService) SetAt(userLevel bool, key string, value any) error {
+	return SetAt(userLevel, key, value)
+}
@@ -12,30 +12,116 @@ package setting
 
 import (
 	"encoding/json"
+	"fmt"
 	"maps"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // Settings represents the complete GenCode configuration.
 type Settings struct {
-	Permissions    PermissionSettings `json:"permissions,omitempty"`
-	Model          string             `json:"model,omitempty"`
-	Hooks          map[string][]Hook  `json:"hooks,omitempty"`
-	Env            map[string]string  `json:"env,omitempty"`
-	EnabledPlugins map[string]bool    `json:"enabledPlugins,omitempty"`
-	DisabledTools  map[string]bool    `json:"disabledTools,omitempty"`
-	Theme          string             `json:"theme,omitempty"`
-	SearchProvider string             `json:"searchProvider,omitempty"`
-	AllowBypass    *bool              `json:"allowBypass,omitempty"`
+	Permissions      PermissionSettings `json:"permissions,omitempty"`
+	Model            string             `json:"model,omitempty"`
+	Hooks            map[string][]Hook  `json:"hooks,omitempty"`
+	Env              map[string]string  `json:"env,omitempty"`
+	EnabledPlugins   map[string]bool    `json:"enabledPlugins,omitempty"`
+	DisabledTools    map[string]bool    `json:"disabledTools,omitempty"`
+	ToolTimeouts     map[string]int     `json:"toolTimeouts,omitempty"`
+	Keybindings      map[string]string  `json:"keybindings,omitempty"`
+	Theme            string             `json:"theme,omitempty"`
+	SearchProvider   string             `json:"searchProvider,omitempty"`
+	AllowBypass      *bool              `json:"allowBypass,omitempty"`
+	ConfirmClear     *bool              `json:"confirmClear,omitempty"`
+	NoTelemetry      *bool              `json:"noTelemetry,omitempty"`
+	ShowSkillPrompts *bool              `json:"showSkillPrompts,omitempty"`
+	ShowPromptModel  *bool              `json:"showPromptModel,omitempty"`
+	ShowCost         *bool              `json:"showCost,omitempty"`
+	ShowThinking     *bool              `json:"showThinking,omitempty"`
+	WrapWidth        int                `json:"wrapWidth,omitempty"`
+	ResponseCache    *bool              `json:"responseCache,omitempty"`
+	ResponseCacheTTL string             `json:"responseCacheTTL,omitempty"`
+	SearchCache      *bool              `json:"searchCache,omitempty"`
+	SearchCacheTTL   string             `json:"searchCacheTTL,omitempty"`
+	ModelSort        string             `json:"modelSort,omitempty"`
+	ProtectedPaths   []string           `json:"protectedPaths,omitempty"`
+	HistoryExclude   []string           `json:"historyExclude,omitempty"`
+	TerminalTitle    *bool              `json:"terminalTitle,omitempty"`
+	MCPSampling      *bool              `json:"mcpSampling,omitempty"`
+	SemanticMemory   *bool              `json:"semanticMemory,omitempty"`
+
+	AutoCompactThreshold *int   `json:"autoCompactThreshold,omitempty"`
+	DefaultMode          string `json:"defaultMode,omitempty"`
+	MaxTokens            int    `json:"maxTokens,omitempty"`
+	BashMaxOutput        int    `json:"bashMaxOutput,omitempty"`
+	MaxToolParallel      int    `json:"maxToolParallel,omitempty"`
+	ImageMaxSize         int    `json:"imageMaxSize,omitempty"`
+
+	Workspaces map[string]Workspace `json:"workspaces,omitempty"`
+}
+
+// Bounds for Settings.WrapWidth and the /width override.
+const (
+	MinWrapWidth = 40
+	MaxWrapWidth = 500
+)
+
+// ValidateWrapWidth reports whether width is 0 (follow the terminal) or
+// within [MinWrapWidth, MaxWrapWidth].
+func ValidateWrapWidth(width int) error {
+	if width != 0 && (width < MinWrapWidth || width > MaxWrapWidth) {
+		return fmt.Errorf("wrap width must be between %d and %d columns", MinWrapWidth, MaxWrapWidth)
+	}
+	return nil
+}
+
+// Bounds for Settings.AutoCompactThreshold, a percentage of the context
+// window. A threshold of 0 turns auto-compaction off.
+const (
+	MinAutoCompactThreshold = 50
+	MaxAutoCompactThreshold = 99
+)
+
+// AutoCompactOff is what Service.AutoCompactThreshold returns when the
+// setting is 0, i.e. auto-compaction is disabled.
+const AutoCompactOff = -1
+
+// ValidateAutoCompactThreshold reports whether percent is 0 (off) or within
+// [MinAutoCompactThreshold, MaxAutoCompactThreshold].
+func ValidateAutoCompactThreshold(percent int) error {
+	if percent != 0 && (percent < MinAutoCompactThreshold || percent > MaxAutoCompactThreshold) {
+		return fmt.Errorf("auto-compact threshold must be between %d and %d percent", MinAutoCompactThreshold, MaxAutoCompactThreshold)
+	}
+	return nil
 }
 
+// Model selector orderings for Settings.ModelSort. The current model is
+// always listed first, followed by favorites, regardless of the ordering.
+const (
+	ModelSortProvider = "provider" // catalog order as returned by the provider (default)
+	ModelSortAlpha    = "alpha"    // alphabetical by display name
+	ModelSortRecency  = "recency"  // most recently selected first
+)
+
+// DefaultResponseCacheTTL is used when the response cache is enabled without
+// a valid responseCacheTTL.
+const DefaultResponseCacheTTL = 24 * time.Hour
+
+// DefaultSearchCacheTTL is how long web search results are reused when
+// searchCacheTTL is unset or invalid.
+const DefaultSearchCacheTTL = time.Hour
+
 // PermissionSettings defines permission rules for tool execution.
 // Rule format: "Tool(pattern)" — e.g. "Bash(npm:*)", "Read(**/.env)".
 type PermissionSettings struct {
 	Allow []string `json:"allow,omitempty"`
 	Deny  []string `json:"deny,omitempty"`
 	Ask   []string `json:"ask,omitempty"`
+
+	// BashAllow and BashDeny are command patterns checked against each
+	// command inside a Bash call; see bash_lists.go.
+	BashAllow []string `json:"bashAllow,omitempty"`
+	BashDeny  []string `json:"bashDeny,omitempty"`
 }
 
 // Hook defines an event hook configuration.
@@ -72,6 +158,7 @@ type SessionPermissions struct {
 	AllowedTools    map[string]bool
 	AllowedPatterns map[string]bool
 	Denials         DenialTracking // Tracks denial frequency for fallback
+	PreApproved     ApprovalBudget // Countdown of calls pre-approved via /approve N
 
 	// WorkingDirectories restricts Edit/Write operations to these directories.
 	// When non-empty, file edits outside these dirs always prompt (bypass-immune).
@@ -81,6 +168,11 @@ type SessionPermissions struct {
 	// IsBypassAvailable controls whether BypassPermissions mode can be entered.
 	IsBypassAvailable bool
 
+	// ProtectedDir is the protected path entry the session was started in
+	// (see MatchProtectedPath). When set, every Bash call prompts
+	// (bypass-immune). It survives mode changes.
+	ProtectedDir string
+
 	// ShouldAvoidPrompts is set for headless/async subagents that cannot
 	// show interactive dialogs. When true, ask → deny automatically.
 	ShouldAvoidPrompts bool
@@ -112,8 +204,8 @@ func (sp *SessionPermissions) IsToolAllowed(toolName string) bool {
 		return true
 	}
 	switch toolName {
-	case "Edit":
-		return sp.AllowAllEdits
+	case "Edit", "MultiEdit":
+		return sp.AllowAllEdits || sp.AllowedTools["Edit"] || sp.AllowedTools["MultiEdit"]
 	case "Write":
 		return sp.AllowAllWrites
 	case "Bash":
@@ -255,13 +347,74 @@ func (s *Settings) Clone() *Settings {
 	dst.Permissions.Allow = append([]string(nil), s.Permissions.Allow...)
 	dst.Permissions.Deny = append([]string(nil), s.Permissions.Deny...)
 	dst.Permissions.Ask = append([]string(nil), s.Permissions.Ask...)
+	dst.Permissions.BashAllow = append([]string(nil), s.Permissions.BashAllow...)
+	dst.Permissions.BashDeny = append([]string(nil), s.Permissions.BashDeny...)
 	dst.Model = s.Model
 	dst.Theme = s.Theme
 	dst.SearchProvider = s.SearchProvider
+	dst.WrapWidth = s.WrapWidth
+	dst.ResponseCacheTTL = s.ResponseCacheTTL
+	dst.SearchCacheTTL = s.SearchCacheTTL
+	dst.ModelSort = s.ModelSort
+	if s.AutoCompactThreshold != nil {
+		v := *s.AutoCompactThreshold
+		dst.AutoCompactThreshold = &v
+	}
+	dst.DefaultMode = s.DefaultMode
+	dst.MaxTokens = s.MaxTokens
+	dst.BashMaxOutput = s.BashMaxOutput
+	dst.MaxToolParallel = s.MaxToolParallel
+	dst.ImageMaxSize = s.ImageMaxSize
+	dst.ProtectedPaths = append([]string(nil), s.ProtectedPaths...)
+	dst.HistoryExclude = append([]string(nil), s.HistoryExclude...)
 	if s.AllowBypass != nil {
 		v := *s.AllowBypass
 		dst.AllowBypass = &v
 	}
+	if s.ConfirmClear != nil {
+		v := *s.ConfirmClear
+		dst.ConfirmClear = &v
+	}
+	if s.NoTelemetry != nil {
+		v := *s.NoTelemetry
+		dst.NoTelemetry = &v
+	}
+	if s.ShowSkillPrompts != nil {
+		v := *s.ShowSkillPrompts
+		dst.ShowSkillPrompts = &v
+	}
+	if s.ShowPromptModel != nil {
+		v := *s.ShowPromptModel
+		dst.ShowPromptModel = &v
+	}
+	if s.ShowCost != nil {
+		v := *s.ShowCost
+		dst.ShowCost = &v
+	}
+	if s.ShowThinking != nil {
+		v := *s.ShowThinking
+		dst.ShowThinking = &v
+	}
+	if s.ResponseCache != nil {
+		v := *s.ResponseCache
+		dst.ResponseCache = &v
+	}
+	if s.SearchCache != nil {
+		v := *s.SearchCache
+		dst.SearchCache = &v
+	}
+	if s.TerminalTitle != nil {
+		v := *s.TerminalTitle
+		dst.TerminalTitle = &v
+	}
+	if s.MCPSampling != nil {
+		v := *s.MCPSampling
+		dst.MCPSampling = &v
+	}
+	if s.SemanticMemory != nil {
+		v := *s.SemanticMemory
+		dst.SemanticMemory = &v
+	}
 	for k, v := range s.Env {
 		dst.Env[k] = v
 	}
@@ -271,6 +424,21 @@ func (s *Settings) Clone() *Settings {
 	for k, v := range s.DisabledTools {
 		dst.DisabledTools[k] = v
 	}
+	if s.ToolTimeouts != nil {
+		dst.ToolTimeouts = make(map[string]int, len(s.ToolTimeouts))
+		for k, v := range s.ToolTimeouts {
+			dst.ToolTimeouts[k] = v
+		}
+	}
+	if s.Keybindings != nil {
+		dst.Keybindings = maps.Clone(s.Keybindings)
+	}
+	if s.Workspaces != nil {
+		dst.Workspaces = make(map[string]Workspace, len(s.Workspaces))
+		for k, v := range s.Workspaces {
+			dst.Workspaces[k] = v.clone()
+		}
+	}
 	for event, hooks := range s.Hooks {
 		clonedHooks := make([]Hook, len(hooks))
 		for i, hook := range hooks {
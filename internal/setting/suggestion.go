@@ -50,6 +50,11 @@ func GenerateSuggestions(toolName string, args map[string]any, maxSuggestions in
 		if fp, ok := args["file_path"].(string); ok {
 			return suggestFileRules(toolName, fp)
 		}
+	case "MultiEdit":
+		// MultiEdit is governed by Edit rules (see BuildRule).
+		if fp, ok := args["file_path"].(string); ok {
+			return suggestFileRules("Edit", fp)
+		}
 	case "Skill":
 		if s, ok := args["skill"].(string); ok {
 			return suggestSkillRules(s)
@@ -0,0 +1,41 @@
+package setting
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Workspace bundles a provider/model, tool set, MCP servers, and operation
+// mode under one name so a whole setup can be switched at once with
+// "/workspace <name>" or "gen --workspace <name>". Empty fields leave the
+// current value unchanged.
+type Workspace struct {
+	Provider   string   `json:"provider,omitempty"`
+	Model      string   `json:"model,omitempty"`
+	Tools      []string `json:"tools,omitempty"`      // enabled built-in tools; all others are disabled
+	MCPServers []string `json:"mcpServers,omitempty"` // servers to connect; all others are disconnected
+	Mode       string   `json:"mode,omitempty"`       // normal, auto, bypass, or dontAsk
+}
+
+// ParseOperationMode maps a user-facing mode name to an OperationMode.
+func ParseOperationMode(name string) (OperationMode, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "normal", "default":
+		return ModeNormal, nil
+	case "auto", "autoaccept", "acceptedits", "accept-edits":
+		return ModeAutoAccept, nil
+	case "bypass", "bypasspermissions":
+		return ModeBypassPermissions, nil
+	case "dontask", "dont-ask":
+		return ModeDontAsk, nil
+	}
+	return ModeNormal, fmt.Errorf("unknown mode %q (want normal, auto, bypass, or dontAsk)", name)
+}
+
+func (w Workspace) clone() Workspace {
+	w.Tools = append([]string(nil), w.Tools...)
+	if w.MCPServers != nil {
+		w.MCPServers = append([]string{}, w.MCPServers...)
+	}
+	return w
+}
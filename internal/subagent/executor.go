@@ -16,6 +16,7 @@ import (
 	"github.com/yanmxa/gencode/internal/task"
 	"github.com/yanmxa/gencode/internal/tool"
 	"github.com/yanmxa/gencode/internal/tool/perm"
+	"github.com/yanmxa/gencode/internal/tool/scratchpad"
 	"github.com/yanmxa/gencode/internal/worktree"
 	"go.uber.org/zap"
 )
@@ -35,6 +36,7 @@ type Executor struct {
 	agentsPrompt        string                   // available agents section for capable subagents
 	mcpGetter           func() []core.ToolSchema // MCP tool schemas from parent
 	mcpRegistry         *mcp.Registry            // MCP registry for tool execution
+	permGate            perm.PermissionFunc      // extra gate applied on top of the agent's own mode
 }
 
 type SubagentSessionStore interface {
@@ -92,6 +94,13 @@ func (e *Executor) SetSessionStore(store SubagentSessionStore, parentSessionID s
 	e.parentSessionID = parentSessionID
 }
 
+// SetPermissionGate installs a check every tool call must also pass, on top
+// of the agent's own permission mode. Headless runs use it to apply the
+// --permission-mode policy.
+func (e *Executor) SetPermissionGate(gate perm.PermissionFunc) {
+	e.permGate = gate
+}
+
 // GetParentModelID returns the parent model ID
 func (e *Executor) GetParentModelID() string {
 	return e.parentModelID
@@ -294,7 +303,14 @@ func (e *Executor) buildAgent(ctx context.Context, rc *runConfig, agentCwd strin
 	// Tools — adapt legacy tool registry + MCP tools
 	toolSet := newAgentToolSet([]string(rc.config.Tools), []string(rc.config.DisallowedTools), e.mcpGetter)
 	schemas := toolSet.Tools()
-	tools := tool.AdaptToolRegistry(schemas, func() string { return agentCwd })
+	// Each run keeps its own scratchpad, dropped with the agent.
+	padID := "agent:" + generateShortID()
+	tools := tool.AdaptToolRegistry(schemas, func() string { return agentCwd }, tool.WithScratchpad(padID))
+	closeMCP := cleanup
+	cleanup = func() {
+		closeMCP()
+		scratchpad.Reset(padID)
+	}
 
 	// Add MCP tool executors
 	if e.mcpRegistry != nil {
@@ -312,6 +328,7 @@ func (e *Executor) buildAgent(ctx context.Context, rc *runConfig, agentCwd strin
 	// Wrap tools with permission decorator
 	permFn := perm.AsPermissionFunc(agentPermission(rc.permMode))
 	coreTools = tool.WithPermission(coreTools, permFn)
+	coreTools = tool.WithPermission(coreTools, e.permGate)
 
 	ag := core.NewAgent(core.Config{
 		LLM:       llm.NewClient(e.provider, rc.modelID, 0),
@@ -321,6 +338,8 @@ func (e *Executor) buildAgent(ctx context.Context, rc *runConfig, agentCwd strin
 		CWD:       agentCwd,
 		MaxTurns:  rc.maxTurns,
 		OutboxBuf: -1, // no outbox: subagents use direct ThinkAct path
+
+		ParallelTool: tool.ParallelSafe,
 	})
 
 	return ag, cleanup, nil
@@ -64,6 +64,7 @@ var toolProgressParams = map[string]string{
 	"Read":       "file_path",
 	"Write":      "file_path",
 	"Edit":       "file_path",
+	"MultiEdit":  "file_path",
 	"Glob":       "pattern",
 	"Grep":       "pattern",
 	"Bash":       "command",
@@ -251,6 +251,17 @@ var modelAliases = map[string]string{
 	"haiku":  "claude-haiku-4-5-20251001",
 }
 
+// ModelAlias returns the short alias for a full model ID, or "" when the
+// model has none.
+func ModelAlias(modelID string) string {
+	for alias, full := range modelAliases {
+		if full == modelID {
+			return alias
+		}
+	}
+	return ""
+}
+
 // resolveModelAlias returns the full model ID for a known alias,
 // or the input unchanged if it is not an alias.
 func resolveModelAlias(model string) string {
@@ -29,6 +29,7 @@ type BashTask struct {
 	exitCode int           // Exit code (if completed)
 	errMsg   string        // Error message (if failed)
 	output   bytes.Buffer  // Collected stdout/stderr
+	readPos  int           // Output already returned by ReadNewOutput
 	done     chan struct{} // Closed when task completes
 	doneOnce sync.Once     // Guards done channel close
 }
@@ -82,6 +83,12 @@ func (t *BashTask) GetDescription() string {
 func (t *BashTask) AppendOutput(data []byte) {
 	t.mu.Lock()
 	t.output.Write(data)
+	// Cap the in-memory buffer to the tail like AgentTask; the full output
+	// is in OutputFile. Dropped bytes move the read position back with them.
+	if over := t.output.Len() - maxOutputBufferSize; over > 0 {
+		t.output.Next(over)
+		t.readPos = max(t.readPos-over, 0)
+	}
 	outputFile := t.OutputFile
 	t.mu.Unlock()
 
@@ -91,6 +98,22 @@ func (t *BashTask) AppendOutput(data []byte) {
 	})
 }
 
+// Write appends p to the output, so the command's pipes can be copied
+// into the task as they produce output.
+func (t *BashTask) Write(p []byte) (int, error) {
+	t.AppendOutput(p)
+	return len(p), nil
+}
+
+// ReadNewOutput returns the output produced since the previous call.
+func (t *BashTask) ReadNewOutput() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := t.output.String()[t.readPos:]
+	t.readPos = t.output.Len()
+	return out
+}
+
 // GetOutput returns the current output
 func (t *BashTask) GetOutput() string {
 	t.mu.RLock()
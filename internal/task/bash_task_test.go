@@ -3,6 +3,7 @@ package task
 import (
 	"context"
 	"os/exec"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -296,3 +297,55 @@ func TestBashTask_ImplementsBackgroundTask(t *testing.T) {
 		t.Errorf("GetDescription() = %s, want 'Interface test'", bt.GetDescription())
 	}
 }
+
+func TestBashTask_ReadNewOutput(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "echo", "test")
+	cmd.Start()
+
+	task := NewBashTask("read-id", "make serve", "Dev server", cmd, ctx, cancel)
+	task.Write([]byte("listening\n"))
+	if got := task.ReadNewOutput(); got != "listening\n" {
+		t.Errorf("first read = %q", got)
+	}
+	if got := task.ReadNewOutput(); got != "" {
+		t.Errorf("read with nothing new = %q, want empty", got)
+	}
+	task.Write([]byte("GET /\n"))
+	if got := task.ReadNewOutput(); got != "GET /\n" {
+		t.Errorf("second read = %q", got)
+	}
+	if got := task.GetOutput(); got != "listening\nGET /\n" {
+		t.Errorf("GetOutput() = %q, want the full output", got)
+	}
+}
+
+func TestBashTask_OutputKeepsTail(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "echo", "test")
+	cmd.Start()
+
+	task := NewBashTask("tail-id", "yes", "Chatty task", cmd, ctx, cancel)
+	task.Write([]byte("read\n"))
+	task.ReadNewOutput()
+	task.Write([]byte("unread\n"))
+
+	// Overflow the buffer so the read and part of the unread output drop.
+	filler := strings.Repeat("x", maxOutputBufferSize-3)
+	task.Write([]byte(filler))
+	if got := task.GetOutput(); len(got) != maxOutputBufferSize || !strings.HasSuffix(got, filler) || !strings.HasPrefix(got, "ad\n") {
+		t.Fatalf("GetOutput() kept %d bytes starting %q, want the last %d bytes", len(got), got[:min(len(got), 8)], maxOutputBufferSize)
+	}
+	if got := task.ReadNewOutput(); got != "ad\n"+filler {
+		t.Errorf("ReadNewOutput() returned %d bytes, want the retained unread tail", len(got))
+	}
+
+	task.Write([]byte("next\n"))
+	if got := task.ReadNewOutput(); got != "next\n" {
+		t.Errorf("read after overflow = %q, want only the new output", got)
+	}
+}
@@ -147,6 +147,34 @@ func (m *Manager) Remove(id string) {
 	delete(m.tasks, id)
 }
 
+// Clear kills the running tasks of the given type and removes every task of
+// that type from the manager. It returns how many tasks were running.
+func (m *Manager) Clear(taskType TaskType) int {
+	m.mu.Lock()
+	var running []BackgroundTask
+	for id, task := range m.tasks {
+		if task.GetType() != taskType {
+			continue
+		}
+		if task.IsRunning() {
+			running = append(running, task)
+		}
+		delete(m.tasks, id)
+	}
+	m.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, task := range running {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = task.Kill()
+		}()
+	}
+	wg.Wait()
+	return len(running)
+}
+
 // cleanup removes all completed tasks older than maxAge
 func (m *Manager) cleanup(maxAge time.Duration) {
 	m.mu.Lock()
@@ -232,3 +232,35 @@ func TestManager_GetBashTask(t *testing.T) {
 		t.Error("retrieved task should match created task")
 	}
 }
+
+func TestManager_Clear(t *testing.T) {
+	m := NewManager()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sleep", "10")
+	cmd.Start()
+	running := m.CreateBashTask(cmd, "sleep 10", "Running", ctx, cancel)
+
+	doneCmd := exec.CommandContext(ctx, "echo", "test")
+	doneCmd.Start()
+	done := m.CreateBashTask(doneCmd, "echo test", "Done", ctx, cancel)
+	done.Complete(0, nil)
+
+	agent := NewAgentTask("agent-id", "Explore", "Agent", ctx, cancel)
+	m.RegisterTask(agent)
+
+	if n := m.Clear(TaskTypeBash); n != 1 {
+		t.Errorf("Clear() = %d, want 1 running task", n)
+	}
+	if running.IsRunning() {
+		t.Error("running bash task should be killed")
+	}
+	if _, ok := m.Get(done.ID); ok {
+		t.Error("finished bash task should be removed")
+	}
+	if _, ok := m.Get(agent.ID); !ok {
+		t.Error("agent tasks should be kept")
+	}
+}
@@ -16,6 +16,7 @@ type Service interface {
 	ListRunning() []BackgroundTask
 	Kill(id string) error
 	Remove(id string)
+	Clear(taskType TaskType) int
 
 	// output
 	SetOutputDir(dir string) error
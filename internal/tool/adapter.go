@@ -32,7 +32,8 @@ type InteractionFunc func(ctx context.Context, req *QuestionRequest) (*QuestionR
 type AdaptOption func(*adaptConfig)
 
 type adaptConfig struct {
-	askFn InteractionFunc
+	askFn        InteractionFunc
+	scratchpadID string
 }
 
 // WithInteraction sets the handler for interactive tools.
@@ -40,6 +41,27 @@ func WithInteraction(fn InteractionFunc) AdaptOption {
 	return func(c *adaptConfig) { c.askFn = fn }
 }
 
+// WithScratchpad makes the adapted tools use the scratchpad with id, so
+// agents of different sessions keep separate notes.
+func WithScratchpad(id string) AdaptOption {
+	return func(c *adaptConfig) { c.scratchpadID = id }
+}
+
+// scratchpadIDKey is the context key for the scratchpad a tool call uses.
+type scratchpadIDKey struct{}
+
+// WithScratchpadID returns a context whose tool calls use the scratchpad with id.
+func WithScratchpadID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, scratchpadIDKey{}, id)
+}
+
+// ScratchpadID returns the scratchpad a tool call uses. "" is the
+// interactive session's.
+func ScratchpadID(ctx context.Context) string {
+	id, _ := ctx.Value(scratchpadIDKey{}).(string)
+	return id
+}
+
 // AdaptTool wraps a legacy Tool as a core.Tool with a dynamic CWD resolver.
 func AdaptTool(t Tool, schema core.ToolSchema, cwd func() string) core.Tool {
 	return &toolAdapter{inner: t, schema: schema, cwd: cwd}
@@ -63,7 +85,7 @@ func AdaptToolRegistry(schemas []core.ToolSchema, cwd func() string, opts ...Ada
 	var adapted []core.Tool
 	for name, schema := range schemaByName {
 		if t, ok := Get(name); ok {
-			adapted = append(adapted, &toolAdapter{inner: t, schema: schema, cwd: cwd, askFn: cfg.askFn})
+			adapted = append(adapted, &toolAdapter{inner: t, schema: schema, cwd: cwd, askFn: cfg.askFn, scratchpadID: cfg.scratchpadID})
 		}
 	}
 	return core.NewTools(adapted...)
@@ -71,10 +93,11 @@ func AdaptToolRegistry(schemas []core.ToolSchema, cwd func() string, opts ...Ada
 
 // toolAdapter wraps a legacy Tool as a core.Tool.
 type toolAdapter struct {
-	inner  Tool
-	schema core.ToolSchema
-	cwd    func() string
-	askFn  InteractionFunc
+	inner        Tool
+	schema       core.ToolSchema
+	cwd          func() string
+	askFn        InteractionFunc
+	scratchpadID string
 }
 
 func (a *toolAdapter) Name() string            { return a.inner.Name() }
@@ -82,6 +105,9 @@ func (a *toolAdapter) Description() string     { return a.inner.Description() }
 func (a *toolAdapter) Schema() core.ToolSchema { return a.schema }
 
 func (a *toolAdapter) Execute(ctx context.Context, input map[string]any) (string, error) {
+	if a.scratchpadID != "" {
+		ctx = WithScratchpadID(ctx, a.scratchpadID)
+	}
 	cwd := ""
 	if a.cwd != nil {
 		cwd = a.cwd()
@@ -103,7 +129,9 @@ func (a *toolAdapter) Execute(ctx context.Context, input map[string]any) (string
 		}
 		result = it.ExecuteWithResponse(ctx, input, resp, cwd)
 	} else {
-		result = a.inner.Execute(ctx, input, cwd)
+		result = runWithTimeout(ctx, a.inner.Name(), input, func(ctx context.Context) toolresult.ToolResult {
+			return a.inner.Execute(ctx, input, cwd)
+		})
 	}
 
 	if result.HookResponse != nil {
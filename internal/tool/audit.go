@@ -0,0 +1,160 @@
+package tool
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/yanmxa/gencode/internal/tool/toolresult"
+)
+
+const (
+	// AuditEnvVar turns on the tool audit log when set to 1. GEN_DEBUG=1
+	// turns it on too.
+	AuditEnvVar = "GEN_AUDIT"
+
+	// auditMaxSizeMB is the size at which the audit log is rotated.
+	auditMaxSizeMB = 10
+	// auditMaxBackups is how many rotated audit logs are kept.
+	auditMaxBackups = 3
+	// auditMaxString caps string parameters so file contents written by a
+	// tool do not bloat the log.
+	auditMaxString = 1024
+)
+
+// auditSecretKey matches parameter names whose values are never logged.
+var auditSecretKey = regexp.MustCompile(`(?i)token|key|secret|password`)
+
+// auditSecretValue matches secrets inside string values, such as a Bash
+// command: bearer tokens, NAME_TOKEN=value assignments (and --api-key=value
+// flags), and sk- style API keys. Groups 1 and 2 are the parts that are kept.
+var auditSecretValue = regexp.MustCompile(
+	`(?i)(bearer\s+)[\w.~+/=-]+` +
+		`|([\w-]*(?:token|key|secret|password)=)(?:"[^"]*"|'[^']*'|\S+)` +
+		`|\bsk-[\w-]{16,}`)
+
+// auditEntry is one line of the audit log: a tool execution or a
+// permission decision.
+type auditEntry struct {
+	Time       time.Time      `json:"ts"`
+	Event      string         `json:"event"`
+	Tool       string         `json:"tool"`
+	Params     map[string]any `json:"params,omitempty"`
+	Success    *bool          `json:"success,omitempty"`
+	DurationMs *int64         `json:"duration_ms,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	Allowed    *bool          `json:"allowed,omitempty"`
+	Reason     string         `json:"reason,omitempty"`
+}
+
+var audit = struct {
+	once sync.Once
+	mu   sync.Mutex
+	w    io.Writer // nil when auditing is off
+}{}
+
+// openAuditLog opens ~/.gen/audit.jsonl when auditing is on.
+func openAuditLog() {
+	if os.Getenv(AuditEnvVar) != "1" && os.Getenv("GEN_DEBUG") != "1" {
+		return
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+	audit.w = &lumberjack.Logger{
+		Filename:   filepath.Join(home, ".gen", "audit.jsonl"),
+		MaxSize:    auditMaxSizeMB,
+		MaxBackups: auditMaxBackups,
+	}
+}
+
+func writeAudit(entry auditEntry) {
+	audit.once.Do(openAuditLog)
+	audit.mu.Lock()
+	defer audit.mu.Unlock()
+	if audit.w == nil {
+		return
+	}
+	entry.Time = time.Now()
+	entry.Params = redactParams(entry.Params)
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	_, _ = audit.w.Write(append(data, '\n'))
+}
+
+// auditExecution records a finished tool call.
+func auditExecution(name string, params map[string]any, result toolresult.ToolResult, duration time.Duration) {
+	ms := duration.Milliseconds()
+	writeAudit(auditEntry{
+		Event:      "execute",
+		Tool:       name,
+		Params:     params,
+		Success:    &result.Success,
+		DurationMs: &ms,
+		Error:      result.Error,
+	})
+}
+
+// auditPermission records whether a tool call was allowed to run.
+func auditPermission(name string, params map[string]any, allowed bool, reason string) {
+	writeAudit(auditEntry{
+		Event:   "permission",
+		Tool:    name,
+		Params:  params,
+		Allowed: &allowed,
+		Reason:  reason,
+	})
+}
+
+// redactParams returns a copy of params with secret-looking keys and values
+// masked and long strings shortened.
+func redactParams(params map[string]any) map[string]any {
+	if params == nil {
+		return nil
+	}
+	out := make(map[string]any, len(params))
+	for k, v := range params {
+		if auditSecretKey.MatchString(k) {
+			out[k] = "[REDACTED]"
+			continue
+		}
+		out[k] = redactValue(v)
+	}
+	return out
+}
+
+func redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		return redactParams(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = redactValue(item)
+		}
+		return out
+	case string:
+		v = redactSecrets(v)
+		if len(v) > auditMaxString {
+			return fmt.Sprintf("%s… (%d bytes)", v[:auditMaxString], len(v))
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// redactSecrets masks the secrets auditSecretValue finds in s.
+func redactSecrets(s string) string {
+	return auditSecretValue.ReplaceAllString(s, "${1}${2}[REDACTED]")
+}
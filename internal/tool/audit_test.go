@@ -0,0 +1,153 @@
+package tool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+// captureAudit sends audit entries to a buffer for the rest of the test.
+func captureAudit(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	audit.once.Do(openAuditLog)
+	var buf bytes.Buffer
+	audit.mu.Lock()
+	prev := audit.w
+	audit.w = &buf
+	audit.mu.Unlock()
+	t.Cleanup(func() {
+		audit.mu.Lock()
+		audit.w = prev
+		audit.mu.Unlock()
+	})
+	return &buf
+}
+
+func readAudit(t *testing.T, buf *bytes.Buffer) []auditEntry {
+	t.Helper()
+	var entries []auditEntry
+	for line := range strings.SplitSeq(strings.TrimSpace(buf.String()), "\n") {
+		var entry auditEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid audit line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestRegistryExecuteWritesAudit(t *testing.T) {
+	setTimeouts(t, map[string]time.Duration{"TestSleep": time.Minute})
+	buf := captureAudit(t)
+	r := NewRegistry()
+	r.Register(&sleepTool{delay: time.Millisecond})
+
+	r.Execute(context.Background(), "TestSleep", map[string]any{"path": "a.txt", "api_key": "sk-123"}, "")
+
+	entries := readAudit(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("got %d audit entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Event != "execute" || e.Tool != "TestSleep" || e.Success == nil || !*e.Success || e.DurationMs == nil || e.Time.IsZero() {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if e.Params["path"] != "a.txt" || e.Params["api_key"] != "[REDACTED]" {
+		t.Errorf("params = %v, want path kept and api_key redacted", e.Params)
+	}
+	if strings.Contains(buf.String(), "sk-123") {
+		t.Error("secret leaked into the audit log")
+	}
+}
+
+func TestPermissionToolWritesAudit(t *testing.T) {
+	buf := captureAudit(t)
+	tools := WithPermission(core.NewTools(stubCoreTool{"Bash"}), func(context.Context, string, map[string]any) (bool, string) {
+		return false, "denied by rule"
+	})
+
+	if _, err := tools.Get("Bash").Execute(context.Background(), map[string]any{"command": "rm -rf /"}); err == nil {
+		t.Fatal("expected the call to be blocked")
+	}
+
+	entries := readAudit(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("got %d audit entries, want only the permission decision", len(entries))
+	}
+	e := entries[0]
+	if e.Event != "permission" || e.Tool != "Bash" || e.Allowed == nil || *e.Allowed || e.Reason != "denied by rule" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+}
+
+func TestPermissionToolAuditsUnadaptedTools(t *testing.T) {
+	buf := captureAudit(t)
+	tools := WithPermission(core.NewTools(stubCoreTool{"mcp__docs__search"}), func(context.Context, string, map[string]any) (bool, string) {
+		return true, ""
+	})
+
+	if _, err := tools.Get("mcp__docs__search").Execute(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := readAudit(t, buf)
+	if len(entries) != 2 || entries[0].Event != "permission" || entries[1].Event != "execute" {
+		t.Fatalf("entries = %+v, want a permission decision then the execution", entries)
+	}
+	if !*entries[0].Allowed || !*entries[1].Success {
+		t.Errorf("entries = %+v, want an allowed, successful call", entries)
+	}
+}
+
+func TestRedactParams(t *testing.T) {
+	long := strings.Repeat("x", auditMaxString+10)
+	got := redactParams(map[string]any{
+		"Authorization_Token": "abc",
+		"headers":             map[string]any{"X-Api-Key": "abc", "Accept": "json"},
+		"items":               []any{map[string]any{"password": "pw"}, "plain"},
+		"content":             long,
+		"count":               3,
+	})
+
+	if got["Authorization_Token"] != "[REDACTED]" {
+		t.Errorf("token = %v", got["Authorization_Token"])
+	}
+	headers := got["headers"].(map[string]any)
+	if headers["X-Api-Key"] != "[REDACTED]" || headers["Accept"] != "json" {
+		t.Errorf("nested map = %v", headers)
+	}
+	items := got["items"].([]any)
+	if items[0].(map[string]any)["password"] != "[REDACTED]" || items[1] != "plain" {
+		t.Errorf("slice = %v", items)
+	}
+	if content := got["content"].(string); !strings.HasSuffix(content, "… (1034 bytes)") || len(content) > auditMaxString+20 {
+		t.Errorf("long string not shortened: %d bytes", len(content))
+	}
+	if got["count"] != 3 {
+		t.Errorf("count = %v", got["count"])
+	}
+}
+
+func TestRedactParams_SecretsInCommands(t *testing.T) {
+	tests := []struct {
+		command, want string
+	}{
+		{`curl -H "Authorization: Bearer abc.DEF-123" https://x`, `curl -H "Authorization: Bearer [REDACTED]" https://x`},
+		{`GITHUB_TOKEN=ghp_abc123 gh pr list`, `GITHUB_TOKEN=[REDACTED] gh pr list`},
+		{`export API_KEY="two words" && run`, `export API_KEY=[REDACTED] && run`},
+		{`tool --api-key=abc --verbose`, `tool --api-key=[REDACTED] --verbose`},
+		{`echo sk-proj-0123456789abcdefXYZ | pbcopy`, `echo [REDACTED] | pbcopy`},
+		{`git checkout task-1234567890abcdefgh && ls -la`, `git checkout task-1234567890abcdefgh && ls -la`},
+	}
+	for _, tt := range tests {
+		got := redactParams(map[string]any{"command": tt.command})
+		if got["command"] != tt.want {
+			t.Errorf("command %q logged as %q, want %q", tt.command, got["command"], tt.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package tool
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/yanmxa/gencode/internal/core"
 	"github.com/yanmxa/gencode/internal/tool/toolresult"
@@ -54,18 +55,26 @@ func (p *PreparedToolCall) Execute(ctx context.Context, cwd string, approved boo
 		if mcpExec == nil {
 			return toolresult.ToolResult{}, fmt.Errorf("mcp executor not configured for tool: %s", p.Call.Name)
 		}
-		return mcpExec.ExecuteMCP(ctx, p.Call.Name, p.Params)
+		start := time.Now()
+		result, err := mcpExec.ExecuteMCP(ctx, p.Call.Name, p.Params)
+		audited := result
+		if err != nil {
+			audited = toolresult.NewErrorResult(p.Call.Name, err.Error())
+		}
+		auditExecution(p.Call.Name, p.Params, audited, time.Since(start))
+		return result, err
 	}
 
 	if p.Tool == nil {
 		return toolresult.ToolResult{}, fmt.Errorf("tool not resolved: %s", p.Call.Name)
 	}
 
-	if approved {
-		if pat, ok := p.Tool.(PermissionAwareTool); ok && pat.RequiresPermission() {
-			return pat.ExecuteApproved(ctx, p.Params, cwd), nil
+	return runWithTimeout(ctx, p.Tool.Name(), p.Params, func(ctx context.Context) toolresult.ToolResult {
+		if approved {
+			if pat, ok := p.Tool.(PermissionAwareTool); ok && pat.RequiresPermission() {
+				return pat.ExecuteApproved(ctx, p.Params, cwd)
+			}
 		}
-	}
-
-	return p.Tool.Execute(ctx, p.Params, cwd), nil
+		return p.Tool.Execute(ctx, p.Params, cwd)
+	}), nil
 }
@@ -0,0 +1,136 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+// ErrCancelledByUser is the error a tool call returns when the user
+// cancels it with RunningCalls.Cancel.
+var ErrCancelledByUser = errors.New("tool call cancelled by user")
+
+// RunningCalls tracks the tool calls in flight so the user can cancel them
+// without stopping the turn. The zero value is ready to use.
+type RunningCalls struct {
+	mu      sync.Mutex
+	next    int
+	cancels map[int]context.CancelFunc
+}
+
+// Cancel cancels every tool call in flight and reports whether there was
+// one. Each cancelled call returns ErrCancelledByUser at once, and the
+// agent carries on with the next inference.
+func (r *RunningCalls) Cancel() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, cancel := range r.cancels {
+		cancel()
+	}
+	cancelled := len(r.cancels) > 0
+	r.cancels = nil
+	return cancelled
+}
+
+func (r *RunningCalls) start(ctx context.Context) (context.Context, int) {
+	ctx, cancel := context.WithCancel(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancels == nil {
+		r.cancels = make(map[int]context.CancelFunc)
+	}
+	r.next++
+	r.cancels[r.next] = cancel
+	return ctx, r.next
+}
+
+func (r *RunningCalls) finish(id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cancel, ok := r.cancels[id]; ok {
+		cancel()
+		delete(r.cancels, id)
+	}
+}
+
+// WithCancel wraps core.Tools so that each call is registered in calls
+// while it runs and can be cancelled on its own. nil calls returns inner
+// unchanged.
+func WithCancel(inner core.Tools, calls *RunningCalls) core.Tools {
+	if calls == nil {
+		return inner
+	}
+	return &cancelTools{inner: inner, calls: calls}
+}
+
+// cancelTools wraps a core.Tools and injects the cancellable call on Get().
+type cancelTools struct {
+	inner core.Tools
+	calls *RunningCalls
+}
+
+func (ct *cancelTools) Get(name string) core.Tool {
+	t := ct.inner.Get(name)
+	if t == nil {
+		return nil
+	}
+	return &cancelTool{inner: t, calls: ct.calls}
+}
+
+func (ct *cancelTools) All() []core.Tool           { return ct.inner.All() }
+func (ct *cancelTools) Add(tool core.Tool)         { ct.inner.Add(tool) }
+func (ct *cancelTools) Remove(name string)         { ct.inner.Remove(name) }
+func (ct *cancelTools) Schemas() []core.ToolSchema { return ct.inner.Schemas() }
+
+// cancelTool wraps a single core.Tool so that it can be cancelled.
+type cancelTool struct {
+	inner core.Tool
+	calls *RunningCalls
+}
+
+func (ct *cancelTool) Name() string            { return ct.inner.Name() }
+func (ct *cancelTool) Description() string     { return ct.inner.Description() }
+func (ct *cancelTool) Schema() core.ToolSchema { return ct.inner.Schema() }
+
+// Execute runs the tool under its own context. When the user cancels the
+// call, it returns ErrCancelledByUser without waiting for the tool, which
+// sees its context cancelled and is left to wind down in the background.
+func (ct *cancelTool) Execute(ctx context.Context, input map[string]any) (string, error) {
+	callCtx, id := ct.calls.start(ctx)
+	defer ct.calls.finish(id)
+
+	type outcome struct {
+		content string
+		err     error
+		panic   any
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- outcome{panic: r}
+			}
+		}()
+		content, err := ct.inner.Execute(callCtx, input)
+		done <- outcome{content: content, err: err}
+	}()
+
+	var o outcome
+	select {
+	case o = <-done:
+	case <-callCtx.Done():
+		if ctx.Err() != nil {
+			// The turn was stopped, not the call: wait as before.
+			o = <-done
+			break
+		}
+		return "", ErrCancelledByUser
+	}
+	// Re-raise panics here so the agent's recovery still sees them.
+	if o.panic != nil {
+		panic(o.panic)
+	}
+	return o.content, o.err
+}
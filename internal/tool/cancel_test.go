@@ -0,0 +1,83 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+// blockingTool runs until its context is cancelled or, when release is
+// set, until release is closed regardless of the context.
+type blockingTool struct {
+	stubCoreTool
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingTool) Execute(ctx context.Context, _ map[string]any) (string, error) {
+	close(b.started)
+	if b.release != nil {
+		<-b.release
+		return "late", nil
+	}
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestWithCancel_CancelsRunningCallOnly(t *testing.T) {
+	var calls RunningCalls
+	if calls.Cancel() {
+		t.Fatal("Cancel with nothing running should report false")
+	}
+
+	// The tool ignores cancellation; the call must return anyway.
+	slow := &blockingTool{stubCoreTool: stubCoreTool{"WebFetch"}, started: make(chan struct{}), release: make(chan struct{})}
+	defer close(slow.release)
+	tools := WithCancel(core.NewTools(slow, stubCoreTool{"Read"}), &calls)
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := tools.Get("WebFetch").Execute(context.Background(), nil)
+		errc <- err
+	}()
+	<-slow.started
+	if !calls.Cancel() {
+		t.Fatal("Cancel should report the running call")
+	}
+	select {
+	case err := <-errc:
+		if !errors.Is(err, ErrCancelledByUser) {
+			t.Fatalf("err = %v, want ErrCancelledByUser", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("cancelled call did not return")
+	}
+
+	if out, err := tools.Get("Read").Execute(context.Background(), nil); err != nil || out != "Read ran" {
+		t.Errorf("next call = %q, %v; want it to run normally", out, err)
+	}
+	if calls.Cancel() {
+		t.Error("finished calls should not stay registered")
+	}
+}
+
+func TestWithCancel_StoppedTurnIsNotReportedAsUserCancel(t *testing.T) {
+	var calls RunningCalls
+	slow := &blockingTool{stubCoreTool: stubCoreTool{"Bash"}, started: make(chan struct{})}
+	tools := WithCancel(core.NewTools(slow), &calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		_, err := tools.Get("Bash").Execute(ctx, nil)
+		errc <- err
+	}()
+	<-slow.started
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want the tool's own context error", err)
+	}
+}
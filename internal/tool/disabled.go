@@ -0,0 +1,97 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+// disabledAlternatives maps a built-in tool to the tools that can usually
+// cover the same ground. Only alternatives that are still enabled are
+// suggested back to the model.
+var disabledAlternatives = map[string][]string{
+	"Bash":      {"Read", "Glob", "Grep", "Edit", "Write"},
+	"Edit":      {"MultiEdit", "Write"},
+	"MultiEdit": {"Edit", "Write"},
+	"Write":     {"Edit"},
+	"Read":      {"Grep", "Bash"},
+	"Glob":      {"Grep", "Bash"},
+	"Grep":      {"Glob", "Read", "Bash"},
+	"WebFetch":  {"WebSearch", "Bash"},
+	"WebSearch": {"WebFetch"},
+}
+
+// WithDisabled wraps core.Tools so that calls to disabled tools resolve to
+// a stub that explains the tool is turned off, instead of failing as an
+// unknown tool. Disabled tools stay out of All() and Schemas(), so the model
+// is never offered them. An empty disabled set returns inner unchanged.
+func WithDisabled(inner core.Tools, disabled map[string]bool) core.Tools {
+	if len(disabled) == 0 {
+		return inner
+	}
+	return &disabledTools{inner: inner, disabled: disabled}
+}
+
+// disabledTools wraps a core.Tools and resolves disabled names to stubs on Get().
+type disabledTools struct {
+	inner    core.Tools
+	disabled map[string]bool
+}
+
+func (dt *disabledTools) Get(name string) core.Tool {
+	if t := dt.inner.Get(name); t != nil {
+		return t
+	}
+	if !dt.disabled[name] {
+		return nil
+	}
+	return &disabledTool{name: name, alternatives: dt.alternatives(name)}
+}
+
+func (dt *disabledTools) All() []core.Tool           { return dt.inner.All() }
+func (dt *disabledTools) Add(tool core.Tool)         { dt.inner.Add(tool) }
+func (dt *disabledTools) Remove(name string)         { dt.inner.Remove(name) }
+func (dt *disabledTools) Schemas() []core.ToolSchema { return dt.inner.Schemas() }
+
+// alternatives returns the enabled tools worth suggesting in place of name.
+func (dt *disabledTools) alternatives(name string) []string {
+	var out []string
+	for _, alt := range disabledAlternatives[name] {
+		if dt.inner.Get(alt) != nil {
+			out = append(out, alt)
+		}
+	}
+	return out
+}
+
+// disabledTool is the stub returned for a disabled tool name.
+type disabledTool struct {
+	name         string
+	alternatives []string
+}
+
+func (d *disabledTool) Name() string        { return d.name }
+func (d *disabledTool) Description() string { return "disabled" }
+func (d *disabledTool) Schema() core.ToolSchema {
+	return core.ToolSchema{Name: d.name, Description: d.Description()}
+}
+
+func (d *disabledTool) Execute(context.Context, map[string]any) (string, error) {
+	return "", errors.New(DisabledToolMessage(d.name, d.alternatives))
+}
+
+// DisabledToolMessage builds the tool result returned when the model calls
+// a tool the user has disabled.
+func DisabledToolMessage(name string, alternatives []string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "The %s tool is disabled in this session and cannot be used. Do not call it again.", name)
+	if len(alternatives) > 0 {
+		fmt.Fprintf(&sb, " Use %s instead if it fits the task.", strings.Join(alternatives, " or "))
+	} else {
+		sb.WriteString(" Continue with the tools that are still available, or ask the user to re-enable it.")
+	}
+	return sb.String()
+}
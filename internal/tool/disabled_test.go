@@ -0,0 +1,70 @@
+package tool
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+type stubCoreTool struct{ name string }
+
+func (s stubCoreTool) Name() string            { return s.name }
+func (s stubCoreTool) Description() string     { return s.name }
+func (s stubCoreTool) Schema() core.ToolSchema { return core.ToolSchema{Name: s.name} }
+func (s stubCoreTool) Execute(context.Context, map[string]any) (string, error) {
+	return s.name + " ran", nil
+}
+
+func TestWithDisabled_ExplainsDisabledTool(t *testing.T) {
+	inner := core.NewTools(stubCoreTool{"Read"}, stubCoreTool{"Grep"})
+	var checked []string
+	gated := WithPermission(inner, func(_ context.Context, name string, _ map[string]any) (bool, string) {
+		checked = append(checked, name)
+		return false, "would prompt"
+	})
+	tools := WithDisabled(gated, map[string]bool{"Bash": true})
+
+	if len(tools.Schemas()) != 2 || len(tools.All()) != 2 {
+		t.Fatalf("disabled tool must not be advertised, got %d schemas", len(tools.Schemas()))
+	}
+
+	bash := tools.Get("Bash")
+	if bash == nil {
+		t.Fatal("expected disabled tool to resolve to a stub")
+	}
+	_, err := bash.Execute(context.Background(), nil)
+	if err == nil {
+		t.Fatal("expected disabled tool call to fail")
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "Bash tool is disabled") {
+		t.Errorf("message should say the tool is disabled, got %q", msg)
+	}
+	if !strings.Contains(msg, "Use Read or Grep instead") {
+		t.Errorf("message should suggest enabled alternatives, got %q", msg)
+	}
+	if len(checked) != 0 {
+		t.Errorf("disabled tool must not reach the permission check, got %v", checked)
+	}
+
+	if tools.Get("Nope") != nil {
+		t.Error("unknown tools should still resolve to nil")
+	}
+}
+
+func TestWithDisabled_NoAlternativesAvailable(t *testing.T) {
+	tools := WithDisabled(core.NewTools(), map[string]bool{"Write": true})
+	_, err := tools.Get("Write").Execute(context.Background(), nil)
+	if err == nil || !strings.Contains(err.Error(), "still available") {
+		t.Fatalf("expected fallback guidance, got %v", err)
+	}
+}
+
+func TestWithDisabled_EmptySetIsPassthrough(t *testing.T) {
+	inner := core.NewTools()
+	if WithDisabled(inner, nil) != inner {
+		t.Fatal("expected inner tools to be returned unchanged")
+	}
+}
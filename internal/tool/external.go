@@ -0,0 +1,165 @@
+package tool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/tool/toolresult"
+)
+
+const (
+	// IconExternal is shown for tools contributed by plugins.
+	IconExternal = "🧩"
+
+	defaultExternalTimeout = 60 * time.Second
+	maxExternalOutput      = 30000
+)
+
+// CommandSpec declares a tool backed by an external command, such as one
+// shipped by a plugin. The command receives the tool input as a JSON object
+// on stdin; its stdout becomes the tool result and a non-zero exit marks the
+// result as an error.
+type CommandSpec struct {
+	Name        string
+	Description string
+	InputSchema map[string]any // JSON Schema; defaults to an empty object schema
+	Command     string
+	Args        []string
+	Env         []string      // extra KEY=value pairs appended to os.Environ()
+	Timeout     time.Duration // 0 = defaultExternalTimeout
+	Source      string        // attribution, e.g. "plugin:jira"
+}
+
+// externalTools holds the schemas and sources of registered command tools,
+// keyed by tool name. The tools themselves live in the default registry.
+var (
+	externalMu    sync.RWMutex
+	externalTools = map[string]CommandSpec{}
+)
+
+// RegisterCommandTool registers a command-backed tool so it is executable
+// and listed by GetToolSchemas. Names that collide with an existing tool are
+// rejected so plugins cannot shadow built-ins or each other.
+func RegisterCommandTool(spec CommandSpec) error {
+	if spec.Name == "" || spec.Command == "" {
+		return fmt.Errorf("tool %q: name and command are required", spec.Name)
+	}
+	if strings.ContainsAny(spec.Name, " /") || strings.HasPrefix(spec.Name, "mcp__") {
+		return fmt.Errorf("tool %q: invalid name", spec.Name)
+	}
+	if _, exists := Get(spec.Name); exists {
+		return fmt.Errorf("tool %q: name already in use", spec.Name)
+	}
+	externalMu.Lock()
+	externalTools[spec.Name] = spec
+	externalMu.Unlock()
+	Register(&commandTool{spec: spec})
+	return nil
+}
+
+// ResetCommandTools removes every registered command tool.
+func ResetCommandTools() {
+	externalMu.Lock()
+	defer externalMu.Unlock()
+	for name := range externalTools {
+		Unregister(name)
+	}
+	externalTools = map[string]CommandSpec{}
+}
+
+// ToolSource returns the attribution of a command tool (e.g. "plugin:jira"),
+// or "" for built-in tools.
+func ToolSource(name string) string {
+	externalMu.RLock()
+	defer externalMu.RUnlock()
+	return externalTools[name].Source
+}
+
+// commandToolSchemas returns the schemas of registered command tools, sorted by name.
+func commandToolSchemas() []core.ToolSchema {
+	externalMu.RLock()
+	defer externalMu.RUnlock()
+	schemas := make([]core.ToolSchema, 0, len(externalTools))
+	for _, spec := range externalTools {
+		params := spec.InputSchema
+		if params == nil {
+			params = map[string]any{"type": "object", "properties": map[string]any{}}
+		}
+		schemas = append(schemas, core.ToolSchema{
+			Name:        spec.Name,
+			Description: spec.Description,
+			Parameters:  params,
+		})
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].Name < schemas[j].Name })
+	return schemas
+}
+
+// commandTool runs a CommandSpec.
+type commandTool struct {
+	spec CommandSpec
+}
+
+func (t *commandTool) Name() string        { return t.spec.Name }
+func (t *commandTool) Description() string { return t.spec.Description }
+func (t *commandTool) Icon() string        { return IconExternal }
+
+func (t *commandTool) Execute(ctx context.Context, params map[string]any, cwd string) toolresult.ToolResult {
+	start := time.Now()
+	input, err := json.Marshal(params)
+	if err != nil {
+		return toolresult.NewErrorResult(t.Name(), "failed to encode input: "+err.Error())
+	}
+
+	timeout := t.spec.Timeout
+	if timeout <= 0 {
+		timeout = defaultExternalTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, t.spec.Command, t.spec.Args...)
+	cmd.Dir = cwd
+	cmd.Env = append(os.Environ(), t.spec.Env...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	runErr := cmd.Run()
+	output := stdout.String()
+	if len(output) > maxExternalOutput {
+		output = output[:maxExternalOutput] + "\n... (output truncated)"
+	}
+
+	if runErr != nil {
+		msg := runErr.Error()
+		if ctx.Err() == context.DeadlineExceeded {
+			msg = fmt.Sprintf("timed out after %s", timeout)
+		}
+		if errOut := strings.TrimSpace(stderr.String()); errOut != "" {
+			msg += ": " + errOut
+		}
+		return toolresult.NewErrorResult(t.Name(), msg)
+	}
+
+	return toolresult.ToolResult{
+		Success: true,
+		Output:  output,
+		Metadata: toolresult.ResultMetadata{
+			Title:    t.Name(),
+			Icon:     t.Icon(),
+			Subtitle: t.spec.Source,
+			Duration: time.Since(start),
+		},
+	}
+}
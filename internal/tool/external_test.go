@@ -0,0 +1,88 @@
+package tool
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRegisterCommandTool(t *testing.T) {
+	t.Cleanup(ResetCommandTools)
+
+	err := RegisterCommandTool(CommandSpec{
+		Name:        "EchoInput",
+		Description: "Echo the tool input",
+		Command:     "sh",
+		Args:        []string{"-c", `cat; printf " %s" "$GREETING"`},
+		Env:         []string{"GREETING=hello"},
+		Source:      "plugin:demo",
+	})
+	if err != nil {
+		t.Fatalf("RegisterCommandTool() error: %v", err)
+	}
+
+	found := false
+	for _, s := range GetToolSchemas() {
+		if s.Name == "EchoInput" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("command tool missing from GetToolSchemas")
+	}
+	if got := ToolSource("EchoInput"); got != "plugin:demo" {
+		t.Errorf("ToolSource() = %q, want plugin:demo", got)
+	}
+	if got := ToolSource("Unknown"); got != "" {
+		t.Errorf("unregistered tools should have no source, got %q", got)
+	}
+
+	result := Execute(context.Background(), "EchoInput", map[string]any{"q": "x"}, t.TempDir())
+	if !result.Success {
+		t.Fatalf("Execute() failed: %s", result.Output)
+	}
+	if result.Output != `{"q":"x"} hello` {
+		t.Errorf("Execute() output = %q", result.Output)
+	}
+
+	ResetCommandTools()
+	if _, ok := Get("EchoInput"); ok {
+		t.Error("expected tool to be unregistered")
+	}
+	if got := ToolSource("EchoInput"); got != "" {
+		t.Errorf("reset tools should have no source, got %q", got)
+	}
+}
+
+func TestRegisterCommandToolRejectsCollisions(t *testing.T) {
+	t.Cleanup(ResetCommandTools)
+
+	if err := RegisterCommandTool(CommandSpec{Name: "mcp__x__y", Command: "true"}); err == nil {
+		t.Error("expected mcp__ prefix to be rejected")
+	}
+	if err := RegisterCommandTool(CommandSpec{Name: "Lookup", Command: "true", Source: "plugin:a"}); err != nil {
+		t.Fatalf("RegisterCommandTool() error: %v", err)
+	}
+	if err := RegisterCommandTool(CommandSpec{Name: "lookup", Command: "true", Source: "plugin:b"}); err == nil {
+		t.Error("expected case-insensitive collision between plugins to be rejected")
+	}
+}
+
+func TestCommandToolFailureReportsStderr(t *testing.T) {
+	t.Cleanup(ResetCommandTools)
+
+	if err := RegisterCommandTool(CommandSpec{
+		Name:    "Fails",
+		Command: "sh",
+		Args:    []string{"-c", "echo boom >&2; exit 3"},
+	}); err != nil {
+		t.Fatalf("RegisterCommandTool() error: %v", err)
+	}
+	result := Execute(context.Background(), "Fails", nil, t.TempDir())
+	if result.Success {
+		t.Fatal("expected failure")
+	}
+	if !strings.Contains(result.Error, "boom") {
+		t.Errorf("expected stderr in error, got %q", result.Error)
+	}
+}
@@ -1,7 +1,6 @@
 package fs
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -11,7 +10,6 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
-	"syscall"
 	"time"
 
 	"github.com/yanmxa/gencode/internal/task"
@@ -23,6 +21,10 @@ import (
 const (
 	IconBash      = "$"
 	cwdFileEnvVar = "GENCODE_CWD_FILE"
+
+	// maxBashTimeout caps the timeout a call may ask for unless the
+	// toolTimeouts setting allows longer or sets no limit.
+	maxBashTimeout = 600 * time.Second
 )
 
 // BashTool executes shell commands
@@ -71,16 +73,14 @@ func (t *BashTool) ExecuteApproved(ctx context.Context, params map[string]any, c
 	description := tool.GetString(params, "description")
 	runBackground := tool.GetBool(params, "run_in_background")
 
-	// Get timeout (default 120 seconds, max 600 seconds)
-	timeout := min(time.Duration(tool.GetFloat64(params, "timeout", 120000))*time.Millisecond, 600*time.Second)
+	timeout := bashTimeout(tool.Timeout(t.Name()), params)
 
 	// Handle background execution
 	if runBackground {
 		return t.executeBackground(ctx, command, description, cwd, timeout)
 	}
 
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(ctx, timeout)
+	ctx, cancel := withOptionalTimeout(ctx, timeout)
 	defer cancel()
 
 	trackedCommand, trackedFile, cleanup := prepareCwdTracking(command)
@@ -93,25 +93,22 @@ func (t *BashTool) ExecuteApproved(ctx context.Context, params map[string]any, c
 	if trackedFile != "" {
 		cmd.Env = append(cmd.Env, cwdFileEnvVar+"="+trackedFile)
 	}
+	killProcessGroup(cmd)
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	// Output is capped as it arrives; the combined buffer keeps stdout and
+	// stderr interleaved as a terminal would show them.
+	limit := maxOutput(params)
+	stdout, stderr, combined := newCappedBuffer(limit), newCappedBuffer(limit), newCappedBuffer(limit)
+	cmd.Stdout = io.MultiWriter(stdout, combined)
+	cmd.Stderr = io.MultiWriter(stderr, combined)
 
 	err := cmd.Run()
 	duration := time.Since(start)
 
 	output := stdout.String()
 	errOutput := stderr.String()
-
-	// Combine output
-	fullOutput := output
-	if errOutput != "" {
-		if fullOutput != "" {
-			fullOutput += "\n"
-		}
-		fullOutput += errOutput
-	}
+	fullOutput := combined.String()
+	truncated := combined.Truncated()
 
 	// Count lines
 	lineCount := 0
@@ -119,14 +116,6 @@ func (t *BashTool) ExecuteApproved(ctx context.Context, params map[string]any, c
 		lineCount = strings.Count(strings.TrimSuffix(fullOutput, "\n"), "\n") + 1
 	}
 
-	// Truncate if too long
-	const maxLen = 30000
-	truncated := false
-	if len(fullOutput) > maxLen {
-		fullOutput = fullOutput[:maxLen] + "\n... (output truncated)"
-		truncated = true
-	}
-
 	// Build CC-compatible structured response for hooks
 	hookResponse := map[string]any{
 		"stdout":           output,
@@ -145,7 +134,7 @@ func (t *BashTool) ExecuteApproved(ctx context.Context, params map[string]any, c
 			return toolresult.ToolResult{
 				Success:      false,
 				Output:       fullOutput,
-				Error:        "command timed out after " + timeout.String(),
+				Error:        fmt.Sprintf("command timed out after %gs and was killed", timeout.Seconds()),
 				HookResponse: hookResponse,
 				Metadata: toolresult.ResultMetadata{
 					Title:     t.Name(),
@@ -211,6 +200,31 @@ func (t *BashTool) ExecuteApproved(ctx context.Context, params map[string]any, c
 	}
 }
 
+// bashTimeout returns the limit for one call, 0 meaning none. A call's own
+// timeout argument wins but is capped at 600s, or at the configured Bash
+// timeout when that is longer; without one the configured timeout applies.
+// A configured timeout of 0 means no limit, so it lifts the cap too.
+func bashTimeout(configured time.Duration, params map[string]any) time.Duration {
+	ms := tool.GetFloat64(params, "timeout", 0)
+	if ms <= 0 {
+		return max(configured, 0)
+	}
+	requested := time.Duration(ms) * time.Millisecond
+	if configured <= 0 {
+		return requested
+	}
+	return min(requested, max(maxBashTimeout, configured))
+}
+
+// withOptionalTimeout is context.WithTimeout, except that a timeout of 0
+// only makes the context cancellable.
+func withOptionalTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
 // Execute implements the Tool interface (for permission-unaware execution)
 func (t *BashTool) Execute(ctx context.Context, params map[string]any, cwd string) toolresult.ToolResult {
 	// This will be called if permission flow is bypassed
@@ -219,8 +233,7 @@ func (t *BashTool) Execute(ctx context.Context, params map[string]any, cwd strin
 
 // executeBackground runs the command in the background and returns immediately
 func (t *BashTool) executeBackground(ctx context.Context, command, description, cwd string, timeout time.Duration) toolresult.ToolResult {
-	// Create context with timeout for background task
-	taskCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	taskCtx, cancel := withOptionalTimeout(context.Background(), timeout)
 
 	// Create command
 	cmd := exec.CommandContext(taskCtx, "bash", "-c", command)
@@ -228,7 +241,7 @@ func (t *BashTool) executeBackground(ctx context.Context, command, description,
 	cmd.Env = bashEnv()
 
 	// Set process group so we can kill all child processes
-	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	killProcessGroup(cmd)
 
 	// Set up pipes for stdout and stderr
 	stdout, err := cmd.StdoutPipe()
@@ -274,36 +287,25 @@ func (t *BashTool) executeBackground(ctx context.Context, command, description,
 	// Register with task manager
 	bgTask := task.Default().CreateBashTask(cmd, command, description, taskCtx, cancel)
 
-	// Start goroutine to collect output and wait for completion
+	// Stream output into the task as it arrives so BashOutput and the TUI
+	// can show it while the command runs.
 	go func() {
 		defer cancel()
 
-		// Read stdout and stderr concurrently
-		var stdoutBuf, stderrBuf bytes.Buffer
 		var wg sync.WaitGroup
 		wg.Add(2)
 		go func() {
 			defer wg.Done()
-			_, _ = io.Copy(&stdoutBuf, stdout)
+			_, _ = io.Copy(bgTask, stdout)
 		}()
 		go func() {
 			defer wg.Done()
-			_, _ = io.Copy(&stderrBuf, stderr)
+			_, _ = io.Copy(bgTask, stderr)
 		}()
 
-		// Wait for command to complete, then wait for pipe drains
-		err := cmd.Wait()
+		// Drain the pipes before Wait closes them
 		wg.Wait()
-
-		// Combine output
-		output := stdoutBuf.String()
-		if stderrBuf.Len() > 0 {
-			if output != "" {
-				output += "\n"
-			}
-			output += stderrBuf.String()
-		}
-		bgTask.AppendOutput([]byte(output))
+		err := cmd.Wait()
 
 		// Get exit code
 		exitCode := 0
@@ -320,7 +322,7 @@ func (t *BashTool) executeBackground(ctx context.Context, command, description,
 	// Return immediately with task ID
 	return toolresult.ToolResult{
 		Success: true,
-		Output:  fmt.Sprintf("Task started in background.\nTask ID: %s\nPID: %d\nCommand: %s\nOutputFile: %s", bgTask.ID, bgTask.PID, command, bgTask.OutputFile),
+		Output:  fmt.Sprintf("Task started in background.\nTask ID: %s\nPID: %d\nCommand: %s\nOutputFile: %s\nUse BashOutput with this ID to read new output, or KillBash to stop it.", bgTask.ID, bgTask.PID, command, bgTask.OutputFile),
 		HookResponse: map[string]any{
 			"backgroundTask": map[string]any{
 				"taskId":      bgTask.ID,
@@ -0,0 +1,131 @@
+package fs
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/yanmxa/gencode/internal/tool"
+)
+
+const (
+	// DefaultMaxOutput is how many bytes of output a Bash call returns when
+	// neither the call nor the bashMaxOutput setting sets a limit.
+	DefaultMaxOutput = 30000
+
+	// killWaitDelay bounds how long a killed command may keep its output
+	// pipes open (e.g. through a process that left the group) before Wait
+	// gives up on them.
+	killWaitDelay = 2 * time.Second
+)
+
+var maxOutputProvider atomic.Value // stores func() int
+
+// SetMaxOutputProvider registers the source of the bashMaxOutput setting.
+// fn returns 0 when it is unset.
+func SetMaxOutputProvider(fn func() int) {
+	maxOutputProvider.Store(fn)
+}
+
+// maxOutput returns the output cap for a call: its max_output argument, else
+// the configured setting, else DefaultMaxOutput.
+func maxOutput(params map[string]any) int {
+	if n := tool.GetInt(params, "max_output", 0); n > 0 {
+		return n
+	}
+	if fn, ok := maxOutputProvider.Load().(func() int); ok && fn != nil {
+		if n := fn(); n > 0 {
+			return n
+		}
+	}
+	return DefaultMaxOutput
+}
+
+// killProcessGroup runs cmd in its own process group and makes cancelling
+// its context kill the whole group, so children of the shell do not outlive
+// a timeout or keep its output pipes open.
+func killProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = killWaitDelay
+}
+
+// cappedBuffer collects command output up to limit bytes. Past that it keeps
+// the first and last half of the limit and counts the bytes dropped in
+// between, so a noisy command cannot exhaust memory or the context window.
+type cappedBuffer struct {
+	mu    sync.Mutex
+	limit int
+	head  []byte
+	tail  []byte
+	total int
+}
+
+func newCappedBuffer(limit int) *cappedBuffer {
+	return &cappedBuffer{limit: limit}
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := len(p)
+	b.total += n
+	if room := b.headCap() - len(b.head); room > 0 {
+		take := min(room, len(p))
+		b.head = append(b.head, p[:take]...)
+		p = p[take:]
+	}
+	if len(p) == 0 {
+		return n, nil
+	}
+	b.tail = append(b.tail, p...)
+	// Compact only once the tail doubles to keep writes amortized O(1).
+	if tailCap := b.limit / 2; len(b.tail) > 2*tailCap {
+		b.tail = append(b.tail[:0], b.tail[len(b.tail)-tailCap:]...)
+	}
+	return n, nil
+}
+
+func (b *cappedBuffer) headCap() int {
+	return b.limit - b.limit/2
+}
+
+// Len returns the number of bytes written, including any omitted ones.
+func (b *cappedBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.total
+}
+
+// Truncated reports whether any output was dropped.
+func (b *cappedBuffer) Truncated() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.omitted() > 0
+}
+
+func (b *cappedBuffer) omitted() int {
+	return b.total - len(b.head) - min(len(b.tail), b.limit/2)
+}
+
+// String returns the kept output, with a note in place of the omitted middle.
+func (b *cappedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	omitted := b.omitted()
+	if omitted <= 0 {
+		return string(b.head) + string(b.tail)
+	}
+	tail := b.tail[len(b.tail)-b.limit/2:]
+	return strings.ToValidUTF8(string(b.head), "") +
+		fmt.Sprintf("\n[output truncated, %d bytes omitted]\n", omitted) +
+		strings.ToValidUTF8(string(tail), "")
+}
@@ -4,7 +4,11 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/yanmxa/gencode/internal/task"
 )
 
 func TestBashToolTracksChangedDirectory(t *testing.T) {
@@ -38,3 +42,128 @@ func TestBashToolTracksChangedDirectory(t *testing.T) {
 		t.Fatalf("tracked cwd = %q (%q), want %q (%q)", got, gotResolved, subdir, wantResolved)
 	}
 }
+
+func TestCappedBufferKeepsHeadAndTail(t *testing.T) {
+	b := newCappedBuffer(10)
+	for _, chunk := range []string{"0123", "456789", "abcdef"} {
+		_, _ = b.Write([]byte(chunk))
+	}
+	if want := "01234\n[output truncated, 6 bytes omitted]\nbcdef"; b.String() != want {
+		t.Errorf("String() = %q, want %q", b.String(), want)
+	}
+	if !b.Truncated() || b.Len() != 16 {
+		t.Errorf("Truncated() = %v, Len() = %d", b.Truncated(), b.Len())
+	}
+
+	small := newCappedBuffer(10)
+	_, _ = small.Write([]byte("0123456789"))
+	if small.String() != "0123456789" || small.Truncated() {
+		t.Errorf("output within the limit should be kept whole, got %q", small.String())
+	}
+}
+
+func TestBashToolCapsOutput(t *testing.T) {
+	result := (&BashTool{}).ExecuteApproved(context.Background(), map[string]any{
+		"command":    "seq 1 10000",
+		"max_output": 100,
+	}, t.TempDir())
+	if !result.Success {
+		t.Fatalf("ExecuteApproved() failed: %s", result.Error)
+	}
+	if !strings.HasPrefix(result.Output, "1\n2\n3\n") || !strings.HasSuffix(result.Output, "\n9999\n10000\n") {
+		t.Errorf("output should keep the start and end:\n%s", result.Output)
+	}
+	if !strings.Contains(result.Output, "[output truncated, 48794 bytes omitted]") {
+		t.Errorf("output is missing the truncation note:\n%s", result.Output)
+	}
+}
+
+func TestBashToolTimeoutKillsProcessGroup(t *testing.T) {
+	cwd := t.TempDir()
+	start := time.Now()
+	result := (&BashTool{}).ExecuteApproved(context.Background(), map[string]any{
+		"command": "sleep 30 & echo $! > child.pid; wait",
+		"timeout": 300,
+	}, cwd)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("ExecuteApproved() took %s after the timeout", elapsed)
+	}
+	if result.Success || !strings.Contains(result.Error, "timed out after 0.3s") {
+		t.Fatalf("result = %+v, want a timeout error", result)
+	}
+
+	data, err := os.ReadFile(filepath.Join(cwd, "child.pid"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid := strings.TrimSpace(string(data))
+	deadline := time.Now().Add(2 * time.Second)
+	for processRunning(pid) {
+		if time.Now().After(deadline) {
+			t.Fatalf("child process %s survived the timeout", pid)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestBashTimeoutFollowsSetting(t *testing.T) {
+	arg := func(ms float64) map[string]any { return map[string]any{"timeout": ms} }
+	tests := []struct {
+		name       string
+		configured time.Duration
+		params     map[string]any
+		want       time.Duration
+	}{
+		{"default", 120 * time.Second, nil, 120 * time.Second},
+		{"call argument", 120 * time.Second, arg(300_000), 300 * time.Second},
+		{"capped argument", 120 * time.Second, arg(3_600_000), maxBashTimeout},
+		{"setting raises cap", 30 * time.Minute, arg(3_600_000), 30 * time.Minute},
+		{"no limit", 0, nil, 0},
+		{"no limit keeps argument uncapped", 0, arg(3_600_000), time.Hour},
+	}
+	for _, tt := range tests {
+		if got := bashTimeout(tt.configured, tt.params); got != tt.want {
+			t.Errorf("%s: bashTimeout() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// processRunning reports whether pid exists and is not a zombie.
+func processRunning(pid string) bool {
+	stat, err := os.ReadFile("/proc/" + pid + "/stat")
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(stat))
+	return len(fields) > 2 && fields[2] != "Z"
+}
+
+func TestBashToolBackgroundStreamsOutput(t *testing.T) {
+	task.Initialize(task.Options{})
+	t.Cleanup(task.ResetService)
+
+	result := (&BashTool{}).ExecuteApproved(context.Background(), map[string]any{
+		"command":           "echo started; sleep 30",
+		"run_in_background": true,
+	}, t.TempDir())
+	if !result.Success {
+		t.Fatalf("ExecuteApproved() failed: %s", result.Error)
+	}
+	running := task.Default().ListRunning()
+	if len(running) != 1 {
+		t.Fatalf("%d running tasks, want 1", len(running))
+	}
+	bg := running[0].(*task.BashTask)
+	defer task.Default().Clear(task.TaskTypeBash)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(bg.GetOutput(), "started") {
+		if time.Now().After(deadline) {
+			t.Fatal("output should be readable while the command runs")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if !bg.IsRunning() {
+		t.Error("command finished early")
+	}
+}
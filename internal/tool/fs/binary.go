@@ -0,0 +1,80 @@
+package fs
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"unicode/utf8"
+
+	"github.com/yanmxa/gencode/internal/tool/toolresult"
+)
+
+const (
+	// binarySniffLen is how many leading bytes are inspected for binary content.
+	binarySniffLen = 8000
+	// maxHexDumpBytes caps the raw hex dump Read returns for binary files.
+	maxHexDumpBytes = 4096
+)
+
+// isBinaryContent reports whether data looks like binary rather than text:
+// it contains a NUL byte, or more than 10% of it is control characters or
+// invalid UTF-8. A multi-byte rune cut off at the end of data is ignored.
+func isBinaryContent(data []byte) bool {
+	if len(data) == 0 {
+		return false
+	}
+	suspicious := 0
+	for i := 0; i < len(data); {
+		b := data[i]
+		if b == 0 {
+			return true
+		}
+		if b < utf8.RuneSelf {
+			if b < 0x20 && b != '\n' && b != '\r' && b != '\t' && b != '\f' && b != '\b' && b != 0x1b {
+				suspicious++
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(data[i:])
+		if r == utf8.RuneError && size == 1 {
+			if !utf8.FullRune(data[i:]) {
+				break
+			}
+			suspicious++
+		}
+		i += size
+	}
+	return suspicious*10 > len(data)
+}
+
+// describeBinary returns a one-line summary of a binary file for tool output.
+func describeBinary(path string, size int64, header []byte) string {
+	return fmt.Sprintf("Binary file not shown: %s (%s, %s)", path, toolresult.FormatSize(size), http.DetectContentType(header))
+}
+
+// readHeader returns up to n leading bytes of the file at path.
+func readHeader(path string, n int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	buf := make([]byte, n)
+	read, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	return buf[:read], nil
+}
+
+// hexDump renders up to maxHexDumpBytes of data in hexdump -C style.
+func hexDump(data []byte) (dump string, truncated bool) {
+	if len(data) > maxHexDumpBytes {
+		data = data[:maxHexDumpBytes]
+		truncated = true
+	}
+	return hex.Dump(data), truncated
+}
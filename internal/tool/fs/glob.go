@@ -2,9 +2,11 @@ package fs
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/bmatcuk/doublestar/v4"
@@ -27,6 +29,81 @@ var ignoredDirs = map[string]bool{
 	"build":        true,
 }
 
+// globPatterns is a parsed Glob pattern list: a file matches when it matches
+// any include and no exclude.
+type globPatterns struct {
+	include []string
+	exclude []string
+}
+
+// parseGlobPatterns splits a comma-separated pattern list such as
+// "**/*.go,!**/*_test.go". Entries starting with "!" exclude matches. Commas
+// inside braces belong to the pattern ("*.{go,mod}"). When only excludes are
+// given, everything else matches.
+func parseGlobPatterns(spec string) (globPatterns, error) {
+	var p globPatterns
+	for _, part := range splitGlobList(spec) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		target := &p.include
+		if rest, ok := strings.CutPrefix(part, "!"); ok {
+			part = strings.TrimSpace(rest)
+			target = &p.exclude
+		}
+		if !doublestar.ValidatePattern(part) {
+			return p, fmt.Errorf("invalid pattern: %s", part)
+		}
+		*target = append(*target, part)
+	}
+	if len(p.include) == 0 && len(p.exclude) == 0 {
+		return p, fmt.Errorf("pattern is required")
+	}
+	if len(p.include) == 0 {
+		p.include = []string{"**"}
+	}
+	return p, nil
+}
+
+// splitGlobList splits spec on commas that are not inside braces.
+func splitGlobList(spec string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(spec); i++ {
+		switch spec[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, spec[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, spec[start:])
+}
+
+func (p globPatterns) match(path string) bool {
+	for _, pattern := range p.exclude {
+		if ok, _ := doublestar.Match(pattern, path); ok {
+			return false
+		}
+	}
+	for _, pattern := range p.include {
+		if ok, _ := doublestar.Match(pattern, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // GlobTool finds files matching a pattern
 type GlobTool struct{}
 
@@ -41,6 +118,10 @@ func (t *GlobTool) Execute(ctx context.Context, params map[string]any, cwd strin
 	if err != nil {
 		return toolresult.NewErrorResult(t.Name(), err.Error())
 	}
+	patterns, err := parseGlobPatterns(pattern)
+	if err != nil {
+		return toolresult.NewErrorResult(t.Name(), err.Error())
+	}
 
 	basePath := cwd
 	if path := tool.GetString(params, "path"); path != "" {
@@ -93,13 +174,7 @@ func (t *GlobTool) Execute(ctx context.Context, params map[string]any, cwd strin
 			return nil
 		}
 
-		// Match pattern
-		matched, err := doublestar.Match(pattern, relPath)
-		if err != nil {
-			return nil // Invalid pattern, skip
-		}
-
-		if matched {
+		if patterns.match(relPath) {
 			info, err := d.Info()
 			if err != nil {
 				return nil
@@ -3,7 +3,9 @@ package fs
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -37,94 +39,56 @@ func (t *GrepTool) Execute(ctx context.Context, params map[string]any, cwd strin
 	headLimit := tool.GetInt(params, "head_limit", 250)
 	offset := tool.GetInt(params, "offset", 0)
 
-	// Build rg args
-	args := []string{"--no-messages"}
-
-	// Case sensitivity (-i param overrides case_sensitive)
-	caseInsensitive := !tool.GetBool(params, "case_sensitive")
+	q := grepQuery{
+		pattern:    pattern,
+		mode:       outputMode,
+		ignoreCase: !tool.GetBool(params, "case_sensitive"),
+		multiline:  tool.GetBool(params, "multiline"),
+		fileType:   tool.GetString(params, "type"),
+		glob:       tool.GetString(params, "glob"),
+		searchPath: cwd,
+		cwd:        cwd,
+	}
+	// -i overrides case_sensitive
 	if v, ok := params["-i"].(bool); ok {
-		caseInsensitive = v
+		q.ignoreCase = v
 	}
-	if caseInsensitive {
-		args = append(args, "--ignore-case")
-	}
-
-	if tool.GetBool(params, "multiline") {
-		args = append(args, "--multiline", "--multiline-dotall")
-	}
-
-	// Output mode flags
-	switch outputMode {
-	case "files_with_matches":
-		args = append(args, "--files-with-matches")
-	case "count":
-		args = append(args, "--count")
-	default: // "content"
-		args = append(args, "--line-number", "--with-filename", "--no-heading")
-
-		// Context lines
-		contextLines := tool.GetInt(params, "context", tool.GetInt(params, "-C", 0))
-		if contextLines > 0 {
-			args = append(args, fmt.Sprintf("--context=%d", contextLines))
-		} else {
-			if a := tool.GetInt(params, "-A", 0); a > 0 {
-				args = append(args, fmt.Sprintf("--after-context=%d", a))
-			}
-			if b := tool.GetInt(params, "-B", 0); b > 0 {
-				args = append(args, fmt.Sprintf("--before-context=%d", b))
-			}
-		}
+	if q.glob == "" {
+		q.glob = tool.GetString(params, "include")
 	}
-
-	if fileType := tool.GetString(params, "type"); fileType != "" {
-		args = append(args, "--type", fileType)
+	if contextLines := tool.GetInt(params, "context", tool.GetInt(params, "-C", 0)); contextLines > 0 {
+		q.before, q.after = contextLines, contextLines
+	} else {
+		q.before, q.after = tool.GetInt(params, "-B", 0), tool.GetInt(params, "-A", 0)
 	}
-
-	if glob := tool.GetString(params, "glob"); glob != "" {
-		args = append(args, "--glob", glob)
-	} else if include := tool.GetString(params, "include"); include != "" {
-		args = append(args, "--glob", include)
-	}
-
-	// Pattern
-	args = append(args, "--", pattern)
-
-	searchPath := cwd
 	if path := tool.GetString(params, "path"); path != "" {
 		if filepath.IsAbs(path) {
-			searchPath = path
+			q.searchPath = path
 		} else {
-			searchPath = filepath.Join(cwd, path)
+			q.searchPath = filepath.Join(cwd, path)
 		}
 	}
-	args = append(args, searchPath)
 
-	// Execute rg
-	rgPath := findRG()
-	cmd := exec.CommandContext(ctx, rgPath, args...)
-	cmd.Dir = cwd
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	// Refuse to search a single binary file; directory searches already skip
+	// binary files (rg's default), so only explicit file paths need a check.
+	if info, err := os.Stat(q.searchPath); err == nil && info.Mode().IsRegular() {
+		if header, err := readHeader(q.searchPath, binarySniffLen); err == nil && isBinaryContent(header) {
+			return toolresult.NewErrorResult(t.Name(), describeBinary(q.searchPath, info.Size(), header)+". Use Read with raw=true to inspect its bytes.")
+		}
+	}
 
-	err = cmd.Run()
-	// rg exits 1 when no matches found (not an error), 2 on actual error
+	var rawLines []string
+	if rgPath, ok := findRG(); ok {
+		rawLines, err = runRipgrep(ctx, rgPath, q)
+	} else {
+		rawLines, err = searchFiles(ctx, q)
+	}
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			if exitErr.ExitCode() == 2 {
-				return toolresult.NewErrorResult(t.Name(), "search error: "+stderr.String())
-			}
-		}
+		return toolresult.NewErrorResult(t.Name(), "search error: "+err.Error())
 	}
 
 	duration := time.Since(start)
 
-	// Parse output, applying offset and head_limit
-	rawLines := strings.Split(strings.TrimRight(stdout.String(), "\n"), "\n")
-	if len(rawLines) == 1 && rawLines[0] == "" {
-		rawLines = nil
-	}
-
 	// Apply offset
 	if offset > 0 && offset < len(rawLines) {
 		rawLines = rawLines[offset:]
@@ -132,23 +96,26 @@ func (t *GrepTool) Execute(ctx context.Context, params map[string]any, cwd strin
 		rawLines = nil
 	}
 
-	truncated := false
+	more := 0
 	if headLimit > 0 && len(rawLines) > headLimit {
+		more = len(rawLines) - headLimit
 		rawLines = rawLines[:headLimit]
-		truncated = true
 	}
 
 	// Build content lines for UI
 	var lines []toolresult.ContentLine
-	for _, line := range rawLines {
-		lines = append(lines, toolresult.ContentLine{Text: line, Type: toolresult.LineMatch})
+	for i, line := range rawLines {
+		rawLines[i] = sanitizeMatchLine(line)
+		lines = append(lines, toolresult.ContentLine{Text: rawLines[i], Type: toolresult.LineMatch})
 	}
 
 	subtitle := fmt.Sprintf("pattern: %q mode: %s", pattern, outputMode)
 
 	hookContent := strings.Join(rawLines, "\n")
-	if truncated {
-		hookContent += "\n(results truncated)"
+	if more > 0 {
+		note := fmt.Sprintf("(%d more matches; use offset or a narrower pattern to see them)", more)
+		lines = append(lines, toolresult.ContentLine{Text: note, Type: toolresult.LineTruncated})
+		hookContent += "\n" + note
 	}
 
 	return toolresult.ToolResult{
@@ -165,17 +132,91 @@ func (t *GrepTool) Execute(ctx context.Context, params map[string]any, cwd strin
 			Subtitle:  subtitle,
 			ItemCount: len(rawLines),
 			Duration:  duration,
-			Truncated: truncated,
+			Truncated: more > 0,
 		},
 	}
 }
 
-// findRG returns the path to the rg binary, preferring PATH over the bundled vendor binary.
-func findRG() string {
-	if path, err := exec.LookPath("rg"); err == nil {
-		return path
+// sanitizeMatchLine keeps matches from non-text data out of the context:
+// binary-looking lines are replaced and invalid UTF-8 is made printable.
+func sanitizeMatchLine(line string) string {
+	if isBinaryContent([]byte(line)) {
+		return "[binary content omitted]"
 	}
-	return "rg"
+	return strings.ToValidUTF8(line, "\uFFFD")
+}
+
+// grepQuery is a Grep call's search, shared by ripgrep and the built-in
+// fallback.
+type grepQuery struct {
+	pattern       string
+	mode          string // "content", "files_with_matches", or "count"
+	ignoreCase    bool
+	multiline     bool
+	before, after int // context lines, content mode only
+	fileType      string
+	glob          string
+	searchPath    string
+	cwd           string
+}
+
+// runRipgrep runs the search with rg and returns its output lines.
+func runRipgrep(ctx context.Context, rgPath string, q grepQuery) ([]string, error) {
+	args := []string{"--no-messages"}
+	if q.ignoreCase {
+		args = append(args, "--ignore-case")
+	}
+	if q.multiline {
+		args = append(args, "--multiline", "--multiline-dotall")
+	}
+
+	switch q.mode {
+	case "files_with_matches":
+		args = append(args, "--files-with-matches")
+	case "count":
+		args = append(args, "--count")
+	default: // "content"
+		args = append(args, "--line-number", "--with-filename", "--no-heading")
+		if q.before > 0 {
+			args = append(args, fmt.Sprintf("--before-context=%d", q.before))
+		}
+		if q.after > 0 {
+			args = append(args, fmt.Sprintf("--after-context=%d", q.after))
+		}
+	}
+
+	if q.fileType != "" {
+		args = append(args, "--type", q.fileType)
+	}
+	if q.glob != "" {
+		args = append(args, "--glob", q.glob)
+	}
+	args = append(args, "--", q.pattern, q.searchPath)
+
+	cmd := exec.CommandContext(ctx, rgPath, args...)
+	cmd.Dir = q.cwd
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// rg exits 1 when no matches found (not an error), 2 on actual error
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 2 {
+			return nil, errors.New(strings.TrimSpace(stderr.String()))
+		}
+	}
+
+	out := strings.TrimRight(stdout.String(), "\n")
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// findRG returns the path to the rg binary on PATH, if there is one.
+func findRG() (string, bool) {
+	path, err := exec.LookPath("rg")
+	return path, err == nil
 }
 
 func init() {
@@ -0,0 +1,144 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// searchFiles is the Grep fallback for when rg is not installed: it walks
+// searchPath and matches lines with Go regexps, printing results the way rg
+// does. Like rg it skips hidden and binary files; it does not read
+// .gitignore and skips the directories Glob ignores instead.
+func searchFiles(ctx context.Context, q grepQuery) ([]string, error) {
+	if q.multiline {
+		return nil, errors.New("multiline search needs ripgrep (rg), which is not installed")
+	}
+	if q.fileType != "" {
+		return nil, errors.New("the type filter needs ripgrep (rg), which is not installed; use glob instead")
+	}
+	expr := q.pattern
+	if q.ignoreCase {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	var globs *globPatterns
+	if q.glob != "" {
+		p, err := parseGlobPatterns(q.glob)
+		if err != nil {
+			return nil, err
+		}
+		globs = &p
+	}
+
+	info, err := os.Stat(q.searchPath)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return grepFile(q, re, q.searchPath), nil
+	}
+
+	var out []string
+	err = filepath.WalkDir(q.searchPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil // unreadable entries are skipped, as with rg --no-messages
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if path != q.searchPath && (strings.HasPrefix(d.Name(), ".") || (d.IsDir() && ignoredDirs[d.Name()])) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !matchesGrepGlob(globs, q.searchPath, path) {
+			return nil
+		}
+		matches := grepFile(q, re, path)
+		if len(matches) > 0 && len(out) > 0 && q.mode == "content" && q.before+q.after > 0 {
+			out = append(out, "--")
+		}
+		out = append(out, matches...)
+		return nil
+	})
+	return out, err
+}
+
+// matchesGrepGlob applies the glob filter as rg does: a pattern without a
+// slash matches the file name in any directory, otherwise the path relative
+// to root.
+func matchesGrepGlob(globs *globPatterns, root, path string) bool {
+	if globs == nil {
+		return true
+	}
+	name := filepath.Base(path)
+	for _, p := range append(globs.include, globs.exclude...) {
+		if strings.Contains(p, "/") {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return false
+			}
+			name = filepath.ToSlash(rel)
+			break
+		}
+	}
+	return globs.match(name)
+}
+
+// grepFile returns rg-style output for one file: "path" when listing files,
+// "path:N" when counting, and "path:line:text" for matches with
+// "path-line-text" for context lines, "--" separating groups.
+func grepFile(q grepQuery, re *regexp.Regexp, path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil || isBinaryContent(data[:min(len(data), binarySniffLen)]) {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	var matched []int
+	for i, line := range lines {
+		if re.MatchString(line) {
+			matched = append(matched, i)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+
+	switch q.mode {
+	case "files_with_matches":
+		return []string{path}
+	case "count":
+		return []string{fmt.Sprintf("%s:%d", path, len(matched))}
+	}
+
+	isMatch := make(map[int]bool, len(matched))
+	for _, i := range matched {
+		isMatch[i] = true
+	}
+	var out []string
+	next := 0 // first line not yet printed
+	for _, m := range matched {
+		from, to := max(m-q.before, next), min(m+q.after, len(lines)-1)
+		if from > next && next > 0 && q.before+q.after > 0 {
+			out = append(out, "--")
+		}
+		for i := from; i <= to; i++ {
+			sep := "-"
+			if isMatch[i] {
+				sep = ":"
+			}
+			out = append(out, fmt.Sprintf("%s%s%d%s%s", path, sep, i+1, sep, lines[i]))
+		}
+		next = max(next, to+1)
+	}
+	return out
+}
@@ -0,0 +1,223 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yanmxa/gencode/internal/tool"
+	"github.com/yanmxa/gencode/internal/tool/perm"
+	"github.com/yanmxa/gencode/internal/tool/toolresult"
+)
+
+// MultiEditTool applies several string replacements to one file as a single
+// change: either every edit applies or the file is left untouched.
+type MultiEditTool struct{}
+
+func (t *MultiEditTool) Name() string { return "MultiEdit" }
+func (t *MultiEditTool) Description() string {
+	return "Apply multiple string replacements to a file atomically"
+}
+func (t *MultiEditTool) Icon() string { return IconEdit }
+
+// RequiresPermission returns true - MultiEdit always requires permission
+func (t *MultiEditTool) RequiresPermission() bool {
+	return true
+}
+
+// fileEdit is one replacement in a MultiEdit call.
+type fileEdit struct {
+	OldString  string
+	NewString  string
+	ReplaceAll bool
+}
+
+// parseEdits reads the edits parameter, which arrives as []any from decoded
+// JSON or as []map[string]any when built in Go.
+func parseEdits(params map[string]any) ([]fileEdit, error) {
+	var raw []map[string]any
+	switch v := params["edits"].(type) {
+	case []map[string]any:
+		raw = v
+	case []any:
+		for i, item := range v {
+			m, ok := item.(map[string]any)
+			if !ok {
+				return nil, &tool.ToolError{Message: fmt.Sprintf("edit %d: must be an object", i+1)}
+			}
+			raw = append(raw, m)
+		}
+	case nil:
+		return nil, &tool.ToolError{Message: "edits is required"}
+	default:
+		return nil, &tool.ToolError{Message: "edits must be an array"}
+	}
+	if len(raw) == 0 {
+		return nil, &tool.ToolError{Message: "edits must contain at least one edit"}
+	}
+
+	edits := make([]fileEdit, 0, len(raw))
+	for i, m := range raw {
+		oldString, ok := m["old_string"].(string)
+		if !ok {
+			return nil, &tool.ToolError{Message: fmt.Sprintf("edit %d: old_string is required", i+1)}
+		}
+		newString, ok := m["new_string"].(string)
+		if !ok {
+			return nil, &tool.ToolError{Message: fmt.Sprintf("edit %d: new_string is required", i+1)}
+		}
+		if oldString == newString {
+			return nil, &tool.ToolError{Message: fmt.Sprintf("edit %d: old_string and new_string are identical", i+1)}
+		}
+		edits = append(edits, fileEdit{
+			OldString:  oldString,
+			NewString:  newString,
+			ReplaceAll: tool.GetBool(m, "replace_all"),
+		})
+	}
+	return edits, nil
+}
+
+// applyEdits applies edits in order, each to the result of the previous one.
+// It returns the final content and the total number of replacements, or an
+// error naming the first edit that cannot be applied.
+func applyEdits(content string, edits []fileEdit) (string, int, error) {
+	replacements := 0
+	for i, e := range edits {
+		count := strings.Count(content, e.OldString)
+		if e.OldString == "" || count == 0 {
+			return "", 0, &tool.ToolError{Message: fmt.Sprintf("edit %d: old_string not found in file (edits apply in order, so earlier edits may have changed it)", i+1)}
+		}
+		if !e.ReplaceAll && count > 1 {
+			return "", 0, &tool.ToolError{Message: fmt.Sprintf("edit %d: old_string is not unique in file (found %d occurrences). Add context or use replace_all=true.", i+1, count)}
+		}
+		if e.ReplaceAll {
+			content = strings.ReplaceAll(content, e.OldString, e.NewString)
+			replacements += count
+		} else {
+			content = strings.Replace(content, e.OldString, e.NewString, 1)
+			replacements++
+		}
+	}
+	return content, replacements, nil
+}
+
+// PreparePermission applies the edits in memory and returns one combined diff
+func (t *MultiEditTool) PreparePermission(ctx context.Context, params map[string]any, cwd string) (*perm.PermissionRequest, error) {
+	filePath, err := tool.RequireString(params, "file_path")
+	if err != nil {
+		return nil, err
+	}
+	edits, err := parseEdits(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if !filepath.IsAbs(filePath) {
+		filePath = filepath.Join(cwd, filePath)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, &tool.ToolError{Message: "file not found: " + filePath}
+		}
+		return nil, &tool.ToolError{Message: "failed to read file: " + err.Error()}
+	}
+
+	oldContent := string(content)
+	newContent, _, err := applyEdits(oldContent, edits)
+	if err != nil {
+		return nil, err
+	}
+
+	return &perm.PermissionRequest{
+		ID:          tool.GenerateRequestID(),
+		ToolName:    t.Name(),
+		FilePath:    filePath,
+		Description: "Apply " + strconv.Itoa(len(edits)) + " edits to file",
+		DiffMeta:    perm.GenerateDiff(filePath, oldContent, newContent),
+	}, nil
+}
+
+// ExecuteApproved re-applies the edits to the current file and writes the
+// result once
+func (t *MultiEditTool) ExecuteApproved(ctx context.Context, params map[string]any, cwd string) toolresult.ToolResult {
+	start := time.Now()
+
+	filePath := tool.GetString(params, "file_path")
+	if filePath == "" {
+		return toolresult.NewErrorResult(t.Name(), "file_path is required")
+	}
+	edits, err := parseEdits(params)
+	if err != nil {
+		return toolresult.NewErrorResult(t.Name(), err.Error())
+	}
+
+	if !filepath.IsAbs(filePath) {
+		filePath = filepath.Join(cwd, filePath)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return toolresult.NewErrorResult(t.Name(), "failed to read file: "+err.Error())
+	}
+
+	oldContent := string(content)
+	newContent, replaceCount, err := applyEdits(oldContent, edits)
+	if err != nil {
+		// Nothing has been written; the file is unchanged.
+		return toolresult.NewErrorResult(t.Name(), err.Error()+" (file may have been modified since approval)")
+	}
+
+	// Preserve original file permissions
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(filePath); err == nil {
+		mode = info.Mode()
+	}
+
+	if err := os.WriteFile(filePath, []byte(newContent), mode); err != nil {
+		return toolresult.NewErrorResult(t.Name(), "failed to write file: "+err.Error())
+	}
+
+	hookEdits := make([]any, len(edits))
+	for i, e := range edits {
+		hookEdits[i] = map[string]any{
+			"old_string":  e.OldString,
+			"new_string":  e.NewString,
+			"replace_all": e.ReplaceAll,
+		}
+	}
+
+	return toolresult.ToolResult{
+		Success: true,
+		Output: fmt.Sprintf("Successfully edited %s (%d edits, %d replacement(s))",
+			filePath, len(edits), replaceCount),
+		HookResponse: map[string]any{
+			"filePath":        filePath,
+			"edits":           hookEdits,
+			"originalFile":    oldContent,
+			"structuredPatch": []any{},
+			"userModified":    false,
+		},
+		Metadata: toolresult.ResultMetadata{
+			Title:    t.Name(),
+			Icon:     t.Icon(),
+			Subtitle: filePath,
+			Duration: time.Since(start),
+		},
+	}
+}
+
+// Execute implements the Tool interface (for permission-unaware execution)
+func (t *MultiEditTool) Execute(ctx context.Context, params map[string]any, cwd string) toolresult.ToolResult {
+	return t.ExecuteApproved(ctx, params, cwd)
+}
+
+func init() {
+	tool.Register(&MultiEditTool{})
+}
@@ -0,0 +1,103 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeMultiEditFixture(t *testing.T, content string) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	return dir, path
+}
+
+func TestMultiEdit_AppliesEditsInOrder(t *testing.T) {
+	dir, path := writeMultiEditFixture(t, "func old() {}\nold()\nvar x = 1\n")
+	tool := &MultiEditTool{}
+	params := map[string]any{
+		"file_path": path,
+		"edits": []any{
+			map[string]any{"old_string": "old", "new_string": "renamed", "replace_all": true},
+			// Overlaps the first edit: it only matches text the first edit produced.
+			map[string]any{"old_string": "func renamed() {}", "new_string": "func renamed() { return }"},
+			map[string]any{"old_string": "var x = 1", "new_string": "var x = 2"},
+		},
+	}
+
+	req, err := tool.PreparePermission(context.Background(), params, dir)
+	if err != nil {
+		t.Fatalf("PreparePermission: %v", err)
+	}
+	if req.DiffMeta == nil || !strings.Contains(req.DiffMeta.UnifiedDiff, "+var x = 2") ||
+		!strings.Contains(req.DiffMeta.UnifiedDiff, "+func renamed() { return }") {
+		t.Fatalf("expected one diff covering every edit, got %+v", req.DiffMeta)
+	}
+
+	result := tool.ExecuteApproved(context.Background(), params, dir)
+	if !result.Success {
+		t.Fatalf("ExecuteApproved failed: %s", result.Error)
+	}
+	if !strings.Contains(result.Output, "3 edits, 4 replacement(s)") {
+		t.Errorf("unexpected output: %s", result.Output)
+	}
+	got, _ := os.ReadFile(path)
+	if want := "func renamed() { return }\nrenamed()\nvar x = 2\n"; string(got) != want {
+		t.Errorf("content = %q, want %q", got, want)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600 preserved", info.Mode().Perm())
+	}
+}
+
+func TestMultiEdit_FailureWritesNothing(t *testing.T) {
+	const content = "a := 1\nb := 1\nb := 1\n"
+
+	tests := []struct {
+		name    string
+		edits   []any
+		wantErr string
+	}{
+		{
+			name: "non-unique old_string",
+			edits: []any{
+				map[string]any{"old_string": "a := 1", "new_string": "a := 2"},
+				map[string]any{"old_string": "b := 1", "new_string": "b := 2"},
+			},
+			wantErr: "edit 2: old_string is not unique in file (found 2 occurrences)",
+		},
+		{
+			name: "earlier edit consumed later old_string",
+			edits: []any{
+				map[string]any{"old_string": "a := 1\nb", "new_string": "a := 1\nc"},
+				map[string]any{"old_string": "a := 1\nb := 1", "new_string": "x"},
+			},
+			wantErr: "edit 2: old_string not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, path := writeMultiEditFixture(t, content)
+			tool := &MultiEditTool{}
+			params := map[string]any{"file_path": path, "edits": tt.edits}
+
+			if _, err := tool.PreparePermission(context.Background(), params, dir); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("PreparePermission error = %v, want %q", err, tt.wantErr)
+			}
+			result := tool.ExecuteApproved(context.Background(), params, dir)
+			if result.Success || !strings.Contains(result.Error, tt.wantErr) {
+				t.Fatalf("ExecuteApproved = %+v, want error %q", result, tt.wantErr)
+			}
+			if got, _ := os.ReadFile(path); string(got) != content {
+				t.Errorf("file changed after failed edit: %q", got)
+			}
+		})
+	}
+}
@@ -2,8 +2,9 @@ package fs
 
 import (
 	"bufio"
-	"bytes"
 	"context"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -39,14 +40,24 @@ func (t *ReadTool) Execute(ctx context.Context, params map[string]any, cwd strin
 		filePath = filepath.Join(cwd, filePath)
 	}
 
-	offset := tool.GetInt(params, "offset", 0)
+	// offset is 1-based; 0 and 1 both start at the first line.
+	offset := max(tool.GetInt(params, "offset", 0), 0)
 	limit := tool.GetInt(params, "limit", maxReadLines)
+	if limit <= 0 {
+		limit = maxReadLines
+	}
+	_, hasOffset := params["offset"]
+	_, hasLimit := params["limit"]
 
 	// Get file info
 	info, err := os.Stat(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return toolresult.NewErrorResult(t.Name(), "file not found: "+filePath)
+			msg := "file not found: " + filePath
+			if suggestions := suggestPaths(ctx, filePath, cwd); len(suggestions) > 0 {
+				msg += "\nDid you mean one of these?\n  " + strings.Join(suggestions, "\n  ")
+			}
+			return toolresult.NewErrorResult(t.Name(), msg)
 		}
 		return toolresult.NewErrorResult(t.Name(), "failed to stat file: "+err.Error())
 	}
@@ -62,22 +73,12 @@ func (t *ReadTool) Execute(ctx context.Context, params map[string]any, cwd strin
 	}
 	defer file.Close()
 
-	// Check for binary file by reading first 512 bytes
-	header := make([]byte, 512)
-	n, _ := file.Read(header)
-	if n > 0 {
-		if bytes.IndexByte(header[:n], 0) >= 0 {
-			return toolresult.ToolResult{
-				Success: true,
-				Output:  "Binary file detected: " + filePath,
-				Metadata: toolresult.ResultMetadata{
-					Title:    t.Name(),
-					Icon:     t.Icon(),
-					Subtitle: filePath + " (binary)",
-					Size:     info.Size(),
-				},
-			}
-		}
+	// Check for binary content before streaming lines into the context.
+	header := make([]byte, binarySniffLen)
+	n, _ := io.ReadFull(file, header)
+	header = header[:n]
+	if isBinaryContent(header) {
+		return t.binaryResult(file, filePath, info.Size(), header, tool.GetBool(params, "raw"))
 	}
 	// Reset file position to beginning
 	if _, err := file.Seek(0, 0); err != nil {
@@ -100,10 +101,10 @@ func (t *ReadTool) Execute(ctx context.Context, params map[string]any, cwd strin
 			continue
 		}
 
-		// Check limit
+		// Past the limit, keep counting so the total line count is known.
 		if readCount >= limit {
 			truncated = true
-			break
+			continue
 		}
 
 		text := scanner.Text()
@@ -136,14 +137,22 @@ func (t *ReadTool) Execute(ctx context.Context, params map[string]any, cwd strin
 	}
 	contentForHook := hookBuf.String()
 
-	startLine := 1
-	if offset > 0 {
-		startLine = offset
+	startLine := max(offset, 1)
+	if startLine > 1 && startLine > lineNo {
+		return toolresult.NewErrorResult(t.Name(),
+			fmt.Sprintf("offset %d is beyond the end of the file (%d lines)", startLine, lineNo))
+	}
+
+	// A requested range gets a header so the model knows what it is seeing.
+	var rangeHeader string
+	if (hasOffset || hasLimit) && len(lines) > 0 {
+		rangeHeader = fmt.Sprintf("[Showing lines %d-%d of %d]", startLine, startLine+len(lines)-1, lineNo)
 	}
 
 	// Build result
 	result := toolresult.ToolResult{
 		Success: true,
+		Output:  rangeHeader,
 		Lines:   lines,
 		HookResponse: map[string]any{
 			"type": "text",
@@ -169,6 +178,41 @@ func (t *ReadTool) Execute(ctx context.Context, params map[string]any, cwd strin
 	return result
 }
 
+// binaryResult refuses to return a binary file's bytes unless raw is set, in
+// which case it returns a bounded hex dump instead of undecodable text.
+func (t *ReadTool) binaryResult(file *os.File, filePath string, size int64, header []byte, raw bool) toolresult.ToolResult {
+	summary := describeBinary(filePath, size, header)
+	if !raw {
+		return toolresult.NewErrorResult(t.Name(), summary+". Pass raw=true to get a hex dump of the first 4 KB.")
+	}
+
+	data := header
+	if len(data) < maxHexDumpBytes && int64(len(data)) < size {
+		if _, err := file.Seek(0, 0); err == nil {
+			buf := make([]byte, maxHexDumpBytes)
+			n, _ := io.ReadFull(file, buf)
+			data = buf[:n]
+		}
+	}
+	dump, truncated := hexDump(data)
+	if truncated || int64(len(data)) < size {
+		truncated = true
+		dump += fmt.Sprintf("(hex dump truncated to the first %d of %d bytes)\n", min(len(data), maxHexDumpBytes), size)
+	}
+
+	return toolresult.ToolResult{
+		Success: true,
+		Output:  summary + "\n" + dump,
+		Metadata: toolresult.ResultMetadata{
+			Title:     t.Name(),
+			Icon:      t.Icon(),
+			Subtitle:  filePath + " (binary, hex)",
+			Size:      size,
+			Truncated: truncated,
+		},
+	}
+}
+
 func init() {
 	tool.Register(&ReadTool{})
 }
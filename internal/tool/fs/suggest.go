@@ -0,0 +1,117 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yanmxa/gencode/internal/fuzzy"
+)
+
+const (
+	maxPathSuggestions = 3
+	// maxSuggestWalkEntries bounds the tree walk used to find similarly-named
+	// files so a not-found error stays cheap in large repositories.
+	maxSuggestWalkEntries = 5000
+)
+
+var errSuggestWalkLimit = errors.New("suggestion walk limit reached")
+
+// suggestPaths returns up to maxPathSuggestions existing files that look like
+// what the caller meant by missing. Candidates come from the nearest existing
+// parent directory and from a bounded walk of cwd for files with a similar
+// base name; they are ranked by edit distance to the requested path.
+func suggestPaths(ctx context.Context, missing, cwd string) []string {
+	base := strings.ToLower(filepath.Base(missing))
+	candidates := make(map[string]bool)
+
+	if dir := nearestExistingDir(filepath.Dir(missing)); dir != "" {
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			if !e.IsDir() && similarName(strings.ToLower(e.Name()), base) {
+				candidates[filepath.Join(dir, e.Name())] = true
+			}
+		}
+	}
+
+	if cwd != "" {
+		visited := 0
+		_ = filepath.WalkDir(cwd, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			visited++
+			if visited > maxSuggestWalkEntries {
+				return errSuggestWalkLimit
+			}
+			if d.IsDir() {
+				if ignoredDirs[d.Name()] {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if similarName(strings.ToLower(d.Name()), base) {
+				candidates[path] = true
+			}
+			return nil
+		})
+	}
+
+	type scored struct {
+		path string
+		dist int
+	}
+	target := strings.ToLower(missing)
+	ranked := make([]scored, 0, len(candidates))
+	for path := range candidates {
+		ranked = append(ranked, scored{path: path, dist: fuzzy.Levenshtein(strings.ToLower(path), target)})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].dist != ranked[j].dist {
+			return ranked[i].dist < ranked[j].dist
+		}
+		return ranked[i].path < ranked[j].path
+	})
+
+	var result []string
+	for _, r := range ranked {
+		if len(result) == maxPathSuggestions {
+			break
+		}
+		result = append(result, r.path)
+	}
+	return result
+}
+
+// nearestExistingDir walks up from dir until it finds a directory that exists.
+func nearestExistingDir(dir string) string {
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// similarName reports whether two lower-cased base names are close enough to
+// be a plausible typo of each other, or share the same stem (main.go vs main_test.go).
+func similarName(name, want string) bool {
+	if name == want {
+		return true
+	}
+	stem := strings.TrimSuffix(want, filepath.Ext(want))
+	if stem != "" && strings.TrimSuffix(name, filepath.Ext(name)) == stem {
+		return true
+	}
+	return fuzzy.Levenshtein(name, want) <= max(2, len(want)/3)
+}
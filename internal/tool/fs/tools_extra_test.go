@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"testing"
 )
@@ -99,6 +101,84 @@ func TestRead_LineLimit_LargeFile(t *testing.T) {
 	})
 }
 
+// TestRead_OffsetLimitRange verifies the range header and that line numbers
+// in FormatForLLM output match the real file when reading a slice.
+func TestRead_OffsetLimitRange(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "range.txt")
+
+	var sb strings.Builder
+	for i := 1; i <= 120; i++ {
+		sb.WriteString(fmt.Sprintf("line %d\n", i))
+	}
+	if err := os.WriteFile(filePath, []byte(sb.String()), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	tool := &ReadTool{}
+	ctx := context.Background()
+
+	t.Run("slice keeps real line numbers and reports the range", func(t *testing.T) {
+		result := tool.Execute(ctx, map[string]any{
+			"file_path": filePath,
+			"offset":    float64(101),
+			"limit":     float64(5),
+		}, tmpDir)
+		if !result.Success {
+			t.Fatalf("Expected success, got error: %s", result.Error)
+		}
+
+		out := result.FormatForLLM()
+		if !strings.HasPrefix(out, "[Showing lines 101-105 of 120]\n") {
+			t.Errorf("Expected range header, got:\n%s", out)
+		}
+		if !strings.Contains(out, "   101\tline 101\n") || !strings.Contains(out, "   105\tline 105\n") {
+			t.Errorf("Expected line numbers aligned to the file, got:\n%s", out)
+		}
+		resp, _ := result.HookResponse.(map[string]any)
+		if file, _ := resp["file"].(map[string]any); file["totalLines"] != 120 {
+			t.Errorf("Expected totalLines 120, got %v", file["totalLines"])
+		}
+	})
+
+	t.Run("limit larger than the file returns the rest", func(t *testing.T) {
+		result := tool.Execute(ctx, map[string]any{
+			"file_path": filePath,
+			"offset":    float64(111),
+			"limit":     float64(500),
+		}, tmpDir)
+		if !result.Success {
+			t.Fatalf("Expected success, got error: %s", result.Error)
+		}
+		if len(result.Lines) != 10 || result.Metadata.Truncated {
+			t.Errorf("Expected 10 untruncated lines, got %d (truncated=%v)", len(result.Lines), result.Metadata.Truncated)
+		}
+		if result.Output != "[Showing lines 111-120 of 120]" {
+			t.Errorf("Unexpected range header %q", result.Output)
+		}
+	})
+
+	t.Run("offset beyond EOF is an error", func(t *testing.T) {
+		result := tool.Execute(ctx, map[string]any{
+			"file_path": filePath,
+			"offset":    float64(121),
+		}, tmpDir)
+		if result.Success {
+			t.Fatal("Expected an error for offset beyond EOF")
+		}
+		if !strings.Contains(result.Error, "offset 121 is beyond the end of the file (120 lines)") {
+			t.Errorf("Unexpected error: %s", result.Error)
+		}
+	})
+
+	t.Run("no offset or limit has no header", func(t *testing.T) {
+		result := tool.Execute(ctx, map[string]any{"file_path": filePath}, tmpDir)
+		if out := result.FormatForLLM(); !strings.HasPrefix(out, "     1\tline 1\n") {
+			t.Errorf("Expected unchanged default output, got prefix %q", out[:min(len(out), 40)])
+		}
+	})
+}
+
 // TestEdit_Fails_WhenOldStringNotUnique verifies that Edit's PreparePermission
 // returns an error when old_string matches more than once and replace_all is false.
 func TestEdit_Fails_WhenOldStringNotUnique(t *testing.T) {
@@ -162,6 +242,72 @@ func TestEdit_Fails_WhenOldStringNotUnique(t *testing.T) {
 	})
 }
 
+// TestWrite_PermissionPreview verifies that Write previews a new file's
+// content but shows a unified diff when overwriting an existing file, even
+// one that is empty.
+func TestWrite_PermissionPreview(t *testing.T) {
+	tmpDir := t.TempDir()
+	tool := &WriteTool{}
+	ctx := context.Background()
+
+	t.Run("new file uses content preview", func(t *testing.T) {
+		req, err := tool.PreparePermission(ctx, map[string]any{
+			"file_path": "new.txt",
+			"content":   "a\nb\n",
+		}, tmpDir)
+		if err != nil {
+			t.Fatalf("PreparePermission: %v", err)
+		}
+		if !req.DiffMeta.IsNewFile || !req.DiffMeta.PreviewMode {
+			t.Errorf("Expected new-file preview, got IsNewFile=%v PreviewMode=%v", req.DiffMeta.IsNewFile, req.DiffMeta.PreviewMode)
+		}
+		if req.Description != "Create new file" {
+			t.Errorf("Description = %q", req.Description)
+		}
+	})
+
+	t.Run("existing file shows diff", func(t *testing.T) {
+		filePath := filepath.Join(tmpDir, "existing.txt")
+		os.WriteFile(filePath, []byte("keep\nold\n"), 0o644)
+
+		req, err := tool.PreparePermission(ctx, map[string]any{
+			"file_path": filePath,
+			"content":   "keep\nnew\n",
+		}, tmpDir)
+		if err != nil {
+			t.Fatalf("PreparePermission: %v", err)
+		}
+		if req.DiffMeta.IsNewFile || req.DiffMeta.PreviewMode {
+			t.Errorf("Expected diff, got IsNewFile=%v PreviewMode=%v", req.DiffMeta.IsNewFile, req.DiffMeta.PreviewMode)
+		}
+		if req.DiffMeta.AddedCount != 1 || req.DiffMeta.RemovedCount != 1 {
+			t.Errorf("Expected +1 -1, got +%d -%d", req.DiffMeta.AddedCount, req.DiffMeta.RemovedCount)
+		}
+		if req.Description != "Overwrite existing file" {
+			t.Errorf("Description = %q", req.Description)
+		}
+	})
+
+	t.Run("existing empty file shows diff", func(t *testing.T) {
+		filePath := filepath.Join(tmpDir, "empty.txt")
+		os.WriteFile(filePath, nil, 0o644)
+
+		req, err := tool.PreparePermission(ctx, map[string]any{
+			"file_path": filePath,
+			"content":   "hello\n",
+		}, tmpDir)
+		if err != nil {
+			t.Fatalf("PreparePermission: %v", err)
+		}
+		if req.DiffMeta.IsNewFile || req.DiffMeta.PreviewMode {
+			t.Errorf("Expected diff for empty existing file, got IsNewFile=%v PreviewMode=%v", req.DiffMeta.IsNewFile, req.DiffMeta.PreviewMode)
+		}
+		if req.DiffMeta.AddedCount != 1 {
+			t.Errorf("Expected +1, got +%d", req.DiffMeta.AddedCount)
+		}
+	})
+}
+
 // TestGlob_PatternMatching verifies that the Glob tool correctly handles
 // ** (recursive) and ? (single character) wildcard patterns.
 func TestGlob_PatternMatching(t *testing.T) {
@@ -281,3 +427,203 @@ func TestGlob_PatternMatching(t *testing.T) {
 		}
 	})
 }
+
+// TestGlob_MultipleAndNegativePatterns verifies comma-separated include
+// patterns and "!" exclusions.
+func TestGlob_MultipleAndNegativePatterns(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"main.go", "main_test.go", "go.mod", "README.md", "pkg/util.go", "pkg/util_test.go"} {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tool := &GlobTool{}
+	ctx := context.Background()
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"**/*.go,!**/*_test.go", []string{"main.go", "pkg/util.go"}},
+		{"**/*.go, !**/*_test.go, go.mod", []string{"go.mod", "main.go", "pkg/util.go"}},
+		{"*.{go,mod},!*_test.go", []string{"go.mod", "main.go"}},
+		{"!**/*.go", []string{"README.md", "go.mod"}},
+		{"**/*.go,!pkg/**", []string{"main.go", "main_test.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			result := tool.Execute(ctx, map[string]any{"pattern": tt.pattern}, tmpDir)
+			if !result.Success {
+				t.Fatalf("Expected success, got error: %s", result.Error)
+			}
+			got := append([]string(nil), result.Files...)
+			for i := range got {
+				got[i] = filepath.ToSlash(got[i])
+			}
+			sort.Strings(got)
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("Files = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("invalid pattern is reported", func(t *testing.T) {
+		result := tool.Execute(ctx, map[string]any{"pattern": "**/*.go,![a"}, tmpDir)
+		if result.Success || !strings.Contains(result.Error, "invalid pattern") {
+			t.Errorf("Expected invalid pattern error, got success=%v error=%q", result.Success, result.Error)
+		}
+	})
+}
+
+// TestRead_NotFound_SuggestsSimilarPaths verifies that a mistyped path returns
+// the closest existing files so the model can self-correct.
+func TestRead_NotFound_SuggestsSimilarPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"config.go", "config_test.go", "unrelated.md"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("x\n"), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, "pkg"), 0o755); err != nil {
+		t.Fatalf("Failed to create pkg dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "pkg", "server.go"), []byte("x\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write server.go: %v", err)
+	}
+
+	tool := &ReadTool{}
+	ctx := context.Background()
+
+	t.Run("typo in file name", func(t *testing.T) {
+		result := tool.Execute(ctx, map[string]any{"file_path": "confg.go"}, tmpDir)
+		if result.Success {
+			t.Fatal("Expected failure for missing file")
+		}
+		if !strings.Contains(result.Error, filepath.Join(tmpDir, "config.go")) {
+			t.Errorf("Expected config.go suggestion, got: %s", result.Error)
+		}
+		if strings.Contains(result.Error, "unrelated.md") {
+			t.Errorf("Did not expect unrelated.md suggestion, got: %s", result.Error)
+		}
+	})
+
+	t.Run("wrong directory", func(t *testing.T) {
+		result := tool.Execute(ctx, map[string]any{"file_path": "internal/server.go"}, tmpDir)
+		if !strings.Contains(result.Error, filepath.Join(tmpDir, "pkg", "server.go")) {
+			t.Errorf("Expected pkg/server.go suggestion, got: %s", result.Error)
+		}
+	})
+}
+
+// TestRead_BinaryFile verifies that binary files are summarized instead of
+// dumped as text, and that raw=true returns a hex dump.
+func TestRead_BinaryFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "image.png")
+	data := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("Failed to write binary file: %v", err)
+	}
+
+	tool := &ReadTool{}
+	ctx := context.Background()
+
+	t.Run("refuses by default", func(t *testing.T) {
+		result := tool.Execute(ctx, map[string]any{"file_path": path}, tmpDir)
+		if result.Success {
+			t.Fatal("Expected failure for binary file")
+		}
+		if !strings.Contains(result.Error, "Binary file not shown") || !strings.Contains(result.Error, "image/png") {
+			t.Errorf("Expected binary summary with MIME type, got: %s", result.Error)
+		}
+	})
+
+	t.Run("raw returns hex dump", func(t *testing.T) {
+		result := tool.Execute(ctx, map[string]any{"file_path": path, "raw": true}, tmpDir)
+		if !result.Success {
+			t.Fatalf("Expected success with raw=true, got: %s", result.Error)
+		}
+		if !strings.Contains(result.Output, "89 50 4e 47") {
+			t.Errorf("Expected hex dump of PNG header, got: %s", result.Output)
+		}
+	})
+}
+
+func TestGrep_FallbackSearch(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"a.go":              "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n",
+		"sub/b.go":          "package sub\n// TODO: Main entry\n",
+		"sub/c.txt":         "func main\n",
+		".hidden/d.go":      "func main() {}\n",
+		"node_modules/x.go": "func main() {}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, b, c := filepath.Join(root, "a.go"), filepath.Join(root, "sub", "b.go"), filepath.Join(root, "sub", "c.txt")
+	search := func(q grepQuery) []string {
+		t.Helper()
+		q.searchPath, q.cwd = root, root
+		got, err := searchFiles(context.Background(), q)
+		if err != nil {
+			t.Fatalf("searchFiles(%+v) error = %v", q, err)
+		}
+		sort.Strings(got)
+		return got
+	}
+
+	if got, want := search(grepQuery{pattern: "func main", mode: "files_with_matches"}), []string{a, c}; !slices.Equal(got, want) {
+		t.Errorf("files = %v, want %v (hidden and ignored dirs skipped)", got, want)
+	}
+	if got, want := search(grepQuery{pattern: "main", mode: "files_with_matches", ignoreCase: true, glob: "*.go"}), []string{a, b}; !slices.Equal(got, want) {
+		t.Errorf("-i with glob = %v, want %v", got, want)
+	}
+	if got, want := search(grepQuery{pattern: "main", mode: "count"}), []string{a + ":2", c + ":1"}; !slices.Equal(got, want) {
+		t.Errorf("count = %v, want %v", got, want)
+	}
+
+	got := search(grepQuery{pattern: "println", mode: "content", glob: "*.go", before: 1, after: 1})
+	want := []string{a + "-3-func main() {", a + ":4:\tprintln(\"hi\")", a + "-5-}"}
+	sort.Strings(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("content with context = %q, want %q", got, want)
+	}
+
+	if _, err := searchFiles(context.Background(), grepQuery{pattern: "x", fileType: "go", searchPath: root}); err == nil {
+		t.Error("type filter without rg should be an error")
+	}
+}
+
+func TestGrep_ReportsMoreMatches(t *testing.T) {
+	root := t.TempDir()
+	var sb strings.Builder
+	for i := 0; i < 5; i++ {
+		sb.WriteString("match\n")
+	}
+	if err := os.WriteFile(filepath.Join(root, "f.txt"), []byte(sb.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := (&GrepTool{}).Execute(context.Background(), map[string]any{
+		"pattern": "match", "output_mode": "content", "head_limit": 2,
+	}, root)
+	if !result.Success || !result.Metadata.Truncated {
+		t.Fatalf("result = %+v, want truncated success", result)
+	}
+	out := result.FormatForLLM()
+	if strings.Count(out, "f.txt:") != 2 || !strings.Contains(out, "(3 more matches") {
+		t.Errorf("FormatForLLM() =\n%s", out)
+	}
+}
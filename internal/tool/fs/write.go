@@ -66,6 +66,9 @@ func (t *WriteTool) PreparePermission(ctx context.Context, params map[string]any
 			return nil, &tool.ToolError{Message: "failed to read existing file: " + readErr.Error()}
 		}
 		diffMeta = perm.GenerateDiff(filePath, string(oldContent), content)
+		// GenerateDiff treats empty old content as a new file; an existing
+		// empty file is still an overwrite and should render as a diff.
+		diffMeta.IsNewFile = false
 	}
 
 	description := "Create new file"
@@ -109,6 +112,15 @@ func (t *WriteTool) ExecuteApproved(ctx context.Context, params map[string]any,
 	_, statErr := os.Stat(filePath)
 	isNewFile := os.IsNotExist(statErr)
 
+	// Keep the previous content for updates so hooks and the session change
+	// log can see what was replaced.
+	var originalFile any
+	if !isNewFile {
+		if old, err := os.ReadFile(filePath); err == nil {
+			originalFile = string(old)
+		}
+	}
+
 	// Get optional mode parameter (default 0644).
 	// Clamp to valid permission bits — JSON numbers are decimal, so an LLM
 	// sending 755 would otherwise become octal 01363 (setgid + wrong perms).
@@ -148,7 +160,7 @@ func (t *WriteTool) ExecuteApproved(ctx context.Context, params map[string]any,
 			"filePath":        filePath,
 			"content":         content,
 			"structuredPatch": []any{},
-			"originalFile":    nil,
+			"originalFile":    originalFile,
 		},
 		Metadata: toolresult.ResultMetadata{
 			Title:     t.Name(),
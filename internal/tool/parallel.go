@@ -0,0 +1,11 @@
+package tool
+
+import "github.com/yanmxa/gencode/internal/tool/perm"
+
+// ParallelSafe reports whether a call to the named tool may run alongside
+// the other calls of the same response. Read-only tools never ask for
+// permission or input, and subagents work independently; every other call
+// runs on its own, in call order, so approval prompts come one at a time.
+func ParallelSafe(name string) bool {
+	return perm.IsReadOnlyTool(name) || IsAgentToolName(name)
+}
@@ -65,7 +65,7 @@ func BypassPermissions() Checker { return permitAll{} }
 
 func isEditTool(name string) bool {
 	switch name {
-	case "Edit", "Write", "NotebookEdit":
+	case "Edit", "MultiEdit", "Write", "NotebookEdit":
 		return true
 	}
 	return false
@@ -96,6 +96,9 @@ var safeTools = func() map[string]bool {
 		"AskUserQuestion": true,
 		"CronList":        true,
 		"ToolSearch":      true,
+		"BashOutput":      true,
+		"ScratchpadWrite": true,
+		"ScratchpadRead":  true,
 	}
 	for name := range readOnlyTools {
 		m[name] = true
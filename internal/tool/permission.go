@@ -3,9 +3,11 @@ package tool
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/yanmxa/gencode/internal/core"
 	"github.com/yanmxa/gencode/internal/tool/perm"
+	"github.com/yanmxa/gencode/internal/tool/toolresult"
 )
 
 // WithPermission wraps core.Tools with permission checking.
@@ -48,10 +50,25 @@ func (pt *permissionTool) Description() string     { return pt.inner.Description
 func (pt *permissionTool) Schema() core.ToolSchema { return pt.inner.Schema() }
 
 func (pt *permissionTool) Execute(ctx context.Context, input map[string]any) (string, error) {
-	if !perm.IsSafeTool(pt.inner.Name()) {
-		if allow, reason := pt.check(ctx, pt.inner.Name(), input); !allow {
+	name := pt.inner.Name()
+	if !perm.IsSafeTool(name) {
+		allow, reason := pt.check(ctx, name, input)
+		auditPermission(name, input, allow, reason)
+		if !allow {
 			return "", fmt.Errorf("blocked: %s", reason)
 		}
 	}
-	return pt.inner.Execute(ctx, input)
+	if _, adapted := pt.inner.(*toolAdapter); adapted {
+		return pt.inner.Execute(ctx, input) // audited by runWithTimeout
+	}
+
+	// MCP and other core tools do not pass through runWithTimeout.
+	start := time.Now()
+	content, err := pt.inner.Execute(ctx, input)
+	result := toolresult.ToolResult{Success: err == nil, Output: content}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	auditExecution(name, input, result, time.Since(start))
+	return content, err
 }
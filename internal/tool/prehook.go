@@ -0,0 +1,79 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/log"
+)
+
+// PreToolHook runs before a tool call. It returns the input the tool should
+// run with, or a non-empty reason to block the call.
+type PreToolHook func(ctx context.Context, name string, input map[string]any) (map[string]any, string)
+
+// WithPreHook wraps core.Tools so that hook runs before every tool call.
+// A hook that panics is logged and the call goes ahead, so a broken hook
+// cannot take the session down. nil hook returns inner unchanged.
+func WithPreHook(inner core.Tools, hook PreToolHook) core.Tools {
+	if hook == nil {
+		return inner
+	}
+	return &preHookTools{inner: inner, hook: hook}
+}
+
+// preHookTools wraps a core.Tools and injects the pre-tool hook on Get().
+type preHookTools struct {
+	inner core.Tools
+	hook  PreToolHook
+}
+
+func (ht *preHookTools) Get(name string) core.Tool {
+	t := ht.inner.Get(name)
+	if t == nil {
+		return nil
+	}
+	return &preHookTool{inner: t, hook: ht.hook}
+}
+
+func (ht *preHookTools) All() []core.Tool           { return ht.inner.All() }
+func (ht *preHookTools) Add(tool core.Tool)         { ht.inner.Add(tool) }
+func (ht *preHookTools) Remove(name string)         { ht.inner.Remove(name) }
+func (ht *preHookTools) Schemas() []core.ToolSchema { return ht.inner.Schemas() }
+
+// preHookTool wraps a single core.Tool with the pre-tool hook.
+type preHookTool struct {
+	inner core.Tool
+	hook  PreToolHook
+}
+
+func (ht *preHookTool) Name() string            { return ht.inner.Name() }
+func (ht *preHookTool) Description() string     { return ht.inner.Description() }
+func (ht *preHookTool) Schema() core.ToolSchema { return ht.inner.Schema() }
+
+func (ht *preHookTool) Execute(ctx context.Context, input map[string]any) (string, error) {
+	input, reason := ht.runHook(ctx, input)
+	if reason != "" {
+		return "", fmt.Errorf("blocked by hook: %s", reason)
+	}
+	return ht.inner.Execute(ctx, input)
+}
+
+func (ht *preHookTool) runHook(ctx context.Context, input map[string]any) (updated map[string]any, reason string) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger().Warn("pre-tool hook panicked; running the tool anyway",
+				zap.String("tool", ht.inner.Name()),
+				zap.Any("panic", r),
+			)
+			updated, reason = input, ""
+		}
+	}()
+	updated, reason = ht.hook(ctx, ht.inner.Name(), input)
+	if updated == nil {
+		updated = input
+	}
+	return updated, reason
+}
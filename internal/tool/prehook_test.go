@@ -0,0 +1,74 @@
+package tool
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/core"
+)
+
+type inputRecordingTool struct {
+	stubCoreTool
+	got map[string]any
+}
+
+func (r *inputRecordingTool) Execute(_ context.Context, input map[string]any) (string, error) {
+	r.got = input
+	return r.name + " ran", nil
+}
+
+func TestWithPreHook_BlocksBeforePermissionCheck(t *testing.T) {
+	var checked []string
+	gated := WithPermission(core.NewTools(stubCoreTool{"Bash"}), func(_ context.Context, name string, _ map[string]any) (bool, string) {
+		checked = append(checked, name)
+		return true, ""
+	})
+	tools := WithPreHook(gated, func(_ context.Context, name string, _ map[string]any) (map[string]any, string) {
+		if name == "Bash" {
+			return nil, "rm is not allowed"
+		}
+		return nil, ""
+	})
+
+	_, err := tools.Get("Bash").Execute(context.Background(), map[string]any{"command": "rm -rf /"})
+	if err == nil || !strings.Contains(err.Error(), "blocked by hook: rm is not allowed") {
+		t.Fatalf("err = %v, want the hook's reason", err)
+	}
+	if len(checked) != 0 {
+		t.Errorf("blocked call must not reach the permission check, got %v", checked)
+	}
+}
+
+func TestWithPreHook_PassesUpdatedInput(t *testing.T) {
+	inner := &inputRecordingTool{stubCoreTool: stubCoreTool{"Bash"}}
+	tools := WithPreHook(core.NewTools(inner), func(_ context.Context, _ string, input map[string]any) (map[string]any, string) {
+		return map[string]any{"command": input["command"].(string) + " --dry-run"}, ""
+	})
+
+	out, err := tools.Get("Bash").Execute(context.Background(), map[string]any{"command": "make deploy"})
+	if err != nil || out != "Bash ran" {
+		t.Fatalf("Execute = %q, %v", out, err)
+	}
+	if inner.got["command"] != "make deploy --dry-run" {
+		t.Errorf("tool input = %v, want the hook's updated input", inner.got)
+	}
+}
+
+func TestWithPreHook_PanickingHookDoesNotBlock(t *testing.T) {
+	inner := &inputRecordingTool{stubCoreTool: stubCoreTool{"Read"}}
+	tools := WithPreHook(core.NewTools(inner), func(context.Context, string, map[string]any) (map[string]any, string) {
+		panic("broken hook")
+	})
+
+	out, err := tools.Get("Read").Execute(context.Background(), map[string]any{"file_path": "a.go"})
+	if err != nil || out != "Read ran" {
+		t.Fatalf("Execute = %q, %v; a broken hook should not stop the tool", out, err)
+	}
+	if inner.got["file_path"] != "a.go" {
+		t.Errorf("tool input = %v, want the original input", inner.got)
+	}
+	if WithPreHook(core.NewTools(inner), nil).Get("Read") != core.Tool(inner) {
+		t.Error("nil hook should leave the tools unwrapped")
+	}
+}
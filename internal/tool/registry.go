@@ -77,7 +77,9 @@ func (r *Registry) Execute(ctx context.Context, name string, params map[string]a
 	if !ok {
 		return toolresult.NewErrorResult(name, "unknown tool: "+name)
 	}
-	return tool.Execute(ctx, params, cwd)
+	return runWithTimeout(ctx, tool.Name(), params, func(ctx context.Context) toolresult.ToolResult {
+		return tool.Execute(ctx, params, cwd)
+	})
 }
 
 // ResetFetched clears all fetched deferred tools (delegates to package-level).
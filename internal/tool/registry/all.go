@@ -6,6 +6,7 @@ import (
 	_ "github.com/yanmxa/gencode/internal/tool/cron"
 	_ "github.com/yanmxa/gencode/internal/tool/fs"
 	_ "github.com/yanmxa/gencode/internal/tool/mode"
+	_ "github.com/yanmxa/gencode/internal/tool/scratchpad"
 	_ "github.com/yanmxa/gencode/internal/tool/skill"
 	_ "github.com/yanmxa/gencode/internal/tool/task"
 	_ "github.com/yanmxa/gencode/internal/tool/tasktools"
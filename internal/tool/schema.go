@@ -9,6 +9,8 @@ const (
 	ToolSendMessage   = "SendMessage"
 	ToolTaskOutput    = "TaskOutput"
 	ToolTaskStop      = "TaskStop"
+	ToolBashOutput    = "BashOutput"
+	ToolKillBash      = "KillBash"
 
 	// Deprecated aliases — kept for backward compatibility with cached model contexts.
 	ToolAgentOutput   = ToolTaskOutput
@@ -25,6 +27,9 @@ const (
 	ToolExitWorktree  = "ExitWorktree"
 	ToolToolSearch    = "ToolSearch"
 
+	ToolScratchpadWrite = "ScratchpadWrite"
+	ToolScratchpadRead  = "ScratchpadRead"
+
 	ToolAskUserQuestion = "AskUserQuestion"
 )
 
@@ -46,8 +51,10 @@ func GetToolSchemasWithMCP(mcpToolsGetter func() []core.ToolSchema) []core.ToolS
 	tools = append(tools, agentToolSchema, continueAgentToolSchema, sendMessageToolSchema)
 	tools = append(tools, toolSearchSchema)
 	tools = append(tools, trackerToolSchemas...)
+	tools = append(tools, scratchpadToolSchemas...)
 	tools = append(tools, cronToolSchemas...)
 	tools = append(tools, worktreeToolSchemas...)
+	tools = append(tools, commandToolSchemas()...)
 
 	if mcpToolsGetter != nil {
 		tools = append(tools, mcpToolsGetter()...)
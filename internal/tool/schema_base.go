@@ -13,9 +13,11 @@ Usage:
 - By default, it reads up to 2000 lines starting from the beginning of the file
 - You can optionally specify a line offset and limit (especially handy for long files), but it's recommended to read the whole file by not providing these parameters
 - Results are returned with line numbers starting at 1
+- When offset or limit is given, the output starts with a header such as "[Showing lines 101-150 of 2000]"; line numbers always match the file
 - This tool can only read files, not directories. To read a directory, use an ls command via the Bash tool.
 - You will regularly be asked to read screenshots. If the user provides a path to a screenshot, ALWAYS use this tool to view the file at the path.
-- If you read a file that exists but has empty contents you will receive a system reminder warning in place of file contents.`,
+- If you read a file that exists but has empty contents you will receive a system reminder warning in place of file contents.
+- Binary files are not returned as text; you get their size and detected type instead. Pass raw=true to get a hex dump of the first 4 KB.`,
 	Parameters: map[string]any{
 		"type": "object",
 		"properties": map[string]any{
@@ -31,6 +33,10 @@ Usage:
 				"type":        "integer",
 				"description": "The number of lines to read. Only provide if the file is too large to read at once.",
 			},
+			"raw": map[string]any{
+				"type":        "boolean",
+				"description": "Return a hex dump for binary files instead of refusing to read them.",
+			},
 		},
 		"required": []string{"file_path"},
 	},
@@ -40,6 +46,7 @@ var globToolSchema = core.ToolSchema{
 	Name: "Glob",
 	Description: `Fast file pattern matching tool that works with any codebase size.
 - Supports glob patterns like "**/*.go" or "src/**/*.ts"
+- Combine patterns with commas and exclude with "!", e.g. "**/*.go,!**/*_test.go" (all Go files except tests)
 - Returns matching file paths sorted by modification time (newest first)
 - Use this tool when you need to find files by name patterns
 - When you are doing an open-ended search that may require multiple rounds of globbing and grepping, use the Agent tool instead`,
@@ -48,7 +55,7 @@ var globToolSchema = core.ToolSchema{
 		"properties": map[string]any{
 			"pattern": map[string]any{
 				"type":        "string",
-				"description": "The glob pattern to match files against",
+				"description": "The glob pattern to match files against. Separate multiple patterns with commas; prefix a pattern with ! to exclude its matches",
 			},
 			"path": map[string]any{
 				"type":        "string",
@@ -187,6 +194,10 @@ When searching for current information, always use the present year rather than
 				"items":       map[string]any{"type": "string"},
 				"description": "Exclude results from these domains",
 			},
+			"no_cache": map[string]any{
+				"type":        "boolean",
+				"description": "Skip results cached from an identical earlier search and search again (default: false)",
+			},
 		},
 		"required": []string{"query"},
 	},
@@ -228,6 +239,50 @@ Usage:
 	},
 }
 
+var multiEditToolSchema = core.ToolSchema{
+	Name: "MultiEdit",
+	Description: `Makes several exact string replacements in one file as a single atomic change. Prefer it over repeated Edit calls when changing multiple places in the same file.
+
+Usage:
+- Follows the same rules as Edit: read the file first, preserve exact indentation, and make each old_string unique or set replace_all.
+- Edits apply in order, each to the result of the previous one. Plan them so an earlier edit does not change text a later edit needs to find.
+- The change is atomic: if any edit fails, none are applied and the error names the failing edit.`,
+	Parameters: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"file_path": map[string]any{
+				"type":        "string",
+				"description": "Path to the file to modify. Relative paths are resolved from the current session working directory.",
+			},
+			"edits": map[string]any{
+				"type":        "array",
+				"description": "Replacements to apply in order",
+				"minItems":    1,
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"old_string": map[string]any{
+							"type":        "string",
+							"description": "The text to replace",
+						},
+						"new_string": map[string]any{
+							"type":        "string",
+							"description": "The text to replace it with (must be different from old_string)",
+						},
+						"replace_all": map[string]any{
+							"type":        "boolean",
+							"description": "Replace all occurrences of old_string (default false)",
+							"default":     false,
+						},
+					},
+					"required": []string{"old_string", "new_string"},
+				},
+			},
+		},
+		"required": []string{"file_path", "edits"},
+	},
+}
+
 var writeToolSchema = core.ToolSchema{
 	Name: "Write",
 	Description: `Writes a file to the local filesystem.
@@ -272,8 +327,9 @@ IMPORTANT: Avoid using this tool to run grep, find, cat, head, tail, sed, awk, o
 - Edit files: Use Edit (NOT sed/awk)
 - Write files: Use Write (NOT echo/cat with redirection)
 
-You may specify an optional timeout in milliseconds (up to 600000ms / 10 minutes). By default, your command will timeout after 120000ms (2 minutes).
-You can use the run_in_background parameter to run the command in the background. You will be notified when it finishes.`,
+You may specify an optional timeout in milliseconds (up to 600000ms / 10 minutes). By default, your command will timeout after 120000ms (2 minutes). A command that times out is killed along with any processes it started.
+Output longer than 30000 bytes (or max_output) keeps its beginning and end, with a note saying how many bytes were omitted in between.
+You can use the run_in_background parameter to run the command in the background, e.g. for a dev server or a long build. It returns a task ID right away; read the command's output as it runs with BashOutput and stop it with KillBash. You will be notified when it finishes.`,
 	Parameters: map[string]any{
 		"type": "object",
 		"properties": map[string]any{
@@ -289,9 +345,13 @@ You can use the run_in_background parameter to run the command in the background
 				"type":        "integer",
 				"description": "Optional timeout in milliseconds (max 600000)",
 			},
+			"max_output": map[string]any{
+				"type":        "integer",
+				"description": "Optional cap on returned output in bytes (default 30000)",
+			},
 			"run_in_background": map[string]any{
 				"type":        "boolean",
-				"description": "Set to true to run this command in the background. You will be notified when it completes.",
+				"description": "Set to true to run this command in the background. Returns a task ID for BashOutput and KillBash; you will be notified when it completes.",
 			},
 		},
 		"required": []string{"command"},
@@ -338,6 +398,40 @@ var taskStopToolSchema = core.ToolSchema{
 	},
 }
 
+var bashOutputToolSchema = core.ToolSchema{
+	Name:        ToolBashOutput,
+	Description: "Reads the output a background Bash command produced since the last BashOutput call, along with its status (running, completed, failed, or killed) and exit code. Use this to check on commands started with run_in_background.",
+	Parameters: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"bash_id": map[string]any{
+				"type":        "string",
+				"description": "The task ID returned when the command was started",
+			},
+			"filter": map[string]any{
+				"type":        "string",
+				"description": "Optional regular expression; only new lines that match are returned. Lines that do not match are discarded.",
+			},
+		},
+		"required": []string{"bash_id"},
+	},
+}
+
+var killBashToolSchema = core.ToolSchema{
+	Name:        ToolKillBash,
+	Description: "Stops a background Bash command started with run_in_background, along with any processes it started.",
+	Parameters: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"bash_id": map[string]any{
+				"type":        "string",
+				"description": "The task ID of the background command to stop",
+			},
+		},
+		"required": []string{"bash_id"},
+	},
+}
+
 var askUserQuestionToolSchema = core.ToolSchema{
 	Name: "AskUserQuestion",
 	Description: `Ask the user a question with predefined choices. An 'Other' option with free-text input is always appended automatically.
@@ -394,8 +488,11 @@ func baseToolSchemas() []core.ToolSchema {
 		webFetchToolSchema,
 		webSearchToolSchema,
 		editToolSchema,
+		multiEditToolSchema,
 		writeToolSchema,
 		bashToolSchema,
+		bashOutputToolSchema,
+		killBashToolSchema,
 		taskStopToolSchema,
 		askUserQuestionToolSchema,
 	}
@@ -0,0 +1,41 @@
+package tool
+
+import "github.com/yanmxa/gencode/internal/core"
+
+// scratchpadToolSchemas defines the schemas for the session scratchpad tools.
+var scratchpadToolSchemas = []core.ToolSchema{
+	{
+		Name: ToolScratchpadWrite,
+		Description: `Save intermediate notes to a private, session-scoped scratchpad instead of writing them to files.
+
+When to use:
+- Long multi-step tasks where findings must be remembered across many tool calls
+- Recording decisions, file locations, or partial results you will need later
+
+The scratchpad is kept in memory for this session only, survives conversation compaction, and is cleared by /clear.
+By default content is appended as a new line; use mode="replace" to rewrite the whole scratchpad with a condensed version (an empty replace clears it).`,
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"content": map[string]any{
+					"type":        "string",
+					"description": "The note to save",
+				},
+				"mode": map[string]any{
+					"type":        "string",
+					"enum":        []string{"append", "replace"},
+					"description": "append (default) adds to the existing notes; replace overwrites them",
+				},
+			},
+			"required": []string{"content"},
+		},
+	},
+	{
+		Name:        ToolScratchpadRead,
+		Description: `Read back everything saved with ScratchpadWrite in this session.`,
+		Parameters: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		},
+	},
+}
@@ -0,0 +1,36 @@
+package scratchpad
+
+import (
+	"context"
+
+	"github.com/yanmxa/gencode/internal/tool"
+	"github.com/yanmxa/gencode/internal/tool/toolresult"
+)
+
+// ReadTool returns the session scratchpad contents.
+type ReadTool struct{}
+
+func (t *ReadTool) Name() string        { return tool.ToolScratchpadRead }
+func (t *ReadTool) Description() string { return "Read notes from the session scratchpad" }
+func (t *ReadTool) Icon() string        { return "📝" }
+
+func (t *ReadTool) Execute(ctx context.Context, params map[string]any, cwd string) toolresult.ToolResult {
+	text := Text(tool.ScratchpadID(ctx))
+	output := text
+	if output == "" {
+		output = "Scratchpad is empty."
+	}
+	return toolresult.ToolResult{
+		Success: true,
+		Output:  output,
+		Metadata: toolresult.ResultMetadata{
+			Title: t.Name(),
+			Icon:  t.Icon(),
+			Size:  int64(len(text)),
+		},
+	}
+}
+
+func init() {
+	tool.Register(&ReadTool{})
+}
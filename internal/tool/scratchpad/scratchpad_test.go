@@ -0,0 +1,86 @@
+package scratchpad
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/tool"
+)
+
+func TestScratchpadWriteAppendReplaceAndRead(t *testing.T) {
+	Reset(Main)
+	t.Cleanup(func() { Reset(Main) })
+	ctx := context.Background()
+
+	for _, note := range []string{"auth lives in internal/auth", "tests use fake clock"} {
+		if r := (&WriteTool{}).Execute(ctx, map[string]any{"content": note}, ""); !r.Success {
+			t.Fatalf("append %q failed: %s", note, r.Error)
+		}
+	}
+	got := (&ReadTool{}).Execute(ctx, nil, "").Output
+	if got != "auth lives in internal/auth\ntests use fake clock" {
+		t.Fatalf("unexpected scratchpad after append: %q", got)
+	}
+
+	if r := (&WriteTool{}).Execute(ctx, map[string]any{"content": "condensed", "mode": "replace"}, ""); !r.Success {
+		t.Fatalf("replace failed: %s", r.Error)
+	}
+	if got := Text(Main); got != "condensed" {
+		t.Fatalf("expected replaced contents, got %q", got)
+	}
+
+	if r := (&WriteTool{}).Execute(ctx, map[string]any{"content": strings.Repeat("x", MaxSize)}, ""); r.Success {
+		t.Fatal("expected write beyond MaxSize to fail")
+	}
+	if got := Text(Main); got != "condensed" {
+		t.Fatalf("failed write must not modify scratchpad, got %q", got)
+	}
+}
+
+func TestAppendToSummary(t *testing.T) {
+	Reset(Main)
+	t.Cleanup(func() { Reset(Main) })
+
+	if got := AppendToSummary(Main, "summary"); got != "summary" {
+		t.Fatalf("empty scratchpad should leave summary unchanged, got %q", got)
+	}
+	Write(Main, "remember the flag", false)
+	got := AppendToSummary(Main, "summary")
+	if !strings.HasPrefix(got, "summary\n\n<scratchpad>\nremember the flag\n</scratchpad>") {
+		t.Fatalf("expected scratchpad to be re-injected, got %q", got)
+	}
+	if got := AppendToSummary(Main, ""); got != "" {
+		t.Fatalf("failed compaction must stay empty, got %q", got)
+	}
+}
+
+func TestScratchpadIsolatedBySession(t *testing.T) {
+	a := tool.WithScratchpadID(context.Background(), "serve:a")
+	b := tool.WithScratchpadID(context.Background(), "serve:b")
+	t.Cleanup(func() { Reset("serve:a"); Reset("serve:b") })
+
+	(&WriteTool{}).Execute(a, map[string]any{"content": "notes for a"}, "")
+	(&WriteTool{}).Execute(b, map[string]any{"content": "notes for b", "mode": "replace"}, "")
+	if got := (&ReadTool{}).Execute(a, nil, "").Output; got != "notes for a" {
+		t.Errorf("session a reads %q, want its own notes", got)
+	}
+	if got := Text(Main); got != "" {
+		t.Errorf("interactive scratchpad = %q, want it untouched", got)
+	}
+
+	// Adapted tools carry the agent's scratchpad ID into each call.
+	adapted := tool.AdaptToolRegistry([]core.ToolSchema{{Name: tool.ToolScratchpadWrite}}, nil, tool.WithScratchpad("serve:a"))
+	if _, err := adapted.Get(tool.ToolScratchpadWrite).Execute(context.Background(), map[string]any{"content": "more"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := Text("serve:a"); got != "notes for a\nmore" {
+		t.Errorf("adapted write landed in %q, want session a", got)
+	}
+
+	Reset("serve:b")
+	if !strings.HasPrefix(Text("serve:a"), "notes for a") || Text("serve:b") != "" {
+		t.Errorf("resetting b changed a: a=%q b=%q", Text("serve:a"), Text("serve:b"))
+	}
+}
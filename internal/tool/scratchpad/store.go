@@ -0,0 +1,66 @@
+// Package scratchpad provides the ScratchpadWrite and ScratchpadRead tools,
+// backed by in-memory notes stores, one per session or agent.
+//
+// Each store is named by an ID that the agent passes to its tools through
+// the context (see tool.WithScratchpad). The interactive session uses Main.
+package scratchpad
+
+import (
+	"strings"
+	"sync"
+)
+
+// Main is the ID of the interactive (TUI) session's scratchpad.
+const Main = ""
+
+// MaxSize caps the scratchpad so it stays cheap to re-inject after compaction.
+const MaxSize = 16 * 1024
+
+var pads = struct {
+	mu   sync.Mutex
+	text map[string]string
+}{text: make(map[string]string)}
+
+// Text returns the contents of the scratchpad with id.
+func Text(id string) string {
+	pads.mu.Lock()
+	defer pads.mu.Unlock()
+	return pads.text[id]
+}
+
+// Write appends note to the scratchpad with id, or replaces its contents
+// when replace is set. It reports false if the result would exceed MaxSize.
+func Write(id, note string, replace bool) bool {
+	note = strings.TrimRight(note, "\n")
+	pads.mu.Lock()
+	defer pads.mu.Unlock()
+
+	next := note
+	if text := pads.text[id]; !replace && text != "" {
+		next = text + "\n" + note
+	}
+	if len(next) > MaxSize {
+		return false
+	}
+	pads.text[id] = next
+	return true
+}
+
+// Reset clears the scratchpad with id. Called when the conversation is
+// cleared, a different session is loaded, or the session or agent ends.
+func Reset(id string) {
+	pads.mu.Lock()
+	delete(pads.text, id)
+	pads.mu.Unlock()
+}
+
+// AppendToSummary attaches the scratchpad with id to a compaction summary so
+// notes survive the history being replaced. The summary is returned
+// unchanged when the scratchpad is empty.
+func AppendToSummary(id, summary string) string {
+	text := Text(id)
+	if text == "" || summary == "" {
+		return summary
+	}
+	return summary + "\n\n<scratchpad>\n" + text + "\n</scratchpad>\nThese are your scratchpad notes from before compaction; ScratchpadRead returns the same contents."
+}
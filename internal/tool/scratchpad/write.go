@@ -0,0 +1,48 @@
+package scratchpad
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yanmxa/gencode/internal/tool"
+	"github.com/yanmxa/gencode/internal/tool/toolresult"
+)
+
+// WriteTool stores notes in the session scratchpad.
+type WriteTool struct{}
+
+func (t *WriteTool) Name() string        { return tool.ToolScratchpadWrite }
+func (t *WriteTool) Description() string { return "Save notes to the session scratchpad" }
+func (t *WriteTool) Icon() string        { return "📝" }
+
+func (t *WriteTool) Execute(ctx context.Context, params map[string]any, cwd string) toolresult.ToolResult {
+	content := tool.GetString(params, "content")
+	replace := tool.GetString(params, "mode") == "replace"
+	if content == "" && !replace {
+		return toolresult.NewErrorResult(t.Name(), "content is required")
+	}
+	id := tool.ScratchpadID(ctx)
+	if !Write(id, content, replace) {
+		return toolresult.NewErrorResult(t.Name(), fmt.Sprintf("scratchpad would exceed %s; use mode=replace with a condensed version", toolresult.FormatSize(MaxSize)))
+	}
+
+	size := len(Text(id))
+	subtitle := "appended"
+	if replace {
+		subtitle = "replaced"
+	}
+	return toolresult.ToolResult{
+		Success: true,
+		Output:  fmt.Sprintf("Scratchpad %s (%s of %s used).", subtitle, toolresult.FormatSize(int64(size)), toolresult.FormatSize(MaxSize)),
+		Metadata: toolresult.ResultMetadata{
+			Title:    t.Name(),
+			Icon:     t.Icon(),
+			Subtitle: subtitle,
+			Size:     int64(size),
+		},
+	}
+}
+
+func init() {
+	tool.Register(&WriteTool{})
+}
@@ -0,0 +1,109 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yanmxa/gencode/internal/task"
+	"github.com/yanmxa/gencode/internal/tool"
+	"github.com/yanmxa/gencode/internal/tool/toolresult"
+)
+
+const (
+	IconBashOutput = ">"
+)
+
+// BashOutputTool returns the output a background Bash command produced
+// since the last time it was read.
+type BashOutputTool struct{}
+
+func (t *BashOutputTool) Name() string { return tool.ToolBashOutput }
+func (t *BashOutputTool) Description() string {
+	return "Reads new output from a background Bash command by its ID"
+}
+func (t *BashOutputTool) Icon() string { return IconBashOutput }
+
+// Execute returns the new output and the status of a background command
+func (t *BashOutputTool) Execute(ctx context.Context, params map[string]any, cwd string) toolresult.ToolResult {
+	start := time.Now()
+
+	bgTask, err := lookupBashTask(tool.GetString(params, "bash_id"))
+	if err != nil {
+		return toolresult.NewErrorResult(t.Name(), err.Error())
+	}
+
+	var filter *regexp.Regexp
+	if pattern := tool.GetString(params, "filter"); pattern != "" {
+		filter, err = regexp.Compile(pattern)
+		if err != nil {
+			return toolresult.NewErrorResult(t.Name(), fmt.Sprintf("invalid filter: %v", err))
+		}
+	}
+
+	// Read the status first so output written before the command finished
+	// is never reported after a "completed" status.
+	info := bgTask.GetStatus()
+	newOutput := filterLines(bgTask.ReadNewOutput(), filter)
+	status := formatStatusString(info)
+
+	output := formatTaskOutput(info, status)
+	if newOutput == "" {
+		output += "\n(no new output)"
+	} else {
+		output += "\nNew output:\n" + newOutput
+	}
+	if info.Error != "" {
+		output += fmt.Sprintf("\nError: %s", info.Error)
+	}
+
+	return toolresult.ToolResult{
+		Success: true,
+		Output:  output,
+		Metadata: toolresult.ResultMetadata{
+			Title:    t.Name(),
+			Icon:     t.Icon(),
+			Subtitle: fmt.Sprintf("%s: %s", info.ID, status),
+			Duration: time.Since(start),
+		},
+	}
+}
+
+// lookupBashTask returns the background Bash command with the given ID.
+func lookupBashTask(id string) (*task.BashTask, error) {
+	if id == "" {
+		return nil, fmt.Errorf("bash_id is required")
+	}
+	bgTask, found := task.Default().Get(id)
+	if !found {
+		return nil, fmt.Errorf("background command not found: %s", id)
+	}
+	bashTask, ok := bgTask.(*task.BashTask)
+	if !ok {
+		return nil, fmt.Errorf("task %s is not a background Bash command", id)
+	}
+	return bashTask, nil
+}
+
+// filterLines keeps the lines of output that match filter.
+func filterLines(output string, filter *regexp.Regexp) string {
+	if filter == nil || output == "" {
+		return output
+	}
+	var kept []string
+	for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+		if filter.MatchString(line) {
+			kept = append(kept, line)
+		}
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+	return strings.Join(kept, "\n") + "\n"
+}
+
+func init() {
+	tool.Register(&BashOutputTool{})
+}
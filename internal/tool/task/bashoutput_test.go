@@ -0,0 +1,70 @@
+package task
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/task"
+)
+
+// startBashTask registers a running background command whose output the
+// test writes itself.
+func startBashTask(t *testing.T) *task.BashTask {
+	t.Helper()
+	task.Initialize(task.Options{})
+	t.Cleanup(task.ResetService)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	cmd := exec.CommandContext(ctx, "sleep", "10")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return task.Default().CreateBashTask(cmd, "make serve", "Dev server", ctx, cancel)
+}
+
+func TestBashOutputTool_ReturnsOnlyNewOutput(t *testing.T) {
+	bg := startBashTask(t)
+	tool := &BashOutputTool{}
+
+	bg.Write([]byte("compiling\nready on :8080\n"))
+	result := tool.Execute(context.Background(), map[string]any{"bash_id": bg.ID}, ".")
+	if !result.Success || !strings.Contains(result.Output, "Status: running") || !strings.Contains(result.Output, "ready on :8080") {
+		t.Fatalf("first read = %+v", result)
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"bash_id": bg.ID}, ".")
+	if strings.Contains(result.Output, "compiling") || !strings.Contains(result.Output, "(no new output)") {
+		t.Errorf("second read repeated output: %s", result.Output)
+	}
+
+	bg.Write([]byte("GET /health 200\nGET /api 500\n"))
+	result = tool.Execute(context.Background(), map[string]any{"bash_id": bg.ID, "filter": " 5\\d\\d$"}, ".")
+	if !strings.Contains(result.Output, "GET /api 500") || strings.Contains(result.Output, "/health") {
+		t.Errorf("filtered read = %s", result.Output)
+	}
+}
+
+func TestBashOutputTool_RejectsUnknownID(t *testing.T) {
+	startBashTask(t)
+	result := (&BashOutputTool{}).Execute(context.Background(), map[string]any{"bash_id": "nope"}, ".")
+	if result.Success || !strings.Contains(result.Error, "not found") {
+		t.Errorf("result = %+v, want a not-found error", result)
+	}
+}
+
+func TestKillBashTool_StopsCommand(t *testing.T) {
+	bg := startBashTask(t)
+	tool := &KillBashTool{}
+
+	result := tool.Execute(context.Background(), map[string]any{"bash_id": bg.ID}, ".")
+	if !result.Success || bg.IsRunning() {
+		t.Fatalf("kill = %+v, running = %v", result, bg.IsRunning())
+	}
+	result = tool.Execute(context.Background(), map[string]any{"bash_id": bg.ID}, ".")
+	if result.Success || !strings.Contains(result.Error, "already finished") {
+		t.Errorf("second kill = %+v, want an already-finished error", result)
+	}
+}
@@ -0,0 +1,61 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yanmxa/gencode/internal/task"
+	"github.com/yanmxa/gencode/internal/tool"
+	"github.com/yanmxa/gencode/internal/tool/toolresult"
+)
+
+const (
+	IconKillBash = "x"
+)
+
+// KillBashTool stops a background Bash command and everything it started.
+type KillBashTool struct{}
+
+func (t *KillBashTool) Name() string        { return tool.ToolKillBash }
+func (t *KillBashTool) Description() string { return "Stops a background Bash command by its ID" }
+func (t *KillBashTool) Icon() string        { return IconKillBash }
+
+// Execute stops the background command
+func (t *KillBashTool) Execute(ctx context.Context, params map[string]any, cwd string) toolresult.ToolResult {
+	start := time.Now()
+
+	bgTask, err := lookupBashTask(tool.GetString(params, "bash_id"))
+	if err != nil {
+		return toolresult.NewErrorResult(t.Name(), err.Error())
+	}
+	if !bgTask.IsRunning() {
+		info := bgTask.GetStatus()
+		return toolresult.NewErrorResult(t.Name(), fmt.Sprintf("command already finished with status: %s", formatStatusString(info)))
+	}
+
+	if err := task.Default().Kill(bgTask.ID); err != nil {
+		return toolresult.NewErrorResult(t.Name(), fmt.Sprintf("failed to stop command: %v", err))
+	}
+
+	info := bgTask.GetStatus()
+	output := fmt.Sprintf("Command stopped.\n%s", formatTaskOutput(info, formatStatusString(info)))
+	if newOutput := bgTask.ReadNewOutput(); newOutput != "" {
+		output += "\nNew output before stop:\n" + newOutput
+	}
+
+	return toolresult.ToolResult{
+		Success: true,
+		Output:  output,
+		Metadata: toolresult.ResultMetadata{
+			Title:    t.Name(),
+			Icon:     t.Icon(),
+			Subtitle: fmt.Sprintf("Stopped: %s", bgTask.ID),
+			Duration: time.Since(start),
+		},
+	}
+}
+
+func init() {
+	tool.Register(&KillBashTool{})
+}
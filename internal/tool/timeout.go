@@ -0,0 +1,126 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/yanmxa/gencode/internal/tool/toolresult"
+)
+
+// DefaultTimeouts limit tools that have no toolTimeouts setting. Tools not
+// listed here run without a limit.
+var DefaultTimeouts = map[string]time.Duration{
+	"Bash":     120 * time.Second,
+	"WebFetch": 30 * time.Second,
+}
+
+// timeoutGrace is how long a tool whose deadline passed may take to return
+// its partial output before it is abandoned.
+const timeoutGrace = 2 * time.Second
+
+var timeoutProvider atomic.Value // stores func(string) (time.Duration, bool)
+
+// SetTimeoutProvider registers the source of per-tool timeout settings. fn
+// reports a tool's configured limit and whether one is set; 0 means none.
+func SetTimeoutProvider(fn func(name string) (time.Duration, bool)) {
+	timeoutProvider.Store(fn)
+}
+
+// Timeout returns how long a tool may run: its configured limit, else its
+// entry in DefaultTimeouts, else 0 for no limit.
+func Timeout(name string) time.Duration {
+	if fn, ok := timeoutProvider.Load().(func(string) (time.Duration, bool)); ok && fn != nil {
+		if d, set := fn(name); set {
+			return d
+		}
+	}
+	return DefaultTimeouts[name]
+}
+
+// callTimeout is the limit for one call: Timeout, raised to the call's own
+// timeout argument in milliseconds (Bash, TaskOutput) when that is longer.
+func callTimeout(name string, params map[string]any) time.Duration {
+	d := Timeout(name)
+	if d <= 0 {
+		return 0
+	}
+	if ms := GetFloat64(params, "timeout", 0); ms > 0 {
+		d = max(d, time.Duration(ms)*time.Millisecond)
+	}
+	return d
+}
+
+// runWithTimeout runs a tool call under its timeout and records it in the
+// audit log. When the deadline passes, the call's context is cancelled and
+// the result is an error naming the timeout, keeping any output the tool
+// returns within timeoutGrace.
+func runWithTimeout(ctx context.Context, name string, params map[string]any, run func(context.Context) toolresult.ToolResult) toolresult.ToolResult {
+	start := time.Now()
+	result := runUnderLimit(ctx, name, params, run)
+	auditExecution(name, params, result, time.Since(start))
+	return result
+}
+
+func runUnderLimit(ctx context.Context, name string, params map[string]any, run func(context.Context) toolresult.ToolResult) toolresult.ToolResult {
+	limit := callTimeout(name, params)
+	if limit <= 0 {
+		return run(ctx)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, limit)
+	defer cancel()
+
+	type outcome struct {
+		result toolresult.ToolResult
+		panic  any
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- outcome{panic: r}
+			}
+		}()
+		done <- outcome{result: run(runCtx)}
+	}()
+	// Re-raise panics here so callers' recovery still sees them.
+	result := func(o outcome) toolresult.ToolResult {
+		if o.panic != nil {
+			panic(o.panic)
+		}
+		return o.result
+	}
+
+	select {
+	case o := <-done:
+		r := result(o)
+		if !r.Success && runCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			return timedOutResult(name, limit, r.Output)
+		}
+		return r
+	case <-runCtx.Done():
+	}
+	if ctx.Err() != nil {
+		// Cancelled by the caller, not the timeout: wait as before.
+		return result(<-done)
+	}
+
+	select {
+	case o := <-done:
+		r := result(o)
+		if r.Success {
+			return r
+		}
+		return timedOutResult(name, limit, r.Output)
+	case <-time.After(timeoutGrace):
+		return timedOutResult(name, limit, "")
+	}
+}
+
+func timedOutResult(name string, limit time.Duration, output string) toolresult.ToolResult {
+	r := toolresult.NewErrorResult(name, fmt.Sprintf("Tool %s timed out after %gs", name, limit.Seconds()))
+	r.Output = output
+	return r
+}
@@ -0,0 +1,98 @@
+package tool
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yanmxa/gencode/internal/tool/toolresult"
+)
+
+// sleepTool waits for its delay or until cancelled, returning partial output
+// when cancelled.
+type sleepTool struct{ delay time.Duration }
+
+func (t *sleepTool) Name() string        { return "TestSleep" }
+func (t *sleepTool) Description() string { return "sleeps" }
+func (t *sleepTool) Icon() string        { return "z" }
+func (t *sleepTool) Execute(ctx context.Context, params map[string]any, cwd string) toolresult.ToolResult {
+	select {
+	case <-time.After(t.delay):
+		return toolresult.ToolResult{Success: true, Output: "done"}
+	case <-ctx.Done():
+		return toolresult.ToolResult{Success: false, Output: "partial", Error: ctx.Err().Error()}
+	}
+}
+
+func setTimeouts(t *testing.T, timeouts map[string]time.Duration) {
+	t.Helper()
+	SetTimeoutProvider(func(name string) (time.Duration, bool) {
+		d, ok := timeouts[name]
+		return d, ok
+	})
+	t.Cleanup(func() { SetTimeoutProvider(nil) })
+}
+
+func TestRegistryExecuteTimesOut(t *testing.T) {
+	setTimeouts(t, map[string]time.Duration{"TestSleep": 20 * time.Millisecond})
+	r := NewRegistry()
+	r.Register(&sleepTool{delay: time.Minute})
+
+	start := time.Now()
+	result := r.Execute(context.Background(), "TestSleep", nil, "")
+	if time.Since(start) > 5*time.Second {
+		t.Fatal("timeout did not stop the call")
+	}
+	if result.Success || result.Error != "Tool TestSleep timed out after 0.02s" {
+		t.Fatalf("result = %+v, want a timeout error", result)
+	}
+	if result.Output != "partial" {
+		t.Errorf("output = %q, want the tool's partial output", result.Output)
+	}
+}
+
+func TestRegistryExecuteWithinTimeout(t *testing.T) {
+	setTimeouts(t, map[string]time.Duration{"TestSleep": time.Minute})
+	r := NewRegistry()
+	r.Register(&sleepTool{delay: time.Millisecond})
+
+	if result := r.Execute(context.Background(), "TestSleep", nil, ""); !result.Success || result.Output != "done" {
+		t.Fatalf("result = %+v, want success", result)
+	}
+}
+
+func TestTimeoutDefaultsAndOverrides(t *testing.T) {
+	setTimeouts(t, map[string]time.Duration{"WebFetch": 0, "Read": 5 * time.Second})
+
+	if d := Timeout("Bash"); d != 120*time.Second {
+		t.Errorf("Bash default = %v, want 120s", d)
+	}
+	if d := Timeout("WebFetch"); d != 0 {
+		t.Errorf("WebFetch set to 0 = %v, want no limit", d)
+	}
+	if d := Timeout("Read"); d != 5*time.Second {
+		t.Errorf("Read = %v, want the setting", d)
+	}
+	if d := Timeout("Glob"); d != 0 {
+		t.Errorf("unlisted tool = %v, want no limit", d)
+	}
+	if d := callTimeout("Bash", map[string]any{"timeout": float64(300000)}); d != 300*time.Second {
+		t.Errorf("Bash with timeout arg = %v, want the longer per-call timeout", d)
+	}
+	if d := callTimeout("Bash", map[string]any{"timeout": float64(1000)}); d != 120*time.Second {
+		t.Errorf("Bash with short timeout arg = %v, want the configured limit", d)
+	}
+}
+
+func TestRunWithTimeoutKeepsPanics(t *testing.T) {
+	setTimeouts(t, map[string]time.Duration{"TestSleep": time.Minute})
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "boom") {
+			t.Fatalf("recovered %v, want the tool's panic", r)
+		}
+	}()
+	runWithTimeout(context.Background(), "TestSleep", nil, func(context.Context) toolresult.ToolResult {
+		panic("boom")
+	})
+}
@@ -44,6 +44,12 @@ func (r ToolResult) FormatForLLM() string {
 	case "Read":
 		if len(r.Lines) > 0 {
 			sb.Grow(len(r.Lines) * 40)
+			// Output holds the range header when a slice was requested; line
+			// numbers stay those of the real file.
+			if r.Output != "" {
+				sb.WriteString(r.Output)
+				sb.WriteString("\n")
+			}
 			for _, line := range r.Lines {
 				fmt.Fprintf(&sb, "%6d\t%s\n", line.LineNo, line.Text)
 			}
@@ -53,6 +53,7 @@ func (t *WebSearchTool) Execute(ctx context.Context, params map[string]any, cwd
 		AllowedDomains: allowedDomains,
 		BlockedDomains: blockedDomains,
 		Timeout:        30 * time.Second,
+		NoCache:        tool.GetBool(params, "no_cache"),
 	}
 
 	results, err := searchProvider.Search(ctx, query, opts)
@@ -0,0 +1,151 @@
+// Package undo keeps snapshots of files modified by tools so /undo can put
+// them back, most recent change first. Snapshots live in memory for the
+// session only.
+package undo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultLimit is how many snapshots a Stack keeps before dropping the oldest.
+const DefaultLimit = 50
+
+// ErrEmpty is returned by Undo when there is nothing left to undo.
+var ErrEmpty = errors.New("nothing to undo")
+
+// ErrModified is returned by Undo when the file no longer holds what the tool
+// wrote, so restoring it would discard someone else's changes.
+var ErrModified = errors.New("file changed since the tool modified it")
+
+// Snapshot is the state of one file around a single tool change.
+type Snapshot struct {
+	Path    string
+	Tool    string
+	Created bool   // the tool created the file; undoing deletes it
+	Before  string // content before the change, unused when Created
+	After   string // content the tool left behind
+	Time    time.Time
+}
+
+// Stack is a bounded, concurrency-safe stack of snapshots.
+type Stack struct {
+	mu        sync.Mutex
+	snapshots []Snapshot
+	limit     int
+}
+
+// New creates an empty stack holding at most limit snapshots. A limit of
+// zero or less uses DefaultLimit.
+func New(limit int) *Stack {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	return &Stack{limit: limit}
+}
+
+// Push records a snapshot, dropping the oldest one when the stack is full.
+// A zero Time is set to now.
+func (s *Stack) Push(snap Snapshot) {
+	if snap.Path == "" {
+		return
+	}
+	if snap.Time.IsZero() {
+		snap.Time = time.Now()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshots = append(s.snapshots, snap)
+	if over := len(s.snapshots) - s.limit; over > 0 {
+		s.snapshots = append([]Snapshot(nil), s.snapshots[over:]...)
+	}
+}
+
+// Len reports how many snapshots can be undone.
+func (s *Stack) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.snapshots)
+}
+
+// Reset discards all snapshots.
+func (s *Stack) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshots = nil
+}
+
+// Undo restores the most recent snapshot and removes it from the stack. If
+// the file was changed or deleted after the tool ran, Undo returns
+// ErrModified and keeps the snapshot unless force is set. A created file
+// that has since been deleted is already undone and is simply dropped.
+func (s *Stack) Undo(force bool) (Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.snapshots) == 0 {
+		return Snapshot{}, ErrEmpty
+	}
+	snap := s.snapshots[len(s.snapshots)-1]
+
+	current, err := os.ReadFile(snap.Path)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return snap, fmt.Errorf("read %s: %w", snap.Path, err)
+	}
+	if !force && (!exists && !snap.Created || exists && string(current) != snap.After) {
+		return snap, ErrModified
+	}
+
+	if err := restore(snap, exists); err != nil {
+		return snap, err
+	}
+	s.snapshots = s.snapshots[:len(s.snapshots)-1]
+	return snap, nil
+}
+
+func restore(snap Snapshot, exists bool) error {
+	if snap.Created {
+		if !exists {
+			return nil
+		}
+		if err := os.Remove(snap.Path); err != nil {
+			return fmt.Errorf("delete %s: %w", snap.Path, err)
+		}
+		return nil
+	}
+	// WriteFile keeps the mode of an existing file.
+	if err := os.WriteFile(snap.Path, []byte(snap.Before), 0o644); err != nil {
+		return fmt.Errorf("restore %s: %w", snap.Path, err)
+	}
+	return nil
+}
+
+// FromToolResponse builds a snapshot from the hook response of a
+// file-writing tool (Write, Edit or MultiEdit), which carries the file's
+// content from just before the tool changed it. The content the tool left
+// is read back from disk. It reports false for other tools or responses
+// that do not describe a file change.
+func FromToolResponse(toolName string, resp map[string]any) (Snapshot, bool) {
+	switch toolName {
+	case "Write", "Edit", "MultiEdit":
+	default:
+		return Snapshot{}, false
+	}
+	path, _ := resp["filePath"].(string)
+	if path == "" {
+		return Snapshot{}, false
+	}
+	created := toolName == "Write" && resp["type"] == "create"
+	before, hasBefore := resp["originalFile"].(string)
+	if !created && !hasBefore {
+		return Snapshot{}, false
+	}
+	after, err := os.ReadFile(path)
+	if err != nil {
+		return Snapshot{}, false
+	}
+	return Snapshot{Path: path, Tool: toolName, Created: created, Before: before, After: string(after)}, true
+}
@@ -0,0 +1,147 @@
+package undo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestUndoModify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	writeFile(t, path, "new\n")
+	s := New(0)
+	s.Push(Snapshot{Path: path, Tool: "Edit", Before: "old\n", After: "new\n"})
+
+	snap, err := s.Undo(false)
+	if err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if snap.Tool != "Edit" || readFile(t, path) != "old\n" || s.Len() != 0 {
+		t.Errorf("got %+v, content %q, len %d", snap, readFile(t, path), s.Len())
+	}
+	if _, err := s.Undo(false); !errors.Is(err, ErrEmpty) {
+		t.Errorf("second Undo() error = %v, want ErrEmpty", err)
+	}
+}
+
+func TestUndoCreate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new.txt")
+	writeFile(t, path, "hello")
+	s := New(0)
+	s.Push(Snapshot{Path: path, Tool: "Write", Created: true, After: "hello"})
+
+	if _, err := s.Undo(false); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("created file should be deleted, stat err = %v", err)
+	}
+
+	// A created file that is already gone is simply dropped.
+	s.Push(Snapshot{Path: path, Tool: "Write", Created: true, After: "hello"})
+	if _, err := s.Undo(false); err != nil || s.Len() != 0 {
+		t.Errorf("Undo() of a deleted created file = %v, len %d", err, s.Len())
+	}
+}
+
+func TestUndoDeletedExternally(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	s := New(0)
+	s.Push(Snapshot{Path: path, Tool: "Edit", Before: "old", After: "new"})
+
+	if _, err := s.Undo(false); !errors.Is(err, ErrModified) || s.Len() != 1 {
+		t.Fatalf("Undo() error = %v, len %d; want ErrModified and the snapshot kept", err, s.Len())
+	}
+	if _, err := s.Undo(true); err != nil {
+		t.Fatalf("forced Undo() error = %v", err)
+	}
+	if got := readFile(t, path); got != "old" {
+		t.Errorf("content = %q, want the file recreated", got)
+	}
+}
+
+func TestUndoChangedExternally(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	writeFile(t, path, "new plus user edit")
+	s := New(0)
+	s.Push(Snapshot{Path: path, Tool: "MultiEdit", Before: "old", After: "new"})
+
+	if _, err := s.Undo(false); !errors.Is(err, ErrModified) {
+		t.Fatalf("Undo() error = %v, want ErrModified", err)
+	}
+	if got := readFile(t, path); got != "new plus user edit" {
+		t.Errorf("refused undo changed the file to %q", got)
+	}
+	if _, err := s.Undo(true); err != nil || readFile(t, path) != "old" {
+		t.Errorf("forced Undo() = %v, content %q", err, readFile(t, path))
+	}
+}
+
+func TestUndoStepsBackInOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	writeFile(t, path, "v3")
+	s := New(0)
+	s.Push(Snapshot{Path: path, Tool: "Edit", Before: "v1", After: "v2"})
+	s.Push(Snapshot{Path: path, Tool: "Edit", Before: "v2", After: "v3"})
+
+	for _, want := range []string{"v2", "v1"} {
+		if _, err := s.Undo(false); err != nil {
+			t.Fatalf("Undo() error = %v", err)
+		}
+		if got := readFile(t, path); got != want {
+			t.Errorf("content = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestStackLimit(t *testing.T) {
+	s := New(2)
+	for _, p := range []string{"a", "b", "c"} {
+		s.Push(Snapshot{Path: p})
+	}
+	if s.Len() != 2 || s.snapshots[0].Path != "b" {
+		t.Errorf("snapshots = %+v, want the oldest dropped", s.snapshots)
+	}
+	s.Reset()
+	if s.Len() != 0 {
+		t.Error("Reset() should empty the stack")
+	}
+}
+
+func TestFromToolResponse(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.go")
+	writeFile(t, path, "after")
+
+	snap, ok := FromToolResponse("Edit", map[string]any{"filePath": path, "originalFile": "before"})
+	if !ok || snap.Created || snap.Before != "before" || snap.After != "after" {
+		t.Errorf("Edit = %+v, %v", snap, ok)
+	}
+	snap, ok = FromToolResponse("Write", map[string]any{"type": "create", "filePath": path, "originalFile": nil})
+	if !ok || !snap.Created {
+		t.Errorf("Write create = %+v, %v", snap, ok)
+	}
+	if _, ok := FromToolResponse("Read", map[string]any{"filePath": path}); ok {
+		t.Error("Read should not produce a snapshot")
+	}
+	if _, ok := FromToolResponse("Edit", map[string]any{"filePath": filepath.Join(dir, "missing"), "originalFile": ""}); ok {
+		t.Error("a file that cannot be read back should not produce a snapshot")
+	}
+}
@@ -0,0 +1,106 @@
+package loop_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/yanmxa/gencode/internal/core"
+	"github.com/yanmxa/gencode/internal/llm"
+	"github.com/yanmxa/gencode/internal/llm/fake"
+	"github.com/yanmxa/gencode/tests/integration/testutil"
+)
+
+// TestFakeProvider_ToolCallRoundTrip drives the real llm.Client streaming path
+// (provider → Client.Infer → agent → tool → provider) with scripted chunks.
+func TestFakeProvider_ToolCallRoundTrip(t *testing.T) {
+	testutil.RegisterFakeTool(t, "EchoTool", "echo: hello")
+
+	p := fake.Install(t)
+	p.EnqueueToolCall("tc1", "EchoTool", `{"text":"hello"}`)
+	p.EnqueueText("the tool said hello")
+
+	provider, err := llm.GetProvider(context.Background(), fake.Name, llm.AuthAPIKey)
+	if err != nil {
+		t.Fatalf("GetProvider() error: %v", err)
+	}
+
+	cwd := t.TempDir()
+	ag := core.NewAgent(core.Config{
+		ID:       "fake-provider-agent",
+		LLM:      llm.NewClient(provider, fake.Model, 0),
+		System:   core.NewSystem(),
+		Tools:    testutil.BuildTestTools(t),
+		CWD:      cwd,
+		MaxTurns: 10,
+	})
+
+	result, err := testutil.RunAgent(context.Background(), ag, "echo hello")
+	if err != nil {
+		t.Fatalf("RunAgent() error: %v", err)
+	}
+	if result.Turns != 2 {
+		t.Errorf("expected 2 turns, got %d", result.Turns)
+	}
+	if result.Content != "the tool said hello" {
+		t.Errorf("unexpected final content %q", result.Content)
+	}
+	if p.Pending() != 0 {
+		t.Errorf("expected all scripts consumed, %d pending", p.Pending())
+	}
+
+	reqs := p.Requests()
+	if len(reqs) != 2 {
+		t.Fatalf("expected 2 provider requests, got %d", len(reqs))
+	}
+	var toolResult *core.ToolResult
+	for _, m := range reqs[1].Messages {
+		if m.ToolResult != nil {
+			toolResult = m.ToolResult
+		}
+	}
+	if toolResult == nil {
+		t.Fatal("second request did not carry the tool result")
+	}
+	if toolResult.ToolCallID != "tc1" || toolResult.Content != "echo: hello" {
+		t.Errorf("unexpected tool result sent back: %+v", toolResult)
+	}
+}
+
+func TestFakeProvider_MalformedToolInput(t *testing.T) {
+	testutil.RegisterFakeTool(t, "EchoTool", "echo: ok")
+
+	p := fake.Install(t)
+	p.EnqueueToolCall("tc1", "EchoTool", `{'text': 'repairable',}`)
+	p.EnqueueToolCall("tc2", "EchoTool", `{"text": not json}`)
+	p.EnqueueText("done")
+
+	ag := core.NewAgent(core.Config{
+		ID:       "malformed-input-agent",
+		LLM:      llm.NewClient(p, fake.Model, 0),
+		System:   core.NewSystem(),
+		Tools:    testutil.BuildTestTools(t),
+		CWD:      t.TempDir(),
+		MaxTurns: 10,
+	})
+	if _, err := testutil.RunAgent(context.Background(), ag, "go"); err != nil {
+		t.Fatalf("RunAgent() error: %v", err)
+	}
+
+	results := map[string]*core.ToolResult{}
+	for _, m := range p.Requests()[2].Messages {
+		if m.ToolResult != nil {
+			results[m.ToolResult.ToolCallID] = m.ToolResult
+		}
+	}
+	if r := results["tc1"]; r == nil || r.IsError || r.Content != "echo: ok" {
+		t.Errorf("repairable input should run the tool, got %+v", r)
+	}
+	r := results["tc2"]
+	if r == nil || !r.IsError {
+		t.Fatalf("unrepairable input should fail, got %+v", r)
+	}
+	if !strings.Contains(r.Content, "not json") {
+		t.Errorf("error should echo the offending JSON, got %q", r.Content)
+	}
+}
@@ -3,6 +3,7 @@ package mcp_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -26,6 +27,7 @@ type FakeTransport struct {
 	alive         bool
 	handlers      map[string]func(*transport.JSONRPCRequest) *transport.JSONRPCResponse
 	notifHandler  transport.NotificationHandler
+	reqHandler    transport.RequestHandler
 	notifications []transport.JSONRPCNotification
 }
 
@@ -115,6 +117,30 @@ func (ft *FakeTransport) SetNotificationHandler(h transport.NotificationHandler)
 	ft.notifHandler = h
 }
 
+func (ft *FakeTransport) SetRequestHandler(h transport.RequestHandler) {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	ft.reqHandler = h
+}
+
+// ServerRequest simulates the server sending a request to the client and
+// returns the client's result marshaled to JSON, or its error.
+func (ft *FakeTransport) ServerRequest(method string, params any) (json.RawMessage, *transport.JSONRPCError) {
+	ft.mu.Lock()
+	h := ft.reqHandler
+	ft.mu.Unlock()
+	if h == nil {
+		return nil, &transport.JSONRPCError{Code: transport.ErrCodeMethodNotFound, Message: "no request handler"}
+	}
+	raw, _ := json.Marshal(params)
+	result, rpcErr := h(context.Background(), method, raw)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	data, _ := json.Marshal(result)
+	return data, nil
+}
+
 // jsonResponse builds a successful JSONRPCResponse with the given result.
 func jsonResponse(id uint64, result any) *transport.JSONRPCResponse {
 	data, _ := json.Marshal(result)
@@ -529,6 +555,160 @@ func TestClient_ToServer(t *testing.T) {
 	client.Disconnect()
 }
 
+// ---------------------------------------------------------------------------
+// Sampling tests
+// ---------------------------------------------------------------------------
+
+var samplingParams = mcp.CreateMessageParams{
+	Messages: []mcp.SamplingMessage{{
+		Role:    "user",
+		Content: mcp.SamplingContent{Type: "text", Text: "Summarize the diff"},
+	}},
+	MaxTokens: 100,
+}
+
+func echoCompleter(_ context.Context, params mcp.CreateMessageParams) (*mcp.CreateMessageResult, error) {
+	return &mcp.CreateMessageResult{
+		Role:       "assistant",
+		Content:    mcp.SamplingContent{Type: "text", Text: "echo: " + params.Messages[0].Content.Text},
+		Model:      "fake-model",
+		StopReason: "endTurn",
+	}, nil
+}
+
+func TestClient_SamplingAdvertisedOnlyWithHandler(t *testing.T) {
+	for _, withHandler := range []bool{false, true} {
+		ft := NewFakeTransport()
+		var initParams mcp.InitializeParams
+		ft.Handle("initialize", func(req *transport.JSONRPCRequest) *transport.JSONRPCResponse {
+			data, _ := json.Marshal(req.Params)
+			_ = json.Unmarshal(data, &initParams)
+			return jsonResponse(req.ID, mcp.InitializeResult{ProtocolVersion: "2024-11-05"})
+		})
+		client := newTestClient(ft)
+		if withHandler {
+			client.Sampling = func(context.Context, string, mcp.CreateMessageParams) (*mcp.CreateMessageResult, error) {
+				return nil, nil
+			}
+		}
+		if err := client.Connect(context.Background()); err != nil {
+			t.Fatalf("Connect() error: %v", err)
+		}
+		if got := initParams.Capabilities.Sampling != nil; got != withHandler {
+			t.Errorf("with handler=%v: sampling advertised=%v", withHandler, got)
+		}
+		if !withHandler {
+			if _, rpcErr := ft.ServerRequest(mcp.MethodSamplingCreateMessage, samplingParams); rpcErr == nil || rpcErr.Code != transport.ErrCodeMethodNotFound {
+				t.Errorf("expected method-not-found without handler, got %+v", rpcErr)
+			}
+		}
+		client.Disconnect()
+	}
+}
+
+func TestClient_SamplingRequestRunsThroughGate(t *testing.T) {
+	gate := mcp.NewSamplingGate(nil)
+	gate.SetCompleter(echoCompleter)
+	gate.EnablePrompts()
+
+	ft := NewFakeTransport()
+	client := newTestClient(ft)
+	client.Sampling = gate.Handle
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+	defer client.Disconnect()
+
+	// First request: the user is asked and allows the server for the session.
+	prompts := make(chan string, 2)
+	go func() {
+		req := gate.Recv()
+		prompts <- req.Server
+		req.Response <- mcp.SamplingAllowSession
+	}()
+
+	raw, rpcErr := ft.ServerRequest(mcp.MethodSamplingCreateMessage, samplingParams)
+	if rpcErr != nil {
+		t.Fatalf("sampling error: %+v", rpcErr)
+	}
+	var result mcp.CreateMessageResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		t.Fatalf("unmarshal result: %v", err)
+	}
+	if result.Content.Text != "echo: Summarize the diff" || result.Role != "assistant" || result.Model != "fake-model" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if server := <-prompts; server != "test" {
+		t.Errorf("consent asked for server %q, want %q", server, "test")
+	}
+
+	// Second request: consent is remembered, so nobody needs to answer.
+	if _, rpcErr := ft.ServerRequest(mcp.MethodSamplingCreateMessage, samplingParams); rpcErr != nil {
+		t.Fatalf("second sampling request error: %+v", rpcErr)
+	}
+	select {
+	case <-prompts:
+		t.Error("consent should not be asked again after allowing for the session")
+	default:
+	}
+}
+
+func TestSamplingGate_Declines(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("disabled in settings", func(t *testing.T) {
+		gate := mcp.NewSamplingGate(func() bool { return false })
+		gate.SetCompleter(echoCompleter)
+		if _, err := gate.Handle(ctx, "srv", samplingParams); !errors.Is(err, mcp.ErrSamplingDeclined) {
+			t.Errorf("err = %v, want ErrSamplingDeclined", err)
+		}
+	})
+
+	t.Run("nobody to ask", func(t *testing.T) {
+		gate := mcp.NewSamplingGate(nil)
+		gate.SetCompleter(echoCompleter)
+		if _, err := gate.Handle(ctx, "srv", samplingParams); !errors.Is(err, mcp.ErrSamplingDeclined) {
+			t.Errorf("err = %v, want ErrSamplingDeclined", err)
+		}
+	})
+
+	t.Run("user denies and is asked again", func(t *testing.T) {
+		gate := mcp.NewSamplingGate(nil)
+		gate.SetCompleter(echoCompleter)
+		gate.EnablePrompts()
+		asked := make(chan struct{}, 2)
+		go func() {
+			for range 2 {
+				req := gate.Recv()
+				asked <- struct{}{}
+				req.Response <- mcp.SamplingDeny
+			}
+		}()
+		for range 2 {
+			if _, err := gate.Handle(ctx, "srv", samplingParams); !errors.Is(err, mcp.ErrSamplingDeclined) {
+				t.Errorf("err = %v, want ErrSamplingDeclined", err)
+			}
+		}
+		if len(asked) != 2 {
+			t.Errorf("asked %d times, want 2", len(asked))
+		}
+	})
+
+	t.Run("declined maps to MCP error code", func(t *testing.T) {
+		gate := mcp.NewSamplingGate(func() bool { return false })
+		ft := NewFakeTransport()
+		client := newTestClient(ft)
+		client.Sampling = gate.Handle
+		if err := client.Connect(ctx); err != nil {
+			t.Fatalf("Connect() error: %v", err)
+		}
+		defer client.Disconnect()
+		if _, rpcErr := ft.ServerRequest(mcp.MethodSamplingCreateMessage, samplingParams); rpcErr == nil || rpcErr.Code != -1 {
+			t.Errorf("expected code -1 for declined sampling, got %+v", rpcErr)
+		}
+	})
+}
+
 // ---------------------------------------------------------------------------
 // Registry tests
 // ---------------------------------------------------------------------------
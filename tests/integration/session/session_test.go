@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/yanmxa/gencode/internal/core"
 	session "github.com/yanmxa/gencode/internal/session"
 )
 
@@ -136,6 +137,40 @@ func TestSession_List(t *testing.T) {
 	}
 }
 
+func TestSession_CustomTitleRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+
+	sess := &session.Snapshot{
+		Metadata: session.SessionMetadata{
+			ID:          "renamed",
+			CustomTitle: "auth",
+		},
+		Entries: []session.Entry{makeUserEntry("u1", "please fix the login redirect")},
+	}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	list, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	if len(list) != 1 || list[0].CustomTitle != "auth" || list[0].Title != "auth" {
+		t.Fatalf("List() = %+v, want custom title %q", list, "auth")
+	}
+
+	loaded, err := store.Load("renamed")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if loaded.Metadata.CustomTitle != "auth" {
+		t.Errorf("CustomTitle = %q, want %q", loaded.Metadata.CustomTitle, "auth")
+	}
+	if loaded.Metadata.Title != "please fix the login redirect" {
+		t.Errorf("generated Title = %q, want first message", loaded.Metadata.Title)
+	}
+}
+
 func TestSession_GetLatest(t *testing.T) {
 	store := newTestStore(t)
 
@@ -165,6 +200,133 @@ func TestSession_GetLatest(t *testing.T) {
 	}
 }
 
+func TestSession_ListRecent(t *testing.T) {
+	store := newTestStore(t)
+
+	for _, id := range []string{"first", "second", "third"} {
+		if err := store.Save(&session.Snapshot{Metadata: session.SessionMetadata{ID: id, Title: id}}); err != nil {
+			t.Fatalf("Save(%s) error: %v", id, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	recent, err := store.ListRecent(2)
+	if err != nil {
+		t.Fatalf("ListRecent() error: %v", err)
+	}
+	if len(recent) != 2 || recent[0].ID != "third" || recent[1].ID != "second" {
+		ids := make([]string, len(recent))
+		for i, meta := range recent {
+			ids[i] = meta.ID
+		}
+		t.Fatalf("ListRecent(2) = %v, want [third second]", ids)
+	}
+}
+
+func TestSession_GetLatestSkipsCorruptSession(t *testing.T) {
+	store := newTestStore(t)
+
+	if err := store.Save(&session.Snapshot{Metadata: session.SessionMetadata{ID: "good", Title: "Good"}}); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if err := store.Save(&session.Snapshot{Metadata: session.SessionMetadata{ID: "broken", Title: "Broken"}}); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	if err := os.WriteFile(store.SessionPath("broken"), []byte("not json\n{}\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+
+	latest, err := store.GetLatest()
+	if err != nil {
+		t.Fatalf("GetLatest() error: %v", err)
+	}
+	if latest.Metadata.ID != "good" {
+		t.Errorf("expected GetLatest to fall back to 'good', got %q", latest.Metadata.ID)
+	}
+}
+
+func TestSession_TokenCountersRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+
+	sess := &session.Snapshot{
+		Metadata: session.SessionMetadata{
+			ID:           "tokens",
+			InputTokens:  5400,
+			OutputTokens: 320,
+		},
+		Entries: []session.Entry{makeUserEntry("u1", "hello"), makeAssistantEntry("a1", "hi")},
+	}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	loaded, err := store.Load("tokens")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if loaded.Metadata.InputTokens != 5400 || loaded.Metadata.OutputTokens != 320 {
+		t.Errorf("tokens = %d/%d, want 5400/320", loaded.Metadata.InputTokens, loaded.Metadata.OutputTokens)
+	}
+}
+
+func TestSession_ForkSnapshotBranchesFromEarlierPoint(t *testing.T) {
+	store := newTestStore(t)
+
+	entries := []session.Entry{
+		makeUserEntry("u1", "first"), makeAssistantEntry("a1", "one"),
+		makeUserEntry("u2", "second"), makeAssistantEntry("a2", "two"),
+	}
+	parent := &session.Snapshot{
+		Metadata: session.SessionMetadata{ID: "parent", CustomTitle: "Original"},
+		Entries:  entries,
+	}
+	if err := store.Save(parent); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	branched, err := store.ForkSnapshot(&session.Snapshot{Metadata: parent.Metadata, Entries: entries[:2]})
+	if err != nil {
+		t.Fatalf("ForkSnapshot() error: %v", err)
+	}
+	if branched.Metadata.ID == "" || branched.Metadata.ID == "parent" {
+		t.Fatalf("branch ID = %q, want a new ID", branched.Metadata.ID)
+	}
+	branched.Entries[0].Message.Content[0].Text = "changed"
+	if getEntryText(entries[0]) != "first" {
+		t.Error("ForkSnapshot should deep-copy the entries it saves")
+	}
+
+	loaded, err := store.Load(branched.Metadata.ID)
+	if err != nil {
+		t.Fatalf("Load(branch) error: %v", err)
+	}
+	if len(loaded.Entries) != 2 || getEntryText(loaded.Entries[1]) != "one" {
+		t.Errorf("branch entries = %+v, want the first exchange", loaded.Entries)
+	}
+	if loaded.Metadata.ParentSessionID != "parent" || loaded.Metadata.CustomTitle != "Original" {
+		t.Errorf("branch metadata = %+v", loaded.Metadata)
+	}
+
+	original, err := store.Load("parent")
+	if err != nil {
+		t.Fatalf("Load(parent) error: %v", err)
+	}
+	if len(original.Entries) != 4 {
+		t.Errorf("parent has %d entries, want 4", len(original.Entries))
+	}
+
+	list, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	for _, meta := range list {
+		if meta.ID == branched.Metadata.ID && meta.ParentSessionID != "parent" {
+			t.Errorf("listed ParentSessionID = %q, want parent", meta.ParentSessionID)
+		}
+	}
+}
+
 func TestSession_Delete(t *testing.T) {
 	store := newTestStore(t)
 
@@ -348,6 +510,43 @@ func TestSession_MessageTypes_PersistRoundTrip(t *testing.T) {
 	}
 }
 
+func TestSession_ContextNote_PersistRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+
+	msgs := []core.ChatMessage{
+		{Role: core.RoleUser, Content: "hello"},
+		{Role: core.RoleContext, Content: "the tests run with make check"},
+		{Role: core.RoleUser, Content: core.ContextNotePrefix + "typed by the user"},
+	}
+	sess := &session.Snapshot{
+		Metadata: session.SessionMetadata{ID: "context-note-roundtrip", Cwd: "/tmp/project"},
+		Entries:  session.ConvertToEntries(msgs),
+	}
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	loaded, err := store.Load("context-note-roundtrip")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if got := loaded.Entries[1].Type; got != session.EntryContext {
+		t.Fatalf("note entry type = %q, want %q", got, session.EntryContext)
+	}
+
+	restored := session.ConvertFromEntries(loaded.Entries)
+	if len(restored) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(restored))
+	}
+	if restored[1].Role != core.RoleContext || restored[1].Content != "the tests run with make check" {
+		t.Errorf("note = %q %q, want the context note without prefix", restored[1].Role, restored[1].Content)
+	}
+	// A user message that merely starts with the prefix stays a user message.
+	if restored[2].Role != core.RoleUser || restored[2].Content != msgs[2].Content {
+		t.Errorf("prefixed user message = %q %q, want it unchanged", restored[2].Role, restored[2].Content)
+	}
+}
+
 func TestSession_PersistToolResult(t *testing.T) {
 	dir := filepath.Join(t.TempDir(), "sessions")
 	if err := os.MkdirAll(dir, 0o755); err != nil {